@@ -76,17 +76,52 @@ async function processAnchoringJob(job: Job<AnchoringJobData>): Promise<void> {
       txCount = Math.floor(Math.random() * 50) + 1;
     }
 
+    // Leave a breadcrumb on Fabric so an interrupted anchor can be re-driven.
+    // RecordAnchor requires the attempt, so without one the anchor could not
+    // be recorded on Fabric: fail before paying for the Algorand transaction
+    // and let BullMQ retry.
+    let attemptId: string | undefined;
+    if (fabricService.isConnected()) {
+      try {
+        attemptId = await fabricService.submitTransaction(
+          config.FABRIC_CHAINCODE_NAME,
+          'BeginAnchorAttempt',
+          stateCode,
+          JSON.stringify({ start: blockStart, end: blockEnd }),
+          stateRoot,
+        );
+      } catch (err) {
+        log.error({ err, stateCode }, 'Failed to begin anchor attempt on Fabric, not anchoring');
+        throw err;
+      }
+    }
+
     // Anchor to Algorand
-    const result = await anchoringService.anchorStateRoot(
-      stateCode,
-      `${stateCode.toLowerCase()}-land-channel`,
-      { start: blockStart, end: blockEnd },
-      stateRoot,
-      txCount,
-    );
+    let result: Awaited<ReturnType<typeof anchoringService.anchorStateRoot>>;
+    try {
+      result = await anchoringService.anchorStateRoot(
+        stateCode,
+        `${stateCode.toLowerCase()}-land-channel`,
+        { start: blockStart, end: blockEnd },
+        stateRoot,
+        txCount,
+      );
+    } catch (err) {
+      if (attemptId) {
+        await fabricService
+          .submitTransaction(
+            config.FABRIC_CHAINCODE_NAME,
+            'AbortAnchorAttempt',
+            attemptId,
+            err instanceof Error ? err.message : String(err),
+          )
+          .catch((abortErr) => log.warn({ err: abortErr }, 'Failed to abort anchor attempt on Fabric'));
+      }
+      throw err;
+    }
 
     // Record the anchor on Fabric too
-    if (fabricService.isConnected()) {
+    if (attemptId) {
       try {
         await fabricService.submitTransaction(
           config.FABRIC_CHAINCODE_NAME,
@@ -103,10 +138,12 @@ async function processAnchoringJob(job: Job<AnchoringJobData>): Promise<void> {
             algorandRound: result.algorandRound,
             anchoredAt: new Date().toISOString(),
             verified: true,
+            attemptId,
           }),
         );
       } catch (err) {
-        log.warn({ err }, 'Failed to record anchor on Fabric (non-critical)');
+        // The attempt stays PENDING, so the watchdog re-drives it
+        log.error({ err, attemptId, anchorId: result.anchorId }, 'Failed to record anchor on Fabric');
       }
     }
 
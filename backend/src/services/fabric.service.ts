@@ -2,12 +2,13 @@
 // Connects to Fabric peer, submits/evaluates transactions on chaincode
 
 import * as grpc from '@grpc/grpc-js';
-import { connect, Contract, Gateway, Identity, Signer, signers } from '@hyperledger/fabric-gateway';
+import { CommitError, connect, Contract, Gateway, Identity, Signer, signers } from '@hyperledger/fabric-gateway';
 import * as crypto from 'crypto';
 import * as fs from 'fs';
 import * as path from 'path';
 import { config } from '../config/index.js';
 import { createServiceLogger } from '../config/logger.js';
+import type { Receipt } from '../types/index.js';
 import {
   FabricConnectionError,
   FabricEndorsementError,
@@ -16,6 +17,19 @@ import {
 
 const log = createServiceLogger('fabric-service');
 
+/**
+ * Transaction validation codes for a commit invalidated because another
+ * transaction in the same block wrote a key it read: MVCC_READ_CONFLICT
+ * and PHANTOM_READ_CONFLICT. Nothing was written, so the transaction can
+ * simply be endorsed and submitted again. Every event-emitting chaincode
+ * call updates the channel's event sequence counter, so concurrent
+ * submissions hit these routinely.
+ */
+const READ_CONFLICT_CODES = new Set([11, 12]);
+
+/** Resubmissions after a read conflict before giving up. */
+const MAX_READ_CONFLICT_RETRIES = 3;
+
 /**
  * FabricService wraps the Hyperledger Fabric Gateway SDK.
  * It manages the gRPC connection, gateway identity, and provides
@@ -97,6 +111,8 @@ class FabricService {
   /**
    * Submit a transaction (write operation) to Fabric chaincode.
    * The transaction is endorsed, ordered, and committed to the ledger.
+   * A transaction invalidated by a read conflict is resubmitted, up to
+   * MAX_READ_CONFLICT_RETRIES times, after a short randomised backoff.
    */
   async submitTransaction(
     chaincodeName: string,
@@ -105,23 +121,51 @@ class FabricService {
   ): Promise<string> {
     const contract = this.getContract(chaincodeName);
 
-    try {
-      log.debug(
-        { chaincode: chaincodeName, function: functionName, argsCount: args.length },
-        'Submitting Fabric transaction',
-      );
-
-      const result = await contract.submitTransaction(functionName, ...args);
-      const resultStr = Buffer.from(result).toString('utf8');
-
-      log.info(
-        { chaincode: chaincodeName, function: functionName },
-        'Fabric transaction submitted successfully',
-      );
+    for (let attempt = 1; ; attempt++) {
+      try {
+        log.debug(
+          { chaincode: chaincodeName, function: functionName, argsCount: args.length, attempt },
+          'Submitting Fabric transaction',
+        );
+
+        const result = await contract.submitTransaction(functionName, ...args);
+        const resultStr = Buffer.from(result).toString('utf8');
+
+        log.info(
+          { chaincode: chaincodeName, function: functionName },
+          'Fabric transaction submitted successfully',
+        );
+
+        return resultStr;
+      } catch (err) {
+        if (attempt > MAX_READ_CONFLICT_RETRIES || !isReadConflict(err)) {
+          return this.handleFabricError(err, functionName);
+        }
+        const backoffMs = 50 * 2 ** attempt + Math.floor(Math.random() * 100);
+        log.warn(
+          { chaincode: chaincodeName, function: functionName, attempt, backoffMs },
+          'Fabric transaction hit a read conflict, resubmitting',
+        );
+        await new Promise((resolve) => setTimeout(resolve, backoffMs));
+      }
+    }
+  }
 
-      return resultStr;
-    } catch (err) {
-      return this.handleFabricError(err, functionName);
+  /**
+   * Submit a transaction whose chaincode function returns a Receipt
+   * (RegisterProperty, ExecuteTransfer, AddEncumbrance, ...) and parse it.
+   */
+  async submitForReceipt(
+    chaincodeName: string,
+    functionName: string,
+    ...args: string[]
+  ): Promise<Receipt> {
+    const result = await this.submitTransaction(chaincodeName, functionName, ...args);
+    try {
+      return JSON.parse(result) as Receipt;
+    } catch {
+      log.error({ function: functionName }, 'Fabric transaction returned an unparseable receipt');
+      throw new FabricConnectionError(`${functionName} returned an invalid receipt`);
     }
   }
 
@@ -249,6 +293,18 @@ class FabricService {
   }
 }
 
+/**
+ * Whether a submit failed because the transaction lost a read conflict
+ * at commit, so resubmitting it is safe.
+ */
+function isReadConflict(err: unknown): boolean {
+  if (err instanceof CommitError) {
+    return READ_CONFLICT_CODES.has(err.code);
+  }
+  const message = err instanceof Error ? err.message : String(err);
+  return message.includes('MVCC_READ_CONFLICT') || message.includes('PHANTOM_READ_CONFLICT');
+}
+
 /** Singleton Fabric service instance */
 export const fabricService = new FabricService();
 export default fabricService;
@@ -308,12 +308,12 @@ class LandService {
     // Submit to Fabric chaincode
     if (fabricService.isConnected()) {
       try {
-        const result = await fabricService.submitTransaction(
+        const receipt = await fabricService.submitForReceipt(
           config.FABRIC_CHAINCODE_NAME,
           'RegisterProperty',
           landRecordJson,
         );
-        fabricTxId = result || `fabric_tx_${Date.now()}`;
+        fabricTxId = receipt.fabricTxId;
         log.info({ propertyId: data.propertyId, fabricTxId }, 'Property registered on Fabric');
       } catch (err) {
         log.error({ err, propertyId: data.propertyId }, 'Failed to register on Fabric');
@@ -272,16 +272,18 @@ class TransferService {
     }
 
     let fabricTxId = '';
+    let coolingExpiresAt: string | undefined;
 
     // Submit to Fabric chaincode
     if (fabricService.isConnected()) {
       try {
-        const result = await fabricService.submitTransaction(
+        const receipt = await fabricService.submitForReceipt(
           config.FABRIC_CHAINCODE_NAME,
           'ExecuteTransfer',
           transferId,
         );
-        fabricTxId = result || `fabric_tx_${Date.now()}`;
+        fabricTxId = receipt.fabricTxId;
+        coolingExpiresAt = receipt.coolingExpiresAt;
       } catch (err) {
         log.error({ err, transferId }, 'Fabric ExecuteTransfer failed');
         throw err;
@@ -291,8 +293,9 @@ class TransferService {
       log.warn({ transferId }, 'Fabric not connected, skipping chaincode (dev mode)');
     }
 
-    // Set cooling period (72 hours)
-    const coolingEnd = coolingPeriodEnd();
+    // Cooling period as set by the chaincode (the state may configure it);
+    // 72 hours in dev mode
+    const coolingEnd = coolingExpiresAt ? new Date(coolingExpiresAt) : coolingPeriodEnd();
 
     // Update transfer record
     await prisma.transfer.update({
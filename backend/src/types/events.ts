@@ -28,6 +28,20 @@ export interface ChaincodeEvent {
   chaincodeName: string;
 }
 
+/**
+ * Metadata the chaincode adds to every event payload under `envelope`.
+ * `eventSequence` counts the channel's events from 1 without gaps, so a
+ * jump means events were missed; branch on `payloadVersion` when parsing.
+ */
+export interface EventEnvelope {
+  payloadVersion: number;
+  eventSequence: number;
+  /** RFC 3339 transaction timestamp */
+  emittedAt: string;
+  fabricTxId: string;
+  channelId: string;
+}
+
 // ============================================
 // Property Registered Event
 // ============================================
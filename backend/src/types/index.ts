@@ -440,6 +440,28 @@ export interface AnchorRecord {
   verified: boolean;
 }
 
+// ============================================
+// Chaincode Receipt Types
+// ============================================
+
+export interface ReceiptRecord {
+  kind: string;
+  id: string;
+  status?: string;
+}
+
+/** Returned by the chaincode's main write functions (RegisterProperty, ExecuteTransfer, ...) */
+export interface Receipt {
+  recordId: string;
+  status?: string;
+  related?: ReceiptRecord[];
+  coolingExpiresAt?: string;
+  requestId?: string;
+  duplicate: boolean;
+  fabricTxId: string;
+  timestamp: string;
+}
+
 // ============================================
 // API Response Types
 // ============================================
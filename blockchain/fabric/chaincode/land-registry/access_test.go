@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// newTestCitizen returns a citizen identity whose certificate carries
+// aadhaarHash; an empty hash leaves the attribute unset.
+func newTestCitizen(t *testing.T, aadhaarHash string) *testIdentity {
+	t.Helper()
+	citizen := newTestIdentity(t, "CitizenMSP", "citizen", "TS")
+	if aadhaarHash != "" {
+		citizen.attrs["aadhaarHash"] = aadhaarHash
+	}
+	return citizen
+}
+
+func TestCitizenSelfAccessMatrix(t *testing.T) {
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	seller, buyer := newTestSigner(t, 1), newTestSigner(t, 2)
+	witness1, witness2 := newTestSigner(t, 3), newTestSigner(t, 4)
+	property := testProperty("142", 1)
+	ledger.registerTestProperty(registrar, property)
+	ledger.registerTestSigners(registrar, seller, buyer, witness1, witness2)
+	transferID := ledger.initiateTestTransfer(registrar, testTransfer(property.PropertyID, seller, buyer, witness1, witness2))
+
+	reads := []struct {
+		name string
+		call func(ctx contractapi.TransactionContextInterface) error
+	}{
+		{"GetProperty", func(ctx contractapi.TransactionContextInterface) error {
+			_, err := ledger.contract.GetProperty(ctx, property.PropertyID)
+			return err
+		}},
+		{"GetPropertyHistory", func(ctx contractapi.TransactionContextInterface) error {
+			_, err := ledger.contract.GetPropertyHistory(ctx, property.PropertyID)
+			return err
+		}},
+		{"QueryByOwner(seller)", func(ctx contractapi.TransactionContextInterface) error {
+			_, err := ledger.contract.QueryByOwner(ctx, seller.hash)
+			return err
+		}},
+		{"QueryByOwner(buyer)", func(ctx contractapi.TransactionContextInterface) error {
+			_, err := ledger.contract.QueryByOwner(ctx, buyer.hash)
+			return err
+		}},
+		{"GetTransfer", func(ctx contractapi.TransactionContextInterface) error {
+			_, err := ledger.contract.GetTransfer(ctx, transferID)
+			return err
+		}},
+	}
+
+	all := []bool{true, true, true, true, true}
+	none := []bool{false, false, false, false, false}
+	tests := []struct {
+		name     string
+		identity *testIdentity
+		allowed  []bool
+	}{
+		{"registrar", registrar, all},
+		{"tehsildar", newTestIdentity(t, "TelanganaMSP", "tehsildar", "TS"), all},
+		{"bank", newTestIdentity(t, "SBIMSP", "bank", "TS"), all},
+		{"court", newTestIdentity(t, "CourtMSP", "court", "TS"), all},
+		{"admin", newTestIdentity(t, "AdminOrgMSP", "admin", "IN"), all},
+		{"owner citizen", newTestCitizen(t, seller.hash), []bool{true, true, true, false, true}},
+		{"buyer citizen", newTestCitizen(t, buyer.hash), []bool{false, false, false, true, true}},
+		{"witness citizen", newTestCitizen(t, witness1.hash), none},
+		{"unrelated citizen", newTestCitizen(t, testAadhaarHash(99)), none},
+		{"citizen without aadhaarHash", newTestCitizen(t, ""), none},
+		{"treasury", newTestIdentity(t, "TreasuryMSP", "treasury", "TS"), none},
+		{"no role", newTestIdentity(t, "CitizenMSP", "", "TS"), none},
+	}
+	for _, tc := range tests {
+		for i, read := range reads {
+			err := ledger.submit(tc.identity, read.call)
+			denied := errorCode(err) == ErrCodeAccessDenied
+			// MockStub has no history database, so an allowed
+			// GetPropertyHistory fails only after the access check
+			if err != nil && !denied && read.name != "GetPropertyHistory" {
+				t.Errorf("%s %s: %v", tc.name, read.name, err)
+			}
+			if denied == tc.allowed[i] {
+				t.Errorf("%s %s: allowed = %v, want %v (%v)", tc.name, read.name, !denied, tc.allowed[i], err)
+			}
+		}
+	}
+}
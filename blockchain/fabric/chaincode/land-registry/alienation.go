@@ -0,0 +1,147 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// SC/ST LAND ALIENATION PROTECTION
+// ============================================================
+// Several states bar the transfer of land held by Scheduled Caste or
+// Scheduled Tribe members to buyers outside the category without the
+// collector's permission. An owner's protected category is recorded
+// only at registration or by a tehsildar's attestation against a caste
+// certificate; it is never inferred from Aadhaar. In states with
+// enforceAlienationProtection set, ExecuteTransfer checks it (see
+// checkAlienation). The category is sensitive: it is left out of
+// events, certificates, extracts and certified copies.
+
+// protectedCategories lists the owner categories land alienation laws
+// protect.
+var protectedCategories = map[string]bool{
+	"SC": true,
+	"ST": true,
+}
+
+// validateProtectedCategory checks an owner's protected category. An
+// empty category means the owner is not recorded as protected.
+func validateProtectedCategory(category, field string) error {
+	if category == "" || protectedCategories[category] {
+		return nil
+	}
+	return newError(ErrCodeValidationError, "%s '%s' must be SC or ST", field, category)
+}
+
+// AttestProtectedCategory records, on a tehsildar's attestation, that a
+// current owner of a property belongs to a protected category (SC or
+// ST). certificateRef is the caste certificate the attestation rests
+// on. Only tehsildars with jurisdiction over the property can attest.
+// Emits OWNER_CATEGORY_ATTESTED, which does not carry the category.
+func (s *LandRegistryContract) AttestProtectedCategory(ctx contractapi.TransactionContextInterface, propertyID, aadhaarHash, category, certificateRef string) error {
+	if err := requireRole(ctx, "tehsildar"); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(aadhaarHash, "aadhaarHash"); err != nil {
+		return err
+	}
+	if category == "" {
+		return newError(ErrCodeValidationError, "category is required")
+	}
+	if err := validateProtectedCategory(category, "category"); err != nil {
+		return err
+	}
+	if certificateRef == "" {
+		return newError(ErrCodeValidationError, "certificateRef is required")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return err
+	}
+
+	var owner *Owner
+	for i := range property.CurrentOwner.Owners {
+		if property.CurrentOwner.Owners[i].AadhaarHash == aadhaarHash {
+			owner = &property.CurrentOwner.Owners[i]
+			break
+		}
+	}
+	if owner == nil {
+		return newError(ErrCodeOwnerNotFound, "%s is not a current owner of property %s", aadhaarHash, propertyID)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	owner.ProtectedCategory = category
+	owner.ProtectedCategoryRef = certificateRef
+	owner.ProtectedCategoryAttestedBy = getCallerID(ctx)
+	owner.ProtectedCategoryAttestedAt = now
+
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+
+	event := OwnerCategoryAttestedEvent{
+		Type:       "OWNER_CATEGORY_ATTESTED",
+		PropertyID: propertyID,
+		OwnerHash:  aadhaarHash,
+		AttestedBy: owner.ProtectedCategoryAttestedBy,
+		FabricTxID: txID,
+		Timestamp:  now,
+		StateCode:  property.Location.StateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "OWNER_CATEGORY_ATTESTED", event)
+}
+
+// checkAlienation enforces the state's enforceAlienationProtection
+// setting: a transfer from an owner of a protected category to a buyer
+// who has not declared the same category needs the collector's
+// permission in alienationPermissionRef. The buyer's declaration and
+// the permission stay on the transfer for audit.
+func checkAlienation(property *LandRecord, transfer *TransferRecord, settings *RegistrySettings) error {
+	if err := validateProtectedCategory(transfer.BuyerCategoryDeclaration, "buyerCategoryDeclaration"); err != nil {
+		return err
+	}
+	if !settings.EnforceAlienationProtection {
+		return nil
+	}
+	for _, owner := range property.CurrentOwner.Owners {
+		if owner.AadhaarHash != transfer.Seller.AadhaarHash || owner.ProtectedCategory == "" {
+			continue
+		}
+		if transfer.BuyerCategoryDeclaration == owner.ProtectedCategory || transfer.AlienationPermissionRef != "" {
+			return nil
+		}
+		return newError(ErrCodeTransferAlienationRestricted, "land of a protected-category owner cannot pass to a buyer outside the category without the collector's permission")
+	}
+	return nil
+}
+
+// redactedRecord returns a copy of a property with its owners'
+// protected categories removed, for views given to third parties.
+func redactedRecord(property *LandRecord) LandRecord {
+	redacted := *property
+	redacted.CurrentOwner.Owners = make([]Owner, len(property.CurrentOwner.Owners))
+	for i, owner := range property.CurrentOwner.Owners {
+		owner.ProtectedCategory = ""
+		owner.ProtectedCategoryRef = ""
+		owner.ProtectedCategoryAttestedBy = ""
+		owner.ProtectedCategoryAttestedAt = ""
+		redacted.CurrentOwner.Owners[i] = owner
+	}
+	return redacted
+}
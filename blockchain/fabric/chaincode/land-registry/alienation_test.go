@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestConfirmHighValueTransferRechecksAlienation(t *testing.T) {
+	ledger, registrar, transferID, signers := signingTestTransfer(t)
+	ledger.signTestTransfer(registrar, transferID, signers...)
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", HighValueTransferThreshold: 1})
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer: %v", err)
+	}
+	if got := ledger.readTransfer(transferID).Status; got != "AWAITING_SECOND_APPROVAL" {
+		t.Fatalf("transfer status = %s, want AWAITING_SECOND_APPROVAL", got)
+	}
+
+	// While the transfer waits the seller is attested SC and the state
+	// starts enforcing alienation protection
+	propertyID := ledger.readTransfer(transferID).PropertyID
+	ledger.mustSubmit(newTestOfficer(t, "tehsildar", "TS", "HYD", "SRN"), func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.AttestProtectedCategory(ctx, propertyID, signers[0].hash, "SC", "TS/SRN/CC/2027/88")
+	})
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", HighValueTransferThreshold: 1, EnforceAlienationProtection: true})
+
+	confirm := func() error {
+		return ledger.submit(newTestIdentity(t, "TelanganaMSP", "registrar", "TS"), func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.ConfirmHighValueTransfer(ctx, transferID)
+		})
+	}
+	expectCode(t, confirm(), ErrCodeTransferAlienationRestricted)
+	if got := ledger.readProperty(propertyID).CurrentOwner.Owners[0].AadhaarHash; got != signers[0].hash {
+		t.Fatalf("owner = %s after a refused confirmation", got)
+	}
+
+	// The state lifts the restriction and the confirmation goes through
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", HighValueTransferThreshold: 1})
+	if err := confirm(); err != nil {
+		t.Fatalf("ConfirmHighValueTransfer: %v", err)
+	}
+}
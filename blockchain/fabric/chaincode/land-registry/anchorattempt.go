@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// ANCHOR ATTEMPTS
+// ============================================================
+// The anchoring job computes a state root, submits it to Algorand and
+// records the result with RecordAnchor. If it dies in between, or the
+// submission fails, nothing on chain says an anchor is owed. So the job
+// first opens an attempt with BeginAnchorAttempt; RecordAnchor must name
+// it and marks it COMPLETED, and AbortAnchorAttempt marks it FAILED. A
+// watchdog re-drives attempts left PENDING with
+// QueryStaleAnchorAttempts.
+
+// BeginAnchorAttempt opens an anchor attempt for stateRoot, the
+// GetStateRoot result for blockRangeJSON in stateCode, and returns its
+// ID. Call it before submitting the root to Algorand. Only admins can
+// begin attempts. Emits ANCHOR_ATTEMPT_STARTED.
+func (s *LandRegistryContract) BeginAnchorAttempt(ctx contractapi.TransactionContextInterface, stateCode, blockRangeJSON, stateRoot string) (string, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return "", err
+	}
+	if stateCode == "" {
+		return "", newError(ErrCodeValidationError, "stateCode is required")
+	}
+	if stateRoot == "" {
+		return "", newError(ErrCodeValidationError, "stateRoot is required")
+	}
+
+	var br BlockRange
+	if err := json.Unmarshal([]byte(blockRangeJSON), &br); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse block range: %v", err)
+	}
+	if br.Start < 0 || br.End < br.Start {
+		return "", newError(ErrCodeValidationError, "invalid block range [%d, %d]", br.Start, br.End)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	attempt := &AnchorAttempt{
+		DocType:          "anchorAttempt",
+		AttemptID:        "aat_" + txID[:8],
+		StateCode:        stateCode,
+		FabricBlockRange: br,
+		StateRoot:        stateRoot,
+		Status:           "PENDING",
+		StartedBy:        getCallerID(ctx),
+		StartedAt:        now,
+		FabricTxID:       txID,
+	}
+	if err := putAnchorAttempt(ctx, attempt); err != nil {
+		return "", err
+	}
+	if err := emitAnchorAttemptEvent(ctx, "ANCHOR_ATTEMPT_STARTED", attempt); err != nil {
+		return "", err
+	}
+	return attempt.AttemptID, nil
+}
+
+// AbortAnchorAttempt marks a PENDING anchor attempt FAILED with reason,
+// e.g. the Algorand submission or its verification failed. The job
+// retries by beginning a new attempt. Only admins can abort attempts.
+// Writes an audit entry. Emits ANCHOR_ATTEMPT_FAILED.
+func (s *LandRegistryContract) AbortAnchorAttempt(ctx contractapi.TransactionContextInterface, attemptID, reason string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+
+	attempt, err := getPendingAnchorAttempt(ctx, attemptID)
+	if err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	attempt.Status = "FAILED"
+	attempt.FailureReason = reason
+	attempt.FinishedBy = getCallerID(ctx)
+	attempt.FinishedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	attempt.FabricTxID = ctx.GetStub().GetTxID()
+	if err := putAnchorAttempt(ctx, attempt); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, "AbortAnchorAttempt", attemptID); err != nil {
+		return err
+	}
+	return emitAnchorAttemptEvent(ctx, "ANCHOR_ATTEMPT_FAILED", attempt)
+}
+
+// QueryStaleAnchorAttempts returns the anchor attempts still PENDING
+// that were begun more than olderThanMinutes before the transaction
+// time, oldest first: anchors whose job most likely died. Only admins
+// can query.
+func (s *LandRegistryContract) QueryStaleAnchorAttempts(ctx contractapi.TransactionContextInterface, olderThanMinutes int) ([]*AnchorAttempt, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+	if olderThanMinutes < 0 {
+		return nil, newError(ErrCodeValidationError, "olderThanMinutes must not be negative")
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	cutoff := time.Unix(timestamp.Seconds, 0).Add(-time.Duration(olderThanMinutes) * time.Minute)
+
+	queryString := `{"selector":{"docType":"anchorAttempt","status":"PENDING"},"sort":[{"startedAt":"asc"}]}`
+	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, internalError("failed to query anchor attempts: %v", err)
+	}
+	defer iterator.Close()
+
+	results := []*AnchorAttempt{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate: %v", err)
+		}
+		var attempt AnchorAttempt
+		if err := json.Unmarshal(kv.Value, &attempt); err != nil {
+			return nil, internalError("failed to unmarshal anchor attempt: %v", err)
+		}
+		startedAt, err := time.Parse(time.RFC3339, attempt.StartedAt)
+		if err != nil || !startedAt.Before(cutoff) {
+			continue
+		}
+		results = append(results, &attempt)
+	}
+	return results, nil
+}
+
+// completeAnchorAttempt marks the attempt an anchor names COMPLETED.
+// The attempt must be PENDING and for the anchor's state and root.
+func completeAnchorAttempt(ctx contractapi.TransactionContextInterface, anchor *AnchorRecord) error {
+	attempt, err := getPendingAnchorAttempt(ctx, anchor.AttemptID)
+	if err != nil {
+		return err
+	}
+	if attempt.StateCode != anchor.StateCode || attempt.StateRoot != anchor.StateRoot {
+		return newError(ErrCodeAnchorAttemptMismatch, "anchor attempt %s is for %s root %s", attempt.AttemptID, attempt.StateCode, attempt.StateRoot)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	attempt.Status = "COMPLETED"
+	attempt.AnchorID = anchor.AnchorID
+	attempt.FinishedBy = getCallerID(ctx)
+	attempt.FinishedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	attempt.FabricTxID = ctx.GetStub().GetTxID()
+	return putAnchorAttempt(ctx, attempt)
+}
+
+// getPendingAnchorAttempt loads an anchor attempt and checks it is
+// still PENDING.
+func getPendingAnchorAttempt(ctx contractapi.TransactionContextInterface, attemptID string) (*AnchorAttempt, error) {
+	if attemptID == "" {
+		return nil, newError(ErrCodeValidationError, "attemptId is required")
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixAnchorAttempt, []string{attemptID})
+	if err != nil {
+		return nil, internalError("failed to create anchor attempt key: %v", err)
+	}
+	attemptBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read anchor attempt: %v", err)
+	}
+	if attemptBytes == nil {
+		return nil, newError(ErrCodeAnchorAttemptNotFound, "%s does not exist", attemptID).with("attemptId", attemptID)
+	}
+	var attempt AnchorAttempt
+	if err := json.Unmarshal(attemptBytes, &attempt); err != nil {
+		return nil, internalError("failed to unmarshal anchor attempt: %v", err)
+	}
+	if attempt.Status != "PENDING" {
+		return nil, newError(ErrCodeAnchorAttemptInvalidState, "anchor attempt %s is %s", attemptID, attempt.Status)
+	}
+	return &attempt, nil
+}
+
+// putAnchorAttempt writes an anchor attempt under its key.
+func putAnchorAttempt(ctx contractapi.TransactionContextInterface, attempt *AnchorAttempt) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixAnchorAttempt, []string{attempt.AttemptID})
+	if err != nil {
+		return internalError("failed to create anchor attempt key: %v", err)
+	}
+	attemptBytes, err := canonicalMarshal(attempt)
+	if err != nil {
+		return internalError("failed to marshal anchor attempt: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, attemptBytes); err != nil {
+		return internalError("failed to write anchor attempt: %v", err)
+	}
+	return nil
+}
+
+func emitAnchorAttemptEvent(ctx contractapi.TransactionContextInterface, eventType string, attempt *AnchorAttempt) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := AnchorAttemptEvent{
+		Type:          eventType,
+		AttemptID:     attempt.AttemptID,
+		StateCode:     attempt.StateCode,
+		StateRoot:     attempt.StateRoot,
+		FailureReason: attempt.FailureReason,
+		FabricTxID:    ctx.GetStub().GetTxID(),
+		Timestamp:     time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		ChannelID:     ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, eventType, event)
+}
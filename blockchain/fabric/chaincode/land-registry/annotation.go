@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LAND RECORD ANNOTATIONS
+// ============================================================
+// Groundwater regulation and rural credit both need to know whether a
+// parcel has a registered well or borewell and whether it is permitted.
+// Annotations record such fixtures on the land record itself. They are
+// informational only: they never block a transfer, they stay on the
+// record when it changes hands, and a split copies each one to the
+// sub-plots the split request assigns it to.
+
+// annotationTypes lists the accepted annotation types.
+var annotationTypes = map[string]bool{
+	"WELL":               true,
+	"BOREWELL":           true,
+	"SOLAR_INSTALLATION": true,
+	"TREE_PATTA":         true,
+}
+
+// annotationPermitStatuses lists the accepted permit statuses.
+var annotationPermitStatuses = map[string]bool{
+	"APPLIED":      true,
+	"GRANTED":      true,
+	"EXPIRED":      true,
+	"NOT_REQUIRED": true,
+}
+
+// AddAnnotation adds an annotation to a property, or replaces one if
+// annotationJSON names an existing annotationId. annotationJSON is a
+// LandAnnotation with type, permitStatus, permitRef (unless the permit
+// status is NOT_REQUIRED), documentHash and an optional description.
+// Only tehsildars with jurisdiction over the property can maintain
+// annotations. Returns the annotation ID. Emits ANNOTATION_ADDED or
+// ANNOTATION_UPDATED.
+func (s *LandRegistryContract) AddAnnotation(ctx contractapi.TransactionContextInterface, propertyID, annotationJSON string) (string, error) {
+	if err := requireRole(ctx, "tehsildar"); err != nil {
+		return "", err
+	}
+
+	var annotation LandAnnotation
+	if err := json.Unmarshal([]byte(annotationJSON), &annotation); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse annotation JSON: %v", err)
+	}
+	annotation.Type = strings.ToUpper(strings.TrimSpace(annotation.Type))
+	if !annotationTypes[annotation.Type] {
+		return "", newError(ErrCodeValidationError, "annotation type '%s' must be WELL, BOREWELL, SOLAR_INSTALLATION or TREE_PATTA", annotation.Type)
+	}
+	if !annotationPermitStatuses[annotation.PermitStatus] {
+		return "", newError(ErrCodeValidationError, "permitStatus '%s' must be APPLIED, GRANTED, EXPIRED or NOT_REQUIRED", annotation.PermitStatus)
+	}
+	annotation.PermitRef = strings.TrimSpace(annotation.PermitRef)
+	if annotation.PermitRef == "" && annotation.PermitStatus != "NOT_REQUIRED" {
+		return "", newError(ErrCodeValidationError, "permitRef is required unless permitStatus is NOT_REQUIRED")
+	}
+	if err := validateDocumentHash(annotation.DocumentHash, "documentHash"); err != nil {
+		return "", err
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return "", err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return "", err
+	}
+	if property.Status == "SPLIT" || property.Status == "MERGED" {
+		return "", newError(ErrCodePropertyNotActive, "cannot annotate property with status %s", property.Status)
+	}
+	if err := requireNotArchived(property); err != nil {
+		return "", err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	annotation.CopiedFrom = ""
+	annotation.AddedBy = getCallerID(ctx)
+	annotation.AddedAt = now
+	annotation.FabricTxID = txID
+
+	eventType := "ANNOTATION_ADDED"
+	if annotation.AnnotationID == "" {
+		annotation.AnnotationID = "ann_" + txID[:8]
+		property.Annotations = append(property.Annotations, annotation)
+	} else {
+		i := annotationIndex(property, annotation.AnnotationID)
+		if i < 0 {
+			return "", newError(ErrCodeAnnotationNotFound, "%s is not an annotation of property %s", annotation.AnnotationID, propertyID)
+		}
+		if err := deleteAnnotationIndex(ctx, property, &property.Annotations[i]); err != nil {
+			return "", err
+		}
+		annotation.CopiedFrom = property.Annotations[i].CopiedFrom
+		property.Annotations[i] = annotation
+		eventType = "ANNOTATION_UPDATED"
+	}
+	if err := putAnnotationIndex(ctx, property, &annotation); err != nil {
+		return "", err
+	}
+
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return "", err
+	}
+
+	event := AnnotationEvent{
+		Type:           eventType,
+		PropertyID:     propertyID,
+		AnnotationID:   annotation.AnnotationID,
+		AnnotationType: annotation.Type,
+		PermitStatus:   annotation.PermitStatus,
+		FabricTxID:     txID,
+		Timestamp:      now,
+		StateCode:      property.Location.StateCode,
+		ChannelID:      ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, eventType, event); err != nil {
+		return "", err
+	}
+	return annotation.AnnotationID, nil
+}
+
+// RemoveAnnotation removes an annotation from a property, e.g. when a
+// borewell is decommissioned. The removal stays in the record's history.
+// Only tehsildars with jurisdiction over the property can remove
+// annotations. Emits ANNOTATION_REMOVED.
+func (s *LandRegistryContract) RemoveAnnotation(ctx contractapi.TransactionContextInterface, propertyID, annotationID, reason string) error {
+	if err := requireRole(ctx, "tehsildar"); err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return err
+	}
+	i := annotationIndex(property, annotationID)
+	if i < 0 {
+		return newError(ErrCodeAnnotationNotFound, "%s is not an annotation of property %s", annotationID, propertyID)
+	}
+	removed := property.Annotations[i]
+	if err := deleteAnnotationIndex(ctx, property, &removed); err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	property.Annotations = append(property.Annotations[:i], property.Annotations[i+1:]...)
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+
+	event := AnnotationEvent{
+		Type:           "ANNOTATION_REMOVED",
+		PropertyID:     propertyID,
+		AnnotationID:   annotationID,
+		AnnotationType: removed.Type,
+		PermitStatus:   removed.PermitStatus,
+		Reason:         reason,
+		FabricTxID:     txID,
+		Timestamp:      now,
+		StateCode:      property.Location.StateCode,
+		ChannelID:      ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "ANNOTATION_REMOVED", event)
+}
+
+// QueryByAnnotationType returns the properties in a district that carry
+// an annotation of annotationType, once each. Properties the caller
+// cannot read are left out, as in QueryByLocation.
+func (s *LandRegistryContract) QueryByAnnotationType(ctx contractapi.TransactionContextInterface, stateCode, districtCode, annotationType string) ([]*LandRecord, error) {
+	if stateCode == "" || districtCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode and districtCode are required")
+	}
+	annotationType = strings.ToUpper(strings.TrimSpace(annotationType))
+	if !annotationTypes[annotationType] {
+		return nil, newError(ErrCodeValidationError, "annotation type '%s' must be WELL, BOREWELL, SOLAR_INSTALLATION or TREE_PATTA", annotationType)
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixAnnotationType, []string{stateCode, districtCode, annotationType})
+	if err != nil {
+		return nil, internalError("failed to query annotation index: %v", err)
+	}
+	defer iterator.Close()
+
+	properties := []*LandRecord{}
+	seen := map[string]bool{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate annotation index: %v", err)
+		}
+		propertyID := string(kv.Value)
+		if seen[propertyID] {
+			continue
+		}
+		seen[propertyID] = true
+		property, err := s.GetProperty(ctx, propertyID)
+		if err != nil {
+			continue
+		}
+		properties = append(properties, property)
+	}
+	return properties, nil
+}
+
+// annotationIndex returns the position of annotationID among a
+// property's annotations, or -1.
+func annotationIndex(property *LandRecord, annotationID string) int {
+	for i := range property.Annotations {
+		if property.Annotations[i].AnnotationID == annotationID {
+			return i
+		}
+	}
+	return -1
+}
+
+// assignAnnotations returns the annotations each sub-plot of a split
+// receives: copies of the parent's annotations listed in its
+// annotationIds. Every parent annotation must go to at least one
+// sub-plot.
+func assignAnnotations(property *LandRecord, splits []SplitRequest) ([][]LandAnnotation, error) {
+	assigned := make([][]LandAnnotation, len(splits))
+	used := map[string]bool{}
+	for i, split := range splits {
+		for _, annotationID := range split.AnnotationIDs {
+			j := annotationIndex(property, annotationID)
+			if j < 0 {
+				return nil, newError(ErrCodeAnnotationNotFound, "split[%d]: %s is not an annotation of property %s", i, annotationID, property.PropertyID)
+			}
+			annotation := property.Annotations[j]
+			annotation.CopiedFrom = property.PropertyID
+			assigned[i] = append(assigned[i], annotation)
+			used[annotationID] = true
+		}
+	}
+	for _, annotation := range property.Annotations {
+		if !used[annotation.AnnotationID] {
+			return nil, newError(ErrCodeValidationError, "annotation %s of property %s is not assigned to any sub-plot", annotation.AnnotationID, property.PropertyID)
+		}
+	}
+	return assigned, nil
+}
+
+// mergedAnnotations returns copies of the sources' annotations for the
+// merged record. An annotation already copied from a common parent is
+// kept once.
+func mergedAnnotations(sources []*LandRecord) []LandAnnotation {
+	var annotations []LandAnnotation
+	seen := map[string]bool{}
+	for _, source := range sources {
+		for _, annotation := range source.Annotations {
+			if seen[annotation.AnnotationID] {
+				continue
+			}
+			seen[annotation.AnnotationID] = true
+			annotation.CopiedFrom = source.PropertyID
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// createAnnotationIndexKey builds the annotation type index key:
+// ANNOTATION_TYPE~{stateCode}~{districtCode}~{type}~{propertyId}~{annotationId}.
+func createAnnotationIndexKey(ctx contractapi.TransactionContextInterface, property *LandRecord, annotation *LandAnnotation) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixAnnotationType, []string{
+		property.Location.StateCode, property.Location.DistrictCode, annotation.Type, property.PropertyID, annotation.AnnotationID,
+	})
+	if err != nil {
+		return "", internalError("failed to create annotation index key: %v", err)
+	}
+	return key, nil
+}
+
+// putAnnotationIndex adds an annotation to the annotation type index.
+func putAnnotationIndex(ctx contractapi.TransactionContextInterface, property *LandRecord, annotation *LandAnnotation) error {
+	key, err := createAnnotationIndexKey(ctx, property, annotation)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, []byte(property.PropertyID)); err != nil {
+		return internalError("failed to write annotation index: %v", err)
+	}
+	return nil
+}
+
+// deleteAnnotationIndex removes an annotation from the annotation type
+// index.
+func deleteAnnotationIndex(ctx contractapi.TransactionContextInterface, property *LandRecord, annotation *LandAnnotation) error {
+	key, err := createAnnotationIndexKey(ctx, property, annotation)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return internalError("failed to remove annotation index: %v", err)
+	}
+	return nil
+}
+
+// putAnnotationIndexes indexes every annotation of a property.
+func putAnnotationIndexes(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	for i := range property.Annotations {
+		if err := putAnnotationIndex(ctx, property, &property.Annotations[i]); err != nil {
+			return errorAt(fmt.Sprintf("annotation[%d]", i), err)
+		}
+	}
+	return nil
+}
+
+// deleteAnnotationIndexes removes every annotation of a property from
+// the index.
+func deleteAnnotationIndexes(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	for i := range property.Annotations {
+		if err := deleteAnnotationIndex(ctx, property, &property.Annotations[i]); err != nil {
+			return errorAt(fmt.Sprintf("annotation[%d]", i), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// ARCHIVAL
+// ============================================================
+
+// ArchiveProperty marks a property that has ceased to exist as a
+// registrable unit (submerged, acquired for a road) as ARCHIVED. The
+// property must be ACTIVE with no active disputes or encumbrances. The
+// owner and location index entries are removed so the parcel no longer
+// shows up in owner or village listings; the land key and its history
+// are kept (Rule 9). Archived records accept no further transfers,
+// mutations or encumbrances. Only admins in the property's state can
+// archive. Emits PROPERTY_ARCHIVED.
+func (s *LandRegistryContract) ArchiveProperty(ctx contractapi.TransactionContextInterface, propertyID, reason, orderRef string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required to archive a property")
+	}
+	if orderRef == "" {
+		return newError(ErrCodeValidationError, "orderRef is required to archive a property")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return err
+	}
+	if property.Status != "ACTIVE" {
+		return newError(ErrCodePropertyNotActive, "cannot archive property with status %s", property.Status)
+	}
+
+	disputes, err := getActiveDisputes(ctx, propertyID)
+	if err != nil {
+		return internalError("failed to check disputes: %v", err)
+	}
+	if property.DisputeStatus != "CLEAR" || len(disputes) > 0 {
+		return newError(ErrCodeLandDisputed, "cannot archive property %s with active disputes", propertyID)
+	}
+	hasEncumbrance, err := hasActiveEncumbrances(ctx, propertyID)
+	if err != nil {
+		return internalError("failed to check encumbrances: %v", err)
+	}
+	if hasEncumbrance {
+		return newError(ErrCodeLandEncumbered, "cannot archive property %s with active encumbrances", propertyID)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	if err := setPropertyStatus(ctx, property, "ARCHIVED", reason); err != nil {
+		return err
+	}
+	property.Archival = &ArchivalInfo{
+		Reason:     reason,
+		OrderRef:   orderRef,
+		ArchivedBy: getCallerID(ctx),
+		ArchivedAt: now,
+	}
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+
+	for _, owner := range property.CurrentOwner.Owners {
+		if err := deleteOwnerIndex(ctx, owner.AadhaarHash, propertyID); err != nil {
+			return internalError("failed to remove owner index: %v", err)
+		}
+		if err := deleteEntityIndex(ctx, owner, propertyID); err != nil {
+			return internalError("failed to remove entity index: %v", err)
+		}
+	}
+	if err := deleteLocationIndex(ctx, property.Location, propertyID); err != nil {
+		return internalError("failed to remove location index: %v", err)
+	}
+
+	event := PropertyArchivedEvent{
+		Type:       "PROPERTY_ARCHIVED",
+		PropertyID: propertyID,
+		Reason:     reason,
+		OrderRef:   orderRef,
+		FabricTxID: txID,
+		Timestamp:  now,
+		StateCode:  property.Location.StateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "PROPERTY_ARCHIVED", event)
+}
+
+// requireNotArchived rejects any change to an archived property.
+func requireNotArchived(property *LandRecord) error {
+	if property.Status == "ARCHIVED" {
+		return newError(ErrCodePropertyArchived, "property %s was archived and accepts no further changes", property.PropertyID)
+	}
+	return nil
+}
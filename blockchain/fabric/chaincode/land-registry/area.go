@@ -0,0 +1,127 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// ============================================================
+// AREA UNITS
+// ============================================================
+
+// Area units accepted in Area.Unit and Area.LocalUnit.
+const (
+	AreaUnitSqMeters = "SQ_METERS"
+	AreaUnitAcres    = "ACRES"
+	AreaUnitHectares = "HECTARES"
+	AreaUnitGuntha   = "GUNTHA"
+	AreaUnitCent     = "CENT"
+	AreaUnitKanal    = "KANAL"
+	AreaUnitMarla    = "MARLA"
+	AreaUnitBigha    = "BIGHA"
+)
+
+// areaUnitSqMeters gives the fixed size of each unit in square meters.
+// Bigha varies by state and comes from the registry settings instead.
+var areaUnitSqMeters = map[string]float64{
+	AreaUnitSqMeters: 1,
+	AreaUnitAcres:    4046.8564224,
+	AreaUnitHectares: 10000,
+	AreaUnitGuntha:   101.17141056, // 1/40 acre
+	AreaUnitCent:     40.468564224, // 1/100 acre
+	AreaUnitKanal:    505.8570528,  // 1/8 acre
+	AreaUnitMarla:    25.29285264,  // 1/20 kanal
+}
+
+// areaUnitAliases maps common spellings to the canonical unit names.
+var areaUnitAliases = map[string]string{
+	"":             AreaUnitSqMeters,
+	"SQ_METER":     AreaUnitSqMeters,
+	"SQM":          AreaUnitSqMeters,
+	"ACRE":         AreaUnitAcres,
+	"HECTARE":      AreaUnitHectares,
+	"HA":           AreaUnitHectares,
+	"GUNTHAS":      AreaUnitGuntha,
+	"CENTS":        AreaUnitCent,
+	"KANALS":       AreaUnitKanal,
+	"MARLAS":       AreaUnitMarla,
+	"BIGHAS":       AreaUnitBigha,
+	"SQUARE_METER": AreaUnitSqMeters,
+}
+
+// areaMismatchTolerance is the largest relative difference allowed
+// between an area's metric value and its local-unit value.
+const areaMismatchTolerance = 0.02
+
+// normalizeAreaUnit returns the canonical name of an area unit.
+func normalizeAreaUnit(unit string) (string, error) {
+	u := strings.ToUpper(strings.TrimSpace(unit))
+	if alias, ok := areaUnitAliases[u]; ok {
+		u = alias
+	}
+	if _, ok := areaUnitSqMeters[u]; ok || u == AreaUnitBigha {
+		return u, nil
+	}
+	return "", newError(ErrCodeInvalidAreaUnit, "unknown area unit '%s'", unit)
+}
+
+// ConvertArea converts value from one area unit to another. bighaSqMeters
+// is the state's bigha size in square meters (from the registry
+// settings) and is only needed when either unit is BIGHA.
+func ConvertArea(value float64, fromUnit, toUnit string, bighaSqMeters float64) (float64, error) {
+	fromFactor, err := areaUnitFactor(fromUnit, bighaSqMeters)
+	if err != nil {
+		return 0, err
+	}
+	toFactor, err := areaUnitFactor(toUnit, bighaSqMeters)
+	if err != nil {
+		return 0, err
+	}
+	return value * fromFactor / toFactor, nil
+}
+
+// areaUnitFactor returns the size of one unit in square meters.
+func areaUnitFactor(unit string, bighaSqMeters float64) (float64, error) {
+	u, err := normalizeAreaUnit(unit)
+	if err != nil {
+		return 0, err
+	}
+	if u == AreaUnitBigha {
+		if bighaSqMeters <= 0 {
+			return 0, newError(ErrCodeAreaUnitNotConfigured, "bigha size is not set in the registry settings for this state")
+		}
+		return bighaSqMeters, nil
+	}
+	return areaUnitSqMeters[u], nil
+}
+
+// validateAreaUnits checks that an area's local value describes the
+// same area as its metric value, within areaMismatchTolerance. Areas
+// without a local value are not cross-checked. The error reports both
+// figures in square meters.
+func validateAreaUnits(area Area, bighaSqMeters float64) error {
+	if area.LocalVal == 0 && area.LocalUnit == "" {
+		return nil
+	}
+	if area.Value <= 0 {
+		return newError(ErrCodeValidationError, "area value must be positive")
+	}
+	if area.LocalVal <= 0 {
+		return newError(ErrCodeValidationError, "area localValue must be positive when localUnit is set")
+	}
+
+	metric, err := ConvertArea(area.Value, area.Unit, AreaUnitSqMeters, bighaSqMeters)
+	if err != nil {
+		return err
+	}
+	local, err := ConvertArea(area.LocalVal, area.LocalUnit, AreaUnitSqMeters, bighaSqMeters)
+	if err != nil {
+		return err
+	}
+
+	if math.Abs(local-metric)/metric > areaMismatchTolerance {
+		return newError(ErrCodeAreaUnitMismatch, "%v %s is %.2f sq m but %v %s converts to %.2f sq m (tolerance %.0f%%)",
+			area.Value, area.Unit, metric, area.LocalVal, area.LocalUnit, local, areaMismatchTolerance*100)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// ACCESS AUDIT LOG
+// ============================================================
+// A denied invocation fails and never commits, so its error return leaves
+// no trace on the ledger. The audit log gives security review a queryable
+// record instead: sensitive operations write an entry alongside their own
+// changes, and the API middleware reports denials via RecordAccessAttempt.
+
+// maxAuditPageSize caps QueryAuditLog pages.
+const maxAuditPageSize = 500
+
+// RecordAccessAttempt writes a DENIED audit entry for an invocation that
+// failed a role, state or jurisdiction check. attemptJSON is an
+// AccessAttempt; callerId is the identity that was refused. Only admins
+// (the middleware's service identity) can record attempts.
+func (s *LandRegistryContract) RecordAccessAttempt(ctx contractapi.TransactionContextInterface, attemptJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	var attempt AccessAttempt
+	if err := json.Unmarshal([]byte(attemptJSON), &attempt); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse access attempt JSON: %v", err)
+	}
+	if attempt.CallerID == "" || attempt.Function == "" {
+		return newError(ErrCodeValidationError, "callerId and function are required")
+	}
+	if attempt.Reason == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+
+	return writeAuditEntry(ctx, attempt.CallerID, attempt.Function, attempt.Target, "DENIED", attempt.Reason)
+}
+
+// recordAudit writes a SUCCESS audit entry for the calling identity's
+// invocation of function on target.
+func recordAudit(ctx contractapi.TransactionContextInterface, function, target string) error {
+	return writeAuditEntry(ctx, getCallerID(ctx), function, target, "SUCCESS", "")
+}
+
+// writeAuditEntry stores an audit entry under AUDIT~{date}~{txId}.
+func writeAuditEntry(ctx contractapi.TransactionContextInterface, callerID, function, target, outcome, reason string) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).UTC()
+	txID := ctx.GetStub().GetTxID()
+
+	entry := AuditEntry{
+		DocType:    "auditEntry",
+		Date:       now.Format("2006-01-02"),
+		FabricTxID: txID,
+		CallerID:   callerID,
+		Function:   function,
+		Target:     target,
+		Outcome:    outcome,
+		Reason:     reason,
+		RecordedBy: getCallerID(ctx),
+		Timestamp:  now.Format(time.RFC3339),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixAudit, []string{entry.Date, txID})
+	if err != nil {
+		return internalError("failed to create audit key: %v", err)
+	}
+	entryBytes, err := canonicalMarshal(entry)
+	if err != nil {
+		return internalError("failed to marshal audit entry: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, entryBytes); err != nil {
+		return internalError("failed to write audit entry: %v", err)
+	}
+	return nil
+}
+
+// QueryAuditLog returns audit entries dated fromDate to toDate inclusive
+// (YYYY-MM-DD), pageSize at a time. Pass an empty bookmark for the first
+// page and the returned bookmark for the next. Only admins can read the
+// audit log.
+func (s *LandRegistryContract) QueryAuditLog(ctx contractapi.TransactionContextInterface, fromDate, toDate string, pageSize int, bookmark string) (*AuditLogPage, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+
+	from, err := time.Parse("2006-01-02", fromDate)
+	if err != nil {
+		return nil, newError(ErrCodeValidationError, "fromDate must be YYYY-MM-DD")
+	}
+	to, err := time.Parse("2006-01-02", toDate)
+	if err != nil {
+		return nil, newError(ErrCodeValidationError, "toDate must be YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return nil, newError(ErrCodeValidationError, "toDate %s is before fromDate %s", toDate, fromDate)
+	}
+	if pageSize <= 0 || pageSize > maxAuditPageSize {
+		return nil, newError(ErrCodeValidationError, "pageSize must be between 1 and %d", maxAuditPageSize)
+	}
+
+	queryString := fmt.Sprintf(`{"selector":{"docType":"auditEntry","date":{"$gte":"%s","$lte":"%s"}}}`, fromDate, toDate)
+	iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(queryString, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, internalError("failed to query audit log: %v", err)
+	}
+	defer iterator.Close()
+
+	page := &AuditLogPage{Entries: []*AuditEntry{}}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate audit log: %v", err)
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(kv.Value, &entry); err != nil {
+			return nil, internalError("failed to unmarshal audit entry: %v", err)
+		}
+		page.Entries = append(page.Entries, &entry)
+	}
+	page.Count = metadata.FetchedRecordsCount
+	if int(page.Count) == pageSize {
+		page.Bookmark = metadata.Bookmark
+	}
+	return page, nil
+}
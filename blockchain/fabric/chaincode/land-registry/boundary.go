@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// BOUNDARY CONFLICTS
+// ============================================================
+// Two parcels whose polygons overlap are a sign of double allotment.
+// Overlaps do not block registration; they are recorded for the survey
+// team to investigate.
+
+// defaultBoundaryOverlapThresholdSqM is the overlap below which two
+// parcels are treated as merely adjacent (survey and rounding noise).
+const defaultBoundaryOverlapThresholdSqM = 1.0
+
+// checkBoundaryOverlaps compares property's GeoJSON with every other
+// parcel in the same village and records a BOUNDARY_CONFLICT for each
+// overlap larger than the state's threshold. The other parcel is flagged
+// and written back here; property is only flagged in memory, since the
+// caller writes it. Returns the IDs of the conflicts found.
+func (s *LandRegistryContract) checkBoundaryOverlaps(ctx contractapi.TransactionContextInterface, property *LandRecord, now, txID string) ([]string, error) {
+	polygons := geoJSONPolygons(property.Boundaries.GeoJSON)
+	if len(polygons) == 0 {
+		return nil, nil
+	}
+	box := polygonsBoundingBox(polygons)
+	originLon, originLat := (box.minLon+box.maxLon)/2, (box.minLat+box.maxLat)/2
+
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	threshold := settings.BoundaryOverlapThresholdSqM
+	if threshold <= 0 {
+		threshold = defaultBoundaryOverlapThresholdSqM
+	}
+
+	loc := property.Location
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixLocationIndex, []string{loc.StateCode, loc.DistrictCode, loc.TehsilCode, loc.VillageCode})
+	if err != nil {
+		return nil, internalError("failed to query location index: %v", err)
+	}
+	defer iterator.Close()
+
+	var conflictIDs []string
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate location index: %v", err)
+		}
+		otherID := string(kv.Value)
+		if otherID == property.PropertyID {
+			continue
+		}
+		other, err := s.GetProperty(ctx, otherID)
+		if err != nil {
+			return nil, err
+		}
+		// Split and merged parents legitimately cover their successors
+		if other.Status == "SPLIT" || other.Status == "MERGED" || other.Status == "ARCHIVED" {
+			continue
+		}
+		otherPolygons := geoJSONPolygons(other.Boundaries.GeoJSON)
+		if len(otherPolygons) == 0 || !box.intersects(polygonsBoundingBox(otherPolygons)) {
+			continue
+		}
+		overlap := polygonOverlapArea(polygons, otherPolygons, originLon, originLat)
+		if overlap <= threshold {
+			continue
+		}
+
+		conflict, err := recordBoundaryConflict(ctx, property, other.PropertyID, overlap, now, txID)
+		if err != nil {
+			return nil, err
+		}
+		conflictIDs = append(conflictIDs, conflict.ConflictID)
+
+		property.BoundaryConflict = true
+		if !other.BoundaryConflict {
+			other.BoundaryConflict = true
+			other.UpdatedAt = now
+			other.FabricTxID = txID
+			if err := putLandRecord(ctx, other); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return conflictIDs, nil
+}
+
+// recordBoundaryConflict creates or refreshes the conflict record for a
+// pair of parcels. The key is built from the sorted pair, so detecting
+// the same overlap again updates the existing record.
+func recordBoundaryConflict(ctx contractapi.TransactionContextInterface, property *LandRecord, otherID string, overlap float64, now, txID string) (*BoundaryConflictRecord, error) {
+	first, second := property.PropertyID, otherID
+	if second < first {
+		first, second = second, first
+	}
+	loc := property.Location
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixBoundaryConflict, []string{loc.StateCode, loc.DistrictCode, first, second})
+	if err != nil {
+		return nil, internalError("failed to create boundary conflict key: %v", err)
+	}
+	existingBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read boundary conflict: %v", err)
+	}
+
+	var conflict BoundaryConflictRecord
+	if existingBytes != nil {
+		if err := json.Unmarshal(existingBytes, &conflict); err != nil {
+			return nil, internalError("failed to unmarshal boundary conflict: %v", err)
+		}
+	} else {
+		conflict = BoundaryConflictRecord{
+			DocType:      "boundaryConflict",
+			ConflictID:   fmt.Sprintf("bcf_%s_%s", txID[:8], otherID),
+			StateCode:    loc.StateCode,
+			DistrictCode: loc.DistrictCode,
+			TehsilCode:   loc.TehsilCode,
+			VillageCode:  loc.VillageCode,
+			PropertyIDs:  []string{first, second},
+			Status:       "OPEN",
+			CreatedAt:    now,
+		}
+	}
+	conflict.OverlapSqMeters = overlap
+	conflict.DetectedBy = property.PropertyID
+	conflict.DetectedAt = now
+	conflict.FabricTxID = txID
+
+	conflictBytes, err := canonicalMarshal(conflict)
+	if err != nil {
+		return nil, internalError("failed to marshal boundary conflict: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, conflictBytes); err != nil {
+		return nil, internalError("failed to write boundary conflict: %v", err)
+	}
+	return &conflict, nil
+}
+
+// QueryBoundaryConflicts returns the boundary conflicts recorded in a
+// district, for the survey team to investigate.
+func (s *LandRegistryContract) QueryBoundaryConflicts(ctx contractapi.TransactionContextInterface, stateCode, districtCode string) ([]*BoundaryConflictRecord, error) {
+	if stateCode == "" || districtCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode and districtCode are required")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixBoundaryConflict, []string{stateCode, districtCode})
+	if err != nil {
+		return nil, internalError("failed to query boundary conflicts: %v", err)
+	}
+	defer iterator.Close()
+
+	conflicts := []*BoundaryConflictRecord{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate boundary conflicts: %v", err)
+		}
+		var conflict BoundaryConflictRecord
+		if err := json.Unmarshal(kv.Value, &conflict); err != nil {
+			return nil, internalError("failed to unmarshal boundary conflict: %v", err)
+		}
+		conflicts = append(conflicts, &conflict)
+	}
+	return conflicts, nil
+}
+
+// ============================================================
+// SPLIT GEOMETRY
+// ============================================================
+// Declared child areas can sum to the parent's while the drawn plots
+// overlap or spill outside it. When the parent and a child both carry
+// GeoJSON, the child's polygon is checked against its declared area,
+// its siblings and the parent boundary.
+
+// splitPolygonAreaTolerance is how far a child's polygon area may differ
+// from its declared area.
+const splitPolygonAreaTolerance = 0.05
+
+// Digitised child boundaries rarely trace the parent's edge exactly, so
+// overlaps and spill-over up to the larger of these are ignored.
+const (
+	splitGeometryBufferSqM   = 1.0
+	splitGeometryBufferRatio = 0.005
+)
+
+// validateSplitGeometry checks each child's GeoJSON against the parent's:
+// polygon area within 5% of the declared area, no overlap with another
+// child, and contained within the parent. Children without GeoJSON, or a
+// parent without it, are left to the numeric area check.
+func validateSplitGeometry(parent *LandRecord, splits []SplitRequest, bighaSqMeters float64) error {
+	parentPolygons := geoJSONPolygons(parent.Boundaries.GeoJSON)
+	if len(parentPolygons) == 0 {
+		return nil
+	}
+	box := polygonsBoundingBox(parentPolygons)
+	originLon, originLat := (box.minLon+box.maxLon)/2, (box.minLat+box.maxLat)/2
+
+	children := make([][][][][]float64, len(splits))
+	areas := make([]float64, len(splits))
+	for i, split := range splits {
+		polygons := geoJSONPolygons(split.Boundaries.GeoJSON)
+		if len(polygons) == 0 {
+			continue
+		}
+		children[i] = polygons
+		areas[i] = polygonArea(polygons, originLon, originLat)
+
+		declared, err := ConvertArea(split.Area.Value, split.Area.Unit, AreaUnitSqMeters, bighaSqMeters)
+		if err != nil {
+			return errorAt(fmt.Sprintf("split[%d]", i), err)
+		}
+		if declared <= 0 || math.Abs(areas[i]-declared)/declared > splitPolygonAreaTolerance {
+			return newError(ErrCodeAreaMismatch, "split[%d]: polygon area %.2f sq m differs from declared area %.2f sq m by more than %.0f%%",
+				i, areas[i], declared, splitPolygonAreaTolerance*100)
+		}
+
+		outside := areas[i] - polygonOverlapArea(polygons, parentPolygons, originLon, originLat)
+		if outside > splitGeometryBuffer(areas[i]) {
+			return newError(ErrCodeSplitOutsideParent, "split[%d]: %.2f sq m of %s lies outside parent %s",
+				i, outside, split.NewPropertyID, parent.PropertyID)
+		}
+	}
+
+	for i := range children {
+		if children[i] == nil {
+			continue
+		}
+		iBox := polygonsBoundingBox(children[i])
+		for j := i + 1; j < len(children); j++ {
+			if children[j] == nil || !iBox.intersects(polygonsBoundingBox(children[j])) {
+				continue
+			}
+			overlap := polygonOverlapArea(children[i], children[j], originLon, originLat)
+			if overlap > splitGeometryBuffer(math.Min(areas[i], areas[j])) {
+				return newError(ErrCodeSplitOverlap, "split[%d]: %s overlaps split[%d] %s by %.2f sq m",
+					i, splits[i].NewPropertyID, j, splits[j].NewPropertyID, overlap)
+			}
+		}
+	}
+	return nil
+}
+
+// splitGeometryBuffer returns the tolerated overlap or spill-over for a
+// child plot of the given area.
+func splitGeometryBuffer(area float64) float64 {
+	return math.Max(splitGeometryBufferSqM, area*splitGeometryBufferRatio)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// registerParcel registers a TS-HYD-SRN-GCB property bounded by ring.
+func (l *testLedger) registerParcel(registrar *testIdentity, surveyNo string, owner int, ring [][]float64) string {
+	l.t.Helper()
+	coordinates, err := json.Marshal([][][]float64{ring})
+	if err != nil {
+		l.t.Fatalf("marshal ring: %v", err)
+	}
+	property := testProperty(surveyNo, owner)
+	property.Boundaries.GeoJSON = GeoJSON{Type: "Polygon", Coordinates: coordinates}
+	l.registerTestProperty(registrar, property)
+	return property.PropertyID
+}
+
+// boundaryConflicts lists the conflicts recorded in TS-HYD.
+func (l *testLedger) boundaryConflicts() []*BoundaryConflictRecord {
+	l.t.Helper()
+	var conflicts []*BoundaryConflictRecord
+	l.mustSubmit(newTestIdentity(l.t, "SurveyOrgMSP", "admin", "TS"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		conflicts, err = l.contract.QueryBoundaryConflicts(ctx, "TS", "HYD")
+		return err
+	})
+	return conflicts
+}
+
+func TestOverlappingParcelsAreFlagged(t *testing.T) {
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+
+	first := ledger.registerParcel(registrar, "10", 1, rectRing(testLon, testLat, testLon+0.001, testLat+0.001))
+	second := ledger.registerParcel(registrar, "11", 2, rectRing(testLon+0.0005, testLat, testLon+0.0015, testLat+0.001))
+	var listed []string
+	if err := json.Unmarshal(ledger.eventFields("PROPERTY_REGISTERED")["boundaryConflicts"], &listed); err != nil {
+		t.Fatalf("PROPERTY_REGISTERED boundaryConflicts: %v", err)
+	}
+
+	if !ledger.readProperty(first).BoundaryConflict || !ledger.readProperty(second).BoundaryConflict {
+		t.Fatal("both parcels should carry boundaryConflict")
+	}
+	conflicts := ledger.boundaryConflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("got %d conflicts, want 1", len(conflicts))
+	}
+	conflict := conflicts[0]
+	if len(conflict.PropertyIDs) != 2 || conflict.PropertyIDs[0] != first || conflict.PropertyIDs[1] != second {
+		t.Fatalf("conflict names %v, want [%s %s]", conflict.PropertyIDs, first, second)
+	}
+	// The detector projects about the pair's centre, not testLat
+	if want := rectArea(0.0005, 0.001); math.Abs(conflict.OverlapSqMeters-want) > want*1e-5 {
+		t.Fatalf("overlap = %f sq m, want %f", conflict.OverlapSqMeters, want)
+	}
+	if conflict.Status != "OPEN" || conflict.DetectedBy != second {
+		t.Fatalf("conflict status %s detected by %s, want OPEN by %s", conflict.Status, conflict.DetectedBy, second)
+	}
+	if len(listed) != 1 || listed[0] != conflict.ConflictID {
+		t.Fatalf("event lists %v, want [%s]", listed, conflict.ConflictID)
+	}
+}
+
+func TestAdjacentParcelsAreNotFlagged(t *testing.T) {
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+
+	first := ledger.registerParcel(registrar, "10", 1, rectRing(testLon, testLat, testLon+0.001, testLat+0.001))
+	second := ledger.registerParcel(registrar, "11", 2, rectRing(testLon+0.001, testLat, testLon+0.002, testLat+0.001))
+
+	if ledger.readProperty(first).BoundaryConflict || ledger.readProperty(second).BoundaryConflict {
+		t.Fatal("parcels sharing an edge should not be flagged")
+	}
+	if conflicts := ledger.boundaryConflicts(); len(conflicts) != 0 {
+		t.Fatalf("got %d conflicts, want none", len(conflicts))
+	}
+}
+
+func TestBoundaryOverlapThreshold(t *testing.T) {
+	// The corners overlap by 0.0001° square, about 117 sq m
+	corner := rectRing(testLon+0.0009, testLat+0.0009, testLon+0.0019, testLat+0.0019)
+	tests := []struct {
+		name      string
+		threshold float64
+		flagged   bool
+	}{
+		{"default threshold", 0, true},
+		{"threshold below the overlap", 100, true},
+		{"threshold above the overlap", 200, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			if tc.threshold > 0 {
+				ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", BoundaryOverlapThresholdSqM: tc.threshold})
+			}
+			registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+			ledger.registerParcel(registrar, "10", 1, rectRing(testLon, testLat, testLon+0.001, testLat+0.001))
+			second := ledger.registerParcel(registrar, "11", 2, corner)
+
+			if got := ledger.readProperty(second).BoundaryConflict; got != tc.flagged {
+				t.Fatalf("boundaryConflict = %v, want %v", got, tc.flagged)
+			}
+			if got := len(ledger.boundaryConflicts()); (got == 1) != tc.flagged {
+				t.Fatalf("got %d conflicts, flagged = %v", got, tc.flagged)
+			}
+		})
+	}
+}
+
+func TestParcelsInOtherVillagesAreNotCompared(t *testing.T) {
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+
+	ring := rectRing(testLon, testLat, testLon+0.001, testLat+0.001)
+	ledger.registerParcel(registrar, "10", 1, ring)
+	coordinates, _ := json.Marshal([][][]float64{ring})
+	other := testProperty("10", 2)
+	other.PropertyID = "TS-HYD-SRN-MDP-10-0"
+	other.Location.VillageCode, other.Location.VillageName = "MDP", "Madhapur"
+	other.Boundaries.GeoJSON = GeoJSON{Type: "Polygon", Coordinates: coordinates}
+	ledger.registerTestProperty(registrar, other)
+
+	if ledger.readProperty(other.PropertyID).BoundaryConflict {
+		t.Fatal("a parcel in another village should not be compared")
+	}
+}
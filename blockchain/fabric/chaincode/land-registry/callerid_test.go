@@ -0,0 +1,100 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// fp8 returns the caller-ID fingerprint of id's certificate.
+func fp8(id *testIdentity) string {
+	sum := sha256.Sum256(id.cert.Raw)
+	return hex.EncodeToString(sum[:])[:callerFingerprintLength]
+}
+
+// callerIDOf returns getCallerID for a transaction submitted by id.
+func (l *testLedger) callerIDOf(id *testIdentity) string {
+	l.t.Helper()
+	var callerID string
+	l.mustSubmit(id, func(ctx contractapi.TransactionContextInterface) error {
+		callerID = getCallerID(ctx)
+		return nil
+	})
+	return callerID
+}
+
+func TestGetCallerID(t *testing.T) {
+	ledger := newTestLedger(t)
+
+	enrolled := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	enrolled.attrs["hf.EnrollmentID"] = "registrar-hyd-01"
+	unenrolled := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	bare := newTestIdentity(t, "TelanganaMSP", "", "")
+	noCert := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	noCert.cert = nil
+
+	tests := []struct {
+		name     string
+		identity *testIdentity
+		want     string
+	}{
+		{"enrollment ID attribute", enrolled, "TelanganaMSP:registrar:TS:registrar-hyd-01#" + fp8(enrolled)},
+		{"subject CN fallback", unenrolled, "TelanganaMSP:registrar:TS:registrar@telanganamsp#" + fp8(unenrolled)},
+		{"no role or state", bare, "TelanganaMSP:::@telanganamsp#" + fp8(bare)},
+		{"no certificate", noCert, "TelanganaMSP:registrar:TS:#"},
+	}
+	for _, tc := range tests {
+		if got := ledger.callerIDOf(tc.identity); got != tc.want {
+			t.Errorf("%s: caller ID = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCallerIDTellsOfficersApart(t *testing.T) {
+	ledger := newTestLedger(t)
+	first := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	second := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+
+	property := testProperty("142", 1)
+	ledger.registerTestProperty(first, property)
+	createdBy := ledger.readProperty(property.PropertyID).CreatedBy
+	if createdBy != ledger.callerIDOf(first) {
+		t.Fatalf("createdBy = %q, want the registering officer", createdBy)
+	}
+	if createdBy == ledger.callerIDOf(second) {
+		t.Fatalf("two registrars of the same office share caller ID %q", createdBy)
+	}
+	if got := parseCallerID(createdBy).Fingerprint; got != fp8(first) {
+		t.Fatalf("fingerprint = %q, want %q", got, fp8(first))
+	}
+}
+
+func TestParseCallerID(t *testing.T) {
+	tests := []struct {
+		callerID string
+		want     CallerIdentity
+	}{
+		{"TelanganaMSP:registrar:TS:registrar-hyd-01#9f86d081",
+			CallerIdentity{MspID: "TelanganaMSP", Role: "registrar", StateCode: "TS", EnrollmentID: "registrar-hyd-01", Fingerprint: "9f86d081"}},
+		// The enrollment ID runs from the third ":" to the last "#"
+		{"TelanganaMSP:registrar:TS:ops:team#2#9f86d081",
+			CallerIdentity{MspID: "TelanganaMSP", Role: "registrar", StateCode: "TS", EnrollmentID: "ops:team#2", Fingerprint: "9f86d081"}},
+		{"TelanganaMSP:registrar:TS:#",
+			CallerIdentity{MspID: "TelanganaMSP", Role: "registrar", StateCode: "TS"}},
+		{"TelanganaMSP:::#9f86d081",
+			CallerIdentity{MspID: "TelanganaMSP", Fingerprint: "9f86d081"}},
+		{"StateMSP:tehsildar:AP:tehsildar-gnt-02#9f86d081 on behalf of registrar-gnt-01",
+			CallerIdentity{MspID: "StateMSP", Role: "tehsildar", StateCode: "AP", EnrollmentID: "tehsildar-gnt-02", Fingerprint: "9f86d081", OnBehalfOf: "registrar-gnt-01"}},
+		// Older forms
+		{"TelanganaMSP:registrar:TS", CallerIdentity{MspID: "TelanganaMSP", Role: "registrar", StateCode: "TS"}},
+		{"TelanganaMSP", CallerIdentity{MspID: "TelanganaMSP"}},
+		{"system", CallerIdentity{MspID: "system"}},
+	}
+	for _, tc := range tests {
+		if got := parseCallerID(tc.callerID); got != tc.want {
+			t.Errorf("parseCallerID(%q) = %+v, want %+v", tc.callerID, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// ============================================================
+// CANONICAL JSON
+// ============================================================
+// Record hashes (state root leaves, event record hashes, the event
+// journal digest) are taken over stored JSON, so the bytes a record
+// marshals to must not change between chaincode versions. encoding/json
+// follows struct field order, which a harmless refactor can change.
+// Every PutState of a record therefore goes through canonicalMarshal:
+// object keys sorted, no insignificant whitespace, no HTML escaping,
+// integers as written and other numbers in Go's shortest float64 form.
+
+// canonicalMarshal returns the canonical JSON encoding of v.
+func canonicalMarshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalJSON(data)
+}
+
+// canonicalJSON re-encodes a JSON document in canonical form. Records
+// stored before canonical encoding are hashed through it so that their
+// hashes agree with a canonical rewrite.
+func canonicalJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, internalError("invalid JSON: %v", err)
+	}
+	value, err := canonicalNumbers(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	// Encode terminates the document with a newline.
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// canonicalNumbers rewrites every number in a decoded document in its
+// canonical form. Maps are left to the encoder, which sorts their keys.
+func canonicalNumbers(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			canonical, err := canonicalNumbers(field)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = canonical
+		}
+		return v, nil
+	case []interface{}:
+		for i, element := range v {
+			canonical, err := canonicalNumbers(element)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = canonical
+		}
+		return v, nil
+	case json.Number:
+		return canonicalNumber(v)
+	default:
+		return v, nil
+	}
+}
+
+// canonicalNumber keeps integer literals, so int64 amounts in paisa
+// never pass through float64, and writes other numbers as Go encodes a
+// float64. Negative zero is written 0 in either form.
+func canonicalNumber(n json.Number) (json.Number, error) {
+	literal := n.String()
+	if !strings.ContainsAny(literal, ".eE") {
+		if literal == "-0" {
+			return "0", nil
+		}
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return "", internalError("invalid number %s: %v", literal, err)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	encoded, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return json.Number(encoded), nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// updateGolden rewrites the golden files from the current encoding:
+// go test -run Canonical -update. A diff in them changes record hashes.
+var updateGolden = flag.Bool("update", false, "rewrite testdata golden files")
+
+// goldenFile compares got with testdata/canonical/name, or rewrites it
+// under -update.
+func goldenFile(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "canonical", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s changed:\n got %s\nwant %s", name, got, want)
+	}
+}
+
+// goldenLandRecord is a registered, mortgaged parcel with a polygon.
+func goldenLandRecord() *LandRecord {
+	property := testProperty("142", 1)
+	property.DocType = "landRecord"
+	property.SchemaVersion = 2
+	property.SubSurveyNumber = "A"
+	property.Location.PinCode = "500032"
+	property.Area = Area{Value: 2023.43, Unit: "SQ_METERS", LocalVal: 0.5, LocalUnit: "ACRES"}
+	property.Boundaries = Boundaries{
+		North: "Survey 141", South: "Road <20 ft>", East: "Survey 143 & canal", West: "Survey 140",
+		GeoJSON: GeoJSON{Type: "Polygon", Coordinates: json.RawMessage(`[[[78.3489, 17.4401], [78.3500E0, 17.4401], [78.35, 17.441], [78.3489, 17.4401]]]`)},
+	}
+	property.CurrentOwner.OwnershipType = "FREEHOLD"
+	property.CurrentOwner.AcquisitionType = "SALE"
+	property.CurrentOwner.AcquisitionDate = "2019-06-01"
+	property.CurrentOwner.Owners[0].FatherName = "Ramaiah"
+	property.LandUse = "AGRICULTURAL"
+	property.LandClassification = "DRY"
+	property.Status = "ACTIVE"
+	property.DisputeStatus = "CLEAR"
+	property.EncumbranceStatus = "ENCUMBERED"
+	property.TaxInfo = TaxInfo{AnnualLandRevenue: 120000, LastPaidDate: "2026-04-10", PaidUpToYear: "2026-27"}
+	property.RegistrationInfo = RegistrationInfo{RegistrationNumber: "TS/SRN/2019/1234", BookNumber: "I", SubRegistrarOffice: "Serilingampally", RegistrationDate: "2019-06-01"}
+	property.Provenance = Provenance{MergedFrom: []string{}, Sequence: 3}
+	property.FabricTxID = testTxID(7)
+	property.CreatedAt = "2027-01-05T09:00:00Z"
+	property.UpdatedAt = "2027-03-15T10:30:00Z"
+	property.CreatedBy = "TelanganaMSP:registrar:TS:registrar-hyd-01#9f86d081"
+	property.UpdatedBy = "SBIMSP:bank:TS:loans-hyd#2c26b46b"
+	property.DataQualityFlags = []string{"AREA_ROUNDED"}
+	return property
+}
+
+// goldenRecords are representative stored records by golden file name.
+func goldenRecords() map[string]interface{} {
+	seller := &testSigner{hash: testAadhaarHash(1), name: "Owner 1"}
+	buyer := &testSigner{hash: testAadhaarHash(2), name: "Owner 2"}
+	witnesses := []*testSigner{{hash: testAadhaarHash(3), name: "Owner 3"}, {hash: testAadhaarHash(4), name: "Owner 4"}}
+	transfer := testTransfer("TS-HYD-SRN-GCB-142-0", seller, buyer, witnesses...)
+	transfer.DocType = "transferRecord"
+	transfer.TransferID = "xfr_00000006"
+	transfer.Status = "SIGNATURES_COMPLETE"
+	transfer.CreatedAt = "2027-03-15T10:30:00Z"
+
+	return map[string]interface{}{
+		"land-record.json":     goldenLandRecord(),
+		"transfer-record.json": transfer,
+		"encumbrance-record.json": &EncumbranceRecord{
+			DocType: "encumbranceRecord", EncumbranceID: "enc_00000005", PropertyID: "TS-HYD-SRN-GCB-142-0",
+			Type: "MORTGAGE", Status: "ACTIVE",
+			Institution: Institution{Name: "State Bank of India", BranchCode: "SBIN0001234", MspID: "SBIMSP"},
+			Details:     EncumbranceDetails{LoanAccountNumber: "HL-0042", SanctionedAmount: 250000000, OutstandingAmount: 187654321, InterestRate: 850, StartDate: "2027-02-01", EndDate: "2047-01-31"},
+			CreatedAt:   "2027-02-01T11:00:00Z",
+			CreatedBy:   "SBIMSP:bank:TS:loans-hyd#2c26b46b",
+		},
+		"mutation-record.json": &MutationRecord{
+			DocType: "mutationRecord", MutationID: "mut_0000000c", PropertyID: "TS-HYD-SRN-GCB-142-0", Type: "SALE",
+			TransferID:    "xfr_00000006",
+			PreviousOwner: OwnerRef{AadhaarHash: testAadhaarHash(1), Name: "Owner 1"},
+			NewOwner:      OwnerRef{AadhaarHash: testAadhaarHash(2), Name: "Owner 2", OwnerType: "INDIVIDUAL"},
+			Status:        "AUTO_APPROVED", ApprovedBy: "system", ApprovedAt: "2027-03-15T10:30:00Z",
+			RevenueRecordUpdated: true,
+			CreatedAt:            "2027-03-15T10:30:00Z",
+		},
+	}
+}
+
+func TestCanonicalGoldenRecords(t *testing.T) {
+	for name, record := range goldenRecords() {
+		got, err := canonicalMarshal(record)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		goldenFile(t, name, got)
+	}
+}
+
+func TestCanonicalGoldenLandRecordHash(t *testing.T) {
+	ledger := newTestLedger(t)
+	var hash string
+	ledger.mustSubmit(newTestIdentity(t, "AdminOrgMSP", "admin", "IN"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		hash, err = landRecordHash(ctx, goldenLandRecord())
+		return err
+	})
+	goldenFile(t, "land-record.sha256", []byte(hash))
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"sorted keys", `{"b":1,"a":{"d":2,"c":3}}`, `{"a":{"c":3,"d":2},"b":1}`},
+		{"whitespace", "{ \"a\" : [ 1 , 2 ] ,\n\t\"b\" : \"x y\" }", `{"a":[1,2],"b":"x y"}`},
+		{"no HTML escaping", `{"a":"<b> & </b>"}`, `{"a":"<b> & </b>"}`},
+		{"unicode kept", `{"a":"తెలంగాణ"}`, `{"a":"తెలంగాణ"}`},
+		{"int64 beyond float64", `{"a":9007199254740993}`, `{"a":9007199254740993}`},
+		{"negative zero integer", `{"a":-0}`, `{"a":0}`},
+		{"negative zero float", `{"a":-0.0}`, `{"a":0}`},
+		{"float trailing zeros", `{"a":1.50}`, `{"a":1.5}`},
+		{"float integral", `{"a":2.0}`, `{"a":2}`},
+		{"exponent", `{"a":1E3,"b":1.5e-7,"c":2.5e21}`, `{"a":1000,"b":1.5e-7,"c":2.5e+21}`},
+		{"arrays keep order", `[3,1,{"b":1,"a":0.10}]`, `[3,1,{"a":0.1,"b":1}]`},
+	}
+	for _, tc := range tests {
+		got, err := canonicalJSON([]byte(tc.in))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: canonicalJSON(%s) = %s, want %s", tc.name, tc.in, got, tc.want)
+		}
+		again, _ := canonicalJSON(got)
+		if !bytes.Equal(again, got) {
+			t.Errorf("%s: not idempotent: %s then %s", tc.name, got, again)
+		}
+	}
+	if _, err := canonicalJSON([]byte(`{"a":`)); err == nil {
+		t.Error("canonicalJSON accepted invalid JSON")
+	}
+}
+
+func TestStoredRecordsAreCanonical(t *testing.T) {
+	ledger, registrar, transferID, signers := signingTestTransfer(t)
+	ledger.signTestTransfer(registrar, transferID, signers...)
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer: %v", err)
+	}
+	ledger.mortgageTestProperty("TS-HYD-SRN-GCB-142-0")
+
+	for key, value := range ledger.stub.State {
+		if !json.Valid(value) {
+			continue
+		}
+		canonical, err := canonicalJSON(value)
+		if err != nil {
+			t.Fatalf("%q: %v", key, err)
+		}
+		if !bytes.Equal(canonical, value) {
+			t.Errorf("%q is stored as %s, not canonically", key, value)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// CARRYING ENCUMBRANCES THROUGH SPLITS AND MERGES
+// ============================================================
+// A bank may consent to a split or merge on condition that its charge
+// follows the land. Rather than release-then-recreate, which leaves the
+// land unprotected in between, the charge is cloned onto the successor
+// parcels in the same transaction and the original is superseded.
+
+// encumbranceHandlingModes lists how SplitProperty and MergeProperties
+// treat active encumbrances on the parcels they retire.
+var encumbranceHandlingModes = map[string]bool{
+	"REQUIRE_CLEAR":      true,
+	"CARRY_WITH_CONSENT": true,
+}
+
+// parseEncumbranceHandling validates the handling mode (empty means
+// REQUIRE_CLEAR) and parses consentRefsJSON, a JSON object mapping each
+// encumbranceId to its holder's consent reference.
+func parseEncumbranceHandling(mode, consentRefsJSON string) (string, map[string]string, error) {
+	if mode == "" {
+		mode = "REQUIRE_CLEAR"
+	}
+	if !encumbranceHandlingModes[mode] {
+		return "", nil, newError(ErrCodeValidationError, "encumbranceHandling '%s' must be REQUIRE_CLEAR or CARRY_WITH_CONSENT", mode)
+	}
+
+	consentRefs := map[string]string{}
+	if consentRefsJSON != "" {
+		if err := json.Unmarshal([]byte(consentRefsJSON), &consentRefs); err != nil {
+			return "", nil, newError(ErrCodeInvalidInput, "failed to parse consent references JSON: %v", err)
+		}
+	}
+	return mode, consentRefs, nil
+}
+
+// checkEncumbrancesCarriable fails unless the active encumbrances on
+// propertyID can be dealt with under mode: none at all for REQUIRE_CLEAR,
+// or a consent reference from every holder for CARRY_WITH_CONSENT.
+// Court orders bind the parcel itself and must always be released first.
+// Easements run with the land and are always carried, so they need
+// neither.
+func checkEncumbrancesCarriable(encumbrances []*EncumbranceRecord, propertyID, mode string, consentRefs map[string]string) error {
+	var charges []*EncumbranceRecord
+	for _, enc := range encumbrances {
+		if enc.Type != "EASEMENT" {
+			charges = append(charges, enc)
+		}
+	}
+	if len(charges) == 0 {
+		return nil
+	}
+	if mode == "REQUIRE_CLEAR" {
+		return newError(ErrCodeLandEncumbered, "property %s has %d active encumbrance(s); release them or carry them with encumbranceHandling CARRY_WITH_CONSENT", propertyID, len(charges))
+	}
+	for _, enc := range charges {
+		if enc.Type == "COURT_ORDER" {
+			return newError(ErrCodeLandEncumbered, "court order encumbrance %s on %s must be released first", enc.EncumbranceID, propertyID)
+		}
+		if consentRefs[enc.EncumbranceID] == "" {
+			return newError(ErrCodeEncumbranceConsentRequired, "no consent reference from %s for encumbrance %s on %s", enc.Institution.Name, enc.EncumbranceID, propertyID)
+		}
+	}
+	return nil
+}
+
+// carryEncumbrances clones each encumbrance onto every target property
+// and marks the original SUPERSEDED. An easement listed in
+// easementTargets goes only to the targets given there, and its clones
+// are flagged needsConfirmation. Clones get new encumbrance IDs
+// derived from the transaction, with CarriedFrom and
+// CarriedFromEncumbranceID pointing back to the original. Returns one
+// ENCUMBRANCE_CARRIED entry per clone.
+func carryEncumbrances(ctx contractapi.TransactionContextInterface, encumbrances []*EncumbranceRecord, targets []string, easementTargets map[string][]string, consentRefs map[string]string, stateCode, now, txID string) ([]EncumbranceCarriedEvent, error) {
+	var carried []EncumbranceCarriedEvent
+	for _, enc := range encumbrances {
+		for _, target := range carryTargets(enc, targets, easementTargets) {
+			clone := *enc
+			clone.EncumbranceID = fmt.Sprintf("enc_%s_%d", txID[:8], len(carried)+1)
+			clone.PropertyID = target
+			clone.CarriedFrom = enc.PropertyID
+			clone.CarriedFromEncumbranceID = enc.EncumbranceID
+			clone.ConsentRef = consentRefs[enc.EncumbranceID]
+			clone.CreatedAt = now
+			clone.CreatedBy = getCallerID(ctx)
+			if enc.Easement != nil {
+				easement := *enc.Easement
+				easement.NeedsConfirmation = true
+				easement.ConfirmedBy = ""
+				easement.ConfirmedAt = ""
+				clone.Easement = &easement
+			}
+			if err := putEncumbrance(ctx, &clone); err != nil {
+				return nil, err
+			}
+			if err := countNewRecord(ctx, stateCode, "encumbranceRecord", clone.Status); err != nil {
+				return nil, err
+			}
+			if clone.CropLoan != nil {
+				if err := putCropLoanIndex(ctx, &clone); err != nil {
+					return nil, err
+				}
+			}
+			if clone.Easement != nil {
+				if err := putEasementIndex(ctx, &clone); err != nil {
+					return nil, err
+				}
+			}
+
+			carried = append(carried, EncumbranceCarriedEvent{
+				Type:                  "ENCUMBRANCE_CARRIED",
+				EncumbranceID:         clone.EncumbranceID,
+				OriginalEncumbranceID: enc.EncumbranceID,
+				FromPropertyID:        enc.PropertyID,
+				ToPropertyID:          target,
+				EncumbranceType:       enc.Type,
+				InstitutionName:       enc.Institution.Name,
+				InstitutionMspID:      enc.Institution.MspID,
+				ConsentRef:            clone.ConsentRef,
+				FabricTxID:            txID,
+				Timestamp:             now,
+				StateCode:             stateCode,
+				ChannelID:             ctx.GetStub().GetChannelID(),
+			})
+		}
+
+		enc.Status = "SUPERSEDED"
+		if err := putEncumbrance(ctx, enc); err != nil {
+			return nil, err
+		}
+	}
+	return carried, nil
+}
+
+// carryTargets returns the properties an encumbrance is carried onto:
+// its easementTargets entry if it has one, otherwise targets.
+func carryTargets(enc *EncumbranceRecord, targets []string, easementTargets map[string][]string) []string {
+	if easementTargets, ok := easementTargets[enc.EncumbranceID]; ok {
+		return easementTargets
+	}
+	return targets
+}
+
+// encumbersTarget reports whether carryEncumbrances will carry any of
+// encumbrances onto target, one of its targets.
+func encumbersTarget(encumbrances []*EncumbranceRecord, target string, easementTargets map[string][]string) bool {
+	for _, enc := range encumbrances {
+		easementTargets, ok := easementTargets[enc.EncumbranceID]
+		if !ok {
+			return true
+		}
+		for _, t := range easementTargets {
+			if t == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// putEncumbrance writes an encumbrance record under its composite key.
+func putEncumbrance(ctx contractapi.TransactionContextInterface, enc *EncumbranceRecord) error {
+	key, err := createEncumbranceKey(ctx, enc.PropertyID, enc.EncumbranceID)
+	if err != nil {
+		return internalError("failed to create encumbrance key: %v", err)
+	}
+	encBytes, err := canonicalMarshal(enc)
+	if err != nil {
+		return internalError("failed to marshal encumbrance: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, encBytes); err != nil {
+		return internalError("failed to write encumbrance %s: %v", enc.EncumbranceID, err)
+	}
+	return nil
+}
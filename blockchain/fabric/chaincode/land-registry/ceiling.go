@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LAND CEILING
+// ============================================================
+// Land ceiling acts cap the agricultural land one person may hold in a
+// state. RegistrySettings.LandCeilingSqM sets the cap per land
+// classification, with DEFAULT for classes without their own entry. A
+// holding counts the owner's share of each active AGRICULTURAL parcel
+// in the state, found through the owner index, with areas converted to
+// square meters. With EnforceLandCeiling set, a transfer or mutation
+// that would take the acquiring party over a cap fails with
+// CEILING_EXCEEDED unless it cites a ceilingExemptionOrderRef.
+
+// ceilingDefaultClass is the LandCeilingSqM key for land classes with
+// no cap of their own.
+const ceilingDefaultClass = "DEFAULT"
+
+// CheckCeilingCompliance reports an owner's agricultural holdings in a
+// state against its ceilings, with the headroom left in each land
+// class. Citizens can check their own holdings.
+func (s *LandRegistryContract) CheckCeilingCompliance(ctx contractapi.TransactionContextInterface, aadhaarHash, stateCode string) (*CeilingReport, error) {
+	if err := validateAadhaarHash(aadhaarHash, "aadhaarHash"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
+	}
+	if err := requireSelfOrRole(ctx, []string{aadhaarHash}, officialRoles...); err != nil {
+		return nil, err
+	}
+
+	settings, err := getSettings(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := ceilingHoldings(ctx, aadhaarHash, settings, "")
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	report := &CeilingReport{
+		AadhaarHash: aadhaarHash,
+		StateCode:   stateCode,
+		Compliant:   true,
+		Classes:     []CeilingClassHolding{},
+		AsOf:        time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+	}
+	classes := make([]string, 0, len(holdings))
+	for class := range holdings {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		entry := CeilingClassHolding{Classification: class, HeldSqM: holdings[class]}
+		if ceiling, ok := ceilingFor(settings, class); ok {
+			entry.CeilingSqM = ceiling
+			entry.HeadroomSqM = ceiling - entry.HeldSqM
+			entry.Exceeded = entry.HeadroomSqM < 0
+			if entry.Exceeded {
+				report.Compliant = false
+			}
+		}
+		report.Classes = append(report.Classes, entry)
+	}
+	return report, nil
+}
+
+// checkCeiling fails with CEILING_EXCEEDED if acquirerHash taking
+// sharePercentage of property would put them over the state's ceiling
+// for its land class. It does nothing unless the state enforces
+// ceilings, the property is agricultural and its class has a ceiling,
+// or when exemptionOrderRef is given.
+func checkCeiling(ctx contractapi.TransactionContextInterface, property *LandRecord, acquirerHash string, sharePercentage int, exemptionOrderRef string) error {
+	if exemptionOrderRef != "" || property.LandUse != "AGRICULTURAL" {
+		return nil
+	}
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return err
+	}
+	if !settings.EnforceLandCeiling {
+		return nil
+	}
+	class := ceilingClass(property)
+	ceiling, ok := ceilingFor(settings, class)
+	if !ok {
+		return nil
+	}
+
+	holdings, err := ceilingHoldings(ctx, acquirerHash, settings, property.PropertyID)
+	if err != nil {
+		return err
+	}
+	area, err := ConvertArea(property.Area.Value, property.Area.Unit, AreaUnitSqMeters, settings.BighaSqMeters)
+	if err != nil {
+		return err
+	}
+	after := holdings[class] + area*float64(sharePercentage)/100
+	if after > ceiling {
+		return newError(ErrCodeCeilingExceeded, "acquiring %s would bring holdings of %s land to %.2f sq m, above the %.2f sq m ceiling; a ceilingExemptionOrderRef is required",
+			property.PropertyID, class, after, ceiling).with("propertyId", property.PropertyID)
+	}
+	return nil
+}
+
+// ceilingHoldings sums, per land class, the square meters of active
+// agricultural land in the settings' state that aadhaarHash holds,
+// weighted by their share. excludePropertyID, if set, is left out.
+func ceilingHoldings(ctx contractapi.TransactionContextInterface, aadhaarHash string, settings *RegistrySettings, excludePropertyID string) (map[string]float64, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixOwnerIndex, []string{aadhaarHash})
+	if err != nil {
+		return nil, internalError("failed to query owner index: %v", err)
+	}
+	defer iterator.Close()
+
+	holdings := map[string]float64{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate owner index: %v", err)
+		}
+		propertyID := string(kv.Value)
+		if propertyID == excludePropertyID {
+			continue
+		}
+		landKey, err := createLandKey(ctx, propertyID)
+		if err != nil {
+			return nil, internalError("failed to create land key: %v", err)
+		}
+		propertyBytes, err := ctx.GetStub().GetState(landKey)
+		if err != nil {
+			return nil, internalError("failed to read property %s: %v", propertyID, err)
+		}
+		if propertyBytes == nil {
+			continue
+		}
+		var property LandRecord
+		if err := json.Unmarshal(propertyBytes, &property); err != nil {
+			return nil, internalError("failed to unmarshal property: %v", err)
+		}
+		normalizeRecord(&property)
+		if property.Location.StateCode != settings.StateCode || property.LandUse != "AGRICULTURAL" || property.Status == "ARCHIVED" {
+			continue
+		}
+		index := ownerIndex(&property, aadhaarHash)
+		if index < 0 {
+			continue
+		}
+		area, err := ConvertArea(property.Area.Value, property.Area.Unit, AreaUnitSqMeters, settings.BighaSqMeters)
+		if err != nil {
+			return nil, errorAt(propertyID, err)
+		}
+		holdings[ceilingClass(&property)] += area * float64(property.CurrentOwner.Owners[index].SharePercentage) / 100
+	}
+	return holdings, nil
+}
+
+// ceilingClass returns the land class a property counts under:
+// its classification, or DEFAULT if it has none.
+func ceilingClass(property *LandRecord) string {
+	if property.LandClassification == "" {
+		return ceilingDefaultClass
+	}
+	return property.LandClassification
+}
+
+// ceilingFor returns the ceiling for a land class, falling back to
+// DEFAULT.
+func ceilingFor(settings *RegistrySettings, class string) (float64, bool) {
+	if ceiling, ok := settings.LandCeilingSqM[class]; ok {
+		return ceiling, true
+	}
+	ceiling, ok := settings.LandCeilingSqM[ceilingDefaultClass]
+	return ceiling, ok
+}
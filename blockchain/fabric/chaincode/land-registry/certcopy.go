@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// CERTIFIED COPIES
+// ============================================================
+// A certified copy is a snapshot of a land record issued by a
+// sub-registrar office for a fee. The copy's serial number and the
+// record hash at issuance (see landRecordHash) are printed on it, so
+// anyone holding a copy can check with VerifyCertifiedCopy that it was
+// really issued and whether the record has changed since. Each office
+// keeps a daily issuance counter that numbers its copies and totals the
+// fees collected.
+
+// IssueCertifiedCopy issues a certified copy of a property's current
+// record to requestorHash for purpose, charging the state's
+// certifiedCopyFee. The issuing office is the registrar's
+// subRegistrarOffice certificate attribute, or the property's
+// {stateCode}-{districtCode} for identities enrolled without one. Only
+// registrars with jurisdiction over the property can issue copies. The
+// returned copy carries the serial number and record hash to print.
+func (s *LandRegistryContract) IssueCertifiedCopy(ctx contractapi.TransactionContextInterface, propertyID, purpose, requestorHash string) (*CertifiedCopy, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, err
+	}
+	purpose = strings.TrimSpace(purpose)
+	if purpose == "" {
+		return nil, newError(ErrCodeValidationError, "purpose is required")
+	}
+	if err := validateAadhaarHash(requestorHash, "requestorHash"); err != nil {
+		return nil, err
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return nil, err
+	}
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+
+	office, found, err := ctx.GetClientIdentity().GetAttributeValue("subRegistrarOffice")
+	if err != nil {
+		return nil, newError(ErrCodeAccessDenied, "failed to read subRegistrarOffice attribute: %v", err)
+	}
+	if !found || office == "" {
+		office = property.Location.StateCode + "-" + property.Location.DistrictCode
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0).UTC()
+	date := txTime.Format("2006-01-02")
+	counter, counterKey, err := getCertifiedCopyCounter(ctx, office, date)
+	if err != nil {
+		return nil, err
+	}
+	counter.CopyCount++
+	counter.TotalFees += settings.CertifiedCopyFee
+
+	txID := ctx.GetStub().GetTxID()
+	certifiedCopy := &CertifiedCopy{
+		DocType:       "certifiedCopy",
+		SerialNumber:  fmt.Sprintf("CC-%s-%s-%05d", office, txTime.Format("20060102"), counter.CopyCount),
+		PropertyID:    propertyID,
+		Purpose:       purpose,
+		RequestorHash: requestorHash,
+		RecordHash:    recordHash,
+		Snapshot:      redactedRecord(property),
+		Office:        office,
+		Fee:           settings.CertifiedCopyFee,
+		IssuedBy:      getCallerID(ctx),
+		IssuedAt:      txTime.Format(time.RFC3339),
+		FabricTxID:    txID,
+	}
+	counter.LastSerialNumber = certifiedCopy.SerialNumber
+	counter.LastFabricTxID = txID
+
+	copyKey, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCertifiedCopy, []string{certifiedCopy.SerialNumber})
+	if err != nil {
+		return nil, internalError("failed to create certified copy key: %v", err)
+	}
+	copyBytes, err := canonicalMarshal(certifiedCopy)
+	if err != nil {
+		return nil, internalError("failed to marshal certified copy: %v", err)
+	}
+	if err := ctx.GetStub().PutState(copyKey, copyBytes); err != nil {
+		return nil, internalError("failed to write certified copy %s: %v", certifiedCopy.SerialNumber, err)
+	}
+	counterBytes, err := canonicalMarshal(counter)
+	if err != nil {
+		return nil, internalError("failed to marshal certified copy counter: %v", err)
+	}
+	if err := ctx.GetStub().PutState(counterKey, counterBytes); err != nil {
+		return nil, internalError("failed to write certified copy counter: %v", err)
+	}
+	return certifiedCopy, nil
+}
+
+// VerifyCertifiedCopy checks a presented certified copy: Authentic if
+// serialNumber was issued with presentedHash as its record hash, and
+// RecordChanged if the property's record no longer hashes to it. Anyone
+// may verify a copy; the result carries no owner details.
+func (s *LandRegistryContract) VerifyCertifiedCopy(ctx contractapi.TransactionContextInterface, serialNumber, presentedHash string) (*CertifiedCopyVerification, error) {
+	if serialNumber == "" || presentedHash == "" {
+		return nil, newError(ErrCodeValidationError, "serialNumber and presentedHash are required")
+	}
+	copyKey, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCertifiedCopy, []string{serialNumber})
+	if err != nil {
+		return nil, internalError("failed to create certified copy key: %v", err)
+	}
+	copyBytes, err := ctx.GetStub().GetState(copyKey)
+	if err != nil {
+		return nil, internalError("failed to read certified copy: %v", err)
+	}
+	if copyBytes == nil {
+		return nil, newError(ErrCodeCertifiedCopyNotFound, "no certified copy was issued with serial number %s", serialNumber)
+	}
+	var certifiedCopy CertifiedCopy
+	if err := json.Unmarshal(copyBytes, &certifiedCopy); err != nil {
+		return nil, internalError("failed to unmarshal certified copy: %v", err)
+	}
+
+	current, err := readLandRecord(ctx, certifiedCopy.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	currentHash, err := landRecordHash(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	return &CertifiedCopyVerification{
+		SerialNumber:      serialNumber,
+		PropertyID:        certifiedCopy.PropertyID,
+		Authentic:         presentedHash == certifiedCopy.RecordHash,
+		RecordChanged:     currentHash != certifiedCopy.RecordHash,
+		CurrentRecordHash: currentHash,
+		Office:            certifiedCopy.Office,
+		IssuedAt:          certifiedCopy.IssuedAt,
+		VerifiedAt:        time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+	}, nil
+}
+
+// GetCertifiedCopyCounter returns an office's certified copy issuance
+// count and fee total for date (YYYY-MM-DD, UTC), for reconciling the
+// fees collected. A day with no copies has a zero counter. Only
+// registrars and admins can query.
+func (s *LandRegistryContract) GetCertifiedCopyCounter(ctx contractapi.TransactionContextInterface, office, date string) (*CertifiedCopyCounter, error) {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return nil, err
+	}
+	if office == "" {
+		return nil, newError(ErrCodeValidationError, "office is required")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, newError(ErrCodeValidationError, "date must be YYYY-MM-DD")
+	}
+	counter, _, err := getCertifiedCopyCounter(ctx, office, date)
+	return counter, err
+}
+
+// getCertifiedCopyCounter loads an office's counter for date, or a zero
+// one, along with its key.
+func getCertifiedCopyCounter(ctx contractapi.TransactionContextInterface, office, date string) (*CertifiedCopyCounter, string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCertifiedCopyCounter, []string{office, date})
+	if err != nil {
+		return nil, "", internalError("failed to create certified copy counter key: %v", err)
+	}
+	counterBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, "", internalError("failed to read certified copy counter: %v", err)
+	}
+	counter := &CertifiedCopyCounter{
+		DocType: "certifiedCopyCounter",
+		Office:  office,
+		Date:    date,
+	}
+	if counterBytes != nil {
+		if err := json.Unmarshal(counterBytes, counter); err != nil {
+			return nil, "", internalError("failed to unmarshal certified copy counter: %v", err)
+		}
+	}
+	return counter, key, nil
+}
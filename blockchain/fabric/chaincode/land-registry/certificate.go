@@ -0,0 +1,259 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// OWNERSHIP CERTIFICATE PAYLOAD
+// ============================================================
+// The citizen portal prints a one-page ownership certificate whose QR
+// code carries a compact canonical JSON payload and its SHA-256. Anyone
+// can scan it and call VerifyCertificatePayload to check it against the
+// chain without trusting the portal. The payload holds owners' masked
+// names and hashes only, never other owner details.
+
+// maxCertificatePayloadBytes keeps the payload within what a printed QR
+// code can carry. Owners beyond what fits are left out and the payload
+// is marked ownersTruncated.
+const maxCertificatePayloadBytes = 2048
+
+// GenerateCertificatePayload returns the certificate payload for a
+// property and its SHA-256. It is readable by whoever can read the
+// property.
+func (s *LandRegistryContract) GenerateCertificatePayload(ctx contractapi.TransactionContextInterface, propertyID string) (*CertificatePayload, error) {
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	content, err := certificateContent(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := encodeCertificateContent(content)
+	if err != nil {
+		return nil, err
+	}
+	return &CertificatePayload{
+		Payload:     string(payload),
+		PayloadHash: certificatePayloadHash(payload),
+		Size:        len(payload),
+	}, nil
+}
+
+// VerifyCertificatePayload checks a scanned certificate payload.
+// HashValid reports whether payloadHash is the SHA-256 of the payload;
+// Current whether the live record still matches it, and Divergence
+// names the fields that no longer do. A newer anchor is not a
+// divergence. Anyone may verify a payload.
+func (s *LandRegistryContract) VerifyCertificatePayload(ctx contractapi.TransactionContextInterface, payloadJSON, payloadHash string) (*CertificateVerification, error) {
+	if payloadJSON == "" || payloadHash == "" {
+		return nil, newError(ErrCodeValidationError, "payloadJSON and payloadHash are required")
+	}
+	payload, err := canonicalJSON([]byte(payloadJSON))
+	if err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse certificate payload: %v", err)
+	}
+	var presented CertificateContent
+	if err := json.Unmarshal(payload, &presented); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse certificate payload: %v", err)
+	}
+	if err := validatePropertyID(presented.PropertyID); err != nil {
+		return nil, err
+	}
+
+	property, err := readLandRecord(ctx, presented.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	live, err := certificateContent(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+
+	divergence := []string{}
+	fields := []struct {
+		name            string
+		presented, live interface{}
+	}{
+		{"surveyNumber", presented.SurveyNumber, live.SurveyNumber},
+		{"subSurveyNumber", presented.SubSurveyNumber, live.SubSurveyNumber},
+		{"owners", presented.Owners, live.Owners},
+		{"ownerCount", presented.OwnerCount, live.OwnerCount},
+		{"status", presented.Status, live.Status},
+		{"disputeStatus", presented.DisputeStatus, live.DisputeStatus},
+		{"encumbranceStatus", presented.EncumbranceStatus, live.EncumbranceStatus},
+		{"coolingPeriodActive", presented.CoolingPeriodActive, live.CoolingPeriodActive},
+		{"area", presented.Area, live.Area},
+		{"lastMutationId", presented.LastMutationID, live.LastMutationID},
+		{"recordHash", presented.RecordHash, live.RecordHash},
+	}
+	for _, field := range fields {
+		if !reflect.DeepEqual(field.presented, field.live) {
+			divergence = append(divergence, field.name)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	return &CertificateVerification{
+		PropertyID:        presented.PropertyID,
+		HashValid:         certificatePayloadHash(payload) == payloadHash,
+		Current:           len(divergence) == 0,
+		Divergence:        divergence,
+		CurrentRecordHash: live.RecordHash,
+		VerifiedAt:        time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+	}, nil
+}
+
+// certificateContent builds the certificate payload content for a
+// property from its record, its latest mutation and the latest anchor
+// of its state.
+func certificateContent(ctx contractapi.TransactionContextInterface, property *LandRecord) (*CertificateContent, error) {
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+	lastMutationID, err := latestMutationID(ctx, property.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := latestAnchor(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &CertificateContent{
+		PropertyID:          property.PropertyID,
+		SurveyNumber:        property.SurveyNumber,
+		SubSurveyNumber:     property.SubSurveyNumber,
+		Owners:              make([]CertificateOwner, 0, len(property.CurrentOwner.Owners)),
+		OwnerCount:          len(property.CurrentOwner.Owners),
+		Status:              property.Status,
+		DisputeStatus:       property.DisputeStatus,
+		EncumbranceStatus:   property.EncumbranceStatus,
+		CoolingPeriodActive: property.CoolingPeriod.Active,
+		Area:                CertificateArea{Value: property.Area.Value, Unit: property.Area.Unit},
+		LastMutationID:      lastMutationID,
+		RecordHash:          recordHash,
+	}
+	if anchor != nil {
+		content.Anchor = &CertificateAnchor{
+			AnchorID:     anchor.AnchorID,
+			AlgorandTxID: anchor.AlgorandTxID,
+			AnchoredAt:   anchor.AnchoredAt,
+		}
+	}
+	for _, owner := range property.CurrentOwner.Owners {
+		content.Owners = append(content.Owners, CertificateOwner{
+			Name:            maskName(owner.Name),
+			AadhaarHash:     owner.AadhaarHash,
+			SharePercentage: owner.SharePercentage,
+		})
+	}
+
+	// Drop owners from the end until the payload fits a QR code.
+	for {
+		payload, err := encodeCertificateContent(content)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) <= maxCertificatePayloadBytes || len(content.Owners) == 0 {
+			return content, nil
+		}
+		content.Owners = content.Owners[:len(content.Owners)-1]
+		content.OwnersTruncated = true
+	}
+}
+
+// encodeCertificateContent returns the canonical JSON of a payload.
+func encodeCertificateContent(content *CertificateContent) ([]byte, error) {
+	payload, err := canonicalMarshal(content)
+	if err != nil {
+		return nil, internalError("failed to marshal certificate payload: %v", err)
+	}
+	return payload, nil
+}
+
+// certificatePayloadHash returns the SHA-256 of a canonical payload.
+func certificatePayloadHash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// maskName keeps the first letter of each word of a name and masks the
+// rest, e.g. "Ramesh Kumar" becomes "R***** K****".
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
+
+// latestMutationID returns the ID of the most recently created mutation
+// of a property, or "" if it has none.
+func latestMutationID(ctx contractapi.TransactionContextInterface, propertyID string) (string, error) {
+	queryString := fmt.Sprintf(`{"selector":{"docType":"mutationRecord","propertyId":"%s"}}`, propertyID)
+	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return "", internalError("failed to query mutations: %v", err)
+	}
+	defer iterator.Close()
+
+	var latest *MutationRecord
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return "", internalError("failed to iterate mutations: %v", err)
+		}
+		var mutation MutationRecord
+		if err := json.Unmarshal(kv.Value, &mutation); err != nil {
+			return "", internalError("failed to unmarshal mutation: %v", err)
+		}
+		if latest == nil || mutation.CreatedAt > latest.CreatedAt ||
+			(mutation.CreatedAt == latest.CreatedAt && mutation.MutationID > latest.MutationID) {
+			latest = &mutation
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return latest.MutationID, nil
+}
+
+// latestAnchor returns the most recently recorded anchor of a state, or
+// nil if it has none.
+func latestAnchor(ctx contractapi.TransactionContextInterface, stateCode string) (*AnchorRecord, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixAnchor, []string{stateCode})
+	if err != nil {
+		return nil, internalError("failed to query anchors: %v", err)
+	}
+	defer iterator.Close()
+
+	var latest *AnchorRecord
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate anchors: %v", err)
+		}
+		var anchor AnchorRecord
+		if err := json.Unmarshal(kv.Value, &anchor); err != nil {
+			return nil, internalError("failed to unmarshal anchor: %v", err)
+		}
+		if latest == nil || anchor.AnchoredAt > latest.AnchoredAt ||
+			(anchor.AnchoredAt == latest.AnchoredAt && anchor.AnchorID > latest.AnchorID) {
+			latest = &anchor
+		}
+	}
+	return latest, nil
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -26,49 +28,79 @@ type LandRegistryContract struct {
 // RegisterProperty registers a new land record on the blockchain.
 // Only users with the "registrar" role can call this function.
 // The caller must belong to the same state as the property location.
+// Where the state names an endorsing org, later writes to the record
+// need that org's endorsement (see applyLandEndorsement).
 // Emits a PROPERTY_REGISTERED event upon success.
-func (s *LandRegistryContract) RegisterProperty(ctx contractapi.TransactionContextInterface, propertyJSON string) error {
+func (s *LandRegistryContract) RegisterProperty(ctx contractapi.TransactionContextInterface, propertyJSON string) (*Receipt, error) {
 	// ABAC: Only registrars can register property
 	if err := requireRole(ctx, "registrar"); err != nil {
-		return err
+		return nil, err
 	}
 
 	var property LandRecord
 	if err := json.Unmarshal([]byte(propertyJSON), &property); err != nil {
-		return fmt.Errorf("INVALID_INPUT: failed to parse property JSON: %v", err)
+		return nil, newError(ErrCodeInvalidInput, "failed to parse property JSON: %v", err)
 	}
 
 	// Validate property ID format
 	if err := validatePropertyID(property.PropertyID); err != nil {
-		return err
+		return nil, err
+	}
+	if err := validatePropertyIDMatches(property.PropertyID, property.Location, property.SurveyNumber, property.SubSurveyNumber); err != nil {
+		return nil, err
 	}
 
 	// State boundary check
 	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check Aadhaar mandatory (Rule 10)
 	if len(property.CurrentOwner.Owners) == 0 {
-		return fmt.Errorf("VALIDATION_ERROR: property must have at least one owner")
+		return nil, newError(ErrCodeValidationError, "property must have at least one owner")
 	}
 	for _, owner := range property.CurrentOwner.Owners {
 		if owner.AadhaarHash == "" {
-			return fmt.Errorf("AADHAAR_REQUIRED: every owner must have an aadhaarHash")
+			return nil, newError(ErrCodeAadhaarRequired, "every owner must have an aadhaarHash")
 		}
 	}
+	if err := validateOwnership(property.CurrentOwner.Owners); err != nil {
+		return nil, err
+	}
+	if err := validateOwnerInfo(&property.CurrentOwner); err != nil {
+		return nil, err
+	}
+	if err := validateGeoJSON(property.Boundaries.GeoJSON); err != nil {
+		return nil, err
+	}
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAreaUnits(property.Area, settings.BighaSqMeters); err != nil {
+		return nil, err
+	}
+	if err := validateLandClassification(property.LandClassification, settings); err != nil {
+		return nil, err
+	}
+	if err := validatePinCode(property.Location.PinCode, property.Location.StateCode); err != nil {
+		return nil, err
+	}
 
 	// Check if property already exists (Rule 9: never overwrite)
 	landKey, err := createLandKey(ctx, property.PropertyID)
 	if err != nil {
-		return fmt.Errorf("failed to create land key: %v", err)
+		return nil, internalError("failed to create land key: %v", err)
 	}
 	existing, err := ctx.GetStub().GetState(landKey)
 	if err != nil {
-		return fmt.Errorf("failed to read world state: %v", err)
+		return nil, internalError("failed to read world state: %v", err)
 	}
 	if existing != nil {
-		return fmt.Errorf("PROPERTY_EXISTS: property %s already registered", property.PropertyID)
+		return nil, newError(ErrCodePropertyExists, "property %s already registered", property.PropertyID)
+	}
+	if err := claimRegistrationNumber(ctx, property.RegistrationInfo, property.PropertyID, "", nil); err != nil {
+		return nil, err
 	}
 
 	// Set metadata
@@ -77,6 +109,7 @@ func (s *LandRegistryContract) RegisterProperty(ctx contractapi.TransactionConte
 	txID := ctx.GetStub().GetTxID()
 
 	property.DocType = "landRecord"
+	property.SchemaVersion = landRecordSchemaVersion
 	property.Status = "ACTIVE"
 	property.DisputeStatus = "CLEAR"
 	property.EncumbranceStatus = "CLEAR"
@@ -89,20 +122,33 @@ func (s *LandRegistryContract) RegisterProperty(ctx contractapi.TransactionConte
 	if property.Provenance.Sequence == 0 {
 		property.Provenance.Sequence = 1
 	}
+	applyOwnerKYCDefaults(ctx, property.CurrentOwner.Owners, "PENDING", now)
+
+	// Overlapping parcels are flagged for survey, not rejected
+	conflictIDs, err := s.checkBoundaryOverlaps(ctx, &property, now, txID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Store property
-	propertyBytes, err := json.Marshal(property)
+	propertyBytes, err := canonicalMarshal(property)
 	if err != nil {
-		return fmt.Errorf("failed to marshal property: %v", err)
+		return nil, internalError("failed to marshal property: %v", err)
 	}
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to put state: %v", err)
+		return nil, internalError("failed to put state: %v", err)
+	}
+	if err := applyLandEndorsement(ctx, &property, settings); err != nil {
+		return nil, err
 	}
 
 	// Create indexes for efficient queries
 	for _, owner := range property.CurrentOwner.Owners {
 		if err := putOwnerIndex(ctx, owner.AadhaarHash, property.PropertyID); err != nil {
-			return fmt.Errorf("failed to create owner index: %v", err)
+			return nil, internalError("failed to create owner index: %v", err)
+		}
+		if err := putEntityIndex(ctx, owner, property.PropertyID); err != nil {
+			return nil, internalError("failed to create entity index: %v", err)
 		}
 	}
 	surveyKey := property.SurveyNumber
@@ -110,45 +156,77 @@ func (s *LandRegistryContract) RegisterProperty(ctx contractapi.TransactionConte
 		surveyKey = property.SurveyNumber + "/" + property.SubSurveyNumber
 	}
 	if err := putSurveyIndex(ctx, property.Location.StateCode, property.Location.DistrictCode, surveyKey, property.PropertyID); err != nil {
-		return fmt.Errorf("failed to create survey index: %v", err)
+		return nil, internalError("failed to create survey index: %v", err)
 	}
 	if err := putLocationIndex(ctx, property.Location, property.PropertyID); err != nil {
-		return fmt.Errorf("failed to create location index: %v", err)
+		return nil, internalError("failed to create location index: %v", err)
+	}
+	if err := countNewRecord(ctx, property.Location.StateCode, "landRecord", property.Status); err != nil {
+		return nil, err
 	}
 
 	// Emit PROPERTY_REGISTERED event
 	event := PropertyRegisteredEvent{
-		Type:         "PROPERTY_REGISTERED",
-		PropertyID:   property.PropertyID,
-		OwnerHash:    property.CurrentOwner.Owners[0].AadhaarHash,
-		SurveyNumber: property.SurveyNumber,
-		FabricTxID:   txID,
-		Timestamp:    now,
-		StateCode:    property.Location.StateCode,
-		ChannelID:    ctx.GetStub().GetChannelID(),
+		Type:              "PROPERTY_REGISTERED",
+		PropertyID:        property.PropertyID,
+		OwnerHash:         property.CurrentOwner.Owners[0].AadhaarHash,
+		SurveyNumber:      property.SurveyNumber,
+		BoundaryConflicts: conflictIDs,
+		FabricTxID:        txID,
+		Timestamp:         now,
+		StateCode:         property.Location.StateCode,
+		ChannelID:         ctx.GetStub().GetChannelID(),
 	}
-	return emitEvent(ctx, "PROPERTY_REGISTERED", event)
+	if err := emitEvent(ctx, "PROPERTY_REGISTERED", event); err != nil {
+		return nil, err
+	}
+	receipt := newReceipt(ctx, property.PropertyID, property.Status)
+	for _, conflictID := range conflictIDs {
+		receipt.relate("boundaryConflict", conflictID, "")
+	}
+	return receipt, nil
 }
 
 // RegisterBulk registers multiple properties in a single transaction.
 // This is primarily used during data migration from legacy state
-// revenue systems. Only users with the "admin" role can call this.
+// revenue systems. The batch is all-or-nothing: the first invalid
+// record aborts it. Only users with the "admin" role can call this.
 func (s *LandRegistryContract) RegisterBulk(ctx contractapi.TransactionContextInterface, propertiesJSON string) error {
+	_, err := s.registerBulk(ctx, propertiesJSON, false)
+	return err
+}
+
+// RegisterBulkWithReport registers multiple properties like RegisterBulk
+// and returns a per-record BulkResult. With continueOnError set, invalid
+// and duplicate records are reported and skipped while the valid ones
+// are committed; without it the batch stays all-or-nothing.
+// Only users with the "admin" role can call this.
+func (s *LandRegistryContract) RegisterBulkWithReport(ctx contractapi.TransactionContextInterface, propertiesJSON string, continueOnError bool) (*BulkResult, error) {
+	return s.registerBulk(ctx, propertiesJSON, continueOnError)
+}
+
+// maxBulkRecords caps a bulk registration batch. Every record adds its
+// land record and index writes to the read-write set, and larger
+// batches risk exceeding endorsement and block size limits.
+const maxBulkRecords = 100
+
+// registerBulk implements RegisterBulk and RegisterBulkWithReport.
+func (s *LandRegistryContract) registerBulk(ctx contractapi.TransactionContextInterface, propertiesJSON string, continueOnError bool) (*BulkResult, error) {
 	// ABAC: Only admins can bulk register (migration use case)
 	if err := requireRole(ctx, "admin"); err != nil {
-		return err
+		return nil, err
 	}
 
 	var properties []LandRecord
 	if err := json.Unmarshal([]byte(propertiesJSON), &properties); err != nil {
-		return fmt.Errorf("INVALID_INPUT: failed to parse properties array: %v", err)
+		return nil, newError(ErrCodeInvalidInput, "failed to parse properties array: %v", err)
 	}
 
 	if len(properties) == 0 {
-		return fmt.Errorf("VALIDATION_ERROR: empty properties array")
+		return nil, newError(ErrCodeValidationError, "empty properties array")
 	}
-	if len(properties) > 100 {
-		return fmt.Errorf("VALIDATION_ERROR: bulk registration limited to 100 properties per transaction")
+	if len(properties) > maxBulkRecords {
+		return nil, newError(ErrCodeValidationError, "bulk registration limited to %d properties per transaction", maxBulkRecords)
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -156,32 +234,43 @@ func (s *LandRegistryContract) RegisterBulk(ctx contractapi.TransactionContextIn
 	txID := ctx.GetStub().GetTxID()
 	callerID := getCallerID(ctx)
 
-	for i, property := range properties {
-		if err := validatePropertyID(property.PropertyID); err != nil {
-			return fmt.Errorf("property[%d]: %v", i, err)
-		}
+	result := &BulkResult{
+		Total:           len(properties),
+		MaxBatchSize:    maxBulkRecords,
+		ContinueOnError: continueOnError,
+		Records:         make([]BulkRecordResult, 0, len(properties)),
+	}
+	// GetState does not see this transaction's own writes, so duplicates
+	// within the batch are tracked here
+	seen := make(map[string]bool, len(properties))
+	regNos := make(map[string]string)
 
-		// Validate Aadhaar (Rule 10)
-		if len(property.CurrentOwner.Owners) == 0 {
-			return fmt.Errorf("property[%d]: must have at least one owner", i)
-		}
-		for _, owner := range property.CurrentOwner.Owners {
-			if owner.AadhaarHash == "" {
-				return fmt.Errorf("property[%d]: AADHAAR_REQUIRED for all owners", i)
+	var registered []BulkRegisteredRecord
+	for i, property := range properties {
+		status, err := s.validateBulkRecord(ctx, &property, seen)
+		if err == nil {
+			if err = claimRegistrationNumber(ctx, property.RegistrationInfo, property.PropertyID, "", regNos); err != nil {
+				status = "REJECTED"
 			}
 		}
-
-		landKey, err := createLandKey(ctx, property.PropertyID)
-		if err != nil {
-			return fmt.Errorf("property[%d]: failed to create key: %v", i, err)
-		}
-		existing, err := ctx.GetStub().GetState(landKey)
 		if err != nil {
-			return fmt.Errorf("property[%d]: failed to read state: %v", i, err)
-		}
-		if existing != nil {
-			return fmt.Errorf("property[%d]: PROPERTY_EXISTS: %s already registered", i, property.PropertyID)
+			if !continueOnError {
+				return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
+			}
+			result.Records = append(result.Records, BulkRecordResult{
+				Index:      i,
+				PropertyID: property.PropertyID,
+				Status:     status,
+				Error:      errorText(err),
+			})
+			if status == "DUPLICATE" {
+				result.Duplicates++
+			} else {
+				result.Rejected++
+			}
+			continue
 		}
+		seen[property.PropertyID] = true
 
 		property.DocType = "landRecord"
 		if property.Status == "" {
@@ -204,39 +293,148 @@ func (s *LandRegistryContract) RegisterBulk(ctx contractapi.TransactionContextIn
 		if property.Provenance.Sequence == 0 {
 			property.Provenance.Sequence = 1
 		}
+		// Migrated hashes were not verified through eKYC
+		applyOwnerKYCDefaults(ctx, property.CurrentOwner.Owners, "MIGRATED_UNVERIFIED", now)
 
-		propertyBytes, err := json.Marshal(property)
+		// Write failures are infrastructure errors and abort the batch
+		// in either mode
+		if err := putLandRecord(ctx, &property); err != nil {
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+		settings, err := getSettings(ctx, property.Location.StateCode)
 		if err != nil {
-			return fmt.Errorf("property[%d]: failed to marshal: %v", i, err)
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
 		}
-		if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-			return fmt.Errorf("property[%d]: failed to put state: %v", i, err)
+		if err := applyLandEndorsement(ctx, &property, settings); err != nil {
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
 		}
 
 		// Create indexes
 		for _, owner := range property.CurrentOwner.Owners {
-			_ = putOwnerIndex(ctx, owner.AadhaarHash, property.PropertyID)
+			if err := putOwnerIndex(ctx, owner.AadhaarHash, property.PropertyID); err != nil {
+				return nil, internalError("property[%d]: failed to create owner index: %v", i, err)
+			}
+			if err := putEntityIndex(ctx, owner, property.PropertyID); err != nil {
+				return nil, internalError("property[%d]: failed to create entity index: %v", i, err)
+			}
+		}
+		surveyKey := surveyIndexNumber(property.SurveyNumber, property.SubSurveyNumber)
+		if err := putSurveyIndex(ctx, property.Location.StateCode, property.Location.DistrictCode, surveyKey, property.PropertyID); err != nil {
+			return nil, internalError("property[%d]: failed to create survey index: %v", i, err)
 		}
-		surveyKey := property.SurveyNumber
-		if property.SubSurveyNumber != "" {
-			surveyKey = property.SurveyNumber + "/" + property.SubSurveyNumber
+		if err := putLocationIndex(ctx, property.Location, property.PropertyID); err != nil {
+			return nil, internalError("property[%d]: failed to create location index: %v", i, err)
 		}
-		_ = putSurveyIndex(ctx, property.Location.StateCode, property.Location.DistrictCode, surveyKey, property.PropertyID)
-		_ = putLocationIndex(ctx, property.Location, property.PropertyID)
+		if err := countNewRecord(ctx, property.Location.StateCode, "landRecord", property.Status); err != nil {
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+
+		result.Registered++
+		result.Records = append(result.Records, BulkRecordResult{
+			Index:      i,
+			PropertyID: property.PropertyID,
+			Status:     "REGISTERED",
+			Warnings:   property.DataQualityFlags,
+		})
+		registered = append(registered, BulkRegisteredRecord{
+			PropertyID:   property.PropertyID,
+			OwnerHash:    property.CurrentOwner.Owners[0].AadhaarHash,
+			SurveyNumber: property.SurveyNumber,
+			StateCode:    property.Location.StateCode,
+		})
+	}
+
+	// Fabric keeps only one chaincode event per transaction, so the bulk
+	// operation emits a single event listing every registered record
+	event := BulkRegisteredEvent{
+		Type:       "BULK_REGISTERED",
+		Count:      len(registered),
+		Failed:     result.Rejected + result.Duplicates,
+		Records:    registered,
+		FabricTxID: txID,
+		Timestamp:  now,
+		StateCode:  extractStateCode(properties[0].PropertyID),
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "BULK_REGISTERED", event); err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	// Emit a single event for the bulk operation
-	event := PropertyRegisteredEvent{
-		Type:         "PROPERTY_REGISTERED",
-		PropertyID:   fmt.Sprintf("BULK:%d_records", len(properties)),
-		OwnerHash:    "bulk_migration",
-		SurveyNumber: "",
-		FabricTxID:   txID,
-		Timestamp:    now,
-		StateCode:    extractStateCode(properties[0].PropertyID),
-		ChannelID:    ctx.GetStub().GetChannelID(),
+// validateBulkRecord runs the registration checks for one bulk record.
+// On failure it returns the result status — DUPLICATE for an already
+// registered propertyId, REJECTED otherwise — with the error. In lenient
+// migration mode area, classification and PIN code problems are added
+// to the record's DataQualityFlags instead of failing.
+func (s *LandRegistryContract) validateBulkRecord(ctx contractapi.TransactionContextInterface, property *LandRecord, seen map[string]bool) (string, error) {
+	if err := validatePropertyID(property.PropertyID); err != nil {
+		return "REJECTED", err
+	}
+	if err := validatePropertyIDMatches(property.PropertyID, property.Location, property.SurveyNumber, property.SubSurveyNumber); err != nil {
+		return "REJECTED", err
+	}
+
+	// State boundary check per record; national admins pass through
+	// requireStateAccess and the state's nationalWriteRoles
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return "REJECTED", err
+	}
+
+	// Validate Aadhaar (Rule 10)
+	if len(property.CurrentOwner.Owners) == 0 {
+		return "REJECTED", newError(ErrCodeValidationError, "must have at least one owner")
+	}
+	for _, owner := range property.CurrentOwner.Owners {
+		if owner.AadhaarHash == "" {
+			return "REJECTED", newError(ErrCodeAadhaarRequired, "required for all owners")
+		}
+	}
+	if err := validateOwnership(property.CurrentOwner.Owners); err != nil {
+		return "REJECTED", err
+	}
+	if err := validateOwnerInfo(&property.CurrentOwner); err != nil {
+		return "REJECTED", err
+	}
+	if err := validateGeoJSON(property.Boundaries.GeoJSON); err != nil {
+		return "REJECTED", err
+	}
+
+	// Legacy data is often dirty; in lenient mode these checks flag the
+	// record for review instead of rejecting it
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return "REJECTED", err
+	}
+	for _, check := range []error{
+		validateAreaUnits(property.Area, settings.BighaSqMeters),
+		validateLandClassification(property.LandClassification, settings),
+		validatePinCode(property.Location.PinCode, property.Location.StateCode),
+	} {
+		if check == nil {
+			continue
+		}
+		if !settings.LenientBulkMigration {
+			return "REJECTED", check
+		}
+		property.DataQualityFlags = append(property.DataQualityFlags, check.Error())
+	}
+
+	if seen[property.PropertyID] {
+		return "DUPLICATE", newError(ErrCodePropertyExists, "%s appears earlier in this batch", property.PropertyID)
+	}
+	landKey, err := createLandKey(ctx, property.PropertyID)
+	if err != nil {
+		return "REJECTED", internalError("failed to create key: %v", err)
+	}
+	existing, err := ctx.GetStub().GetState(landKey)
+	if err != nil {
+		return "REJECTED", internalError("failed to read state: %v", err)
+	}
+	if existing != nil {
+		return "DUPLICATE", newError(ErrCodePropertyExists, "%s already registered", property.PropertyID)
 	}
-	return emitEvent(ctx, "PROPERTY_REGISTERED", event)
+	return "", nil
 }
 
 // ============================================================
@@ -245,7 +443,9 @@ func (s *LandRegistryContract) RegisterBulk(ctx contractapi.TransactionContextIn
 
 // GetProperty retrieves a land record by its property ID.
 // Accessible by registrar, tehsildar, bank, court, admin, and citizens
-// (citizens can only view their own properties, enforced at middleware).
+// who are among its current owners (see requireSelfOrRole).
+// Records stored at an older schema version are returned in the current
+// shape (see normalizeRecord).
 func (s *LandRegistryContract) GetProperty(ctx contractapi.TransactionContextInterface, propertyID string) (*LandRecord, error) {
 	if err := validatePropertyID(propertyID); err != nil {
 		return nil, err
@@ -253,40 +453,45 @@ func (s *LandRegistryContract) GetProperty(ctx contractapi.TransactionContextInt
 
 	landKey, err := createLandKey(ctx, propertyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create land key: %v", err)
+		return nil, internalError("failed to create land key: %v", err)
 	}
 
 	propertyBytes, err := ctx.GetStub().GetState(landKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read world state: %v", err)
+		return nil, internalError("failed to read world state: %v", err)
 	}
 	if propertyBytes == nil {
-		return nil, fmt.Errorf("PROPERTY_NOT_FOUND: %s does not exist", propertyID)
+		return nil, errPropertyNotFound(propertyID)
 	}
 
 	var property LandRecord
 	if err := json.Unmarshal(propertyBytes, &property); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal property: %v", err)
+		return nil, internalError("failed to unmarshal property: %v", err)
+	}
+	if err := requireSelfOrRole(ctx, ownerHashes(&property), officialRoles...); err != nil {
+		return nil, err
 	}
+	normalizeRecord(&property)
 	return &property, nil
 }
 
 // GetPropertyHistory retrieves the full transaction history of a
 // land record using Fabric's built-in history database. This provides
-// the complete provenance chain for the property.
+// the complete provenance chain for the property. Citizens may only read
+// the history of properties they currently own.
 func (s *LandRegistryContract) GetPropertyHistory(ctx contractapi.TransactionContextInterface, propertyID string) ([]*HistoryEntry, error) {
-	if err := validatePropertyID(propertyID); err != nil {
+	if _, err := s.GetProperty(ctx, propertyID); err != nil {
 		return nil, err
 	}
 
 	landKey, err := createLandKey(ctx, propertyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create land key: %v", err)
+		return nil, internalError("failed to create land key: %v", err)
 	}
 
 	historyIterator, err := ctx.GetStub().GetHistoryForKey(landKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get history for %s: %v", propertyID, err)
+		return nil, internalError("failed to get history for %s: %v", propertyID, err)
 	}
 	defer historyIterator.Close()
 
@@ -294,7 +499,7 @@ func (s *LandRegistryContract) GetPropertyHistory(ctx contractapi.TransactionCon
 	for historyIterator.HasNext() {
 		modification, err := historyIterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate history: %v", err)
+			return nil, internalError("failed to iterate history: %v", err)
 		}
 
 		entry := &HistoryEntry{
@@ -314,16 +519,29 @@ func (s *LandRegistryContract) GetPropertyHistory(ctx contractapi.TransactionCon
 	return history, nil
 }
 
-// QueryByOwner returns all properties owned by the specified Aadhaar hash.
-// Uses the OWNER composite key index for efficient lookup.
+// QueryByOwner returns all properties owned by the specified Aadhaar
+// hash, or by a non-individual owner's entity identifier (CIN, trust
+// registration number, department code or PAN). Uses the OWNER or
+// ENTITY composite key index for efficient lookup. Citizens may only
+// query their own hash.
 func (s *LandRegistryContract) QueryByOwner(ctx contractapi.TransactionContextInterface, ownerAadhaarHash string) ([]*LandRecord, error) {
 	if ownerAadhaarHash == "" {
-		return nil, fmt.Errorf("VALIDATION_ERROR: ownerAadhaarHash cannot be empty")
+		return nil, newError(ErrCodeValidationError, "ownerAadhaarHash cannot be empty")
+	}
+	if err := requireSelfOrRole(ctx, []string{ownerAadhaarHash}, officialRoles...); err != nil {
+		return nil, err
+	}
+	indexPrefix := KeyPrefixOwnerIndex
+	if !aadhaarHashPattern.MatchString(ownerAadhaarHash) {
+		if rawAadhaarPattern.MatchString(strings.TrimSpace(ownerAadhaarHash)) {
+			return nil, validateAadhaarHash(ownerAadhaarHash, "ownerAadhaarHash")
+		}
+		indexPrefix = KeyPrefixEntityIndex
 	}
 
-	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixOwnerIndex, []string{ownerAadhaarHash})
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(indexPrefix, []string{ownerAadhaarHash})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query owner index: %v", err)
+		return nil, internalError("failed to query owner index: %v", err)
 	}
 	defer iterator.Close()
 
@@ -331,13 +549,16 @@ func (s *LandRegistryContract) QueryByOwner(ctx contractapi.TransactionContextIn
 	for iterator.HasNext() {
 		kv, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate owner index: %v", err)
+			return nil, internalError("failed to iterate owner index: %v", err)
 		}
 		propertyID := string(kv.Value)
 		property, err := s.GetProperty(ctx, propertyID)
 		if err != nil {
 			continue // Property may have been archived; skip
 		}
+		if property.Status == "ARCHIVED" {
+			continue
+		}
 		properties = append(properties, property)
 	}
 	return properties, nil
@@ -347,20 +568,20 @@ func (s *LandRegistryContract) QueryByOwner(ctx contractapi.TransactionContextIn
 // and survey number. Uses the SURVEY composite key index.
 func (s *LandRegistryContract) QueryBySurvey(ctx contractapi.TransactionContextInterface, stateCode, districtCode, surveyNo string) (*LandRecord, error) {
 	if stateCode == "" || districtCode == "" || surveyNo == "" {
-		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and surveyNo are all required")
+		return nil, newError(ErrCodeValidationError, "stateCode, districtCode, and surveyNo are all required")
 	}
 
 	surveyKey, err := createSurveyIndexKey(ctx, stateCode, districtCode, surveyNo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create survey index key: %v", err)
+		return nil, internalError("failed to create survey index key: %v", err)
 	}
 
 	propertyIDBytes, err := ctx.GetStub().GetState(surveyKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read survey index: %v", err)
+		return nil, internalError("failed to read survey index: %v", err)
 	}
 	if propertyIDBytes == nil {
-		return nil, fmt.Errorf("PROPERTY_NOT_FOUND: no property for survey %s/%s/%s", stateCode, districtCode, surveyNo)
+		return nil, newError(ErrCodePropertyNotFound, "no property for survey %s/%s/%s", stateCode, districtCode, surveyNo)
 	}
 
 	return s.GetProperty(ctx, string(propertyIDBytes))
@@ -370,7 +591,7 @@ func (s *LandRegistryContract) QueryBySurvey(ctx contractapi.TransactionContextI
 // location. Uses the LOCATION composite key index for hierarchical queries.
 func (s *LandRegistryContract) QueryByLocation(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode, villageCode string) ([]*LandRecord, error) {
 	if stateCode == "" {
-		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
 	}
 
 	attrs := []string{stateCode}
@@ -386,7 +607,7 @@ func (s *LandRegistryContract) QueryByLocation(ctx contractapi.TransactionContex
 
 	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixLocationIndex, attrs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query location index: %v", err)
+		return nil, internalError("failed to query location index: %v", err)
 	}
 	defer iterator.Close()
 
@@ -394,7 +615,7 @@ func (s *LandRegistryContract) QueryByLocation(ctx contractapi.TransactionContex
 	for iterator.HasNext() {
 		kv, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate location index: %v", err)
+			return nil, internalError("failed to iterate location index: %v", err)
 		}
 		propertyID := string(kv.Value)
 		property, err := s.GetProperty(ctx, propertyID)
@@ -421,7 +642,7 @@ func (s *LandRegistryContract) InitiateTransfer(ctx contractapi.TransactionConte
 
 	var transfer TransferRecord
 	if err := json.Unmarshal([]byte(transferJSON), &transfer); err != nil {
-		return "", fmt.Errorf("INVALID_INPUT: failed to parse transfer JSON: %v", err)
+		return "", newError(ErrCodeInvalidInput, "failed to parse transfer JSON: %v", err)
 	}
 
 	// Validate property exists
@@ -440,36 +661,58 @@ func (s *LandRegistryContract) InitiateTransfer(ctx contractapi.TransactionConte
 
 	// Rule 10: Aadhaar mandatory for both parties
 	if transfer.Seller.AadhaarHash == "" || transfer.Buyer.AadhaarHash == "" {
-		return "", fmt.Errorf("AADHAAR_REQUIRED: both seller and buyer must have aadhaarHash")
+		return "", newError(ErrCodeAadhaarRequired, "both seller and buyer must have aadhaarHash")
+	}
+	if err := validateTransferParties(&transfer); err != nil {
+		return "", err
+	}
+	// Signatures and witness flags are recorded only through SignTransfer
+	transfer.Signatures = nil
+	// A party acting through an attorney must name a valid POA
+	if err := checkTransferPOAs(ctx, &transfer); err != nil {
+		return "", err
+	}
+	// Approvals and schedules are recorded after initiation
+	transfer.GovernmentApprovals = nil
+	transfer.ExecuteAfter, transfer.ScheduledBy, transfer.ScheduledAt = "", "", ""
+	for i := range transfer.Witnesses {
+		transfer.Witnesses[i].Signed = false
 	}
 
 	// Rule 1: No transfer if dispute flag active
 	if property.DisputeStatus != "CLEAR" {
-		return "", fmt.Errorf("LAND_DISPUTED: property %s has active dispute, cannot initiate transfer", transfer.PropertyID)
+		return "", newError(ErrCodeLandDisputed, "property %s has active dispute, cannot initiate transfer", transfer.PropertyID)
 	}
 
 	// Check property is not frozen
 	if property.Status == "FROZEN" {
-		return "", fmt.Errorf("LAND_FROZEN: property %s is frozen by court order", transfer.PropertyID)
+		return "", newError(ErrCodeLandFrozen, "property %s is frozen by court order", transfer.PropertyID)
+	}
+	if err := requireNoInvestigationHold(property); err != nil {
+		return "", err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return "", err
 	}
 
 	// Check property is not already in transfer
 	if property.Status == "TRANSFER_IN_PROGRESS" {
-		return "", fmt.Errorf("TRANSFER_IN_PROGRESS: property %s already has an active transfer", transfer.PropertyID)
+		return "", newError(ErrCodeTransferInProgress, "property %s already has an active transfer", transfer.PropertyID)
 	}
 
-	// Rule 6: Encumbrance check mandatory
-	hasEncumbrance, err := hasActiveEncumbrances(ctx, transfer.PropertyID)
+	// Rule 6: Encumbrance check mandatory; crop loans are acknowledged
+	// by the buyer instead (see checkCropLoansAcknowledged)
+	hasEncumbrance, err := hasBlockingEncumbrances(ctx, transfer.PropertyID)
 	if err != nil {
-		return "", fmt.Errorf("failed to check encumbrances: %v", err)
+		return "", internalError("failed to check encumbrances: %v", err)
 	}
 	if hasEncumbrance {
-		return "", fmt.Errorf("LAND_ENCUMBERED: property %s has active encumbrances, cannot initiate transfer", transfer.PropertyID)
+		return "", newError(ErrCodeLandEncumbered, "property %s has active encumbrances, cannot initiate transfer", transfer.PropertyID)
 	}
 
 	// Rule 5: No active cooling period
 	if property.CoolingPeriod.Active {
-		return "", fmt.Errorf("LAND_COOLING_PERIOD: property %s in cooling period until %s", transfer.PropertyID, property.CoolingPeriod.ExpiresAt)
+		return "", newError(ErrCodeLandCoolingPeriod, "property %s in cooling period until %s", transfer.PropertyID, property.CoolingPeriod.ExpiresAt)
 	}
 
 	// Rule 4: Verify seller is current owner
@@ -481,7 +724,12 @@ func (s *LandRegistryContract) InitiateTransfer(ctx contractapi.TransactionConte
 		}
 	}
 	if !sellerIsOwner {
-		return "", fmt.Errorf("TRANSFER_INVALID_OWNER: seller %s is not a current owner of %s", transfer.Seller.Name, transfer.PropertyID)
+		return "", newError(ErrCodeTransferInvalidOwner, "seller %s is not a current owner of %s", transfer.Seller.Name, transfer.PropertyID)
+	}
+
+	// Forest and notified land need the named authority's clearance
+	if err := requireClassificationClearance(ctx, property, &transfer); err != nil {
+		return "", err
 	}
 
 	// Generate transfer ID
@@ -507,27 +755,36 @@ func (s *LandRegistryContract) InitiateTransfer(ctx contractapi.TransactionConte
 	// Store transfer
 	transferKey, err := createTransferKey(ctx, transfer.TransferID)
 	if err != nil {
-		return "", fmt.Errorf("failed to create transfer key: %v", err)
+		return "", internalError("failed to create transfer key: %v", err)
 	}
-	transferBytes, err := json.Marshal(transfer)
+	transferBytes, err := canonicalMarshal(transfer)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal transfer: %v", err)
+		return "", internalError("failed to marshal transfer: %v", err)
 	}
 	if err := ctx.GetStub().PutState(transferKey, transferBytes); err != nil {
-		return "", fmt.Errorf("failed to put transfer state: %v", err)
+		return "", internalError("failed to put transfer state: %v", err)
+	}
+	if err := countNewRecord(ctx, property.Location.StateCode, "transferRecord", transfer.Status); err != nil {
+		return "", err
 	}
 
 	// Update property status to TRANSFER_IN_PROGRESS
-	property.Status = "TRANSFER_IN_PROGRESS"
+	if err := setPropertyStatus(ctx, property, "TRANSFER_IN_PROGRESS", "transfer "+transfer.TransferID+" initiated"); err != nil {
+		return "", err
+	}
 	property.UpdatedAt = now
 	property.UpdatedBy = getCallerID(ctx)
 	landKey, _ := createLandKey(ctx, property.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return "", fmt.Errorf("failed to update property status: %v", err)
+		return "", internalError("failed to update property status: %v", err)
 	}
 
 	// Emit event
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return "", err
+	}
 	event := TransferEvent{
 		Type:              "TRANSFER_INITIATED",
 		TransferID:        transfer.TransferID,
@@ -538,6 +795,9 @@ func (s *LandRegistryContract) InitiateTransfer(ctx contractapi.TransactionConte
 		Timestamp:         now,
 		StateCode:         property.Location.StateCode,
 		ChannelID:         ctx.GetStub().GetChannelID(),
+		PreviousOwners:    ownerShares(property.CurrentOwner.Owners),
+		NewOwners:         transferBuyerShares(&transfer),
+		RecordHash:        recordHash,
 	}
 	if err := emitEvent(ctx, "TRANSFER_INITIATED", event); err != nil {
 		return "", err
@@ -561,13 +821,21 @@ func (s *LandRegistryContract) InitiateTransfer(ctx contractapi.TransactionConte
 //  9. Never overwrite; always append
 //  10. Aadhaar mandatory
 //
+// Where the state sets HighValueTransferThreshold and the transfer's
+// applicable value exceeds it, no state changes are made: the transfer
+// moves to AWAITING_SECOND_APPROVAL until a different registrar calls
+// ConfirmHighValueTransfer.
+//
+// A transfer scheduled with ScheduleTransferExecution fails with
+// TRANSFER_NOT_YET_DUE before its executeAfter time.
+//
 // Only users with the "registrar" role can execute transfers.
-func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContextInterface, transferID string) error {
+func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContextInterface, transferID string) (*Receipt, error) {
 	// ========================================
 	// STEP 1: IDENTITY & AUTHORIZATION
 	// ========================================
 	if err := requireRole(ctx, "registrar"); err != nil {
-		return err
+		return nil, err
 	}
 
 	// ========================================
@@ -575,21 +843,25 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 	// ========================================
 	transferKey, err := createTransferKey(ctx, transferID)
 	if err != nil {
-		return fmt.Errorf("failed to create transfer key: %v", err)
+		return nil, internalError("failed to create transfer key: %v", err)
 	}
 	transferBytes, err := ctx.GetStub().GetState(transferKey)
 	if err != nil || transferBytes == nil {
-		return fmt.Errorf("TRANSFER_NOT_FOUND: %s", transferID)
+		return nil, newError(ErrCodeTransferNotFound, "%s", transferID)
 	}
 
 	var transfer TransferRecord
 	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
-		return fmt.Errorf("failed to unmarshal transfer: %v", err)
+		return nil, internalError("failed to unmarshal transfer: %v", err)
 	}
 
 	// Verify transfer is in correct state
 	if transfer.Status != "SIGNATURES_COMPLETE" {
-		return fmt.Errorf("TRANSFER_INVALID_STATE: expected SIGNATURES_COMPLETE, got %s", transfer.Status)
+		return nil, newError(ErrCodeTransferInvalidState, "expected SIGNATURES_COMPLETE, got %s", transfer.Status)
+	}
+	// A scheduled transfer waits for its execution time
+	if err := requireTransferDue(ctx, &transfer); err != nil {
+		return nil, err
 	}
 
 	// ========================================
@@ -597,47 +869,105 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 	// ========================================
 	property, err := s.GetProperty(ctx, transfer.PropertyID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// State boundary check
 	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
-		return err
+		return nil, err
+	}
+	if transfer.ExecuteAfter != "" {
+		if err := deleteScheduleIndex(ctx, property.Location.StateCode, &transfer); err != nil {
+			return nil, err
+		}
 	}
 
 	// ========================================
 	// STEP 4: BUSINESS RULE VALIDATION (ALL 10)
 	// ========================================
 
+	if err := checkTransferRules(ctx, &transfer, property); err != nil {
+		return nil, err
+	}
+
+	// Above the state's threshold a second registrar must confirm
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	if requiresSecondApproval(&transfer, settings) {
+		return awaitSecondApproval(ctx, &transfer, transferKey, property)
+	}
+
+	return s.applyTransfer(ctx, &transfer, transferKey, property, nil)
+}
+
+// checkTransferRules enforces the business rules ExecuteTransfer and
+// ConfirmHighValueTransfer check before any state changes.
+func checkTransferRules(ctx contractapi.TransactionContextInterface, transfer *TransferRecord, property *LandRecord) error {
 	// Rule 10: Aadhaar mandatory — verify both parties
 	if transfer.Seller.AadhaarHash == "" || transfer.Buyer.AadhaarHash == "" {
-		return fmt.Errorf("AADHAAR_REQUIRED: both seller and buyer must have aadhaarHash")
+		return newError(ErrCodeAadhaarRequired, "both seller and buyer must have aadhaarHash")
+	}
+	if err := validateTransferParties(transfer); err != nil {
+		return err
 	}
 
 	// Rule 1: No transfer if disputed
 	if property.DisputeStatus != "CLEAR" {
-		return fmt.Errorf("LAND_DISPUTED: property %s has active dispute", transfer.PropertyID)
+		return newError(ErrCodeLandDisputed, "property %s has active dispute", transfer.PropertyID)
 	}
 
 	// Rule 1 (continued): No transfer if frozen by court
 	if property.Status == "FROZEN" {
-		return fmt.Errorf("LAND_FROZEN: property %s is frozen by court order", transfer.PropertyID)
+		return newError(ErrCodeLandFrozen, "property %s is frozen by court order", transfer.PropertyID)
+	}
+	if err := requireNoInvestigationHold(property); err != nil {
+		return err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return err
 	}
 
 	// Rule 6: Encumbrance check mandatory
 	if property.EncumbranceStatus != "CLEAR" {
 		activeEncumbrances, err := getActiveEncumbrances(ctx, transfer.PropertyID)
 		if err != nil {
-			return fmt.Errorf("failed to check encumbrances: %v", err)
+			return internalError("failed to check encumbrances: %v", err)
 		}
 		for _, enc := range activeEncumbrances {
 			if enc.Type == "MORTGAGE" && !transfer.BankConsent {
-				return fmt.Errorf("LAND_ENCUMBERED: mortgage by %s requires bank consent before transfer", enc.Institution.Name)
+				return newError(ErrCodeLandEncumbered, "mortgage by %s requires bank consent before transfer", enc.Institution.Name)
 			}
 			if enc.Type == "COURT_ORDER" {
-				return fmt.Errorf("LAND_ENCUMBERED: court order encumbrance %s must be released before transfer", enc.EncumbranceID)
+				return newError(ErrCodeLandEncumbered, "court order encumbrance %s must be released before transfer", enc.EncumbranceID)
 			}
 		}
+		if err := checkCropLoansAcknowledged(transfer, activeEncumbrances); err != nil {
+			return err
+		}
+	}
+
+	// Land revenue dues, where the state requires clearance
+	if err := requireTaxClearance(ctx, property, transfer); err != nil {
+		return err
+	}
+
+	// Forest and notified land need the named authority's clearance
+	if err := requireClassificationClearance(ctx, property, transfer); err != nil {
+		return err
+	}
+
+	// POAs may have been revoked or expired since initiation
+	if err := checkTransferPOAs(ctx, transfer); err != nil {
+		return err
+	}
+
+	// Leases pass with the land but must be disclosed to the buyer
+	if property.LeaseStatus == "LEASED" {
+		if err := checkLeasesDisclosed(ctx, transfer); err != nil {
+			return err
+		}
 	}
 
 	// Rule 4: Verify seller is current owner
@@ -649,47 +979,78 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 		}
 	}
 	if !sellerIsOwner {
-		return fmt.Errorf("TRANSFER_INVALID_OWNER: seller is not current owner")
+		return newError(ErrCodeTransferInvalidOwner, "seller is not current owner")
+	}
+
+	// Land ceiling, where the state enforces it
+	if err := checkCeiling(ctx, property, transfer.Buyer.AadhaarHash, 100, transfer.CeilingExemptionOrderRef); err != nil {
+		return err
 	}
 
 	// Rule 5 (no active cooling period): Check cooling period
 	if property.CoolingPeriod.Active {
-		return fmt.Errorf("LAND_COOLING_PERIOD: property in cooling period until %s", property.CoolingPeriod.ExpiresAt)
+		return newError(ErrCodeLandCoolingPeriod, "property in cooling period until %s", property.CoolingPeriod.ExpiresAt)
 	}
 
 	// Rule 2: Stamp duty must be paid and calculated against circle rate
 	if transfer.TransactionDetails.StampDutyAmount == 0 {
-		return fmt.Errorf("TRANSFER_STAMP_DUTY_UNPAID: stamp duty amount cannot be zero")
+		return newError(ErrCodeTransferStampDutyUnpaid, "stamp duty amount cannot be zero")
 	}
 
 	// Rule 2 (anti-benami): Declared value must be >= circle rate value
 	if transfer.TransactionDetails.DeclaredValue < transfer.TransactionDetails.CircleRateValue {
-		return fmt.Errorf("TRANSFER_UNDERVALUED: declared value (%d paisa) is below circle rate (%d paisa)", transfer.TransactionDetails.DeclaredValue, transfer.TransactionDetails.CircleRateValue)
+		return newError(ErrCodeTransferUndervalued, "declared value (%d paisa) is below circle rate (%d paisa)", transfer.TransactionDetails.DeclaredValue, transfer.TransactionDetails.CircleRateValue)
 	}
 
 	// Rule 4: Minor's property requires court order
 	for _, owner := range property.CurrentOwner.Owners {
 		if owner.IsMinor && transfer.CourtOrderRef == "" {
-			return fmt.Errorf("TRANSFER_MINOR_PROPERTY: court order required for transfer of minor's property (owner: %s)", owner.Name)
+			return newError(ErrCodeTransferMinorProperty, "court order required for transfer of minor's property (owner: %s)", owner.Name)
 		}
 	}
 
 	// Rule 5: NRI transfers require FEMA compliance check
 	if transfer.IsNRI && !transfer.FEMACompliance {
-		return fmt.Errorf("TRANSFER_FEMA_REQUIRED: NRI transfer requires FEMA compliance clearance")
+		return newError(ErrCodeTransferFemaRequired, "NRI transfer requires FEMA compliance clearance")
 	}
 
-	// Rule 7: Two-witness digital signatures required
+	// Rule 7: Two-witness digital signatures required, except where the
+	// government is a party (registration practice exempts them)
 	signedWitnesses := 0
 	for _, w := range transfer.Witnesses {
 		if w.Signed && w.AadhaarHash != "" {
 			signedWitnesses++
 		}
 	}
-	if signedWitnesses < 2 {
-		return fmt.Errorf("TRANSFER_WITNESS_REQUIRED: at least 2 witnesses must have signed, got %d", signedWitnesses)
+	governmentParty := property.CurrentOwner.OwnerType == "GOVERNMENT" || transfer.Buyer.OwnerType == "GOVERNMENT"
+	if signedWitnesses < 2 && !governmentParty {
+		return newError(ErrCodeTransferWitnessRequired, "at least 2 witnesses must have signed, got %d", signedWitnesses)
+	}
+
+	// SC/ST land needs the collector's permission to leave the category
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return err
+	}
+	if err := checkAlienation(property, transfer, settings); err != nil {
+		return err
 	}
 
+	// Government land is disposed of only with every configured
+	// official's approval
+	if property.CurrentOwner.OwnerType == "GOVERNMENT" {
+		return requireGovernmentApprovals(ctx, property, transfer)
+	}
+	return nil
+}
+
+// applyTransfer performs the state changes of an executed transfer:
+// ownership, indexes, transfer status and the automatic mutation, then
+// emits TRANSFER_COMPLETED. approvers lists both registrars of a
+// dual-approved transfer and is nil otherwise.
+func (s *LandRegistryContract) applyTransfer(ctx contractapi.TransactionContextInterface, transfer *TransferRecord, transferKey string, property *LandRecord, approvers []TransferApproval) (*Receipt, error) {
+	transferID := transfer.TransferID
+
 	// ========================================
 	// STEP 5: EXECUTE STATE CHANGES
 	// ========================================
@@ -700,30 +1061,57 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 
 	// Save previous owner info before update (Rule 9: append provenance)
 	previousOwner := property.CurrentOwner
+	previousRegistration := property.RegistrationInfo
+
+	// The new deed's registration number must not have been used before
+	if transfer.RegistrationInfo.RegistrationNumber != "" {
+		if err := claimRegistrationNumber(ctx, transfer.RegistrationInfo, property.PropertyID, transferID, nil); err != nil {
+			return nil, err
+		}
+		property.RegistrationInfo = transfer.RegistrationInfo
+	}
 
 	// 5a. Update property ownership
 	property.CurrentOwner = OwnerInfo{
-		OwnerType: "INDIVIDUAL",
+		OwnerType: transfer.Buyer.OwnerType,
 		Owners: []Owner{{
 			AadhaarHash:     transfer.Buyer.AadhaarHash,
 			Name:            transfer.Buyer.Name,
 			SharePercentage: 100,
-			IsMinor:         false,
+			IsMinor:         transfer.Buyer.IsMinor,
+			Guardian:        transfer.Buyer.Guardian,
+			Entity:          transfer.Buyer.Entity,
 		}},
 		OwnershipType:           previousOwner.OwnershipType,
 		AcquisitionType:         "SALE",
 		AcquisitionDate:         now[:10],
 		AcquisitionDocumentHash: transfer.Documents.SaleDeedHash,
 	}
+	if err := validateOwnership(property.CurrentOwner.Owners); err != nil {
+		return nil, err
+	}
+	if err := validateOwnerInfo(&property.CurrentOwner); err != nil {
+		return nil, err
+	}
 
-	// Rule 8: 72-hour cooling period before finality
-	coolingExpiry := time.Unix(timestamp.Seconds, 0).Add(72 * time.Hour).Format(time.RFC3339)
+	// Rule 8: cooling period before finality, 72 hours unless the state sets another
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	coolingExpiry := time.Unix(timestamp.Seconds, 0).Add(coolingPeriod(settings)).Format(time.RFC3339)
 	property.CoolingPeriod = CoolingPeriod{
-		Active:    true,
-		ExpiresAt: coolingExpiry,
+		Active:     true,
+		ExpiresAt:  coolingExpiry,
+		TransferID: transfer.TransferID,
+	}
+	if err := countCoolingPeriods(ctx, property.Location.StateCode, 1); err != nil {
+		return nil, err
 	}
 
-	property.Status = "ACTIVE"
+	if err := setPropertyStatus(ctx, property, "ACTIVE", "transfer "+transfer.TransferID+" registered"); err != nil {
+		return nil, err
+	}
 	property.UpdatedAt = now
 	property.UpdatedBy = getCallerID(ctx)
 	property.Provenance.Sequence++
@@ -731,31 +1119,51 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 
 	// 5b. Save updated property (Rule 9: Fabric history preserves all versions)
 	landKey, _ := createLandKey(ctx, transfer.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update property: %v", err)
+		return nil, internalError("failed to update property: %v", err)
 	}
 
 	// 5c. Update owner indexes
 	for _, prevOwner := range previousOwner.Owners {
 		_ = deleteOwnerIndex(ctx, prevOwner.AadhaarHash, property.PropertyID)
+		_ = deleteEntityIndex(ctx, prevOwner, property.PropertyID)
 	}
 	for _, newOwner := range property.CurrentOwner.Owners {
 		_ = putOwnerIndex(ctx, newOwner.AadhaarHash, property.PropertyID)
+		_ = putEntityIndex(ctx, newOwner, property.PropertyID)
 	}
 
-	// 5d. Update transfer status
+	// 5d. Update transfer status; cultivators stay on the land
+	cultivationDisclosed, err := carryOverCultivation(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+	transfer.CultivationDisclosed = cultivationDisclosed
+	taxArrearsFlagged, err := flagTaxArrears(ctx, property, transferID)
+	if err != nil {
+		return nil, err
+	}
+	transfer.TaxArrearsFlagged = taxArrearsFlagged
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return nil, err
+	}
 	transfer.Status = "REGISTERED_PENDING_FINALITY"
+	transfer.PreviousOwners = ownerShares(previousOwner.Owners)
+	transfer.PreviousOwnerInfo = &previousOwner
+	transfer.PreviousRegistrationInfo = &previousRegistration
 	transfer.StatusHistory = append(transfer.StatusHistory, StatusEntry{
-		Status: "REGISTERED_PENDING_FINALITY",
-		At:     now,
-		By:     getCallerID(ctx),
+		Status:          "REGISTERED_PENDING_FINALITY",
+		At:              now,
+		By:              getCallerID(ctx),
+		CertFingerprint: fingerprint,
 	})
 	transfer.FabricTxID = txID
 	transfer.UpdatedAt = now
-	transferUpdatedBytes, _ := json.Marshal(transfer)
+	transferUpdatedBytes, _ := canonicalMarshal(transfer)
 	if err := ctx.GetStub().PutState(transferKey, transferUpdatedBytes); err != nil {
-		return fmt.Errorf("failed to update transfer: %v", err)
+		return nil, internalError("failed to update transfer: %v", err)
 	}
 
 	// Rule 3: Mutation is automatic after registration
@@ -773,6 +1181,10 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 		NewOwner: OwnerRef{
 			AadhaarHash: transfer.Buyer.AadhaarHash,
 			Name:        transfer.Buyer.Name,
+			IsMinor:     transfer.Buyer.IsMinor,
+			Guardian:    transfer.Buyer.Guardian,
+			OwnerType:   property.CurrentOwner.OwnerType,
+			Entity:      transfer.Buyer.Entity,
 		},
 		Status:               "AUTO_APPROVED",
 		ApprovedBy:           "system",
@@ -781,9 +1193,12 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 		CreatedAt:            now,
 	}
 	mutationKey, _ := createMutationKey(ctx, mutationID)
-	mutationBytes, _ := json.Marshal(mutation)
+	mutationBytes, _ := canonicalMarshal(mutation)
 	if err := ctx.GetStub().PutState(mutationKey, mutationBytes); err != nil {
-		return fmt.Errorf("failed to create mutation record: %v", err)
+		return nil, internalError("failed to create mutation record: %v", err)
+	}
+	if err := emitMutationCreated(ctx, &mutation, property.Location.StateCode); err != nil {
+		return nil, err
 	}
 
 	// ========================================
@@ -791,6 +1206,10 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 	// ========================================
 
 	// Transfer event for middleware (PostgreSQL sync + Algorand anchoring)
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return nil, err
+	}
 	transferEvent := TransferEvent{
 		Type:              "TRANSFER_COMPLETED",
 		TransferID:        transferID,
@@ -803,12 +1222,154 @@ func (s *LandRegistryContract) ExecuteTransfer(ctx contractapi.TransactionContex
 		DocumentHash:      transfer.Documents.SaleDeedHash,
 		StateCode:         property.Location.StateCode,
 		ChannelID:         ctx.GetStub().GetChannelID(),
+		Approvers:         approvers,
+		PreviousOwners:    transfer.PreviousOwners,
+		NewOwners:         ownerShares(property.CurrentOwner.Owners),
+		RecordHash:        recordHash,
 	}
+	transferEvent.GovernmentApprovals = transfer.GovernmentApprovals
 	if err := emitEvent(ctx, "TRANSFER_COMPLETED", transferEvent); err != nil {
+		return nil, err
+	}
+	if err := emitCoolingPeriodStarted(ctx, property); err != nil {
+		return nil, err
+	}
+	receipt := newReceipt(ctx, transferID, transfer.Status).
+		relate("mutation", mutationID, mutation.Status).
+		relate("property", property.PropertyID, property.Status)
+	receipt.CoolingExpiresAt = coolingExpiry
+	return receipt, nil
+}
+
+// ConfirmHighValueTransfer completes a transfer that ExecuteTransfer
+// held at AWAITING_SECOND_APPROVAL. The caller must be a registrar other
+// than the one who executed it, compared by certificate fingerprint. The
+// transfer rules are checked again before the execution is applied as in
+// ExecuteTransfer. Emits TRANSFER_COMPLETED naming both approvers.
+func (s *LandRegistryContract) ConfirmHighValueTransfer(ctx contractapi.TransactionContextInterface, transferID string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
 		return err
 	}
 
-	return nil
+	transferKey, err := createTransferKey(ctx, transferID)
+	if err != nil {
+		return internalError("failed to create transfer key: %v", err)
+	}
+	transferBytes, err := ctx.GetStub().GetState(transferKey)
+	if err != nil || transferBytes == nil {
+		return newError(ErrCodeTransferNotFound, "%s", transferID)
+	}
+
+	var transfer TransferRecord
+	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
+		return internalError("failed to unmarshal transfer: %v", err)
+	}
+	if transfer.Status != "AWAITING_SECOND_APPROVAL" || transfer.FirstApproval == nil {
+		return newError(ErrCodeTransferInvalidState, "expected AWAITING_SECOND_APPROVAL, got %s", transfer.Status)
+	}
+
+	property, err := s.GetProperty(ctx, transfer.PropertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+	if fingerprint == transfer.FirstApproval.CertFingerprint {
+		return newError(ErrCodeSelfConfirmationDenied, "the registrar who executed %s cannot also confirm it", transferID)
+	}
+
+	// The property may have changed while the transfer waited
+	if err := checkTransferRules(ctx, &transfer, property); err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	approvers := []TransferApproval{
+		*transfer.FirstApproval,
+		{
+			ApproverID:      getCallerID(ctx),
+			CertFingerprint: fingerprint,
+			ApprovedAt:      time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		},
+	}
+	_, err = s.applyTransfer(ctx, &transfer, transferKey, property, approvers)
+	return err
+}
+
+// requiresSecondApproval reports whether a transfer's applicable value,
+// the higher of declared and circle-rate value, exceeds the state's
+// HighValueTransferThreshold.
+func requiresSecondApproval(transfer *TransferRecord, settings *RegistrySettings) bool {
+	if settings.HighValueTransferThreshold <= 0 {
+		return false
+	}
+	applicable := transfer.TransactionDetails.DeclaredValue
+	if transfer.TransactionDetails.CircleRateValue > applicable {
+		applicable = transfer.TransactionDetails.CircleRateValue
+	}
+	return applicable > settings.HighValueTransferThreshold
+}
+
+// awaitSecondApproval records the executing registrar as first approver
+// and holds the transfer at AWAITING_SECOND_APPROVAL without touching the
+// property. Emits TRANSFER_AWAITING_SECOND_APPROVAL.
+func awaitSecondApproval(ctx contractapi.TransactionContextInterface, transfer *TransferRecord, transferKey string, property *LandRecord) (*Receipt, error) {
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	transfer.FirstApproval = &TransferApproval{
+		ApproverID:      getCallerID(ctx),
+		CertFingerprint: fingerprint,
+		ApprovedAt:      now,
+	}
+	transfer.Status = "AWAITING_SECOND_APPROVAL"
+	transfer.StatusHistory = append(transfer.StatusHistory, StatusEntry{
+		Status:          "AWAITING_SECOND_APPROVAL",
+		At:              now,
+		By:              transfer.FirstApproval.ApproverID,
+		CertFingerprint: fingerprint,
+	})
+	transfer.FabricTxID = txID
+	transfer.UpdatedAt = now
+
+	transferBytes, err := canonicalMarshal(transfer)
+	if err != nil {
+		return nil, internalError("failed to marshal transfer: %v", err)
+	}
+	if err := ctx.GetStub().PutState(transferKey, transferBytes); err != nil {
+		return nil, internalError("failed to update transfer: %v", err)
+	}
+
+	event := TransferEvent{
+		Type:              "TRANSFER_AWAITING_SECOND_APPROVAL",
+		TransferID:        transfer.TransferID,
+		PropertyID:        transfer.PropertyID,
+		PreviousOwnerHash: transfer.Seller.AadhaarHash,
+		NewOwnerHash:      transfer.Buyer.AadhaarHash,
+		FabricTxID:        txID,
+		Timestamp:         now,
+		StateCode:         property.Location.StateCode,
+		ChannelID:         ctx.GetStub().GetChannelID(),
+		Approvers:         []TransferApproval{*transfer.FirstApproval},
+		PreviousOwners:    ownerShares(property.CurrentOwner.Owners),
+		NewOwners:         transferBuyerShares(transfer),
+	}
+	if err := emitEvent(ctx, "TRANSFER_AWAITING_SECOND_APPROVAL", event); err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, transfer.TransferID, transfer.Status), nil
 }
 
 // CancelTransfer cancels a pending transfer and resets the property
@@ -820,21 +1381,25 @@ func (s *LandRegistryContract) CancelTransfer(ctx contractapi.TransactionContext
 
 	transferKey, err := createTransferKey(ctx, transferID)
 	if err != nil {
-		return fmt.Errorf("failed to create transfer key: %v", err)
+		return internalError("failed to create transfer key: %v", err)
 	}
 	transferBytes, err := ctx.GetStub().GetState(transferKey)
 	if err != nil || transferBytes == nil {
-		return fmt.Errorf("TRANSFER_NOT_FOUND: %s", transferID)
+		return newError(ErrCodeTransferNotFound, "%s", transferID)
 	}
 
 	var transfer TransferRecord
 	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
-		return fmt.Errorf("failed to unmarshal transfer: %v", err)
+		return internalError("failed to unmarshal transfer: %v", err)
 	}
 
 	// Cannot cancel an already finalized transfer
 	if transfer.Status == "REGISTERED_FINAL" {
-		return fmt.Errorf("TRANSFER_ALREADY_FINAL: cannot cancel a finalized transfer")
+		return newError(ErrCodeTransferAlreadyFinal, "cannot cancel a finalized transfer")
+	}
+	// A registered transfer has changed the owners; only a rollback undoes that
+	if transfer.Status == "REGISTERED_PENDING_FINALITY" || transfer.Status == "ROLLED_BACK" {
+		return newError(ErrCodeTransferInvalidState, "cannot cancel a %s transfer; see RollbackTransfer", transfer.Status)
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -851,9 +1416,9 @@ func (s *LandRegistryContract) CancelTransfer(ctx contractapi.TransactionContext
 	transfer.FabricTxID = txID
 	transfer.UpdatedAt = now
 
-	transferUpdatedBytes, _ := json.Marshal(transfer)
+	transferUpdatedBytes, _ := canonicalMarshal(transfer)
 	if err := ctx.GetStub().PutState(transferKey, transferUpdatedBytes); err != nil {
-		return fmt.Errorf("failed to update transfer: %v", err)
+		return internalError("failed to update transfer: %v", err)
 	}
 
 	// Reset property status to ACTIVE
@@ -861,16 +1426,27 @@ func (s *LandRegistryContract) CancelTransfer(ctx contractapi.TransactionContext
 	if err != nil {
 		return err
 	}
-	property.Status = "ACTIVE"
+	if transfer.ExecuteAfter != "" {
+		if err := deleteScheduleIndex(ctx, property.Location.StateCode, &transfer); err != nil {
+			return err
+		}
+	}
+	if err := setPropertyStatus(ctx, property, "ACTIVE", "transfer "+transferID+" cancelled: "+reason); err != nil {
+		return err
+	}
 	property.UpdatedAt = now
 	property.UpdatedBy = getCallerID(ctx)
 
 	landKey, _ := createLandKey(ctx, transfer.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to reset property status: %v", err)
+		return internalError("failed to reset property status: %v", err)
 	}
 
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return err
+	}
 	event := TransferEvent{
 		Type:              "TRANSFER_CANCELLED",
 		TransferID:        transferID,
@@ -881,41 +1457,70 @@ func (s *LandRegistryContract) CancelTransfer(ctx contractapi.TransactionContext
 		Timestamp:         now,
 		StateCode:         property.Location.StateCode,
 		ChannelID:         ctx.GetStub().GetChannelID(),
+		PreviousOwners:    ownerShares(property.CurrentOwner.Owners),
+		NewOwners:         transferBuyerShares(&transfer),
+		RecordHash:        recordHash,
 	}
 	return emitEvent(ctx, "TRANSFER_CANCELLED", event)
 }
 
+// GetTransfer retrieves a transfer record by its ID. Accessible by
+// official roles, and by citizens who are the transfer's seller or buyer.
+func (s *LandRegistryContract) GetTransfer(ctx contractapi.TransactionContextInterface, transferID string) (*TransferRecord, error) {
+	transferKey, err := createTransferKey(ctx, transferID)
+	if err != nil {
+		return nil, internalError("failed to create transfer key: %v", err)
+	}
+	transferBytes, err := ctx.GetStub().GetState(transferKey)
+	if err != nil || transferBytes == nil {
+		return nil, newError(ErrCodeTransferNotFound, "%s", transferID)
+	}
+
+	var transfer TransferRecord
+	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
+		return nil, internalError("failed to unmarshal transfer: %v", err)
+	}
+
+	parties := []string{transfer.Seller.AadhaarHash, transfer.Buyer.AadhaarHash}
+	if err := requireSelfOrRole(ctx, parties, officialRoles...); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
 // FinalizeAfterCooling finalizes a transfer after the 72-hour cooling
 // period has expired. This sets the transfer status to REGISTERED_FINAL
-// and deactivates the cooling period on the property.
-func (s *LandRegistryContract) FinalizeAfterCooling(ctx contractapi.TransactionContextInterface, transferID string) error {
+// and deactivates the cooling period on the property. Emits
+// TRANSFER_FINALIZED, listing COOLING_PERIOD_ENDED with outcome
+// FINALIZED.
+func (s *LandRegistryContract) FinalizeAfterCooling(ctx contractapi.TransactionContextInterface, transferID string) (*Receipt, error) {
 	// Either registrar or admin can finalize (system-triggered via BullMQ job)
 	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
-		return err
+		return nil, err
 	}
 
 	transferKey, err := createTransferKey(ctx, transferID)
 	if err != nil {
-		return fmt.Errorf("failed to create transfer key: %v", err)
+		return nil, internalError("failed to create transfer key: %v", err)
 	}
 	transferBytes, err := ctx.GetStub().GetState(transferKey)
 	if err != nil || transferBytes == nil {
-		return fmt.Errorf("TRANSFER_NOT_FOUND: %s", transferID)
+		return nil, newError(ErrCodeTransferNotFound, "%s", transferID)
 	}
 
 	var transfer TransferRecord
 	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
-		return fmt.Errorf("failed to unmarshal transfer: %v", err)
+		return nil, internalError("failed to unmarshal transfer: %v", err)
 	}
 
 	if transfer.Status != "REGISTERED_PENDING_FINALITY" {
-		return fmt.Errorf("TRANSFER_INVALID_STATE: expected REGISTERED_PENDING_FINALITY, got %s", transfer.Status)
+		return nil, newError(ErrCodeTransferInvalidState, "expected REGISTERED_PENDING_FINALITY, got %s", transfer.Status)
 	}
 
 	// Verify cooling period has expired
 	property, err := s.GetProperty(ctx, transfer.PropertyID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -925,38 +1530,20 @@ func (s *LandRegistryContract) FinalizeAfterCooling(ctx contractapi.TransactionC
 	if property.CoolingPeriod.Active && property.CoolingPeriod.ExpiresAt != "" {
 		expiresAt, err := time.Parse(time.RFC3339, property.CoolingPeriod.ExpiresAt)
 		if err == nil && nowTime.Before(expiresAt) {
-			return fmt.Errorf("COOLING_PERIOD_ACTIVE: cooling period expires at %s, current time is %s", property.CoolingPeriod.ExpiresAt, now)
+			return nil, newError(ErrCodeCoolingPeriodActive, "cooling period expires at %s, current time is %s", property.CoolingPeriod.ExpiresAt, now)
 		}
 	}
 
 	txID := ctx.GetStub().GetTxID()
 
-	// Finalize transfer
-	transfer.Status = "REGISTERED_FINAL"
-	transfer.StatusHistory = append(transfer.StatusHistory, StatusEntry{
-		Status: "REGISTERED_FINAL",
-		At:     now,
-		By:     "system",
-	})
-	transfer.FabricTxID = txID
-	transfer.UpdatedAt = now
-
-	transferUpdatedBytes, _ := json.Marshal(transfer)
-	if err := ctx.GetStub().PutState(transferKey, transferUpdatedBytes); err != nil {
-		return fmt.Errorf("failed to finalize transfer: %v", err)
+	if err := finalizeTransfer(ctx, &transfer, transferKey, property); err != nil {
+		return nil, err
 	}
 
-	// Deactivate cooling period on property
-	property.CoolingPeriod = CoolingPeriod{Active: false, ExpiresAt: ""}
-	property.UpdatedAt = now
-	property.UpdatedBy = "system"
-
-	landKey, _ := createLandKey(ctx, transfer.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
-	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update property cooling period: %v", err)
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return nil, err
 	}
-
 	event := TransferEvent{
 		Type:              "TRANSFER_FINALIZED",
 		TransferID:        transferID,
@@ -967,17 +1554,50 @@ func (s *LandRegistryContract) FinalizeAfterCooling(ctx contractapi.TransactionC
 		Timestamp:         now,
 		StateCode:         property.Location.StateCode,
 		ChannelID:         ctx.GetStub().GetChannelID(),
+		PreviousOwners:    transfer.PreviousOwners,
+		NewOwners:         ownerShares(property.CurrentOwner.Owners),
+		RecordHash:        recordHash,
+	}
+	if err := emitEvent(ctx, "TRANSFER_FINALIZED", event); err != nil {
+		return nil, err
 	}
-	return emitEvent(ctx, "TRANSFER_FINALIZED", event)
+	if err := emitCoolingPeriodEnded(ctx, property, transferID, "FINALIZED"); err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, transferID, transfer.Status).relate("property", property.PropertyID, property.Status), nil
 }
 
 // ============================================================
 // MUTATIONS
 // ============================================================
 
+// emitMutationCreated counts and emits MUTATION_CREATED for a newly written
+// mutation record, as a related event (see emitRelatedEvent) so the
+// operation that created it still emits its own event.
+func emitMutationCreated(ctx contractapi.TransactionContextInterface, mutation *MutationRecord, stateCode string) error {
+	if err := countNewRecord(ctx, stateCode, "mutationRecord", mutation.Status); err != nil {
+		return err
+	}
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := MutationCreatedEvent{
+		Type:         "MUTATION_CREATED",
+		MutationID:   mutation.MutationID,
+		PropertyID:   mutation.PropertyID,
+		MutationType: mutation.Type,
+		Status:       mutation.Status,
+		CreatedBy:    getCallerID(ctx),
+		FabricTxID:   ctx.GetStub().GetTxID(),
+		Timestamp:    time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:    stateCode,
+		ChannelID:    ctx.GetStub().GetChannelID(),
+	}
+	return emitRelatedEvent(ctx, "MUTATION_CREATED", "mutationsCreated", event, event)
+}
+
 // ApproveMutation approves a pending mutation (dakhil-kharij).
-// Only Tehsildars can approve non-sale mutations (sale mutations
-// are auto-approved by ExecuteTransfer).
+// Only Tehsildars with jurisdiction over the property can approve
+// non-sale mutations (sale mutations are auto-approved by
+// ExecuteTransfer).
 func (s *LandRegistryContract) ApproveMutation(ctx contractapi.TransactionContextInterface, mutationID string) error {
 	if err := requireRole(ctx, "tehsildar"); err != nil {
 		return err
@@ -985,27 +1605,37 @@ func (s *LandRegistryContract) ApproveMutation(ctx contractapi.TransactionContex
 
 	mutationKey, err := createMutationKey(ctx, mutationID)
 	if err != nil {
-		return fmt.Errorf("failed to create mutation key: %v", err)
+		return internalError("failed to create mutation key: %v", err)
 	}
 	mutationBytes, err := ctx.GetStub().GetState(mutationKey)
 	if err != nil || mutationBytes == nil {
-		return fmt.Errorf("MUTATION_NOT_FOUND: %s", mutationID)
+		return newError(ErrCodeMutationNotFound, "%s", mutationID)
 	}
 
 	var mutation MutationRecord
 	if err := json.Unmarshal(mutationBytes, &mutation); err != nil {
-		return fmt.Errorf("failed to unmarshal mutation: %v", err)
+		return internalError("failed to unmarshal mutation: %v", err)
 	}
 
 	if mutation.Status != "PENDING_APPROVAL" {
-		return fmt.Errorf("MUTATION_INVALID_STATE: expected PENDING_APPROVAL, got %s", mutation.Status)
+		return newError(ErrCodeMutationInvalidState, "expected PENDING_APPROVAL, got %s", mutation.Status)
 	}
 
-	// State boundary check
-	propertyStateCode := extractStateCode(mutation.PropertyID)
-	if err := requireStateAccess(ctx, propertyStateCode); err != nil {
+	// Jurisdiction check
+	property, err := s.GetProperty(ctx, mutation.PropertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return err
+	}
+	if err := requireNoInvestigationHold(property); err != nil {
 		return err
 	}
+	if err := checkCeiling(ctx, property, mutation.NewOwner.AadhaarHash, 100, mutation.CeilingExemptionOrderRef); err != nil {
+		return err
+	}
+	propertyStateCode := property.Location.StateCode
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
 	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
@@ -1016,28 +1646,45 @@ func (s *LandRegistryContract) ApproveMutation(ctx contractapi.TransactionContex
 	mutation.ApprovedAt = now
 	mutation.RevenueRecordUpdated = true
 
-	mutationUpdatedBytes, _ := json.Marshal(mutation)
+	mutationUpdatedBytes, _ := canonicalMarshal(mutation)
 	if err := ctx.GetStub().PutState(mutationKey, mutationUpdatedBytes); err != nil {
-		return fmt.Errorf("failed to update mutation: %v", err)
+		return internalError("failed to update mutation: %v", err)
+	}
+	if err := countStatusChange(ctx, propertyStateCode, "mutationRecord", "PENDING_APPROVAL", mutation.Status); err != nil {
+		return err
 	}
 
 	// Update property ownership based on mutation
-	property, err := s.GetProperty(ctx, mutation.PropertyID)
-	if err != nil {
+	if err := requireNotArchived(property); err != nil {
 		return err
 	}
 
+	newOwners := []Owner{{
+		AadhaarHash:     mutation.NewOwner.AadhaarHash,
+		Name:            mutation.NewOwner.Name,
+		SharePercentage: 100,
+		IsMinor:         mutation.NewOwner.IsMinor,
+		Guardian:        mutation.NewOwner.Guardian,
+		Entity:          mutation.NewOwner.Entity,
+	}}
+	if err := validateOwnership(newOwners); err != nil {
+		return err
+	}
+	newOwnerInfo := OwnerInfo{OwnerType: mutation.NewOwner.OwnerType, Owners: newOwners}
+	if err := validateOwnerInfo(&newOwnerInfo); err != nil {
+		return err
+	}
+
+	previousOwners := ownerShares(property.CurrentOwner.Owners)
+
 	// Update owner indexes
 	for _, oldOwner := range property.CurrentOwner.Owners {
 		_ = deleteOwnerIndex(ctx, oldOwner.AadhaarHash, property.PropertyID)
+		_ = deleteEntityIndex(ctx, oldOwner, property.PropertyID)
 	}
 
-	property.CurrentOwner.Owners = []Owner{{
-		AadhaarHash:     mutation.NewOwner.AadhaarHash,
-		Name:            mutation.NewOwner.Name,
-		SharePercentage: 100,
-		IsMinor:         false,
-	}}
+	property.CurrentOwner.OwnerType = newOwnerInfo.OwnerType
+	property.CurrentOwner.Owners = newOwners
 	property.CurrentOwner.AcquisitionType = mutation.Type
 	property.CurrentOwner.AcquisitionDate = now[:10]
 	property.UpdatedAt = now
@@ -1046,29 +1693,38 @@ func (s *LandRegistryContract) ApproveMutation(ctx contractapi.TransactionContex
 	property.FabricTxID = txID
 
 	landKey, _ := createLandKey(ctx, property.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update property after mutation: %v", err)
+		return internalError("failed to update property after mutation: %v", err)
 	}
 
 	// Create new owner index
 	_ = putOwnerIndex(ctx, mutation.NewOwner.AadhaarHash, property.PropertyID)
+	_ = putEntityIndex(ctx, newOwners[0], property.PropertyID)
 
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return err
+	}
 	event := MutationEvent{
-		Type:         "MUTATION_APPROVED",
-		MutationID:   mutationID,
-		PropertyID:   mutation.PropertyID,
-		MutationType: mutation.Type,
-		FabricTxID:   txID,
-		Timestamp:    now,
-		StateCode:    propertyStateCode,
-		ChannelID:    ctx.GetStub().GetChannelID(),
+		Type:           "MUTATION_APPROVED",
+		MutationID:     mutationID,
+		PropertyID:     mutation.PropertyID,
+		MutationType:   mutation.Type,
+		FabricTxID:     txID,
+		Timestamp:      now,
+		StateCode:      propertyStateCode,
+		ChannelID:      ctx.GetStub().GetChannelID(),
+		PreviousOwners: previousOwners,
+		NewOwners:      ownerShares(newOwners),
+		RecordHash:     recordHash,
 	}
 	return emitEvent(ctx, "MUTATION_APPROVED", event)
 }
 
 // RejectMutation rejects a pending mutation with a reason.
-// Only Tehsildars can reject mutations.
+// Only Tehsildars with jurisdiction over the property can reject
+// mutations.
 func (s *LandRegistryContract) RejectMutation(ctx contractapi.TransactionContextInterface, mutationID, reason string) error {
 	if err := requireRole(ctx, "tehsildar"); err != nil {
 		return err
@@ -1076,26 +1732,30 @@ func (s *LandRegistryContract) RejectMutation(ctx contractapi.TransactionContext
 
 	mutationKey, err := createMutationKey(ctx, mutationID)
 	if err != nil {
-		return fmt.Errorf("failed to create mutation key: %v", err)
+		return internalError("failed to create mutation key: %v", err)
 	}
 	mutationBytes, err := ctx.GetStub().GetState(mutationKey)
 	if err != nil || mutationBytes == nil {
-		return fmt.Errorf("MUTATION_NOT_FOUND: %s", mutationID)
+		return newError(ErrCodeMutationNotFound, "%s", mutationID)
 	}
 
 	var mutation MutationRecord
 	if err := json.Unmarshal(mutationBytes, &mutation); err != nil {
-		return fmt.Errorf("failed to unmarshal mutation: %v", err)
+		return internalError("failed to unmarshal mutation: %v", err)
 	}
 
 	if mutation.Status != "PENDING_APPROVAL" {
-		return fmt.Errorf("MUTATION_INVALID_STATE: expected PENDING_APPROVAL, got %s", mutation.Status)
+		return newError(ErrCodeMutationInvalidState, "expected PENDING_APPROVAL, got %s", mutation.Status)
 	}
 
-	propertyStateCode := extractStateCode(mutation.PropertyID)
-	if err := requireStateAccess(ctx, propertyStateCode); err != nil {
+	property, err := s.GetProperty(ctx, mutation.PropertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
 		return err
 	}
+	propertyStateCode := property.Location.StateCode
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
 	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
@@ -1107,9 +1767,12 @@ func (s *LandRegistryContract) RejectMutation(ctx contractapi.TransactionContext
 	mutation.RejectedReason = reason
 	mutation.RevenueRecordUpdated = false
 
-	mutationUpdatedBytes, _ := json.Marshal(mutation)
+	mutationUpdatedBytes, _ := canonicalMarshal(mutation)
 	if err := ctx.GetStub().PutState(mutationKey, mutationUpdatedBytes); err != nil {
-		return fmt.Errorf("failed to update mutation: %v", err)
+		return internalError("failed to update mutation: %v", err)
+	}
+	if err := countStatusChange(ctx, propertyStateCode, "mutationRecord", "PENDING_APPROVAL", mutation.Status); err != nil {
+		return err
 	}
 
 	event := MutationEvent{
@@ -1130,26 +1793,54 @@ func (s *LandRegistryContract) RejectMutation(ctx contractapi.TransactionContext
 // ============================================================
 
 // AddEncumbrance adds a new encumbrance (mortgage, lien, court order)
-// to a property. Only banks and courts can add encumbrances.
-func (s *LandRegistryContract) AddEncumbrance(ctx contractapi.TransactionContextInterface, encumbranceJSON string) error {
-	if _, err := requireAnyRole(ctx, "bank", "court", "admin"); err != nil {
-		return err
+// to a property. Only banks and courts can add encumbrances. A bank's
+// encumbrance is always recorded under its own MSP ID; courts and admins
+// must name a registered institution (see RegisterInstitution). A repeat
+// call with the same requestId returns the original encumbrance as a
+// duplicate result (see findRequest). Easements are recorded with
+// AddEasement.
+func (s *LandRegistryContract) AddEncumbrance(ctx contractapi.TransactionContextInterface, encumbranceJSON string) (*Receipt, error) {
+	role, err := requireAnyRole(ctx, "bank", "court", "admin")
+	if err != nil {
+		return nil, err
 	}
 
 	var enc EncumbranceRecord
 	if err := json.Unmarshal([]byte(encumbranceJSON), &enc); err != nil {
-		return fmt.Errorf("INVALID_INPUT: failed to parse encumbrance JSON: %v", err)
+		return nil, newError(ErrCodeInvalidInput, "failed to parse encumbrance JSON: %v", err)
+	}
+	if enc.Type == "EASEMENT" {
+		return nil, newError(ErrCodeValidationError, "easements are recorded by a registrar with AddEasement")
+	}
+	enc.Easement = nil
+	if err := bindInstitution(ctx, role, &enc.Institution); err != nil {
+		return nil, err
+	}
+	if enc.RequestID != "" {
+		if prior, err := findRequest(ctx, enc.RequestID, "AddEncumbrance", encumbranceJSON); err != nil || prior != nil {
+			return prior, err
+		}
 	}
 
 	// Validate property exists
 	property, err := s.GetProperty(ctx, enc.PropertyID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Cannot add encumbrance to frozen property
 	if property.Status == "FROZEN" {
-		return fmt.Errorf("LAND_FROZEN: cannot add encumbrance to frozen property %s", enc.PropertyID)
+		return nil, newError(ErrCodeLandFrozen, "cannot add encumbrance to frozen property %s", enc.PropertyID)
+	}
+	if err := requireNotArchived(property); err != nil {
+		return nil, err
+	}
+	if enc.Type == "CROP_LOAN" {
+		if err := validateCropLoan(ctx, &enc, property); err != nil {
+			return nil, err
+		}
+	} else {
+		enc.CropLoan = nil
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -1168,14 +1859,27 @@ func (s *LandRegistryContract) AddEncumbrance(ctx contractapi.TransactionContext
 	// Store encumbrance with composite key
 	encKey, err := createEncumbranceKey(ctx, enc.PropertyID, enc.EncumbranceID)
 	if err != nil {
-		return fmt.Errorf("failed to create encumbrance key: %v", err)
+		return nil, internalError("failed to create encumbrance key: %v", err)
 	}
-	encBytes, err := json.Marshal(enc)
+	encBytes, err := canonicalMarshal(enc)
 	if err != nil {
-		return fmt.Errorf("failed to marshal encumbrance: %v", err)
+		return nil, internalError("failed to marshal encumbrance: %v", err)
 	}
 	if err := ctx.GetStub().PutState(encKey, encBytes); err != nil {
-		return fmt.Errorf("failed to put encumbrance state: %v", err)
+		return nil, internalError("failed to put encumbrance state: %v", err)
+	}
+	if err := countNewRecord(ctx, property.Location.StateCode, "encumbranceRecord", enc.Status); err != nil {
+		return nil, err
+	}
+	if enc.CropLoan != nil {
+		if err := putCropLoanIndex(ctx, &enc); err != nil {
+			return nil, err
+		}
+	}
+	if enc.RequestID != "" {
+		if err := putRequest(ctx, enc.RequestID, "AddEncumbrance", encumbranceJSON, encKey, enc.EncumbranceID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Update property encumbrance status
@@ -1185,9 +1889,9 @@ func (s *LandRegistryContract) AddEncumbrance(ctx contractapi.TransactionContext
 	property.FabricTxID = txID
 
 	landKey, _ := createLandKey(ctx, enc.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update property encumbrance status: %v", err)
+		return nil, internalError("failed to update property encumbrance status: %v", err)
 	}
 
 	event := EncumbranceEvent{
@@ -1201,43 +1905,38 @@ func (s *LandRegistryContract) AddEncumbrance(ctx contractapi.TransactionContext
 		StateCode:       property.Location.StateCode,
 		ChannelID:       ctx.GetStub().GetChannelID(),
 	}
-	return emitEvent(ctx, "ENCUMBRANCE_ADDED", event)
+	if err := emitEvent(ctx, "ENCUMBRANCE_ADDED", event); err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, enc.EncumbranceID, enc.Status).withRequest(enc.RequestID).relate("property", enc.PropertyID, property.EncumbranceStatus), nil
 }
 
 // ReleaseEncumbrance releases an active encumbrance. Only the
-// institution that created it (or an admin) can release it.
+// institution that created it (or an admin) can release it; easements
+// only an admin.
 func (s *LandRegistryContract) ReleaseEncumbrance(ctx contractapi.TransactionContextInterface, encumbranceID string) error {
-	if _, err := requireAnyRole(ctx, "bank", "court", "admin"); err != nil {
-		return err
-	}
-
-	// We need to find the encumbrance across all properties
-	// Use a rich query on CouchDB (docType + encumbranceId)
-	queryString := fmt.Sprintf(`{"selector":{"docType":"encumbranceRecord","encumbranceId":"%s"}}`, encumbranceID)
-	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	role, err := requireAnyRole(ctx, "bank", "court", "admin")
 	if err != nil {
-		return fmt.Errorf("failed to query encumbrance: %v", err)
-	}
-	defer iterator.Close()
-
-	if !iterator.HasNext() {
-		return fmt.Errorf("ENCUMBRANCE_NOT_FOUND: %s", encumbranceID)
+		return err
 	}
 
-	kv, err := iterator.Next()
+	enc, err := findEncumbrance(ctx, encumbranceID)
 	if err != nil {
-		return fmt.Errorf("failed to read encumbrance: %v", err)
-	}
-
-	var enc EncumbranceRecord
-	if err := json.Unmarshal(kv.Value, &enc); err != nil {
-		return fmt.Errorf("failed to unmarshal encumbrance: %v", err)
+		return err
 	}
-
 	if enc.Status != "ACTIVE" {
-		return fmt.Errorf("ENCUMBRANCE_NOT_ACTIVE: encumbrance %s has status %s", encumbranceID, enc.Status)
+		return newError(ErrCodeEncumbranceNotActive, "encumbrance %s has status %s", encumbranceID, enc.Status)
 	}
+	if enc.Type == "EASEMENT" && role != "admin" {
+		return newError(ErrCodeAccessDenied, "only an admin can release easement %s", encumbranceID)
+	}
+	return s.releaseEncumbrance(ctx, enc)
+}
 
+// releaseEncumbrance marks an active encumbrance RELEASED, clears the
+// property's encumbrance status if it was the last one, and emits
+// ENCUMBRANCE_RELEASED.
+func (s *LandRegistryContract) releaseEncumbrance(ctx contractapi.TransactionContextInterface, enc *EncumbranceRecord) error {
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
 	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
 	txID := ctx.GetStub().GetTxID()
@@ -1246,15 +1945,15 @@ func (s *LandRegistryContract) ReleaseEncumbrance(ctx contractapi.TransactionCon
 
 	// Store updated encumbrance
 	encKey, _ := createEncumbranceKey(ctx, enc.PropertyID, enc.EncumbranceID)
-	encBytes, _ := json.Marshal(enc)
+	encBytes, _ := canonicalMarshal(enc)
 	if err := ctx.GetStub().PutState(encKey, encBytes); err != nil {
-		return fmt.Errorf("failed to update encumbrance: %v", err)
+		return internalError("failed to update encumbrance: %v", err)
 	}
 
 	// Check if any other active encumbrances remain
 	remaining, err := getActiveEncumbrances(ctx, enc.PropertyID)
 	if err != nil {
-		return fmt.Errorf("failed to check remaining encumbrances: %v", err)
+		return internalError("failed to check remaining encumbrances: %v", err)
 	}
 
 	property, err := s.GetProperty(ctx, enc.PropertyID)
@@ -1270,9 +1969,9 @@ func (s *LandRegistryContract) ReleaseEncumbrance(ctx contractapi.TransactionCon
 	property.FabricTxID = txID
 
 	landKey, _ := createLandKey(ctx, enc.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update property encumbrance status: %v", err)
+		return internalError("failed to update property encumbrance status: %v", err)
 	}
 
 	event := EncumbranceEvent{
@@ -1289,8 +1988,83 @@ func (s *LandRegistryContract) ReleaseEncumbrance(ctx contractapi.TransactionCon
 	return emitEvent(ctx, "ENCUMBRANCE_RELEASED", event)
 }
 
+// UpdateEncumbranceOutstanding records a new outstanding amount (in
+// paisa) on an active encumbrance, e.g. after repayments. It cannot
+// exceed the sanctioned amount. Banks can update only their own
+// encumbrances; admins any. Emits ENCUMBRANCE_UPDATED.
+func (s *LandRegistryContract) UpdateEncumbranceOutstanding(ctx contractapi.TransactionContextInterface, encumbranceID string, outstandingAmount int64) error {
+	role, err := requireAnyRole(ctx, "bank", "admin")
+	if err != nil {
+		return err
+	}
+	if outstandingAmount < 0 {
+		return newError(ErrCodeValidationError, "outstandingAmount cannot be negative")
+	}
+
+	queryString := fmt.Sprintf(`{"selector":{"docType":"encumbranceRecord","encumbranceId":"%s"}}`, encumbranceID)
+	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return internalError("failed to query encumbrance: %v", err)
+	}
+	defer iterator.Close()
+	if !iterator.HasNext() {
+		return newError(ErrCodeEncumbranceNotFound, "%s", encumbranceID)
+	}
+	kv, err := iterator.Next()
+	if err != nil {
+		return internalError("failed to read encumbrance: %v", err)
+	}
+	var enc EncumbranceRecord
+	if err := json.Unmarshal(kv.Value, &enc); err != nil {
+		return internalError("failed to unmarshal encumbrance: %v", err)
+	}
+
+	if enc.Status != "ACTIVE" {
+		return newError(ErrCodeEncumbranceNotActive, "encumbrance %s has status %s", encumbranceID, enc.Status)
+	}
+	if role == "bank" {
+		mspID, _ := ctx.GetClientIdentity().GetMSPID()
+		if enc.Institution.MspID != mspID {
+			return newError(ErrCodeAccessDenied, "encumbrance %s is held by %s", encumbranceID, enc.Institution.MspID)
+		}
+	}
+	if enc.Details.SanctionedAmount > 0 && outstandingAmount > enc.Details.SanctionedAmount {
+		return newError(ErrCodeValidationError, "outstandingAmount %d exceeds sanctionedAmount %d", outstandingAmount, enc.Details.SanctionedAmount)
+	}
+	if outstandingAmount == enc.Details.OutstandingAmount {
+		return nil
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	change := FieldChange{
+		Field:    "details.outstandingAmount",
+		OldValue: strconv.FormatInt(enc.Details.OutstandingAmount, 10),
+		NewValue: strconv.FormatInt(outstandingAmount, 10),
+	}
+	enc.Details.OutstandingAmount = outstandingAmount
+	if err := putEncumbrance(ctx, &enc); err != nil {
+		return err
+	}
+
+	event := EncumbranceUpdatedEvent{
+		Type:             "ENCUMBRANCE_UPDATED",
+		EncumbranceID:    enc.EncumbranceID,
+		PropertyID:       enc.PropertyID,
+		InstitutionMspID: enc.Institution.MspID,
+		Changes:          []FieldChange{change},
+		FabricTxID:       ctx.GetStub().GetTxID(),
+		Timestamp:        now,
+		StateCode:        extractStateCode(enc.PropertyID),
+		ChannelID:        ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "ENCUMBRANCE_UPDATED", event)
+}
+
 // GetEncumbrances returns all encumbrances (active and released)
-// for the specified property.
+// for the specified property. Consumers should identify the holder by
+// institution.mspId, which is bound on creation, not by its name.
 func (s *LandRegistryContract) GetEncumbrances(ctx contractapi.TransactionContextInterface, propertyID string) ([]*EncumbranceRecord, error) {
 	if err := validatePropertyID(propertyID); err != nil {
 		return nil, err
@@ -1298,7 +2072,7 @@ func (s *LandRegistryContract) GetEncumbrances(ctx contractapi.TransactionContex
 
 	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixEncumbrance, []string{propertyID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query encumbrances: %v", err)
+		return nil, internalError("failed to query encumbrances: %v", err)
 	}
 	defer iterator.Close()
 
@@ -1306,7 +2080,7 @@ func (s *LandRegistryContract) GetEncumbrances(ctx contractapi.TransactionContex
 	for iterator.HasNext() {
 		kv, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate encumbrances: %v", err)
+			return nil, internalError("failed to iterate encumbrances: %v", err)
 		}
 		var enc EncumbranceRecord
 		if err := json.Unmarshal(kv.Value, &enc); err != nil {
@@ -1323,21 +2097,37 @@ func (s *LandRegistryContract) GetEncumbrances(ctx contractapi.TransactionContex
 
 // FlagDispute flags a legal dispute against a property. Only courts
 // and admins can flag disputes. This changes the property's dispute
-// status to prevent transfers.
-func (s *LandRegistryContract) FlagDispute(ctx contractapi.TransactionContextInterface, disputeJSON string) error {
+// status to prevent transfers. A repeat call with the same requestId
+// returns the original dispute as a duplicate result.
+func (s *LandRegistryContract) FlagDispute(ctx contractapi.TransactionContextInterface, disputeJSON string) (*Receipt, error) {
 	if _, err := requireAnyRole(ctx, "court", "admin"); err != nil {
-		return err
+		return nil, err
 	}
 
 	var dispute DisputeRecord
 	if err := json.Unmarshal([]byte(disputeJSON), &dispute); err != nil {
-		return fmt.Errorf("INVALID_INPUT: failed to parse dispute JSON: %v", err)
+		return nil, newError(ErrCodeInvalidInput, "failed to parse dispute JSON: %v", err)
+	}
+	if dispute.RequestID != "" {
+		if prior, err := findRequest(ctx, dispute.RequestID, "FlagDispute", disputeJSON); err != nil || prior != nil {
+			return prior, err
+		}
+	}
+	if dispute.FiledBy.AadhaarHash != "" {
+		if err := validateAadhaarHash(dispute.FiledBy.AadhaarHash, "filedBy.aadhaarHash"); err != nil {
+			return nil, err
+		}
+	}
+	if dispute.Against.AadhaarHash != "" {
+		if err := validateAadhaarHash(dispute.Against.AadhaarHash, "against.aadhaarHash"); err != nil {
+			return nil, err
+		}
 	}
 
 	// Validate property exists
 	property, err := s.GetProperty(ctx, dispute.PropertyID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -1357,14 +2147,22 @@ func (s *LandRegistryContract) FlagDispute(ctx contractapi.TransactionContextInt
 	// Store dispute
 	disputeKey, err := createDisputeKey(ctx, dispute.PropertyID, dispute.DisputeID)
 	if err != nil {
-		return fmt.Errorf("failed to create dispute key: %v", err)
+		return nil, internalError("failed to create dispute key: %v", err)
 	}
-	disputeBytes, err := json.Marshal(dispute)
+	disputeBytes, err := canonicalMarshal(dispute)
 	if err != nil {
-		return fmt.Errorf("failed to marshal dispute: %v", err)
+		return nil, internalError("failed to marshal dispute: %v", err)
 	}
 	if err := ctx.GetStub().PutState(disputeKey, disputeBytes); err != nil {
-		return fmt.Errorf("failed to put dispute state: %v", err)
+		return nil, internalError("failed to put dispute state: %v", err)
+	}
+	if err := countNewRecord(ctx, property.Location.StateCode, "disputeRecord", dispute.Status); err != nil {
+		return nil, err
+	}
+	if dispute.RequestID != "" {
+		if err := putRequest(ctx, dispute.RequestID, "FlagDispute", disputeJSON, disputeKey, dispute.DisputeID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Update property dispute status (Rule 1: blocks all transfers)
@@ -1374,9 +2172,9 @@ func (s *LandRegistryContract) FlagDispute(ctx contractapi.TransactionContextInt
 	property.FabricTxID = txID
 
 	landKey, _ := createLandKey(ctx, dispute.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update property dispute status: %v", err)
+		return nil, internalError("failed to update property dispute status: %v", err)
 	}
 
 	event := DisputeEvent{
@@ -1389,11 +2187,14 @@ func (s *LandRegistryContract) FlagDispute(ctx contractapi.TransactionContextInt
 		StateCode:   property.Location.StateCode,
 		ChannelID:   ctx.GetStub().GetChannelID(),
 	}
-	return emitEvent(ctx, "DISPUTE_FLAGGED", event)
+	if err := emitEvent(ctx, "DISPUTE_FLAGGED", event); err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, dispute.DisputeID, dispute.Status).withRequest(dispute.RequestID).relate("property", dispute.PropertyID, property.DisputeStatus), nil
 }
 
 // ResolveDispute resolves a dispute with the given resolution.
-// Only courts and admins can resolve disputes.
+// Only courts and admins can resolve disputes. Writes an audit entry.
 func (s *LandRegistryContract) ResolveDispute(ctx contractapi.TransactionContextInterface, disputeID, resolution string) error {
 	if _, err := requireAnyRole(ctx, "court", "admin"); err != nil {
 		return err
@@ -1403,26 +2204,26 @@ func (s *LandRegistryContract) ResolveDispute(ctx contractapi.TransactionContext
 	queryString := fmt.Sprintf(`{"selector":{"docType":"disputeRecord","disputeId":"%s"}}`, disputeID)
 	iterator, err := ctx.GetStub().GetQueryResult(queryString)
 	if err != nil {
-		return fmt.Errorf("failed to query dispute: %v", err)
+		return internalError("failed to query dispute: %v", err)
 	}
 	defer iterator.Close()
 
 	if !iterator.HasNext() {
-		return fmt.Errorf("DISPUTE_NOT_FOUND: %s", disputeID)
+		return newError(ErrCodeDisputeNotFound, "%s", disputeID)
 	}
 
 	kv, err := iterator.Next()
 	if err != nil {
-		return fmt.Errorf("failed to read dispute: %v", err)
+		return internalError("failed to read dispute: %v", err)
 	}
 
 	var dispute DisputeRecord
 	if err := json.Unmarshal(kv.Value, &dispute); err != nil {
-		return fmt.Errorf("failed to unmarshal dispute: %v", err)
+		return internalError("failed to unmarshal dispute: %v", err)
 	}
 
 	if dispute.Status == "RESOLVED_IN_FAVOR" || dispute.Status == "RESOLVED_AGAINST" || dispute.Status == "SETTLED" {
-		return fmt.Errorf("DISPUTE_ALREADY_RESOLVED: %s has status %s", disputeID, dispute.Status)
+		return newError(ErrCodeDisputeAlreadyResolved, "%s has status %s", disputeID, dispute.Status)
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -1434,15 +2235,15 @@ func (s *LandRegistryContract) ResolveDispute(ctx contractapi.TransactionContext
 	dispute.Resolution = resolution
 
 	disputeKey, _ := createDisputeKey(ctx, dispute.PropertyID, dispute.DisputeID)
-	disputeBytes, _ := json.Marshal(dispute)
+	disputeBytes, _ := canonicalMarshal(dispute)
 	if err := ctx.GetStub().PutState(disputeKey, disputeBytes); err != nil {
-		return fmt.Errorf("failed to update dispute: %v", err)
+		return internalError("failed to update dispute: %v", err)
 	}
 
 	// Check if any other active disputes remain for this property
 	activeDisputes, err := getActiveDisputes(ctx, dispute.PropertyID)
 	if err != nil {
-		return fmt.Errorf("failed to check remaining disputes: %v", err)
+		return internalError("failed to check remaining disputes: %v", err)
 	}
 
 	property, err := s.GetProperty(ctx, dispute.PropertyID)
@@ -1458,9 +2259,9 @@ func (s *LandRegistryContract) ResolveDispute(ctx contractapi.TransactionContext
 	property.FabricTxID = txID
 
 	landKey, _ := createLandKey(ctx, dispute.PropertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update property dispute status: %v", err)
+		return internalError("failed to update property dispute status: %v", err)
 	}
 
 	event := DisputeEvent{
@@ -1473,11 +2274,15 @@ func (s *LandRegistryContract) ResolveDispute(ctx contractapi.TransactionContext
 		StateCode:   property.Location.StateCode,
 		ChannelID:   ctx.GetStub().GetChannelID(),
 	}
+	if err := recordAudit(ctx, "ResolveDispute", dispute.DisputeID); err != nil {
+		return err
+	}
 	return emitEvent(ctx, "DISPUTE_RESOLVED", event)
 }
 
 // FreezeProperty freezes a property by court order. A frozen property
 // cannot be transferred, encumbered, or modified until unfrozen.
+// Writes an audit entry.
 func (s *LandRegistryContract) FreezeProperty(ctx contractapi.TransactionContextInterface, propertyID, courtOrderRef string) error {
 	if _, err := requireAnyRole(ctx, "court", "admin"); err != nil {
 		return err
@@ -1489,26 +2294,28 @@ func (s *LandRegistryContract) FreezeProperty(ctx contractapi.TransactionContext
 	}
 
 	if property.Status == "FROZEN" {
-		return fmt.Errorf("PROPERTY_ALREADY_FROZEN: %s is already frozen", propertyID)
+		return newError(ErrCodePropertyAlreadyFrozen, "%s is already frozen", propertyID)
 	}
 
 	if courtOrderRef == "" {
-		return fmt.Errorf("VALIDATION_ERROR: courtOrderRef is required to freeze a property")
+		return newError(ErrCodeValidationError, "courtOrderRef is required to freeze a property")
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
 	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
 	txID := ctx.GetStub().GetTxID()
 
-	property.Status = "FROZEN"
+	if err := setPropertyStatus(ctx, property, "FROZEN", "frozen by court order "+courtOrderRef); err != nil {
+		return err
+	}
 	property.UpdatedAt = now
 	property.UpdatedBy = getCallerID(ctx)
 	property.FabricTxID = txID
 
 	landKey, _ := createLandKey(ctx, propertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to freeze property: %v", err)
+		return internalError("failed to freeze property: %v", err)
 	}
 
 	event := PropertyFrozenEvent{
@@ -1520,10 +2327,14 @@ func (s *LandRegistryContract) FreezeProperty(ctx contractapi.TransactionContext
 		StateCode:     property.Location.StateCode,
 		ChannelID:     ctx.GetStub().GetChannelID(),
 	}
+	if err := recordAudit(ctx, "FreezeProperty", propertyID); err != nil {
+		return err
+	}
 	return emitEvent(ctx, "PROPERTY_FROZEN", event)
 }
 
 // UnfreezeProperty removes the freeze on a property by court order.
+// Writes an audit entry.
 func (s *LandRegistryContract) UnfreezeProperty(ctx contractapi.TransactionContextInterface, propertyID, courtOrderRef string) error {
 	if _, err := requireAnyRole(ctx, "court", "admin"); err != nil {
 		return err
@@ -1535,26 +2346,28 @@ func (s *LandRegistryContract) UnfreezeProperty(ctx contractapi.TransactionConte
 	}
 
 	if property.Status != "FROZEN" {
-		return fmt.Errorf("PROPERTY_NOT_FROZEN: %s has status %s", propertyID, property.Status)
+		return newError(ErrCodePropertyNotFrozen, "%s has status %s", propertyID, property.Status)
 	}
 
 	if courtOrderRef == "" {
-		return fmt.Errorf("VALIDATION_ERROR: courtOrderRef is required to unfreeze a property")
+		return newError(ErrCodeValidationError, "courtOrderRef is required to unfreeze a property")
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
 	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
 	txID := ctx.GetStub().GetTxID()
 
-	property.Status = "ACTIVE"
+	if err := setPropertyStatus(ctx, property, "ACTIVE", "unfrozen by court order "+courtOrderRef); err != nil {
+		return err
+	}
 	property.UpdatedAt = now
 	property.UpdatedBy = getCallerID(ctx)
 	property.FabricTxID = txID
 
 	landKey, _ := createLandKey(ctx, propertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to unfreeze property: %v", err)
+		return internalError("failed to unfreeze property: %v", err)
 	}
 
 	event := PropertyFrozenEvent{
@@ -1566,6 +2379,9 @@ func (s *LandRegistryContract) UnfreezeProperty(ctx contractapi.TransactionConte
 		StateCode:     property.Location.StateCode,
 		ChannelID:     ctx.GetStub().GetChannelID(),
 	}
+	if err := recordAudit(ctx, "UnfreezeProperty", propertyID); err != nil {
+		return err
+	}
 	return emitEvent(ctx, "PROPERTY_UNFROZEN", event)
 }
 
@@ -1575,40 +2391,108 @@ func (s *LandRegistryContract) UnfreezeProperty(ctx contractapi.TransactionConte
 
 // SplitProperty subdivides a property into multiple smaller plots.
 // The original property is marked as SPLIT and new properties are
-// created with provenance linking back to the original.
-// Only registrars can split properties.
-func (s *LandRegistryContract) SplitProperty(ctx contractapi.TransactionContextInterface, propertyID string, splitsJSON string) error {
+// created with provenance linking back to the original. The parent's
+// owner, survey and location index entries are removed so lookups
+// return the children, and each child's survey number must be free.
+// With encumbranceHandling REQUIRE_CLEAR (the default) the parent must
+// have no active encumbrances; with CARRY_WITH_CONSENT, consentRefsJSON
+// maps each active encumbranceId to its holder's consent and every
+// charge is cloned onto each child. When the parent and children carry GeoJSON,
+// the child plots must match their declared areas, must not overlap and
+// must lie within the parent. Only registrars can split properties, and
+// only in states that do not require tehsildar sanction (see ProposeSplit).
+func (s *LandRegistryContract) SplitProperty(ctx contractapi.TransactionContextInterface, propertyID string, splitsJSON string, encumbranceHandling string, consentRefsJSON string) (*Receipt, error) {
 	if err := requireRole(ctx, "registrar"); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := validatePropertyID(propertyID); err != nil {
-		return err
+		return nil, err
 	}
 
 	property, err := s.GetProperty(ctx, propertyID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
-		return err
+		return nil, err
+	}
+	if err := requireSingleStepSubdivision(ctx, property.Location.StateCode); err != nil {
+		return nil, err
+	}
+
+	handling, consentRefs, err := parseEncumbranceHandling(encumbranceHandling, consentRefsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var splits []SplitRequest
+	if err := json.Unmarshal([]byte(splitsJSON), &splits); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse splits JSON: %v", err)
+	}
+
+	result, err := s.splitParcel(ctx, property, splits, handling, consentRefs)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	event := PropertySplitEvent{
+		Type:                "PROPERTY_SPLIT",
+		OriginalProperty:    propertyID,
+		NewPropertyIDs:      result.NewPropertyIDs,
+		EncumbrancesCarried: result.EncumbrancesCarried,
+		FabricTxID:          txID,
+		Timestamp:           now,
+		StateCode:           property.Location.StateCode,
+		ChannelID:           ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "PROPERTY_SPLIT", event); err != nil {
+		return nil, err
+	}
+	receipt := newReceipt(ctx, propertyID, property.Status)
+	for _, childID := range result.NewPropertyIDs {
+		receipt.relate("property", childID, "ACTIVE")
 	}
+	return receipt.relateCarried(result.EncumbrancesCarried), nil
+}
+
+// splitResult is what splitParcel created.
+type splitResult struct {
+	NewPropertyIDs      []string
+	EncumbrancesCarried []EncumbranceCarriedEvent
+}
 
+// splitParcel carries out a split for SplitProperty, SanctionSplit and
+// PartitionProperty: it validates the children against the parent,
+// creates them with their indexes, carries consented encumbrances and
+// marks the parent SPLIT. The caller checks roles and emits the event.
+func (s *LandRegistryContract) splitParcel(ctx contractapi.TransactionContextInterface, property *LandRecord, splits []SplitRequest, handling string, consentRefs map[string]string) (*splitResult, error) {
 	if property.Status != "ACTIVE" {
-		return fmt.Errorf("PROPERTY_NOT_ACTIVE: cannot split property with status %s", property.Status)
+		return nil, newError(ErrCodePropertyNotActive, "cannot split property with status %s", property.Status)
 	}
 	if property.DisputeStatus != "CLEAR" {
-		return fmt.Errorf("LAND_DISPUTED: cannot split disputed property %s", propertyID)
+		return nil, newError(ErrCodeLandDisputed, "cannot split disputed property %s", property.PropertyID)
+	}
+	if err := requireNoInvestigationHold(property); err != nil {
+		return nil, err
 	}
 
-	var splits []SplitRequest
-	if err := json.Unmarshal([]byte(splitsJSON), &splits); err != nil {
-		return fmt.Errorf("INVALID_INPUT: failed to parse splits JSON: %v", err)
+	// A charge on the parent must not silently vanish from the children
+	encumbrances, err := getActiveEncumbrances(ctx, property.PropertyID)
+	if err != nil {
+		return nil, internalError("failed to check encumbrances: %v", err)
+	}
+	if err := checkEncumbrancesCarriable(encumbrances, property.PropertyID, handling, consentRefs); err != nil {
+		return nil, err
 	}
 
 	if len(splits) < 2 {
-		return fmt.Errorf("VALIDATION_ERROR: split requires at least 2 sub-plots")
+		return nil, newError(ErrCodeValidationError, "split requires at least 2 sub-plots")
 	}
 
 	// Validate total area of splits matches original (with 1% tolerance)
@@ -1618,39 +2502,110 @@ func (s *LandRegistryContract) SplitProperty(ctx contractapi.TransactionContextI
 	}
 	areaRatio := totalSplitArea / property.Area.Value
 	if areaRatio < 0.99 || areaRatio > 1.01 {
-		return fmt.Errorf("AREA_MISMATCH: total split area (%.2f) does not match original (%.2f)", totalSplitArea, property.Area.Value)
+		return nil, newError(ErrCodeAreaMismatch, "total split area (%.2f) does not match original (%.2f)", totalSplitArea, property.Area.Value)
+	}
+
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSplitLimits(property, splits, settings); err != nil {
+		return nil, err
+	}
+
+	// Drawn plots must also add up: no overlaps, nothing outside the parent
+	for i, split := range splits {
+		if err := validateGeoJSON(split.Boundaries.GeoJSON); err != nil {
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
+		}
+	}
+	if err := validateSplitGeometry(property, splits, settings.BighaSqMeters); err != nil {
+		return nil, err
+	}
+	// Easements go only to the plots their strip crosses
+	easementTargets := easementSplitTargets(encumbrances, splits)
+	childAnnotations, err := assignAnnotations(property, splits)
+	if err != nil {
+		return nil, err
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
 	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
 	txID := ctx.GetStub().GetTxID()
 
+	// Release the parent's index slots first, so a child may take over
+	// the parent's survey number
+	if err := removeLookupIndexes(ctx, property); err != nil {
+		return nil, err
+	}
+
 	var newPropertyIDs []string
+	claimedSurveyKeys := make(map[string]string, len(splits))
 
 	for i, split := range splits {
 		if err := validatePropertyID(split.NewPropertyID); err != nil {
-			return fmt.Errorf("split[%d]: %v", i, err)
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
+		}
+		if err := validatePropertyIDMatches(split.NewPropertyID, property.Location, split.SurveyNumber, split.SubSurveyNumber); err != nil {
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
 		}
 
 		// Validate Aadhaar (Rule 10)
 		for _, owner := range split.OwnerInfo.Owners {
 			if owner.AadhaarHash == "" {
-				return fmt.Errorf("split[%d]: AADHAAR_REQUIRED", i)
+				return nil, newError(ErrCodeAadhaarRequired, "split[%d]: every owner must have an aadhaarHash", i)
 			}
 		}
+		if err := validateOwnership(split.OwnerInfo.Owners); err != nil {
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
+		}
+		if err := validateOwnerInfo(&split.OwnerInfo); err != nil {
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
+		}
+		if err := validateAreaUnits(split.Area, settings.BighaSqMeters); err != nil {
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
+		}
 
 		newLandKey, err := createLandKey(ctx, split.NewPropertyID)
 		if err != nil {
-			return fmt.Errorf("split[%d]: failed to create key: %v", i, err)
+			return nil, internalError("split[%d]: failed to create key: %v", i, err)
 		}
 
 		existing, _ := ctx.GetStub().GetState(newLandKey)
 		if existing != nil {
-			return fmt.Errorf("split[%d]: PROPERTY_EXISTS: %s", i, split.NewPropertyID)
+			return nil, newError(ErrCodePropertyExists, "split[%d]: %s", i, split.NewPropertyID)
+		}
+
+		// The child's survey number must not already belong to another
+		// parcel. GetState does not see this transaction's writes, so
+		// the parent's released slot counts as free and siblings are
+		// tracked in claimedSurveyKeys.
+		surveyKey := surveyIndexNumber(split.SurveyNumber, split.SubSurveyNumber)
+		if sibling, ok := claimedSurveyKeys[surveyKey]; ok {
+			return nil, newError(ErrCodeSurveyNumberOccupied, "split[%d]: survey %s is also used by %s in this split", i, surveyKey, sibling)
+		}
+		surveyIndexKey, err := createSurveyIndexKey(ctx, property.Location.StateCode, property.Location.DistrictCode, surveyKey)
+		if err != nil {
+			return nil, internalError("split[%d]: failed to create survey index key: %v", i, err)
+		}
+		holder, err := ctx.GetStub().GetState(surveyIndexKey)
+		if err != nil {
+			return nil, internalError("split[%d]: failed to read survey index: %v", i, err)
+		}
+		if holder != nil && string(holder) != property.PropertyID {
+			return nil, newError(ErrCodeSurveyNumberOccupied, "split[%d]: survey %s is already registered to %s", i, surveyKey, string(holder))
+		}
+		claimedSurveyKeys[surveyKey] = split.NewPropertyID
+
+		encumbranceStatus := "CLEAR"
+		if encumbersTarget(encumbrances, split.NewPropertyID, easementTargets) {
+			encumbranceStatus = "ENCUMBERED"
 		}
 
 		newProperty := LandRecord{
 			DocType:            "landRecord",
+			SchemaVersion:      landRecordSchemaVersion,
 			PropertyID:         split.NewPropertyID,
 			SurveyNumber:       split.SurveyNumber,
 			SubSurveyNumber:    split.SubSurveyNumber,
@@ -1662,215 +2617,356 @@ func (s *LandRegistryContract) SplitProperty(ctx contractapi.TransactionContextI
 			LandClassification: property.LandClassification,
 			Status:             "ACTIVE",
 			DisputeStatus:      "CLEAR",
-			EncumbranceStatus:  "CLEAR",
+			EncumbranceStatus:  encumbranceStatus,
 			CoolingPeriod:      CoolingPeriod{Active: false, ExpiresAt: ""},
 			TaxInfo:            property.TaxInfo,
 			RegistrationInfo:   property.RegistrationInfo,
 			AlgorandInfo:       AlgorandInfo{},
 			PolygonInfo:        PolygonInfo{Tokenized: false},
 			Provenance: Provenance{
-				PreviousPropertyID: propertyID,
-				SplitFrom:          propertyID,
-				MergedFrom:         nil,
-				Sequence:           1,
+				PreviousPropertyID:  property.PropertyID,
+				SplitFrom:           property.PropertyID,
+				MergedFrom:          nil,
+				Sequence:            1,
+				MinPlotExemptionRef: split.ExemptionOrderRef,
 			},
-			FabricTxID: txID,
-			CreatedAt:  now,
-			UpdatedAt:  now,
-			CreatedBy:  getCallerID(ctx),
-			UpdatedBy:  getCallerID(ctx),
+			Annotations:    childAnnotations[i],
+			DisasterStatus: inheritedDisasterStatus([]*LandRecord{property}),
+			FabricTxID:     txID,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			CreatedBy:      getCallerID(ctx),
+			UpdatedBy:      getCallerID(ctx),
 		}
 
-		newPropertyBytes, _ := json.Marshal(newProperty)
+		newPropertyBytes, _ := canonicalMarshal(newProperty)
 		if err := ctx.GetStub().PutState(newLandKey, newPropertyBytes); err != nil {
-			return fmt.Errorf("split[%d]: failed to put state: %v", i, err)
+			return nil, internalError("split[%d]: failed to put state: %v", i, err)
+		}
+		if err := countNewRecord(ctx, property.Location.StateCode, "landRecord", newProperty.Status); err != nil {
+			return nil, err
+		}
+		if err := inheritLandEndorsement(ctx, property.PropertyID, split.NewPropertyID); err != nil {
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
 		}
 
 		// Create indexes for new property
 		for _, owner := range split.OwnerInfo.Owners {
 			_ = putOwnerIndex(ctx, owner.AadhaarHash, split.NewPropertyID)
+			_ = putEntityIndex(ctx, owner, split.NewPropertyID)
+		}
+		if err := putSurveyIndex(ctx, property.Location.StateCode, property.Location.DistrictCode, surveyKey, split.NewPropertyID); err != nil {
+			return nil, internalError("split[%d]: failed to create survey index: %v", i, err)
+		}
+		if err := putLocationIndex(ctx, property.Location, split.NewPropertyID); err != nil {
+			return nil, internalError("split[%d]: failed to create location index: %v", i, err)
 		}
-		surveyKey := split.SurveyNumber
-		if split.SubSurveyNumber != "" {
-			surveyKey = split.SurveyNumber + "/" + split.SubSurveyNumber
+		if err := putAnnotationIndexes(ctx, &newProperty); err != nil {
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
+		}
+		if newProperty.DisasterStatus != nil {
+			if err := putDisasterIndex(ctx, &newProperty); err != nil {
+				return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
+			}
+		}
+		if err := putChildIndex(ctx, property.PropertyID, split.NewPropertyID); err != nil {
+			return nil, errorAt(fmt.Sprintf("split[%d]", i), err)
 		}
-		_ = putSurveyIndex(ctx, property.Location.StateCode, property.Location.DistrictCode, surveyKey, split.NewPropertyID)
-		_ = putLocationIndex(ctx, property.Location, split.NewPropertyID)
 
 		newPropertyIDs = append(newPropertyIDs, split.NewPropertyID)
 	}
 
+	// With the holders' consent each charge follows the land
+	carried, err := carryEncumbrances(ctx, encumbrances, newPropertyIDs, easementTargets, consentRefs, property.Location.StateCode, now, txID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Mark original property as SPLIT (do NOT delete — Rule 9: never overwrite)
-	property.Status = "SPLIT"
+	if err := setPropertyStatus(ctx, property, "SPLIT", "split into "+strings.Join(newPropertyIDs, ", ")); err != nil {
+		return nil, err
+	}
+	if len(encumbrances) > 0 {
+		property.EncumbranceStatus = "CLEAR"
+	}
 	property.UpdatedAt = now
 	property.UpdatedBy = getCallerID(ctx)
 	property.FabricTxID = txID
 
-	landKey, _ := createLandKey(ctx, propertyID)
-	propertyBytes, _ := json.Marshal(property)
+	landKey, _ := createLandKey(ctx, property.PropertyID)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update original property: %v", err)
+		return nil, internalError("failed to update original property: %v", err)
 	}
 
-	event := PropertySplitEvent{
-		Type:             "PROPERTY_SPLIT",
-		OriginalProperty: propertyID,
-		NewPropertyIDs:   newPropertyIDs,
-		FabricTxID:       txID,
-		Timestamp:        now,
-		StateCode:        property.Location.StateCode,
-		ChannelID:        ctx.GetStub().GetChannelID(),
-	}
-	return emitEvent(ctx, "PROPERTY_SPLIT", event)
+	return &splitResult{NewPropertyIDs: newPropertyIDs, EncumbrancesCarried: carried}, nil
 }
 
 // MergeProperties merges multiple properties into a single new property.
 // All source properties must have the same owner, be in ACTIVE status,
-// and not have disputes or encumbrances.
-func (s *LandRegistryContract) MergeProperties(ctx contractapi.TransactionContextInterface, propertyIDsJSON string, mergedPropertyJSON string) error {
+// and not have disputes. Encumbrances are handled as in SplitProperty:
+// rejected under REQUIRE_CLEAR, or cloned onto the merged property with
+// every holder's consent under CARRY_WITH_CONSENT. Sources must share a location
+// down to the state's mergeLocationLevel (village by default); the
+// merged record's location must be a source's and its area is the sum
+// of the sources' areas. States that require tehsildar sanction use
+// ProposeMerge instead.
+func (s *LandRegistryContract) MergeProperties(ctx contractapi.TransactionContextInterface, propertyIDsJSON string, mergedPropertyJSON string, encumbranceHandling string, consentRefsJSON string) (*Receipt, error) {
 	if err := requireRole(ctx, "registrar"); err != nil {
-		return err
+		return nil, err
 	}
 
 	var propertyIDs []string
 	if err := json.Unmarshal([]byte(propertyIDsJSON), &propertyIDs); err != nil {
-		return fmt.Errorf("INVALID_INPUT: failed to parse property IDs: %v", err)
+		return nil, newError(ErrCodeInvalidInput, "failed to parse property IDs: %v", err)
 	}
 
 	if len(propertyIDs) < 2 {
-		return fmt.Errorf("VALIDATION_ERROR: merge requires at least 2 properties")
+		return nil, newError(ErrCodeValidationError, "merge requires at least 2 properties")
+	}
+	if err := requireSingleStepSubdivision(ctx, extractStateCode(propertyIDs[0])); err != nil {
+		return nil, err
 	}
 
 	var mergedProperty LandRecord
 	if err := json.Unmarshal([]byte(mergedPropertyJSON), &mergedProperty); err != nil {
-		return fmt.Errorf("INVALID_INPUT: failed to parse merged property JSON: %v", err)
+		return nil, newError(ErrCodeInvalidInput, "failed to parse merged property JSON: %v", err)
 	}
 
 	if err := validatePropertyID(mergedProperty.PropertyID); err != nil {
-		return err
+		return nil, err
+	}
+
+	handling, consentRefs, err := parseEncumbranceHandling(encumbranceHandling, consentRefsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	carried, err := s.mergeParcels(ctx, propertyIDs, &mergedProperty, handling, consentRefs)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	event := PropertyMergeEvent{
+		Type:                "PROPERTY_MERGED",
+		SourcePropertyIDs:   propertyIDs,
+		MergedPropertyID:    mergedProperty.PropertyID,
+		EncumbrancesCarried: carried,
+		FabricTxID:          txID,
+		Timestamp:           now,
+		StateCode:           mergedProperty.Location.StateCode,
+		ChannelID:           ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "PROPERTY_MERGED", event); err != nil {
+		return nil, err
+	}
+	receipt := newReceipt(ctx, mergedProperty.PropertyID, mergedProperty.Status)
+	for _, sourceID := range propertyIDs {
+		receipt.relate("property", sourceID, "MERGED")
 	}
+	return receipt.relateCarried(carried), nil
+}
 
+// mergeParcels carries out a merge for MergeProperties and
+// SanctionMerge: it validates the sources, stores merged with its
+// indexes, carries consented encumbrances and marks the sources MERGED.
+// The caller checks roles and emits the event.
+func (s *LandRegistryContract) mergeParcels(ctx contractapi.TransactionContextInterface, propertyIDs []string, merged *LandRecord, handling string, consentRefs map[string]string) ([]EncumbranceCarriedEvent, error) {
 	// Validate all source properties
-	var totalArea float64
+	var sources []*LandRecord
+	var encumbrances []*EncumbranceRecord
 	var ownerHash string
 	for i, propID := range propertyIDs {
 		if err := validatePropertyID(propID); err != nil {
-			return fmt.Errorf("property[%d]: %v", i, err)
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
 		}
 
 		prop, err := s.GetProperty(ctx, propID)
 		if err != nil {
-			return fmt.Errorf("property[%d]: %v", i, err)
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
 		}
 
 		if prop.Status != "ACTIVE" {
-			return fmt.Errorf("property[%d]: status must be ACTIVE, got %s", i, prop.Status)
+			return nil, newError(ErrCodePropertyNotActive, "property[%d]: status must be ACTIVE, got %s", i, prop.Status)
 		}
 		if prop.DisputeStatus != "CLEAR" {
-			return fmt.Errorf("property[%d]: cannot merge disputed property", i)
+			return nil, newError(ErrCodeLandDisputed, "property[%d]: cannot merge disputed property", i)
+		}
+		if err := requireNoInvestigationHold(prop); err != nil {
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+		propEncumbrances, err := getActiveEncumbrances(ctx, propID)
+		if err != nil {
+			return nil, internalError("property[%d]: failed to check encumbrances: %v", i, err)
 		}
-		if prop.EncumbranceStatus != "CLEAR" {
-			return fmt.Errorf("property[%d]: cannot merge encumbered property", i)
+		if err := checkEncumbrancesCarriable(propEncumbrances, propID, handling, consentRefs); err != nil {
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
 		}
+		encumbrances = append(encumbrances, propEncumbrances...)
 
 		// All properties must have the same primary owner
 		if len(prop.CurrentOwner.Owners) > 0 {
 			if ownerHash == "" {
 				ownerHash = prop.CurrentOwner.Owners[0].AadhaarHash
 			} else if prop.CurrentOwner.Owners[0].AadhaarHash != ownerHash {
-				return fmt.Errorf("property[%d]: all merged properties must have the same owner", i)
+				return nil, newError(ErrCodeValidationError, "property[%d]: all merged properties must have the same owner", i)
 			}
 		}
 
-		totalArea += prop.Area.Value
+		sources = append(sources, prop)
 	}
 
 	// State boundary check on the first property
-	firstProp, _ := s.GetProperty(ctx, propertyIDs[0])
-	if err := requireStateAccess(ctx, firstProp.Location.StateCode); err != nil {
-		return err
+	if err := requireStateAccess(ctx, sources[0].Location.StateCode); err != nil {
+		return nil, err
+	}
+
+	// Only neighbouring parcels merge; location and area come from the sources
+	settings, err := getSettings(ctx, sources[0].Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMergeLocation(sources, merged, settings.MergeLocationLevel); err != nil {
+		return nil, err
+	}
+	if err := validatePropertyIDMatches(merged.PropertyID, merged.Location, merged.SurveyNumber, merged.SubSurveyNumber); err != nil {
+		return nil, err
+	}
+	area, err := mergedArea(sources, merged.Area, settings.BighaSqMeters)
+	if err != nil {
+		return nil, err
 	}
+	merged.Area = area
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
 	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
 	txID := ctx.GetStub().GetTxID()
 
 	// Create the merged property
-	mergedProperty.DocType = "landRecord"
-	mergedProperty.Status = "ACTIVE"
-	mergedProperty.DisputeStatus = "CLEAR"
-	mergedProperty.EncumbranceStatus = "CLEAR"
-	mergedProperty.CoolingPeriod = CoolingPeriod{Active: false, ExpiresAt: ""}
-	mergedProperty.Provenance = Provenance{
+	merged.DocType = "landRecord"
+	merged.Status = "ACTIVE"
+	merged.DisputeStatus = "CLEAR"
+	merged.EncumbranceStatus = "CLEAR"
+	if len(encumbrances) > 0 {
+		merged.EncumbranceStatus = "ENCUMBERED"
+	}
+	merged.CoolingPeriod = CoolingPeriod{Active: false, ExpiresAt: ""}
+	merged.Annotations = mergedAnnotations(sources)
+	merged.DisasterStatus = inheritedDisasterStatus(sources)
+	merged.Provenance = Provenance{
 		MergedFrom: propertyIDs,
 		Sequence:   1,
 	}
-	mergedProperty.FabricTxID = txID
-	mergedProperty.CreatedAt = now
-	mergedProperty.UpdatedAt = now
-	mergedProperty.CreatedBy = getCallerID(ctx)
-	mergedProperty.UpdatedBy = getCallerID(ctx)
+	merged.SchemaVersion = landRecordSchemaVersion
+	merged.FabricTxID = txID
+	merged.CreatedAt = now
+	merged.UpdatedAt = now
+	merged.CreatedBy = getCallerID(ctx)
+	merged.UpdatedBy = getCallerID(ctx)
 
 	// Validate Aadhaar (Rule 10)
-	for _, owner := range mergedProperty.CurrentOwner.Owners {
+	for _, owner := range merged.CurrentOwner.Owners {
 		if owner.AadhaarHash == "" {
-			return fmt.Errorf("AADHAAR_REQUIRED: all owners must have aadhaarHash")
+			return nil, newError(ErrCodeAadhaarRequired, "all owners must have aadhaarHash")
 		}
 	}
+	if err := validateOwnership(merged.CurrentOwner.Owners); err != nil {
+		return nil, err
+	}
+	if err := validateOwnerInfo(&merged.CurrentOwner); err != nil {
+		return nil, err
+	}
 
 	// Check merged property does not exist
-	mergedKey, _ := createLandKey(ctx, mergedProperty.PropertyID)
+	mergedKey, _ := createLandKey(ctx, merged.PropertyID)
 	existing, _ := ctx.GetStub().GetState(mergedKey)
 	if existing != nil {
-		return fmt.Errorf("PROPERTY_EXISTS: %s already exists", mergedProperty.PropertyID)
+		return nil, newError(ErrCodePropertyExists, "%s already exists", merged.PropertyID)
 	}
 
 	// Store merged property
-	mergedBytes, _ := json.Marshal(mergedProperty)
+	mergedBytes, _ := canonicalMarshal(merged)
 	if err := ctx.GetStub().PutState(mergedKey, mergedBytes); err != nil {
-		return fmt.Errorf("failed to put merged property: %v", err)
+		return nil, internalError("failed to put merged property: %v", err)
+	}
+	if err := countNewRecord(ctx, merged.Location.StateCode, "landRecord", merged.Status); err != nil {
+		return nil, err
+	}
+	if err := inheritLandEndorsement(ctx, propertyIDs[0], merged.PropertyID); err != nil {
+		return nil, err
 	}
 
 	// Create indexes for merged property
-	for _, owner := range mergedProperty.CurrentOwner.Owners {
-		_ = putOwnerIndex(ctx, owner.AadhaarHash, mergedProperty.PropertyID)
+	for _, owner := range merged.CurrentOwner.Owners {
+		_ = putOwnerIndex(ctx, owner.AadhaarHash, merged.PropertyID)
+		_ = putEntityIndex(ctx, owner, merged.PropertyID)
+	}
+	surveyKey := merged.SurveyNumber
+	if merged.SubSurveyNumber != "" {
+		surveyKey = merged.SurveyNumber + "/" + merged.SubSurveyNumber
+	}
+	_ = putSurveyIndex(ctx, merged.Location.StateCode, merged.Location.DistrictCode, surveyKey, merged.PropertyID)
+	_ = putLocationIndex(ctx, merged.Location, merged.PropertyID)
+	if err := putAnnotationIndexes(ctx, merged); err != nil {
+		return nil, err
+	}
+	if merged.DisasterStatus != nil {
+		if err := putDisasterIndex(ctx, merged); err != nil {
+			return nil, err
+		}
+	}
+	for _, propID := range propertyIDs {
+		if err := putChildIndex(ctx, propID, merged.PropertyID); err != nil {
+			return nil, err
+		}
 	}
-	surveyKey := mergedProperty.SurveyNumber
-	if mergedProperty.SubSurveyNumber != "" {
-		surveyKey = mergedProperty.SurveyNumber + "/" + mergedProperty.SubSurveyNumber
+
+	// With the holders' consent each source's charges follow the land
+	carried, err := carryEncumbrances(ctx, encumbrances, []string{merged.PropertyID}, nil, consentRefs, merged.Location.StateCode, now, txID)
+	if err != nil {
+		return nil, err
 	}
-	_ = putSurveyIndex(ctx, mergedProperty.Location.StateCode, mergedProperty.Location.DistrictCode, surveyKey, mergedProperty.PropertyID)
-	_ = putLocationIndex(ctx, mergedProperty.Location, mergedProperty.PropertyID)
 
 	// Mark source properties as MERGED (Rule 9: never overwrite)
 	for _, propID := range propertyIDs {
 		prop, _ := s.GetProperty(ctx, propID)
-		prop.Status = "MERGED"
+		if err := setPropertyStatus(ctx, prop, "MERGED", "merged into "+merged.PropertyID); err != nil {
+			return nil, err
+		}
+		if err := deleteAnnotationIndexes(ctx, prop); err != nil {
+			return nil, err
+		}
+		if prop.DisasterStatus != nil {
+			if err := deleteDisasterIndex(ctx, prop); err != nil {
+				return nil, err
+			}
+		}
+		prop.EncumbranceStatus = "CLEAR"
 		prop.UpdatedAt = now
 		prop.UpdatedBy = getCallerID(ctx)
 		prop.FabricTxID = txID
 
 		propKey, _ := createLandKey(ctx, propID)
-		propBytes, _ := json.Marshal(prop)
+		propBytes, _ := canonicalMarshal(prop)
 		_ = ctx.GetStub().PutState(propKey, propBytes)
 	}
 
-	event := PropertyMergeEvent{
-		Type:              "PROPERTY_MERGED",
-		SourcePropertyIDs: propertyIDs,
-		MergedPropertyID:  mergedProperty.PropertyID,
-		FabricTxID:        txID,
-		Timestamp:         now,
-		StateCode:         mergedProperty.Location.StateCode,
-		ChannelID:         ctx.GetStub().GetChannelID(),
-	}
-	return emitEvent(ctx, "PROPERTY_MERGED", event)
+	return carried, nil
 }
 
 // ChangeLandUse changes the land use classification of a property.
 // Requires registrar or admin role and a valid approval reference
-// from the relevant authority.
-func (s *LandRegistryContract) ChangeLandUse(ctx contractapi.TransactionContextInterface, propertyID, newLandUse, approvalRef string) error {
+// from the relevant authority. In states with requireConversionCharge
+// set, conversionChallan must be a stamp-duty challan paying exactly
+// the conversion charge for the change (see settleConversionCharge);
+// it is consumed against the property. Changing away from a land use
+// the state restricts (such as FOREST) also needs diversionApprovalJSON,
+// a Clearance from the authority it names (see checkDiversionApproval).
+func (s *LandRegistryContract) ChangeLandUse(ctx contractapi.TransactionContextInterface, propertyID, newLandUse, approvalRef, conversionChallan, diversionApprovalJSON string) error {
 	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
 		return err
 	}
@@ -1889,14 +2985,14 @@ func (s *LandRegistryContract) ChangeLandUse(ctx contractapi.TransactionContextI
 	}
 
 	if property.Status != "ACTIVE" {
-		return fmt.Errorf("PROPERTY_NOT_ACTIVE: cannot change land use for property with status %s", property.Status)
+		return newError(ErrCodePropertyNotActive, "cannot change land use for property with status %s", property.Status)
 	}
 
 	if newLandUse == "" {
-		return fmt.Errorf("VALIDATION_ERROR: newLandUse cannot be empty")
+		return newError(ErrCodeValidationError, "newLandUse cannot be empty")
 	}
 	if approvalRef == "" {
-		return fmt.Errorf("VALIDATION_ERROR: approvalRef is required for land use change")
+		return newError(ErrCodeValidationError, "approvalRef is required for land use change")
 	}
 
 	// Validate land use values
@@ -1906,7 +3002,23 @@ func (s *LandRegistryContract) ChangeLandUse(ctx contractapi.TransactionContextI
 		"GOVERNMENT": true, "BARREN": true, "WATER_BODY": true,
 	}
 	if !validLandUses[newLandUse] {
-		return fmt.Errorf("VALIDATION_ERROR: invalid land use '%s'", newLandUse)
+		return newError(ErrCodeValidationError, "invalid land use '%s'", newLandUse)
+	}
+
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return err
+	}
+	diversionApproval, err := checkDiversionApproval(settings, property, newLandUse, diversionApprovalJSON)
+	if err != nil {
+		return err
+	}
+	var chargePaid int64
+	if settings.RequireConversionCharge {
+		chargePaid, err = settleConversionCharge(ctx, property, newLandUse, conversionChallan, settings.BighaSqMeters)
+		if err != nil {
+			return err
+		}
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -1920,9 +3032,9 @@ func (s *LandRegistryContract) ChangeLandUse(ctx contractapi.TransactionContextI
 	property.FabricTxID = txID
 
 	landKey, _ := createLandKey(ctx, propertyID)
-	propertyBytes, _ := json.Marshal(property)
+	propertyBytes, _ := canonicalMarshal(property)
 	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
-		return fmt.Errorf("failed to update land use: %v", err)
+		return internalError("failed to update land use: %v", err)
 	}
 
 	event := LandUseChangedEvent{
@@ -1931,11 +3043,15 @@ func (s *LandRegistryContract) ChangeLandUse(ctx contractapi.TransactionContextI
 		OldLandUse:  oldLandUse,
 		NewLandUse:  newLandUse,
 		ApprovalRef: approvalRef,
+		ChargePaid:  chargePaid,
 		FabricTxID:  txID,
 		Timestamp:   now,
 		StateCode:   property.Location.StateCode,
 		ChannelID:   ctx.GetStub().GetChannelID(),
 	}
+	if diversionApproval != nil {
+		event.DiversionClearanceRef = diversionApproval.ClearanceRef
+	}
 	return emitEvent(ctx, "LAND_USE_CHANGED", event)
 }
 
@@ -1953,18 +3069,18 @@ func (s *LandRegistryContract) GetStateRoot(ctx contractapi.TransactionContextIn
 
 	var br BlockRange
 	if err := json.Unmarshal([]byte(blockRange), &br); err != nil {
-		return "", fmt.Errorf("INVALID_INPUT: failed to parse block range: %v", err)
+		return "", newError(ErrCodeInvalidInput, "failed to parse block range: %v", err)
 	}
 
 	if br.Start < 0 || br.End < br.Start {
-		return "", fmt.Errorf("VALIDATION_ERROR: invalid block range [%d, %d]", br.Start, br.End)
+		return "", newError(ErrCodeValidationError, "invalid block range [%d, %d]", br.Start, br.End)
 	}
 
 	// Query all land records (in production, this would use block event data)
 	// For now, compute hash over all current land records
 	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixLand, []string{})
 	if err != nil {
-		return "", fmt.Errorf("failed to iterate land records: %v", err)
+		return "", internalError("failed to iterate land records: %v", err)
 	}
 	defer iterator.Close()
 
@@ -1978,10 +3094,15 @@ func (s *LandRegistryContract) GetStateRoot(ctx contractapi.TransactionContextIn
 	for iterator.HasNext() {
 		kv, err := iterator.Next()
 		if err != nil {
-			return "", fmt.Errorf("failed to iterate: %v", err)
+			return "", internalError("failed to iterate: %v", err)
+		}
+		// Records written before canonical encoding hash as if rewritten
+		value, err := canonicalJSON(kv.Value)
+		if err != nil {
+			return "", internalError("failed to canonicalize %s: %v", kv.Key, err)
 		}
 		keys = append(keys, kv.Key)
-		keyValueMap[kv.Key] = kv.Value
+		keyValueMap[kv.Key] = value
 	}
 
 	// Sort keys for deterministic ordering
@@ -1997,25 +3118,37 @@ func (s *LandRegistryContract) GetStateRoot(ctx contractapi.TransactionContextIn
 }
 
 // RecordAnchor records the result of an Algorand anchoring operation
-// back in Fabric for cross-reference. Only admins can record anchors.
-func (s *LandRegistryContract) RecordAnchor(ctx contractapi.TransactionContextInterface, anchorJSON string) error {
+// back in Fabric for cross-reference. The anchor must name the PENDING
+// attempt opened by BeginAnchorAttempt as attemptId, which it marks
+// COMPLETED. A repeat call with the same requestId returns the original
+// anchor as a duplicate result. Only admins can record anchors. Writes
+// an audit entry.
+func (s *LandRegistryContract) RecordAnchor(ctx contractapi.TransactionContextInterface, anchorJSON string) (*Receipt, error) {
 	if err := requireRole(ctx, "admin"); err != nil {
-		return err
+		return nil, err
 	}
 
 	var anchor AnchorRecord
 	if err := json.Unmarshal([]byte(anchorJSON), &anchor); err != nil {
-		return fmt.Errorf("INVALID_INPUT: failed to parse anchor JSON: %v", err)
+		return nil, newError(ErrCodeInvalidInput, "failed to parse anchor JSON: %v", err)
+	}
+	if anchor.RequestID != "" {
+		if prior, err := findRequest(ctx, anchor.RequestID, "RecordAnchor", anchorJSON); err != nil || prior != nil {
+			return prior, err
+		}
 	}
 
 	if anchor.StateCode == "" {
-		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
 	}
 	if anchor.StateRoot == "" {
-		return fmt.Errorf("VALIDATION_ERROR: stateRoot is required")
+		return nil, newError(ErrCodeValidationError, "stateRoot is required")
 	}
 	if anchor.AlgorandTxID == "" {
-		return fmt.Errorf("VALIDATION_ERROR: algorandTxId is required")
+		return nil, newError(ErrCodeValidationError, "algorandTxId is required")
+	}
+	if anchor.AttemptID == "" {
+		return nil, newError(ErrCodeValidationError, "attemptId is required")
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -2032,14 +3165,22 @@ func (s *LandRegistryContract) RecordAnchor(ctx contractapi.TransactionContextIn
 
 	anchorKey, err := createAnchorKey(ctx, anchor.StateCode, anchor.AnchorID)
 	if err != nil {
-		return fmt.Errorf("failed to create anchor key: %v", err)
+		return nil, internalError("failed to create anchor key: %v", err)
 	}
-	anchorBytes, err := json.Marshal(anchor)
+	anchorBytes, err := canonicalMarshal(anchor)
 	if err != nil {
-		return fmt.Errorf("failed to marshal anchor: %v", err)
+		return nil, internalError("failed to marshal anchor: %v", err)
 	}
 	if err := ctx.GetStub().PutState(anchorKey, anchorBytes); err != nil {
-		return fmt.Errorf("failed to put anchor state: %v", err)
+		return nil, internalError("failed to put anchor state: %v", err)
+	}
+	if err := completeAnchorAttempt(ctx, &anchor); err != nil {
+		return nil, err
+	}
+	if anchor.RequestID != "" {
+		if err := putRequest(ctx, anchor.RequestID, "RecordAnchor", anchorJSON, anchorKey, anchor.AnchorID); err != nil {
+			return nil, err
+		}
 	}
 
 	event := AnchorRecordedEvent{
@@ -2052,5 +3193,13 @@ func (s *LandRegistryContract) RecordAnchor(ctx contractapi.TransactionContextIn
 		Timestamp:    now,
 		ChannelID:    ctx.GetStub().GetChannelID(),
 	}
-	return emitEvent(ctx, "ANCHOR_RECORDED", event)
+	if err := recordAudit(ctx, "RecordAnchor", anchor.AnchorID); err != nil {
+		return nil, err
+	}
+	if err := emitEvent(ctx, "ANCHOR_RECORDED", event); err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, anchor.AnchorID, "").
+		withRequest(anchor.RequestID).
+		relate("anchorAttempt", anchor.AttemptID, "COMPLETED"), nil
 }
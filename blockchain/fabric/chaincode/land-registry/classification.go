@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// RESTRICTED LAND CLASSIFICATIONS
+// ============================================================
+// Forest land and land notified under various acts cannot pass to
+// private parties, or be put to other uses, without clearance from a
+// named authority (e.g. the Forest Conservation Act's central
+// clearance). States list such classifications with the authority in
+// RegistrySettings.RestrictedClassifications; transfers of those
+// parcels, and land-use changes away from them, must carry that
+// authority's clearance.
+
+// requireClassificationClearance fails unless a transfer of a parcel
+// whose land classification the state restricts carries a clearance
+// from the authority the state names for it. Unrestricted parcels are
+// not checked.
+func requireClassificationClearance(ctx contractapi.TransactionContextInterface, property *LandRecord, transfer *TransferRecord) error {
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return err
+	}
+	authority, restricted := settings.RestrictedClassifications[property.LandClassification]
+	if !restricted {
+		return nil
+	}
+	if transfer.ClassificationClearance == nil {
+		return newError(ErrCodeTransferClassificationRestricted, "%s land cannot be transferred without clearance from %s",
+			property.LandClassification, authority)
+	}
+	if err := checkClearance(transfer.ClassificationClearance, authority, ErrCodeTransferClassificationRestricted); err != nil {
+		return errorAt("classificationClearance", err)
+	}
+	return nil
+}
+
+// checkDiversionApproval fails unless a change of a parcel away from a
+// restricted land use (such as FOREST) carries diversion approval from
+// the authority the state names for it, given as a Clearance JSON. It
+// returns the approval, or nil where none is needed.
+func checkDiversionApproval(settings *RegistrySettings, property *LandRecord, newLandUse, approvalJSON string) (*Clearance, error) {
+	authority, restricted := settings.RestrictedClassifications[property.LandUse]
+	if !restricted || newLandUse == property.LandUse {
+		return nil, nil
+	}
+	if approvalJSON == "" {
+		return nil, newError(ErrCodeDiversionApprovalRequired, "changing %s from %s to %s needs diversion approval from %s",
+			property.PropertyID, property.LandUse, newLandUse, authority)
+	}
+	var approval Clearance
+	if err := json.Unmarshal([]byte(approvalJSON), &approval); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse diversion approval JSON: %v", err)
+	}
+	if err := checkClearance(&approval, authority, ErrCodeDiversionApprovalRequired); err != nil {
+		return nil, errorAt("diversionApproval", err)
+	}
+	return &approval, nil
+}
+
+// checkClearance checks that a clearance names authority, failing with
+// code if not, and has a reference and document hash.
+func checkClearance(clearance *Clearance, authority, code string) error {
+	if !strings.EqualFold(strings.TrimSpace(clearance.Authority), authority) {
+		return newError(code, "clearance is from '%s', %s is required", clearance.Authority, authority)
+	}
+	if strings.TrimSpace(clearance.ClearanceRef) == "" {
+		return newError(ErrCodeValidationError, "clearanceRef is required")
+	}
+	return validateDocumentHash(clearance.DocumentHash, "documentHash")
+}
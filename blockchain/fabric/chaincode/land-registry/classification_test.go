@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// restrictedSettings restricts FOREST and a local NOTIFIED class in
+// Telangana.
+var restrictedSettings = &RegistrySettings{DocType: "registrySettings", StateCode: "TS",
+	ExtraLandClassifications: []string{"NOTIFIED"},
+	RestrictedClassifications: map[string]string{
+		"FOREST":   "MoEFCC",
+		"NOTIFIED": "District Collector",
+	}}
+
+// forestClearance is a valid central clearance for forest land.
+var forestClearance = &Clearance{Authority: "MoEFCC", ClearanceRef: "FC/8-12/2027", DocumentHash: fmt.Sprintf("%064x", 0xfc)}
+
+// initiateClassifiedTransfer registers a parcel of classification in a
+// state with restrictedSettings and initiates its sale with clearance.
+func initiateClassifiedTransfer(t *testing.T, classification string, clearance *Clearance) error {
+	t.Helper()
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(restrictedSettings)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	property := testProperty("142", 1)
+	property.LandClassification = classification
+	ledger.registerTestProperty(registrar, property)
+
+	transfer := testTransfer(property.PropertyID, newTestSigner(t, 1), newTestSigner(t, 2), newTestSigner(t, 3), newTestSigner(t, 4))
+	transfer.ClassificationClearance = clearance
+	transferJSON, _ := json.Marshal(transfer)
+	return ledger.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := ledger.contract.InitiateTransfer(ctx, string(transferJSON))
+		return err
+	})
+}
+
+func TestInitiateTransferClassificationRestrictions(t *testing.T) {
+	otherAuthority := *forestClearance
+	otherAuthority.Authority = "State Forest Department"
+	noRef := *forestClearance
+	noRef.ClearanceRef = " "
+	badHash := *forestClearance
+	badHash.DocumentHash = "not-a-hash"
+	lowerCase := *forestClearance
+	lowerCase.Authority = " moefcc "
+
+	tests := []struct {
+		name           string
+		classification string
+		clearance      *Clearance
+		want           string
+	}{
+		{"unrestricted", "IRRIGATED", nil, ""},
+		{"unrestricted with clearance", "IRRIGATED", forestClearance, ""},
+		{"forest without clearance", "FOREST", nil, ErrCodeTransferClassificationRestricted},
+		{"forest cleared", "FOREST", forestClearance, ""},
+		{"authority matched loosely", "FOREST", &lowerCase, ""},
+		{"other authority", "FOREST", &otherAuthority, ErrCodeTransferClassificationRestricted},
+		{"forest clearance for notified land", "NOTIFIED", forestClearance, ErrCodeTransferClassificationRestricted},
+		{"clearance without reference", "FOREST", &noRef, ErrCodeValidationError},
+		{"clearance with bad document hash", "FOREST", &badHash, ErrCodeValidationError},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expectCode(t, initiateClassifiedTransfer(t, tc.classification, tc.clearance), tc.want)
+		})
+	}
+}
+
+func TestClassificationRestrictionNamesAuthority(t *testing.T) {
+	err := initiateClassifiedTransfer(t, "NOTIFIED", nil)
+	expectCode(t, err, ErrCodeTransferClassificationRestricted)
+	if !strings.Contains(err.Error(), "NOTIFIED") || !strings.Contains(err.Error(), "District Collector") {
+		t.Fatalf("error %q should name the classification and the authority", err)
+	}
+}
+
+func TestExecuteTransferRechecksClassification(t *testing.T) {
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	signers := []*testSigner{newTestSigner(t, 1), newTestSigner(t, 2), newTestSigner(t, 3), newTestSigner(t, 4)}
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", ExtraLandClassifications: []string{"NOTIFIED"}})
+	property := testProperty("142", 1)
+	property.LandClassification = "NOTIFIED"
+	ledger.registerTestProperty(registrar, property)
+	ledger.registerTestSigners(registrar, signers...)
+	transferID := ledger.initiateTestTransfer(registrar, testTransfer(property.PropertyID, signers[0], signers[1], signers[2], signers[3]))
+	ledger.signTestTransfer(registrar, transferID, signers...)
+
+	// The state restricts notified land after the sale was initiated
+	ledger.putTestSettings(restrictedSettings)
+	expectCode(t, ledger.executeTestTransfer(registrar, transferID), ErrCodeTransferClassificationRestricted)
+	if got := ledger.readTransfer(transferID).Status; got != "SIGNATURES_COMPLETE" {
+		t.Fatalf("transfer status = %s after a refused execution", got)
+	}
+}
+
+func TestChangeLandUseAwayFromForestNeedsDiversionApproval(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(restrictedSettings)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	forest, farm := testProperty("142", 1), testProperty("143", 2)
+	forest.LandUse, farm.LandUse = "FOREST", "AGRICULTURAL"
+	ledger.registerTestProperty(registrar, forest)
+	ledger.registerTestProperty(registrar, farm)
+
+	change := func(propertyID, landUse string, approval *Clearance) error {
+		approvalJSON := ""
+		if approval != nil {
+			data, _ := json.Marshal(approval)
+			approvalJSON = string(data)
+		}
+		return ledger.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.ChangeLandUse(ctx, propertyID, landUse, "CLU/2027/17", "", approvalJSON)
+		})
+	}
+	stateForest := *forestClearance
+	stateForest.Authority = "State Forest Department"
+
+	expectCode(t, change(forest.PropertyID, "AGRICULTURAL", nil), ErrCodeDiversionApprovalRequired)
+	expectCode(t, change(forest.PropertyID, "AGRICULTURAL", &stateForest), ErrCodeDiversionApprovalRequired)
+	if got := ledger.readProperty(forest.PropertyID).LandUse; got != "FOREST" {
+		t.Fatalf("landUse = %s after refused changes", got)
+	}
+
+	if err := change(forest.PropertyID, "AGRICULTURAL", forestClearance); err != nil {
+		t.Fatalf("ChangeLandUse with diversion approval: %v", err)
+	}
+	if got := stringField(t, ledger.eventFields("LAND_USE_CHANGED"), "diversionClearanceRef"); got != forestClearance.ClearanceRef {
+		t.Fatalf("diversionClearanceRef = %q, want %q", got, forestClearance.ClearanceRef)
+	}
+	if got := ledger.readProperty(forest.PropertyID).LandUse; got != "AGRICULTURAL" {
+		t.Fatalf("landUse = %s, want AGRICULTURAL", got)
+	}
+
+	// Land can become forest without approval
+	if err := change(farm.PropertyID, "FOREST", nil); err != nil {
+		t.Fatalf("ChangeLandUse to FOREST: %v", err)
+	}
+	if _, ok := ledger.eventFields("LAND_USE_CHANGED")["diversionClearanceRef"]; ok {
+		t.Fatal("a change to FOREST should not record a diversion clearance")
+	}
+}
+
+func TestSetRegistrySettingsRestrictedClassifications(t *testing.T) {
+	ledger := newTestLedger(t)
+	admin := newTestIdentity(t, "AdminOrgMSP", "admin", "TS")
+	set := func(settingsJSON string) error {
+		return ledger.submit(admin, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.SetRegistrySettings(ctx, "TS", settingsJSON)
+		})
+	}
+	expectCode(t, set(`{"restrictedClassifications":{"FOREST":" "}}`), ErrCodeValidationError)
+	expectCode(t, set(`{"restrictedClassifications":{"":"MoEFCC"}}`), ErrCodeValidationError)
+	if err := set(`{"restrictedClassifications":{"FOREST":"MoEFCC"}}`); err != nil {
+		t.Fatalf("SetRegistrySettings: %v", err)
+	}
+}
@@ -0,0 +1,587 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LAND ACQUISITION AND COMPENSATION
+// ============================================================
+// AcquireLand vests a property in the acquiring government department
+// under a compensation award and opens a CompensationRecord for each
+// dispossessed owner. Compensation is paid in tranches by the treasury
+// (RecordCompensationPayment) and is often litigated; a dispute over it
+// is flagged against the compensation record, not the property, which
+// is government land by then.
+
+// AcquireLand records a government acquisition of a property.
+// acquisitionJSON is a LandAcquisition with awardRef, notificationRef,
+// awardDocumentHash, the acquiring department as a GOVERNMENT owner,
+// and one award per current owner (ownerHash, entitlement in paisa,
+// interestRate in basis points a year, interestFrom). The property must
+// be free of disputes and encumbrances. Writes an AUTO_APPROVED
+// ACQUISITION mutation. Only admins in the property's state can record
+// acquisitions. Emits COMPENSATION_AWARDED.
+func (s *LandRegistryContract) AcquireLand(ctx contractapi.TransactionContextInterface, propertyID, acquisitionJSON string) (*Receipt, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+
+	var acquisition LandAcquisition
+	if err := json.Unmarshal([]byte(acquisitionJSON), &acquisition); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse acquisition JSON: %v", err)
+	}
+	if strings.TrimSpace(acquisition.AwardRef) == "" {
+		return nil, newError(ErrCodeValidationError, "awardRef is required")
+	}
+	if strings.TrimSpace(acquisition.NotificationRef) == "" {
+		return nil, newError(ErrCodeValidationError, "notificationRef is required")
+	}
+	if err := validateDocumentHash(acquisition.AwardDocumentHash, "awardDocumentHash"); err != nil {
+		return nil, err
+	}
+	department := acquisition.AcquiringDepartment
+	department.SharePercentage = 100
+	if err := validateEntityDetails("GOVERNMENT", department.Entity, department.IsMinor, "acquiringDepartment"); err != nil {
+		return nil, err
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return nil, err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return nil, err
+	}
+	if property.DisputeStatus != "CLEAR" {
+		return nil, newError(ErrCodeLandDisputed, "property %s has an active dispute", propertyID)
+	}
+	if property.Status == "FROZEN" {
+		return nil, newError(ErrCodeLandFrozen, "property %s is frozen by court order", propertyID)
+	}
+	if property.Status == "TRANSFER_IN_PROGRESS" {
+		return nil, newError(ErrCodeTransferInProgress, "property %s has an active transfer", propertyID)
+	}
+	hasEncumbrance, err := hasActiveEncumbrances(ctx, propertyID)
+	if err != nil {
+		return nil, internalError("failed to check encumbrances: %v", err)
+	}
+	if hasEncumbrance {
+		return nil, newError(ErrCodeLandEncumbered, "property %s has active encumbrances; settle them from the award first", propertyID)
+	}
+
+	previousOwner := property.CurrentOwner
+	awards := make(map[string]CompensationAward, len(acquisition.Awards))
+	for i, award := range acquisition.Awards {
+		field := fmt.Sprintf("awards[%d]", i)
+		if ownerIndex(property, award.OwnerHash) < 0 {
+			return nil, newError(ErrCodeValidationError, "%s.ownerHash is not a current owner of %s", field, propertyID)
+		}
+		if _, dup := awards[award.OwnerHash]; dup {
+			return nil, newError(ErrCodeValidationError, "%s duplicates the award for %s", field, award.OwnerHash)
+		}
+		if award.Entitlement <= 0 {
+			return nil, newError(ErrCodeValidationError, "%s.entitlement must be positive", field)
+		}
+		if award.InterestRate < 0 {
+			return nil, newError(ErrCodeValidationError, "%s.interestRate cannot be negative", field)
+		}
+		if award.InterestFrom != "" {
+			if _, err := time.Parse("2006-01-02", award.InterestFrom); err != nil {
+				return nil, newError(ErrCodeValidationError, "%s.interestFrom must be YYYY-MM-DD", field)
+			}
+		}
+		awards[award.OwnerHash] = award
+	}
+	for _, owner := range previousOwner.Owners {
+		if _, ok := awards[owner.AadhaarHash]; !ok {
+			return nil, newError(ErrCodeValidationError, "no award for owner %s", owner.AadhaarHash)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	property.CurrentOwner = OwnerInfo{
+		OwnerType:               "GOVERNMENT",
+		Owners:                  []Owner{department},
+		OwnershipType:           "SOLE",
+		AcquisitionType:         "ACQUISITION",
+		AcquisitionDate:         now[:10],
+		AcquisitionDocumentHash: acquisition.AwardDocumentHash,
+	}
+	if err := validateOwnership(property.CurrentOwner.Owners); err != nil {
+		return nil, errorAt("acquiringDepartment", err)
+	}
+	if err := validateOwnerInfo(&property.CurrentOwner); err != nil {
+		return nil, err
+	}
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.Provenance.Sequence++
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return nil, err
+	}
+
+	for _, owner := range previousOwner.Owners {
+		if err := deleteOwnerIndex(ctx, owner.AadhaarHash, propertyID); err != nil {
+			return nil, internalError("failed to delete owner index: %v", err)
+		}
+		_ = deleteEntityIndex(ctx, owner, propertyID)
+	}
+	if err := putOwnerIndex(ctx, department.AadhaarHash, propertyID); err != nil {
+		return nil, internalError("failed to create owner index: %v", err)
+	}
+	_ = putEntityIndex(ctx, department, propertyID)
+
+	mutation := &MutationRecord{
+		DocType:    "mutationRecord",
+		MutationID: "mut_" + txID[:8],
+		PropertyID: propertyID,
+		Type:       "ACQUISITION",
+		PreviousOwner: OwnerRef{
+			AadhaarHash: previousOwner.Owners[0].AadhaarHash,
+			Name:        previousOwner.Owners[0].Name,
+		},
+		NewOwner: OwnerRef{
+			AadhaarHash: department.AadhaarHash,
+			Name:        department.Name,
+			OwnerType:   "GOVERNMENT",
+			Entity:      department.Entity,
+		},
+		Status:               "AUTO_APPROVED",
+		ApprovedBy:           getCallerID(ctx),
+		ApprovedAt:           now,
+		RevenueRecordUpdated: true,
+		DeedHash:             acquisition.AwardDocumentHash,
+		AwardRef:             acquisition.AwardRef,
+		CreatedAt:            now,
+	}
+	mutationKey, err := createMutationKey(ctx, mutation.MutationID)
+	if err != nil {
+		return nil, internalError("failed to create mutation key: %v", err)
+	}
+	mutationBytes, err := canonicalMarshal(mutation)
+	if err != nil {
+		return nil, internalError("failed to marshal mutation: %v", err)
+	}
+	if err := ctx.GetStub().PutState(mutationKey, mutationBytes); err != nil {
+		return nil, internalError("failed to create mutation record: %v", err)
+	}
+	if err := emitMutationCreated(ctx, mutation, property.Location.StateCode); err != nil {
+		return nil, err
+	}
+
+	receipt := newReceipt(ctx, acquisition.AwardRef, "AWARDED").
+		relate("mutation", mutation.MutationID, mutation.Status).
+		relate("property", propertyID, property.Status)
+	var awarded []CompensationEntry
+	for i, owner := range previousOwner.Owners {
+		award := awards[owner.AadhaarHash]
+		record := &CompensationRecord{
+			DocType:         "compensationRecord",
+			RecordID:        fmt.Sprintf("cmp_%s_%d", txID[:8], i+1),
+			PropertyID:      propertyID,
+			StateCode:       property.Location.StateCode,
+			DistrictCode:    property.Location.DistrictCode,
+			AwardRef:        acquisition.AwardRef,
+			NotificationRef: acquisition.NotificationRef,
+			MutationID:      mutation.MutationID,
+			OwnerHash:       owner.AadhaarHash,
+			OwnerName:       owner.Name,
+			Entitlement:     award.Entitlement,
+			InterestRate:    award.InterestRate,
+			InterestFrom:    award.InterestFrom,
+			Status:          "UNPAID",
+			DisputeStatus:   "CLEAR",
+			AwardedBy:       getCallerID(ctx),
+			AwardedAt:       now,
+			FabricTxID:      txID,
+		}
+		if err := putCompensationRecord(ctx, record); err != nil {
+			return nil, err
+		}
+		receipt.relate("compensation", record.RecordID, record.Status)
+		awarded = append(awarded, CompensationEntry{
+			RecordID:    record.RecordID,
+			OwnerHash:   record.OwnerHash,
+			Entitlement: record.Entitlement,
+		})
+	}
+
+	event := CompensationAwardedEvent{
+		Type:            "COMPENSATION_AWARDED",
+		PropertyID:      propertyID,
+		AwardRef:        acquisition.AwardRef,
+		NotificationRef: acquisition.NotificationRef,
+		MutationID:      mutation.MutationID,
+		Awards:          awarded,
+		FabricTxID:      txID,
+		Timestamp:       now,
+		StateCode:       property.Location.StateCode,
+		ChannelID:       ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "COMPENSATION_AWARDED", event); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// RecordCompensationPayment records a tranche paid against a
+// compensation record. paymentJSON is a CompensationPayment with amount
+// (principal, paisa), optionally interestAmount (paisa), paymentRef and
+// paidOn (YYYY-MM-DD). Principal paid cannot exceed the entitlement.
+// While the record is disputed a payment must cite the courtOrderRef
+// allowing it. Only treasury and admins can record payments. Emits
+// COMPENSATION_PAID.
+func (s *LandRegistryContract) RecordCompensationPayment(ctx contractapi.TransactionContextInterface, recordID, paymentJSON string) error {
+	if _, err := requireAnyRole(ctx, "treasury", "admin"); err != nil {
+		return err
+	}
+
+	var payment CompensationPayment
+	if err := json.Unmarshal([]byte(paymentJSON), &payment); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse payment JSON: %v", err)
+	}
+	if payment.Amount < 0 || payment.InterestAmount < 0 || payment.Amount+payment.InterestAmount == 0 {
+		return newError(ErrCodeValidationError, "amount and interestAmount must not be negative, and not both zero")
+	}
+	if strings.TrimSpace(payment.PaymentRef) == "" {
+		return newError(ErrCodeValidationError, "paymentRef is required")
+	}
+	if _, err := time.Parse("2006-01-02", payment.PaidOn); err != nil {
+		return newError(ErrCodeValidationError, "paidOn must be YYYY-MM-DD")
+	}
+
+	record, err := getCompensationRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	if err := requireStateAccess(ctx, record.StateCode); err != nil {
+		return err
+	}
+	if record.DisputeStatus != "CLEAR" && payment.CourtOrderRef == "" {
+		return newError(ErrCodeCompensationDisputed, "compensation %s is disputed; a payment needs a courtOrderRef", recordID)
+	}
+	if record.PaidAmount+payment.Amount > record.Entitlement {
+		return newError(ErrCodeValidationError, "payment of %d exceeds the %d remaining of the entitlement", payment.Amount, record.Entitlement-record.PaidAmount)
+	}
+	for _, prior := range record.Payments {
+		if prior.PaymentRef == payment.PaymentRef {
+			return newError(ErrCodeValidationError, "paymentRef %s is already recorded on %s", payment.PaymentRef, recordID)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	payment.PaymentID = "cpy_" + txID[:8]
+	payment.RecordedBy = getCallerID(ctx)
+	payment.RecordedAt = now
+	record.Payments = append(record.Payments, payment)
+	record.PaidAmount += payment.Amount
+	record.InterestPaid += payment.InterestAmount
+	if record.PaidAmount == record.Entitlement {
+		record.Status = "PAID"
+	} else if record.PaidAmount > 0 {
+		record.Status = "PARTIALLY_PAID"
+	}
+	record.FabricTxID = txID
+	if err := putCompensationRecord(ctx, record); err != nil {
+		return err
+	}
+
+	event := CompensationPaidEvent{
+		Type:           "COMPENSATION_PAID",
+		RecordID:       record.RecordID,
+		PropertyID:     record.PropertyID,
+		OwnerHash:      record.OwnerHash,
+		PaymentID:      payment.PaymentID,
+		Amount:         payment.Amount,
+		InterestAmount: payment.InterestAmount,
+		PaymentRef:     payment.PaymentRef,
+		PaidAmount:     record.PaidAmount,
+		Entitlement:    record.Entitlement,
+		Status:         record.Status,
+		FabricTxID:     txID,
+		Timestamp:      now,
+		StateCode:      record.StateCode,
+		ChannelID:      ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "COMPENSATION_PAID", event)
+}
+
+// QueryUnpaidCompensation returns the compensation records in a
+// district not yet paid in full. Only treasury, courts and admins can
+// query.
+func (s *LandRegistryContract) QueryUnpaidCompensation(ctx contractapi.TransactionContextInterface, stateCode, districtCode string) ([]*CompensationRecord, error) {
+	if _, err := requireAnyRole(ctx, "treasury", "court", "admin"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" || districtCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode and districtCode are required")
+	}
+
+	queryString := fmt.Sprintf(`{"selector":{"docType":"compensationRecord","stateCode":"%s","districtCode":"%s","status":{"$ne":"PAID"}}}`, stateCode, districtCode)
+	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, internalError("failed to query compensation: %v", err)
+	}
+	defer iterator.Close()
+
+	records := []*CompensationRecord{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate: %v", err)
+		}
+		var record CompensationRecord
+		if err := json.Unmarshal(kv.Value, &record); err != nil {
+			return nil, internalError("failed to unmarshal compensation: %v", err)
+		}
+		records = append(records, &record)
+	}
+	return records, nil
+}
+
+// GetCompensationRecord returns a compensation record. Citizens can
+// read their own.
+func (s *LandRegistryContract) GetCompensationRecord(ctx contractapi.TransactionContextInterface, recordID string) (*CompensationRecord, error) {
+	record, err := getCompensationRecord(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSelfOrRole(ctx, []string{record.OwnerHash}, "treasury", "court", "admin"); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// FlagCompensationDispute flags a dispute over a compensation record,
+// the compensation-side variant of FlagDispute. disputeJSON is a
+// DisputeRecord (type, filedBy, courtDetails, description); the
+// property's dispute status is left alone. Only courts and admins can
+// flag compensation disputes. Emits DISPUTE_FLAGGED naming the record.
+func (s *LandRegistryContract) FlagCompensationDispute(ctx contractapi.TransactionContextInterface, recordID, disputeJSON string) (*Receipt, error) {
+	if _, err := requireAnyRole(ctx, "court", "admin"); err != nil {
+		return nil, err
+	}
+
+	var dispute DisputeRecord
+	if err := json.Unmarshal([]byte(disputeJSON), &dispute); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse dispute JSON: %v", err)
+	}
+	if dispute.FiledBy.AadhaarHash != "" {
+		if err := validateAadhaarHash(dispute.FiledBy.AadhaarHash, "filedBy.aadhaarHash"); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := getCompensationRecord(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	dispute.DocType = "compensationDispute"
+	dispute.DisputeID = "dsp_" + txID[:8]
+	dispute.PropertyID = record.PropertyID
+	dispute.CompensationRecordID = recordID
+	dispute.Status = "FILED"
+	dispute.CreatedAt = now
+	if err := putCompensationDispute(ctx, &dispute); err != nil {
+		return nil, err
+	}
+
+	record.DisputeStatus = "DISPUTED"
+	record.FabricTxID = txID
+	if err := putCompensationRecord(ctx, record); err != nil {
+		return nil, err
+	}
+
+	event := DisputeEvent{
+		Type:                 "DISPUTE_FLAGGED",
+		DisputeID:            dispute.DisputeID,
+		PropertyID:           record.PropertyID,
+		DisputeType:          dispute.Type,
+		CompensationRecordID: recordID,
+		FabricTxID:           txID,
+		Timestamp:            now,
+		StateCode:            record.StateCode,
+		ChannelID:            ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "DISPUTE_FLAGGED", event); err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, dispute.DisputeID, dispute.Status).relate("compensation", recordID, record.DisputeStatus), nil
+}
+
+// ResolveCompensationDispute resolves a dispute over a compensation
+// record with resolution (RESOLVED_IN_FAVOR, RESOLVED_AGAINST or
+// SETTLED). The record is clear again once no dispute over it remains
+// open. Only courts and admins can resolve compensation disputes. Emits
+// DISPUTE_RESOLVED naming the record.
+func (s *LandRegistryContract) ResolveCompensationDispute(ctx contractapi.TransactionContextInterface, recordID, disputeID, resolution string) error {
+	if _, err := requireAnyRole(ctx, "court", "admin"); err != nil {
+		return err
+	}
+	if !disputeResolutions[resolution] {
+		return newError(ErrCodeValidationError, "resolution '%s' must be RESOLVED_IN_FAVOR, RESOLVED_AGAINST or SETTLED", resolution)
+	}
+
+	record, err := getCompensationRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	disputes, err := getCompensationDisputes(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	var dispute *DisputeRecord
+	open := 0
+	for _, d := range disputes {
+		if disputeResolutions[d.Status] {
+			continue
+		}
+		if d.DisputeID == disputeID {
+			dispute = d
+		} else {
+			open++
+		}
+	}
+	if dispute == nil {
+		return newError(ErrCodeDisputeNotFound, "no open dispute %s on compensation %s", disputeID, recordID)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	dispute.Status = resolution
+	dispute.Resolution = resolution
+	dispute.ResolvedAt = now
+	if err := putCompensationDispute(ctx, dispute); err != nil {
+		return err
+	}
+	if open == 0 {
+		record.DisputeStatus = "CLEAR"
+		record.FabricTxID = txID
+		if err := putCompensationRecord(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	event := DisputeEvent{
+		Type:                 "DISPUTE_RESOLVED",
+		DisputeID:            disputeID,
+		PropertyID:           record.PropertyID,
+		DisputeType:          dispute.Type,
+		CompensationRecordID: recordID,
+		FabricTxID:           txID,
+		Timestamp:            now,
+		StateCode:            record.StateCode,
+		ChannelID:            ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "DISPUTE_RESOLVED", event)
+}
+
+// disputeResolutions lists the final statuses of a dispute.
+var disputeResolutions = map[string]bool{
+	"RESOLVED_IN_FAVOR": true,
+	"RESOLVED_AGAINST":  true,
+	"SETTLED":           true,
+}
+
+// getCompensationRecord reads a compensation record by ID.
+func getCompensationRecord(ctx contractapi.TransactionContextInterface, recordID string) (*CompensationRecord, error) {
+	if recordID == "" {
+		return nil, newError(ErrCodeValidationError, "recordId is required")
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCompensation, []string{recordID})
+	if err != nil {
+		return nil, internalError("failed to create compensation key: %v", err)
+	}
+	recordBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read compensation: %v", err)
+	}
+	if recordBytes == nil {
+		return nil, newError(ErrCodeCompensationNotFound, "%s does not exist", recordID).with("recordId", recordID)
+	}
+	var record CompensationRecord
+	if err := json.Unmarshal(recordBytes, &record); err != nil {
+		return nil, internalError("failed to unmarshal compensation: %v", err)
+	}
+	return &record, nil
+}
+
+// putCompensationRecord writes a compensation record under its key.
+func putCompensationRecord(ctx contractapi.TransactionContextInterface, record *CompensationRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCompensation, []string{record.RecordID})
+	if err != nil {
+		return internalError("failed to create compensation key: %v", err)
+	}
+	recordBytes, err := canonicalMarshal(record)
+	if err != nil {
+		return internalError("failed to marshal compensation: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, recordBytes); err != nil {
+		return internalError("failed to write compensation %s: %v", record.RecordID, err)
+	}
+	return nil
+}
+
+// getCompensationDisputes reads the disputes under
+// COMPENSATION_DISPUTE~{recordId}.
+func getCompensationDisputes(ctx contractapi.TransactionContextInterface, recordID string) ([]*DisputeRecord, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixCompensationDispute, []string{recordID})
+	if err != nil {
+		return nil, internalError("failed to query compensation disputes: %v", err)
+	}
+	defer iterator.Close()
+
+	var disputes []*DisputeRecord
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate compensation disputes: %v", err)
+		}
+		var dispute DisputeRecord
+		if err := json.Unmarshal(kv.Value, &dispute); err != nil {
+			return nil, internalError("failed to unmarshal dispute: %v", err)
+		}
+		disputes = append(disputes, &dispute)
+	}
+	return disputes, nil
+}
+
+// putCompensationDispute writes a compensation dispute under its
+// composite key.
+func putCompensationDispute(ctx contractapi.TransactionContextInterface, dispute *DisputeRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCompensationDispute, []string{dispute.CompensationRecordID, dispute.DisputeID})
+	if err != nil {
+		return internalError("failed to create compensation dispute key: %v", err)
+	}
+	disputeBytes, err := canonicalMarshal(dispute)
+	if err != nil {
+		return internalError("failed to marshal dispute: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, disputeBytes); err != nil {
+		return internalError("failed to write dispute %s: %v", dispute.DisputeID, err)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// COOLING PERIOD (Rule 8)
+// ============================================================
+// A registered transfer stays REGISTERED_PENDING_FINALITY for 72 hours,
+// or the state's CoolingPeriodHours.
+// COOLING_PERIOD_STARTED announces each window with its expiry, and
+// COOLING_PERIOD_ENDED its outcome: FINALIZED, BLOCKED (the sweep left a
+// disputed or frozen record for a registrar to resolve) or
+// EXPIRED_UNFINALIZED (the window lapsed with no transfer to finalize)
+// or ROLLED_BACK (an objection was upheld; see RollbackTransfer).
+// A finality job that was down can find the windows it missed with
+// QueryCoolingPeriodsExpiringBefore.
+
+// defaultCoolingPeriod is the cooling period of a state that sets no
+// CoolingPeriodHours.
+const defaultCoolingPeriod = 72 * time.Hour
+
+// coolingPeriod returns a state's cooling period.
+func coolingPeriod(settings *RegistrySettings) time.Duration {
+	if settings.CoolingPeriodHours > 0 {
+		return time.Duration(settings.CoolingPeriodHours) * time.Hour
+	}
+	return defaultCoolingPeriod
+}
+
+// QueryCoolingPeriodsExpiringBefore returns the active cooling periods
+// expiring before timestamp (RFC 3339), in expiry order. Only
+// registrars and admins can query.
+func (s *LandRegistryContract) QueryCoolingPeriodsExpiringBefore(ctx contractapi.TransactionContextInterface, timestamp string) ([]*CoolingPeriodInfo, error) {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return nil, err
+	}
+	before, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return nil, newError(ErrCodeValidationError, "timestamp must be RFC 3339")
+	}
+
+	properties, err := activeCoolingPeriods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := []*CoolingPeriodInfo{}
+	for _, property := range properties {
+		expiresAt, err := time.Parse(time.RFC3339, property.CoolingPeriod.ExpiresAt)
+		if err != nil || !expiresAt.Before(before) {
+			continue
+		}
+		results = append(results, &CoolingPeriodInfo{
+			PropertyID: property.PropertyID,
+			TransferID: property.CoolingPeriod.TransferID,
+			ExpiresAt:  property.CoolingPeriod.ExpiresAt,
+			StateCode:  property.Location.StateCode,
+		})
+	}
+	return results, nil
+}
+
+// FinalizeExpiredCoolingPeriods is the batch finalizer: it ends up to
+// maxCount cooling periods that have expired at the transaction time.
+// Transfers are finalized as by FinalizeAfterCooling, except that records
+// now disputed or frozen are left BLOCKED: their window is closed but the
+// transfer stays pending for a registrar to finalize. Windows with no transfer pending finality are closed as
+// EXPIRED_UNFINALIZED. Call again while Done is false. Only registrars
+// and admins can run it. Emits COOLING_PERIOD_ENDED, listing every
+// outcome.
+func (s *LandRegistryContract) FinalizeExpiredCoolingPeriods(ctx contractapi.TransactionContextInterface, maxCount int) (*CoolingSweepResult, error) {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return nil, err
+	}
+	if maxCount <= 0 || maxCount > maxBulkRecords {
+		return nil, newError(ErrCodeValidationError, "maxCount must be between 1 and %d", maxBulkRecords)
+	}
+
+	properties, err := activeCoolingPeriods(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	nowTime := time.Unix(timestamp.Seconds, 0)
+
+	result := &CoolingSweepResult{Outcomes: []CoolingPeriodOutcome{}, Done: true}
+	for _, property := range properties {
+		expiresAt, err := time.Parse(time.RFC3339, property.CoolingPeriod.ExpiresAt)
+		if err != nil || nowTime.Before(expiresAt) {
+			continue
+		}
+		if len(result.Outcomes) >= maxCount {
+			result.Done = false
+			break
+		}
+
+		transferID := property.CoolingPeriod.TransferID
+		outcome, err := endCoolingPeriod(ctx, property)
+		if err != nil {
+			return nil, err
+		}
+		result.Outcomes = append(result.Outcomes, CoolingPeriodOutcome{
+			PropertyID: property.PropertyID,
+			TransferID: transferID,
+			Outcome:    outcome,
+		})
+		if err := emitCoolingPeriodEnded(ctx, property, transferID, outcome); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// endCoolingPeriod settles one expired cooling period and returns its
+// outcome.
+func endCoolingPeriod(ctx contractapi.TransactionContextInterface, property *LandRecord) (string, error) {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	// A blocked window is closed so later sweeps pass over it; its
+	// transfer stays REGISTERED_PENDING_FINALITY for a registrar.
+	if property.DisputeStatus != "CLEAR" || property.Status == "FROZEN" {
+		property.CoolingPeriod.Active = false
+		if err := countCoolingPeriods(ctx, property.Location.StateCode, -1); err != nil {
+			return "", err
+		}
+		property.UpdatedAt = now
+		property.UpdatedBy = "system"
+		if err := putLandRecord(ctx, property); err != nil {
+			return "", err
+		}
+		return "BLOCKED", nil
+	}
+
+	var transfer *TransferRecord
+	transferKey := ""
+	if property.CoolingPeriod.TransferID != "" {
+		key, err := createTransferKey(ctx, property.CoolingPeriod.TransferID)
+		if err != nil {
+			return "", internalError("failed to create transfer key: %v", err)
+		}
+		transferBytes, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return "", internalError("failed to read transfer: %v", err)
+		}
+		if transferBytes != nil {
+			var record TransferRecord
+			if err := json.Unmarshal(transferBytes, &record); err != nil {
+				return "", internalError("failed to unmarshal transfer: %v", err)
+			}
+			if record.Status == "REGISTERED_PENDING_FINALITY" {
+				transfer = &record
+				transferKey = key
+			}
+		}
+	}
+
+	if transfer == nil {
+		property.CoolingPeriod = CoolingPeriod{Active: false, ExpiresAt: ""}
+		if err := countCoolingPeriods(ctx, property.Location.StateCode, -1); err != nil {
+			return "", err
+		}
+		property.UpdatedAt = now
+		property.UpdatedBy = "system"
+		if err := putLandRecord(ctx, property); err != nil {
+			return "", err
+		}
+		return "EXPIRED_UNFINALIZED", nil
+	}
+	if err := finalizeTransfer(ctx, transfer, transferKey, property); err != nil {
+		return "", err
+	}
+	return "FINALIZED", nil
+}
+
+// finalizeTransfer marks a transfer REGISTERED_FINAL and closes its
+// property's cooling period.
+func finalizeTransfer(ctx contractapi.TransactionContextInterface, transfer *TransferRecord, transferKey string, property *LandRecord) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	transfer.Status = "REGISTERED_FINAL"
+	transfer.StatusHistory = append(transfer.StatusHistory, StatusEntry{
+		Status: "REGISTERED_FINAL",
+		At:     now,
+		By:     "system",
+	})
+	transfer.FabricTxID = txID
+	transfer.UpdatedAt = now
+
+	transferUpdatedBytes, _ := canonicalMarshal(transfer)
+	if err := ctx.GetStub().PutState(transferKey, transferUpdatedBytes); err != nil {
+		return internalError("failed to finalize transfer: %v", err)
+	}
+
+	// Deactivate cooling period on property; a blocked window was
+	// already closed
+	if property.CoolingPeriod.Active {
+		if err := countCoolingPeriods(ctx, property.Location.StateCode, -1); err != nil {
+			return err
+		}
+	}
+	property.CoolingPeriod = CoolingPeriod{Active: false, ExpiresAt: ""}
+	property.UpdatedAt = now
+	property.UpdatedBy = "system"
+
+	landKey, _ := createLandKey(ctx, transfer.PropertyID)
+	propertyBytes, _ := canonicalMarshal(property)
+	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
+		return internalError("failed to update property cooling period: %v", err)
+	}
+	return nil
+}
+
+// activeCoolingPeriods returns the land records with an active cooling
+// period, in expiry order.
+func activeCoolingPeriods(ctx contractapi.TransactionContextInterface) ([]*LandRecord, error) {
+	queryString := `{"selector":{"docType":"landRecord","coolingPeriod.active":true},"sort":[{"coolingPeriod.expiresAt":"asc"}]}`
+	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, internalError("failed to query cooling periods: %v", err)
+	}
+	defer iterator.Close()
+
+	var properties []*LandRecord
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate: %v", err)
+		}
+		var property LandRecord
+		if err := json.Unmarshal(kv.Value, &property); err != nil {
+			return nil, internalError("failed to unmarshal property: %v", err)
+		}
+		// Sweeps write these records back, so they must be upgraded first
+		normalizeRecord(&property)
+		properties = append(properties, &property)
+	}
+	return properties, nil
+}
+
+// emitCoolingPeriodStarted emits COOLING_PERIOD_STARTED for a property
+// whose cooling period has just begun, listed under
+// coolingPeriodsStarted on TRANSFER_COMPLETED.
+func emitCoolingPeriodStarted(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	event := newCoolingPeriodEvent(ctx, "COOLING_PERIOD_STARTED", property, property.CoolingPeriod.TransferID, "")
+	event.ExpiresAt = property.CoolingPeriod.ExpiresAt
+	return emitRelatedEvent(ctx, "COOLING_PERIOD_STARTED", "coolingPeriodsStarted", event, event)
+}
+
+// emitCoolingPeriodEnded emits COOLING_PERIOD_ENDED with its outcome,
+// listed under coolingPeriodsEnded.
+func emitCoolingPeriodEnded(ctx contractapi.TransactionContextInterface, property *LandRecord, transferID, outcome string) error {
+	event := newCoolingPeriodEvent(ctx, "COOLING_PERIOD_ENDED", property, transferID, outcome)
+	return emitRelatedEvent(ctx, "COOLING_PERIOD_ENDED", "coolingPeriodsEnded", event, event)
+}
+
+func newCoolingPeriodEvent(ctx contractapi.TransactionContextInterface, eventType string, property *LandRecord, transferID, outcome string) CoolingPeriodEvent {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	return CoolingPeriodEvent{
+		Type:       eventType,
+		PropertyID: property.PropertyID,
+		TransferID: transferID,
+		Outcome:    outcome,
+		FabricTxID: ctx.GetStub().GetTxID(),
+		Timestamp:  time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:  property.Location.StateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+}
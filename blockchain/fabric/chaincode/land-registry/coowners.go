@@ -0,0 +1,351 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// CO-OWNERSHIP CHANGES (FAMILY ARRANGEMENTS)
+// ============================================================
+
+// AddCoOwner adds a co-owner (typically a spouse) to a property under a
+// registered deed without a sale. ownerJSON is an Owner plus a
+// "rebalancedShares" map of existing owners' aadhaarHash to their new
+// share; the new owner's sharePercentage and the rebalanced shares must
+// sum to 100 and every owner must keep a positive share. A
+// FAMILY_ARRANGEMENT mutation is recorded for the revenue trail.
+// Only registrars in the property's state can add co-owners.
+// Emits CO_OWNERSHIP_CHANGED.
+func (s *LandRegistryContract) AddCoOwner(ctx contractapi.TransactionContextInterface, propertyID, ownerJSON, deedHash string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if deedHash == "" {
+		return newError(ErrCodeValidationError, "deedHash of the registered deed is required")
+	}
+
+	var req AddCoOwnerRequest
+	if err := json.Unmarshal([]byte(ownerJSON), &req); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse owner JSON: %v", err)
+	}
+	if err := validateAadhaarHash(req.AadhaarHash, "owner.aadhaarHash"); err != nil {
+		return err
+	}
+	if req.Name == "" {
+		return newError(ErrCodeValidationError, "co-owner name is required")
+	}
+	if req.SharePercentage <= 0 {
+		return newError(ErrCodeValidationError, "co-owner sharePercentage must be positive")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+	if err := requireCoOwnershipChangeAllowed(property); err != nil {
+		return err
+	}
+
+	owners := make([]Owner, len(property.CurrentOwner.Owners))
+	copy(owners, property.CurrentOwner.Owners)
+	for i, owner := range owners {
+		if owner.AadhaarHash == req.AadhaarHash {
+			return newError(ErrCodeOwnerExists, "%s is already an owner of %s", req.AadhaarHash, propertyID)
+		}
+		if share, ok := req.RebalancedShares[owner.AadhaarHash]; ok {
+			owners[i].SharePercentage = share
+		}
+	}
+	for hash := range req.RebalancedShares {
+		if !hasOwner(owners, hash) {
+			return newError(ErrCodeOwnerNotFound, "rebalancedShares names %s, who is not an owner of %s", hash, propertyID)
+		}
+	}
+	owners = append(owners, req.Owner)
+	if err := validateOwnership(owners); err != nil {
+		return err
+	}
+	ownerType := normalizeOwnerType(property.CurrentOwner.OwnerType)
+	if err := validateEntityDetails(ownerType, owners[len(owners)-1].Entity, req.IsMinor, "owner"); err != nil {
+		return err
+	}
+
+	primary := property.CurrentOwner.Owners[0]
+	property.CurrentOwner.Owners = owners
+	if property.CurrentOwner.OwnershipType == "" || property.CurrentOwner.OwnershipType == "SOLE" {
+		property.CurrentOwner.OwnershipType = "JOINT"
+	}
+
+	mutationID, err := s.recordFamilyArrangement(ctx, property, OwnerRef{AadhaarHash: primary.AadhaarHash, Name: primary.Name}, OwnerRef{AadhaarHash: req.AadhaarHash, Name: req.Name})
+	if err != nil {
+		return err
+	}
+	if err := putOwnerIndex(ctx, req.AadhaarHash, propertyID); err != nil {
+		return internalError("failed to create owner index: %v", err)
+	}
+	if err := putEntityIndex(ctx, owners[len(owners)-1], propertyID); err != nil {
+		return internalError("failed to create entity index: %v", err)
+	}
+
+	return emitCoOwnershipChanged(ctx, property, "ADD_CO_OWNER", mutationID, primary.AadhaarHash, req.AadhaarHash, req.SharePercentage, deedHash)
+}
+
+// ReleaseShare records a release deed by which one co-owner relinquishes
+// shareTransferred percentage points to another existing co-owner. A
+// co-owner releasing their whole share leaves the owner list. A sole
+// owner cannot release their entire holding — that is a transfer.
+// A FAMILY_ARRANGEMENT mutation is recorded for the revenue trail.
+// Only registrars in the property's state can record releases.
+// Emits CO_OWNERSHIP_CHANGED.
+func (s *LandRegistryContract) ReleaseShare(ctx contractapi.TransactionContextInterface, propertyID, releasingHash, beneficiaryHash string, shareTransferred int, deedHash string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(releasingHash, "releasingHash"); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(beneficiaryHash, "beneficiaryHash"); err != nil {
+		return err
+	}
+	if releasingHash == beneficiaryHash {
+		return newError(ErrCodeValidationError, "an owner cannot release a share to themselves")
+	}
+	if shareTransferred <= 0 {
+		return newError(ErrCodeValidationError, "shareTransferred must be positive")
+	}
+	if deedHash == "" {
+		return newError(ErrCodeValidationError, "deedHash of the registered release deed is required")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+	if err := requireCoOwnershipChangeAllowed(property); err != nil {
+		return err
+	}
+
+	if len(property.CurrentOwner.Owners) == 1 {
+		return newError(ErrCodeReleaseIsTransfer, "the sole owner of %s cannot release their holding; register a transfer instead", propertyID)
+	}
+
+	releaserIdx, beneficiaryIdx := -1, -1
+	for i, owner := range property.CurrentOwner.Owners {
+		switch owner.AadhaarHash {
+		case releasingHash:
+			releaserIdx = i
+		case beneficiaryHash:
+			beneficiaryIdx = i
+		}
+	}
+	if releaserIdx < 0 {
+		return newError(ErrCodeOwnerNotFound, "%s is not a co-owner of %s", releasingHash, propertyID)
+	}
+	if beneficiaryIdx < 0 {
+		return newError(ErrCodeOwnerNotFound, "beneficiary %s is not a co-owner of %s; a release must be in favour of an existing co-owner", beneficiaryHash, propertyID)
+	}
+
+	releaser := property.CurrentOwner.Owners[releaserIdx]
+	beneficiary := property.CurrentOwner.Owners[beneficiaryIdx]
+	if releaser.IsMinor {
+		return newError(ErrCodeTransferMinorProperty, "a minor's share cannot be released without a court order (owner: %s)", releaser.Name)
+	}
+	if shareTransferred > releaser.SharePercentage {
+		return newError(ErrCodeValidationError, "%s holds %d%%, cannot release %d%%", releasingHash, releaser.SharePercentage, shareTransferred)
+	}
+
+	var owners []Owner
+	for i, owner := range property.CurrentOwner.Owners {
+		switch i {
+		case releaserIdx:
+			owner.SharePercentage -= shareTransferred
+			if owner.SharePercentage == 0 {
+				continue
+			}
+		case beneficiaryIdx:
+			owner.SharePercentage += shareTransferred
+		}
+		owners = append(owners, owner)
+	}
+	if err := validateOwnership(owners); err != nil {
+		return err
+	}
+
+	property.CurrentOwner.Owners = owners
+	if len(owners) == 1 {
+		property.CurrentOwner.OwnershipType = "SOLE"
+	}
+
+	mutationID, err := s.recordFamilyArrangement(ctx, property, OwnerRef{AadhaarHash: releaser.AadhaarHash, Name: releaser.Name}, OwnerRef{AadhaarHash: beneficiary.AadhaarHash, Name: beneficiary.Name})
+	if err != nil {
+		return err
+	}
+	if !hasOwner(owners, releasingHash) {
+		if err := deleteOwnerIndex(ctx, releasingHash, propertyID); err != nil {
+			return internalError("failed to remove owner index: %v", err)
+		}
+		if err := deleteEntityIndex(ctx, releaser, propertyID); err != nil {
+			return internalError("failed to remove entity index: %v", err)
+		}
+	}
+
+	return emitCoOwnershipChanged(ctx, property, "RELEASE_SHARE", mutationID, releasingHash, beneficiaryHash, shareTransferred, deedHash)
+}
+
+// requireCoOwnershipChangeAllowed applies the transfer preconditions
+// that also bind family arrangements: active, undisputed, not frozen
+// or held, and outside a cooling period.
+func requireCoOwnershipChangeAllowed(property *LandRecord) error {
+	if property.Status == "FROZEN" {
+		return newError(ErrCodeLandFrozen, "property %s is frozen by court order", property.PropertyID)
+	}
+	if err := requireNoInvestigationHold(property); err != nil {
+		return err
+	}
+	if property.Status != "ACTIVE" {
+		return newError(ErrCodePropertyNotActive, "cannot change ownership of property with status %s", property.Status)
+	}
+	if property.DisputeStatus != "CLEAR" {
+		return newError(ErrCodeLandDisputed, "property %s has active dispute", property.PropertyID)
+	}
+	if property.CoolingPeriod.Active {
+		return newError(ErrCodeLandCoolingPeriod, "property in cooling period until %s", property.CoolingPeriod.ExpiresAt)
+	}
+	return nil
+}
+
+// recordFamilyArrangement saves the updated property and creates an
+// auto-approved FAMILY_ARRANGEMENT mutation. The deed is already
+// registered, so the revenue record is updated immediately.
+func (s *LandRegistryContract) recordFamilyArrangement(ctx contractapi.TransactionContextInterface, property *LandRecord, from, to OwnerRef) (string, error) {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	property.CurrentOwner.AcquisitionType = "FAMILY_ARRANGEMENT"
+	property.CurrentOwner.AcquisitionDate = now[:10]
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.Provenance.Sequence++
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return "", err
+	}
+
+	mutationID := "mut_" + txID[:8]
+	mutation := MutationRecord{
+		DocType:              "mutationRecord",
+		MutationID:           mutationID,
+		PropertyID:           property.PropertyID,
+		Type:                 "FAMILY_ARRANGEMENT",
+		PreviousOwner:        from,
+		NewOwner:             to,
+		Status:               "AUTO_APPROVED",
+		ApprovedBy:           "system",
+		ApprovedAt:           now,
+		RevenueRecordUpdated: true,
+		CreatedAt:            now,
+	}
+	mutationKey, err := createMutationKey(ctx, mutationID)
+	if err != nil {
+		return "", internalError("failed to create mutation key: %v", err)
+	}
+	mutationBytes, err := canonicalMarshal(mutation)
+	if err != nil {
+		return "", internalError("failed to marshal mutation: %v", err)
+	}
+	if err := ctx.GetStub().PutState(mutationKey, mutationBytes); err != nil {
+		return "", internalError("failed to create mutation record: %v", err)
+	}
+	if err := emitMutationCreated(ctx, &mutation, property.Location.StateCode); err != nil {
+		return "", err
+	}
+	return mutationID, nil
+}
+
+// emitCoOwnershipChanged emits a CO_OWNERSHIP_CHANGED event.
+func emitCoOwnershipChanged(ctx contractapi.TransactionContextInterface, property *LandRecord, changeType, mutationID, fromHash, toHash string, share int, deedHash string) error {
+	event := CoOwnershipChangedEvent{
+		Type:            "CO_OWNERSHIP_CHANGED",
+		ChangeType:      changeType,
+		PropertyID:      property.PropertyID,
+		MutationID:      mutationID,
+		FromOwnerHash:   fromHash,
+		ToOwnerHash:     toHash,
+		SharePercentage: share,
+		DeedHash:        deedHash,
+		FabricTxID:      property.FabricTxID,
+		Timestamp:       property.UpdatedAt,
+		StateCode:       property.Location.StateCode,
+		ChannelID:       ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "CO_OWNERSHIP_CHANGED", event)
+}
+
+// hasOwner reports whether owners contains the given aadhaarHash.
+func hasOwner(owners []Owner, aadhaarHash string) bool {
+	for _, owner := range owners {
+		if owner.AadhaarHash == aadhaarHash {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditOwnershipInvariants scans every land record and returns those
+// whose owners violate the share invariants enforced by
+// validateOwnership. It is a one-time check for records written before
+// the invariants were enforced. Only admins can run the audit.
+func (s *LandRegistryContract) AuditOwnershipInvariants(ctx contractapi.TransactionContextInterface) ([]*OwnershipViolation, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixLand, []string{})
+	if err != nil {
+		return nil, internalError("failed to iterate land records: %v", err)
+	}
+	defer iterator.Close()
+
+	violations := []*OwnershipViolation{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate: %v", err)
+		}
+		var property LandRecord
+		if err := json.Unmarshal(kv.Value, &property); err != nil {
+			continue
+		}
+		normalizeRecord(&property)
+		owners := make([]Owner, len(property.CurrentOwner.Owners))
+		copy(owners, property.CurrentOwner.Owners)
+		if err := validateOwnership(owners); err != nil {
+			violations = append(violations, &OwnershipViolation{
+				PropertyID: property.PropertyID,
+				Status:     property.Status,
+				Violation:  errorText(err),
+			})
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// CORRECTIONS
+// ============================================================
+
+// correctableFields lists the top-level LandRecord fields that may be
+// changed through CorrectPropertyDetails. Ownership, status and location
+// change only through transfers, mutations and court orders.
+var correctableFields = map[string]bool{
+	"boundaries":         true,
+	"area":               true,
+	"subSurveyNumber":    true,
+	"landClassification": true,
+	"pinCode":            true,
+}
+
+// CorrectPropertyDetails fixes clerical errors in a property's
+// boundaries, area, sub-survey number, land classification or PIN code
+// under a correction order. correctionsJSON holds a "reason" plus any of
+// "boundaries", "area", "subSurveyNumber", "landClassification" and
+// "pinCode"; nested objects are merged onto the current values,
+// so only the supplied sub-fields change. Any other field is rejected.
+// The change is appended to the record's Corrections audit trail and a
+// PROPERTY_CORRECTED event carries the field-level diff.
+// Requires registrar or admin role in the property's state.
+func (s *LandRegistryContract) CorrectPropertyDetails(ctx contractapi.TransactionContextInterface, propertyID, correctionsJSON, approvalRef string) error {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if approvalRef == "" {
+		return newError(ErrCodeValidationError, "approvalRef (correction order) is required")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(correctionsJSON), &fields); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse corrections JSON: %v", err)
+	}
+
+	var reason string
+	if raw, ok := fields["reason"]; ok {
+		if err := json.Unmarshal(raw, &reason); err != nil {
+			return newError(ErrCodeInvalidInput, "reason must be a string")
+		}
+		delete(fields, "reason")
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required for a correction")
+	}
+	if len(fields) == 0 {
+		return newError(ErrCodeValidationError, "no fields to correct")
+	}
+	for field := range fields {
+		if !correctableFields[field] {
+			return newError(ErrCodeCorrectionFieldNotAllowed, "'%s' cannot be changed by correction; only boundaries, area, subSurveyNumber, landClassification and pinCode are correctable", field)
+		}
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+
+	if property.Status != "ACTIVE" {
+		return newError(ErrCodePropertyNotActive, "cannot correct property with status %s", property.Status)
+	}
+
+	var changes []FieldChange
+
+	if raw, ok := fields["boundaries"]; ok {
+		corrected := property.Boundaries
+		if err := json.Unmarshal(raw, &corrected); err != nil {
+			return newError(ErrCodeInvalidInput, "failed to parse boundaries: %v", err)
+		}
+		if err := validateGeoJSON(corrected.GeoJSON); err != nil {
+			return err
+		}
+		changes = appendChange(changes, "boundaries.north", property.Boundaries.North, corrected.North)
+		changes = appendChange(changes, "boundaries.south", property.Boundaries.South, corrected.South)
+		changes = appendChange(changes, "boundaries.east", property.Boundaries.East, corrected.East)
+		changes = appendChange(changes, "boundaries.west", property.Boundaries.West, corrected.West)
+		oldGeo, _ := json.Marshal(property.Boundaries.GeoJSON)
+		newGeo, _ := json.Marshal(corrected.GeoJSON)
+		changes = appendChange(changes, "boundaries.geoJson", string(oldGeo), string(newGeo))
+		property.Boundaries = corrected
+	}
+
+	if raw, ok := fields["area"]; ok {
+		corrected := property.Area
+		if err := json.Unmarshal(raw, &corrected); err != nil {
+			return newError(ErrCodeInvalidInput, "failed to parse area: %v", err)
+		}
+		if corrected.Value <= 0 {
+			return newError(ErrCodeValidationError, "area value must be positive")
+		}
+		changes = appendChange(changes, "area.value", fmt.Sprintf("%v", property.Area.Value), fmt.Sprintf("%v", corrected.Value))
+		changes = appendChange(changes, "area.unit", property.Area.Unit, corrected.Unit)
+		changes = appendChange(changes, "area.localValue", fmt.Sprintf("%v", property.Area.LocalVal), fmt.Sprintf("%v", corrected.LocalVal))
+		changes = appendChange(changes, "area.localUnit", property.Area.LocalUnit, corrected.LocalUnit)
+		property.Area = corrected
+	}
+
+	oldSurveyKey := surveyIndexNumber(property.SurveyNumber, property.SubSurveyNumber)
+	if raw, ok := fields["subSurveyNumber"]; ok {
+		var corrected string
+		if err := json.Unmarshal(raw, &corrected); err != nil {
+			return newError(ErrCodeInvalidInput, "subSurveyNumber must be a string")
+		}
+		changes = appendChange(changes, "subSurveyNumber", property.SubSurveyNumber, corrected)
+		property.SubSurveyNumber = corrected
+	}
+
+	if raw, ok := fields["landClassification"]; ok {
+		var corrected string
+		if err := json.Unmarshal(raw, &corrected); err != nil {
+			return newError(ErrCodeInvalidInput, "landClassification must be a string")
+		}
+		settings, err := getSettings(ctx, property.Location.StateCode)
+		if err != nil {
+			return err
+		}
+		if err := validateLandClassification(corrected, settings); err != nil {
+			return err
+		}
+		changes = appendChange(changes, "landClassification", property.LandClassification, corrected)
+		property.LandClassification = corrected
+	}
+
+	if raw, ok := fields["pinCode"]; ok {
+		var corrected string
+		if err := json.Unmarshal(raw, &corrected); err != nil {
+			return newError(ErrCodeInvalidInput, "pinCode must be a string")
+		}
+		if err := validatePinCode(corrected, property.Location.StateCode); err != nil {
+			return err
+		}
+		changes = appendChange(changes, "location.pinCode", property.Location.PinCode, corrected)
+		property.Location.PinCode = corrected
+	}
+
+	if len(changes) == 0 {
+		return newError(ErrCodeValidationError, "corrections do not change any field")
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	entry := CorrectionEntry{
+		CorrectionID: "cor_" + txID[:8],
+		Type:         "PROPERTY_DETAILS",
+		Changes:      changes,
+		Reason:       reason,
+		ApprovalRef:  approvalRef,
+		CorrectedBy:  getCallerID(ctx),
+		CorrectedAt:  now,
+		FabricTxID:   txID,
+	}
+	property.Corrections = append(property.Corrections, entry)
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+
+	// A corrected polygon may now overlap a neighbour
+	var conflictIDs []string
+	if _, ok := fields["boundaries"]; ok {
+		conflictIDs, err = s.checkBoundaryOverlaps(ctx, property, now, txID)
+		if err != nil {
+			return err
+		}
+	}
+
+	landKey, err := createLandKey(ctx, propertyID)
+	if err != nil {
+		return internalError("failed to create land key: %v", err)
+	}
+	propertyBytes, err := canonicalMarshal(property)
+	if err != nil {
+		return internalError("failed to marshal property: %v", err)
+	}
+	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
+		return internalError("failed to update property: %v", err)
+	}
+
+	// Keep the survey index in step with a corrected sub-survey number
+	newSurveyKey := surveyIndexNumber(property.SurveyNumber, property.SubSurveyNumber)
+	if newSurveyKey != oldSurveyKey {
+		if err := deleteSurveyIndex(ctx, property.Location.StateCode, property.Location.DistrictCode, oldSurveyKey); err != nil {
+			return internalError("failed to remove survey index: %v", err)
+		}
+		if err := putSurveyIndex(ctx, property.Location.StateCode, property.Location.DistrictCode, newSurveyKey, propertyID); err != nil {
+			return internalError("failed to create survey index: %v", err)
+		}
+	}
+
+	event := PropertyCorrectedEvent{
+		Type:              "PROPERTY_CORRECTED",
+		PropertyID:        propertyID,
+		CorrectionID:      entry.CorrectionID,
+		Changes:           changes,
+		Reason:            reason,
+		ApprovalRef:       approvalRef,
+		BoundaryConflicts: conflictIDs,
+		FabricTxID:        txID,
+		Timestamp:         now,
+		StateCode:         property.Location.StateCode,
+		ChannelID:         ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "PROPERTY_CORRECTED", event)
+}
+
+// appendChange records a field change if the value differs.
+func appendChange(changes []FieldChange, field, oldValue, newValue string) []FieldChange {
+	if oldValue == newValue {
+		return changes
+	}
+	return append(changes, FieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+}
+
+// CorrectOwnerName fixes the spelling of an owner's name without
+// touching ownership. The owner is matched by aadhaarHash, which cannot
+// change here: an unknown hash fails with OWNER_NOT_FOUND, since
+// substituting a different person is an ownership change that must go
+// through a transfer or mutation. The old name, supporting document hash
+// and approver are recorded in the Corrections audit trail.
+// Only registrars in the property's state can correct names.
+// Emits OWNER_NAME_CORRECTED.
+func (s *LandRegistryContract) CorrectOwnerName(ctx contractapi.TransactionContextInterface, propertyID, aadhaarHash, newName, supportingDocHash string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(aadhaarHash, "aadhaarHash"); err != nil {
+		return err
+	}
+	if newName == "" {
+		return newError(ErrCodeValidationError, "newName cannot be empty")
+	}
+	if supportingDocHash == "" {
+		return newError(ErrCodeValidationError, "supportingDocHash is required for a name correction")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+
+	if property.Status != "ACTIVE" {
+		return newError(ErrCodePropertyNotActive, "cannot correct property with status %s", property.Status)
+	}
+
+	ownerIdx := -1
+	for i, owner := range property.CurrentOwner.Owners {
+		if owner.AadhaarHash == aadhaarHash {
+			ownerIdx = i
+			break
+		}
+	}
+	if ownerIdx < 0 {
+		return newError(ErrCodeOwnerNotFound, "no owner with aadhaarHash %s on property %s; a name correction cannot change who owns the property", aadhaarHash, propertyID)
+	}
+
+	oldName := property.CurrentOwner.Owners[ownerIdx].Name
+	if oldName == newName {
+		return newError(ErrCodeValidationError, "owner name is already '%s'", newName)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	property.CurrentOwner.Owners[ownerIdx].Name = newName
+
+	changes := []FieldChange{{
+		Field:    fmt.Sprintf("currentOwner.owners[%s].name", aadhaarHash),
+		OldValue: oldName,
+		NewValue: newName,
+	}}
+	entry := CorrectionEntry{
+		CorrectionID: "cor_" + txID[:8],
+		Type:         "OWNER_NAME",
+		Changes:      changes,
+		Reason:       "owner name correction",
+		DocumentHash: supportingDocHash,
+		CorrectedBy:  getCallerID(ctx),
+		CorrectedAt:  now,
+		FabricTxID:   txID,
+	}
+	property.Corrections = append(property.Corrections, entry)
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+
+	landKey, err := createLandKey(ctx, propertyID)
+	if err != nil {
+		return internalError("failed to create land key: %v", err)
+	}
+	propertyBytes, err := canonicalMarshal(property)
+	if err != nil {
+		return internalError("failed to marshal property: %v", err)
+	}
+	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
+		return internalError("failed to update property: %v", err)
+	}
+
+	event := OwnerNameCorrectedEvent{
+		Type:              "OWNER_NAME_CORRECTED",
+		PropertyID:        propertyID,
+		CorrectionID:      entry.CorrectionID,
+		OwnerHash:         aadhaarHash,
+		OldName:           oldName,
+		NewName:           newName,
+		SupportingDocHash: supportingDocHash,
+		FabricTxID:        txID,
+		Timestamp:         now,
+		StateCode:         property.Location.StateCode,
+		ChannelID:         ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "OWNER_NAME_CORRECTED", event)
+}
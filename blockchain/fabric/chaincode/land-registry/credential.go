@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// OWNERSHIP VERIFIABLE CREDENTIAL
+// ============================================================
+// DigiLocker and banks' loan origination systems take ownership proofs
+// as W3C Verifiable Credentials. The chaincode holds no signing key, so
+// the credential's proof is not a signature: it carries the SHA-256 of
+// the canonical credentialSubject, the land record's hash and the
+// state's latest anchor, and VerifyOwnershipCredential checks them
+// against the ledger. The subject carries the holder's masked name and
+// hash only, as the certificate payload does.
+
+// Credential vocabulary.
+const (
+	credentialContextW3C  = "https://www.w3.org/2018/credentials/v1"
+	credentialContextLand = "https://bhulekhchain.gov.in/credentials/land-ownership/v1"
+	credentialType        = "LandOwnershipCredential"
+	credentialProofType   = "LedgerRecordHashProof"
+)
+
+// ExportOwnershipCredential returns a LandOwnershipCredential stating
+// holderAadhaarHash's share in a property, issued by the state's
+// RegistryDID. The holder must be a current owner, and only the holder
+// or an official may export the credential. SPLIT, MERGED and ARCHIVED
+// records have no owners to attest.
+func (s *LandRegistryContract) ExportOwnershipCredential(ctx contractapi.TransactionContextInterface, propertyID, holderAadhaarHash string) (*OwnershipCredential, error) {
+	if err := validateAadhaarHash(holderAadhaarHash, "holderAadhaarHash"); err != nil {
+		return nil, err
+	}
+	if err := requireSelfOrRole(ctx, []string{holderAadhaarHash}, officialRoles...); err != nil {
+		return nil, err
+	}
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if retiredFromIndexes(property.Status) {
+		return nil, newError(ErrCodeValidationError, "%s is %s and has no current owners", propertyID, property.Status)
+	}
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	if settings.RegistryDID == "" {
+		return nil, newError(ErrCodeCredentialIssuerNotConfigured, "%s has no registryDid configured", property.Location.StateCode)
+	}
+
+	subject, err := credentialSubject(property, holderAadhaarHash)
+	if err != nil {
+		return nil, err
+	}
+	subjectHash, err := credentialSubjectHash(subject)
+	if err != nil {
+		return nil, err
+	}
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := latestAnchor(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).UTC().Format(time.RFC3339)
+
+	credential := &OwnershipCredential{
+		Context:           []string{credentialContextW3C, credentialContextLand},
+		ID:                "urn:bhulekh:credential:" + ctx.GetStub().GetTxID(),
+		Type:              []string{"VerifiableCredential", credentialType},
+		Issuer:            settings.RegistryDID,
+		IssuanceDate:      now,
+		CredentialSubject: *subject,
+		Proof: OwnershipCredentialProof{
+			Type:               credentialProofType,
+			Created:            now,
+			ProofPurpose:       "assertionMethod",
+			VerificationMethod: settings.RegistryDID + "#ledger",
+			SubjectHash:        subjectHash,
+			RecordHash:         recordHash,
+			ChannelID:          ctx.GetStub().GetChannelID(),
+		},
+	}
+	if anchor != nil {
+		credential.Proof.Anchor = &CertificateAnchor{
+			AnchorID:     anchor.AnchorID,
+			AlgorandTxID: anchor.AlgorandTxID,
+			AnchoredAt:   anchor.AnchoredAt,
+		}
+	}
+	return credential, nil
+}
+
+// VerifyOwnershipCredential checks a credential from
+// ExportOwnershipCredential. SubjectHashValid reports whether the
+// credentialSubject is the one the proof hashed, IssuerValid whether the
+// issuer is the state's RegistryDID and AnchorValid whether the anchor
+// in the proof is on the ledger. Current reports whether the live record
+// still hashes to the proof's recordHash and matches the subject;
+// Divergence names the subject fields it no longer matches. Valid needs
+// all four. Anyone may verify a credential.
+func (s *LandRegistryContract) VerifyOwnershipCredential(ctx contractapi.TransactionContextInterface, credentialJSON string) (*CredentialVerification, error) {
+	var presented OwnershipCredential
+	if err := json.Unmarshal([]byte(credentialJSON), &presented); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse credential: %v", err)
+	}
+	subject := presented.CredentialSubject
+	if err := validatePropertyID(subject.PropertyID); err != nil {
+		return nil, err
+	}
+	if err := validateAadhaarHash(subject.HolderAadhaarHash, "credentialSubject.holderAadhaarHash"); err != nil {
+		return nil, err
+	}
+
+	property, err := readLandRecord(ctx, subject.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	subjectHash, err := credentialSubjectHash(&subject)
+	if err != nil {
+		return nil, err
+	}
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+
+	result := &CredentialVerification{
+		PropertyID:        subject.PropertyID,
+		HolderAadhaarHash: subject.HolderAadhaarHash,
+		SubjectHashValid:  subjectHash == presented.Proof.SubjectHash,
+		IssuerValid:       settings.RegistryDID != "" && presented.Issuer == settings.RegistryDID,
+		AnchorValid:       true,
+		Current:           recordHash == presented.Proof.RecordHash,
+		Divergence:        []string{},
+		CurrentRecordHash: recordHash,
+		VerifiedAt:        time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+	}
+
+	if anchor := presented.Proof.Anchor; anchor != nil {
+		anchorKey, err := createAnchorKey(ctx, property.Location.StateCode, anchor.AnchorID)
+		if err != nil {
+			return nil, internalError("failed to create anchor key: %v", err)
+		}
+		anchorBytes, err := ctx.GetStub().GetState(anchorKey)
+		if err != nil {
+			return nil, internalError("failed to read anchor: %v", err)
+		}
+		var recorded AnchorRecord
+		result.AnchorValid = anchorBytes != nil &&
+			json.Unmarshal(anchorBytes, &recorded) == nil &&
+			recorded.AlgorandTxID == anchor.AlgorandTxID
+	}
+
+	live, err := credentialSubject(property, subject.HolderAadhaarHash)
+	if err != nil {
+		// The holder no longer owns a share
+		result.Divergence = append(result.Divergence, "holderAadhaarHash")
+	} else {
+		presentedFields := reflect.ValueOf(subject)
+		liveFields := reflect.ValueOf(*live)
+		for i := 0; i < presentedFields.NumField(); i++ {
+			if !reflect.DeepEqual(presentedFields.Field(i).Interface(), liveFields.Field(i).Interface()) {
+				name, _, _ := strings.Cut(presentedFields.Type().Field(i).Tag.Get("json"), ",")
+				result.Divergence = append(result.Divergence, name)
+			}
+		}
+	}
+	if len(result.Divergence) > 0 {
+		result.Current = false
+	}
+	result.Valid = result.SubjectHashValid && result.IssuerValid && result.AnchorValid && result.Current
+	return result, nil
+}
+
+// credentialSubject builds the credentialSubject of a holder's
+// ownership credential, or fails if the holder is not a current owner.
+func credentialSubject(property *LandRecord, holderAadhaarHash string) (*OwnershipCredentialSubject, error) {
+	for _, owner := range property.CurrentOwner.Owners {
+		if owner.AadhaarHash != holderAadhaarHash {
+			continue
+		}
+		return &OwnershipCredentialSubject{
+			PropertyID:          property.PropertyID,
+			SurveyNumber:        property.SurveyNumber,
+			SubSurveyNumber:     property.SubSurveyNumber,
+			StateCode:           property.Location.StateCode,
+			DistrictCode:        property.Location.DistrictCode,
+			TehsilCode:          property.Location.TehsilCode,
+			VillageCode:         property.Location.VillageCode,
+			Area:                CertificateArea{Value: property.Area.Value, Unit: property.Area.Unit},
+			LandUse:             property.LandUse,
+			OwnerType:           property.CurrentOwner.OwnerType,
+			HolderName:          maskName(owner.Name),
+			HolderAadhaarHash:   owner.AadhaarHash,
+			SharePercentage:     owner.SharePercentage,
+			OwnerCount:          len(property.CurrentOwner.Owners),
+			Status:              property.Status,
+			DisputeStatus:       property.DisputeStatus,
+			EncumbranceStatus:   property.EncumbranceStatus,
+			CoolingPeriodActive: property.CoolingPeriod.Active,
+		}, nil
+	}
+	return nil, newError(ErrCodeCredentialHolderNotOwner, "holder is not a current owner of %s", property.PropertyID)
+}
+
+// credentialSubjectHash returns the SHA-256 of a credentialSubject's
+// canonical JSON.
+func credentialSubjectHash(subject *OwnershipCredentialSubject) (string, error) {
+	subjectBytes, err := canonicalMarshal(subject)
+	if err != nil {
+		return "", internalError("failed to marshal credential subject: %v", err)
+	}
+	return certificatePayloadHash(subjectBytes), nil
+}
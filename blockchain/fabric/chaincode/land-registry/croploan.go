@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// CROP LOANS
+// ============================================================
+// A Kisan Credit Card loan is a seasonal charge on the land and its
+// standing crop: an encumbrance of type CROP_LOAN whose cropLoan details
+// name the cultivator, season, crop and limit, and whose details.endDate
+// is the season end. It does not block a sale, but the buyer must
+// acknowledge it (see checkCropLoansAcknowledged). Once the season has
+// ended without renewal it can be released by the revenue office as
+// well as the bank (see ReleaseLapsedCropLoan). RenewCropLoan moves the
+// same charge to the next season, so it keeps its ID and creation time.
+
+// RenewCropLoan renews an active crop loan for the next season.
+// renewalJSON is a CropLoanRenewal with season, year, crop, limitAmount
+// (paisa) and seasonEndDate (YYYY-MM-DD, after the current one). The
+// current season is kept in the loan's history. Banks can renew only
+// their own crop loans; admins any. Emits ENCUMBRANCE_UPDATED.
+func (s *LandRegistryContract) RenewCropLoan(ctx contractapi.TransactionContextInterface, encumbranceID, renewalJSON string) error {
+	role, err := requireAnyRole(ctx, "bank", "admin")
+	if err != nil {
+		return err
+	}
+
+	var renewal CropLoanRenewal
+	if err := json.Unmarshal([]byte(renewalJSON), &renewal); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse renewal JSON: %v", err)
+	}
+
+	enc, err := findEncumbrance(ctx, encumbranceID)
+	if err != nil {
+		return err
+	}
+	if enc.Type != "CROP_LOAN" || enc.CropLoan == nil {
+		return newError(ErrCodeValidationError, "encumbrance %s is not a crop loan", encumbranceID)
+	}
+	if enc.Status != "ACTIVE" {
+		return newError(ErrCodeEncumbranceNotActive, "encumbrance %s has status %s", encumbranceID, enc.Status)
+	}
+	if role == "bank" {
+		mspID, _ := ctx.GetClientIdentity().GetMSPID()
+		if enc.Institution.MspID != mspID {
+			return newError(ErrCodeAccessDenied, "encumbrance %s is held by %s", encumbranceID, enc.Institution.MspID)
+		}
+	}
+
+	next := CropLoanDetails{
+		CultivatorHash: enc.CropLoan.CultivatorHash,
+		Season:         renewal.Season,
+		Year:           renewal.Year,
+		Crop:           renewal.Crop,
+		LimitAmount:    renewal.LimitAmount,
+	}
+	if err := validateCropSeason(&next, renewal.SeasonEndDate); err != nil {
+		return err
+	}
+	if renewal.SeasonEndDate <= enc.Details.EndDate {
+		return newError(ErrCodeValidationError, "seasonEndDate must be after the current season end %s", enc.Details.EndDate)
+	}
+	if enc.Details.OutstandingAmount > next.LimitAmount {
+		return newError(ErrCodeValidationError, "outstandingAmount %d exceeds the renewed limitAmount %d", enc.Details.OutstandingAmount, next.LimitAmount)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	changes := []FieldChange{
+		{Field: "cropLoan.season", OldValue: fmt.Sprintf("%s %d", enc.CropLoan.Season, enc.CropLoan.Year), NewValue: fmt.Sprintf("%s %d", next.Season, next.Year)},
+		{Field: "details.endDate", OldValue: enc.Details.EndDate, NewValue: renewal.SeasonEndDate},
+	}
+	if next.Crop != enc.CropLoan.Crop {
+		changes = append(changes, FieldChange{Field: "cropLoan.crop", OldValue: enc.CropLoan.Crop, NewValue: next.Crop})
+	}
+	if next.LimitAmount != enc.CropLoan.LimitAmount {
+		changes = append(changes, FieldChange{
+			Field:    "cropLoan.limitAmount",
+			OldValue: strconv.FormatInt(enc.CropLoan.LimitAmount, 10),
+			NewValue: strconv.FormatInt(next.LimitAmount, 10),
+		})
+	}
+
+	next.History = append(enc.CropLoan.History, CropLoanRenewal{
+		Season:        enc.CropLoan.Season,
+		Year:          enc.CropLoan.Year,
+		Crop:          enc.CropLoan.Crop,
+		LimitAmount:   enc.CropLoan.LimitAmount,
+		SeasonEndDate: enc.Details.EndDate,
+		RenewedAt:     now,
+		RenewedBy:     getCallerID(ctx),
+	})
+	enc.CropLoan = &next
+	enc.Details.EndDate = renewal.SeasonEndDate
+	enc.Details.SanctionedAmount = next.LimitAmount
+	if err := putEncumbrance(ctx, enc); err != nil {
+		return err
+	}
+
+	event := EncumbranceUpdatedEvent{
+		Type:             "ENCUMBRANCE_UPDATED",
+		EncumbranceID:    enc.EncumbranceID,
+		PropertyID:       enc.PropertyID,
+		InstitutionMspID: enc.Institution.MspID,
+		Changes:          changes,
+		FabricTxID:       ctx.GetStub().GetTxID(),
+		Timestamp:        now,
+		StateCode:        extractStateCode(enc.PropertyID),
+		ChannelID:        ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "ENCUMBRANCE_UPDATED", event)
+}
+
+// ReleaseLapsedCropLoan releases an active crop loan whose season ended
+// before the transaction date and was not renewed. Besides the holding
+// bank, tehsildars and registrars can release a lapsed crop loan, so a
+// charge left behind by the bank does not stay on the land. Emits
+// ENCUMBRANCE_RELEASED.
+func (s *LandRegistryContract) ReleaseLapsedCropLoan(ctx contractapi.TransactionContextInterface, encumbranceID string) error {
+	role, err := requireAnyRole(ctx, "bank", "tehsildar", "registrar", "admin")
+	if err != nil {
+		return err
+	}
+
+	enc, err := findEncumbrance(ctx, encumbranceID)
+	if err != nil {
+		return err
+	}
+	if enc.Type != "CROP_LOAN" {
+		return newError(ErrCodeValidationError, "encumbrance %s is not a crop loan", encumbranceID)
+	}
+	if enc.Status != "ACTIVE" {
+		return newError(ErrCodeEncumbranceNotActive, "encumbrance %s has status %s", encumbranceID, enc.Status)
+	}
+	if role == "bank" {
+		mspID, _ := ctx.GetClientIdentity().GetMSPID()
+		if enc.Institution.MspID != mspID {
+			return newError(ErrCodeAccessDenied, "encumbrance %s is held by %s", encumbranceID, enc.Institution.MspID)
+		}
+	}
+	if !cropLoanLapsed(ctx, enc) {
+		return newError(ErrCodeCropLoanSeasonActive, "crop loan %s runs until %s", encumbranceID, enc.Details.EndDate)
+	}
+	return s.releaseEncumbrance(ctx, enc)
+}
+
+// QueryCropLoansByCultivator returns the crop loans, active and
+// released, taken by a cultivator across all properties. Citizens can
+// query their own.
+func (s *LandRegistryContract) QueryCropLoansByCultivator(ctx contractapi.TransactionContextInterface, cultivatorHash string) ([]*EncumbranceRecord, error) {
+	if err := validateAadhaarHash(cultivatorHash, "cultivatorHash"); err != nil {
+		return nil, err
+	}
+	if err := requireSelfOrRole(ctx, []string{cultivatorHash}, officialRoles...); err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixCropLoanCultivator, []string{cultivatorHash})
+	if err != nil {
+		return nil, internalError("failed to query crop loan index: %v", err)
+	}
+	defer iterator.Close()
+
+	loans := []*EncumbranceRecord{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate crop loan index: %v", err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+		enc, err := getEncumbrance(ctx, string(kv.Value), parts[1])
+		if err != nil {
+			return nil, err
+		}
+		loans = append(loans, enc)
+	}
+	return loans, nil
+}
+
+// validateCropLoan checks the cropLoan details of a CROP_LOAN submitted
+// to AddEncumbrance. The cultivator must own the property or be an
+// active cultivator on it.
+func validateCropLoan(ctx contractapi.TransactionContextInterface, enc *EncumbranceRecord, property *LandRecord) error {
+	if enc.CropLoan == nil {
+		return newError(ErrCodeValidationError, "a CROP_LOAN encumbrance requires cropLoan")
+	}
+	if err := validateAadhaarHash(enc.CropLoan.CultivatorHash, "cropLoan.cultivatorHash"); err != nil {
+		return err
+	}
+	if err := validateCropSeason(enc.CropLoan, enc.Details.EndDate); err != nil {
+		return err
+	}
+	enc.CropLoan.History = nil
+	enc.Details.SanctionedAmount = enc.CropLoan.LimitAmount
+
+	if ownerIndex(property, enc.CropLoan.CultivatorHash) >= 0 {
+		return nil
+	}
+	cultivators, err := getActiveCultivation(ctx, property.PropertyID)
+	if err != nil {
+		return err
+	}
+	for _, cultivation := range cultivators {
+		if cultivation.CultivatorHash == enc.CropLoan.CultivatorHash {
+			return nil
+		}
+	}
+	return newError(ErrCodeValidationError, "cropLoan.cultivatorHash is neither an owner nor a recorded cultivator of %s", property.PropertyID)
+}
+
+// validateCropSeason checks a crop loan's season, crop and limit and
+// its season end date.
+func validateCropSeason(loan *CropLoanDetails, seasonEndDate string) error {
+	loan.Season = strings.ToUpper(loan.Season)
+	if !cropSeasons[loan.Season] {
+		return newError(ErrCodeValidationError, "cropLoan.season '%s' must be KHARIF, RABI or ZAID", loan.Season)
+	}
+	if loan.Year < 1900 {
+		return newError(ErrCodeValidationError, "cropLoan.year is invalid")
+	}
+	if strings.TrimSpace(loan.Crop) == "" {
+		return newError(ErrCodeValidationError, "cropLoan.crop is required")
+	}
+	if loan.LimitAmount <= 0 {
+		return newError(ErrCodeValidationError, "cropLoan.limitAmount must be positive")
+	}
+	if _, err := time.Parse("2006-01-02", seasonEndDate); err != nil {
+		return newError(ErrCodeValidationError, "the season end date (details.endDate) must be YYYY-MM-DD")
+	}
+	return nil
+}
+
+// cropLoanLapsed reports whether a crop loan's season ended before the
+// transaction date.
+func cropLoanLapsed(ctx contractapi.TransactionContextInterface, enc *EncumbranceRecord) bool {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	today := time.Unix(timestamp.Seconds, 0).Format("2006-01-02")
+	return enc.Details.EndDate != "" && enc.Details.EndDate < today
+}
+
+// hasBlockingEncumbrances reports whether a property has an active
+// encumbrance other than a crop loan or easement; neither stops a
+// transfer from being initiated.
+func hasBlockingEncumbrances(ctx contractapi.TransactionContextInterface, propertyID string) (bool, error) {
+	encumbrances, err := getActiveEncumbrances(ctx, propertyID)
+	if err != nil {
+		return false, err
+	}
+	for _, enc := range encumbrances {
+		if enc.Type != "CROP_LOAN" && enc.Type != "EASEMENT" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkCropLoansAcknowledged fails unless a transfer lists every active
+// crop loan on the property in acknowledgedCropLoanIds, recording that
+// the buyer takes the land subject to them.
+func checkCropLoansAcknowledged(transfer *TransferRecord, encumbrances []*EncumbranceRecord) error {
+	acknowledged := map[string]bool{}
+	for _, id := range transfer.AcknowledgedCropLoanIDs {
+		acknowledged[id] = true
+	}
+	var missing []string
+	for _, enc := range encumbrances {
+		if enc.Type == "CROP_LOAN" && !acknowledged[enc.EncumbranceID] {
+			missing = append(missing, enc.EncumbranceID)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return newError(ErrCodeCropLoanNotAcknowledged, "buyer must acknowledge crop loan(s) on %s: %s", transfer.PropertyID, strings.Join(missing, ", ")).
+			with("encumbranceIds", strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// putCropLoanIndex indexes a crop loan under its cultivator:
+// CROPLOAN_CULTIVATOR~{cultivatorHash}~{encumbranceId} -> propertyId.
+func putCropLoanIndex(ctx contractapi.TransactionContextInterface, enc *EncumbranceRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCropLoanCultivator, []string{enc.CropLoan.CultivatorHash, enc.EncumbranceID})
+	if err != nil {
+		return internalError("failed to create crop loan index key: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, []byte(enc.PropertyID)); err != nil {
+		return internalError("failed to write crop loan index: %v", err)
+	}
+	return nil
+}
+
+// findEncumbrance looks an encumbrance up by ID across all properties,
+// with a rich query on docType and encumbranceId.
+func findEncumbrance(ctx contractapi.TransactionContextInterface, encumbranceID string) (*EncumbranceRecord, error) {
+	queryString := fmt.Sprintf(`{"selector":{"docType":"encumbranceRecord","encumbranceId":"%s"}}`, encumbranceID)
+	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return nil, internalError("failed to query encumbrance: %v", err)
+	}
+	defer iterator.Close()
+
+	if !iterator.HasNext() {
+		return nil, newError(ErrCodeEncumbranceNotFound, "%s", encumbranceID)
+	}
+	kv, err := iterator.Next()
+	if err != nil {
+		return nil, internalError("failed to read encumbrance: %v", err)
+	}
+	var enc EncumbranceRecord
+	if err := json.Unmarshal(kv.Value, &enc); err != nil {
+		return nil, internalError("failed to unmarshal encumbrance: %v", err)
+	}
+	return &enc, nil
+}
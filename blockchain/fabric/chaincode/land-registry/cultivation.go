@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// CULTIVATION (RECORD OF RIGHTS)
+// ============================================================
+// The record of rights lists who cultivates a parcel, and under what
+// tenure, separately from who owns it; agricultural schemes key off the
+// cultivator column. Cultivation entries never change ownership. They
+// stay with the parcel through a transfer: the buyer takes the land
+// with its cultivators, and the transfer records that they were
+// disclosed (see carryOverCultivation).
+
+// cropSeasons lists the crop seasons a cultivation entry can name.
+var cropSeasons = map[string]bool{
+	"KHARIF": true,
+	"RABI":   true,
+	"ZAID":   true,
+}
+
+// RecordCultivator records a cultivator of a property. cultivationJSON
+// is a CultivationRecord with propertyId, cultivatorHash,
+// cultivatorName, tenureClass (the state's category, e.g. PATTADAR,
+// BHUMIDHAR, TENANT), seasons (each with season KHARIF, RABI or ZAID,
+// year and crop), startDate and optionally endDate (YYYY-MM-DD). Only
+// tehsildars with jurisdiction over the property can record
+// cultivators. Emits CULTIVATOR_RECORDED.
+func (s *LandRegistryContract) RecordCultivator(ctx contractapi.TransactionContextInterface, cultivationJSON string) (string, error) {
+	if err := requireRole(ctx, "tehsildar"); err != nil {
+		return "", err
+	}
+
+	var cultivation CultivationRecord
+	if err := json.Unmarshal([]byte(cultivationJSON), &cultivation); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse cultivation JSON: %v", err)
+	}
+	if err := validateCultivation(&cultivation); err != nil {
+		return "", err
+	}
+
+	property, err := s.GetProperty(ctx, cultivation.PropertyID)
+	if err != nil {
+		return "", err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return "", err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return "", err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	cultivation.DocType = "cultivationRecord"
+	cultivation.CultivationID = "cul_" + txID[:8]
+	cultivation.Status = "ACTIVE"
+	cultivation.EndReason = ""
+	cultivation.DisclosedInTransfers = nil
+	cultivation.RecordedBy = getCallerID(ctx)
+	cultivation.RecordedAt = now
+	cultivation.FabricTxID = txID
+	if err := putCultivation(ctx, &cultivation); err != nil {
+		return "", err
+	}
+	if err := emitCultivationEvent(ctx, "CULTIVATOR_RECORDED", &cultivation, property.Location.StateCode); err != nil {
+		return "", err
+	}
+	return cultivation.CultivationID, nil
+}
+
+// EndCultivation ends an active cultivation entry on endDate
+// (YYYY-MM-DD), e.g. when the tenancy is surrendered, recording reason.
+// Only tehsildars with jurisdiction over the property can end
+// cultivation. Emits CULTIVATION_ENDED.
+func (s *LandRegistryContract) EndCultivation(ctx contractapi.TransactionContextInterface, propertyID, cultivationID, endDate, reason string) error {
+	if err := requireRole(ctx, "tehsildar"); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+	if _, err := time.Parse("2006-01-02", endDate); err != nil {
+		return newError(ErrCodeValidationError, "endDate must be YYYY-MM-DD")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return err
+	}
+
+	cultivation, err := getCultivation(ctx, propertyID, cultivationID)
+	if err != nil {
+		return err
+	}
+	if cultivation.Status != "ACTIVE" {
+		return newError(ErrCodeCultivationNotActive, "cultivation %s has status %s", cultivationID, cultivation.Status)
+	}
+	if endDate < cultivation.StartDate {
+		return newError(ErrCodeValidationError, "endDate must not be before startDate %s", cultivation.StartDate)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	cultivation.Status = "ENDED"
+	cultivation.EndDate = endDate
+	cultivation.EndReason = reason
+	cultivation.EndedBy = getCallerID(ctx)
+	cultivation.EndedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	cultivation.FabricTxID = ctx.GetStub().GetTxID()
+	if err := putCultivation(ctx, cultivation); err != nil {
+		return err
+	}
+	return emitCultivationEvent(ctx, "CULTIVATION_ENDED", cultivation, property.Location.StateCode)
+}
+
+// GetRecordOfRights returns a property's record of rights: its owners
+// alongside the cultivators currently recorded on it.
+func (s *LandRegistryContract) GetRecordOfRights(ctx contractapi.TransactionContextInterface, propertyID string) (*RecordOfRights, error) {
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	cultivators, err := getActiveCultivation(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	return &RecordOfRights{
+		PropertyID:         property.PropertyID,
+		SurveyNumber:       property.SurveyNumber,
+		SubSurveyNumber:    property.SubSurveyNumber,
+		Location:           property.Location,
+		Area:               property.Area,
+		LandUse:            property.LandUse,
+		LandClassification: property.LandClassification,
+		Owner:              property.CurrentOwner,
+		Cultivators:        cultivators,
+		AsOf:               time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+	}, nil
+}
+
+// validateCultivation checks a cultivation entry submitted to
+// RecordCultivator.
+func validateCultivation(cultivation *CultivationRecord) error {
+	if err := validatePropertyID(cultivation.PropertyID); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(cultivation.CultivatorHash, "cultivatorHash"); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cultivation.CultivatorName) == "" {
+		return newError(ErrCodeValidationError, "cultivatorName is required")
+	}
+	cultivation.TenureClass = strings.ToUpper(strings.TrimSpace(cultivation.TenureClass))
+	if cultivation.TenureClass == "" {
+		return newError(ErrCodeValidationError, "tenureClass is required")
+	}
+	for i := range cultivation.Seasons {
+		season := &cultivation.Seasons[i]
+		season.Season = strings.ToUpper(season.Season)
+		if !cropSeasons[season.Season] {
+			return newError(ErrCodeValidationError, "seasons[%d].season '%s' must be KHARIF, RABI or ZAID", i, season.Season)
+		}
+		if season.Year < 1900 {
+			return newError(ErrCodeValidationError, "seasons[%d].year is invalid", i)
+		}
+		if strings.TrimSpace(season.Crop) == "" {
+			return newError(ErrCodeValidationError, "seasons[%d].crop is required", i)
+		}
+	}
+
+	start, err := time.Parse("2006-01-02", cultivation.StartDate)
+	if err != nil {
+		return newError(ErrCodeValidationError, "startDate must be YYYY-MM-DD")
+	}
+	if cultivation.EndDate != "" {
+		end, err := time.Parse("2006-01-02", cultivation.EndDate)
+		if err != nil {
+			return newError(ErrCodeValidationError, "endDate must be YYYY-MM-DD")
+		}
+		if end.Before(start) {
+			return newError(ErrCodeValidationError, "endDate must not be before startDate")
+		}
+	}
+	return nil
+}
+
+// carryOverCultivation records a transfer against every cultivation
+// entry active on the property and reports whether there were any, so
+// the transfer can show the buyer took the land with its cultivators.
+// The entries themselves are unchanged: cultivation survives a change
+// of owner.
+func carryOverCultivation(ctx contractapi.TransactionContextInterface, transfer *TransferRecord) (bool, error) {
+	active, err := getActiveCultivation(ctx, transfer.PropertyID)
+	if err != nil {
+		return false, err
+	}
+	for _, cultivation := range active {
+		cultivation.DisclosedInTransfers = append(cultivation.DisclosedInTransfers, transfer.TransferID)
+		cultivation.FabricTxID = ctx.GetStub().GetTxID()
+		if err := putCultivation(ctx, cultivation); err != nil {
+			return false, err
+		}
+	}
+	return len(active) > 0, nil
+}
+
+// withdrawCultivationDisclosure removes a rolled-back transfer from the
+// cultivation entries on its property that carried it over, whatever
+// their status now.
+func withdrawCultivationDisclosure(ctx contractapi.TransactionContextInterface, transfer *TransferRecord) error {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixCultivation, []string{transfer.PropertyID})
+	if err != nil {
+		return internalError("failed to query cultivation: %v", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return internalError("failed to iterate cultivation: %v", err)
+		}
+		var cultivation CultivationRecord
+		if err := json.Unmarshal(kv.Value, &cultivation); err != nil {
+			return internalError("failed to unmarshal cultivation: %v", err)
+		}
+		kept := cultivation.DisclosedInTransfers[:0]
+		for _, transferID := range cultivation.DisclosedInTransfers {
+			if transferID != transfer.TransferID {
+				kept = append(kept, transferID)
+			}
+		}
+		if len(kept) == len(cultivation.DisclosedInTransfers) {
+			continue
+		}
+		if len(kept) == 0 {
+			kept = nil
+		}
+		cultivation.DisclosedInTransfers = kept
+		cultivation.FabricTxID = ctx.GetStub().GetTxID()
+		if err := putCultivation(ctx, &cultivation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getActiveCultivation returns the cultivation entries on a property
+// that are ACTIVE and have not reached their end date at the
+// transaction time.
+func getActiveCultivation(ctx contractapi.TransactionContextInterface, propertyID string) ([]*CultivationRecord, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixCultivation, []string{propertyID})
+	if err != nil {
+		return nil, internalError("failed to query cultivation: %v", err)
+	}
+	defer iterator.Close()
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	today := time.Unix(timestamp.Seconds, 0).Format("2006-01-02")
+
+	active := []*CultivationRecord{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate cultivation: %v", err)
+		}
+		var cultivation CultivationRecord
+		if err := json.Unmarshal(kv.Value, &cultivation); err != nil {
+			return nil, internalError("failed to unmarshal cultivation: %v", err)
+		}
+		if cultivation.Status == "ACTIVE" && (cultivation.EndDate == "" || cultivation.EndDate >= today) {
+			active = append(active, &cultivation)
+		}
+	}
+	return active, nil
+}
+
+// getCultivation reads a cultivation entry by property and ID.
+func getCultivation(ctx contractapi.TransactionContextInterface, propertyID, cultivationID string) (*CultivationRecord, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCultivation, []string{propertyID, cultivationID})
+	if err != nil {
+		return nil, internalError("failed to create cultivation key: %v", err)
+	}
+	cultivationBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read cultivation: %v", err)
+	}
+	if cultivationBytes == nil {
+		return nil, newError(ErrCodeCultivationNotFound, "cultivation %s does not exist on %s", cultivationID, propertyID)
+	}
+	var cultivation CultivationRecord
+	if err := json.Unmarshal(cultivationBytes, &cultivation); err != nil {
+		return nil, internalError("failed to unmarshal cultivation: %v", err)
+	}
+	return &cultivation, nil
+}
+
+// putCultivation writes a cultivation entry under its composite key.
+func putCultivation(ctx contractapi.TransactionContextInterface, cultivation *CultivationRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixCultivation, []string{cultivation.PropertyID, cultivation.CultivationID})
+	if err != nil {
+		return internalError("failed to create cultivation key: %v", err)
+	}
+	cultivationBytes, err := canonicalMarshal(cultivation)
+	if err != nil {
+		return internalError("failed to marshal cultivation: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, cultivationBytes); err != nil {
+		return internalError("failed to write cultivation %s: %v", cultivation.CultivationID, err)
+	}
+	return nil
+}
+
+func emitCultivationEvent(ctx contractapi.TransactionContextInterface, eventType string, cultivation *CultivationRecord, stateCode string) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := CultivationEvent{
+		Type:           eventType,
+		CultivationID:  cultivation.CultivationID,
+		PropertyID:     cultivation.PropertyID,
+		CultivatorHash: cultivation.CultivatorHash,
+		TenureClass:    cultivation.TenureClass,
+		Seasons:        cultivation.Seasons,
+		StartDate:      cultivation.StartDate,
+		EndDate:        cultivation.EndDate,
+		Reason:         cultivation.EndReason,
+		FabricTxID:     ctx.GetStub().GetTxID(),
+		Timestamp:      time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:      stateCode,
+		ChannelID:      ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, eventType, event)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// DELEGATION OF CHARGE
+// ============================================================
+// When an officer is on leave another is put in charge for a fixed
+// period. Rather than sharing certificates, an admin records a
+// delegation naming the delegate's enrollment; role and jurisdiction
+// checks then accept the delegate until it expires or is revoked.
+
+// GrantDelegation records a time-bound delegation of a role.
+// delegationJSON is a Delegation with delegatorId, delegateMspId,
+// delegateEnrollmentId, role, stateCode (and optionally districtCode and
+// tehsilCode) and expiresAt (RFC 3339, in the future); audit fields are
+// set here. The admin role cannot be delegated. Only admins in the
+// delegation's state can grant. Returns the delegation ID. Emits
+// DELEGATION_GRANTED.
+func (s *LandRegistryContract) GrantDelegation(ctx contractapi.TransactionContextInterface, delegationJSON string) (string, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return "", err
+	}
+
+	var delegation Delegation
+	if err := json.Unmarshal([]byte(delegationJSON), &delegation); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse delegation JSON: %v", err)
+	}
+	if delegation.DelegatorID == "" {
+		return "", newError(ErrCodeValidationError, "delegatorId is required")
+	}
+	if delegation.DelegateMspID == "" || delegation.DelegateEnrollmentID == "" {
+		return "", newError(ErrCodeValidationError, "delegateMspId and delegateEnrollmentId are required")
+	}
+	delegable := false
+	for _, role := range officialRoles {
+		if role == delegation.Role && role != "admin" {
+			delegable = true
+		}
+	}
+	if !delegable {
+		return "", newError(ErrCodeValidationError, "role '%s' cannot be delegated", delegation.Role)
+	}
+	if delegation.StateCode == "" {
+		return "", newError(ErrCodeValidationError, "stateCode is required")
+	}
+	if delegation.TehsilCode != "" && delegation.DistrictCode == "" {
+		return "", newError(ErrCodeValidationError, "districtCode is required with tehsilCode")
+	}
+	if err := requireStateAccess(ctx, delegation.StateCode); err != nil {
+		return "", err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0)
+	now := txTime.Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	expiresAt, err := time.Parse(time.RFC3339, delegation.ExpiresAt)
+	if err != nil {
+		return "", newError(ErrCodeValidationError, "expiresAt must be an RFC 3339 timestamp")
+	}
+	if !expiresAt.After(txTime) {
+		return "", newError(ErrCodeValidationError, "expiresAt %s is not in the future", delegation.ExpiresAt)
+	}
+
+	delegation.DocType = "delegation"
+	delegation.DelegationID = "dlg_" + txID[:8]
+	delegation.Status = "ACTIVE"
+	delegation.GrantedBy = getCallerID(ctx)
+	delegation.GrantedAt = now
+	delegation.RevokedBy = ""
+	delegation.RevokedAt = ""
+	delegation.RevokeReason = ""
+	delegation.FabricTxID = txID
+
+	if err := putDelegation(ctx, &delegation); err != nil {
+		return "", err
+	}
+	if err := recordAudit(ctx, "GrantDelegation", delegation.DelegationID); err != nil {
+		return "", err
+	}
+	if err := emitDelegationEvent(ctx, "DELEGATION_GRANTED", &delegation, now); err != nil {
+		return "", err
+	}
+	return delegation.DelegationID, nil
+}
+
+// RevokeDelegation ends a delegation before it expires. Only admins in
+// the delegation's state can revoke. Emits DELEGATION_REVOKED.
+func (s *LandRegistryContract) RevokeDelegation(ctx contractapi.TransactionContextInterface, delegationID, reason string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required to revoke a delegation")
+	}
+
+	queryString := fmt.Sprintf(`{"selector":{"docType":"delegation","delegationId":"%s"}}`, delegationID)
+	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return internalError("failed to query delegation: %v", err)
+	}
+	defer iterator.Close()
+	if !iterator.HasNext() {
+		return newError(ErrCodeDelegationNotFound, "%s", delegationID)
+	}
+	kv, err := iterator.Next()
+	if err != nil {
+		return internalError("failed to read delegation: %v", err)
+	}
+	var delegation Delegation
+	if err := json.Unmarshal(kv.Value, &delegation); err != nil {
+		return internalError("failed to unmarshal delegation: %v", err)
+	}
+
+	if err := requireStateAccess(ctx, delegation.StateCode); err != nil {
+		return err
+	}
+	if delegation.Status != "ACTIVE" {
+		return newError(ErrCodeDelegationInvalidState, "%s has status %s", delegationID, delegation.Status)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	delegation.Status = "REVOKED"
+	delegation.RevokedBy = getCallerID(ctx)
+	delegation.RevokedAt = now
+	delegation.RevokeReason = reason
+	delegation.FabricTxID = ctx.GetStub().GetTxID()
+
+	if err := putDelegation(ctx, &delegation); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, "RevokeDelegation", delegationID); err != nil {
+		return err
+	}
+	return emitDelegationEvent(ctx, "DELEGATION_REVOKED", &delegation, now)
+}
+
+// activeDelegations returns the caller's delegations that are ACTIVE,
+// unexpired at the transaction timestamp and within the state on the
+// caller's own certificate. Callers without an enrollment ID attribute
+// have none.
+func activeDelegations(ctx contractapi.TransactionContextInterface) ([]*Delegation, error) {
+	clientIdentity := ctx.GetClientIdentity()
+	enrollmentID, found, err := clientIdentity.GetAttributeValue("hf.EnrollmentID")
+	if err != nil || !found || enrollmentID == "" {
+		return nil, nil
+	}
+	mspID, err := clientIdentity.GetMSPID()
+	if err != nil {
+		return nil, nil
+	}
+	callerState, _, _ := clientIdentity.GetAttributeValue("stateCode")
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixDelegation, []string{mspID, enrollmentID})
+	if err != nil {
+		return nil, internalError("failed to query delegations: %v", err)
+	}
+	defer iterator.Close()
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0)
+
+	var active []*Delegation
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate delegations: %v", err)
+		}
+		var delegation Delegation
+		if err := json.Unmarshal(kv.Value, &delegation); err != nil {
+			return nil, internalError("failed to unmarshal delegation: %v", err)
+		}
+		if delegation.Status != "ACTIVE" || delegation.StateCode != callerState {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, delegation.ExpiresAt)
+		if err != nil || !txTime.Before(expiresAt) {
+			continue
+		}
+		active = append(active, &delegation)
+	}
+	return active, nil
+}
+
+// delegatedRole reports whether the caller holds an active delegation
+// of role.
+func delegatedRole(ctx contractapi.TransactionContextInterface, role string) (bool, error) {
+	delegations, err := activeDelegations(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, delegation := range delegations {
+		if delegation.Role == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// delegatedJurisdiction reports whether one of the caller's active
+// delegations covers location.
+func delegatedJurisdiction(ctx contractapi.TransactionContextInterface, location Location) (bool, error) {
+	delegations, err := activeDelegations(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, delegation := range delegations {
+		if delegation.StateCode != location.StateCode {
+			continue
+		}
+		if delegation.DistrictCode != "" && delegation.DistrictCode != location.DistrictCode {
+			continue
+		}
+		if delegation.TehsilCode != "" && delegation.TehsilCode != location.TehsilCode {
+			continue
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// putDelegation writes a delegation under its delegate's key.
+func putDelegation(ctx contractapi.TransactionContextInterface, delegation *Delegation) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixDelegation, []string{delegation.DelegateMspID, delegation.DelegateEnrollmentID, delegation.DelegationID})
+	if err != nil {
+		return internalError("failed to create delegation key: %v", err)
+	}
+	delegationBytes, err := canonicalMarshal(delegation)
+	if err != nil {
+		return internalError("failed to marshal delegation: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, delegationBytes); err != nil {
+		return internalError("failed to write delegation: %v", err)
+	}
+	return nil
+}
+
+// emitDelegationEvent emits DELEGATION_GRANTED or DELEGATION_REVOKED.
+func emitDelegationEvent(ctx contractapi.TransactionContextInterface, eventType string, delegation *Delegation, now string) error {
+	event := DelegationEvent{
+		Type:                 eventType,
+		DelegationID:         delegation.DelegationID,
+		DelegatorID:          delegation.DelegatorID,
+		DelegateMspID:        delegation.DelegateMspID,
+		DelegateEnrollmentID: delegation.DelegateEnrollmentID,
+		Role:                 delegation.Role,
+		ExpiresAt:            delegation.ExpiresAt,
+		Reason:               delegation.RevokeReason,
+		FabricTxID:           delegation.FabricTxID,
+		Timestamp:            now,
+		StateCode:            delegation.StateCode,
+		ChannelID:            ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, eventType, event)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// IDENTITY DENY LIST
+// ============================================================
+// Revoking a certificate only takes effect once the CRL reaches every
+// peer. When a key is stolen an admin blocks the identity here instead,
+// which applies from the next transaction: requireRole and its siblings
+// look the caller's certificate fingerprint and enrollment ID up directly
+// before any other check.
+//
+// The chaincode cannot tell from an identifier alone whether it names an
+// admin, so an entry binds admins only once a second admin has confirmed
+// it by calling BlockIdentity again. Entries bind every other caller
+// immediately.
+
+// BlockIdentity adds an identity to the deny list. identifier is the
+// hex SHA-256 fingerprint of the identity's certificate or its Fabric CA
+// enrollment ID. A second call by a different admin on a blocked
+// identifier confirms the entry, making it binding on admin identities
+// too. Only admins can block, and not themselves. Emits IDENTITY_BLOCKED.
+func (s *LandRegistryContract) BlockIdentity(ctx contractapi.TransactionContextInterface, identifier, reason string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+	if identifier == "" {
+		return newError(ErrCodeValidationError, "identifier is required")
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required to block an identity")
+	}
+
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+	if identifier == fingerprint || identifier == callerEnrollmentID(ctx) {
+		return newError(ErrCodeValidationError, "an admin cannot block their own identity")
+	}
+
+	entry, err := getDenyListEntry(ctx, identifier)
+	if err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	if entry != nil && entry.Status == "BLOCKED" {
+		if entry.ConfirmedBy != "" {
+			return newError(ErrCodeDenylistInvalidState, "%s is already blocked and confirmed", identifier)
+		}
+		if entry.BlockedByFingerprint == fingerprint {
+			return newError(ErrCodeSelfConfirmationDenied, "the admin who blocked %s cannot also confirm it", identifier)
+		}
+		entry.ConfirmedBy = getCallerID(ctx)
+		entry.ConfirmedAt = now
+		entry.ConfirmReason = reason
+	} else {
+		entry = &DenyListEntry{
+			DocType:              "denyListEntry",
+			Identifier:           identifier,
+			Status:               "BLOCKED",
+			Reason:               reason,
+			BlockedBy:            getCallerID(ctx),
+			BlockedByFingerprint: fingerprint,
+			BlockedAt:            now,
+		}
+	}
+	entry.FabricTxID = ctx.GetStub().GetTxID()
+
+	if err := putDenyListEntry(ctx, entry); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, "BlockIdentity", identifier); err != nil {
+		return err
+	}
+	return emitIdentityBlockEvent(ctx, "IDENTITY_BLOCKED", entry, reason, now)
+}
+
+// UnblockIdentity lifts a deny list entry, e.g. once the identity's
+// certificate has been revoked and reissued. Only admins can unblock.
+// Emits IDENTITY_UNBLOCKED.
+func (s *LandRegistryContract) UnblockIdentity(ctx contractapi.TransactionContextInterface, identifier, reason string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required to unblock an identity")
+	}
+
+	entry, err := getDenyListEntry(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return newError(ErrCodeDenylistEntryNotFound, "%s", identifier)
+	}
+	if entry.Status != "BLOCKED" {
+		return newError(ErrCodeDenylistInvalidState, "%s has status %s", identifier, entry.Status)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	entry.Status = "UNBLOCKED"
+	entry.UnblockedBy = getCallerID(ctx)
+	entry.UnblockedAt = now
+	entry.UnblockReason = reason
+	entry.FabricTxID = ctx.GetStub().GetTxID()
+
+	if err := putDenyListEntry(ctx, entry); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, "UnblockIdentity", identifier); err != nil {
+		return err
+	}
+	return emitIdentityBlockEvent(ctx, "IDENTITY_UNBLOCKED", entry, reason, now)
+}
+
+// checkDenyList refuses a caller whose certificate fingerprint or
+// enrollment ID is blocked. Admin callers are refused only by confirmed
+// entries.
+func checkDenyList(ctx contractapi.TransactionContextInterface) error {
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+	role, _, _ := ctx.GetClientIdentity().GetAttributeValue("role")
+
+	for _, identifier := range []string{fingerprint, callerEnrollmentID(ctx)} {
+		if identifier == "" {
+			continue
+		}
+		entry, err := getDenyListEntry(ctx, identifier)
+		if err != nil {
+			return err
+		}
+		if entry == nil || entry.Status != "BLOCKED" {
+			continue
+		}
+		if role == "admin" && entry.ConfirmedBy == "" {
+			continue
+		}
+		return newError(ErrCodeIdentityBlocked, "caller identity %s was blocked at %s", identifier, entry.BlockedAt)
+	}
+	return nil
+}
+
+// getDenyListEntry loads the deny list entry for identifier, or nil if
+// there is none.
+func getDenyListEntry(ctx contractapi.TransactionContextInterface, identifier string) (*DenyListEntry, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixDenyList, []string{identifier})
+	if err != nil {
+		return nil, internalError("failed to create deny list key: %v", err)
+	}
+	entryBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read deny list: %v", err)
+	}
+	if entryBytes == nil {
+		return nil, nil
+	}
+	var entry DenyListEntry
+	if err := json.Unmarshal(entryBytes, &entry); err != nil {
+		return nil, internalError("failed to unmarshal deny list entry: %v", err)
+	}
+	return &entry, nil
+}
+
+// putDenyListEntry writes a deny list entry under its identifier.
+func putDenyListEntry(ctx contractapi.TransactionContextInterface, entry *DenyListEntry) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixDenyList, []string{entry.Identifier})
+	if err != nil {
+		return internalError("failed to create deny list key: %v", err)
+	}
+	entryBytes, err := canonicalMarshal(entry)
+	if err != nil {
+		return internalError("failed to marshal deny list entry: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, entryBytes); err != nil {
+		return internalError("failed to write deny list entry: %v", err)
+	}
+	return nil
+}
+
+// emitIdentityBlockEvent emits IDENTITY_BLOCKED or IDENTITY_UNBLOCKED.
+func emitIdentityBlockEvent(ctx contractapi.TransactionContextInterface, eventType string, entry *DenyListEntry, reason, now string) error {
+	stateCode, _, _ := ctx.GetClientIdentity().GetAttributeValue("stateCode")
+	event := IdentityBlockEvent{
+		Type:       eventType,
+		Identifier: entry.Identifier,
+		Reason:     reason,
+		Confirmed:  entry.ConfirmedBy != "",
+		ActorID:    getCallerID(ctx),
+		FabricTxID: entry.FabricTxID,
+		Timestamp:  now,
+		StateCode:  stateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, eventType, event)
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// DISASTER-AFFECTED PARCELS
+// ============================================================
+// After a notified disaster (flood, earthquake, cyclone) the district
+// marks affected parcels, which drives compensation and rebuilding
+// permits. While a parcel is flagged, the checks a state lists in
+// RegistrySettings.DisasterRelaxations accept a disaster certificate in
+// place of documents lost in the disaster.
+
+// maxDisasterBatch caps MarkDisasterAffected and ClearDisasterStatus
+// batches.
+const maxDisasterBatch = 500
+
+// maxDisasterPageSize caps QueryDisasterAffected pages.
+const maxDisasterPageSize = 1000
+
+// disasterSeverities lists the damage grades of a DisasterStatus.
+var disasterSeverities = map[string]bool{
+	"MINOR":     true,
+	"MODERATE":  true,
+	"SEVERE":    true,
+	"DESTROYED": true,
+}
+
+// disasterStages lists the stages of a DisasterStatus.
+var disasterStages = map[string]bool{
+	"AFFECTED":       true,
+	"REHABILITATION": true,
+}
+
+// disasterRelaxableChecks lists the checks a state can relax for
+// disaster-affected parcels, and what accepts the disaster certificate.
+var disasterRelaxableChecks = map[string]string{
+	// requireTaxClearance: a transfer's disasterCertificateRef stands in
+	// for dues paid or a dues-clearance document
+	"TAX_CLEARANCE": "transfer.disasterCertificateRef",
+}
+
+// MarkDisasterAffected flags up to 500 properties of one state as
+// affected by a notified disaster. propertyIDsJSON is a JSON array of
+// property IDs; eventJSON is a DisasterStatus giving the notified
+// eventRef, a severity (MINOR, MODERATE, SEVERE or DESTROYED) and
+// optionally the stage (AFFECTED, the default, or REHABILITATION) and a
+// description. Marking an already flagged property replaces its status.
+// Only admins with access to the state can mark properties. Emits one
+// DISASTER_STATUS_SET event listing every property.
+func (s *LandRegistryContract) MarkDisasterAffected(ctx contractapi.TransactionContextInterface, propertyIDsJSON, eventJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+	propertyIDs, err := parseDisasterBatch(propertyIDsJSON)
+	if err != nil {
+		return err
+	}
+
+	var status DisasterStatus
+	if err := json.Unmarshal([]byte(eventJSON), &status); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse disaster event JSON: %v", err)
+	}
+	status.EventRef = strings.TrimSpace(status.EventRef)
+	if status.EventRef == "" {
+		return newError(ErrCodeValidationError, "eventRef is required")
+	}
+	status.Severity = strings.ToUpper(status.Severity)
+	if !disasterSeverities[status.Severity] {
+		return newError(ErrCodeValidationError, "severity '%s' must be MINOR, MODERATE, SEVERE or DESTROYED", status.Severity)
+	}
+	status.Stage = strings.ToUpper(status.Stage)
+	if status.Stage == "" {
+		status.Stage = "AFFECTED"
+	}
+	if !disasterStages[status.Stage] {
+		return newError(ErrCodeValidationError, "stage '%s' must be AFFECTED or REHABILITATION", status.Stage)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+	status.MarkedBy = getCallerID(ctx)
+	status.MarkedAt = now
+	status.FabricTxID = txID
+
+	properties, err := s.readDisasterBatch(ctx, propertyIDs)
+	if err != nil {
+		return err
+	}
+	for i, property := range properties {
+		if err := requireNotArchived(property); err != nil {
+			return errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+		if property.DisasterStatus != nil {
+			if err := deleteDisasterIndex(ctx, property); err != nil {
+				return errorAt(fmt.Sprintf("property[%d]", i), err)
+			}
+		}
+		marked := status
+		property.DisasterStatus = &marked
+		property.UpdatedAt = now
+		property.UpdatedBy = status.MarkedBy
+		property.FabricTxID = txID
+		if err := putLandRecord(ctx, property); err != nil {
+			return errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+		if err := putDisasterIndex(ctx, property); err != nil {
+			return errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+	}
+
+	// Fabric keeps only one chaincode event per transaction, so the
+	// batch is announced in a single event
+	event := DisasterStatusEvent{
+		Type:        "DISASTER_STATUS_SET",
+		EventRef:    status.EventRef,
+		Severity:    status.Severity,
+		Stage:       status.Stage,
+		PropertyIDs: propertyIDs,
+		Count:       len(propertyIDs),
+		FabricTxID:  txID,
+		Timestamp:   now,
+		StateCode:   properties[0].Location.StateCode,
+		ChannelID:   ctx.GetStub().GetChannelID(),
+	}
+	if err := recordAudit(ctx, "MarkDisasterAffected", fmt.Sprintf("%s %d properties", status.EventRef, len(propertyIDs))); err != nil {
+		return err
+	}
+	return emitEvent(ctx, "DISASTER_STATUS_SET", event)
+}
+
+// ClearDisasterStatus removes the disaster flag from up to 500
+// properties of one state, once relief and rebuilding are complete.
+// propertyIDsJSON is a JSON array of property IDs; each must be flagged.
+// Only admins with access to the state can clear the flag. Emits one
+// DISASTER_STATUS_CLEARED event listing every property.
+func (s *LandRegistryContract) ClearDisasterStatus(ctx contractapi.TransactionContextInterface, propertyIDsJSON, reason string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+	if strings.TrimSpace(reason) == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+	propertyIDs, err := parseDisasterBatch(propertyIDsJSON)
+	if err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	properties, err := s.readDisasterBatch(ctx, propertyIDs)
+	if err != nil {
+		return err
+	}
+	for i, property := range properties {
+		if property.DisasterStatus == nil {
+			return errorAt(fmt.Sprintf("property[%d]", i), newError(ErrCodeDisasterStatusNotSet, "%s is not flagged as disaster-affected", property.PropertyID))
+		}
+		if err := deleteDisasterIndex(ctx, property); err != nil {
+			return errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+		property.DisasterStatus = nil
+		property.UpdatedAt = now
+		property.UpdatedBy = getCallerID(ctx)
+		property.FabricTxID = txID
+		if err := putLandRecord(ctx, property); err != nil {
+			return errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+	}
+
+	event := DisasterStatusEvent{
+		Type:        "DISASTER_STATUS_CLEARED",
+		PropertyIDs: propertyIDs,
+		Count:       len(propertyIDs),
+		Reason:      reason,
+		FabricTxID:  txID,
+		Timestamp:   now,
+		StateCode:   properties[0].Location.StateCode,
+		ChannelID:   ctx.GetStub().GetChannelID(),
+	}
+	if err := recordAudit(ctx, "ClearDisasterStatus", fmt.Sprintf("%d properties", len(propertyIDs))); err != nil {
+		return err
+	}
+	return emitEvent(ctx, "DISASTER_STATUS_CLEARED", event)
+}
+
+// QueryDisasterAffected returns a page of the flagged properties in a
+// district, pageSize at a time, optionally only those of one notified
+// eventRef. Pass an empty bookmark for the first page and the returned
+// bookmark for the next. Only tehsildars, registrars and admins with
+// jurisdiction over the district can query it.
+func (s *LandRegistryContract) QueryDisasterAffected(ctx contractapi.TransactionContextInterface, stateCode, districtCode, eventRef string, pageSize int, bookmark string) (*DisasterAffectedPage, error) {
+	if _, err := requireAnyRole(ctx, "tehsildar", "registrar", "admin"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" || districtCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode and districtCode are required")
+	}
+	if pageSize <= 0 || pageSize > maxDisasterPageSize {
+		return nil, newError(ErrCodeValidationError, "pageSize must be between 1 and %d", maxDisasterPageSize)
+	}
+	if err := requireJurisdiction(ctx, Location{StateCode: stateCode, DistrictCode: districtCode}); err != nil {
+		return nil, err
+	}
+
+	attributes := []string{stateCode, districtCode}
+	if eventRef != "" {
+		attributes = append(attributes, eventRef)
+	}
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(KeyPrefixDisaster, attributes, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, internalError("failed to query disaster index: %v", err)
+	}
+	defer iterator.Close()
+
+	page := &DisasterAffectedPage{Properties: []*LandRecord{}}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate disaster index: %v", err)
+		}
+		property, err := readLandRecord(ctx, string(kv.Value))
+		if err != nil {
+			return nil, err
+		}
+		page.Properties = append(page.Properties, property)
+	}
+	page.Count = int32(len(page.Properties))
+	if int(metadata.FetchedRecordsCount) == pageSize {
+		page.Bookmark = metadata.Bookmark
+	}
+	return page, nil
+}
+
+// disasterRelaxed reports whether check accepts a disaster certificate
+// for a property: the property is flagged and its state lists check in
+// DisasterRelaxations.
+func disasterRelaxed(settings *RegistrySettings, property *LandRecord, check string) bool {
+	if property.DisasterStatus == nil {
+		return false
+	}
+	for _, relaxed := range settings.DisasterRelaxations {
+		if relaxed == check {
+			return true
+		}
+	}
+	return false
+}
+
+// inheritedDisasterStatus returns a copy of the disaster status of the
+// first flagged parcel among sources, for a parcel split or merged from
+// them, or nil if none is flagged.
+func inheritedDisasterStatus(sources []*LandRecord) *DisasterStatus {
+	for _, source := range sources {
+		if source.DisasterStatus != nil {
+			status := *source.DisasterStatus
+			return &status
+		}
+	}
+	return nil
+}
+
+// parseDisasterBatch parses and checks the property IDs of a disaster
+// flag batch.
+func parseDisasterBatch(propertyIDsJSON string) ([]string, error) {
+	var propertyIDs []string
+	if err := json.Unmarshal([]byte(propertyIDsJSON), &propertyIDs); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse property IDs: %v", err)
+	}
+	if len(propertyIDs) == 0 {
+		return nil, newError(ErrCodeValidationError, "empty property IDs array")
+	}
+	if len(propertyIDs) > maxDisasterBatch {
+		return nil, newError(ErrCodeValidationError, "disaster status limited to %d properties per transaction", maxDisasterBatch)
+	}
+	seen := make(map[string]bool, len(propertyIDs))
+	for i, propertyID := range propertyIDs {
+		if err := validatePropertyID(propertyID); err != nil {
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+		if seen[propertyID] {
+			return nil, newError(ErrCodeValidationError, "property[%d]: %s is listed twice", i, propertyID)
+		}
+		seen[propertyID] = true
+	}
+	return propertyIDs, nil
+}
+
+// readDisasterBatch reads the properties of a disaster flag batch,
+// which must all be in one state the caller has access to.
+func (s *LandRegistryContract) readDisasterBatch(ctx contractapi.TransactionContextInterface, propertyIDs []string) ([]*LandRecord, error) {
+	properties := make([]*LandRecord, 0, len(propertyIDs))
+	for i, propertyID := range propertyIDs {
+		property, err := s.GetProperty(ctx, propertyID)
+		if err != nil {
+			return nil, errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+		if i == 0 {
+			if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+				return nil, err
+			}
+		} else if property.Location.StateCode != properties[0].Location.StateCode {
+			return nil, newError(ErrCodeValidationError, "property[%d]: all properties must be in state %s", i, properties[0].Location.StateCode)
+		}
+		properties = append(properties, property)
+	}
+	return properties, nil
+}
+
+// createDisasterIndexKey returns a flagged property's key in the
+// disaster index.
+func createDisasterIndexKey(ctx contractapi.TransactionContextInterface, property *LandRecord) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixDisaster, []string{
+		property.Location.StateCode, property.Location.DistrictCode, property.DisasterStatus.EventRef, property.PropertyID,
+	})
+	if err != nil {
+		return "", internalError("failed to create disaster index key: %v", err)
+	}
+	return key, nil
+}
+
+// putDisasterIndex adds a flagged property to the disaster index.
+func putDisasterIndex(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	key, err := createDisasterIndexKey(ctx, property)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, []byte(property.PropertyID)); err != nil {
+		return internalError("failed to write disaster index: %v", err)
+	}
+	return nil
+}
+
+// deleteDisasterIndex removes a flagged property from the disaster
+// index.
+func deleteDisasterIndex(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	key, err := createDisasterIndexKey(ctx, property)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return internalError("failed to remove disaster index: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// PROPERTY DOCUMENTS
+// ============================================================
+
+// propertyDocumentTypes is the controlled vocabulary for documents
+// attached to a property after registration.
+var propertyDocumentTypes = map[string]bool{
+	"NA_CONVERSION_ORDER":     true,
+	"BUILDING_APPROVAL":       true,
+	"SURVEY_SKETCH":           true,
+	"MUTATION_ORDER":          true,
+	"COURT_ORDER":             true,
+	"TAX_RECEIPT":             true,
+	"ENCUMBRANCE_CERTIFICATE": true,
+	"OCCUPANCY_CERTIFICATE":   true,
+	"PARTITION_DEED":          true,
+	"GIFT_DEED":               true,
+	"RELEASE_DEED":            true,
+	"OTHER":                   true,
+}
+
+// AddPropertyDocument attaches a supporting document to a property.
+// docJSON is a PropertyDocument; docType must be from the controlled
+// vocabulary and hash an IPFS CID or SHA-256 digest. Documents are
+// append-only: setting "supersedes" to an existing documentId marks that
+// document as superseded by the new one instead of removing it.
+// Only registrars and tehsildars in the property's state can add
+// documents. Emits PROPERTY_DOCUMENT_ADDED.
+func (s *LandRegistryContract) AddPropertyDocument(ctx contractapi.TransactionContextInterface, propertyID, docJSON string) error {
+	if _, err := requireAnyRole(ctx, "registrar", "tehsildar"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+
+	var doc PropertyDocument
+	if err := json.Unmarshal([]byte(docJSON), &doc); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse document JSON: %v", err)
+	}
+	if !propertyDocumentTypes[doc.DocType] {
+		return newError(ErrCodeValidationError, "unknown docType '%s'", doc.DocType)
+	}
+	if err := validateDocumentHash(doc.Hash, "hash"); err != nil {
+		return err
+	}
+	if doc.IssuedBy == "" {
+		return newError(ErrCodeValidationError, "issuedBy is required")
+	}
+	if _, err := time.Parse("2006-01-02", doc.IssuedDate); err != nil {
+		return newError(ErrCodeValidationError, "issuedDate must be YYYY-MM-DD")
+	}
+	if doc.DocType == "OTHER" && doc.Description == "" {
+		return newError(ErrCodeValidationError, "description is required for docType OTHER")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+
+	if property.Status == "SPLIT" || property.Status == "MERGED" {
+		return newError(ErrCodePropertyNotActive, "cannot add documents to property with status %s", property.Status)
+	}
+
+	for _, existing := range property.Documents {
+		if existing.Hash == doc.Hash {
+			return newError(ErrCodeDocumentExists, "document %s already attached as %s", doc.Hash, existing.DocumentID)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	doc.DocumentID = "doc_" + txID[:8]
+	doc.SupersededBy = ""
+	doc.AddedBy = getCallerID(ctx)
+	doc.AddedAt = now
+	doc.FabricTxID = txID
+
+	if doc.Supersedes != "" {
+		found := false
+		for i := range property.Documents {
+			if property.Documents[i].DocumentID != doc.Supersedes {
+				continue
+			}
+			if property.Documents[i].SupersededBy != "" {
+				return newError(ErrCodeDocumentSuperseded, "document %s was already superseded by %s", doc.Supersedes, property.Documents[i].SupersededBy)
+			}
+			property.Documents[i].SupersededBy = doc.DocumentID
+			found = true
+			break
+		}
+		if !found {
+			return newError(ErrCodeDocumentNotFound, "%s is not attached to property %s", doc.Supersedes, propertyID)
+		}
+	}
+
+	property.Documents = append(property.Documents, doc)
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+
+	event := PropertyDocumentAddedEvent{
+		Type:       "PROPERTY_DOCUMENT_ADDED",
+		PropertyID: propertyID,
+		DocumentID: doc.DocumentID,
+		DocType:    doc.DocType,
+		Hash:       doc.Hash,
+		Supersedes: doc.Supersedes,
+		FabricTxID: txID,
+		Timestamp:  now,
+		StateCode:  property.Location.StateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "PROPERTY_DOCUMENT_ADDED", event)
+}
+
+// GetPropertyDocuments returns every document attached to a property,
+// including superseded ones, in the order they were added.
+func (s *LandRegistryContract) GetPropertyDocuments(ctx contractapi.TransactionContextInterface, propertyID string) ([]PropertyDocument, error) {
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if property.Documents == nil {
+		return []PropertyDocument{}, nil
+	}
+	return property.Documents, nil
+}
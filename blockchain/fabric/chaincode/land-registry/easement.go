@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// EASEMENTS AND RIGHTS OF WAY
+// ============================================================
+// An easement burdens one parcel (the servient parcel, where it is
+// recorded as an encumbrance of type EASEMENT) for the benefit of
+// another (the dominant parcel), e.g. a right of way across a field to
+// a landlocked plot. It runs with the land: it does not block a
+// transfer, and a split or merge carries it without the holder's
+// consent. On a split it goes to the children its strip crosses, and
+// every carried easement is flagged needsConfirmation until a registrar
+// has checked it against the new plots (see ConfirmEasement).
+
+// easementPurposes lists the accepted easement purposes.
+var easementPurposes = map[string]bool{
+	"RIGHT_OF_WAY":  true,
+	"DRAINAGE":      true,
+	"WATER_CHANNEL": true,
+	"UTILITY_LINE":  true,
+	"LIGHT_AND_AIR": true,
+	"OTHER":         true,
+}
+
+// AddEasement records an easement over a servient parcel for a dominant
+// parcel. easementJSON is an EasementRequest with propertyId (the
+// servient parcel), dominantPropertyId, purpose, extent (the width or
+// extent as described in the deed), the consent document hashes of
+// both parcels' owners and an optional GeoJSON strip, which must lie
+// over the servient parcel where that has a drawn boundary. Only
+// registrars with jurisdiction over the servient parcel can record
+// easements. Emits ENCUMBRANCE_ADDED.
+func (s *LandRegistryContract) AddEasement(ctx contractapi.TransactionContextInterface, easementJSON string) (*Receipt, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, err
+	}
+
+	var req EasementRequest
+	if err := json.Unmarshal([]byte(easementJSON), &req); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse easement JSON: %v", err)
+	}
+	if err := validatePropertyID(req.DominantPropertyID); err != nil {
+		return nil, errorAt("dominantPropertyId", err)
+	}
+	if req.DominantPropertyID == req.PropertyID {
+		return nil, newError(ErrCodeValidationError, "an easement cannot benefit the parcel it burdens")
+	}
+	req.Purpose = strings.ToUpper(strings.TrimSpace(req.Purpose))
+	if !easementPurposes[req.Purpose] {
+		return nil, newError(ErrCodeValidationError, "purpose '%s' is not a recognised easement purpose", req.Purpose)
+	}
+	req.Extent = strings.TrimSpace(req.Extent)
+	if req.Extent == "" {
+		return nil, newError(ErrCodeValidationError, "extent is required")
+	}
+	if err := validateDocumentHash(req.ServientConsentHash, "servientConsentHash"); err != nil {
+		return nil, err
+	}
+	if err := validateDocumentHash(req.DominantConsentHash, "dominantConsentHash"); err != nil {
+		return nil, err
+	}
+
+	property, err := s.GetProperty(ctx, req.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return nil, err
+	}
+	if req.RequestID != "" {
+		if prior, err := findRequest(ctx, req.RequestID, "AddEasement", easementJSON); err != nil || prior != nil {
+			return prior, err
+		}
+	}
+	if property.Status == "FROZEN" {
+		return nil, newError(ErrCodeLandFrozen, "cannot add easement to frozen property %s", req.PropertyID)
+	}
+	if err := requireNotArchived(property); err != nil {
+		return nil, err
+	}
+	dominant, err := readLandRecord(ctx, req.DominantPropertyID)
+	if err != nil {
+		return nil, errorAt("dominantPropertyId", err)
+	}
+	if err := requireNotArchived(dominant); err != nil {
+		return nil, errorAt("dominantPropertyId", err)
+	}
+	if req.Strip != nil {
+		if err := validateStrip(*req.Strip, property); err != nil {
+			return nil, err
+		}
+	}
+
+	office, found, err := ctx.GetClientIdentity().GetAttributeValue("subRegistrarOffice")
+	if err != nil {
+		return nil, newError(ErrCodeAccessDenied, "failed to read subRegistrarOffice attribute: %v", err)
+	}
+	if !found || office == "" {
+		office = property.Location.StateCode + "-" + property.Location.DistrictCode
+	}
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	enc := EncumbranceRecord{
+		DocType:       "encumbranceRecord",
+		EncumbranceID: "enc_" + txID[:8],
+		PropertyID:    req.PropertyID,
+		Type:          "EASEMENT",
+		Status:        "ACTIVE",
+		Institution:   Institution{Name: office, MspID: mspID},
+		Details:       EncumbranceDetails{StartDate: now[:10]},
+		CreatedAt:     now,
+		CreatedBy:     getCallerID(ctx),
+		RequestID:     req.RequestID,
+		Easement: &EasementDetails{
+			DominantPropertyID:  req.DominantPropertyID,
+			Purpose:             req.Purpose,
+			Extent:              req.Extent,
+			Strip:               req.Strip,
+			ServientConsentHash: req.ServientConsentHash,
+			DominantConsentHash: req.DominantConsentHash,
+		},
+	}
+	if err := putEncumbrance(ctx, &enc); err != nil {
+		return nil, err
+	}
+	if err := countNewRecord(ctx, property.Location.StateCode, "encumbranceRecord", enc.Status); err != nil {
+		return nil, err
+	}
+	if err := putEasementIndex(ctx, &enc); err != nil {
+		return nil, err
+	}
+	if req.RequestID != "" {
+		encKey, _ := createEncumbranceKey(ctx, enc.PropertyID, enc.EncumbranceID)
+		if err := putRequest(ctx, req.RequestID, "AddEasement", easementJSON, encKey, enc.EncumbranceID); err != nil {
+			return nil, err
+		}
+	}
+
+	property.EncumbranceStatus = "ENCUMBERED"
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+
+	landKey, _ := createLandKey(ctx, property.PropertyID)
+	propertyBytes, _ := canonicalMarshal(property)
+	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
+		return nil, internalError("failed to update property encumbrance status: %v", err)
+	}
+
+	event := EncumbranceEvent{
+		Type:            "ENCUMBRANCE_ADDED",
+		EncumbranceID:   enc.EncumbranceID,
+		PropertyID:      enc.PropertyID,
+		EncumbranceType: enc.Type,
+		InstitutionName: enc.Institution.Name,
+		FabricTxID:      txID,
+		Timestamp:       now,
+		StateCode:       property.Location.StateCode,
+		ChannelID:       ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "ENCUMBRANCE_ADDED", event); err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, enc.EncumbranceID, enc.Status).withRequest(req.RequestID).
+		relate("property", enc.PropertyID, property.EncumbranceStatus).
+		relate("property", req.DominantPropertyID, dominant.Status), nil
+}
+
+// ConfirmEasement clears the needsConfirmation flag of an easement
+// carried onto a parcel by a split or merge, once a registrar has
+// checked that the strip really crosses it. Only registrars with
+// jurisdiction over the parcel can confirm. Emits EASEMENT_CONFIRMED.
+func (s *LandRegistryContract) ConfirmEasement(ctx contractapi.TransactionContextInterface, encumbranceID string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+
+	enc, err := findEncumbrance(ctx, encumbranceID)
+	if err != nil {
+		return err
+	}
+	if enc.Type != "EASEMENT" || enc.Easement == nil {
+		return newError(ErrCodeValidationError, "encumbrance %s is not an easement", encumbranceID)
+	}
+	if enc.Status != "ACTIVE" {
+		return newError(ErrCodeEncumbranceNotActive, "encumbrance %s has status %s", encumbranceID, enc.Status)
+	}
+	if !enc.Easement.NeedsConfirmation {
+		return newError(ErrCodeValidationError, "easement %s does not need confirmation", encumbranceID)
+	}
+	property, err := readLandRecord(ctx, enc.PropertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	enc.Easement.NeedsConfirmation = false
+	enc.Easement.ConfirmedBy = getCallerID(ctx)
+	enc.Easement.ConfirmedAt = now
+	if err := putEncumbrance(ctx, enc); err != nil {
+		return err
+	}
+
+	event := EncumbranceEvent{
+		Type:            "EASEMENT_CONFIRMED",
+		EncumbranceID:   enc.EncumbranceID,
+		PropertyID:      enc.PropertyID,
+		EncumbranceType: enc.Type,
+		InstitutionName: enc.Institution.Name,
+		FabricTxID:      ctx.GetStub().GetTxID(),
+		Timestamp:       now,
+		StateCode:       property.Location.StateCode,
+		ChannelID:       ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "EASEMENT_CONFIRMED", event)
+}
+
+// GetEasementsBenefiting returns the active easements other parcels
+// bear for the benefit of a dominant parcel, ordered by creation. It is
+// readable by whoever can read the dominant parcel.
+func (s *LandRegistryContract) GetEasementsBenefiting(ctx contractapi.TransactionContextInterface, dominantPropertyID string) ([]*EncumbranceRecord, error) {
+	if _, err := s.GetProperty(ctx, dominantPropertyID); err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixEasementDominant, []string{dominantPropertyID})
+	if err != nil {
+		return nil, internalError("failed to query easement index: %v", err)
+	}
+	defer iterator.Close()
+
+	easements := []*EncumbranceRecord{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate easement index: %v", err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) != 2 {
+			continue
+		}
+		encKey, err := createEncumbranceKey(ctx, string(kv.Value), parts[1])
+		if err != nil {
+			return nil, internalError("failed to create encumbrance key: %v", err)
+		}
+		encBytes, err := ctx.GetStub().GetState(encKey)
+		if err != nil {
+			return nil, internalError("failed to read encumbrance %s: %v", parts[1], err)
+		}
+		if encBytes == nil {
+			continue
+		}
+		var enc EncumbranceRecord
+		if err := json.Unmarshal(encBytes, &enc); err != nil {
+			return nil, internalError("failed to unmarshal encumbrance: %v", err)
+		}
+		if enc.Status == "ACTIVE" {
+			easements = append(easements, &enc)
+		}
+	}
+	sort.SliceStable(easements, func(i, j int) bool { return easements[i].CreatedAt < easements[j].CreatedAt })
+	return easements, nil
+}
+
+// validateStrip checks an easement strip's geometry and, if the
+// servient parcel has a drawn boundary, that the strip lies over it.
+func validateStrip(strip GeoJSON, property *LandRecord) error {
+	if err := validateGeoJSON(strip); err != nil {
+		return errorAt("strip", err)
+	}
+	parcel := geoJSONPolygons(property.Boundaries.GeoJSON)
+	if len(parcel) == 0 {
+		return nil
+	}
+	if !stripCrosses(geoJSONPolygons(strip), parcel) {
+		return newError(ErrCodeValidationError, "easement strip does not cross %s", property.PropertyID)
+	}
+	return nil
+}
+
+// stripCrosses reports whether an easement strip overlaps a parcel.
+func stripCrosses(strip, parcel [][][][]float64) bool {
+	box := polygonsBoundingBox(parcel)
+	if !box.intersects(polygonsBoundingBox(strip)) {
+		return false
+	}
+	originLon, originLat := (box.minLon+box.maxLon)/2, (box.minLat+box.maxLat)/2
+	return polygonOverlapArea(strip, parcel, originLon, originLat) > 0
+}
+
+// easementSplitTargets returns, for each easement, the children of a
+// split that its strip crosses. An easement without a strip, one whose
+// strip crosses none of the drawn children, or a split with an undrawn
+// child goes to every child.
+func easementSplitTargets(encumbrances []*EncumbranceRecord, splits []SplitRequest) map[string][]string {
+	all := make([]string, 0, len(splits))
+	children := make([][][][][]float64, len(splits))
+	drawn := true
+	for i, split := range splits {
+		all = append(all, split.NewPropertyID)
+		children[i] = geoJSONPolygons(split.Boundaries.GeoJSON)
+		if len(children[i]) == 0 {
+			drawn = false
+		}
+	}
+
+	targets := map[string][]string{}
+	for _, enc := range encumbrances {
+		if enc.Easement == nil {
+			continue
+		}
+		targets[enc.EncumbranceID] = all
+		if enc.Easement.Strip == nil || !drawn {
+			continue
+		}
+		strip := geoJSONPolygons(*enc.Easement.Strip)
+		var crossed []string
+		for i, split := range splits {
+			if stripCrosses(strip, children[i]) {
+				crossed = append(crossed, split.NewPropertyID)
+			}
+		}
+		if len(crossed) > 0 {
+			targets[enc.EncumbranceID] = crossed
+		}
+	}
+	return targets
+}
+
+// putEasementIndex indexes an easement under its dominant parcel:
+// EASEMENT_DOMINANT~{dominantPropertyId}~{encumbranceId} -> servient propertyId.
+func putEasementIndex(ctx contractapi.TransactionContextInterface, enc *EncumbranceRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixEasementDominant, []string{enc.Easement.DominantPropertyID, enc.EncumbranceID})
+	if err != nil {
+		return internalError("failed to create easement index key: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, []byte(enc.PropertyID)); err != nil {
+		return internalError("failed to write easement index: %v", err)
+	}
+	return nil
+}
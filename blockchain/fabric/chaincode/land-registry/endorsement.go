@@ -0,0 +1,246 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// KEY-LEVEL ENDORSEMENT
+// ============================================================
+// The channel endorsement policy lets any sufficient set of orgs write
+// any state's land records. Each land key therefore carries a
+// state-based validation parameter requiring endorsement by a peer of
+// the org that administers the record's district, taken from the
+// state's registry settings.
+
+// endorsingMspID returns the MSP that must endorse writes to land
+// records in a district: the district's entry in DistrictMspIDs, else the
+// state's EndorsingMspID. Empty means the channel policy alone applies.
+func endorsingMspID(settings *RegistrySettings, districtCode string) string {
+	if mspID := settings.DistrictMspIDs[districtCode]; mspID != "" {
+		return mspID
+	}
+	return settings.EndorsingMspID
+}
+
+// setLandEndorsement requires a peer of mspID to endorse every later
+// write to the land record for propertyID.
+func setLandEndorsement(ctx contractapi.TransactionContextInterface, propertyID, mspID string) error {
+	ep, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return internalError("failed to create endorsement policy: %v", err)
+	}
+	if err := ep.AddOrgs(statebased.RoleTypePeer, mspID); err != nil {
+		return internalError("failed to add %s to endorsement policy: %v", mspID, err)
+	}
+	policy, err := ep.Policy()
+	if err != nil {
+		return internalError("failed to marshal endorsement policy: %v", err)
+	}
+	landKey, err := createLandKey(ctx, propertyID)
+	if err != nil {
+		return internalError("failed to create land key: %v", err)
+	}
+	if err := ctx.GetStub().SetStateValidationParameter(landKey, policy); err != nil {
+		return internalError("failed to set endorsement policy on %s: %v", propertyID, err)
+	}
+	return nil
+}
+
+// applyLandEndorsement sets the endorsement policy of a newly
+// registered record from its state's settings. States without an
+// endorsing MSP are left to the channel policy.
+func applyLandEndorsement(ctx contractapi.TransactionContextInterface, property *LandRecord, settings *RegistrySettings) error {
+	mspID := endorsingMspID(settings, property.Location.DistrictCode)
+	if mspID == "" {
+		return nil
+	}
+	return setLandEndorsement(ctx, property.PropertyID, mspID)
+}
+
+// inheritLandEndorsement gives a split or merge child the endorsement
+// policy of the parcel it came from, if that parcel has one.
+func inheritLandEndorsement(ctx contractapi.TransactionContextInterface, parentID, childID string) error {
+	parentKey, err := createLandKey(ctx, parentID)
+	if err != nil {
+		return internalError("failed to create land key: %v", err)
+	}
+	policy, err := ctx.GetStub().GetStateValidationParameter(parentKey)
+	if err != nil {
+		return internalError("failed to read endorsement policy of %s: %v", parentID, err)
+	}
+	if len(policy) == 0 {
+		return nil
+	}
+	childKey, err := createLandKey(ctx, childID)
+	if err != nil {
+		return internalError("failed to create land key: %v", err)
+	}
+	if err := ctx.GetStub().SetStateValidationParameter(childKey, policy); err != nil {
+		return internalError("failed to set endorsement policy on %s: %v", childID, err)
+	}
+	return nil
+}
+
+// ReassignDistrictOrg moves a district to another org after an
+// administrative reorganization: the state's DistrictMspIDs entry is set
+// to mspID and up to maxCount of the district's land records are given
+// an endorsement policy naming it. The district's LOCATION index entries
+// are scanned in key order starting after bookmark (empty for the first
+// batch); call again with the returned bookmark until Done. Changing a
+// key's policy must itself satisfy the old policy, so each batch needs
+// the outgoing org's endorsement. Only admins in the state can reassign.
+// Emits DISTRICT_ORG_REASSIGNED.
+func (s *LandRegistryContract) ReassignDistrictOrg(ctx contractapi.TransactionContextInterface, stateCode, districtCode, mspID string, maxCount int, bookmark string) (*EndorsementUpdateResult, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+
+	if stateCode == "" || districtCode == "" || mspID == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode, districtCode and mspId are all required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return nil, err
+	}
+	if maxCount <= 0 || maxCount > maxBulkRecords {
+		return nil, newError(ErrCodeValidationError, "maxCount must be between 1 and %d", maxBulkRecords)
+	}
+
+	settings, err := getSettings(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+	previousMspID := endorsingMspID(settings, districtCode)
+	if settings.DistrictMspIDs == nil {
+		settings.DistrictMspIDs = map[string]string{}
+	}
+	settings.DistrictMspIDs[districtCode] = mspID
+
+	result := &EndorsementUpdateResult{
+		StateCode:    stateCode,
+		DistrictCode: districtCode,
+		MspID:        mspID,
+	}
+	if err := rekeyLandRecords(ctx, settings, []string{stateCode, districtCode}, maxCount, bookmark, result); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, "ReassignDistrictOrg", stateCode+"-"+districtCode); err != nil {
+		return nil, err
+	}
+	return result, emitOrgReassigned(ctx, "DISTRICT_ORG_REASSIGNED", result, previousMspID)
+}
+
+// ReassignStateOrg changes the org that endorses a state's land records
+// (EndorsingMspID) after an administrative reorganization, and gives up
+// to maxCount of the records in districts without their own org (see
+// ReassignDistrictOrg) an endorsement policy naming it. Batches work as
+// for ReassignDistrictOrg, over the state's LOCATION index. Only admins
+// in the state can reassign. Emits STATE_ORG_REASSIGNED.
+func (s *LandRegistryContract) ReassignStateOrg(ctx contractapi.TransactionContextInterface, stateCode, mspID string, maxCount int, bookmark string) (*EndorsementUpdateResult, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+
+	if stateCode == "" || mspID == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode and mspId are both required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return nil, err
+	}
+	if maxCount <= 0 || maxCount > maxBulkRecords {
+		return nil, newError(ErrCodeValidationError, "maxCount must be between 1 and %d", maxBulkRecords)
+	}
+
+	settings, err := getSettings(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+	previousMspID := settings.EndorsingMspID
+	settings.EndorsingMspID = mspID
+
+	result := &EndorsementUpdateResult{
+		StateCode: stateCode,
+		MspID:     mspID,
+	}
+	if err := rekeyLandRecords(ctx, settings, []string{stateCode}, maxCount, bookmark, result); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, "ReassignStateOrg", stateCode); err != nil {
+		return nil, err
+	}
+	return result, emitOrgReassigned(ctx, "STATE_ORG_REASSIGNED", result, previousMspID)
+}
+
+// rekeyLandRecords saves the updated settings and gives up to maxCount
+// land records under the LOCATION index prefix attrs, after bookmark,
+// an endorsement policy naming result.MspID. Records in a district the
+// settings map to another org are skipped.
+func rekeyLandRecords(ctx contractapi.TransactionContextInterface, settings *RegistrySettings, attrs []string, maxCount int, bookmark string, result *EndorsementUpdateResult) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	settings.UpdatedBy = getCallerID(ctx)
+	settings.UpdatedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	settings.FabricTxID = ctx.GetStub().GetTxID()
+	if err := putSettings(ctx, settings); err != nil {
+		return err
+	}
+
+	// Paginated queries are not allowed in update transactions, so the
+	// bookmark is the last LOCATION key processed
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixLocationIndex, attrs)
+	if err != nil {
+		return internalError("failed to iterate location index: %v", err)
+	}
+	defer iterator.Close()
+
+	result.Done = true
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return internalError("failed to iterate: %v", err)
+		}
+		if bookmark != "" && kv.Key <= bookmark {
+			continue
+		}
+		if result.Updated >= maxCount {
+			result.Done = false
+			break
+		}
+		result.Bookmark = kv.Key
+		result.Scanned++
+
+		_, keyAttrs, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(keyAttrs) != 5 {
+			continue
+		}
+		if endorsingMspID(settings, keyAttrs[1]) != result.MspID {
+			continue
+		}
+		if err := setLandEndorsement(ctx, keyAttrs[4], result.MspID); err != nil {
+			return err
+		}
+		result.Updated++
+	}
+	return nil
+}
+
+// emitOrgReassigned emits a DistrictOrgReassignedEvent for one
+// reassignment batch.
+func emitOrgReassigned(ctx contractapi.TransactionContextInterface, eventName string, result *EndorsementUpdateResult, previousMspID string) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := DistrictOrgReassignedEvent{
+		Type:          eventName,
+		DistrictCode:  result.DistrictCode,
+		MspID:         result.MspID,
+		PreviousMspID: previousMspID,
+		Updated:       result.Updated,
+		Done:          result.Done,
+		FabricTxID:    ctx.GetStub().GetTxID(),
+		Timestamp:     time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:     result.StateCode,
+		ChannelID:     ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, eventName, event)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// expectedPolicy returns the validation parameter naming a peer of mspID.
+func expectedPolicy(t *testing.T, mspID string) []byte {
+	t.Helper()
+	ep, err := statebased.NewStateEP(nil)
+	if err != nil {
+		t.Fatalf("new state EP: %v", err)
+	}
+	if err := ep.AddOrgs(statebased.RoleTypePeer, mspID); err != nil {
+		t.Fatalf("add org: %v", err)
+	}
+	policy, err := ep.Policy()
+	if err != nil {
+		t.Fatalf("marshal policy: %v", err)
+	}
+	return policy
+}
+
+// landPolicy returns the validation parameter on propertyID's land key.
+func (l *testLedger) landPolicy(propertyID string) []byte {
+	l.t.Helper()
+	key, err := l.stub.CreateCompositeKey(KeyPrefixLand, []string{propertyID})
+	if err != nil {
+		l.t.Fatalf("create land key: %v", err)
+	}
+	policy, _ := l.stub.GetStateValidationParameter(key)
+	return policy
+}
+
+func TestRegisterPropertySetsEndorsementPolicy(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(&RegistrySettings{
+		DocType:        "registrySettings",
+		StateCode:      "TS",
+		EndorsingMspID: "TelanganaMSP",
+		DistrictMspIDs: map[string]string{"RR": "RangareddyMSP"},
+	})
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+
+	ledger.registerTestProperty(registrar, testProperty("142", 1))
+	if got, want := ledger.landPolicy("TS-HYD-SRN-GCB-142-0"), expectedPolicy(t, "TelanganaMSP"); !bytes.Equal(got, want) {
+		t.Fatalf("HYD policy = %x, want %x", got, want)
+	}
+
+	district := testProperty("7", 2)
+	district.PropertyID = "TS-RR-SRN-GCB-7-0"
+	district.Location.DistrictCode = "RR"
+	ledger.registerTestProperty(registrar, district)
+	if got, want := ledger.landPolicy("TS-RR-SRN-GCB-7-0"), expectedPolicy(t, "RangareddyMSP"); !bytes.Equal(got, want) {
+		t.Fatalf("RR policy = %x, want %x", got, want)
+	}
+}
+
+func TestRegisterPropertyWithoutEndorsingMspLeavesChannelPolicy(t *testing.T) {
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+
+	ledger.registerTestProperty(registrar, testProperty("142", 1))
+	if policy := ledger.landPolicy("TS-HYD-SRN-GCB-142-0"); len(policy) != 0 {
+		t.Fatalf("expected no validation parameter, got %x", policy)
+	}
+}
+
+func TestReassignDistrictOrgRekeysDistrictInBatches(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", EndorsingMspID: "TelanganaMSP"})
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	for i, surveyNo := range []string{"1", "2", "3"} {
+		ledger.registerTestProperty(registrar, testProperty(surveyNo, i+1))
+	}
+	other := testProperty("9", 9)
+	other.PropertyID = "TS-RR-SRN-GCB-9-0"
+	other.Location.DistrictCode = "RR"
+	ledger.registerTestProperty(registrar, other)
+
+	admin := newTestIdentity(t, "TelanganaMSP", "admin", "TS")
+	var first, second *EndorsementUpdateResult
+	ledger.mustSubmit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		first, err = ledger.contract.ReassignDistrictOrg(ctx, "TS", "HYD", "HyderabadMSP", 2, "")
+		return err
+	})
+	if first.Updated != 2 || first.Done {
+		t.Fatalf("first batch = %+v, want 2 updated and not done", first)
+	}
+	if !ledger.hasEvent("DISTRICT_ORG_REASSIGNED") {
+		t.Fatal("expected DISTRICT_ORG_REASSIGNED")
+	}
+	ledger.mustSubmit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		second, err = ledger.contract.ReassignDistrictOrg(ctx, "TS", "HYD", "HyderabadMSP", 2, first.Bookmark)
+		return err
+	})
+	if second.Updated != 1 || !second.Done {
+		t.Fatalf("second batch = %+v, want 1 updated and done", second)
+	}
+
+	want := expectedPolicy(t, "HyderabadMSP")
+	for _, propertyID := range []string{"TS-HYD-SRN-GCB-1-0", "TS-HYD-SRN-GCB-2-0", "TS-HYD-SRN-GCB-3-0"} {
+		if got := ledger.landPolicy(propertyID); !bytes.Equal(got, want) {
+			t.Fatalf("%s policy = %x, want %x", propertyID, got, want)
+		}
+	}
+	if got, want := ledger.landPolicy("TS-RR-SRN-GCB-9-0"), expectedPolicy(t, "TelanganaMSP"); !bytes.Equal(got, want) {
+		t.Fatalf("RR record was re-keyed: %x", got)
+	}
+}
+
+func TestReassignStateOrgSkipsDistrictsWithTheirOwnOrg(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(&RegistrySettings{
+		DocType:        "registrySettings",
+		StateCode:      "TS",
+		EndorsingMspID: "TelanganaMSP",
+		DistrictMspIDs: map[string]string{"RR": "RangareddyMSP"},
+	})
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	ledger.registerTestProperty(registrar, testProperty("1", 1))
+	district := testProperty("9", 9)
+	district.PropertyID = "TS-RR-SRN-GCB-9-0"
+	district.Location.DistrictCode = "RR"
+	ledger.registerTestProperty(registrar, district)
+
+	admin := newTestIdentity(t, "TelanganaMSP", "admin", "TS")
+	var result *EndorsementUpdateResult
+	ledger.mustSubmit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		result, err = ledger.contract.ReassignStateOrg(ctx, "TS", "TelanganaRevenueMSP", 10, "")
+		return err
+	})
+	if result.Updated != 1 || !result.Done {
+		t.Fatalf("result = %+v, want 1 updated and done", result)
+	}
+	if got, want := ledger.landPolicy("TS-HYD-SRN-GCB-1-0"), expectedPolicy(t, "TelanganaRevenueMSP"); !bytes.Equal(got, want) {
+		t.Fatalf("HYD policy = %x, want %x", got, want)
+	}
+	if got, want := ledger.landPolicy("TS-RR-SRN-GCB-9-0"), expectedPolicy(t, "RangareddyMSP"); !bytes.Equal(got, want) {
+		t.Fatalf("RR policy = %x, want %x", got, want)
+	}
+}
+
+func TestSetRegistrySettingsRejectsEndorsingMspChange(t *testing.T) {
+	ledger := newTestLedger(t)
+	admin := newTestIdentity(t, "TelanganaMSP", "admin", "TS")
+
+	err := ledger.submit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetRegistrySettings(ctx, "TS", `{"endorsingMspId":"TelanganaMSP"}`)
+	})
+	expectCode(t, err, ErrCodeValidationError)
+
+	err = ledger.submit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetRegistrySettings(ctx, "TS", `{"districtMspIds":{"HYD":"HyderabadMSP"}}`)
+	})
+	expectCode(t, err, ErrCodeValidationError)
+}
+
+func TestSplitChildInheritsParentPolicy(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", EndorsingMspID: "TelanganaMSP"})
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	ledger.registerTestProperty(registrar, testProperty("1", 1))
+
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		return inheritLandEndorsement(ctx, "TS-HYD-SRN-GCB-1-0", "TS-HYD-SRN-GCB-1-1")
+	})
+	if got, want := ledger.landPolicy("TS-HYD-SRN-GCB-1-1"), expectedPolicy(t, "TelanganaMSP"); !bytes.Equal(got, want) {
+		t.Fatalf("child policy = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// ERRORS
+// ============================================================
+// Every error a contract function returns is a *ChaincodeError. Its
+// message is the code, a colon and the error as JSON:
+//
+//	PROPERTY_NOT_FOUND: {"code":"PROPERTY_NOT_FOUND","message":"AP-GNT-... does not exist","details":{"propertyId":"AP-GNT-..."}}
+//
+// so clients can parse everything after the first ": {" that follows
+// Fabric's own wrapper, while code-prefix regexes keep working.
+// GetErrorCodes lists every code.
+
+// Error codes. Add new codes here and to errorCodes.
+const (
+	ErrCodeAadhaarFormatInvalid             = "AADHAAR_FORMAT_INVALID"
+	ErrCodeAadhaarRequired                  = "AADHAAR_REQUIRED"
+	ErrCodeAccessDenied                     = "ACCESS_DENIED"
+	ErrCodeAlreadySigned                    = "ALREADY_SIGNED"
+	ErrCodeAnchorAttemptInvalidState        = "ANCHOR_ATTEMPT_INVALID_STATE"
+	ErrCodeAnchorAttemptMismatch            = "ANCHOR_ATTEMPT_MISMATCH"
+	ErrCodeAnchorAttemptNotFound            = "ANCHOR_ATTEMPT_NOT_FOUND"
+	ErrCodeAnnotationNotFound               = "ANNOTATION_NOT_FOUND"
+	ErrCodeAreaMismatch                     = "AREA_MISMATCH"
+	ErrCodeAreaUnitMismatch                 = "AREA_UNIT_MISMATCH"
+	ErrCodeAreaUnitNotConfigured            = "AREA_UNIT_NOT_CONFIGURED"
+	ErrCodeCeilingExceeded                  = "CEILING_EXCEEDED"
+	ErrCodeCertifiedCopyNotFound            = "CERTIFIED_COPY_NOT_FOUND"
+	ErrCodeCompensationDisputed             = "COMPENSATION_DISPUTED"
+	ErrCodeCompensationNotFound             = "COMPENSATION_NOT_FOUND"
+	ErrCodeConversionChargeUnpaid           = "CONVERSION_CHARGE_UNPAID"
+	ErrCodeCoolingPeriodActive              = "COOLING_PERIOD_ACTIVE"
+	ErrCodeCorrectionFieldNotAllowed        = "CORRECTION_FIELD_NOT_ALLOWED"
+	ErrCodeCredentialHolderNotOwner         = "CREDENTIAL_HOLDER_NOT_OWNER"
+	ErrCodeCredentialIssuerNotConfigured    = "CREDENTIAL_ISSUER_NOT_CONFIGURED"
+	ErrCodeCropLoanNotAcknowledged          = "CROP_LOAN_NOT_ACKNOWLEDGED"
+	ErrCodeCropLoanSeasonActive             = "CROP_LOAN_SEASON_ACTIVE"
+	ErrCodeCultivationNotActive             = "CULTIVATION_NOT_ACTIVE"
+	ErrCodeCultivationNotFound              = "CULTIVATION_NOT_FOUND"
+	ErrCodeDelegationInvalidState           = "DELEGATION_INVALID_STATE"
+	ErrCodeDelegationNotFound               = "DELEGATION_NOT_FOUND"
+	ErrCodeDenylistEntryNotFound            = "DENYLIST_ENTRY_NOT_FOUND"
+	ErrCodeDenylistInvalidState             = "DENYLIST_INVALID_STATE"
+	ErrCodeDisasterStatusNotSet             = "DISASTER_STATUS_NOT_SET"
+	ErrCodeDisputeAlreadyResolved           = "DISPUTE_ALREADY_RESOLVED"
+	ErrCodeDisputeNotFound                  = "DISPUTE_NOT_FOUND"
+	ErrCodeDiversionApprovalRequired        = "DIVERSION_APPROVAL_REQUIRED"
+	ErrCodeDocumentExists                   = "DOCUMENT_EXISTS"
+	ErrCodeDocumentNotFound                 = "DOCUMENT_NOT_FOUND"
+	ErrCodeDocumentSuperseded               = "DOCUMENT_SUPERSEDED"
+	ErrCodeEncumbranceConsentRequired       = "ENCUMBRANCE_CONSENT_REQUIRED"
+	ErrCodeEncumbranceNotActive             = "ENCUMBRANCE_NOT_ACTIVE"
+	ErrCodeEncumbranceNotFound              = "ENCUMBRANCE_NOT_FOUND"
+	ErrCodeEventlogPruned                   = "EVENTLOG_PRUNED"
+	ErrCodeGovernmentApprovalDuplicate      = "GOVERNMENT_APPROVAL_DUPLICATE"
+	ErrCodeGovernmentApprovalRequired       = "GOVERNMENT_APPROVAL_REQUIRED"
+	ErrCodeGuardianInvalid                  = "GUARDIAN_INVALID"
+	ErrCodeGuardianRequired                 = "GUARDIAN_REQUIRED"
+	ErrCodeHeirCertificateInvalid           = "HEIR_CERTIFICATE_INVALID"
+	ErrCodeHeirCertificateMismatch          = "HEIR_CERTIFICATE_MISMATCH"
+	ErrCodeHeirCertificateNotFound          = "HEIR_CERTIFICATE_NOT_FOUND"
+	ErrCodeIdentityBlocked                  = "IDENTITY_BLOCKED"
+	ErrCodeInstitutionNotRegistered         = "INSTITUTION_NOT_REGISTERED"
+	ErrCodeInternalError                    = "INTERNAL_ERROR"
+	ErrCodeInvalidAreaUnit                  = "INVALID_AREA_UNIT"
+	ErrCodeInvalidGeojson                   = "INVALID_GEOJSON"
+	ErrCodeInvalidInput                     = "INVALID_INPUT"
+	ErrCodeInvestigationHoldExists          = "INVESTIGATION_HOLD_EXISTS"
+	ErrCodeInvestigationHoldNotFound        = "INVESTIGATION_HOLD_NOT_FOUND"
+	ErrCodeJurisdictionMismatch             = "JURISDICTION_MISMATCH"
+	ErrCodeKycAlreadyVerified               = "KYC_ALREADY_VERIFIED"
+	ErrCodeLandCoolingPeriod                = "LAND_COOLING_PERIOD"
+	ErrCodeLandDisputed                     = "LAND_DISPUTED"
+	ErrCodeLandEncumbered                   = "LAND_ENCUMBERED"
+	ErrCodeLandFrozen                       = "LAND_FROZEN"
+	ErrCodeLandUnderInvestigation           = "LAND_UNDER_INVESTIGATION"
+	ErrCodeLeaseLessorNotOwner              = "LEASE_LESSOR_NOT_OWNER"
+	ErrCodeLeaseNotActive                   = "LEASE_NOT_ACTIVE"
+	ErrCodeLeaseNotDisclosed                = "LEASE_NOT_DISCLOSED"
+	ErrCodeLeaseNotFound                    = "LEASE_NOT_FOUND"
+	ErrCodeMergeLocationMismatch            = "MERGE_LOCATION_MISMATCH"
+	ErrCodeMergeNotColocated                = "MERGE_NOT_COLOCATED"
+	ErrCodeMutationInvalidState             = "MUTATION_INVALID_STATE"
+	ErrCodeMutationNotFound                 = "MUTATION_NOT_FOUND"
+	ErrCodeOwnershipInvalid                 = "OWNERSHIP_INVALID"
+	ErrCodeOwnerExists                      = "OWNER_EXISTS"
+	ErrCodeOwnerIdentityInvalid             = "OWNER_IDENTITY_INVALID"
+	ErrCodeOwnerNotFound                    = "OWNER_NOT_FOUND"
+	ErrCodeOwnerNotMinor                    = "OWNER_NOT_MINOR"
+	ErrCodeOwnerStillMinor                  = "OWNER_STILL_MINOR"
+	ErrCodePartitionOwnerMismatch           = "PARTITION_OWNER_MISMATCH"
+	ErrCodePartitionShareMismatch           = "PARTITION_SHARE_MISMATCH"
+	ErrCodePoaInvalid                       = "POA_INVALID"
+	ErrCodePoaNotFound                      = "POA_NOT_FOUND"
+	ErrCodePropertyAlreadyFrozen            = "PROPERTY_ALREADY_FROZEN"
+	ErrCodePropertyArchived                 = "PROPERTY_ARCHIVED"
+	ErrCodePropertyExists                   = "PROPERTY_EXISTS"
+	ErrCodePropertyIdMismatch               = "PROPERTY_ID_MISMATCH"
+	ErrCodePropertyNotActive                = "PROPERTY_NOT_ACTIVE"
+	ErrCodePropertyNotFound                 = "PROPERTY_NOT_FOUND"
+	ErrCodePropertyNotFrozen                = "PROPERTY_NOT_FROZEN"
+	ErrCodePropertyNotSplit                 = "PROPERTY_NOT_SPLIT"
+	ErrCodeProposalInvalidState             = "PROPOSAL_INVALID_STATE"
+	ErrCodeProposalNotFound                 = "PROPOSAL_NOT_FOUND"
+	ErrCodeRegistrationNumberDuplicate      = "REGISTRATION_NUMBER_DUPLICATE"
+	ErrCodeRegistrationNumberNotFound       = "REGISTRATION_NUMBER_NOT_FOUND"
+	ErrCodeReleaseIsTransfer                = "RELEASE_IS_TRANSFER"
+	ErrCodeRequestIdConflict                = "REQUEST_ID_CONFLICT"
+	ErrCodeSanctionRequired                 = "SANCTION_REQUIRED"
+	ErrCodeSelfConfirmationDenied           = "SELF_CONFIRMATION_DENIED"
+	ErrCodeSettingsProposalNotFound         = "SETTINGS_PROPOSAL_NOT_FOUND"
+	ErrCodeSettingsProposalStale            = "SETTINGS_PROPOSAL_STALE"
+	ErrCodeSignatureInvalid                 = "SIGNATURE_INVALID"
+	ErrCodeSignerNotParty                   = "SIGNER_NOT_PARTY"
+	ErrCodeSigningKeyExists                 = "SIGNING_KEY_EXISTS"
+	ErrCodeSigningKeyNotFound               = "SIGNING_KEY_NOT_FOUND"
+	ErrCodeSplitBelowMinPlot                = "SPLIT_BELOW_MIN_PLOT"
+	ErrCodeSplitNotRevertible               = "SPLIT_NOT_REVERTIBLE"
+	ErrCodeSplitOutsideParent               = "SPLIT_OUTSIDE_PARENT"
+	ErrCodeSplitOverlap                     = "SPLIT_OVERLAP"
+	ErrCodeSplitTooManyChildren             = "SPLIT_TOO_MANY_CHILDREN"
+	ErrCodeStampDutyCallFailed              = "STAMP_DUTY_CALL_FAILED"
+	ErrCodeStateMismatch                    = "STATE_MISMATCH"
+	ErrCodeSurveyNumberOccupied             = "SURVEY_NUMBER_OCCUPIED"
+	ErrCodeTaxReceiptDuplicate              = "TAX_RECEIPT_DUPLICATE"
+	ErrCodeTransferAlienationRestricted     = "TRANSFER_ALIENATION_RESTRICTED"
+	ErrCodeTransferAlreadyFinal             = "TRANSFER_ALREADY_FINAL"
+	ErrCodeTransferClassificationRestricted = "TRANSFER_CLASSIFICATION_RESTRICTED"
+	ErrCodeTransferFemaRequired             = "TRANSFER_FEMA_REQUIRED"
+	ErrCodeTransferInvalidOwner             = "TRANSFER_INVALID_OWNER"
+	ErrCodeTransferInvalidState             = "TRANSFER_INVALID_STATE"
+	ErrCodeTransferInProgress               = "TRANSFER_IN_PROGRESS"
+	ErrCodeTransferMinorProperty            = "TRANSFER_MINOR_PROPERTY"
+	ErrCodeTransferNotFound                 = "TRANSFER_NOT_FOUND"
+	ErrCodeTransferNotRevertible            = "TRANSFER_NOT_REVERTIBLE"
+	ErrCodeTransferNotYetDue                = "TRANSFER_NOT_YET_DUE"
+	ErrCodeTransferStampDutyUnpaid          = "TRANSFER_STAMP_DUTY_UNPAID"
+	ErrCodeTransferTaxDuesPending           = "TRANSFER_TAX_DUES_PENDING"
+	ErrCodeTransferUndervalued              = "TRANSFER_UNDERVALUED"
+	ErrCodeTransferWitnessRequired          = "TRANSFER_WITNESS_REQUIRED"
+	ErrCodeValidationError                  = "VALIDATION_ERROR"
+	ErrCodeWillNotActive                    = "WILL_NOT_ACTIVE"
+	ErrCodeWillNotFound                     = "WILL_NOT_FOUND"
+)
+
+// errorCodes describes every error code, in code order.
+var errorCodes = []ErrorCodeInfo{
+	{ErrCodeAadhaarFormatInvalid, "An owner hash looks like a raw Aadhaar number"},
+	{ErrCodeAadhaarRequired, "An owner has no Aadhaar hash"},
+	{ErrCodeAccessDenied, "The caller's role or identity does not permit the operation"},
+	{ErrCodeAlreadySigned, "The signer has already signed the transfer"},
+	{ErrCodeAnchorAttemptInvalidState, "The anchor attempt has already completed or failed"},
+	{ErrCodeAnchorAttemptMismatch, "The anchor's state or root differs from its attempt's"},
+	{ErrCodeAnchorAttemptNotFound, "No anchor attempt has the given ID"},
+	{ErrCodeAnnotationNotFound, "No annotation has the given ID on the property"},
+	{ErrCodeAreaMismatch, "A polygon's area disagrees with the declared area"},
+	{ErrCodeAreaUnitMismatch, "An area's value disagrees with its local-unit value"},
+	{ErrCodeAreaUnitNotConfigured, "The state has no size configured for the local area unit"},
+	{ErrCodeCeilingExceeded, "The acquisition would take the party over the state's land ceiling"},
+	{ErrCodeCertifiedCopyNotFound, "No certified copy has the given serial number"},
+	{ErrCodeCompensationDisputed, "The compensation record is disputed"},
+	{ErrCodeCompensationNotFound, "No compensation record has the given ID"},
+	{ErrCodeConversionChargeUnpaid, "The land-use conversion charge has not been paid"},
+	{ErrCodeCoolingPeriodActive, "The transfer's cooling period has not yet expired"},
+	{ErrCodeCorrectionFieldNotAllowed, "The field cannot be changed by a correction"},
+	{ErrCodeCredentialHolderNotOwner, "The credential holder is not a current owner of the property"},
+	{ErrCodeCredentialIssuerNotConfigured, "The state has no registry DID to issue credentials"},
+	{ErrCodeCropLoanNotAcknowledged, "The buyer has not acknowledged every crop loan on the property"},
+	{ErrCodeCropLoanSeasonActive, "The crop loan's season has not yet ended"},
+	{ErrCodeCultivationNotActive, "The cultivation entry has already ended"},
+	{ErrCodeCultivationNotFound, "No cultivation entry has the given ID on the property"},
+	{ErrCodeDelegationInvalidState, "The delegation is not in a state that allows the operation"},
+	{ErrCodeDelegationNotFound, "No delegation has the given ID"},
+	{ErrCodeDenylistEntryNotFound, "The identity is not on the deny list"},
+	{ErrCodeDenylistInvalidState, "The deny list entry is not in a state that allows the operation"},
+	{ErrCodeDisasterStatusNotSet, "The property is not flagged as disaster-affected"},
+	{ErrCodeDisputeAlreadyResolved, "The dispute is already resolved"},
+	{ErrCodeDisputeNotFound, "No dispute has the given ID"},
+	{ErrCodeDiversionApprovalRequired, "Changing the land use requires diversion approval from the named authority"},
+	{ErrCodeDocumentExists, "The document is already attached"},
+	{ErrCodeDocumentNotFound, "The document is not attached to the property"},
+	{ErrCodeDocumentSuperseded, "The document has already been superseded"},
+	{ErrCodeEncumbranceConsentRequired, "The encumbrance holder's consent has not been recorded"},
+	{ErrCodeEncumbranceNotActive, "The encumbrance is not active"},
+	{ErrCodeEncumbranceNotFound, "No encumbrance has the given ID"},
+	{ErrCodeEventlogPruned, "The requested event journal range has been pruned"},
+	{ErrCodeGovernmentApprovalDuplicate, "The designation or certificate has already approved the transfer"},
+	{ErrCodeGovernmentApprovalRequired, "The transfer of government land awaits required approvals"},
+	{ErrCodeGuardianInvalid, "A guardian is given for an owner who is not a minor"},
+	{ErrCodeGuardianRequired, "A minor owner has no guardian"},
+	{ErrCodeHeirCertificateInvalid, "The legal heir certificate has expired or been invalidated"},
+	{ErrCodeHeirCertificateMismatch, "The heirs differ from those on the legal heir certificate"},
+	{ErrCodeHeirCertificateNotFound, "No legal heir certificate has the given ID"},
+	{ErrCodeIdentityBlocked, "The caller's identity is on the deny list"},
+	{ErrCodeInstitutionNotRegistered, "The institution is not registered as an encumbrance holder"},
+	{ErrCodeInternalError, "World state or serialization failure; retry or report"},
+	{ErrCodeInvalidAreaUnit, "The area unit is not recognised"},
+	{ErrCodeInvalidGeojson, "The boundary GeoJSON is malformed"},
+	{ErrCodeInvalidInput, "A JSON argument could not be parsed"},
+	{ErrCodeInvestigationHoldExists, "The property is already under an investigation hold"},
+	{ErrCodeInvestigationHoldNotFound, "The property has no investigation hold"},
+	{ErrCodeJurisdictionMismatch, "The record is outside the caller's district or tehsil"},
+	{ErrCodeKycAlreadyVerified, "The owner's KYC is already verified"},
+	{ErrCodeLandCoolingPeriod, "The property is in its post-transfer cooling period"},
+	{ErrCodeLandDisputed, "The property has an active dispute"},
+	{ErrCodeLandEncumbered, "The property has an active encumbrance"},
+	{ErrCodeLandFrozen, "The property is frozen by court order"},
+	{ErrCodeLandUnderInvestigation, "The property is under an investigation hold"},
+	{ErrCodeLeaseLessorNotOwner, "The lessor is not a current owner of the property"},
+	{ErrCodeLeaseNotActive, "The lease is not active"},
+	{ErrCodeLeaseNotDisclosed, "The transfer does not disclose every active lease on the property"},
+	{ErrCodeLeaseNotFound, "No lease on the property has the given ID"},
+	{ErrCodeMergeLocationMismatch, "The merged location does not match a source property"},
+	{ErrCodeMergeNotColocated, "The properties to merge are not in the same village"},
+	{ErrCodeMutationInvalidState, "The mutation is not in a state that allows the operation"},
+	{ErrCodeMutationNotFound, "No mutation has the given ID"},
+	{ErrCodeOwnershipInvalid, "The owners or their shares are invalid"},
+	{ErrCodeOwnerExists, "The person is already an owner of the property"},
+	{ErrCodeOwnerIdentityInvalid, "An owner's type and identity details are inconsistent"},
+	{ErrCodeOwnerNotFound, "The person is not an owner of the property"},
+	{ErrCodeOwnerNotMinor, "The owner is not recorded as a minor"},
+	{ErrCodeOwnerStillMinor, "The owner has not yet turned 18"},
+	{ErrCodePartitionOwnerMismatch, "A partition allotment does not match the current owners"},
+	{ErrCodePartitionShareMismatch, "A partition's area does not match its owners' shares"},
+	{ErrCodePoaInvalid, "The power of attorney is revoked, expired, or does not cover the party or property"},
+	{ErrCodePoaNotFound, "No power of attorney has the given ID"},
+	{ErrCodePropertyAlreadyFrozen, "The property is already frozen"},
+	{ErrCodePropertyArchived, "The property is archived and accepts no changes"},
+	{ErrCodePropertyExists, "A property with the given ID is already registered"},
+	{ErrCodePropertyIdMismatch, "The property ID does not match the record's location"},
+	{ErrCodePropertyNotActive, "The property's status does not allow the operation"},
+	{ErrCodePropertyNotFound, "No property has the given ID"},
+	{ErrCodePropertyNotFrozen, "The property is not frozen"},
+	{ErrCodePropertyNotSplit, "The property has not been split"},
+	{ErrCodeProposalInvalidState, "The subdivision proposal is not in a state that allows the operation"},
+	{ErrCodeProposalNotFound, "No subdivision proposal has the given ID"},
+	{ErrCodeRegistrationNumberDuplicate, "The deed registration number is already used"},
+	{ErrCodeRegistrationNumberNotFound, "No property has the given deed registration number"},
+	{ErrCodeReleaseIsTransfer, "A sole owner's release must be registered as a transfer"},
+	{ErrCodeRequestIdConflict, "The requestId was already used by a different function or with a different payload"},
+	{ErrCodeSanctionRequired, "The state requires tehsildar sanction for the operation"},
+	{ErrCodeSelfConfirmationDenied, "The same identity cannot both propose and confirm"},
+	{ErrCodeSettingsProposalNotFound, "The state has no pending settings proposal with the given ID"},
+	{ErrCodeSettingsProposalStale, "The settings changed after the proposal was made"},
+	{ErrCodeSignatureInvalid, "The signature does not verify against the signer's key"},
+	{ErrCodeSignerNotParty, "The signer is not a party or witness to the transfer"},
+	{ErrCodeSigningKeyExists, "The person already has an active signing key"},
+	{ErrCodeSigningKeyNotFound, "The person has no active signing key"},
+	{ErrCodeSplitBelowMinPlot, "A sub-plot is below the state's minimum plot size"},
+	{ErrCodeSplitNotRevertible, "The split cannot be reverted"},
+	{ErrCodeSplitOutsideParent, "A sub-plot lies outside the parent boundary"},
+	{ErrCodeSplitOverlap, "Two sub-plots overlap"},
+	{ErrCodeSplitTooManyChildren, "The split exceeds the state's sub-plot limit"},
+	{ErrCodeStampDutyCallFailed, "A call to the stamp-duty chaincode failed"},
+	{ErrCodeStateMismatch, "The record is outside the caller's state"},
+	{ErrCodeSurveyNumberOccupied, "The survey number is already registered"},
+	{ErrCodeTaxReceiptDuplicate, "The tax receipt is already recorded"},
+	{ErrCodeTransferAlienationRestricted, "The seller's protected category requires the collector's permission for this buyer"},
+	{ErrCodeTransferAlreadyFinal, "The transfer is final and cannot change"},
+	{ErrCodeTransferClassificationRestricted, "The land's classification requires clearance from the named authority"},
+	{ErrCodeTransferFemaRequired, "An NRI transfer lacks FEMA clearance"},
+	{ErrCodeTransferInvalidOwner, "The seller is not a current owner"},
+	{ErrCodeTransferInvalidState, "The transfer is not in a state that allows the operation"},
+	{ErrCodeTransferInProgress, "The property already has an active transfer"},
+	{ErrCodeTransferMinorProperty, "A minor's property needs a court order to transfer"},
+	{ErrCodeTransferNotFound, "No transfer has the given ID"},
+	{ErrCodeTransferNotRevertible, "The transfer cannot be rolled back"},
+	{ErrCodeTransferNotYetDue, "The transfer is scheduled for execution at a later time"},
+	{ErrCodeTransferStampDutyUnpaid, "Stamp duty has not been paid"},
+	{ErrCodeTransferTaxDuesPending, "Land revenue dues are outstanding"},
+	{ErrCodeTransferUndervalued, "The declared value is below the circle rate"},
+	{ErrCodeTransferWitnessRequired, "Too few witnesses have signed"},
+	{ErrCodeValidationError, "An argument failed validation"},
+	{ErrCodeWillNotActive, "The will has been revoked, superseded or already probated"},
+	{ErrCodeWillNotFound, "No will has the given ID"},
+}
+
+// ChaincodeError is a coded contract error. Details carries IDs and
+// values a client may need without parsing Message.
+type ChaincodeError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func (e *ChaincodeError) Error() string {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return e.Code + ": " + e.Message
+	}
+	return e.Code + ": " + string(payload)
+}
+
+// with sets a detail on the error and returns it.
+func (e *ChaincodeError) with(key, value string) *ChaincodeError {
+	if e.Details == nil {
+		e.Details = map[string]string{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// newError builds a coded error. An error among args is formatted as
+// "CODE: message" rather than as nested JSON.
+func newError(code, format string, args ...interface{}) *ChaincodeError {
+	for i, arg := range args {
+		if err, ok := arg.(error); ok {
+			args[i] = errorText(err)
+		}
+	}
+	return &ChaincodeError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// internalError builds an INTERNAL_ERROR, for world state and
+// serialization failures.
+func internalError(format string, args ...interface{}) *ChaincodeError {
+	return newError(ErrCodeInternalError, format, args...)
+}
+
+// errPropertyNotFound reports a property ID with no land record.
+func errPropertyNotFound(propertyID string) *ChaincodeError {
+	return newError(ErrCodePropertyNotFound, "%s does not exist", propertyID).with("propertyId", propertyID)
+}
+
+// errorAt prefixes err's message with the position of the input that
+// caused it, such as "property[2]", keeping its code.
+func errorAt(position string, err error) error {
+	var coded *ChaincodeError
+	if !errors.As(err, &coded) {
+		return internalError("%s: %v", position, err).with("position", position)
+	}
+	positioned := &ChaincodeError{Code: coded.Code, Message: position + ": " + coded.Message}
+	for key, value := range coded.Details {
+		positioned.with(key, value)
+	}
+	return positioned.with("position", position)
+}
+
+// errorText renders an error as "CODE: message" for places that store
+// or embed it as text.
+func errorText(err error) string {
+	var coded *ChaincodeError
+	if errors.As(err, &coded) {
+		return coded.Code + ": " + coded.Message
+	}
+	return err.Error()
+}
+
+// GetErrorCodes lists every error code the contract returns, with a
+// description. Open to every caller.
+func (s *LandRegistryContract) GetErrorCodes(ctx contractapi.TransactionContextInterface) []ErrorCodeInfo {
+	return errorCodes
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// EVENT JOURNAL
+// ============================================================
+// A listener that misses blocks would otherwise have to replay them with
+// a Fabric SDK to learn what happened. Instead every event is journaled
+// under its envelope sequence number: the event name, the IDs it names,
+// and the transaction. Full payloads are not kept; a catching-up
+// consumer re-fetches the records by key. GetEventsSince reads the
+// journal, and PruneEventLog folds old entries into one digest record
+// per channel to cap its growth.
+//
+// Separately, a daily digest per channel chains a hash over the events
+// themselves, so an auditor can check that the middleware received every
+// event of a day by comparing GetEventDigest with its own hash.
+
+const (
+	// maxEventLogPageSize caps GetEventsSince pages.
+	maxEventLogPageSize = 500
+	// maxEventLogPrune caps the entries one PruneEventLog call deletes.
+	maxEventLogPrune = 1000
+	// eventLogSequenceWidth zero-pads sequences in keys so they sort in
+	// order.
+	eventLogSequenceWidth = 20
+)
+
+// GetEventsSince returns the journaled events of the caller's channel
+// after sequence afterSequence, oldest first, at most pageSize of them.
+// Pass the returned LastSequence for the next page. Fails with
+// EVENTLOG_PRUNED if entries after afterSequence have been pruned. Only
+// admins can read the journal.
+func (s *LandRegistryContract) GetEventsSince(ctx contractapi.TransactionContextInterface, afterSequence int64, pageSize int) (*EventLogPage, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+	if afterSequence < 0 {
+		return nil, newError(ErrCodeValidationError, "afterSequence must not be negative")
+	}
+	if pageSize <= 0 || pageSize > maxEventLogPageSize {
+		return nil, newError(ErrCodeValidationError, "pageSize must be between 1 and %d", maxEventLogPageSize)
+	}
+
+	channelID := ctx.GetStub().GetChannelID()
+	digest, err := getEventLogDigest(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if digest != nil && afterSequence < digest.ThroughSequence {
+		return nil, newError(ErrCodeEventlogPruned, "events through sequence %d have been pruned; resume after it", digest.ThroughSequence)
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixEventLog, []string{channelID})
+	if err != nil {
+		return nil, internalError("failed to read event log: %v", err)
+	}
+	defer iterator.Close()
+
+	page := &EventLogPage{Entries: []*EventLogEntry{}, LastSequence: afterSequence}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate event log: %v", err)
+		}
+		var entry EventLogEntry
+		if err := json.Unmarshal(kv.Value, &entry); err != nil {
+			return nil, internalError("failed to unmarshal event log entry: %v", err)
+		}
+		if entry.Sequence <= afterSequence {
+			continue
+		}
+		if len(page.Entries) == pageSize {
+			page.HasMore = true
+			break
+		}
+		page.Entries = append(page.Entries, &entry)
+		page.LastSequence = entry.Sequence
+	}
+	return page, nil
+}
+
+// PruneEventLog deletes the caller's channel's journal entries up to
+// throughSequence, at most maxEventLogPrune per call, folding them into
+// the channel's digest record: Digest chains SHA-256 over the previous
+// digest and each deleted entry, so an exported copy of the journal can
+// still be checked against it. Only admins can prune. Writes an audit
+// entry.
+func (s *LandRegistryContract) PruneEventLog(ctx contractapi.TransactionContextInterface, throughSequence int64) (*EventLogDigest, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+	if throughSequence <= 0 {
+		return nil, newError(ErrCodeValidationError, "throughSequence must be positive")
+	}
+
+	channelID := ctx.GetStub().GetChannelID()
+	digest, err := getEventLogDigest(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if digest == nil {
+		digest = &EventLogDigest{DocType: "eventLogDigest", ChannelID: channelID}
+	}
+	if throughSequence <= digest.ThroughSequence {
+		return nil, newError(ErrCodeValidationError, "events through sequence %d are already pruned", digest.ThroughSequence)
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixEventLog, []string{channelID})
+	if err != nil {
+		return nil, internalError("failed to read event log: %v", err)
+	}
+	defer iterator.Close()
+
+	pruned := 0
+	for iterator.HasNext() && pruned < maxEventLogPrune {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate event log: %v", err)
+		}
+		var entry EventLogEntry
+		if err := json.Unmarshal(kv.Value, &entry); err != nil {
+			return nil, internalError("failed to unmarshal event log entry: %v", err)
+		}
+		if entry.Sequence > throughSequence {
+			break
+		}
+
+		hasher := sha256.New()
+		hasher.Write([]byte(digest.Digest))
+		hasher.Write(kv.Value)
+		digest.Digest = "sha256:" + hex.EncodeToString(hasher.Sum(nil))
+		digest.EntryCount++
+		digest.ThroughSequence = entry.Sequence
+
+		if err := ctx.GetStub().DelState(kv.Key); err != nil {
+			return nil, internalError("failed to delete event log entry: %v", err)
+		}
+		pruned++
+	}
+	if pruned == 0 {
+		return nil, newError(ErrCodeValidationError, "no journaled events through sequence %d", throughSequence)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	digest.PrunedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	digest.PrunedBy = getCallerID(ctx)
+	digest.FabricTxID = ctx.GetStub().GetTxID()
+
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixEventLogDigest, []string{channelID})
+	if err != nil {
+		return nil, internalError("failed to create event log digest key: %v", err)
+	}
+	digestBytes, err := canonicalMarshal(digest)
+	if err != nil {
+		return nil, internalError("failed to marshal event log digest: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, digestBytes); err != nil {
+		return nil, internalError("failed to write event log digest: %v", err)
+	}
+	if err := recordAudit(ctx, "PruneEventLog", fmt.Sprintf("%s through %d", channelID, digest.ThroughSequence)); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// journalEvent writes the journal entry for the event set with envelope.
+// A transaction that sets its event more than once overwrites its entry
+// (see emitEvent).
+func journalEvent(ctx contractapi.TransactionContextInterface, envelope *EventEnvelope, eventName string, fields map[string]json.RawMessage, relatedLists []string) error {
+	entry := EventLogEntry{
+		DocType:      "eventLogEntry",
+		Sequence:     envelope.EventSequence,
+		EventName:    eventName,
+		Keys:         eventKeyFields(fields),
+		RelatedLists: relatedLists,
+		FabricTxID:   envelope.FabricTxID,
+		Timestamp:    envelope.EmittedAt,
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixEventLog, []string{envelope.ChannelID, fmt.Sprintf("%0*d", eventLogSequenceWidth, entry.Sequence)})
+	if err != nil {
+		return internalError("failed to create event log key: %v", err)
+	}
+	entryBytes, err := canonicalMarshal(entry)
+	if err != nil {
+		return internalError("failed to marshal event log entry: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, entryBytes); err != nil {
+		return internalError("failed to write event log entry: %v", err)
+	}
+	return nil
+}
+
+// eventKeyFields picks the record IDs out of an event payload: its
+// top-level string fields named *Id, other than fabricTxId and
+// channelId, plus stateCode.
+func eventKeyFields(fields map[string]json.RawMessage) map[string]string {
+	keys := map[string]string{}
+	for name, raw := range fields {
+		if name == "fabricTxId" || name == "channelId" {
+			continue
+		}
+		if !strings.HasSuffix(name, "Id") && name != "stateCode" {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil || value == "" {
+			continue
+		}
+		keys[name] = value
+	}
+	return keys
+}
+
+// relatedListNames returns the names of a transaction's related-event
+// lists, sorted.
+func relatedListNames(related map[string][]interface{}) []string {
+	if len(related) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(related))
+	for name := range related {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getEventLogDigest loads a channel's event log digest, or nil if the
+// journal has never been pruned.
+func getEventLogDigest(ctx contractapi.TransactionContextInterface, channelID string) (*EventLogDigest, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixEventLogDigest, []string{channelID})
+	if err != nil {
+		return nil, internalError("failed to create event log digest key: %v", err)
+	}
+	digestBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read event log digest: %v", err)
+	}
+	if digestBytes == nil {
+		return nil, nil
+	}
+	var digest EventLogDigest
+	if err := json.Unmarshal(digestBytes, &digest); err != nil {
+		return nil, internalError("failed to unmarshal event log digest: %v", err)
+	}
+	return &digest, nil
+}
+
+// GetEventDigest returns the caller's channel's event digest for date
+// (YYYY-MM-DD, UTC, by transaction timestamp). A consumer checks its
+// stream by chaining, over each event it received that day in block
+// order,
+//
+//	digest = "sha256:" + hex(SHA-256(digest || eventName || "\n" || payload))
+//
+// starting from the empty string, where payload is the event's bytes as
+// delivered, and comparing digest and the count. A day with no events
+// has a zero digest. Only admins can read digests.
+func (s *LandRegistryContract) GetEventDigest(ctx contractapi.TransactionContextInterface, date string) (*EventDigest, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, newError(ErrCodeValidationError, "date must be YYYY-MM-DD")
+	}
+	digest, _, err := getEventDigest(ctx, ctx.GetStub().GetChannelID(), date)
+	return digest, err
+}
+
+// addToEventDigest chains the event a transaction sets into its day's
+// digest. An event set again by the same transaction replaces its
+// earlier contribution (see emitEvent).
+// The digest shares the event sequence's serialisation: every
+// transaction that emits already writes the sequence counter.
+func addToEventDigest(ctx contractapi.TransactionContextInterface, envelope *EventEnvelope, eventName string, payload []byte) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	date := time.Unix(timestamp.Seconds, 0).UTC().Format("2006-01-02")
+
+	digest, key, err := getEventDigest(ctx, envelope.ChannelID, date)
+	if err != nil {
+		return err
+	}
+	if digest.LastFabricTxID != envelope.FabricTxID {
+		digest.PreviousDigest = digest.Digest
+		digest.EventCount++
+		if digest.FirstSequence == 0 {
+			digest.FirstSequence = envelope.EventSequence
+		}
+	}
+	hasher := sha256.New()
+	hasher.Write([]byte(digest.PreviousDigest))
+	hasher.Write([]byte(eventName + "\n"))
+	hasher.Write(payload)
+	digest.Digest = "sha256:" + hex.EncodeToString(hasher.Sum(nil))
+	digest.LastSequence = envelope.EventSequence
+	digest.LastFabricTxID = envelope.FabricTxID
+
+	digestBytes, err := canonicalMarshal(digest)
+	if err != nil {
+		return internalError("failed to marshal event digest: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, digestBytes); err != nil {
+		return internalError("failed to write event digest: %v", err)
+	}
+	return nil
+}
+
+// getEventDigest loads a channel's digest for date, or a zero one, along
+// with its key.
+func getEventDigest(ctx contractapi.TransactionContextInterface, channelID, date string) (*EventDigest, string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixEventDigest, []string{channelID, date})
+	if err != nil {
+		return nil, "", internalError("failed to create event digest key: %v", err)
+	}
+	digestBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, "", internalError("failed to read event digest: %v", err)
+	}
+	digest := &EventDigest{DocType: "eventDigest", ChannelID: channelID, Date: date}
+	if digestBytes != nil {
+		if err := json.Unmarshal(digestBytes, digest); err != nil {
+			return nil, "", internalError("failed to unmarshal event digest: %v", err)
+		}
+	}
+	return digest, key, nil
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -26,6 +26,28 @@ type TransferEvent struct {
 	DocumentHash      string `json:"documentHash"`
 	StateCode         string `json:"stateCode"`
 	ChannelID         string `json:"channelId"`
+	// Approvers names both registrars of a dual-approved transfer.
+	Approvers []TransferApproval `json:"approvers,omitempty"`
+	// PreviousOwners and NewOwners are the full owner sets before and
+	// after the transfer (proposed after, until it is registered).
+	// PreviousOwnerHash and NewOwnerHash repeat their first entries.
+	PreviousOwners []OwnerShare `json:"previousOwners"`
+	NewOwners      []OwnerShare `json:"newOwners"`
+	// RecordHash is the land record's hash as written by this
+	// transaction; see landRecordHash. Empty if the record was not
+	// written.
+	RecordHash string `json:"recordHash,omitempty"`
+	// GovernmentApprovals lists, in order, the officials who approved
+	// a transfer of government land.
+	GovernmentApprovals []GovernmentApproval `json:"governmentApprovals,omitempty"`
+	// Reason is given on TRANSFER_ROLLED_BACK.
+	Reason string `json:"reason,omitempty"`
+}
+
+// OwnerShare is one owner in an event's owner set.
+type OwnerShare struct {
+	AadhaarHash     string `json:"aadhaarHash"`
+	SharePercentage int    `json:"sharePercentage"`
 }
 
 // PropertyRegisteredEvent is emitted when a new property is registered
@@ -35,12 +57,283 @@ type PropertyRegisteredEvent struct {
 	PropertyID   string `json:"propertyId"`
 	OwnerHash    string `json:"ownerHash"`
 	SurveyNumber string `json:"surveyNumber"`
+	// BoundaryConflicts lists conflicts detected against other parcels.
+	BoundaryConflicts []string `json:"boundaryConflicts,omitempty"`
+	FabricTxID        string   `json:"fabricTxId"`
+	Timestamp         string   `json:"timestamp"`
+	StateCode         string   `json:"stateCode"`
+	ChannelID         string   `json:"channelId"`
+}
+
+// LeaseEvent is emitted when a lease is registered on a property
+// (LEASE_REGISTERED) or terminated (LEASE_TERMINATED, with Reason).
+type LeaseEvent struct {
+	Type       string `json:"type"`
+	LeaseID    string `json:"leaseId"`
+	PropertyID string `json:"propertyId"`
+	LeaseType  string `json:"leaseType"`
+	LessorHash string `json:"lessorHash"`
+	LesseeHash string `json:"lesseeHash"`
+	StartDate  string `json:"startDate"`
+	EndDate    string `json:"endDate"`
+	AnnualRent int64  `json:"annualRent"`
+	Reason     string `json:"reason,omitempty"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
+// CultivationEvent is emitted when a cultivator is recorded on a
+// property (CULTIVATOR_RECORDED) or their cultivation ends
+// (CULTIVATION_ENDED, with Reason), for the agriculture department.
+type CultivationEvent struct {
+	Type           string       `json:"type"`
+	CultivationID  string       `json:"cultivationId"`
+	PropertyID     string       `json:"propertyId"`
+	CultivatorHash string       `json:"cultivatorHash"`
+	TenureClass    string       `json:"tenureClass"`
+	Seasons        []CropSeason `json:"seasons,omitempty"`
+	StartDate      string       `json:"startDate"`
+	EndDate        string       `json:"endDate,omitempty"`
+	Reason         string       `json:"reason,omitempty"`
+	FabricTxID     string       `json:"fabricTxId"`
+	Timestamp      string       `json:"timestamp"`
+	StateCode      string       `json:"stateCode"`
+	ChannelID      string       `json:"channelId"`
+}
+
+// CompensationAwardedEvent is emitted when a property is acquired,
+// listing the compensation record opened for each dispossessed owner.
+type CompensationAwardedEvent struct {
+	Type            string              `json:"type"`
+	PropertyID      string              `json:"propertyId"`
+	AwardRef        string              `json:"awardRef"`
+	NotificationRef string              `json:"notificationRef"`
+	MutationID      string              `json:"mutationId"`
+	Awards          []CompensationEntry `json:"awards"`
+	FabricTxID      string              `json:"fabricTxId"`
+	Timestamp       string              `json:"timestamp"`
+	StateCode       string              `json:"stateCode"`
+	ChannelID       string              `json:"channelId"`
+}
+
+// CompensationEntry is one owner's compensation record in a
+// CompensationAwardedEvent. Entitlement is in paisa.
+type CompensationEntry struct {
+	RecordID    string `json:"recordId"`
+	OwnerHash   string `json:"ownerHash"`
+	Entitlement int64  `json:"entitlement"`
+}
+
+// CompensationPaidEvent is emitted when a compensation payment is
+// recorded. Amounts are in paisa; PaidAmount is the principal paid so
+// far.
+type CompensationPaidEvent struct {
+	Type           string `json:"type"`
+	RecordID       string `json:"recordId"`
+	PropertyID     string `json:"propertyId"`
+	OwnerHash      string `json:"ownerHash"`
+	PaymentID      string `json:"paymentId"`
+	Amount         int64  `json:"amount"`
+	InterestAmount int64  `json:"interestAmount,omitempty"`
+	PaymentRef     string `json:"paymentRef"`
+	PaidAmount     int64  `json:"paidAmount"`
+	Entitlement    int64  `json:"entitlement"`
+	Status         string `json:"status"`
+	FabricTxID     string `json:"fabricTxId"`
+	Timestamp      string `json:"timestamp"`
+	StateCode      string `json:"stateCode"`
+	ChannelID      string `json:"channelId"`
+}
+
+// ValuationEvent is emitted when a valuation is recorded on a
+// property. Value is in paisa.
+type ValuationEvent struct {
+	Type                     string `json:"type"`
+	ValuationID              string `json:"valuationId"`
+	PropertyID               string `json:"propertyId"`
+	Purpose                  string `json:"purpose"`
+	Value                    int64  `json:"value"`
+	Method                   string `json:"method"`
+	ValuerRegistrationNumber string `json:"valuerRegistrationNumber"`
+	ReportHash               string `json:"reportHash"`
+	ValuationDate            string `json:"valuationDate"`
+	RecordedByMspID          string `json:"recordedByMspId"`
+	FabricTxID               string `json:"fabricTxId"`
+	Timestamp                string `json:"timestamp"`
+	StateCode                string `json:"stateCode"`
+	ChannelID                string `json:"channelId"`
+}
+
+// AnnotationEvent is emitted when an annotation is added to, updated
+// on or removed from a property.
+type AnnotationEvent struct {
+	Type           string `json:"type"`
+	PropertyID     string `json:"propertyId"`
+	AnnotationID   string `json:"annotationId"`
+	AnnotationType string `json:"annotationType"`
+	PermitStatus   string `json:"permitStatus"`
+	Reason         string `json:"reason,omitempty"`
+	FabricTxID     string `json:"fabricTxId"`
+	Timestamp      string `json:"timestamp"`
+	StateCode      string `json:"stateCode"`
+	ChannelID      string `json:"channelId"`
+}
+
+// OwnerCategoryAttestedEvent is emitted when a tehsildar attests an
+// owner's protected category. The category itself is left out.
+type OwnerCategoryAttestedEvent struct {
+	Type       string `json:"type"`
+	PropertyID string `json:"propertyId"`
+	OwnerHash  string `json:"ownerHash"`
+	AttestedBy string `json:"attestedBy"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
+// HoldEvent is emitted when an investigation hold is placed on or
+// released from a property.
+type HoldEvent struct {
+	Type               string `json:"type"`
+	PropertyID         string `json:"propertyId"`
+	HoldID             string `json:"holdId"`
+	Agency             string `json:"agency"`
+	AttachmentOrderRef string `json:"attachmentOrderRef"`
+	ReviewBy           string `json:"reviewBy"`
+	ReleaseOrderRef    string `json:"releaseOrderRef,omitempty"`
+	FabricTxID         string `json:"fabricTxId"`
+	Timestamp          string `json:"timestamp"`
+	StateCode          string `json:"stateCode"`
+	ChannelID          string `json:"channelId"`
+}
+
+// DisasterStatusEvent is emitted once per MarkDisasterAffected or
+// ClearDisasterStatus call and lists every property in it.
+type DisasterStatusEvent struct {
+	Type        string   `json:"type"`
+	EventRef    string   `json:"eventRef,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Stage       string   `json:"stage,omitempty"`
+	PropertyIDs []string `json:"propertyIds"`
+	Count       int      `json:"count"`
+	Reason      string   `json:"reason,omitempty"`
+	FabricTxID  string   `json:"fabricTxId"`
+	Timestamp   string   `json:"timestamp"`
+	StateCode   string   `json:"stateCode"`
+	ChannelID   string   `json:"channelId"`
+}
+
+// TransferScheduledEvent is emitted when a signed transfer is scheduled
+// for execution at a later time.
+type TransferScheduledEvent struct {
+	Type         string `json:"type"`
+	TransferID   string `json:"transferId"`
+	PropertyID   string `json:"propertyId"`
+	ExecuteAfter string `json:"executeAfter"`
+	ScheduledBy  string `json:"scheduledBy"`
 	FabricTxID   string `json:"fabricTxId"`
 	Timestamp    string `json:"timestamp"`
 	StateCode    string `json:"stateCode"`
 	ChannelID    string `json:"channelId"`
 }
 
+// GovernmentApprovalEvent is emitted when an official approves a
+// transfer of government land.
+type GovernmentApprovalEvent struct {
+	Type        string `json:"type"`
+	TransferID  string `json:"transferId"`
+	PropertyID  string `json:"propertyId"`
+	Designation string `json:"designation"`
+	OrderRef    string `json:"orderRef"`
+	ApproverID  string `json:"approverId"`
+	Approved    int    `json:"approved"`
+	Required    int    `json:"required"`
+	FabricTxID  string `json:"fabricTxId"`
+	Timestamp   string `json:"timestamp"`
+	StateCode   string `json:"stateCode"`
+	ChannelID   string `json:"channelId"`
+}
+
+// IndexRepairedEvent is emitted when RepairIndexes has applied a repair
+// request.
+type IndexRepairedEvent struct {
+	Type       string `json:"type"`
+	RepairID   string `json:"repairId"`
+	Applied    int    `json:"applied"`
+	Skipped    int    `json:"skipped"`
+	RepairedBy string `json:"repairedBy"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
+// SettingsChangedEvent is emitted when a state's registry settings
+// change. Type is SETTINGS_CHANGED, or SETTINGS_CHANGE_PROPOSED when the
+// change awaits a second admin's approval.
+type SettingsChangedEvent struct {
+	Type       string          `json:"type"`
+	ProposalID string          `json:"proposalId,omitempty"`
+	Changes    []SettingChange `json:"changes"`
+	ChangedBy  string          `json:"changedBy"`
+	ApprovedBy string          `json:"approvedBy,omitempty"`
+	FabricTxID string          `json:"fabricTxId"`
+	Timestamp  string          `json:"timestamp"`
+	StateCode  string          `json:"stateCode"`
+	ChannelID  string          `json:"channelId"`
+}
+
+// POAEvent is emitted when a power of attorney is registered
+// (POA_REGISTERED) or revoked (POA_REVOKED, with Reason).
+type POAEvent struct {
+	Type          string   `json:"type"`
+	POAID         string   `json:"poaId"`
+	PrincipalHash string   `json:"principalHash"`
+	AttorneyHash  string   `json:"attorneyHash"`
+	Scope         string   `json:"scope"`
+	PropertyIDs   []string `json:"propertyIds,omitempty"`
+	ValidUntil    string   `json:"validUntil"`
+	Reason        string   `json:"reason,omitempty"`
+	FabricTxID    string   `json:"fabricTxId"`
+	Timestamp     string   `json:"timestamp"`
+	ChannelID     string   `json:"channelId"`
+}
+
+// WillEvent is emitted when a will is registered (WILL_REGISTERED) or
+// revoked (WILL_REVOKED, with Reason).
+type WillEvent struct {
+	Type             string   `json:"type"`
+	WillID           string   `json:"willId"`
+	TestatorHash     string   `json:"testatorHash"`
+	PropertyIDs      []string `json:"propertyIds"`
+	WillDocumentHash string   `json:"willDocumentHash"`
+	SupersedesWillID string   `json:"supersedesWillId,omitempty"`
+	Reason           string   `json:"reason,omitempty"`
+	FabricTxID       string   `json:"fabricTxId"`
+	Timestamp        string   `json:"timestamp"`
+	StateCode        string   `json:"stateCode"`
+	ChannelID        string   `json:"channelId"`
+}
+
+// ProbateAppliedEvent is emitted when a court's probate of a will has
+// passed the testator's properties to the beneficiaries.
+type ProbateAppliedEvent struct {
+	Type            string       `json:"type"`
+	WillID          string       `json:"willId"`
+	TestatorHash    string       `json:"testatorHash"`
+	ProbateOrderRef string       `json:"probateOrderRef"`
+	PropertyIDs     []string     `json:"propertyIds"`
+	MutationIDs     []string     `json:"mutationIds"`
+	Beneficiaries   []OwnerShare `json:"beneficiaries"`
+	FabricTxID      string       `json:"fabricTxId"`
+	Timestamp       string       `json:"timestamp"`
+	StateCode       string       `json:"stateCode"`
+	ChannelID       string       `json:"channelId"`
+}
+
 // EncumbranceEvent is emitted when an encumbrance (mortgage, lien)
 // is added to or released from a property.
 type EncumbranceEvent struct {
@@ -62,10 +355,13 @@ type DisputeEvent struct {
 	DisputeID   string `json:"disputeId"`
 	PropertyID  string `json:"propertyId"`
 	DisputeType string `json:"disputeType"`
-	FabricTxID  string `json:"fabricTxId"`
-	Timestamp   string `json:"timestamp"`
-	StateCode   string `json:"stateCode"`
-	ChannelID   string `json:"channelId"`
+	// CompensationRecordID is set when the dispute is over acquisition
+	// compensation rather than the property.
+	CompensationRecordID string `json:"compensationRecordId,omitempty"`
+	FabricTxID           string `json:"fabricTxId"`
+	Timestamp            string `json:"timestamp"`
+	StateCode            string `json:"stateCode"`
+	ChannelID            string `json:"channelId"`
 }
 
 // MutationEvent is emitted when a mutation (revenue record update)
@@ -79,6 +375,11 @@ type MutationEvent struct {
 	Timestamp    string `json:"timestamp"`
 	StateCode    string `json:"stateCode"`
 	ChannelID    string `json:"channelId"`
+	// PreviousOwners, NewOwners and RecordHash are set when the mutation
+	// changes ownership, as on TransferEvent.
+	PreviousOwners []OwnerShare `json:"previousOwners,omitempty"`
+	NewOwners      []OwnerShare `json:"newOwners,omitempty"`
+	RecordHash     string       `json:"recordHash,omitempty"`
 }
 
 // PropertyFrozenEvent is emitted when a property is frozen or
@@ -101,22 +402,30 @@ type LandUseChangedEvent struct {
 	OldLandUse  string `json:"oldLandUse"`
 	NewLandUse  string `json:"newLandUse"`
 	ApprovalRef string `json:"approvalRef"`
-	FabricTxID  string `json:"fabricTxId"`
-	Timestamp   string `json:"timestamp"`
-	StateCode   string `json:"stateCode"`
-	ChannelID   string `json:"channelId"`
+	// ChargePaid is the conversion charge paid, in paisa, where the
+	// state requires one.
+	ChargePaid int64 `json:"chargePaid,omitempty"`
+	// DiversionClearanceRef is the diversion approval for a change away
+	// from a restricted land use.
+	DiversionClearanceRef string `json:"diversionClearanceRef,omitempty"`
+	FabricTxID            string `json:"fabricTxId"`
+	Timestamp             string `json:"timestamp"`
+	StateCode             string `json:"stateCode"`
+	ChannelID             string `json:"channelId"`
 }
 
 // PropertySplitEvent is emitted when a property is subdivided into
 // multiple smaller plots.
 type PropertySplitEvent struct {
-	Type              string   `json:"type"`
-	OriginalProperty  string   `json:"originalPropertyId"`
-	NewPropertyIDs    []string `json:"newPropertyIds"`
-	FabricTxID        string   `json:"fabricTxId"`
-	Timestamp         string   `json:"timestamp"`
-	StateCode         string   `json:"stateCode"`
-	ChannelID         string   `json:"channelId"`
+	Type             string   `json:"type"`
+	OriginalProperty string   `json:"originalPropertyId"`
+	NewPropertyIDs   []string `json:"newPropertyIds"`
+	// EncumbrancesCarried lists each charge cloned onto a successor.
+	EncumbrancesCarried []EncumbranceCarriedEvent `json:"encumbrancesCarried,omitempty"`
+	FabricTxID          string                    `json:"fabricTxId"`
+	Timestamp           string                    `json:"timestamp"`
+	StateCode           string                    `json:"stateCode"`
+	ChannelID           string                    `json:"channelId"`
 }
 
 // PropertyMergeEvent is emitted when multiple properties are merged
@@ -125,10 +434,84 @@ type PropertyMergeEvent struct {
 	Type              string   `json:"type"`
 	SourcePropertyIDs []string `json:"sourcePropertyIds"`
 	MergedPropertyID  string   `json:"mergedPropertyId"`
-	FabricTxID        string   `json:"fabricTxId"`
-	Timestamp         string   `json:"timestamp"`
-	StateCode         string   `json:"stateCode"`
-	ChannelID         string   `json:"channelId"`
+	// EncumbrancesCarried lists each charge cloned onto a successor.
+	EncumbrancesCarried []EncumbranceCarriedEvent `json:"encumbrancesCarried,omitempty"`
+	FabricTxID          string                    `json:"fabricTxId"`
+	Timestamp           string                    `json:"timestamp"`
+	StateCode           string                    `json:"stateCode"`
+	ChannelID           string                    `json:"channelId"`
+}
+
+// PropertyPartitionedEvent is emitted when a co-owned property is
+// partitioned among its owners.
+type PropertyPartitionedEvent struct {
+	Type               string               `json:"type"`
+	OriginalPropertyID string               `json:"originalPropertyId"`
+	PartitionDeedHash  string               `json:"partitionDeedHash"`
+	NewPropertyIDs     []string             `json:"newPropertyIds"`
+	Allotments         []PartitionAllotment `json:"allotments"`
+	FabricTxID         string               `json:"fabricTxId"`
+	Timestamp          string               `json:"timestamp"`
+	StateCode          string               `json:"stateCode"`
+	ChannelID          string               `json:"channelId"`
+}
+
+// SubdivisionProposalEvent is emitted when a split or merge is proposed
+// (SPLIT_PROPOSED, MERGE_PROPOSED), sanctioned (SPLIT_SANCTIONED,
+// MERGE_SANCTIONED) or rejected (SPLIT_REJECTED, MERGE_REJECTED).
+// ResultPropertyIDs lists the properties created on sanction.
+type SubdivisionProposalEvent struct {
+	Type                string                    `json:"type"`
+	ProposalID          string                    `json:"proposalId"`
+	ProposalType        string                    `json:"proposalType"`
+	PropertyIDs         []string                  `json:"propertyIds"`
+	ResultPropertyIDs   []string                  `json:"resultPropertyIds,omitempty"`
+	RejectedReason      string                    `json:"rejectedReason,omitempty"`
+	EncumbrancesCarried []EncumbranceCarriedEvent `json:"encumbrancesCarried,omitempty"`
+	FabricTxID          string                    `json:"fabricTxId"`
+	Timestamp           string                    `json:"timestamp"`
+	StateCode           string                    `json:"stateCode"`
+	ChannelID           string                    `json:"channelId"`
+}
+
+// SplitRevertedEvent is emitted when a split is undone: the children
+// are cancelled and the original property is active again.
+type SplitRevertedEvent struct {
+	Type               string   `json:"type"`
+	OriginalPropertyID string   `json:"originalPropertyId"`
+	CancelledIDs       []string `json:"cancelledPropertyIds"`
+	Reason             string   `json:"reason"`
+	FabricTxID         string   `json:"fabricTxId"`
+	Timestamp          string   `json:"timestamp"`
+	StateCode          string   `json:"stateCode"`
+	ChannelID          string   `json:"channelId"`
+}
+
+// PartitionAllotment assigns one parent owner to the child they received.
+type PartitionAllotment struct {
+	OwnerHash     string `json:"ownerHash"`
+	NewPropertyID string `json:"newPropertyId"`
+	MutationID    string `json:"mutationId"`
+}
+
+// EncumbranceCarriedEvent describes one encumbrance cloned onto a
+// successor parcel by a split or merge, so the holder can update its
+// collateral reference. Fabric keeps only one chaincode event per
+// transaction, so these travel inside PROPERTY_SPLIT and PROPERTY_MERGED.
+type EncumbranceCarriedEvent struct {
+	Type                  string `json:"type"`
+	EncumbranceID         string `json:"encumbranceId"`
+	OriginalEncumbranceID string `json:"originalEncumbranceId"`
+	FromPropertyID        string `json:"fromPropertyId"`
+	ToPropertyID          string `json:"toPropertyId"`
+	EncumbranceType       string `json:"encumbranceType"`
+	InstitutionName       string `json:"institutionName"`
+	InstitutionMspID      string `json:"institutionMspId"`
+	ConsentRef            string `json:"consentRef"`
+	FabricTxID            string `json:"fabricTxId"`
+	Timestamp             string `json:"timestamp"`
+	StateCode             string `json:"stateCode"`
+	ChannelID             string `json:"channelId"`
 }
 
 // AnchorRecordedEvent is emitted when a state root is anchored to
@@ -144,21 +527,519 @@ type AnchorRecordedEvent struct {
 	ChannelID     string `json:"channelId"`
 }
 
+// AnchorAttemptEvent is emitted when an anchor attempt begins
+// (ANCHOR_ATTEMPT_STARTED) or is aborted (ANCHOR_ATTEMPT_FAILED).
+type AnchorAttemptEvent struct {
+	Type          string `json:"type"`
+	AttemptID     string `json:"attemptId"`
+	StateCode     string `json:"stateCode"`
+	StateRoot     string `json:"stateRoot"`
+	FailureReason string `json:"failureReason,omitempty"`
+	FabricTxID    string `json:"fabricTxId"`
+	Timestamp     string `json:"timestamp"`
+	ChannelID     string `json:"channelId"`
+}
+
+// PropertyCorrectedEvent is emitted when clerical details of a property
+// are corrected under a correction order.
+type PropertyCorrectedEvent struct {
+	Type         string        `json:"type"`
+	PropertyID   string        `json:"propertyId"`
+	CorrectionID string        `json:"correctionId"`
+	Changes      []FieldChange `json:"changes"`
+	Reason       string        `json:"reason"`
+	ApprovalRef  string        `json:"approvalRef"`
+	// BoundaryConflicts lists conflicts detected against other parcels.
+	BoundaryConflicts []string `json:"boundaryConflicts,omitempty"`
+	FabricTxID        string   `json:"fabricTxId"`
+	Timestamp         string   `json:"timestamp"`
+	StateCode         string   `json:"stateCode"`
+	ChannelID         string   `json:"channelId"`
+}
+
+// OwnerNameCorrectedEvent is emitted when the spelling of an owner's
+// name is corrected without a change of ownership.
+type OwnerNameCorrectedEvent struct {
+	Type              string `json:"type"`
+	PropertyID        string `json:"propertyId"`
+	CorrectionID      string `json:"correctionId"`
+	OwnerHash         string `json:"ownerHash"`
+	OldName           string `json:"oldName"`
+	NewName           string `json:"newName"`
+	SupportingDocHash string `json:"supportingDocHash"`
+	FabricTxID        string `json:"fabricTxId"`
+	Timestamp         string `json:"timestamp"`
+	StateCode         string `json:"stateCode"`
+	ChannelID         string `json:"channelId"`
+}
+
+// OwnerAttainedMajorityEvent is emitted when a minor owner turns 18 and
+// their guardian is released.
+type OwnerAttainedMajorityEvent struct {
+	Type         string `json:"type"`
+	PropertyID   string `json:"propertyId"`
+	CorrectionID string `json:"correctionId"`
+	OwnerHash    string `json:"ownerHash"`
+	ProofDocHash string `json:"proofDocHash"`
+	FabricTxID   string `json:"fabricTxId"`
+	Timestamp    string `json:"timestamp"`
+	StateCode    string `json:"stateCode"`
+	ChannelID    string `json:"channelId"`
+}
+
+// CoOwnershipChangedEvent is emitted when a co-owner is added or a
+// share is released under a registered family arrangement deed.
+type CoOwnershipChangedEvent struct {
+	Type            string `json:"type"`
+	ChangeType      string `json:"changeType"`
+	PropertyID      string `json:"propertyId"`
+	MutationID      string `json:"mutationId"`
+	FromOwnerHash   string `json:"fromOwnerHash"`
+	ToOwnerHash     string `json:"toOwnerHash"`
+	SharePercentage int    `json:"sharePercentage"`
+	DeedHash        string `json:"deedHash"`
+	FabricTxID      string `json:"fabricTxId"`
+	Timestamp       string `json:"timestamp"`
+	StateCode       string `json:"stateCode"`
+	ChannelID       string `json:"channelId"`
+}
+
+// PropertyDocumentAddedEvent is emitted when a document is attached to
+// a property.
+type PropertyDocumentAddedEvent struct {
+	Type       string `json:"type"`
+	PropertyID string `json:"propertyId"`
+	DocumentID string `json:"documentId"`
+	DocType    string `json:"docType"`
+	Hash       string `json:"hash"`
+	Supersedes string `json:"supersedes,omitempty"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
+// BulkRegisteredEvent is emitted once per RegisterBulk transaction and
+// lists every property registered in it.
+type BulkRegisteredEvent struct {
+	Type       string                 `json:"type"`
+	Count      int                    `json:"count"`
+	Failed     int                    `json:"failed"`
+	Records    []BulkRegisteredRecord `json:"records"`
+	FabricTxID string                 `json:"fabricTxId"`
+	Timestamp  string                 `json:"timestamp"`
+	StateCode  string                 `json:"stateCode"`
+	ChannelID  string                 `json:"channelId"`
+}
+
+// BulkRegisteredRecord identifies one property in a BulkRegisteredEvent.
+type BulkRegisteredRecord struct {
+	PropertyID   string `json:"propertyId"`
+	OwnerHash    string `json:"ownerHash"`
+	SurveyNumber string `json:"surveyNumber"`
+	StateCode    string `json:"stateCode"`
+}
+
+// PropertyArchivedEvent is emitted when a property is archived.
+type PropertyArchivedEvent struct {
+	Type       string `json:"type"`
+	PropertyID string `json:"propertyId"`
+	Reason     string `json:"reason"`
+	OrderRef   string `json:"orderRef"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
+// TaxPaymentRecordedEvent is emitted when a land revenue payment is
+// recorded against a property.
+type TaxPaymentRecordedEvent struct {
+	Type          string `json:"type"`
+	PropertyID    string `json:"propertyId"`
+	PaymentID     string `json:"paymentId"`
+	Year          string `json:"year"`
+	Amount        int64  `json:"amount"`
+	ReceiptNumber string `json:"receiptNumber"`
+	PayerHash     string `json:"payerHash,omitempty"`
+	PaidUpToYear  string `json:"paidUpToYear"`
+	FabricTxID    string `json:"fabricTxId"`
+	Timestamp     string `json:"timestamp"`
+	StateCode     string `json:"stateCode"`
+	ChannelID     string `json:"channelId"`
+}
+
+// TaxArrearsFlaggedEvent is emitted when a transfer is registered on a
+// property whose land revenue arrears reach the state's
+// TaxArrearsAlertYears. Amounts are in paisa.
+type TaxArrearsFlaggedEvent struct {
+	Type            string `json:"type"`
+	PropertyID      string `json:"propertyId"`
+	TransferID      string `json:"transferId"`
+	PaidUpToYear    string `json:"paidUpToYear"`
+	DueThroughYear  string `json:"dueThroughYear"`
+	YearsInArrears  int    `json:"yearsInArrears"`
+	AmountInArrears int64  `json:"amountInArrears"`
+	ThresholdYears  int    `json:"thresholdYears"`
+	FabricTxID      string `json:"fabricTxId"`
+	Timestamp       string `json:"timestamp"`
+	StateCode       string `json:"stateCode"`
+	ChannelID       string `json:"channelId"`
+}
+
+// TaxArrearsFlagWithdrawnEvent is emitted when a transfer that raised
+// TAX_ARREARS_FLAGGED is rolled back.
+type TaxArrearsFlagWithdrawnEvent struct {
+	Type       string `json:"type"`
+	PropertyID string `json:"propertyId"`
+	TransferID string `json:"transferId"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
+// OwnerKYCVerifiedEvent is emitted when an owner's Aadhaar is verified
+// through eKYC.
+type OwnerKYCVerifiedEvent struct {
+	Type            string `json:"type"`
+	PropertyID      string `json:"propertyId"`
+	OwnerHash       string `json:"ownerHash"`
+	PreviousStatus  string `json:"previousStatus"`
+	VerificationRef string `json:"verificationRef"`
+	VerifiedBy      string `json:"verifiedBy"`
+	FabricTxID      string `json:"fabricTxId"`
+	Timestamp       string `json:"timestamp"`
+	StateCode       string `json:"stateCode"`
+	ChannelID       string `json:"channelId"`
+}
+
+// RoleHierarchyChangedEvent is emitted when SetRegistrySettings changes
+// a state's role hierarchy.
+type RoleHierarchyChangedEvent struct {
+	Type              string              `json:"type"`
+	RoleHierarchy     map[string][]string `json:"roleHierarchy"`
+	PreviousHierarchy map[string][]string `json:"previousHierarchy"`
+	ChangedBy         string              `json:"changedBy"`
+	FabricTxID        string              `json:"fabricTxId"`
+	Timestamp         string              `json:"timestamp"`
+	StateCode         string              `json:"stateCode"`
+	ChannelID         string              `json:"channelId"`
+}
+
+// DelegationEvent is emitted when a delegation is granted or revoked.
+// Type is DELEGATION_GRANTED or DELEGATION_REVOKED.
+type DelegationEvent struct {
+	Type                 string `json:"type"`
+	DelegationID         string `json:"delegationId"`
+	DelegatorID          string `json:"delegatorId"`
+	DelegateMspID        string `json:"delegateMspId"`
+	DelegateEnrollmentID string `json:"delegateEnrollmentId"`
+	Role                 string `json:"role"`
+	ExpiresAt            string `json:"expiresAt"`
+	Reason               string `json:"reason,omitempty"`
+	FabricTxID           string `json:"fabricTxId"`
+	Timestamp            string `json:"timestamp"`
+	StateCode            string `json:"stateCode"`
+	ChannelID            string `json:"channelId"`
+}
+
+// DistrictOrgReassignedEvent is emitted as DISTRICT_ORG_REASSIGNED for
+// each ReassignDistrictOrg batch and as STATE_ORG_REASSIGNED, with no
+// DistrictCode, for each ReassignStateOrg batch. Updated counts the land
+// records given the new policy.
+type DistrictOrgReassignedEvent struct {
+	Type          string `json:"type"`
+	DistrictCode  string `json:"districtCode"`
+	MspID         string `json:"mspId"`
+	PreviousMspID string `json:"previousMspId"`
+	Updated       int    `json:"updated"`
+	Done          bool   `json:"done"`
+	FabricTxID    string `json:"fabricTxId"`
+	Timestamp     string `json:"timestamp"`
+	StateCode     string `json:"stateCode"`
+	ChannelID     string `json:"channelId"`
+}
+
+// IdentityBlockEvent is emitted as IDENTITY_BLOCKED when an identity is
+// blocked or a block is confirmed (Confirmed set), and as
+// IDENTITY_UNBLOCKED when it is lifted. StateCode is the acting admin's.
+type IdentityBlockEvent struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+	Reason     string `json:"reason"`
+	Confirmed  bool   `json:"confirmed"`
+	ActorID    string `json:"actorId"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
+// PropertyStatusChangedEvent is emitted by setPropertyStatus whenever a
+// land record's status changes. Every event also lists the
+// transaction's status changes under statusChanges, so consumers learn
+// of a change even when a more specific event replaces this one.
+type PropertyStatusChangedEvent struct {
+	Type       string `json:"type"`
+	PropertyID string `json:"propertyId"`
+	OldStatus  string `json:"oldStatus"`
+	NewStatus  string `json:"newStatus"`
+	Reason     string `json:"reason"`
+	Actor      string `json:"actor"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
+// PropertyStatusChange is one entry of an event's statusChanges list.
+type PropertyStatusChange struct {
+	PropertyID string `json:"propertyId"`
+	OldStatus  string `json:"oldStatus"`
+	NewStatus  string `json:"newStatus"`
+	Reason     string `json:"reason"`
+	Actor      string `json:"actor"`
+}
+
+// MutationCreatedEvent is emitted wherever a mutation record is first
+// written, for the tehsildar work queue. Mutations created alongside a
+// transfer or partition are also listed under mutationsCreated on that
+// operation's event.
+type MutationCreatedEvent struct {
+	Type         string `json:"type"`
+	MutationID   string `json:"mutationId"`
+	PropertyID   string `json:"propertyId"`
+	MutationType string `json:"mutationType"`
+	Status       string `json:"status"`
+	CreatedBy    string `json:"createdBy"`
+	FabricTxID   string `json:"fabricTxId"`
+	Timestamp    string `json:"timestamp"`
+	StateCode    string `json:"stateCode"`
+	ChannelID    string `json:"channelId"`
+}
+
+// EncumbranceUpdatedEvent is emitted when an active encumbrance's terms
+// change. Changes lists each changed field with its old and new value.
+type EncumbranceUpdatedEvent struct {
+	Type             string        `json:"type"`
+	EncumbranceID    string        `json:"encumbranceId"`
+	PropertyID       string        `json:"propertyId"`
+	InstitutionMspID string        `json:"institutionMspId"`
+	Changes          []FieldChange `json:"changes"`
+	FabricTxID       string        `json:"fabricTxId"`
+	Timestamp        string        `json:"timestamp"`
+	StateCode        string        `json:"stateCode"`
+	ChannelID        string        `json:"channelId"`
+}
+
+// CoolingPeriodEvent is emitted when a property's post-registration
+// cooling period starts (with its expiry) and when it ends (with its
+// outcome: FINALIZED, BLOCKED, EXPIRED_UNFINALIZED or ROLLED_BACK).
+type CoolingPeriodEvent struct {
+	Type       string `json:"type"`
+	PropertyID string `json:"propertyId"`
+	TransferID string `json:"transferId,omitempty"`
+	ExpiresAt  string `json:"expiresAt,omitempty"`
+	Outcome    string `json:"outcome,omitempty"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	StateCode  string `json:"stateCode"`
+	ChannelID  string `json:"channelId"`
+}
+
 // ============================================================
 // Event emission helper
 // ============================================================
 
-// emitEvent serialises the given event payload to JSON and sets it as
-// a chaincode event on the transaction stub. The eventName should be
-// one of the standard event type constants (e.g. "TRANSFER_COMPLETED",
-// "PROPERTY_REGISTERED", etc.).
+// EventPayloadVersion is the version of the event payload schema,
+// raised whenever a field of an existing event changes meaning or is
+// removed. Adding fields does not change it.
+const EventPayloadVersion = 1
+
+// EventEnvelope is added by emitEvent to every event payload under
+// "envelope". EventSequence numbers the channel's events from 1 with no
+// gaps, so a consumer that sees a jump has missed events.
+type EventEnvelope struct {
+	PayloadVersion int    `json:"payloadVersion"`
+	EventSequence  int64  `json:"eventSequence"`
+	EmittedAt      string `json:"emittedAt"`
+	FabricTxID     string `json:"fabricTxId"`
+	ChannelID      string `json:"channelId"`
+}
+
+// LandRegistryContext is the transaction context contract functions
+// receive (see main). It remembers what the transaction has emitted so
+// far (see emitEvent) and the ledger counters it has adjusted (see
+// ledgerCountersFor).
+type LandRegistryContext struct {
+	contractapi.TransactionContext
+	events   txEventState
+	counters map[string]*LedgerCounters
+}
+
+// txEventState is the event history of one transaction. related holds
+// the entries of each related-event list (see emitRelatedEvent).
+type txEventState struct {
+	lastName      string
+	lastFields    map[string]json.RawMessage
+	lastIsRelated bool
+	related       map[string][]interface{}
+}
+
+func (c *LandRegistryContext) eventState() *txEventState {
+	return &c.events
+}
+
+// eventStateOf returns the event history of ctx's transaction, or nil
+// for a context that does not keep one.
+func eventStateOf(ctx contractapi.TransactionContextInterface) *txEventState {
+	if c, ok := ctx.(interface{ eventState() *txEventState }); ok {
+		return c.eventState()
+	}
+	return nil
+}
+
+// emitEvent serialises the given event payload to JSON, adds an
+// EventEnvelope and sets it as a chaincode event on the transaction
+// stub, journaling it for GetEventsSince and GetEventDigest. The
+// eventName should be one of the standard event type constants (e.g.
+// "TRANSFER_COMPLETED", "PROPERTY_REGISTERED", etc.). The payload's own
+// fields are left as they are, and every related event of the
+// transaction so far is listed alongside them.
+//
+// Fabric delivers only the last event a transaction sets, so the last
+// call wins. By-products of an operation, such as status changes and
+// mutations, are emitted with emitRelatedEvent instead, which lists them
+// on whichever event the transaction ends up delivering.
 func emitEvent(ctx contractapi.TransactionContextInterface, eventName string, payload interface{}) error {
-	eventJSON, err := json.Marshal(payload)
+	return setChaincodeEvent(ctx, eventName, payload, false)
+}
+
+// emitRelatedEvent emits a by-product event, such as a status change or
+// a mutation created by a transfer, and appends entry to the list named
+// listName that every later event of the transaction carries. If a
+// specific event has already been emitted it is re-emitted with the
+// longer list instead of being replaced; a specific event emitted
+// afterwards replaces this one but still lists it.
+func emitRelatedEvent(ctx contractapi.TransactionContextInterface, eventName, listName string, entry, payload interface{}) error {
+	state := eventStateOf(ctx)
+	if state == nil {
+		return emitEvent(ctx, eventName, payload)
+	}
+	if state.related == nil {
+		state.related = map[string][]interface{}{}
+	}
+	state.related[listName] = append(state.related[listName], entry)
+	if state.lastName != "" && !state.lastIsRelated {
+		return setChaincodeEvent(ctx, state.lastName, nil, false)
+	}
+	return setChaincodeEvent(ctx, eventName, payload, true)
+}
+
+// setChaincodeEvent builds and sets the transaction's event. A nil
+// payload re-sets the last event with the current related lists.
+func setChaincodeEvent(ctx contractapi.TransactionContextInterface, eventName string, payload interface{}, related bool) error {
+	state := eventStateOf(ctx)
+
+	var fields map[string]json.RawMessage
+	if payload == nil && state != nil {
+		fields = state.lastFields
+	} else {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return internalError("failed to marshal event %s: %v", eventName, err)
+		}
+		if err := json.Unmarshal(payloadJSON, &fields); err != nil {
+			return internalError("event %s payload is not a JSON object: %v", eventName, err)
+		}
+	}
+	var relatedLists []string
+	if state != nil {
+		relatedLists = relatedListNames(state.related)
+		for listName, entries := range state.related {
+			listJSON, err := json.Marshal(entries)
+			if err != nil {
+				return internalError("failed to marshal %s: %v", listName, err)
+			}
+			fields[listName] = listJSON
+		}
+		if payload != nil {
+			state.lastIsRelated = related
+		}
+		state.lastName = eventName
+		state.lastFields = fields
+	}
+
+	envelope, err := nextEventEnvelope(ctx)
+	if err != nil {
+		return err
+	}
+	envelopeJSON, err := json.Marshal(envelope)
+	if err != nil {
+		return internalError("failed to marshal event envelope: %v", err)
+	}
+	fields["envelope"] = envelopeJSON
+	if err := journalEvent(ctx, envelope, eventName, fields, relatedLists); err != nil {
+		return err
+	}
+
+	eventJSON, err := json.Marshal(fields)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event %s: %v", eventName, err)
+		return internalError("failed to marshal event %s: %v", eventName, err)
+	}
+	if err := addToEventDigest(ctx, envelope, eventName, eventJSON); err != nil {
+		return err
 	}
 	if err := ctx.GetStub().SetEvent(eventName, eventJSON); err != nil {
-		return fmt.Errorf("failed to emit event %s: %v", eventName, err)
+		return internalError("failed to emit event %s: %v", eventName, err)
 	}
 	return nil
 }
+
+// nextEventEnvelope assigns the transaction's event its channel sequence
+// number. A transaction that emits more than once reuses its number
+// (see emitEvent). Every emitting transaction writes the one counter
+// key, so concurrent ones in a block fail MVCC validation and must be
+// resubmitted; the backend's FabricService.submitTransaction does so.
+func nextEventEnvelope(ctx contractapi.TransactionContextInterface) (*EventEnvelope, error) {
+	stub := ctx.GetStub()
+	channelID := stub.GetChannelID()
+	txID := stub.GetTxID()
+
+	key, err := stub.CreateCompositeKey(KeyPrefixEventSequence, []string{channelID})
+	if err != nil {
+		return nil, internalError("failed to create event sequence key: %v", err)
+	}
+	counterBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read event sequence: %v", err)
+	}
+	var counter EventSequenceCounter
+	if counterBytes != nil {
+		if err := json.Unmarshal(counterBytes, &counter); err != nil {
+			return nil, internalError("failed to unmarshal event sequence: %v", err)
+		}
+	}
+	if counter.FabricTxID != txID {
+		counter.DocType = "eventSequence"
+		counter.Sequence++
+		counter.FabricTxID = txID
+		counterBytes, err = canonicalMarshal(counter)
+		if err != nil {
+			return nil, internalError("failed to marshal event sequence: %v", err)
+		}
+		if err := stub.PutState(key, counterBytes); err != nil {
+			return nil, internalError("failed to write event sequence: %v", err)
+		}
+	}
+
+	timestamp, _ := stub.GetTxTimestamp()
+	return &EventEnvelope{
+		PayloadVersion: EventPayloadVersion,
+		EventSequence:  counter.Sequence,
+		EmittedAt:      time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		FabricTxID:     txID,
+		ChannelID:      channelID,
+	}, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// stringField returns the string value of fields[name], or "".
+func stringField(t *testing.T, fields map[string]json.RawMessage, name string) string {
+	t.Helper()
+	var value string
+	if raw, ok := fields[name]; ok {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			t.Fatalf("field %s = %s is not a string", name, raw)
+		}
+	}
+	return value
+}
+
+// expectEnvelope fails the test unless fields carry an event envelope
+// for the ledger's last transaction.
+func expectEnvelope(t *testing.T, ledger *testLedger, fields map[string]json.RawMessage) {
+	t.Helper()
+	var envelope EventEnvelope
+	if err := json.Unmarshal(fields["envelope"], &envelope); err != nil {
+		t.Fatalf("envelope %s: %v", fields["envelope"], err)
+	}
+	if envelope.EventSequence == 0 || envelope.FabricTxID != testTxID(ledger.txCount) || envelope.ChannelID != "landregistry" {
+		t.Fatalf("envelope = %+v", envelope)
+	}
+}
+
+func TestExecuteTransferEmitsMutationCreated(t *testing.T) {
+	ledger, registrar, transferID, signers := signingTestTransfer(t)
+	ledger.signTestTransfer(registrar, transferID, signers...)
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer: %v", err)
+	}
+	// Fabric delivers only the last event, which must list the mutation
+	fields, last := ledger.eventFields("MUTATION_CREATED"), ledger.events[len(ledger.events)-1]
+	lastFields := ledger.eventFields(last.Name)
+	if fields == nil {
+		t.Fatal("ExecuteTransfer did not emit MUTATION_CREATED")
+	}
+	if last.Name == "MUTATION_CREATED" {
+		t.Fatal("MUTATION_CREATED replaced the transfer's own event")
+	}
+	expectEnvelope(t, ledger, fields)
+	expectEnvelope(t, ledger, lastFields)
+
+	want := map[string]string{
+		"type":         "MUTATION_CREATED",
+		"propertyId":   ledger.readTransfer(transferID).PropertyID,
+		"mutationType": "SALE",
+		"status":       "AUTO_APPROVED",
+		"createdBy":    ledger.callerIDOf(registrar),
+		"stateCode":    "TS",
+	}
+	for name, value := range want {
+		if got := stringField(t, fields, name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	mutationID := stringField(t, fields, "mutationId")
+	if mutationID == "" {
+		t.Fatal("MUTATION_CREATED has no mutationId")
+	}
+	var listed []MutationCreatedEvent
+	if err := json.Unmarshal(lastFields["mutationsCreated"], &listed); err != nil {
+		t.Fatalf("%s mutationsCreated: %v", last.Name, err)
+	}
+	if len(listed) != 1 || listed[0].MutationID != mutationID {
+		t.Fatalf("%s mutationsCreated = %+v, want %s", last.Name, listed, mutationID)
+	}
+}
+
+func TestUpdateEncumbranceOutstandingEmitsEncumbranceUpdated(t *testing.T) {
+	ledger := newTestLedger(t)
+	property := testProperty("142", 1)
+	ledger.registerTestProperty(newTestIdentity(t, "TelanganaMSP", "registrar", "TS"), property)
+	encumbranceID := ledger.mortgageTestProperty(property.PropertyID)
+	bank := newTestIdentity(t, "SBIMSP", "bank", "TS")
+	update := func(id *testIdentity, amount int64) error {
+		return ledger.submit(id, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.UpdateEncumbranceOutstanding(ctx, encumbranceID, amount)
+		})
+	}
+
+	if err := update(bank, 250000000); err != nil {
+		t.Fatalf("UpdateEncumbranceOutstanding: %v", err)
+	}
+	if len(ledger.events) != 1 || ledger.events[0].Name != "ENCUMBRANCE_UPDATED" {
+		t.Fatalf("events = %+v, want one ENCUMBRANCE_UPDATED", ledger.events)
+	}
+	var event EncumbranceUpdatedEvent
+	if err := json.Unmarshal(ledger.events[0].Payload, &event); err != nil {
+		t.Fatalf("unmarshal ENCUMBRANCE_UPDATED: %v", err)
+	}
+	wantChange := FieldChange{Field: "details.outstandingAmount", OldValue: "0", NewValue: "250000000"}
+	if event.EncumbranceID != encumbranceID || event.PropertyID != property.PropertyID || event.InstitutionMspID != "SBIMSP" ||
+		len(event.Changes) != 1 || event.Changes[0] != wantChange || event.StateCode != "TS" {
+		t.Fatalf("ENCUMBRANCE_UPDATED = %+v", event)
+	}
+	expectEnvelope(t, ledger, ledger.eventFields("ENCUMBRANCE_UPDATED"))
+
+	// An unchanged amount writes and emits nothing
+	if err := update(bank, 250000000); err != nil {
+		t.Fatalf("UpdateEncumbranceOutstanding: %v", err)
+	}
+	if len(ledger.events) != 0 {
+		t.Fatalf("unchanged amount emitted %+v", ledger.events)
+	}
+	// Another bank cannot touch the charge
+	expectCode(t, update(newTestIdentity(t, "HDFCMSP", "bank", "TS"), 0), ErrCodeAccessDenied)
+	if got := ledger.readEncumbrances(property.PropertyID)[0].Details.OutstandingAmount; got != 250000000 {
+		t.Fatalf("outstandingAmount = %d", got)
+	}
+}
+
+func TestRenewCropLoanEmitsEncumbranceUpdated(t *testing.T) {
+	ledger := newTestLedger(t)
+	property := testProperty("142", 1)
+	property.LandUse = "AGRICULTURAL"
+	ledger.registerTestProperty(newTestIdentity(t, "TelanganaMSP", "registrar", "TS"), property)
+	bank := newTestIdentity(t, "SBIMSP", "bank", "TS")
+
+	loan := EncumbranceRecord{
+		PropertyID:  property.PropertyID,
+		Type:        "CROP_LOAN",
+		Institution: Institution{Name: "State Bank of India"},
+		Details:     EncumbranceDetails{SanctionedAmount: 30000000, EndDate: "2027-06-30"},
+		CropLoan:    &CropLoanDetails{CultivatorHash: testAadhaarHash(1), Season: "RABI", Year: 2026, Crop: "Paddy", LimitAmount: 30000000},
+	}
+	loanJSON, _ := json.Marshal(loan)
+	ledger.mustSubmit(bank, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := ledger.contract.AddEncumbrance(ctx, string(loanJSON))
+		return err
+	})
+	encumbranceID := ledger.readEncumbrances(property.PropertyID)[0].EncumbranceID
+
+	renewalJSON, _ := json.Marshal(CropLoanRenewal{Season: "KHARIF", Year: 2027, Crop: "Cotton", LimitAmount: 40000000, SeasonEndDate: "2027-11-30"})
+	ledger.mustSubmit(bank, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.RenewCropLoan(ctx, encumbranceID, string(renewalJSON))
+	})
+	var event EncumbranceUpdatedEvent
+	if len(ledger.events) != 1 || ledger.events[0].Name != "ENCUMBRANCE_UPDATED" {
+		t.Fatalf("events = %+v, want one ENCUMBRANCE_UPDATED", ledger.events)
+	}
+	if err := json.Unmarshal(ledger.events[0].Payload, &event); err != nil {
+		t.Fatalf("unmarshal ENCUMBRANCE_UPDATED: %v", err)
+	}
+	wantChanges := []FieldChange{
+		{Field: "cropLoan.season", OldValue: "RABI 2026", NewValue: "KHARIF 2027"},
+		{Field: "details.endDate", OldValue: "2027-06-30", NewValue: "2027-11-30"},
+		{Field: "cropLoan.crop", OldValue: "Paddy", NewValue: "Cotton"},
+		{Field: "cropLoan.limitAmount", OldValue: "30000000", NewValue: "40000000"},
+	}
+	if event.EncumbranceID != encumbranceID || event.PropertyID != property.PropertyID || len(event.Changes) != len(wantChanges) {
+		t.Fatalf("ENCUMBRANCE_UPDATED = %+v", event)
+	}
+	for i, change := range wantChanges {
+		if event.Changes[i] != change {
+			t.Errorf("change %d = %+v, want %+v", i, event.Changes[i], change)
+		}
+	}
+	expectEnvelope(t, ledger, ledger.eventFields("ENCUMBRANCE_UPDATED"))
+}
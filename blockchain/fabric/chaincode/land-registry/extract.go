@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// VILLAGE EXTRACT (KHASRA / KHATAUNI)
+// ============================================================
+// Revenue officers work from village registers: the khatauni lists
+// every holding in a village grouped by owner, with each parcel's area
+// and classification. GenerateVillageExtract builds one page of it from
+// the location index, so large villages are read in several calls.
+
+// maxExtractPageSize caps GenerateVillageExtract pages.
+const maxExtractPageSize = 1000
+
+// GenerateVillageExtract returns a page of the village extract: the
+// live parcels among the next pageSize entries of the village's location
+// index, grouped by primary owner (the first listed) with areas in
+// square meters. SPLIT, MERGED and ARCHIVED records are left out. Pass
+// an empty bookmark for the first page and the returned bookmark for the
+// next; a holder whose parcels span pages appears on each of them.
+// ExtractHash covers everything in the page but itself and AsOf, so a
+// printed page can be checked against a regenerated one. Only
+// tehsildars, registrars and admins with jurisdiction over the village
+// can generate extracts.
+func (s *LandRegistryContract) GenerateVillageExtract(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode, villageCode string, pageSize int, bookmark string) (*VillageExtract, error) {
+	if _, err := requireAnyRole(ctx, "tehsildar", "registrar", "admin"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" || districtCode == "" || tehsilCode == "" || villageCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode, districtCode, tehsilCode and villageCode are required")
+	}
+	if pageSize <= 0 || pageSize > maxExtractPageSize {
+		return nil, newError(ErrCodeValidationError, "pageSize must be between 1 and %d", maxExtractPageSize)
+	}
+	location := Location{StateCode: stateCode, DistrictCode: districtCode, TehsilCode: tehsilCode, VillageCode: villageCode}
+	if err := requireJurisdiction(ctx, location); err != nil {
+		return nil, err
+	}
+	settings, err := getSettings(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(KeyPrefixLocationIndex,
+		[]string{stateCode, districtCode, tehsilCode, villageCode}, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, internalError("failed to query location index: %v", err)
+	}
+	defer iterator.Close()
+
+	extract := &VillageExtract{
+		StateCode:    stateCode,
+		DistrictCode: districtCode,
+		TehsilCode:   tehsilCode,
+		VillageCode:  villageCode,
+		Holdings:     []*VillageHolding{},
+	}
+	holdings := map[string]*VillageHolding{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate location index: %v", err)
+		}
+		property, err := readLandRecord(ctx, string(kv.Value))
+		if err != nil {
+			return nil, err
+		}
+		if property.Status == "SPLIT" || property.Status == "MERGED" || property.Status == "ARCHIVED" {
+			continue
+		}
+		if extract.VillageName == "" {
+			extract.VillageName = property.Location.VillageName
+		}
+		areaSqM, err := ConvertArea(property.Area.Value, property.Area.Unit, AreaUnitSqMeters, settings.BighaSqMeters)
+		if err != nil {
+			return nil, errorAt(property.PropertyID, err)
+		}
+
+		ownerHash, ownerName := "", ""
+		if len(property.CurrentOwner.Owners) > 0 {
+			ownerHash = property.CurrentOwner.Owners[0].AadhaarHash
+			ownerName = property.CurrentOwner.Owners[0].Name
+		}
+		holding, ok := holdings[ownerHash]
+		if !ok {
+			holding = &VillageHolding{OwnerHash: ownerHash, OwnerName: ownerName, Parcels: []ExtractParcel{}}
+			holdings[ownerHash] = holding
+			extract.Holdings = append(extract.Holdings, holding)
+		}
+		holding.Parcels = append(holding.Parcels, ExtractParcel{
+			PropertyID:         property.PropertyID,
+			SurveyNumber:       property.SurveyNumber,
+			SubSurveyNumber:    property.SubSurveyNumber,
+			AreaSqM:            areaSqM,
+			LandUse:            property.LandUse,
+			LandClassification: property.LandClassification,
+			OwnerCount:         len(property.CurrentOwner.Owners),
+			Disputed:           property.DisputeStatus == "DISPUTED",
+			Encumbered:         property.EncumbranceStatus == "ENCUMBERED",
+		})
+		holding.TotalAreaSqM += areaSqM
+		extract.ParcelCount++
+		extract.TotalAreaSqM += areaSqM
+	}
+	sort.SliceStable(extract.Holdings, func(i, j int) bool {
+		return extract.Holdings[i].OwnerHash < extract.Holdings[j].OwnerHash
+	})
+	if int(metadata.FetchedRecordsCount) == pageSize {
+		extract.Bookmark = metadata.Bookmark
+	}
+
+	extractBytes, err := canonicalMarshal(extract)
+	if err != nil {
+		return nil, internalError("failed to marshal village extract: %v", err)
+	}
+	sum := sha256.Sum256(extractBytes)
+	extract.ExtractHash = "sha256:" + hex.EncodeToString(sum[:])
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	extract.AsOf = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	return extract, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+)
+
+// ============================================================
+// FRAGMENTATION LIMITS
+// ============================================================
+// States bar splitting agricultural land into uneconomic holdings, and
+// building bylaws set minimum residential plot sizes. Both are
+// configured per state in RegistrySettings; unconfigured states have no
+// limits.
+
+// validateSplitLimits checks a split against the state's maximum number
+// of children and the minimum plot size for the parent's land use. A
+// child below the minimum needs an exemption order reference.
+func validateSplitLimits(property *LandRecord, splits []SplitRequest, settings *RegistrySettings) error {
+	if settings.MaxSplitChildren > 0 && len(splits) > settings.MaxSplitChildren {
+		return newError(ErrCodeSplitTooManyChildren, "%d sub-plots exceeds the limit of %d in state %s",
+			len(splits), settings.MaxSplitChildren, property.Location.StateCode)
+	}
+
+	minSqM := settings.MinPlotSizeSqM[property.LandUse]
+	if minSqM <= 0 {
+		return nil
+	}
+	for i, split := range splits {
+		if split.ExemptionOrderRef != "" {
+			continue
+		}
+		areaSqM, err := ConvertArea(split.Area.Value, split.Area.Unit, AreaUnitSqMeters, settings.BighaSqMeters)
+		if err != nil {
+			return errorAt(fmt.Sprintf("split[%d]", i), err)
+		}
+		if areaSqM < minSqM {
+			return newError(ErrCodeSplitBelowMinPlot, "split[%d]: %s is %.2f sq m, below the %.2f sq m minimum for %s land; an exemptionOrderRef is required",
+				i, split.NewPropertyID, areaSqM, minSqM, property.LandUse)
+		}
+	}
+	return nil
+}
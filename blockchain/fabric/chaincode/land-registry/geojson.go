@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// ============================================================
+// GEOJSON BOUNDARY VALIDATION
+// ============================================================
+
+// India's bounding box, including the island territories. Boundaries
+// outside it are data-entry errors (swapped lat/lon, wrong datum).
+const (
+	indiaMinLongitude = 68.0
+	indiaMaxLongitude = 97.5
+	indiaMinLatitude  = 6.0
+	indiaMaxLatitude  = 37.5
+)
+
+// validateGeoJSON checks a property boundary geometry. GeoJSON is
+// optional: an empty geometry passes. Otherwise Type must be "Polygon"
+// or "MultiPolygon", and every linear ring must have at least 4
+// positions, be closed (first position equals last) and contain only
+// finite [longitude, latitude] pairs inside India's bounding box.
+// Errors name the offending vertex and its coordinates.
+func validateGeoJSON(geo GeoJSON) error {
+	raw := string(geo.Coordinates)
+	if geo.Type == "" && (raw == "" || raw == "null") {
+		return nil
+	}
+
+	var polygons [][][][]float64
+	switch geo.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(geo.Coordinates, &polygon); err != nil {
+			return newError(ErrCodeInvalidGeojson, "Polygon coordinates must be an array of linear rings: %v", err)
+		}
+		polygons = [][][][]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(geo.Coordinates, &polygons); err != nil {
+			return newError(ErrCodeInvalidGeojson, "MultiPolygon coordinates must be an array of polygons: %v", err)
+		}
+	default:
+		return newError(ErrCodeInvalidGeojson, "type must be Polygon or MultiPolygon, got '%s'", geo.Type)
+	}
+
+	if len(polygons) == 0 {
+		return newError(ErrCodeInvalidGeojson, "%s has no coordinates", geo.Type)
+	}
+	for p, polygon := range polygons {
+		if len(polygon) == 0 {
+			return newError(ErrCodeInvalidGeojson, "polygon %d has no linear rings", p)
+		}
+		for r, ring := range polygon {
+			if err := validateLinearRing(ring, p, r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateLinearRing checks one ring of polygon p.
+func validateLinearRing(ring [][]float64, p, r int) error {
+	if len(ring) < 4 {
+		return newError(ErrCodeInvalidGeojson, "polygon %d ring %d has %d positions, need at least 4", p, r, len(ring))
+	}
+	for v, pos := range ring {
+		if len(pos) < 2 {
+			return newError(ErrCodeInvalidGeojson, "polygon %d ring %d vertex %d must be [longitude, latitude], got %v", p, r, v, pos)
+		}
+		lon, lat := pos[0], pos[1]
+		if math.IsNaN(lon) || math.IsNaN(lat) || math.IsInf(lon, 0) || math.IsInf(lat, 0) {
+			return newError(ErrCodeInvalidGeojson, "polygon %d ring %d vertex %d is not finite: [%v, %v]", p, r, v, lon, lat)
+		}
+		if lon < indiaMinLongitude || lon > indiaMaxLongitude || lat < indiaMinLatitude || lat > indiaMaxLatitude {
+			return newError(ErrCodeInvalidGeojson, "polygon %d ring %d vertex %d [%v, %v] is outside India's bounding box", p, r, v, lon, lat)
+		}
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		return newError(ErrCodeInvalidGeojson, "polygon %d ring %d is not closed: first vertex [%v, %v] != last vertex [%v, %v]", p, r, first[0], first[1], last[0], last[1])
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// ============================================================
+// POLYGON GEOMETRY
+// ============================================================
+// Pure, deterministic geometry on validated GeoJSON boundaries. Every
+// endorsing peer must compute the same result, so there are no external
+// services and no map iteration in these helpers.
+
+// Metres per degree of latitude, and of longitude at the equator.
+const (
+	metersPerDegreeLat = 110574.0
+	metersPerDegreeLon = 111320.0
+)
+
+// point is a position projected to metres on a local plane.
+type point struct {
+	x, y float64
+}
+
+// boundingBox is an axis-aligned [longitude, latitude] rectangle.
+type boundingBox struct {
+	minLon, minLat, maxLon, maxLat float64
+}
+
+// intersects reports whether two bounding boxes overlap.
+func (b boundingBox) intersects(o boundingBox) bool {
+	return b.minLon <= o.maxLon && o.minLon <= b.maxLon && b.minLat <= o.maxLat && o.minLat <= b.maxLat
+}
+
+// geoJSONPolygons returns the polygons of a GeoJSON geometry that has
+// passed validateGeoJSON, as polygon → ring → [longitude, latitude].
+// An empty geometry returns nil.
+func geoJSONPolygons(geo GeoJSON) [][][][]float64 {
+	switch geo.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(geo.Coordinates, &polygon); err != nil {
+			return nil
+		}
+		return [][][][]float64{polygon}
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(geo.Coordinates, &polygons); err != nil {
+			return nil
+		}
+		return polygons
+	}
+	return nil
+}
+
+// polygonsBoundingBox returns the bounding box of the outer rings.
+func polygonsBoundingBox(polygons [][][][]float64) boundingBox {
+	box := boundingBox{minLon: math.Inf(1), minLat: math.Inf(1), maxLon: math.Inf(-1), maxLat: math.Inf(-1)}
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+		for _, pos := range polygon[0] {
+			box.minLon = math.Min(box.minLon, pos[0])
+			box.maxLon = math.Max(box.maxLon, pos[0])
+			box.minLat = math.Min(box.minLat, pos[1])
+			box.maxLat = math.Max(box.maxLat, pos[1])
+		}
+	}
+	return box
+}
+
+// polygonOverlapArea returns the area in square metres shared by two
+// (multi)polygons, holes included. Both are projected onto a local
+// equirectangular plane centred on origin, which is accurate to well
+// under 1% at parcel scale.
+//
+// Each polygon is decomposed into a fan of signed triangles whose
+// weighted indicator functions sum to the polygon's, so the overlap is
+// the weighted sum of convex triangle-triangle intersections. This
+// handles concave parcels and holes without a general clipping library.
+func polygonOverlapArea(a, b [][][][]float64, originLon, originLat float64) float64 {
+	ta := fanTriangles(a, originLon, originLat)
+	tb := fanTriangles(b, originLon, originLat)
+
+	var area float64
+	for _, t := range ta {
+		for _, u := range tb {
+			if !triangleBoxesOverlap(t.vertices, u.vertices) {
+				continue
+			}
+			area += t.weight * u.weight * convexIntersectionArea(t.vertices[:], u.vertices[:])
+		}
+	}
+	// Rounding can leave a tiny negative residue for disjoint shapes
+	if area < 0 {
+		return 0
+	}
+	return area
+}
+
+// polygonArea returns the planar area in square metres of a
+// (multi)polygon, holes excluded, on the same local plane as
+// polygonOverlapArea.
+func polygonArea(polygons [][][][]float64, originLon, originLat float64) float64 {
+	var area float64
+	for _, t := range fanTriangles(polygons, originLon, originLat) {
+		area += t.weight * signedArea(t.vertices[:])
+	}
+	if area < 0 {
+		return 0
+	}
+	return area
+}
+
+// weightedTriangle is a counter-clockwise triangle with weight ±1.
+type weightedTriangle struct {
+	vertices [3]point
+	weight   float64
+}
+
+// fanTriangles decomposes polygons into weighted fan triangles. Outer
+// rings count positive and holes negative, whatever their winding.
+func fanTriangles(polygons [][][][]float64, originLon, originLat float64) []weightedTriangle {
+	var triangles []weightedTriangle
+	for _, polygon := range polygons {
+		for r, ring := range polygon {
+			pts := projectRing(ring, originLon, originLat)
+			ringArea := signedArea(pts)
+			if ringArea == 0 {
+				continue
+			}
+			orientation := math.Copysign(1, ringArea)
+			ringWeight := 1.0
+			if r > 0 {
+				ringWeight = -1.0
+			}
+			for i := 1; i+1 < len(pts); i++ {
+				tri := [3]point{pts[0], pts[i], pts[i+1]}
+				s := signedArea(tri[:])
+				if s == 0 {
+					continue
+				}
+				if s < 0 {
+					tri[1], tri[2] = tri[2], tri[1]
+				}
+				triangles = append(triangles, weightedTriangle{
+					vertices: tri,
+					weight:   ringWeight * orientation * math.Copysign(1, s),
+				})
+			}
+		}
+	}
+	return triangles
+}
+
+// projectRing projects a closed [longitude, latitude] ring onto the
+// local plane, dropping the repeated closing position.
+func projectRing(ring [][]float64, originLon, originLat float64) []point {
+	lonScale := metersPerDegreeLon * math.Cos(originLat*math.Pi/180)
+	n := len(ring)
+	if n > 1 && ring[0][0] == ring[n-1][0] && ring[0][1] == ring[n-1][1] {
+		n--
+	}
+	pts := make([]point, 0, n)
+	for _, pos := range ring[:n] {
+		pts = append(pts, point{
+			x: (pos[0] - originLon) * lonScale,
+			y: (pos[1] - originLat) * metersPerDegreeLat,
+		})
+	}
+	return pts
+}
+
+// signedArea returns the shoelace area of a polygon, positive when its
+// vertices run counter-clockwise.
+func signedArea(pts []point) float64 {
+	var sum float64
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		sum += pts[i].x*pts[j].y - pts[j].x*pts[i].y
+	}
+	return sum / 2
+}
+
+// triangleBoxesOverlap is a cheap bounding-box rejection test.
+func triangleBoxesOverlap(t, u [3]point) bool {
+	tMinX, tMaxX := math.Min(t[0].x, math.Min(t[1].x, t[2].x)), math.Max(t[0].x, math.Max(t[1].x, t[2].x))
+	tMinY, tMaxY := math.Min(t[0].y, math.Min(t[1].y, t[2].y)), math.Max(t[0].y, math.Max(t[1].y, t[2].y))
+	uMinX, uMaxX := math.Min(u[0].x, math.Min(u[1].x, u[2].x)), math.Max(u[0].x, math.Max(u[1].x, u[2].x))
+	uMinY, uMaxY := math.Min(u[0].y, math.Min(u[1].y, u[2].y)), math.Max(u[0].y, math.Max(u[1].y, u[2].y))
+	return tMinX <= uMaxX && uMinX <= tMaxX && tMinY <= uMaxY && uMinY <= tMaxY
+}
+
+// convexIntersectionArea clips subject by clip (both convex and
+// counter-clockwise) with Sutherland–Hodgman and returns the area left.
+func convexIntersectionArea(subject, clip []point) float64 {
+	output := subject
+	for i := range clip {
+		if len(output) == 0 {
+			return 0
+		}
+		a, b := clip[i], clip[(i+1)%len(clip)]
+		input := output
+		output = make([]point, 0, len(input)+1)
+		for j := range input {
+			cur, prev := input[j], input[(j+len(input)-1)%len(input)]
+			curIn, prevIn := edgeSide(a, b, cur) >= 0, edgeSide(a, b, prev) >= 0
+			if curIn {
+				if !prevIn {
+					output = append(output, lineIntersection(prev, cur, a, b))
+				}
+				output = append(output, cur)
+			} else if prevIn {
+				output = append(output, lineIntersection(prev, cur, a, b))
+			}
+		}
+	}
+	if len(output) < 3 {
+		return 0
+	}
+	return math.Abs(signedArea(output))
+}
+
+// edgeSide is positive when p lies left of the directed edge a→b.
+func edgeSide(a, b, p point) float64 {
+	return (b.x-a.x)*(p.y-a.y) - (b.y-a.y)*(p.x-a.x)
+}
+
+// lineIntersection returns where segment p→q crosses the line a→b.
+func lineIntersection(p, q, a, b point) point {
+	sp, sq := edgeSide(a, b, p), edgeSide(a, b, q)
+	t := sp / (sp - sq)
+	return point{x: p.x + t*(q.x-p.x), y: p.y + t*(q.y-p.y)}
+}
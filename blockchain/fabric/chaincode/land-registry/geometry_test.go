@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// Test parcels sit near Gachibowli, where 0.0001° is about 11 m.
+const (
+	testLon = 78.35
+	testLat = 17.44
+)
+
+// rectRing returns the closed counter-clockwise ring of the rectangle
+// [lon0, lon1] × [lat0, lat1].
+func rectRing(lon0, lat0, lon1, lat1 float64) [][]float64 {
+	return [][]float64{{lon0, lat0}, {lon1, lat0}, {lon1, lat1}, {lon0, lat1}, {lon0, lat0}}
+}
+
+// reversedRing returns ring with its winding reversed.
+func reversedRing(ring [][]float64) [][]float64 {
+	out := make([][]float64, len(ring))
+	for i, pos := range ring {
+		out[len(ring)-1-i] = pos
+	}
+	return out
+}
+
+// rectArea returns the area in square metres of a rectangle dLon × dLat
+// degrees on the local plane at testLat.
+func rectArea(dLon, dLat float64) float64 {
+	return dLon * metersPerDegreeLon * math.Cos(testLat*math.Pi/180) * dLat * metersPerDegreeLat
+}
+
+// polygonsOf wraps rings as a single-polygon geometry.
+func polygonsOf(rings ...[][]float64) [][][][]float64 {
+	return [][][][]float64{rings}
+}
+
+// approxEqual compares areas to a millionth of a square metre.
+func approxEqual(got, want float64) bool {
+	return math.Abs(got-want) < 1e-6
+}
+
+func TestPolygonArea(t *testing.T) {
+	square := rectRing(testLon, testLat, testLon+0.001, testLat+0.001)
+	want := rectArea(0.001, 0.001)
+	if got := polygonArea(polygonsOf(square), testLon, testLat); !approxEqual(got, want) {
+		t.Errorf("square area = %f, want %f", got, want)
+	}
+	if got := polygonArea(polygonsOf(reversedRing(square)), testLon, testLat); !approxEqual(got, want) {
+		t.Errorf("clockwise square area = %f, want %f", got, want)
+	}
+
+	hole := rectRing(testLon+0.0004, testLat+0.0004, testLon+0.0006, testLat+0.0006)
+	if got, want := polygonArea(polygonsOf(square, hole), testLon, testLat), want-rectArea(0.0002, 0.0002); !approxEqual(got, want) {
+		t.Errorf("holed square area = %f, want %f", got, want)
+	}
+}
+
+func TestPolygonOverlapArea(t *testing.T) {
+	square := rectRing(testLon, testLat, testLon+0.001, testLat+0.001)
+	// The L-shape is the 0.002° square without its upper-right quarter
+	lShape := [][]float64{
+		{testLon, testLat}, {testLon + 0.002, testLat}, {testLon + 0.002, testLat + 0.001},
+		{testLon + 0.001, testLat + 0.001}, {testLon + 0.001, testLat + 0.002}, {testLon, testLat + 0.002},
+		{testLon, testLat},
+	}
+	outer := rectRing(testLon-0.001, testLat-0.001, testLon+0.002, testLat+0.002)
+	hole := rectRing(testLon, testLat, testLon+0.001, testLat+0.001)
+
+	tests := []struct {
+		name string
+		a, b [][][][]float64
+		want float64
+	}{
+		{"identical squares", polygonsOf(square), polygonsOf(square), rectArea(0.001, 0.001)},
+		{"half overlap",
+			polygonsOf(square),
+			polygonsOf(rectRing(testLon+0.0005, testLat, testLon+0.0015, testLat+0.001)),
+			rectArea(0.0005, 0.001)},
+		{"corner overlap",
+			polygonsOf(square),
+			polygonsOf(rectRing(testLon+0.0009, testLat+0.0009, testLon+0.0019, testLat+0.0019)),
+			rectArea(0.0001, 0.0001)},
+		{"shared edge",
+			polygonsOf(square),
+			polygonsOf(rectRing(testLon+0.001, testLat, testLon+0.002, testLat+0.001)),
+			0},
+		{"disjoint",
+			polygonsOf(square),
+			polygonsOf(rectRing(testLon+0.003, testLat+0.003, testLon+0.004, testLat+0.004)),
+			0},
+		{"contained",
+			polygonsOf(square),
+			polygonsOf(rectRing(testLon+0.0002, testLat+0.0002, testLon+0.0004, testLat+0.0004)),
+			rectArea(0.0002, 0.0002)},
+		{"opposite windings", polygonsOf(square), polygonsOf(reversedRing(square)), rectArea(0.001, 0.001)},
+		{"square in the notch of an L",
+			polygonsOf(lShape),
+			polygonsOf(rectRing(testLon+0.001, testLat+0.001, testLon+0.002, testLat+0.002)),
+			0},
+		{"square across the corner of an L",
+			polygonsOf(lShape),
+			polygonsOf(rectRing(testLon+0.0005, testLat+0.0005, testLon+0.0015, testLat+0.0015)),
+			rectArea(0.001, 0.001) - rectArea(0.0005, 0.0005)},
+		{"parcel inside a hole", polygonsOf(outer, hole), polygonsOf(square), 0},
+		{"parcel over a hole edge",
+			polygonsOf(outer, hole),
+			polygonsOf(rectRing(testLon+0.0005, testLat, testLon+0.0015, testLat+0.001)),
+			rectArea(0.0005, 0.001)},
+		{"multipolygon",
+			[][][][]float64{
+				{rectRing(testLon, testLat, testLon+0.001, testLat+0.001)},
+				{rectRing(testLon+0.002, testLat, testLon+0.003, testLat+0.001)},
+			},
+			polygonsOf(rectRing(testLon+0.0005, testLat, testLon+0.0025, testLat+0.001)),
+			2 * rectArea(0.0005, 0.001)},
+	}
+	for _, tc := range tests {
+		if got := polygonOverlapArea(tc.a, tc.b, testLon, testLat); !approxEqual(got, tc.want) {
+			t.Errorf("%s: overlap = %f, want %f", tc.name, got, tc.want)
+		}
+		if got := polygonOverlapArea(tc.b, tc.a, testLon, testLat); !approxEqual(got, tc.want) {
+			t.Errorf("%s (swapped): overlap = %f, want %f", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestConvexIntersectionArea(t *testing.T) {
+	unit := []point{{0, 0}, {2, 0}, {2, 2}, {0, 2}}
+	tests := []struct {
+		name string
+		clip []point
+		want float64
+	}{
+		{"offset square", []point{{1, 1}, {3, 1}, {3, 3}, {1, 3}}, 1},
+		{"disjoint", []point{{5, 5}, {6, 5}, {6, 6}}, 0},
+		{"contained triangle", []point{{0.5, 0.5}, {1.5, 0.5}, {0.5, 1.5}}, 0.5},
+		{"covering triangle", []point{{-1, -1}, {10, -1}, {-1, 10}}, 4},
+	}
+	for _, tc := range tests {
+		if got := convexIntersectionArea(unit, tc.clip); !approxEqual(got, tc.want) {
+			t.Errorf("%s: area = %f, want %f", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestBoundingBoxes(t *testing.T) {
+	a := polygonsBoundingBox(polygonsOf(rectRing(testLon, testLat, testLon+0.001, testLat+0.001)))
+	if a != (boundingBox{minLon: testLon, minLat: testLat, maxLon: testLon + 0.001, maxLat: testLat + 0.001}) {
+		t.Fatalf("bounding box = %+v", a)
+	}
+	touching := polygonsBoundingBox(polygonsOf(rectRing(testLon+0.001, testLat, testLon+0.002, testLat+0.001)))
+	apart := polygonsBoundingBox(polygonsOf(rectRing(testLon+0.0011, testLat, testLon+0.002, testLat+0.001)))
+	if !a.intersects(touching) || !touching.intersects(a) {
+		t.Error("boxes sharing an edge should intersect")
+	}
+	if a.intersects(apart) || apart.intersects(a) {
+		t.Error("separated boxes should not intersect")
+	}
+}
+
+func TestGeoJSONPolygons(t *testing.T) {
+	ring := rectRing(testLon, testLat, testLon+0.001, testLat+0.001)
+	polygonJSON, _ := json.Marshal([][][]float64{ring})
+	multiJSON, _ := json.Marshal([][][][]float64{{ring}, {ring}})
+
+	if got := geoJSONPolygons(GeoJSON{Type: "Polygon", Coordinates: polygonJSON}); len(got) != 1 || len(got[0][0]) != 5 {
+		t.Errorf("Polygon = %v", got)
+	}
+	if got := geoJSONPolygons(GeoJSON{Type: "MultiPolygon", Coordinates: multiJSON}); len(got) != 2 {
+		t.Errorf("MultiPolygon = %v", got)
+	}
+	if got := geoJSONPolygons(GeoJSON{}); got != nil {
+		t.Errorf("empty geometry = %v, want nil", got)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// GOVERNMENT LAND DISPOSAL APPROVALS
+// ============================================================
+// Government land is not disposed of on one registrar's say-so: each
+// official the state lists in GovernmentDisposalApprovers (say the
+// department head, finance and the collector) must approve the transfer
+// from their own certificate before it can be executed. The approvals,
+// in the order given, stay on the transfer's status history and go out
+// with TRANSFER_COMPLETED.
+
+// RecordGovernmentApproval records one official's approval of a transfer
+// of government-owned land. approvalJSON is a GovernmentApproval giving
+// the approver's designation, the sanction order reference and
+// optionally its document hash. The caller must hold the role the state
+// configures for that designation and carry it in their certificate's
+// "designation" attribute; each designation approves once, and no
+// certificate may approve for two designations. The transfer must be
+// INITIATED or SIGNATURES_COMPLETE. Emits GOVERNMENT_APPROVAL_RECORDED.
+func (s *LandRegistryContract) RecordGovernmentApproval(ctx contractapi.TransactionContextInterface, transferID, approvalJSON string) error {
+	var approval GovernmentApproval
+	if err := json.Unmarshal([]byte(approvalJSON), &approval); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse approval JSON: %v", err)
+	}
+	approval.Designation = strings.ToUpper(strings.TrimSpace(approval.Designation))
+	if approval.Designation == "" || strings.TrimSpace(approval.OrderRef) == "" {
+		return newError(ErrCodeValidationError, "designation and orderRef are required")
+	}
+	if approval.DocumentHash != "" {
+		if err := validateDocumentHash(approval.DocumentHash, "documentHash"); err != nil {
+			return err
+		}
+	}
+
+	transferKey, err := createTransferKey(ctx, transferID)
+	if err != nil {
+		return internalError("failed to create transfer key: %v", err)
+	}
+	transferBytes, err := ctx.GetStub().GetState(transferKey)
+	if err != nil || transferBytes == nil {
+		return newError(ErrCodeTransferNotFound, "%s", transferID)
+	}
+	var transfer TransferRecord
+	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
+		return internalError("failed to unmarshal transfer: %v", err)
+	}
+	if transfer.Status != "INITIATED" && transfer.Status != "SIGNATURES_COMPLETE" {
+		return newError(ErrCodeTransferInvalidState, "expected INITIATED or SIGNATURES_COMPLETE, got %s", transfer.Status)
+	}
+
+	property, err := readLandRecord(ctx, transfer.PropertyID)
+	if err != nil {
+		return err
+	}
+	if property.CurrentOwner.OwnerType != "GOVERNMENT" {
+		return newError(ErrCodeValidationError, "%s is not government-owned land", transfer.PropertyID)
+	}
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return err
+	}
+	approver := governmentApprover(settings, approval.Designation)
+	if approver == nil {
+		return newError(ErrCodeValidationError, "designation '%s' is not a government disposal approver in %s", approval.Designation, property.Location.StateCode)
+	}
+	if err := requireRole(ctx, approver.Role); err != nil {
+		return err
+	}
+	designation, _, _ := ctx.GetClientIdentity().GetAttributeValue("designation")
+	if !strings.EqualFold(designation, approval.Designation) {
+		return newError(ErrCodeAccessDenied, "caller's designation '%s' is not %s", designation, approval.Designation)
+	}
+
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+	for _, existing := range transfer.GovernmentApprovals {
+		if existing.Designation == approval.Designation {
+			return newError(ErrCodeGovernmentApprovalDuplicate, "%s has already approved %s", approval.Designation, transferID)
+		}
+		if existing.CertFingerprint == fingerprint {
+			return newError(ErrCodeGovernmentApprovalDuplicate, "this certificate already approved %s as %s", transferID, existing.Designation)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	approval.ApproverID = getCallerID(ctx)
+	approval.CertFingerprint = fingerprint
+	approval.ApprovedAt = now
+	transfer.GovernmentApprovals = append(transfer.GovernmentApprovals, approval)
+	transfer.StatusHistory = append(transfer.StatusHistory, StatusEntry{
+		Status:          "GOVERNMENT_APPROVED:" + approval.Designation,
+		At:              now,
+		By:              approval.ApproverID,
+		CertFingerprint: fingerprint,
+	})
+	transfer.FabricTxID = txID
+	transfer.UpdatedAt = now
+
+	transferUpdatedBytes, _ := canonicalMarshal(transfer)
+	if err := ctx.GetStub().PutState(transferKey, transferUpdatedBytes); err != nil {
+		return internalError("failed to update transfer: %v", err)
+	}
+
+	event := GovernmentApprovalEvent{
+		Type:        "GOVERNMENT_APPROVAL_RECORDED",
+		TransferID:  transferID,
+		PropertyID:  transfer.PropertyID,
+		Designation: approval.Designation,
+		OrderRef:    approval.OrderRef,
+		ApproverID:  approval.ApproverID,
+		Approved:    len(transfer.GovernmentApprovals),
+		Required:    len(settings.GovernmentDisposalApprovers),
+		FabricTxID:  txID,
+		Timestamp:   now,
+		StateCode:   property.Location.StateCode,
+		ChannelID:   ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "GOVERNMENT_APPROVAL_RECORDED", event)
+}
+
+// requireGovernmentApprovals fails unless every designation the state
+// lists in GovernmentDisposalApprovers has approved a transfer of
+// government-owned land. A state that lists none cannot dispose of
+// government land through the registry.
+func requireGovernmentApprovals(ctx contractapi.TransactionContextInterface, property *LandRecord, transfer *TransferRecord) error {
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return err
+	}
+	if len(settings.GovernmentDisposalApprovers) == 0 {
+		return newError(ErrCodeGovernmentApprovalRequired, "%s has no government disposal approvers configured", property.Location.StateCode)
+	}
+	approved := map[string]bool{}
+	for _, approval := range transfer.GovernmentApprovals {
+		approved[approval.Designation] = true
+	}
+	var missing []string
+	for _, approver := range settings.GovernmentDisposalApprovers {
+		if !approved[approver.Designation] {
+			missing = append(missing, approver.Designation)
+		}
+	}
+	if len(missing) > 0 {
+		return newError(ErrCodeGovernmentApprovalRequired, "transfer of government land %s awaits approval from: %s",
+			transfer.PropertyID, strings.Join(missing, ", ")).with("designations", strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// governmentApprover returns the state's configured approver for
+// designation, or nil.
+func governmentApprover(settings *RegistrySettings, designation string) *GovernmentApprover {
+	for i := range settings.GovernmentDisposalApprovers {
+		if settings.GovernmentDisposalApprovers[i].Designation == designation {
+			return &settings.GovernmentDisposalApprovers[i]
+		}
+	}
+	return nil
+}
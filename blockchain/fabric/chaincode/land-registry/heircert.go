@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LEGAL HEIR CERTIFICATES
+// ============================================================
+// A legal heir certificate names a deceased person's heirs and their
+// shares. It is recorded once and referenced by ID from each intestate
+// inheritance of the deceased's properties (see RecordInheritance), so
+// every mutation takes its heirs from the same validated list.
+
+// heirCertificateStatuses lists the statuses the issuing authority can
+// set with SetHeirCertificateStatus.
+var heirCertificateStatuses = map[string]bool{
+	"EXPIRED":     true,
+	"INVALIDATED": true,
+}
+
+// RecordHeirCertificate records a legal heir certificate. certJSON is a
+// HeirCertificate with deceasedHash, issuingAuthority,
+// certificateNumber, issueDate (YYYY-MM-DD), optionally validUntil,
+// heirs (each with aadhaarHash, name, relationship, sharePercentage and
+// a guardian if a minor; shares sum to 100) and documentHash. Only
+// tehsildars can record certificates; the caller's MSP becomes the
+// issuer. Writes an audit entry.
+func (s *LandRegistryContract) RecordHeirCertificate(ctx contractapi.TransactionContextInterface, certJSON string) (string, error) {
+	if err := requireRole(ctx, "tehsildar"); err != nil {
+		return "", err
+	}
+
+	var cert HeirCertificate
+	if err := json.Unmarshal([]byte(certJSON), &cert); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse heir certificate JSON: %v", err)
+	}
+	if err := validateAadhaarHash(cert.DeceasedHash, "deceasedHash"); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(cert.IssuingAuthority) == "" {
+		return "", newError(ErrCodeValidationError, "issuingAuthority is required")
+	}
+	if strings.TrimSpace(cert.CertificateNumber) == "" {
+		return "", newError(ErrCodeValidationError, "certificateNumber is required")
+	}
+	if _, err := time.Parse("2006-01-02", cert.IssueDate); err != nil {
+		return "", newError(ErrCodeValidationError, "issueDate must be YYYY-MM-DD")
+	}
+	if cert.ValidUntil != "" {
+		if _, err := time.Parse("2006-01-02", cert.ValidUntil); err != nil {
+			return "", newError(ErrCodeValidationError, "validUntil must be YYYY-MM-DD")
+		}
+		if cert.ValidUntil < cert.IssueDate {
+			return "", newError(ErrCodeValidationError, "validUntil must not be before issueDate")
+		}
+	}
+	if err := validateDocumentHash(cert.DocumentHash, "documentHash"); err != nil {
+		return "", err
+	}
+	for i, heir := range cert.Heirs {
+		if strings.TrimSpace(heir.Relationship) == "" {
+			return "", newError(ErrCodeValidationError, "heirs[%d].relationship is required", i)
+		}
+	}
+	if err := validateHeirs(heirOwners(cert.Heirs), cert.DeceasedHash, "heirs"); err != nil {
+		return "", err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
+
+	cert.DocType = "heirCertificate"
+	cert.CertificateID = "hcert_" + txID[:8]
+	cert.Status = "ACTIVE"
+	cert.StatusReason = ""
+	cert.IssuerMspID = mspID
+	cert.RecordedBy = getCallerID(ctx)
+	cert.RecordedAt = now
+	cert.FabricTxID = txID
+	if err := putHeirCertificate(ctx, &cert); err != nil {
+		return "", err
+	}
+	if err := recordAudit(ctx, "RecordHeirCertificate", cert.CertificateID); err != nil {
+		return "", err
+	}
+	return cert.CertificateID, nil
+}
+
+// GetHeirCertificate returns a legal heir certificate by ID.
+func (s *LandRegistryContract) GetHeirCertificate(ctx contractapi.TransactionContextInterface, certificateID string) (*HeirCertificate, error) {
+	if _, err := requireAnyRole(ctx, "tehsildar", "registrar", "court", "admin"); err != nil {
+		return nil, err
+	}
+	return getHeirCertificate(ctx, certificateID)
+}
+
+// SetHeirCertificateStatus marks an active certificate EXPIRED or
+// INVALIDATED, with reason; inheritances can no longer cite it. Only
+// tehsildars of the issuing MSP, and admins, can change a certificate's
+// status. Writes an audit entry.
+func (s *LandRegistryContract) SetHeirCertificateStatus(ctx contractapi.TransactionContextInterface, certificateID, status, reason string) error {
+	role, err := requireAnyRole(ctx, "tehsildar", "admin")
+	if err != nil {
+		return err
+	}
+	if !heirCertificateStatuses[status] {
+		return newError(ErrCodeValidationError, "status '%s' must be EXPIRED or INVALIDATED", status)
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+
+	cert, err := getHeirCertificate(ctx, certificateID)
+	if err != nil {
+		return err
+	}
+	if role != "admin" {
+		mspID, _ := ctx.GetClientIdentity().GetMSPID()
+		if mspID != cert.IssuerMspID {
+			return newError(ErrCodeAccessDenied, "heir certificate %s was issued by %s", certificateID, cert.IssuerMspID)
+		}
+	}
+	if cert.Status != "ACTIVE" {
+		return newError(ErrCodeHeirCertificateInvalid, "heir certificate %s is already %s", certificateID, cert.Status)
+	}
+
+	cert.Status = status
+	cert.StatusReason = reason
+	cert.FabricTxID = ctx.GetStub().GetTxID()
+	if err := putHeirCertificate(ctx, cert); err != nil {
+		return err
+	}
+	return recordAudit(ctx, "SetHeirCertificateStatus", fmt.Sprintf("%s %s", certificateID, status))
+}
+
+// RecordInheritance passes a deceased owner's share of a property to
+// the heirs on a legal heir certificate, through an AUTO_APPROVED
+// INHERITANCE mutation (see applySuccession). heirsJSON, if not empty,
+// is the heirs as submitted with the mutation application: a list of
+// Owner that must match the certificate's heirs and shares exactly.
+// Only tehsildars with jurisdiction over the property can record an
+// inheritance. Emits MUTATION_CREATED.
+func (s *LandRegistryContract) RecordInheritance(ctx contractapi.TransactionContextInterface, propertyID, certificateID, heirsJSON string) (*Receipt, error) {
+	if err := requireRole(ctx, "tehsildar"); err != nil {
+		return nil, err
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return nil, err
+	}
+
+	cert, err := requireUsableHeirCertificate(ctx, certificateID)
+	if err != nil {
+		return nil, err
+	}
+	heirs := heirOwners(cert.Heirs)
+	if heirsJSON != "" {
+		var submitted []Owner
+		if err := json.Unmarshal([]byte(heirsJSON), &submitted); err != nil {
+			return nil, newError(ErrCodeInvalidInput, "failed to parse heirs JSON: %v", err)
+		}
+		if err := matchCertificateHeirs(cert, submitted); err != nil {
+			return nil, err
+		}
+	}
+
+	txID := ctx.GetStub().GetTxID()
+	mutation, err := applySuccession(ctx, property, cert.DeceasedHash, heirs, successionDetails{
+		MutationID:    "mut_" + txID[:8],
+		DeedHash:      cert.DocumentHash,
+		CertificateID: cert.CertificateID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, mutation.MutationID, mutation.Status).
+		relate("property", propertyID, property.Status), nil
+}
+
+// requireUsableHeirCertificate loads a certificate and checks it is
+// ACTIVE and not past its validUntil date at the transaction time.
+func requireUsableHeirCertificate(ctx contractapi.TransactionContextInterface, certificateID string) (*HeirCertificate, error) {
+	cert, err := getHeirCertificate(ctx, certificateID)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Status != "ACTIVE" {
+		return nil, newError(ErrCodeHeirCertificateInvalid, "heir certificate %s is %s", certificateID, cert.Status).with("certificateId", certificateID)
+	}
+	if cert.ValidUntil != "" {
+		timestamp, _ := ctx.GetStub().GetTxTimestamp()
+		if time.Unix(timestamp.Seconds, 0).Format("2006-01-02") > cert.ValidUntil {
+			return nil, newError(ErrCodeHeirCertificateInvalid, "heir certificate %s expired on %s", certificateID, cert.ValidUntil).with("certificateId", certificateID)
+		}
+	}
+	return cert, nil
+}
+
+// matchCertificateHeirs fails unless submitted names exactly the
+// certificate's heirs with the same shares.
+func matchCertificateHeirs(cert *HeirCertificate, submitted []Owner) error {
+	shares := make(map[string]int, len(cert.Heirs))
+	for _, heir := range cert.Heirs {
+		shares[heir.AadhaarHash] = heir.SharePercentage
+	}
+	if len(submitted) != len(shares) {
+		return newError(ErrCodeHeirCertificateMismatch, "certificate %s names %d heir(s), %d submitted", cert.CertificateID, len(shares), len(submitted))
+	}
+	for i, heir := range submitted {
+		share, ok := shares[heir.AadhaarHash]
+		if !ok {
+			return newError(ErrCodeHeirCertificateMismatch, "heirs[%d] is not an heir on certificate %s", i, cert.CertificateID)
+		}
+		if share != heir.SharePercentage {
+			return newError(ErrCodeHeirCertificateMismatch, "heirs[%d] has share %d, certificate %s gives %d", i, heir.SharePercentage, cert.CertificateID, share)
+		}
+		delete(shares, heir.AadhaarHash)
+	}
+	return nil
+}
+
+// heirOwners converts certificate heirs to the owners they become.
+func heirOwners(heirs []HeirEntry) []Owner {
+	owners := make([]Owner, 0, len(heirs))
+	for _, heir := range heirs {
+		owners = append(owners, Owner{
+			AadhaarHash:     heir.AadhaarHash,
+			Name:            heir.Name,
+			SharePercentage: heir.SharePercentage,
+			IsMinor:         heir.IsMinor,
+			Guardian:        heir.Guardian,
+		})
+	}
+	return owners
+}
+
+// getHeirCertificate reads a certificate by ID.
+func getHeirCertificate(ctx contractapi.TransactionContextInterface, certificateID string) (*HeirCertificate, error) {
+	if certificateID == "" {
+		return nil, newError(ErrCodeValidationError, "certificateId is required")
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixHeirCertificate, []string{certificateID})
+	if err != nil {
+		return nil, internalError("failed to create heir certificate key: %v", err)
+	}
+	certBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read heir certificate: %v", err)
+	}
+	if certBytes == nil {
+		return nil, newError(ErrCodeHeirCertificateNotFound, "%s does not exist", certificateID).with("certificateId", certificateID)
+	}
+	var cert HeirCertificate
+	if err := json.Unmarshal(certBytes, &cert); err != nil {
+		return nil, internalError("failed to unmarshal heir certificate: %v", err)
+	}
+	return &cert, nil
+}
+
+// putHeirCertificate writes a certificate under its key.
+func putHeirCertificate(ctx contractapi.TransactionContextInterface, cert *HeirCertificate) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixHeirCertificate, []string{cert.CertificateID})
+	if err != nil {
+		return internalError("failed to create heir certificate key: %v", err)
+	}
+	certBytes, err := canonicalMarshal(cert)
+	if err != nil {
+		return internalError("failed to marshal heir certificate: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, certBytes); err != nil {
+		return internalError("failed to write heir certificate %s: %v", cert.CertificateID, err)
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -22,18 +25,98 @@ const (
 	KeyPrefixTransfer = "TRANSFER"
 	// KeyPrefixEncumbrance is the prefix for encumbrance keys: ENCUMBRANCE~{propertyId}~{encumbranceId}
 	KeyPrefixEncumbrance = "ENCUMBRANCE"
+	// KeyPrefixLease is the prefix for lease keys: LEASE~{propertyId}~{leaseId}
+	KeyPrefixLease = "LEASE"
+	// KeyPrefixPOA is the prefix for powers of attorney: POA~{poaId}
+	KeyPrefixPOA = "POA"
+	// KeyPrefixWill is the prefix for registered wills: WILL~{willId}
+	KeyPrefixWill = "WILL"
+	// KeyPrefixHeirCertificate is the prefix for legal heir certificates: HEIRCERT~{certificateId}
+	KeyPrefixHeirCertificate = "HEIRCERT"
+	// KeyPrefixCultivation is the prefix for record-of-rights cultivation entries: CULTIVATION~{propertyId}~{cultivationId}
+	KeyPrefixCultivation = "CULTIVATION"
+	// KeyPrefixCropLoanCultivator is the prefix for the crop loan index by cultivator: CROPLOAN_CULTIVATOR~{cultivatorHash}~{encumbranceId}
+	KeyPrefixCropLoanCultivator = "CROPLOAN_CULTIVATOR"
+	// KeyPrefixCompensation is the prefix for acquisition compensation records: COMPENSATION~{recordId}
+	KeyPrefixCompensation = "COMPENSATION"
+	// KeyPrefixCompensationDispute is the prefix for disputes over compensation: COMPENSATION_DISPUTE~{recordId}~{disputeId}
+	KeyPrefixCompensationDispute = "COMPENSATION_DISPUTE"
+	// KeyPrefixValuation is the prefix for append-only property valuations: VALUATION~{propertyId}~{valuationId}
+	KeyPrefixValuation = "VALUATION"
+	// KeyPrefixCertifiedCopy is the prefix for issued certified copies: CERTCOPY~{serialNumber}
+	KeyPrefixCertifiedCopy = "CERTCOPY"
+	// KeyPrefixCertifiedCopyCounter is the prefix for daily certified copy counters: CERTCOPY_COUNTER~{office}~{date}
+	KeyPrefixCertifiedCopyCounter = "CERTCOPY_COUNTER"
+	// KeyPrefixEasementDominant is the prefix for the easement index by dominant parcel: EASEMENT_DOMINANT~{dominantPropertyId}~{encumbranceId}
+	KeyPrefixEasementDominant = "EASEMENT_DOMINANT"
+	// KeyPrefixAnnotationType is the prefix for the annotation index by type: ANNOTATION_TYPE~{stateCode}~{districtCode}~{type}~{propertyId}~{annotationId}
+	KeyPrefixAnnotationType = "ANNOTATION_TYPE"
+	// KeyPrefixInvestigationHold is the prefix for the investigation hold index: INVESTIGATION_HOLD~{stateCode}~{reviewBy}~{propertyId}
+	KeyPrefixInvestigationHold = "INVESTIGATION_HOLD"
+	// KeyPrefixInsurance is the prefix for property insurance policies: INSURANCE~{propertyId}~{policyId}
+	KeyPrefixInsurance = "INSURANCE"
+	// KeyPrefixInsuranceExpiry is the prefix for the policy index by lender: INSURANCE_EXPIRY~{lenderMspId}~{endDate}~{propertyId}~{policyId}
+	KeyPrefixInsuranceExpiry = "INSURANCE_EXPIRY"
+	// KeyPrefixDisaster is the prefix for the disaster-affected index: DISASTER~{stateCode}~{districtCode}~{eventRef}~{propertyId}
+	KeyPrefixDisaster = "DISASTER"
+	// KeyPrefixScheduledTransfer is the prefix for the scheduled transfer index: SCHEDULED_TRANSFER~{stateCode}~{executeAfter}~{transferId}
+	KeyPrefixScheduledTransfer = "SCHEDULED_TRANSFER"
+	// KeyPrefixLedgerCounters is the prefix for per-state record counters: LEDGER_COUNTERS~{stateCode}
+	KeyPrefixLedgerCounters = "LEDGER_COUNTERS"
+	// KeyPrefixIndexRepair is the prefix for index repair logs: INDEX_REPAIR~{stateCode}~{repairId}
+	KeyPrefixIndexRepair = "INDEX_REPAIR"
+	// KeyPrefixSettingsProposal is the prefix for pending settings changes: SETTINGS_PROPOSAL~{stateCode}
+	KeyPrefixSettingsProposal = "SETTINGS_PROPOSAL"
+	// KeyPrefixSettingsHistory is the prefix for applied settings changes: SETTINGS_HISTORY~{stateCode}~{changedAt}~{txId}
+	KeyPrefixSettingsHistory = "SETTINGS_HISTORY"
 	// KeyPrefixDispute is the prefix for dispute keys: DISPUTE~{propertyId}~{disputeId}
 	KeyPrefixDispute = "DISPUTE"
 	// KeyPrefixMutation is the prefix for mutation keys: MUTATION~{mutationId}
 	KeyPrefixMutation = "MUTATION"
 	// KeyPrefixAnchor is the prefix for anchor keys: ANCHOR~{stateCode}~{anchorId}
 	KeyPrefixAnchor = "ANCHOR"
+	// KeyPrefixAnchorAttempt is the prefix for anchor attempts: ANCHOR_ATTEMPT~{attemptId}
+	KeyPrefixAnchorAttempt = "ANCHOR_ATTEMPT"
 	// KeyPrefixOwnerIndex is the prefix for the owner lookup index: OWNER~{aadhaarHash}~{propertyId}
 	KeyPrefixOwnerIndex = "OWNER"
 	// KeyPrefixSurveyIndex is the prefix for survey number lookups: SURVEY~{stateCode}~{districtCode}~{surveyNo}
 	KeyPrefixSurveyIndex = "SURVEY"
 	// KeyPrefixLocationIndex is the prefix for location-based lookups: LOCATION~{stateCode}~{districtCode}~{tehsilCode}~{villageCode}~{propertyId}
 	KeyPrefixLocationIndex = "LOCATION"
+	// KeyPrefixSettings is the prefix for per-state registry settings: REGISTRY_SETTINGS~{stateCode}
+	KeyPrefixSettings = "REGISTRY_SETTINGS"
+	// KeyPrefixEntityIndex is the prefix for the non-individual owner lookup index: ENTITY~{identifier}~{propertyId}
+	KeyPrefixEntityIndex = "ENTITY"
+	// KeyPrefixBoundaryConflict is the prefix for boundary conflicts: BOUNDARY_CONFLICT~{stateCode}~{districtCode}~{propertyId}~{propertyId}
+	KeyPrefixBoundaryConflict = "BOUNDARY_CONFLICT"
+	// KeyPrefixRegNo is the prefix for the deed registration number index: REGNO~{sro}~{bookNumber}~{registrationNumber}
+	KeyPrefixRegNo = "REGNO"
+	// KeyPrefixSubdivisionProposal is the prefix for split and merge proposals: SUBDIVISION_PROPOSAL~{proposalId}
+	KeyPrefixSubdivisionProposal = "SUBDIVISION_PROPOSAL"
+	// KeyPrefixChildIndex is the prefix for the split/merge descendants index: CHILDREN~{parentPropertyId}~{childPropertyId}
+	KeyPrefixChildIndex = "CHILDREN"
+	// KeyPrefixInstitution is the prefix for registered encumbrance holders: INSTITUTION~{mspId}
+	KeyPrefixInstitution = "INSTITUTION"
+	// KeyPrefixAudit is the prefix for access audit entries: AUDIT~{date}~{txId}
+	KeyPrefixAudit = "AUDIT"
+	// KeyPrefixDelegation is the prefix for role delegations: DELEGATION~{delegateMspId}~{delegateEnrollmentId}~{delegationId}
+	KeyPrefixDelegation = "DELEGATION"
+	// KeyPrefixRequest is the prefix for the idempotent request index: REQUEST~{mspId}~{enrollmentId or fingerprint}~{requestId}
+	KeyPrefixRequest = "REQUEST"
+	// KeyPrefixDenyList is the prefix for blocked identities: DENYLIST~{fingerprint or enrollmentId}
+	KeyPrefixDenyList = "DENYLIST"
+	// KeyPrefixSigningKey is the prefix for citizens' registered signing keys: SIGNING_KEY~{aadhaarHash}
+	KeyPrefixSigningKey = "SIGNING_KEY"
+	// KeyPrefixEventSequence is the prefix for per-channel event counters: EVENT_SEQUENCE~{channelId}
+	KeyPrefixEventSequence = "EVENT_SEQUENCE"
+	// KeyPrefixEventLog is the prefix for the event journal: EVENTLOG~{channelId}~{sequence}
+	KeyPrefixEventLog = "EVENTLOG"
+	// KeyPrefixEventLogDigest is the prefix for pruned journal digests: EVENTLOG_DIGEST~{channelId}
+	KeyPrefixEventLogDigest = "EVENTLOG_DIGEST"
+	// KeyPrefixEventDigest is the prefix for daily event digests: EVENT_DIGEST~{channelId}~{date}
+	KeyPrefixEventDigest = "EVENT_DIGEST"
+	// KeyPrefixTaxDigest is the prefix for daily tax payment digests: TAX_DIGEST~{stateCode}~{districtCode}~{date}
+	KeyPrefixTaxDigest = "TAX_DIGEST"
 )
 
 // ============================================================
@@ -101,14 +184,83 @@ var propertyIDPattern = regexp.MustCompile(`^[A-Z]{2}-[A-Z]{2,5}-[A-Z]{2,5}-[A-Z
 // Indian land record format: {StateCode}-{DistrictCode}-{TehsilCode}-{VillageCode}-{SurveyNo}-{SubSurveyNo}
 func validatePropertyID(propertyID string) error {
 	if propertyID == "" {
-		return fmt.Errorf("VALIDATION_ERROR: propertyId cannot be empty")
+		return newError(ErrCodeValidationError, "propertyId cannot be empty")
 	}
 	if !propertyIDPattern.MatchString(propertyID) {
-		return fmt.Errorf("VALIDATION_ERROR: propertyId '%s' does not match format {StateCode}-{DistrictCode}-{TehsilCode}-{VillageCode}-{SurveyNo}-{SubSurveyNo}", propertyID)
+		return newError(ErrCodeValidationError, "propertyId '%s' does not match format {StateCode}-{DistrictCode}-{TehsilCode}-{VillageCode}-{SurveyNo}-{SubSurveyNo}", propertyID)
 	}
 	parts := strings.Split(propertyID, "-")
 	if len(parts) != 6 {
-		return fmt.Errorf("VALIDATION_ERROR: propertyId must have exactly 6 segments separated by '-', got %d", len(parts))
+		return newError(ErrCodeValidationError, "propertyId must have exactly 6 segments separated by '-', got %d", len(parts))
+	}
+	return nil
+}
+
+// aadhaarHashPattern matches a lowercase hex SHA-256 digest.
+var aadhaarHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// rawAadhaarPattern matches a raw 12-digit Aadhaar number, optionally
+// grouped with spaces or hyphens.
+var rawAadhaarPattern = regexp.MustCompile(`^\d{4}[ -]?\d{4}[ -]?\d{4}$`)
+
+// validateAadhaarHash checks that an Aadhaar reference is a SHA-256
+// hash (64 lowercase hex characters) and never a raw Aadhaar number.
+// Errors name only the field path and never echo the value, so a raw
+// number submitted by mistake does not end up in logs.
+func validateAadhaarHash(hash, field string) error {
+	if hash == "" {
+		return newError(ErrCodeAadhaarRequired, "%s is required", field)
+	}
+	if rawAadhaarPattern.MatchString(strings.TrimSpace(hash)) {
+		return newError(ErrCodeAadhaarFormatInvalid, "%s appears to be a raw Aadhaar number; submit its SHA-256 hash", field)
+	}
+	if !aadhaarHashPattern.MatchString(hash) {
+		return newError(ErrCodeAadhaarFormatInvalid, "%s must be a 64-character lowercase hex SHA-256 hash", field)
+	}
+	return nil
+}
+
+// validateTransferParties checks the Aadhaar hashes of a transfer's
+// seller, buyer and witnesses. Witnesses without a hash are allowed
+// here; they simply do not count as signed.
+func validateTransferParties(transfer *TransferRecord) error {
+	if err := validateAadhaarHash(transfer.Seller.AadhaarHash, "seller.aadhaarHash"); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(transfer.Buyer.AadhaarHash, "buyer.aadhaarHash"); err != nil {
+		return err
+	}
+	transfer.Buyer.OwnerType = normalizeOwnerType(transfer.Buyer.OwnerType)
+	if !ownerTypes[transfer.Buyer.OwnerType] {
+		return newError(ErrCodeValidationError, "buyer.ownerType '%s' must be INDIVIDUAL, HUF, COMPANY, TRUST or GOVERNMENT", transfer.Buyer.OwnerType)
+	}
+	if err := validateEntityDetails(transfer.Buyer.OwnerType, transfer.Buyer.Entity, transfer.Buyer.IsMinor, "buyer"); err != nil {
+		return err
+	}
+	for i, w := range transfer.Witnesses {
+		if w.AadhaarHash == "" {
+			continue
+		}
+		if err := validateAadhaarHash(w.AadhaarHash, fmt.Sprintf("witnesses[%d].aadhaarHash", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// documentHashPattern matches the document references accepted on
+// chain: an IPFS CIDv0 (Qm...), an IPFS CIDv1 in base32 (b...), or a
+// lowercase hex SHA-256 digest.
+var documentHashPattern = regexp.MustCompile(`^(Qm[1-9A-HJ-NP-Za-km-z]{44}|b[a-z2-7]{58}|[0-9a-f]{64})$`)
+
+// validateDocumentHash checks that a document reference is an IPFS CID
+// or a SHA-256 hex digest.
+func validateDocumentHash(hash, field string) error {
+	if hash == "" {
+		return newError(ErrCodeValidationError, "%s is required", field)
+	}
+	if !documentHashPattern.MatchString(hash) {
+		return newError(ErrCodeValidationError, "%s must be an IPFS CID or a SHA-256 hex digest", field)
 	}
 	return nil
 }
@@ -133,7 +285,7 @@ func extractStateCode(propertyID string) string {
 func getActiveEncumbrances(ctx contractapi.TransactionContextInterface, propertyID string) ([]*EncumbranceRecord, error) {
 	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixEncumbrance, []string{propertyID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query encumbrances for property %s: %v", propertyID, err)
+		return nil, internalError("failed to query encumbrances for property %s: %v", propertyID, err)
 	}
 	defer iterator.Close()
 
@@ -141,11 +293,11 @@ func getActiveEncumbrances(ctx contractapi.TransactionContextInterface, property
 	for iterator.HasNext() {
 		kv, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate encumbrances: %v", err)
+			return nil, internalError("failed to iterate encumbrances: %v", err)
 		}
 		var enc EncumbranceRecord
 		if err := json.Unmarshal(kv.Value, &enc); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal encumbrance: %v", err)
+			return nil, internalError("failed to unmarshal encumbrance: %v", err)
 		}
 		if enc.Status == "ACTIVE" {
 			activeEncumbrances = append(activeEncumbrances, &enc)
@@ -169,71 +321,352 @@ func hasActiveEncumbrances(ctx contractapi.TransactionContextInterface, property
 // ============================================================
 
 // requireRole verifies that the calling identity has the specified role
-// attribute in their X.509 certificate. Roles include: registrar,
-// tehsildar, bank, court, admin, citizen.
+// attribute in their X.509 certificate, a role that includes it in the
+// role hierarchy (see inheritsRole), or an active delegation of it (see
+// GrantDelegation). Roles include: registrar, tehsildar, bank, court,
+// admin, citizen. Blocked identities are refused first (see
+// checkDenyList).
 func requireRole(ctx contractapi.TransactionContextInterface, requiredRole string) error {
-	clientIdentity := ctx.GetClientIdentity()
-	role, found, err := clientIdentity.GetAttributeValue("role")
+	if err := checkDenyList(ctx); err != nil {
+		return err
+	}
+	role, err := callerRole(ctx)
 	if err != nil {
-		return fmt.Errorf("ACCESS_DENIED: failed to read role attribute: %v", err)
+		return err
 	}
-	if !found {
-		return fmt.Errorf("ACCESS_DENIED: caller identity has no 'role' attribute")
+	inherits, err := hasRole(ctx, role, requiredRole)
+	if err != nil {
+		return err
 	}
-	if role != requiredRole {
-		return fmt.Errorf("ACCESS_DENIED: required role '%s', caller has role '%s'", requiredRole, role)
+	if !inherits {
+		return newError(ErrCodeAccessDenied, "required role '%s', caller has role '%s'", requiredRole, role)
 	}
 	return nil
 }
 
 // requireAnyRole verifies that the calling identity has at least one
-// of the specified roles in their X.509 certificate.
+// of the specified roles in their X.509 certificate, directly or through
+// the role hierarchy or a delegation. It returns the allowed role the caller acts as:
+// their own role when it is listed, otherwise the first listed role it
+// includes. Blocked identities are refused first.
 func requireAnyRole(ctx contractapi.TransactionContextInterface, allowedRoles ...string) (string, error) {
-	clientIdentity := ctx.GetClientIdentity()
-	role, found, err := clientIdentity.GetAttributeValue("role")
-	if err != nil {
-		return "", fmt.Errorf("ACCESS_DENIED: failed to read role attribute: %v", err)
+	if err := checkDenyList(ctx); err != nil {
+		return "", err
 	}
-	if !found {
-		return "", fmt.Errorf("ACCESS_DENIED: caller identity has no 'role' attribute")
+	role, err := callerRole(ctx)
+	if err != nil {
+		return "", err
 	}
 	for _, allowed := range allowedRoles {
 		if role == allowed {
 			return role, nil
 		}
 	}
-	return "", fmt.Errorf("ACCESS_DENIED: role '%s' is not in allowed roles %v", role, allowedRoles)
+	for _, allowed := range allowedRoles {
+		inherits, err := hasRole(ctx, role, allowed)
+		if err != nil {
+			return "", err
+		}
+		if inherits {
+			return allowed, nil
+		}
+	}
+	return "", newError(ErrCodeAccessDenied, "role '%s' is not in allowed roles %v", role, allowedRoles)
+}
+
+// hasRole reports whether a caller with role may act as requiredRole,
+// through the role hierarchy or under an active delegation.
+func hasRole(ctx contractapi.TransactionContextInterface, role, requiredRole string) (bool, error) {
+	inherits, err := inheritsRole(ctx, role, requiredRole)
+	if err != nil || inherits {
+		return inherits, err
+	}
+	return delegatedRole(ctx, requiredRole)
+}
+
+// callerRole reads the role attribute from the caller's certificate.
+func callerRole(ctx contractapi.TransactionContextInterface) (string, error) {
+	role, found, err := ctx.GetClientIdentity().GetAttributeValue("role")
+	if err != nil {
+		return "", newError(ErrCodeAccessDenied, "failed to read role attribute: %v", err)
+	}
+	if !found {
+		return "", newError(ErrCodeAccessDenied, "caller identity has no 'role' attribute")
+	}
+	return role, nil
 }
 
+// inheritsRole reports whether role is, or includes, requiredRole under
+// the RoleHierarchy of the caller's state settings. Inclusion is
+// transitive. Callers without a stateCode attribute, and states without
+// a hierarchy, get no inheritance.
+func inheritsRole(ctx contractapi.TransactionContextInterface, role, requiredRole string) (bool, error) {
+	if role == requiredRole {
+		return true, nil
+	}
+	callerState, found, err := ctx.GetClientIdentity().GetAttributeValue("stateCode")
+	if err != nil || !found || callerState == "" {
+		return false, nil
+	}
+	settings, err := getSettings(ctx, callerState)
+	if err != nil {
+		return false, err
+	}
+
+	seen := map[string]bool{role: true}
+	pending := []string{role}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		for _, included := range settings.RoleHierarchy[current] {
+			if included == requiredRole {
+				return true, nil
+			}
+			if !seen[included] {
+				seen[included] = true
+				pending = append(pending, included)
+			}
+		}
+	}
+	return false, nil
+}
+
+// NationalStateCode is the stateCode attribute of central identities
+// (Supreme Court, NCLT, bank head offices, the central admin team)
+// whose scope is the whole country rather than one state.
+const NationalStateCode = "IN"
+
+// defaultNationalWriteRoles are the national-scope roles that may modify
+// a state's records when its settings leave NationalWriteRoles unset.
+var defaultNationalWriteRoles = []string{"court", "admin"}
+
 // requireStateAccess verifies that the calling identity's stateCode
 // attribute matches the state of the property being accessed. This
 // enforces jurisdictional boundaries — an AP registrar cannot modify
-// Maharashtra records.
+// Maharashtra records. National-scope identities (stateCode "IN") pass
+// when their role is one of the target state's NationalWriteRoles; their
+// caller ID records the "IN" scope in audit fields.
 func requireStateAccess(ctx contractapi.TransactionContextInterface, propertyStateCode string) error {
 	clientIdentity := ctx.GetClientIdentity()
 	callerState, found, err := clientIdentity.GetAttributeValue("stateCode")
 	if err != nil {
-		return fmt.Errorf("ACCESS_DENIED: failed to read stateCode attribute: %v", err)
+		return newError(ErrCodeAccessDenied, "failed to read stateCode attribute: %v", err)
 	}
 	if !found {
-		return fmt.Errorf("ACCESS_DENIED: caller identity has no 'stateCode' attribute")
+		return newError(ErrCodeAccessDenied, "caller identity has no 'stateCode' attribute")
+	}
+	if callerState == NationalStateCode && propertyStateCode != NationalStateCode {
+		return requireNationalWriteScope(ctx, propertyStateCode)
 	}
 	if callerState != propertyStateCode {
-		return fmt.Errorf("STATE_MISMATCH: registrar from %s cannot modify %s records", callerState, propertyStateCode)
+		return newError(ErrCodeStateMismatch, "registrar from %s cannot modify %s records", callerState, propertyStateCode)
+	}
+	return nil
+}
+
+// requireNationalWriteScope verifies that a national-scope caller's role
+// may modify stateCode's records.
+func requireNationalWriteScope(ctx contractapi.TransactionContextInterface, stateCode string) error {
+	role, err := callerRole(ctx)
+	if err != nil {
+		return err
+	}
+	settings, err := getSettings(ctx, stateCode)
+	if err != nil {
+		return err
+	}
+	allowed := settings.NationalWriteRoles
+	if allowed == nil {
+		allowed = defaultNationalWriteRoles
+	}
+	for _, r := range allowed {
+		if r == role {
+			return nil
+		}
+	}
+	return newError(ErrCodeStateMismatch, "national %s cannot modify %s records", role, stateCode)
+}
+
+// requireJurisdiction verifies the caller's jurisdiction over a location.
+// The state must always match (see requireStateAccess); certificates may
+// additionally carry districtCode and tehsilCode attributes narrowing the
+// caller to one district or tehsil, and every one present must match.
+// Identities enrolled without them keep state-wide scope. An active
+// delegation covering the location also grants jurisdiction.
+func requireJurisdiction(ctx contractapi.TransactionContextInterface, location Location) error {
+	if err := requireStateAccess(ctx, location.StateCode); err != nil {
+		return err
+	}
+	clientIdentity := ctx.GetClientIdentity()
+	scopes := []struct {
+		attribute string
+		value     string
+	}{
+		{"districtCode", location.DistrictCode},
+		{"tehsilCode", location.TehsilCode},
+	}
+	for _, scope := range scopes {
+		callerValue, found, err := clientIdentity.GetAttributeValue(scope.attribute)
+		if err != nil {
+			return newError(ErrCodeAccessDenied, "failed to read %s attribute: %v", scope.attribute, err)
+		}
+		if found && callerValue != "" && callerValue != scope.value {
+			delegated, err := delegatedJurisdiction(ctx, location)
+			if err != nil {
+				return err
+			}
+			if delegated {
+				return nil
+			}
+			return newError(ErrCodeJurisdictionMismatch, "caller's %s %s does not cover %s records", scope.attribute, callerValue, scope.value)
+		}
 	}
 	return nil
 }
 
-// getCallerID extracts a human-readable identifier from the caller's
-// X.509 certificate for audit trail purposes. Combines role and stateCode.
+// officialRoles are the roles that may read any record. Citizens may only
+// read records they are a party to; see requireSelfOrRole.
+var officialRoles = []string{"registrar", "tehsildar", "bank", "court", "admin"}
+
+// requireSelfOrRole allows callers holding one of roles, and citizens
+// whose certificate aadhaarHash attribute is one of selfHashes. Citizen
+// certificates must carry aadhaarHash (the same SHA-256 hash stored in
+// owner and party records); a citizen without it is denied. Blocked
+// identities are refused first.
+func requireSelfOrRole(ctx contractapi.TransactionContextInterface, selfHashes []string, roles ...string) error {
+	if err := checkDenyList(ctx); err != nil {
+		return err
+	}
+	role, err := callerRole(ctx)
+	if err != nil {
+		return err
+	}
+	for _, allowed := range roles {
+		inherits, err := hasRole(ctx, role, allowed)
+		if err != nil {
+			return err
+		}
+		if inherits {
+			return nil
+		}
+	}
+	if role != "citizen" {
+		return newError(ErrCodeAccessDenied, "role '%s' is not in allowed roles %v", role, roles)
+	}
+
+	callerHash, found, err := ctx.GetClientIdentity().GetAttributeValue("aadhaarHash")
+	if err != nil {
+		return newError(ErrCodeAccessDenied, "failed to read aadhaarHash attribute: %v", err)
+	}
+	if !found || callerHash == "" {
+		return newError(ErrCodeAccessDenied, "citizen identity has no 'aadhaarHash' attribute")
+	}
+	for _, hash := range selfHashes {
+		if hash == callerHash {
+			return nil
+		}
+	}
+	return newError(ErrCodeAccessDenied, "citizens can only access their own records")
+}
+
+// ownerHashes returns the aadhaarHash of every current owner of property.
+func ownerHashes(property *LandRecord) []string {
+	hashes := make([]string, 0, len(property.CurrentOwner.Owners))
+	for _, owner := range property.CurrentOwner.Owners {
+		hashes = append(hashes, owner.AadhaarHash)
+	}
+	return hashes
+}
+
+// callerFingerprint returns the hex SHA-256 of the caller's X.509
+// certificate, which distinguishes individual enrollments.
+func callerFingerprint(ctx contractapi.TransactionContextInterface) (string, error) {
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil || cert == nil {
+		return "", newError(ErrCodeAccessDenied, "failed to read caller certificate: %v", err)
+	}
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// callerEnrollmentID returns the caller's Fabric CA enrollment ID,
+// falling back to the certificate's subject CN, or "" if neither is set.
+func callerEnrollmentID(ctx contractapi.TransactionContextInterface) string {
+	clientIdentity := ctx.GetClientIdentity()
+	if enrollmentID, _, _ := clientIdentity.GetAttributeValue("hf.EnrollmentID"); enrollmentID != "" {
+		return enrollmentID
+	}
+	if cert, err := clientIdentity.GetX509Certificate(); err == nil && cert != nil {
+		return cert.Subject.CommonName
+	}
+	return ""
+}
+
+// callerFingerprintLength is the number of fingerprint hex digits kept
+// in a caller ID.
+const callerFingerprintLength = 8
+
+// getCallerID identifies the caller for audit fields (CreatedBy,
+// UpdatedBy, StatusEntry.By and the like) as
+// "MSP:role:stateCode:enrollmentID#fp8", where enrollmentID is the Fabric
+// CA enrollment ID (or the certificate's subject CN) and fp8 the first
+// eight hex digits of the certificate fingerprint, so that two officers
+// with the same role in the same office can be told apart. Missing
+// attributes leave their field empty. While the caller holds an active
+// delegation the delegator is appended, e.g.
+// "StateMSP:tehsildar:AP:tehsildar-gnt-02#9f86d081 on behalf of
+// registrar-gnt-01". parseCallerID splits the string back up.
 func getCallerID(ctx contractapi.TransactionContextInterface) string {
-	role, _, _ := ctx.GetClientIdentity().GetAttributeValue("role")
-	stateCode, _, _ := ctx.GetClientIdentity().GetAttributeValue("stateCode")
-	mspID, _ := ctx.GetClientIdentity().GetMSPID()
-	if role != "" && stateCode != "" {
-		return fmt.Sprintf("%s:%s:%s", mspID, role, stateCode)
+	clientIdentity := ctx.GetClientIdentity()
+	role, _, _ := clientIdentity.GetAttributeValue("role")
+	stateCode, _, _ := clientIdentity.GetAttributeValue("stateCode")
+	mspID, _ := clientIdentity.GetMSPID()
+
+	fingerprint, _ := callerFingerprint(ctx)
+	if len(fingerprint) > callerFingerprintLength {
+		fingerprint = fingerprint[:callerFingerprintLength]
+	}
+
+	callerID := fmt.Sprintf("%s:%s:%s:%s#%s", mspID, role, stateCode, callerEnrollmentID(ctx), fingerprint)
+	if delegations, err := activeDelegations(ctx); err == nil && len(delegations) > 0 {
+		callerID += callerOnBehalfOf + delegations[0].DelegatorID
+	}
+	return callerID
+}
+
+// callerOnBehalfOf separates a caller ID from the delegator it acts for.
+const callerOnBehalfOf = " on behalf of "
+
+// parseCallerID splits a caller ID written by getCallerID into its
+// parts. Fields are separated by ":" and the fingerprint follows the last
+// "#"; the enrollment ID is everything between the third ":" and that
+// "#", so it may itself contain either character. Older IDs of the form
+// "MSP" or "MSP:role:stateCode" parse with the later fields empty, and
+// "system" parses as an MSP ID of "system".
+func parseCallerID(callerID string) CallerIdentity {
+	var identity CallerIdentity
+	if i := strings.Index(callerID, callerOnBehalfOf); i >= 0 {
+		identity.OnBehalfOf = callerID[i+len(callerOnBehalfOf):]
+		callerID = callerID[:i]
+	}
+
+	fields := strings.SplitN(callerID, ":", 4)
+	identity.MspID = fields[0]
+	if len(fields) > 1 {
+		identity.Role = fields[1]
+	}
+	if len(fields) > 2 {
+		identity.StateCode = fields[2]
 	}
-	return mspID
+	if len(fields) > 3 {
+		enrollment := fields[3]
+		if i := strings.LastIndex(enrollment, "#"); i >= 0 {
+			identity.Fingerprint = enrollment[i+1:]
+			enrollment = enrollment[:i]
+		}
+		identity.EnrollmentID = enrollment
+	}
+	return identity
 }
 
 // ============================================================
@@ -245,7 +678,7 @@ func getCallerID(ctx contractapi.TransactionContextInterface) string {
 func getActiveDisputes(ctx contractapi.TransactionContextInterface, propertyID string) ([]*DisputeRecord, error) {
 	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixDispute, []string{propertyID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query disputes for property %s: %v", propertyID, err)
+		return nil, internalError("failed to query disputes for property %s: %v", propertyID, err)
 	}
 	defer iterator.Close()
 
@@ -253,11 +686,11 @@ func getActiveDisputes(ctx contractapi.TransactionContextInterface, propertyID s
 	for iterator.HasNext() {
 		kv, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate disputes: %v", err)
+			return nil, internalError("failed to iterate disputes: %v", err)
 		}
 		var dispute DisputeRecord
 		if err := json.Unmarshal(kv.Value, &dispute); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal dispute: %v", err)
+			return nil, internalError("failed to unmarshal dispute: %v", err)
 		}
 		if dispute.Status != "RESOLVED_IN_FAVOR" && dispute.Status != "RESOLVED_AGAINST" && dispute.Status != "SETTLED" {
 			activeDisputes = append(activeDisputes, &dispute)
@@ -266,6 +699,122 @@ func getActiveDisputes(ctx contractapi.TransactionContextInterface, propertyID s
 	return activeDisputes, nil
 }
 
+// validateOwnership enforces the ownership invariants on an owner list:
+// at least one owner, every owner named with a unique aadhaarHash, and
+// positive integer shares summing to exactly 100. A sole owner with a
+// zero share is defaulted to 100 in place.
+func validateOwnership(owners []Owner) error {
+	if len(owners) == 0 {
+		return newError(ErrCodeOwnershipInvalid, "property must have at least one owner")
+	}
+	if len(owners) == 1 && owners[0].SharePercentage == 0 {
+		owners[0].SharePercentage = 100
+	}
+
+	seen := make(map[string]bool, len(owners))
+	total := 0
+	for i, owner := range owners {
+		if err := validateAadhaarHash(owner.AadhaarHash, fmt.Sprintf("owners[%d].aadhaarHash", i)); err != nil {
+			return err
+		}
+		if seen[owner.AadhaarHash] {
+			return newError(ErrCodeOwnershipInvalid, "owner[%d] duplicates aadhaarHash %s", i, owner.AadhaarHash)
+		}
+		seen[owner.AadhaarHash] = true
+		if strings.TrimSpace(owner.Name) == "" {
+			return newError(ErrCodeOwnershipInvalid, "owner[%d] (%s) has no name", i, owner.AadhaarHash)
+		}
+		if owner.SharePercentage <= 0 {
+			return newError(ErrCodeOwnershipInvalid, "owner[%d] (%s) has non-positive share %d", i, owner.AadhaarHash, owner.SharePercentage)
+		}
+		if err := validateKYCStatus(owner.KYCStatus, fmt.Sprintf("owners[%d].kycStatus", i)); err != nil {
+			return err
+		}
+		if err := validateGuardian(owner, fmt.Sprintf("owners[%d]", i)); err != nil {
+			return err
+		}
+		total += owner.SharePercentage
+	}
+	if total != 100 {
+		return newError(ErrCodeOwnershipInvalid, "shares sum to %d, must be exactly 100", total)
+	}
+	return nil
+}
+
+// putLandRecord writes a land record back to world state.
+func putLandRecord(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	property.SchemaVersion = landRecordSchemaVersion
+	landKey, err := createLandKey(ctx, property.PropertyID)
+	if err != nil {
+		return internalError("failed to create land key: %v", err)
+	}
+	propertyBytes, err := canonicalMarshal(property)
+	if err != nil {
+		return internalError("failed to marshal property: %v", err)
+	}
+	if err := ctx.GetStub().PutState(landKey, propertyBytes); err != nil {
+		return internalError("failed to update property: %v", err)
+	}
+	return nil
+}
+
+// readLandRecord reads a land record in the current shape without the
+// caller checks of GetProperty, for public verification functions that
+// only disclose hashes and flags derived from it.
+func readLandRecord(ctx contractapi.TransactionContextInterface, propertyID string) (*LandRecord, error) {
+	landKey, err := createLandKey(ctx, propertyID)
+	if err != nil {
+		return nil, internalError("failed to create land key: %v", err)
+	}
+	propertyBytes, err := ctx.GetStub().GetState(landKey)
+	if err != nil {
+		return nil, internalError("failed to read world state: %v", err)
+	}
+	if propertyBytes == nil {
+		return nil, errPropertyNotFound(propertyID)
+	}
+	var property LandRecord
+	if err := json.Unmarshal(propertyBytes, &property); err != nil {
+		return nil, internalError("failed to unmarshal property: %v", err)
+	}
+	normalizeRecord(&property)
+	return &property, nil
+}
+
+// landRecordHash returns the hash of a land record as GetStateRoot
+// consumes it for anchoring: SHA-256 over its state key followed by its
+// canonical JSON value. Called after the record is written, it lets event
+// consumers check the payload against the next anchor.
+func landRecordHash(ctx contractapi.TransactionContextInterface, property *LandRecord) (string, error) {
+	landKey, err := createLandKey(ctx, property.PropertyID)
+	if err != nil {
+		return "", internalError("failed to create land key: %v", err)
+	}
+	propertyBytes, err := canonicalMarshal(property)
+	if err != nil {
+		return "", internalError("failed to marshal property: %v", err)
+	}
+	hasher := sha256.New()
+	hasher.Write([]byte(landKey))
+	hasher.Write(propertyBytes)
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// ownerShares lists owners' hashes and shares for an event.
+func ownerShares(owners []Owner) []OwnerShare {
+	shares := make([]OwnerShare, 0, len(owners))
+	for _, owner := range owners {
+		shares = append(shares, OwnerShare{AadhaarHash: owner.AadhaarHash, SharePercentage: owner.SharePercentage})
+	}
+	return shares
+}
+
+// transferBuyerShares is the owner set a transfer proposes: the buyer
+// alone.
+func transferBuyerShares(transfer *TransferRecord) []OwnerShare {
+	return []OwnerShare{{AadhaarHash: transfer.Buyer.AadhaarHash, SharePercentage: 100}}
+}
+
 // ============================================================
 // Index Management Helpers
 // ============================================================
@@ -275,7 +824,7 @@ func getActiveDisputes(ctx contractapi.TransactionContextInterface, propertyID s
 func putOwnerIndex(ctx contractapi.TransactionContextInterface, aadhaarHash, propertyID string) error {
 	key, err := createOwnerIndexKey(ctx, aadhaarHash, propertyID)
 	if err != nil {
-		return fmt.Errorf("failed to create owner index key: %v", err)
+		return internalError("failed to create owner index key: %v", err)
 	}
 	return ctx.GetStub().PutState(key, []byte(propertyID))
 }
@@ -284,7 +833,7 @@ func putOwnerIndex(ctx contractapi.TransactionContextInterface, aadhaarHash, pro
 func deleteOwnerIndex(ctx contractapi.TransactionContextInterface, aadhaarHash, propertyID string) error {
 	key, err := createOwnerIndexKey(ctx, aadhaarHash, propertyID)
 	if err != nil {
-		return fmt.Errorf("failed to create owner index key for deletion: %v", err)
+		return internalError("failed to create owner index key for deletion: %v", err)
 	}
 	return ctx.GetStub().DelState(key)
 }
@@ -293,16 +842,144 @@ func deleteOwnerIndex(ctx contractapi.TransactionContextInterface, aadhaarHash,
 func putSurveyIndex(ctx contractapi.TransactionContextInterface, stateCode, districtCode, surveyNo, propertyID string) error {
 	key, err := createSurveyIndexKey(ctx, stateCode, districtCode, surveyNo)
 	if err != nil {
-		return fmt.Errorf("failed to create survey index key: %v", err)
+		return internalError("failed to create survey index key: %v", err)
 	}
 	return ctx.GetStub().PutState(key, []byte(propertyID))
 }
 
+// deleteSurveyIndex removes the survey number index entry.
+func deleteSurveyIndex(ctx contractapi.TransactionContextInterface, stateCode, districtCode, surveyNo string) error {
+	key, err := createSurveyIndexKey(ctx, stateCode, districtCode, surveyNo)
+	if err != nil {
+		return internalError("failed to create survey index key for deletion: %v", err)
+	}
+	return ctx.GetStub().DelState(key)
+}
+
+// surveyIndexNumber returns the survey number used in the survey index:
+// "{surveyNo}/{subSurveyNo}" when a sub-survey number is present.
+func surveyIndexNumber(surveyNo, subSurveyNo string) string {
+	if subSurveyNo != "" {
+		return surveyNo + "/" + subSurveyNo
+	}
+	return surveyNo
+}
+
 // putLocationIndex creates or updates the location index entry.
 func putLocationIndex(ctx contractapi.TransactionContextInterface, loc Location, propertyID string) error {
 	key, err := createLocationIndexKey(ctx, loc.StateCode, loc.DistrictCode, loc.TehsilCode, loc.VillageCode, propertyID)
 	if err != nil {
-		return fmt.Errorf("failed to create location index key: %v", err)
+		return internalError("failed to create location index key: %v", err)
 	}
 	return ctx.GetStub().PutState(key, []byte(propertyID))
 }
+
+// deleteLocationIndex removes the location-based index entry.
+func deleteLocationIndex(ctx contractapi.TransactionContextInterface, loc Location, propertyID string) error {
+	key, err := createLocationIndexKey(ctx, loc.StateCode, loc.DistrictCode, loc.TehsilCode, loc.VillageCode, propertyID)
+	if err != nil {
+		return internalError("failed to create location index key for deletion: %v", err)
+	}
+	return ctx.GetStub().DelState(key)
+}
+
+// removeLookupIndexes removes a property from the owner, entity, survey,
+// location, annotation and disaster indexes. The survey entry is only removed
+// while it still points at the property.
+func removeLookupIndexes(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	for _, owner := range property.CurrentOwner.Owners {
+		if err := deleteOwnerIndex(ctx, owner.AadhaarHash, property.PropertyID); err != nil {
+			return internalError("failed to remove owner index: %v", err)
+		}
+		if err := deleteEntityIndex(ctx, owner, property.PropertyID); err != nil {
+			return internalError("failed to remove entity index: %v", err)
+		}
+	}
+	surveyKey, err := createSurveyIndexKey(ctx, property.Location.StateCode, property.Location.DistrictCode, surveyIndexNumber(property.SurveyNumber, property.SubSurveyNumber))
+	if err != nil {
+		return internalError("failed to create survey index key: %v", err)
+	}
+	if holder, _ := ctx.GetStub().GetState(surveyKey); string(holder) == property.PropertyID {
+		if err := ctx.GetStub().DelState(surveyKey); err != nil {
+			return internalError("failed to remove survey index: %v", err)
+		}
+	}
+	if err := deleteLocationIndex(ctx, property.Location, property.PropertyID); err != nil {
+		return internalError("failed to remove location index: %v", err)
+	}
+	if property.DisasterStatus != nil {
+		if err := deleteDisasterIndex(ctx, property); err != nil {
+			return err
+		}
+	}
+	return deleteAnnotationIndexes(ctx, property)
+}
+
+// restoreLookupIndexes puts a property back into the owner, entity,
+// survey, location, annotation and disaster indexes.
+func restoreLookupIndexes(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	for _, owner := range property.CurrentOwner.Owners {
+		if err := putOwnerIndex(ctx, owner.AadhaarHash, property.PropertyID); err != nil {
+			return internalError("failed to restore owner index: %v", err)
+		}
+		if err := putEntityIndex(ctx, owner, property.PropertyID); err != nil {
+			return internalError("failed to restore entity index: %v", err)
+		}
+	}
+	surveyNo := surveyIndexNumber(property.SurveyNumber, property.SubSurveyNumber)
+	if err := putSurveyIndex(ctx, property.Location.StateCode, property.Location.DistrictCode, surveyNo, property.PropertyID); err != nil {
+		return internalError("failed to restore survey index: %v", err)
+	}
+	if err := putLocationIndex(ctx, property.Location, property.PropertyID); err != nil {
+		return internalError("failed to restore location index: %v", err)
+	}
+	if property.DisasterStatus != nil {
+		if err := putDisasterIndex(ctx, property); err != nil {
+			return err
+		}
+	}
+	return putAnnotationIndexes(ctx, property)
+}
+
+// ============================================================
+// Property Status Helper
+// ============================================================
+
+// setPropertyStatus moves an existing land record to newStatus and emits
+// PROPERTY_STATUS_CHANGED; every status transition goes through it so
+// none happens without an event. The caller still writes the record. A
+// record's initial status, set at registration, split or merge, is
+// announced by that operation's own event instead.
+func setPropertyStatus(ctx contractapi.TransactionContextInterface, property *LandRecord, newStatus, reason string) error {
+	oldStatus := property.Status
+	if oldStatus == newStatus {
+		return nil
+	}
+	property.Status = newStatus
+	if err := countStatusChange(ctx, property.Location.StateCode, "landRecord", oldStatus, newStatus); err != nil {
+		return err
+	}
+
+	change := PropertyStatusChange{
+		PropertyID: property.PropertyID,
+		OldStatus:  oldStatus,
+		NewStatus:  newStatus,
+		Reason:     reason,
+		Actor:      getCallerID(ctx),
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := PropertyStatusChangedEvent{
+		Type:       "PROPERTY_STATUS_CHANGED",
+		PropertyID: change.PropertyID,
+		OldStatus:  change.OldStatus,
+		NewStatus:  change.NewStatus,
+		Reason:     change.Reason,
+		Actor:      change.Actor,
+		FabricTxID: ctx.GetStub().GetTxID(),
+		Timestamp:  time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:  property.Location.StateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	return emitRelatedEvent(ctx, "PROPERTY_STATUS_CHANGED", "statusChanges", change, event)
+}
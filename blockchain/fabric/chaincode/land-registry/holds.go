@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// INVESTIGATION HOLDS
+// ============================================================
+// An enforcement agency investigating a benami or proceeds-of-crime
+// case attaches a property by its own order, not a court's. The hold is
+// kept apart from a court freeze: it stops transfers, mutations,
+// successions and subdivisions, but banks may still record charges. It
+// carries a review-by date; holds past it are listed by
+// QueryExpiredHolds for review and are never released automatically.
+// National-scope agencies need "enforcement" in the state's
+// NationalWriteRoles.
+
+// PlaceInvestigationHold places an investigation hold on a property.
+// holdJSON is an InvestigationHold giving the agency, its attachment
+// order reference and a review-by date (YYYY-MM-DD) after the
+// transaction date. Only enforcement or admin identities with access to
+// the property's state can place one. Emits HOLD_PLACED. Returns the
+// hold ID.
+func (s *LandRegistryContract) PlaceInvestigationHold(ctx contractapi.TransactionContextInterface, propertyID, holdJSON string) (string, error) {
+	if _, err := requireAnyRole(ctx, "enforcement", "admin"); err != nil {
+		return "", err
+	}
+	if err := validatePropertyID(propertyID); err != nil {
+		return "", err
+	}
+
+	var hold InvestigationHold
+	if err := json.Unmarshal([]byte(holdJSON), &hold); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse hold JSON: %v", err)
+	}
+	hold.Agency = strings.TrimSpace(hold.Agency)
+	hold.AttachmentOrderRef = strings.TrimSpace(hold.AttachmentOrderRef)
+	if hold.Agency == "" || hold.AttachmentOrderRef == "" {
+		return "", newError(ErrCodeValidationError, "agency and attachmentOrderRef are required")
+	}
+	if hold.OrderDocumentHash != "" {
+		if err := validateDocumentHash(hold.OrderDocumentHash, "orderDocumentHash"); err != nil {
+			return "", err
+		}
+	}
+	if _, err := time.Parse("2006-01-02", hold.ReviewBy); err != nil {
+		return "", newError(ErrCodeValidationError, "reviewBy must be YYYY-MM-DD")
+	}
+
+	property, err := readLandRecord(ctx, propertyID)
+	if err != nil {
+		return "", err
+	}
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return "", err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return "", err
+	}
+	if property.InvestigationHold != nil {
+		return "", newError(ErrCodeInvestigationHoldExists, "%s is already held under order %s of %s",
+			propertyID, property.InvestigationHold.AttachmentOrderRef, property.InvestigationHold.Agency)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0)
+	now := txTime.Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	if hold.ReviewBy <= txTime.Format("2006-01-02") {
+		return "", newError(ErrCodeValidationError, "reviewBy must be after the transaction date")
+	}
+
+	hold.HoldID = "HOLD-" + txID[:8]
+	hold.PlacedBy = getCallerID(ctx)
+	hold.PlacedAt = now
+	hold.FabricTxID = txID
+	property.InvestigationHold = &hold
+	property.UpdatedAt = now
+	property.UpdatedBy = hold.PlacedBy
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return "", err
+	}
+	if err := putHoldIndex(ctx, property); err != nil {
+		return "", err
+	}
+
+	if err := recordAudit(ctx, "PlaceInvestigationHold", propertyID); err != nil {
+		return "", err
+	}
+	if err := emitEvent(ctx, "HOLD_PLACED", newHoldEvent(ctx, "HOLD_PLACED", property, &hold, "")); err != nil {
+		return "", err
+	}
+	return hold.HoldID, nil
+}
+
+// ReleaseInvestigationHold lifts the investigation hold on a property.
+// releaseOrderRef is the agency's release order or the court order
+// setting the attachment aside. Enforcement, court or admin identities
+// with access to the property's state can release a hold. Emits
+// HOLD_RELEASED.
+func (s *LandRegistryContract) ReleaseInvestigationHold(ctx contractapi.TransactionContextInterface, propertyID, releaseOrderRef string) error {
+	if _, err := requireAnyRole(ctx, "enforcement", "court", "admin"); err != nil {
+		return err
+	}
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(releaseOrderRef) == "" {
+		return newError(ErrCodeValidationError, "releaseOrderRef is required to release a hold")
+	}
+
+	property, err := readLandRecord(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+	hold := property.InvestigationHold
+	if hold == nil {
+		return newError(ErrCodeInvestigationHoldNotFound, "%s has no investigation hold", propertyID)
+	}
+	if err := deleteHoldIndex(ctx, property); err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	property.InvestigationHold = nil
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+
+	if err := recordAudit(ctx, "ReleaseInvestigationHold", propertyID); err != nil {
+		return err
+	}
+	return emitEvent(ctx, "HOLD_RELEASED", newHoldEvent(ctx, "HOLD_RELEASED", property, hold, releaseOrderRef))
+}
+
+// QueryExpiredHolds lists the investigation holds in a state whose
+// review-by date has passed, oldest first, for the agency or admin to
+// renew or release. Holds stay in force until released.
+func (s *LandRegistryContract) QueryExpiredHolds(ctx contractapi.TransactionContextInterface, stateCode string) ([]*InvestigationHold, error) {
+	if _, err := requireAnyRole(ctx, "enforcement", "court", "admin"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	today := time.Unix(timestamp.Seconds, 0).Format("2006-01-02")
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixInvestigationHold, []string{stateCode})
+	if err != nil {
+		return nil, internalError("failed to query hold index: %v", err)
+	}
+	defer iterator.Close()
+
+	holds := []*InvestigationHold{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate hold index: %v", err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) != 3 {
+			continue
+		}
+		// Keys sort by review-by date, so the rest are not yet due
+		if parts[1] >= today {
+			break
+		}
+		property, err := readLandRecord(ctx, parts[2])
+		if err != nil || property.InvestigationHold == nil {
+			continue
+		}
+		holds = append(holds, property.InvestigationHold)
+	}
+	return holds, nil
+}
+
+// requireNoInvestigationHold fails if a property is under an
+// investigation hold.
+func requireNoInvestigationHold(property *LandRecord) error {
+	if property.InvestigationHold == nil {
+		return nil
+	}
+	return newError(ErrCodeLandUnderInvestigation, "property %s is held by %s under order %s",
+		property.PropertyID, property.InvestigationHold.Agency, property.InvestigationHold.AttachmentOrderRef)
+}
+
+// newHoldEvent builds a HOLD_PLACED or HOLD_RELEASED event.
+func newHoldEvent(ctx contractapi.TransactionContextInterface, eventType string, property *LandRecord, hold *InvestigationHold, releaseOrderRef string) HoldEvent {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	return HoldEvent{
+		Type:               eventType,
+		PropertyID:         property.PropertyID,
+		HoldID:             hold.HoldID,
+		Agency:             hold.Agency,
+		AttachmentOrderRef: hold.AttachmentOrderRef,
+		ReviewBy:           hold.ReviewBy,
+		ReleaseOrderRef:    releaseOrderRef,
+		FabricTxID:         ctx.GetStub().GetTxID(),
+		Timestamp:          time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:          property.Location.StateCode,
+		ChannelID:          ctx.GetStub().GetChannelID(),
+	}
+}
+
+// createHoldIndexKey returns a property's key in the investigation hold
+// index.
+func createHoldIndexKey(ctx contractapi.TransactionContextInterface, property *LandRecord) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixInvestigationHold, []string{
+		property.Location.StateCode, property.InvestigationHold.ReviewBy, property.PropertyID,
+	})
+	if err != nil {
+		return "", internalError("failed to create hold index key: %v", err)
+	}
+	return key, nil
+}
+
+// putHoldIndex adds a held property to the investigation hold index.
+func putHoldIndex(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	key, err := createHoldIndexKey(ctx, property)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, []byte(property.PropertyID)); err != nil {
+		return internalError("failed to write hold index: %v", err)
+	}
+	return nil
+}
+
+// deleteHoldIndex removes a held property from the investigation hold
+// index.
+func deleteHoldIndex(ctx contractapi.TransactionContextInterface, property *LandRecord) error {
+	key, err := createHoldIndexKey(ctx, property)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return internalError("failed to remove hold index: %v", err)
+	}
+	return nil
+}
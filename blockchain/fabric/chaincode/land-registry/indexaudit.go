@@ -0,0 +1,612 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// INDEX AUDIT
+// ============================================================
+// Several write paths update the owner, survey and location indexes
+// fire-and-forget, so an index can drift from the records it serves.
+// AuditIndexes checks one district in slices, in three phases:
+//
+//   - RECORDS: each land record is checked against its own entries. A
+//     live record must have an owner entry per current owner, its
+//     location entry and the survey entry for its survey number; a
+//     SPLIT, MERGED or ARCHIVED record, or the CANCELLED child of a
+//     reverted split, must have none of them.
+//   - LOCATION and SURVEY: each entry of the district is checked for a
+//     record that exists and still has that location or survey number.
+//
+// Owner entries are keyed by owner, not district, so only those of the
+// district's records are checked. The audit only reads; RepairIndexes
+// applies its findings.
+
+// maxIndexAuditRecords caps the keys one AuditIndexes call scans.
+const maxIndexAuditRecords = 500
+
+// Index names and discrepancy kinds of an IndexDiscrepancy.
+const (
+	indexOwner    = "OWNER"
+	indexSurvey   = "SURVEY"
+	indexLocation = "LOCATION"
+
+	discrepancyMissing      = "MISSING"
+	discrepancyOrphaned     = "ORPHANED"
+	discrepancyStalePointer = "STALE_POINTER"
+)
+
+// Index audit phases, in the order they run.
+const (
+	indexAuditRecords  = "RECORDS"
+	indexAuditLocation = "LOCATION"
+	indexAuditSurvey   = "SURVEY"
+)
+
+// AuditIndexes checks up to maxRecords keys of a district's land records
+// and index entries and reports every discrepancy found. Pass an empty
+// bookmark to start and the returned bookmark to continue until Done;
+// each call covers part of one phase, so a call may report nothing and
+// still not be done. Only admins in the state can audit its indexes.
+func (s *LandRegistryContract) AuditIndexes(ctx contractapi.TransactionContextInterface, stateCode, districtCode string, maxRecords int, bookmark string) (*IndexAuditReport, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" || districtCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode and districtCode are required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return nil, err
+	}
+	if maxRecords <= 0 || maxRecords > maxIndexAuditRecords {
+		return nil, newError(ErrCodeValidationError, "maxRecords must be between 1 and %d", maxIndexAuditRecords)
+	}
+
+	phase, pageBookmark := indexAuditRecords, ""
+	if bookmark != "" {
+		var ok bool
+		phase, pageBookmark, ok = strings.Cut(bookmark, "|")
+		if !ok || (phase != indexAuditRecords && phase != indexAuditLocation && phase != indexAuditSurvey) {
+			return nil, newError(ErrCodeValidationError, "bookmark was not returned by AuditIndexes")
+		}
+	}
+
+	report := &IndexAuditReport{
+		StateCode:     stateCode,
+		DistrictCode:  districtCode,
+		Phase:         phase,
+		Discrepancies: []IndexDiscrepancy{},
+	}
+	var next string
+	var exhausted bool
+	var err error
+	switch phase {
+	case indexAuditRecords:
+		next, exhausted, err = auditRecordIndexes(ctx, report, maxRecords, pageBookmark)
+	case indexAuditLocation:
+		next, exhausted, err = auditLocationEntries(ctx, report, maxRecords, pageBookmark)
+	default:
+		next, exhausted, err = auditSurveyEntries(ctx, report, maxRecords, pageBookmark)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !exhausted:
+		report.Bookmark = phase + "|" + next
+	case phase == indexAuditRecords:
+		report.Bookmark = indexAuditLocation + "|"
+	case phase == indexAuditLocation:
+		report.Bookmark = indexAuditSurvey + "|"
+	default:
+		report.Done = true
+	}
+	return report, nil
+}
+
+// auditRecordIndexes checks one page of land records of the report's
+// district against their own index entries. Land keys sort by property
+// ID, which starts with the state and district, so the phase ends at the
+// first record past the district.
+func auditRecordIndexes(ctx contractapi.TransactionContextInterface, report *IndexAuditReport, maxRecords int, bookmark string) (string, bool, error) {
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(KeyPrefixLand, []string{}, int32(maxRecords), bookmark)
+	if err != nil {
+		return "", false, internalError("failed to iterate land records: %v", err)
+	}
+	defer iterator.Close()
+
+	prefix := report.StateCode + "-" + report.DistrictCode + "-"
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return "", false, internalError("failed to iterate land records: %v", err)
+		}
+		report.Scanned++
+		_, attrs, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(attrs) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(attrs[0], prefix) {
+			if attrs[0] > prefix {
+				return "", true, nil
+			}
+			continue
+		}
+		var property LandRecord
+		if err := json.Unmarshal(kv.Value, &property); err != nil {
+			return "", false, internalError("failed to unmarshal %s: %v", attrs[0], err)
+		}
+		normalizeRecord(&property)
+		report.Checked++
+		if err := auditPropertyIndexes(ctx, report, &property); err != nil {
+			return "", false, err
+		}
+	}
+	if metadata.FetchedRecordsCount < int32(maxRecords) {
+		return "", true, nil
+	}
+	return metadata.Bookmark, false, nil
+}
+
+// auditPropertyIndexes checks one land record's owner, survey and
+// location entries.
+func auditPropertyIndexes(ctx contractapi.TransactionContextInterface, report *IndexAuditReport, property *LandRecord) error {
+	retired := retiredFromIndexes(property.Status)
+	stub := ctx.GetStub()
+
+	for _, owner := range property.CurrentOwner.Owners {
+		key, err := createOwnerIndexKey(ctx, owner.AadhaarHash, property.PropertyID)
+		if err != nil {
+			return internalError("failed to create owner index key: %v", err)
+		}
+		value, err := stub.GetState(key)
+		if err != nil {
+			return internalError("failed to read owner index: %v", err)
+		}
+		attrs := []string{owner.AadhaarHash, property.PropertyID}
+		if value == nil && !retired {
+			report.add(indexOwner, discrepancyMissing, attrs, property.PropertyID, "", "current owner has no owner entry")
+		} else if value != nil && retired {
+			report.add(indexOwner, discrepancyOrphaned, attrs, property.PropertyID, "", "owner entry names a "+property.Status+" record")
+		}
+	}
+
+	loc := property.Location
+	surveyNo := surveyIndexNumber(property.SurveyNumber, property.SubSurveyNumber)
+	surveyKey, err := createSurveyIndexKey(ctx, loc.StateCode, loc.DistrictCode, surveyNo)
+	if err != nil {
+		return internalError("failed to create survey index key: %v", err)
+	}
+	holder, err := stub.GetState(surveyKey)
+	if err != nil {
+		return internalError("failed to read survey index: %v", err)
+	}
+	surveyAttrs := []string{loc.StateCode, loc.DistrictCode, surveyNo}
+	switch {
+	case retired:
+		if string(holder) == property.PropertyID {
+			report.add(indexSurvey, discrepancyOrphaned, surveyAttrs, property.PropertyID, "", "survey entry names a "+property.Status+" record")
+		}
+	case holder == nil:
+		report.add(indexSurvey, discrepancyMissing, surveyAttrs, property.PropertyID, "", "survey number has no survey entry")
+	case string(holder) != property.PropertyID:
+		// Two live records may not share a survey number; only a pointer
+		// to a record that has given the number up is stale
+		other, err := readIndexedRecord(ctx, string(holder))
+		if err != nil {
+			return err
+		}
+		if other == nil || retiredFromIndexes(other.Status) || !sameSurvey(other, property) {
+			report.add(indexSurvey, discrepancyStalePointer, surveyAttrs, property.PropertyID, string(holder), "survey entry names "+string(holder))
+		}
+	}
+
+	locationKey, err := createLocationIndexKey(ctx, loc.StateCode, loc.DistrictCode, loc.TehsilCode, loc.VillageCode, property.PropertyID)
+	if err != nil {
+		return internalError("failed to create location index key: %v", err)
+	}
+	value, err := stub.GetState(locationKey)
+	if err != nil {
+		return internalError("failed to read location index: %v", err)
+	}
+	locationAttrs := []string{loc.StateCode, loc.DistrictCode, loc.TehsilCode, loc.VillageCode, property.PropertyID}
+	if value == nil && !retired {
+		report.add(indexLocation, discrepancyMissing, locationAttrs, property.PropertyID, "", "record has no location entry")
+	} else if value != nil && retired {
+		report.add(indexLocation, discrepancyOrphaned, locationAttrs, property.PropertyID, "", "location entry names a "+property.Status+" record")
+	}
+	return nil
+}
+
+// auditLocationEntries checks one page of the district's location
+// entries for records that are gone or have moved. Entries of retired
+// records are reported by the RECORDS phase.
+func auditLocationEntries(ctx contractapi.TransactionContextInterface, report *IndexAuditReport, maxRecords int, bookmark string) (string, bool, error) {
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(KeyPrefixLocationIndex,
+		[]string{report.StateCode, report.DistrictCode}, int32(maxRecords), bookmark)
+	if err != nil {
+		return "", false, internalError("failed to query location index: %v", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return "", false, internalError("failed to iterate location index: %v", err)
+		}
+		report.Scanned++
+		_, attrs, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(attrs) != 5 {
+			continue
+		}
+		report.Checked++
+		property, err := readIndexedRecord(ctx, attrs[4])
+		if err != nil {
+			return "", false, err
+		}
+		switch {
+		case property == nil:
+			report.add(indexLocation, discrepancyOrphaned, attrs, attrs[4], "", "location entry names a record that does not exist")
+		case property.Location.TehsilCode != attrs[2] || property.Location.VillageCode != attrs[3] ||
+			property.Location.StateCode != attrs[0] || property.Location.DistrictCode != attrs[1]:
+			report.add(indexLocation, discrepancyOrphaned, attrs, attrs[4], "", "record is no longer at this location")
+		}
+	}
+	if metadata.FetchedRecordsCount < int32(maxRecords) {
+		return "", true, nil
+	}
+	return metadata.Bookmark, false, nil
+}
+
+// auditSurveyEntries checks one page of the district's survey entries
+// for records that are gone or no longer carry the survey number.
+// Entries of retired records are reported by the RECORDS phase.
+func auditSurveyEntries(ctx contractapi.TransactionContextInterface, report *IndexAuditReport, maxRecords int, bookmark string) (string, bool, error) {
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(KeyPrefixSurveyIndex,
+		[]string{report.StateCode, report.DistrictCode}, int32(maxRecords), bookmark)
+	if err != nil {
+		return "", false, internalError("failed to query survey index: %v", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return "", false, internalError("failed to iterate survey index: %v", err)
+		}
+		report.Scanned++
+		_, attrs, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(attrs) != 3 {
+			continue
+		}
+		report.Checked++
+		propertyID := string(kv.Value)
+		property, err := readIndexedRecord(ctx, propertyID)
+		if err != nil {
+			return "", false, err
+		}
+		switch {
+		case property == nil:
+			report.add(indexSurvey, discrepancyOrphaned, attrs, propertyID, "", "survey entry names a record that does not exist")
+		case property.Location.StateCode != attrs[0] || property.Location.DistrictCode != attrs[1] ||
+			surveyIndexNumber(property.SurveyNumber, property.SubSurveyNumber) != attrs[2]:
+			report.add(indexSurvey, discrepancyOrphaned, attrs, propertyID, "", fmt.Sprintf("%s no longer has survey number %s", propertyID, attrs[2]))
+		}
+	}
+	if metadata.FetchedRecordsCount < int32(maxRecords) {
+		return "", true, nil
+	}
+	return metadata.Bookmark, false, nil
+}
+
+// add appends a discrepancy to the report.
+func (r *IndexAuditReport) add(index, kind string, attrs []string, propertyID, pointsTo, detail string) {
+	r.Discrepancies = append(r.Discrepancies, IndexDiscrepancy{
+		Index:      index,
+		Kind:       kind,
+		Attributes: attrs,
+		PropertyID: propertyID,
+		PointsTo:   pointsTo,
+		Detail:     detail,
+	})
+}
+
+// readIndexedRecord reads the land record an index entry names, or nil
+// if there is none.
+func readIndexedRecord(ctx contractapi.TransactionContextInterface, propertyID string) (*LandRecord, error) {
+	landKey, err := createLandKey(ctx, propertyID)
+	if err != nil {
+		return nil, internalError("failed to create land key: %v", err)
+	}
+	propertyBytes, err := ctx.GetStub().GetState(landKey)
+	if err != nil {
+		return nil, internalError("failed to read %s: %v", propertyID, err)
+	}
+	if propertyBytes == nil {
+		return nil, nil
+	}
+	var property LandRecord
+	if err := json.Unmarshal(propertyBytes, &property); err != nil {
+		return nil, internalError("failed to unmarshal %s: %v", propertyID, err)
+	}
+	normalizeRecord(&property)
+	return &property, nil
+}
+
+// retiredFromIndexes reports whether a land record in status should be
+// out of the lookup indexes.
+func retiredFromIndexes(status string) bool {
+	return status == "SPLIT" || status == "MERGED" || status == "ARCHIVED" || status == "CANCELLED"
+}
+
+// sameSurvey reports whether two land records carry the same survey
+// number in the same district.
+func sameSurvey(a, b *LandRecord) bool {
+	return a.Location.StateCode == b.Location.StateCode && a.Location.DistrictCode == b.Location.DistrictCode &&
+		surveyIndexNumber(a.SurveyNumber, a.SubSurveyNumber) == surveyIndexNumber(b.SurveyNumber, b.SubSurveyNumber)
+}
+
+// ============================================================
+// INDEX REPAIR
+// ============================================================
+// Each discrepancy is checked again against the ledger as it stands when
+// the repair runs, not as the audit saw it: one that no longer holds is
+// skipped with a note, never forced. Missing entries are rebuilt from
+// the land record, orphaned ones deleted and stale survey entries
+// pointed back at the record that carries the survey number.
+
+// RepairIndexes applies the corrections for up to maxBulkRecords
+// discrepancies reported by AuditIndexes. repairRequestJSON is an
+// IndexRepairRequest giving the stateCode and the discrepancies, all of
+// that state. Every outcome, applied or skipped, is kept in an index
+// repair log with the operator's identity. Only admins in the state can
+// repair its indexes. Emits INDEX_REPAIRED. Returns the log.
+func (s *LandRegistryContract) RepairIndexes(ctx contractapi.TransactionContextInterface, repairRequestJSON string) (*IndexRepairLog, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+	var req IndexRepairRequest
+	if err := json.Unmarshal([]byte(repairRequestJSON), &req); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse repair request JSON: %v", err)
+	}
+	if req.StateCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
+	}
+	if len(req.Discrepancies) == 0 || len(req.Discrepancies) > maxBulkRecords {
+		return nil, newError(ErrCodeValidationError, "between 1 and %d discrepancies are required", maxBulkRecords)
+	}
+	if err := requireStateAccess(ctx, req.StateCode); err != nil {
+		return nil, err
+	}
+	for i, d := range req.Discrepancies {
+		if err := validateDiscrepancy(d, req.StateCode); err != nil {
+			return nil, errorAt(fmt.Sprintf("discrepancies[%d]", i), err)
+		}
+	}
+
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
+
+	repairLog := &IndexRepairLog{
+		DocType:         "indexRepairLog",
+		RepairID:        "rep_" + txID[:8],
+		StateCode:       req.StateCode,
+		Repairs:         make([]IndexRepairEntry, 0, len(req.Discrepancies)),
+		RepairedBy:      getCallerID(ctx),
+		RepairedByMspID: mspID,
+		CertFingerprint: fingerprint,
+		RepairedAt:      now,
+		FabricTxID:      txID,
+	}
+	// GetState does not see this transaction's writes, so a key is
+	// repaired at most once per call
+	touched := map[string]bool{}
+	for _, d := range req.Discrepancies {
+		key, err := ctx.GetStub().CreateCompositeKey(indexKeyPrefix(d.Index), d.Attributes)
+		if err != nil {
+			return nil, internalError("failed to create %s index key: %v", d.Index, err)
+		}
+		entry := IndexRepairEntry{Discrepancy: d, Action: "SKIPPED"}
+		if touched[key] {
+			entry.Note = "entry already repaired in this request"
+		} else {
+			entry.Action, entry.Note, err = repairIndexEntry(ctx, key, d)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if entry.Action == "SKIPPED" {
+			repairLog.Skipped++
+		} else {
+			touched[key] = true
+			repairLog.Applied++
+		}
+		repairLog.Repairs = append(repairLog.Repairs, entry)
+	}
+
+	logKey, err := ctx.GetStub().CreateCompositeKey(KeyPrefixIndexRepair, []string{req.StateCode, repairLog.RepairID})
+	if err != nil {
+		return nil, internalError("failed to create index repair key: %v", err)
+	}
+	logBytes, err := canonicalMarshal(repairLog)
+	if err != nil {
+		return nil, internalError("failed to marshal index repair log: %v", err)
+	}
+	if err := ctx.GetStub().PutState(logKey, logBytes); err != nil {
+		return nil, internalError("failed to write index repair log: %v", err)
+	}
+	if err := recordAudit(ctx, "RepairIndexes", repairLog.RepairID); err != nil {
+		return nil, err
+	}
+
+	event := IndexRepairedEvent{
+		Type:       "INDEX_REPAIRED",
+		RepairID:   repairLog.RepairID,
+		Applied:    repairLog.Applied,
+		Skipped:    repairLog.Skipped,
+		RepairedBy: repairLog.RepairedBy,
+		FabricTxID: txID,
+		Timestamp:  now,
+		StateCode:  req.StateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "INDEX_REPAIRED", event); err != nil {
+		return nil, err
+	}
+	return repairLog, nil
+}
+
+// repairIndexEntry re-checks one discrepancy against the current ledger
+// and applies its correction. It returns the action taken (CREATED,
+// DELETED, RETARGETED or SKIPPED) and, for a skip, why.
+func repairIndexEntry(ctx contractapi.TransactionContextInterface, key string, d IndexDiscrepancy) (string, string, error) {
+	stub := ctx.GetStub()
+	current, err := stub.GetState(key)
+	if err != nil {
+		return "", "", internalError("failed to read %s index: %v", d.Index, err)
+	}
+
+	switch d.Kind {
+	case discrepancyMissing:
+		if current != nil {
+			return "SKIPPED", "conflict: entry now exists", nil
+		}
+		property, err := readIndexedRecord(ctx, d.PropertyID)
+		if err != nil {
+			return "", "", err
+		}
+		if property == nil || retiredFromIndexes(property.Status) || !entryMatches(property, d) {
+			return "SKIPPED", "conflict: record no longer calls for this entry", nil
+		}
+		if err := stub.PutState(key, []byte(property.PropertyID)); err != nil {
+			return "", "", internalError("failed to write %s index: %v", d.Index, err)
+		}
+		return "CREATED", "", nil
+
+	case discrepancyOrphaned:
+		if current == nil {
+			return "SKIPPED", "conflict: entry no longer exists", nil
+		}
+		named := d.PropertyID
+		if d.Index == indexSurvey {
+			named = string(current)
+		}
+		property, err := readIndexedRecord(ctx, named)
+		if err != nil {
+			return "", "", err
+		}
+		if property != nil && !retiredFromIndexes(property.Status) && entryMatches(property, d) {
+			return "SKIPPED", "conflict: entry now names a live record that matches it", nil
+		}
+		if err := stub.DelState(key); err != nil {
+			return "", "", internalError("failed to remove %s index: %v", d.Index, err)
+		}
+		return "DELETED", "", nil
+
+	default: // discrepancyStalePointer
+		if string(current) != d.PointsTo {
+			return "SKIPPED", "conflict: survey entry no longer names " + d.PointsTo, nil
+		}
+		stale, err := readIndexedRecord(ctx, d.PointsTo)
+		if err != nil {
+			return "", "", err
+		}
+		if stale != nil && !retiredFromIndexes(stale.Status) && entryMatches(stale, d) {
+			return "SKIPPED", "conflict: " + d.PointsTo + " still carries the survey number", nil
+		}
+		property, err := readIndexedRecord(ctx, d.PropertyID)
+		if err != nil {
+			return "", "", err
+		}
+		if property == nil || retiredFromIndexes(property.Status) || !entryMatches(property, d) {
+			return "SKIPPED", "conflict: " + d.PropertyID + " no longer carries the survey number", nil
+		}
+		if err := stub.PutState(key, []byte(property.PropertyID)); err != nil {
+			return "", "", internalError("failed to write survey index: %v", err)
+		}
+		return "RETARGETED", "", nil
+	}
+}
+
+// entryMatches reports whether an index entry with d's attributes
+// belongs to a land record as it stands.
+func entryMatches(property *LandRecord, d IndexDiscrepancy) bool {
+	loc := property.Location
+	switch d.Index {
+	case indexOwner:
+		if d.Attributes[1] != property.PropertyID {
+			return false
+		}
+		for _, owner := range property.CurrentOwner.Owners {
+			if owner.AadhaarHash == d.Attributes[0] {
+				return true
+			}
+		}
+		return false
+	case indexSurvey:
+		return loc.StateCode == d.Attributes[0] && loc.DistrictCode == d.Attributes[1] &&
+			surveyIndexNumber(property.SurveyNumber, property.SubSurveyNumber) == d.Attributes[2]
+	default:
+		return loc.StateCode == d.Attributes[0] && loc.DistrictCode == d.Attributes[1] &&
+			loc.TehsilCode == d.Attributes[2] && loc.VillageCode == d.Attributes[3] &&
+			property.PropertyID == d.Attributes[4]
+	}
+}
+
+// validateDiscrepancy checks the shape of a discrepancy submitted to
+// RepairIndexes and that it belongs to stateCode.
+func validateDiscrepancy(d IndexDiscrepancy, stateCode string) error {
+	arity := map[string]int{indexOwner: 2, indexSurvey: 3, indexLocation: 5}[d.Index]
+	if arity == 0 {
+		return newError(ErrCodeValidationError, "index must be OWNER, SURVEY or LOCATION")
+	}
+	if len(d.Attributes) != arity {
+		return newError(ErrCodeValidationError, "%s entries have %d attributes, got %d", d.Index, arity, len(d.Attributes))
+	}
+	switch d.Kind {
+	case discrepancyMissing, discrepancyOrphaned:
+	case discrepancyStalePointer:
+		if d.Index != indexSurvey || d.PointsTo == "" {
+			return newError(ErrCodeValidationError, "STALE_POINTER applies to survey entries and needs pointsTo")
+		}
+	default:
+		return newError(ErrCodeValidationError, "kind must be MISSING, ORPHANED or STALE_POINTER")
+	}
+	if err := validatePropertyID(d.PropertyID); err != nil {
+		return err
+	}
+	entryState := d.Attributes[0]
+	if d.Index == indexOwner {
+		entryState = extractStateCode(d.Attributes[1])
+	}
+	if entryState != stateCode {
+		return newError(ErrCodeValidationError, "entry is in %s, not %s", entryState, stateCode)
+	}
+	return nil
+}
+
+// indexKeyPrefix returns the key prefix of an index named in an
+// IndexDiscrepancy.
+func indexKeyPrefix(index string) string {
+	switch index {
+	case indexOwner:
+		return KeyPrefixOwnerIndex
+	case indexSurvey:
+		return KeyPrefixSurveyIndex
+	default:
+		return KeyPrefixLocationIndex
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// REGISTERED INSTITUTIONS
+// ============================================================
+// An encumbrance belongs to the institution named by its MSP ID. Banks
+// can only create charges under their own MSP; courts and admins, who
+// record charges on a bank's behalf, must name an institution that has
+// been registered here.
+
+// RegisterInstitution adds or replaces an institution that can hold
+// encumbrances. institutionJSON is a RegisteredInstitution; the MSP ID
+// and name are required. Only admins can register institutions. Writes
+// an audit entry.
+func (s *LandRegistryContract) RegisterInstitution(ctx contractapi.TransactionContextInterface, institutionJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	var institution RegisteredInstitution
+	if err := json.Unmarshal([]byte(institutionJSON), &institution); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse institution JSON: %v", err)
+	}
+	if institution.MspID == "" {
+		return newError(ErrCodeValidationError, "mspId is required")
+	}
+	if institution.Name == "" {
+		return newError(ErrCodeValidationError, "name is required")
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	institution.DocType = "registeredInstitution"
+	institution.RegisteredBy = getCallerID(ctx)
+	institution.RegisteredAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	institution.FabricTxID = ctx.GetStub().GetTxID()
+
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixInstitution, []string{institution.MspID})
+	if err != nil {
+		return internalError("failed to create institution key: %v", err)
+	}
+	institutionBytes, err := canonicalMarshal(institution)
+	if err != nil {
+		return internalError("failed to marshal institution: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, institutionBytes); err != nil {
+		return internalError("failed to write institution: %v", err)
+	}
+	return recordAudit(ctx, "RegisterInstitution", institution.MspID)
+}
+
+// GetInstitution returns a registered institution by MSP ID.
+func (s *LandRegistryContract) GetInstitution(ctx contractapi.TransactionContextInterface, mspID string) (*RegisteredInstitution, error) {
+	institution, err := getInstitution(ctx, mspID)
+	if err != nil {
+		return nil, err
+	}
+	if institution == nil {
+		return nil, newError(ErrCodeInstitutionNotRegistered, "%s", mspID)
+	}
+	return institution, nil
+}
+
+// getInstitution reads a registered institution, returning nil if the
+// MSP ID is not registered.
+func getInstitution(ctx contractapi.TransactionContextInterface, mspID string) (*RegisteredInstitution, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixInstitution, []string{mspID})
+	if err != nil {
+		return nil, internalError("failed to create institution key: %v", err)
+	}
+	institutionBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read institution: %v", err)
+	}
+	if institutionBytes == nil {
+		return nil, nil
+	}
+	var institution RegisteredInstitution
+	if err := json.Unmarshal(institutionBytes, &institution); err != nil {
+		return nil, internalError("failed to unmarshal institution: %v", err)
+	}
+	return &institution, nil
+}
+
+// bindInstitution sets the authoritative institution on a new
+// encumbrance. A bank's charge is always under its own MSP ID; a court
+// or admin must name a registered institution, and a branch code, if
+// given, must be one of its registered branches.
+func bindInstitution(ctx contractapi.TransactionContextInterface, role string, inst *Institution) error {
+	if role == "bank" {
+		mspID, err := ctx.GetClientIdentity().GetMSPID()
+		if err != nil {
+			return newError(ErrCodeAccessDenied, "failed to read caller MSP ID: %v", err)
+		}
+		inst.MspID = mspID
+		return nil
+	}
+
+	if inst.MspID == "" {
+		return newError(ErrCodeValidationError, "institution.mspId is required")
+	}
+	registered, err := getInstitution(ctx, inst.MspID)
+	if err != nil {
+		return err
+	}
+	if registered == nil {
+		return newError(ErrCodeInstitutionNotRegistered, "%s is not a registered institution", inst.MspID)
+	}
+	if inst.BranchCode != "" && len(registered.BranchCodes) > 0 {
+		found := false
+		for _, code := range registered.BranchCodes {
+			if code == inst.BranchCode {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return newError(ErrCodeInstitutionNotRegistered, "branch %s is not registered for %s", inst.BranchCode, inst.MspID)
+		}
+	}
+	inst.Name = registered.Name
+	return nil
+}
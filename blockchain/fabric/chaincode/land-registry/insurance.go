@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// PROPERTY INSURANCE
+// ============================================================
+// Lenders require the mortgaged property to be insured and want the
+// policy on record next to their charge. Policies are informational:
+// an expired or missing policy never blocks a transfer or encumbrance.
+// Whether a policy is active is worked out against the transaction
+// time whenever it is read.
+
+// maxExpiryWindowDays bounds QueryExpiringPolicies' look-ahead.
+const maxExpiryWindowDays = 366
+
+// RecordInsurancePolicy records an insurance policy on a property.
+// policyJSON is an InsurancePolicy with insurer, policyNumber,
+// sumInsured (paisa), startDate and endDate (YYYY-MM-DD) and
+// policyDocumentHash, and optionally the encumbranceId of the charge it
+// backs. The lender is the bank holding that charge, or else a calling
+// bank; QueryExpiringPolicies lists policies by lender. Banks and
+// registrars with jurisdiction over the property can record policies.
+// Returns the policy ID.
+func (s *LandRegistryContract) RecordInsurancePolicy(ctx contractapi.TransactionContextInterface, propertyID, policyJSON string) (string, error) {
+	role, err := requireAnyRole(ctx, "bank", "registrar")
+	if err != nil {
+		return "", err
+	}
+
+	var policy InsurancePolicy
+	if err := json.Unmarshal([]byte(policyJSON), &policy); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse policy JSON: %v", err)
+	}
+	if policy.PropertyID == "" {
+		policy.PropertyID = propertyID
+	}
+	if policy.PropertyID != propertyID {
+		return "", newError(ErrCodeValidationError, "policy propertyId %s does not match %s", policy.PropertyID, propertyID)
+	}
+	if err := validateInsurancePolicy(&policy); err != nil {
+		return "", err
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return "", err
+	}
+	if role == "registrar" {
+		if err := requireJurisdiction(ctx, property.Location); err != nil {
+			return "", err
+		}
+	}
+	if err := requireNotArchived(property); err != nil {
+		return "", err
+	}
+
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
+	policy.LenderMspID = ""
+	if role == "bank" {
+		policy.LenderMspID = mspID
+	}
+	if policy.EncumbranceID != "" {
+		enc, err := findEncumbrance(ctx, policy.EncumbranceID)
+		if err != nil {
+			return "", err
+		}
+		if enc.PropertyID != propertyID {
+			return "", newError(ErrCodeValidationError, "encumbrance %s is not on %s", policy.EncumbranceID, propertyID)
+		}
+		policy.LenderMspID = enc.Institution.MspID
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0)
+	txID := ctx.GetStub().GetTxID()
+
+	policy.DocType = "insurancePolicy"
+	policy.PolicyID = "ins_" + txID[:8]
+	policy.RecordedBy = getCallerID(ctx)
+	policy.RecordedByMspID = mspID
+	policy.RecordedAt = txTime.Format(time.RFC3339)
+	policy.FabricTxID = txID
+	policy.IsActive = policyActive(&policy, txTime.Format("2006-01-02"))
+
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixInsurance, []string{propertyID, policy.PolicyID})
+	if err != nil {
+		return "", internalError("failed to create insurance key: %v", err)
+	}
+	policyBytes, err := canonicalMarshal(policy)
+	if err != nil {
+		return "", internalError("failed to marshal policy: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, policyBytes); err != nil {
+		return "", internalError("failed to write policy %s: %v", policy.PolicyID, err)
+	}
+
+	if policy.LenderMspID != "" {
+		indexKey, err := ctx.GetStub().CreateCompositeKey(KeyPrefixInsuranceExpiry, []string{
+			policy.LenderMspID, policy.EndDate, propertyID, policy.PolicyID,
+		})
+		if err != nil {
+			return "", internalError("failed to create insurance expiry index key: %v", err)
+		}
+		if err := ctx.GetStub().PutState(indexKey, []byte(key)); err != nil {
+			return "", internalError("failed to write insurance expiry index: %v", err)
+		}
+	}
+	return policy.PolicyID, nil
+}
+
+// GetInsurancePolicies returns every policy recorded on a property,
+// latest ending first, each with isActive set for the transaction date.
+// It is readable by whoever can read the property.
+func (s *LandRegistryContract) GetInsurancePolicies(ctx contractapi.TransactionContextInterface, propertyID string) ([]*InsurancePolicy, error) {
+	if _, err := s.GetProperty(ctx, propertyID); err != nil {
+		return nil, err
+	}
+	return getInsurancePolicies(ctx, propertyID)
+}
+
+// QueryExpiringPolicies lists the active policies backing mspID's
+// lending that end within withinDays of the transaction date, soonest
+// first. Only the lending bank itself and admins can query them.
+func (s *LandRegistryContract) QueryExpiringPolicies(ctx contractapi.TransactionContextInterface, mspID string, withinDays int) ([]*InsurancePolicy, error) {
+	role, err := requireAnyRole(ctx, "bank", "admin")
+	if err != nil {
+		return nil, err
+	}
+	if mspID == "" {
+		return nil, newError(ErrCodeValidationError, "mspId is required")
+	}
+	if role == "bank" {
+		callerMspID, _ := ctx.GetClientIdentity().GetMSPID()
+		if callerMspID != mspID {
+			return nil, newError(ErrCodeAccessDenied, "%s cannot list the policies of %s", callerMspID, mspID)
+		}
+	}
+	if withinDays < 0 || withinDays > maxExpiryWindowDays {
+		return nil, newError(ErrCodeValidationError, "withinDays must be between 0 and %d", maxExpiryWindowDays)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0).UTC()
+	today := txTime.Format("2006-01-02")
+	horizon := txTime.AddDate(0, 0, withinDays).Format("2006-01-02")
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixInsuranceExpiry, []string{mspID})
+	if err != nil {
+		return nil, internalError("failed to query insurance expiry index: %v", err)
+	}
+	defer iterator.Close()
+
+	policies := []*InsurancePolicy{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate insurance expiry index: %v", err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) != 4 {
+			continue
+		}
+		// Keys sort by end date
+		if parts[1] < today {
+			continue
+		}
+		if parts[1] > horizon {
+			break
+		}
+		policyBytes, err := ctx.GetStub().GetState(string(kv.Value))
+		if err != nil {
+			return nil, internalError("failed to read policy %s: %v", parts[3], err)
+		}
+		if policyBytes == nil {
+			continue
+		}
+		var policy InsurancePolicy
+		if err := json.Unmarshal(policyBytes, &policy); err != nil {
+			return nil, internalError("failed to unmarshal policy: %v", err)
+		}
+		policy.IsActive = policyActive(&policy, today)
+		if policy.IsActive {
+			policies = append(policies, &policy)
+		}
+	}
+	return policies, nil
+}
+
+// validateInsurancePolicy checks a policy submitted to
+// RecordInsurancePolicy.
+func validateInsurancePolicy(policy *InsurancePolicy) error {
+	if err := validatePropertyID(policy.PropertyID); err != nil {
+		return err
+	}
+	policy.Insurer = strings.TrimSpace(policy.Insurer)
+	policy.PolicyNumber = strings.TrimSpace(policy.PolicyNumber)
+	if policy.Insurer == "" || policy.PolicyNumber == "" {
+		return newError(ErrCodeValidationError, "insurer and policyNumber are required")
+	}
+	if policy.SumInsured <= 0 {
+		return newError(ErrCodeValidationError, "sumInsured must be positive")
+	}
+	start, err := time.Parse("2006-01-02", policy.StartDate)
+	if err != nil {
+		return newError(ErrCodeValidationError, "startDate must be YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", policy.EndDate)
+	if err != nil {
+		return newError(ErrCodeValidationError, "endDate must be YYYY-MM-DD")
+	}
+	if !end.After(start) {
+		return newError(ErrCodeValidationError, "endDate must be after startDate")
+	}
+	return validateDocumentHash(policy.PolicyDocumentHash, "policyDocumentHash")
+}
+
+// policyActive reports whether a policy covers today (YYYY-MM-DD).
+func policyActive(policy *InsurancePolicy, today string) bool {
+	return policy.StartDate <= today && today <= policy.EndDate
+}
+
+// getInsurancePolicies reads a property's policies, latest ending first,
+// with isActive set for the transaction date.
+func getInsurancePolicies(ctx contractapi.TransactionContextInterface, propertyID string) ([]*InsurancePolicy, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixInsurance, []string{propertyID})
+	if err != nil {
+		return nil, internalError("failed to query insurance policies: %v", err)
+	}
+	defer iterator.Close()
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	today := time.Unix(timestamp.Seconds, 0).Format("2006-01-02")
+
+	policies := []*InsurancePolicy{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate insurance policies: %v", err)
+		}
+		var policy InsurancePolicy
+		if err := json.Unmarshal(kv.Value, &policy); err != nil {
+			return nil, internalError("failed to unmarshal policy: %v", err)
+		}
+		policy.IsActive = policyActive(&policy, today)
+		policies = append(policies, &policy)
+	}
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].EndDate != policies[j].EndDate {
+			return policies[i].EndDate > policies[j].EndDate
+		}
+		return policies[i].RecordedAt > policies[j].RecordedAt
+	})
+	return policies, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// newTestOfficer returns a TelanganaMSP identity of role scoped by the
+// optional districtCode and tehsilCode attributes; "-" leaves one unset.
+func newTestOfficer(t *testing.T, role, stateCode, districtCode, tehsilCode string) *testIdentity {
+	t.Helper()
+	officer := newTestIdentity(t, "TelanganaMSP", role, stateCode)
+	if districtCode != "-" {
+		officer.attrs["districtCode"] = districtCode
+	}
+	if tehsilCode != "-" {
+		officer.attrs["tehsilCode"] = tehsilCode
+	}
+	return officer
+}
+
+func TestRequireJurisdiction(t *testing.T) {
+	// The test property is in TS / HYD / SRN
+	tests := []struct {
+		name                    string
+		state, district, tehsil string
+		want                    string
+	}{
+		{"state-wide enrollment", "TS", "-", "-", ""},
+		{"empty narrower attributes", "TS", "", "", ""},
+		{"own district", "TS", "HYD", "-", ""},
+		{"other district", "TS", "RNG", "-", ErrCodeJurisdictionMismatch},
+		{"own district and tehsil", "TS", "HYD", "SRN", ""},
+		{"own district, other tehsil", "TS", "HYD", "GWK", ErrCodeJurisdictionMismatch},
+		{"other district, own tehsil code", "TS", "RNG", "SRN", ErrCodeJurisdictionMismatch},
+		{"own tehsil without district", "TS", "-", "SRN", ""},
+		{"other tehsil without district", "TS", "-", "GWK", ErrCodeJurisdictionMismatch},
+		{"other state", "KA", "HYD", "SRN", ErrCodeStateMismatch},
+		{"no state", "", "HYD", "SRN", ErrCodeAccessDenied},
+	}
+	location := testProperty("142", 1).Location
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			officer := newTestOfficer(t, "tehsildar", tc.state, tc.district, tc.tehsil)
+			err := ledger.submit(officer, func(ctx contractapi.TransactionContextInterface) error {
+				return requireJurisdiction(ctx, location)
+			})
+			expectCode(t, err, tc.want)
+		})
+	}
+}
+
+func TestRecordTaxPaymentRequiresJurisdiction(t *testing.T) {
+	ledger := newTestLedger(t)
+	property := testProperty("142", 1)
+	ledger.registerTestProperty(newTestIdentity(t, "TelanganaMSP", "registrar", "TS"), property)
+
+	record := func(officer *testIdentity, receipt string) error {
+		paymentJSON, _ := json.Marshal(TaxPayment{Year: "2026-27", Amount: 120000, ReceiptNumber: receipt, PaidDate: "2027-03-01"})
+		return ledger.submit(officer, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.RecordTaxPayment(ctx, property.PropertyID, string(paymentJSON))
+		})
+	}
+
+	expectCode(t, record(newTestOfficer(t, "tehsildar", "TS", "RNG", "-"), "R-1"), ErrCodeJurisdictionMismatch)
+	expectCode(t, record(newTestOfficer(t, "tehsildar", "TS", "HYD", "GWK"), "R-2"), ErrCodeJurisdictionMismatch)
+	if got := ledger.readProperty(property.PropertyID).TaxInfo.PaidUpToYear; got != "" {
+		t.Fatalf("paidUpToYear = %q after refused payments", got)
+	}
+
+	if err := record(newTestOfficer(t, "tehsildar", "TS", "HYD", "SRN"), "R-3"); err != nil {
+		t.Fatalf("tehsildar of SRN: %v", err)
+	}
+	if err := record(newTestOfficer(t, "tehsildar", "TS", "-", "-"), "R-4"); err != nil {
+		t.Fatalf("state-wide tehsildar: %v", err)
+	}
+	if got := ledger.readProperty(property.PropertyID).TaxInfo.PaidUpToYear; got != "2026-27" {
+		t.Fatalf("paidUpToYear = %q, want 2026-27", got)
+	}
+}
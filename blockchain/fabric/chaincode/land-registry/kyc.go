@@ -0,0 +1,121 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// OWNER KYC STATUS
+// ============================================================
+// Owners verified through Aadhaar eKYC are treated differently by
+// downstream processes (loans, DBT) from legacy owners whose hashes were
+// migrated without verification.
+
+// kycStatuses is the owner KYC status vocabulary.
+var kycStatuses = map[string]bool{
+	"VERIFIED":            true,
+	"MIGRATED_UNVERIFIED": true,
+	"PENDING":             true,
+}
+
+// validateKYCStatus checks an owner's KYC status against the vocabulary.
+// An empty status is allowed; the registration path fills in a default.
+func validateKYCStatus(status, field string) error {
+	if status == "" || kycStatuses[status] {
+		return nil
+	}
+	return newError(ErrCodeValidationError, "%s '%s' must be VERIFIED, MIGRATED_UNVERIFIED or PENDING", field, status)
+}
+
+// applyOwnerKYCDefaults sets defaultStatus on owners without a KYC
+// status and stamps the verifier on owners submitted as VERIFIED.
+func applyOwnerKYCDefaults(ctx contractapi.TransactionContextInterface, owners []Owner, defaultStatus, now string) {
+	for i := range owners {
+		if owners[i].KYCStatus == "" {
+			owners[i].KYCStatus = defaultStatus
+		}
+		if owners[i].KYCStatus == "VERIFIED" && owners[i].KYCVerifiedAt == "" {
+			owners[i].KYCVerifiedAt = now
+			owners[i].KYCVerifiedBy = getCallerID(ctx)
+		}
+	}
+}
+
+// MarkOwnerKYCVerified records that an owner's Aadhaar was verified
+// through eKYC. verificationRef is the eKYC transaction reference from
+// UIDAI. Only registrars in the property's state can verify owners.
+// Emits OWNER_KYC_VERIFIED.
+func (s *LandRegistryContract) MarkOwnerKYCVerified(ctx contractapi.TransactionContextInterface, propertyID, aadhaarHash, verificationRef string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(aadhaarHash, "aadhaarHash"); err != nil {
+		return err
+	}
+	if verificationRef == "" {
+		return newError(ErrCodeValidationError, "verificationRef is required")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return err
+	}
+
+	var owner *Owner
+	for i := range property.CurrentOwner.Owners {
+		if property.CurrentOwner.Owners[i].AadhaarHash == aadhaarHash {
+			owner = &property.CurrentOwner.Owners[i]
+			break
+		}
+	}
+	if owner == nil {
+		return newError(ErrCodeOwnerNotFound, "%s is not a current owner of property %s", aadhaarHash, propertyID)
+	}
+	if owner.KYCStatus == "VERIFIED" {
+		return newError(ErrCodeKycAlreadyVerified, "owner %s was verified at %s", aadhaarHash, owner.KYCVerifiedAt)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	previousStatus := owner.KYCStatus
+	owner.KYCStatus = "VERIFIED"
+	owner.KYCVerifiedAt = now
+	owner.KYCVerifiedBy = getCallerID(ctx)
+	owner.KYCVerificationRef = verificationRef
+
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+
+	event := OwnerKYCVerifiedEvent{
+		Type:            "OWNER_KYC_VERIFIED",
+		PropertyID:      propertyID,
+		OwnerHash:       aadhaarHash,
+		PreviousStatus:  previousStatus,
+		VerificationRef: verificationRef,
+		VerifiedBy:      owner.KYCVerifiedBy,
+		FabricTxID:      txID,
+		Timestamp:       now,
+		StateCode:       property.Location.StateCode,
+		ChannelID:       ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "OWNER_KYC_VERIFIED", event)
+}
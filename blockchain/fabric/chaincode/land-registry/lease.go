@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LEASES
+// ============================================================
+// A registered long-term lease (an agricultural tenancy, a commercial
+// ground lease) binds whoever owns the land, so a buyer must know of it.
+// Leases do not block transfers: a transfer of leased land must instead
+// disclose every lease active on it (see checkLeasesDisclosed).
+
+// leaseTypes lists the registrable lease types.
+var leaseTypes = map[string]bool{
+	"AGRICULTURAL_TENANCY": true,
+	"COMMERCIAL_GROUND":    true,
+	"RESIDENTIAL":          true,
+	"OTHER":                true,
+}
+
+// RegisterLease registers a lease on a property. leaseJSON is a
+// LeaseRecord with propertyId, leaseType, lessor (a current owner),
+// lessee, startDate and endDate (YYYY-MM-DD), annualRent (paisa) and
+// leaseDeedHash. Marks the property LEASED. Only registrars with
+// jurisdiction over the property can register leases. Emits
+// LEASE_REGISTERED.
+func (s *LandRegistryContract) RegisterLease(ctx contractapi.TransactionContextInterface, leaseJSON string) (string, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return "", err
+	}
+
+	var lease LeaseRecord
+	if err := json.Unmarshal([]byte(leaseJSON), &lease); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse lease JSON: %v", err)
+	}
+	if err := validateLease(&lease); err != nil {
+		return "", err
+	}
+
+	property, err := s.GetProperty(ctx, lease.PropertyID)
+	if err != nil {
+		return "", err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return "", err
+	}
+	if property.Status == "FROZEN" {
+		return "", newError(ErrCodeLandFrozen, "cannot register a lease on frozen property %s", lease.PropertyID)
+	}
+	if err := requireNotArchived(property); err != nil {
+		return "", err
+	}
+
+	lessorIsOwner := false
+	for _, owner := range property.CurrentOwner.Owners {
+		if owner.AadhaarHash == lease.Lessor.AadhaarHash {
+			lessorIsOwner = true
+			break
+		}
+	}
+	if !lessorIsOwner {
+		return "", newError(ErrCodeLeaseLessorNotOwner, "lessor is not a current owner of %s", lease.PropertyID)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	if lease.EndDate < now[:10] {
+		return "", newError(ErrCodeValidationError, "lease ended on %s", lease.EndDate)
+	}
+
+	lease.DocType = "leaseRecord"
+	lease.LeaseID = "lse_" + txID[:8]
+	lease.Status = "ACTIVE"
+	lease.RegisteredBy = getCallerID(ctx)
+	lease.RegisteredAt = now
+	lease.FabricTxID = txID
+	if err := putLease(ctx, &lease); err != nil {
+		return "", err
+	}
+
+	property.LeaseStatus = "LEASED"
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return "", err
+	}
+
+	if err := emitLeaseEvent(ctx, "LEASE_REGISTERED", &lease, property.Location.StateCode); err != nil {
+		return "", err
+	}
+	return lease.LeaseID, nil
+}
+
+// TerminateLease ends an active lease early, e.g. on surrender or a
+// court's eviction order, recording reason. The property stops being
+// LEASED once no active lease remains. Only registrars with jurisdiction
+// over the property can terminate leases. Emits LEASE_TERMINATED.
+func (s *LandRegistryContract) TerminateLease(ctx contractapi.TransactionContextInterface, propertyID, leaseID, reason string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixLease, []string{propertyID, leaseID})
+	if err != nil {
+		return internalError("failed to create lease key: %v", err)
+	}
+	leaseBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return internalError("failed to read lease: %v", err)
+	}
+	if leaseBytes == nil {
+		return newError(ErrCodeLeaseNotFound, "lease %s does not exist on %s", leaseID, propertyID)
+	}
+	var lease LeaseRecord
+	if err := json.Unmarshal(leaseBytes, &lease); err != nil {
+		return internalError("failed to unmarshal lease: %v", err)
+	}
+	if lease.Status != "ACTIVE" {
+		return newError(ErrCodeLeaseNotActive, "lease %s has status %s", leaseID, lease.Status)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	lease.Status = "TERMINATED"
+	lease.TerminationReason = reason
+	lease.TerminatedBy = getCallerID(ctx)
+	lease.TerminatedAt = now
+	lease.FabricTxID = txID
+	if err := putLease(ctx, &lease); err != nil {
+		return err
+	}
+
+	remaining, err := getActiveLeases(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if len(remaining) == 0 {
+		property.LeaseStatus = ""
+	}
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+
+	return emitLeaseEvent(ctx, "LEASE_TERMINATED", &lease, property.Location.StateCode)
+}
+
+// GetLeases returns every lease registered on a property, active,
+// expired and terminated.
+func (s *LandRegistryContract) GetLeases(ctx contractapi.TransactionContextInterface, propertyID string) ([]*LeaseRecord, error) {
+	if err := validatePropertyID(propertyID); err != nil {
+		return nil, err
+	}
+	return getLeases(ctx, propertyID)
+}
+
+// validateLease checks a lease submitted to RegisterLease.
+func validateLease(lease *LeaseRecord) error {
+	if err := validatePropertyID(lease.PropertyID); err != nil {
+		return err
+	}
+	lease.LeaseType = strings.ToUpper(lease.LeaseType)
+	if !leaseTypes[lease.LeaseType] {
+		return newError(ErrCodeValidationError, "leaseType '%s' must be AGRICULTURAL_TENANCY, COMMERCIAL_GROUND, RESIDENTIAL or OTHER", lease.LeaseType)
+	}
+	if err := validateAadhaarHash(lease.Lessor.AadhaarHash, "lessor.aadhaarHash"); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(lease.Lessee.AadhaarHash, "lessee.aadhaarHash"); err != nil {
+		return err
+	}
+	if lease.Lessee.AadhaarHash == lease.Lessor.AadhaarHash {
+		return newError(ErrCodeValidationError, "lessee cannot be the lessor")
+	}
+	lease.Lessee.OwnerType = normalizeOwnerType(lease.Lessee.OwnerType)
+	if !ownerTypes[lease.Lessee.OwnerType] {
+		return newError(ErrCodeValidationError, "lessee.ownerType '%s' must be INDIVIDUAL, HUF, COMPANY, TRUST or GOVERNMENT", lease.Lessee.OwnerType)
+	}
+	if err := validateEntityDetails(lease.Lessee.OwnerType, lease.Lessee.Entity, lease.Lessee.IsMinor, "lessee"); err != nil {
+		return err
+	}
+
+	start, err := time.Parse("2006-01-02", lease.StartDate)
+	if err != nil {
+		return newError(ErrCodeValidationError, "startDate must be YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", lease.EndDate)
+	if err != nil {
+		return newError(ErrCodeValidationError, "endDate must be YYYY-MM-DD")
+	}
+	if !end.After(start) {
+		return newError(ErrCodeValidationError, "endDate must be after startDate")
+	}
+	if lease.AnnualRent < 0 {
+		return newError(ErrCodeValidationError, "annualRent cannot be negative")
+	}
+	return validateDocumentHash(lease.LeaseDeedHash, "leaseDeedHash")
+}
+
+// checkLeasesDisclosed fails unless a transfer lists every lease active
+// on the property in disclosedLeaseIds. The lease itself does not block
+// the transfer; it passes to the buyer with the land.
+func checkLeasesDisclosed(ctx contractapi.TransactionContextInterface, transfer *TransferRecord) error {
+	active, err := getActiveLeases(ctx, transfer.PropertyID)
+	if err != nil {
+		return err
+	}
+	disclosed := map[string]bool{}
+	for _, leaseID := range transfer.DisclosedLeaseIDs {
+		disclosed[leaseID] = true
+	}
+	var missing []string
+	for _, lease := range active {
+		if !disclosed[lease.LeaseID] {
+			missing = append(missing, lease.LeaseID)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return newError(ErrCodeLeaseNotDisclosed, "transfer of %s must disclose active lease(s): %s", transfer.PropertyID, strings.Join(missing, ", ")).
+			with("leaseIds", strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// getActiveLeases returns the leases on a property that are ACTIVE and
+// have not reached their end date at the transaction time.
+func getActiveLeases(ctx contractapi.TransactionContextInterface, propertyID string) ([]*LeaseRecord, error) {
+	leases, err := getLeases(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	today := time.Unix(timestamp.Seconds, 0).Format("2006-01-02")
+
+	var active []*LeaseRecord
+	for _, lease := range leases {
+		if lease.Status == "ACTIVE" && lease.EndDate >= today {
+			active = append(active, lease)
+		}
+	}
+	return active, nil
+}
+
+// getLeases reads the leases under LEASE~{propertyId}.
+func getLeases(ctx contractapi.TransactionContextInterface, propertyID string) ([]*LeaseRecord, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixLease, []string{propertyID})
+	if err != nil {
+		return nil, internalError("failed to query leases: %v", err)
+	}
+	defer iterator.Close()
+
+	leases := []*LeaseRecord{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate leases: %v", err)
+		}
+		var lease LeaseRecord
+		if err := json.Unmarshal(kv.Value, &lease); err != nil {
+			return nil, internalError("failed to unmarshal lease: %v", err)
+		}
+		leases = append(leases, &lease)
+	}
+	return leases, nil
+}
+
+// putLease writes a lease under its composite key.
+func putLease(ctx contractapi.TransactionContextInterface, lease *LeaseRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixLease, []string{lease.PropertyID, lease.LeaseID})
+	if err != nil {
+		return internalError("failed to create lease key: %v", err)
+	}
+	leaseBytes, err := canonicalMarshal(lease)
+	if err != nil {
+		return internalError("failed to marshal lease: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, leaseBytes); err != nil {
+		return internalError("failed to write lease %s: %v", lease.LeaseID, err)
+	}
+	return nil
+}
+
+func emitLeaseEvent(ctx contractapi.TransactionContextInterface, eventType string, lease *LeaseRecord, stateCode string) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := LeaseEvent{
+		Type:       eventType,
+		LeaseID:    lease.LeaseID,
+		PropertyID: lease.PropertyID,
+		LeaseType:  lease.LeaseType,
+		LessorHash: lease.Lessor.AadhaarHash,
+		LesseeHash: lease.Lessee.AadhaarHash,
+		StartDate:  lease.StartDate,
+		EndDate:    lease.EndDate,
+		AnnualRent: lease.AnnualRent,
+		Reason:     lease.TerminationReason,
+		FabricTxID: ctx.GetStub().GetTxID(),
+		Timestamp:  time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:  stateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, eventType, event)
+}
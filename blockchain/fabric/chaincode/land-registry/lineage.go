@@ -0,0 +1,137 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// SPLIT TREE
+// ============================================================
+// Provenance records each parcel's parent, which is enough to walk up
+// from a child. Walking down needs the CHILDREN index, written whenever
+// a split, partition or merge creates a property from another.
+
+// maxSplitTreeDepth and maxSplitTreeNodes bound the work a single
+// GetSplitTree call can do.
+const (
+	maxSplitTreeDepth = 10
+	maxSplitTreeNodes = 500
+)
+
+// createChildIndexKey creates a composite key for the children index.
+func createChildIndexKey(ctx contractapi.TransactionContextInterface, parentID, childID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(KeyPrefixChildIndex, []string{parentID, childID})
+}
+
+// putChildIndex records that childID was created from parentID.
+func putChildIndex(ctx contractapi.TransactionContextInterface, parentID, childID string) error {
+	key, err := createChildIndexKey(ctx, parentID, childID)
+	if err != nil {
+		return internalError("failed to create children index key: %v", err)
+	}
+	return ctx.GetStub().PutState(key, []byte(childID))
+}
+
+// deleteChildIndex forgets that childID was created from parentID.
+func deleteChildIndex(ctx contractapi.TransactionContextInterface, parentID, childID string) error {
+	key, err := createChildIndexKey(ctx, parentID, childID)
+	if err != nil {
+		return internalError("failed to create children index key: %v", err)
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return internalError("failed to remove children index: %v", err)
+	}
+	return nil
+}
+
+// GetSplitTree returns the descendants of a property created by
+// successive splits, partitions and merges, down to maxDepth levels
+// (at most 10; zero or less means 10). A merged property appears under
+// each of its sources. The walk stops after 500 nodes, marking the
+// nodes it could not expand as Truncated, and never revisits a property
+// already on the current path.
+func (s *LandRegistryContract) GetSplitTree(ctx contractapi.TransactionContextInterface, propertyID string, maxDepth int) (*SplitTreeNode, error) {
+	if maxDepth <= 0 || maxDepth > maxSplitTreeDepth {
+		maxDepth = maxSplitTreeDepth
+	}
+
+	root, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := 0
+	return s.splitTreeNode(ctx, root, maxDepth, map[string]bool{}, &visited)
+}
+
+// splitTreeNode builds the node for property and, depth permitting, its
+// children. onPath holds the properties between the root and this node.
+func (s *LandRegistryContract) splitTreeNode(ctx contractapi.TransactionContextInterface, property *LandRecord, depth int, onPath map[string]bool, visited *int) (*SplitTreeNode, error) {
+	*visited++
+	node := &SplitTreeNode{
+		PropertyID: property.PropertyID,
+		Relation:   splitRelation(property),
+		Status:     property.Status,
+		Area:       property.Area,
+		OwnerCount: len(property.CurrentOwner.Owners),
+		Children:   []*SplitTreeNode{},
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixChildIndex, []string{property.PropertyID})
+	if err != nil {
+		return nil, internalError("failed to query children index: %v", err)
+	}
+	defer iterator.Close()
+
+	var childIDs []string
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate children index: %v", err)
+		}
+		childIDs = append(childIDs, string(kv.Value))
+	}
+	if len(childIDs) == 0 {
+		return node, nil
+	}
+	if depth == 0 || *visited >= maxSplitTreeNodes {
+		node.Truncated = true
+		return node, nil
+	}
+
+	onPath[property.PropertyID] = true
+	defer delete(onPath, property.PropertyID)
+
+	for _, childID := range childIDs {
+		if onPath[childID] {
+			node.Children = append(node.Children, &SplitTreeNode{PropertyID: childID, Cycle: true, Children: []*SplitTreeNode{}})
+			continue
+		}
+		if *visited >= maxSplitTreeNodes {
+			node.Truncated = true
+			break
+		}
+		child, err := s.GetProperty(ctx, childID)
+		if err != nil {
+			return nil, err
+		}
+		childNode, err := s.splitTreeNode(ctx, child, depth-1, onPath, visited)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
+
+// splitRelation describes how a property came from its parent: SPLIT,
+// MERGE, or empty for an original registration.
+func splitRelation(property *LandRecord) string {
+	switch {
+	case len(property.Provenance.MergedFrom) > 0:
+		return "MERGE"
+	case property.Provenance.SplitFrom != "":
+		return "SPLIT"
+	}
+	return ""
+}
@@ -10,7 +10,10 @@ import (
 )
 
 func main() {
-	landRegistryChaincode, err := contractapi.NewChaincode(&LandRegistryContract{})
+	contract := new(LandRegistryContract)
+	contract.TransactionContextHandler = new(LandRegistryContext)
+
+	landRegistryChaincode, err := contractapi.NewChaincode(contract)
 	if err != nil {
 		log.Panicf("Error creating land-registry chaincode: %v", err)
 	}
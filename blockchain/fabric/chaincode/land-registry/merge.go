@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ============================================================
+// MERGE VALIDATION
+// ============================================================
+// Only neighbouring parcels can be merged into one record, and the merged
+// record's location and area are derived from the sources rather than
+// taken from the caller's payload.
+
+// mergeLocationLevels lists the location levels sources must share, from
+// loosest to strictest. Settings pick the level; the default is VILLAGE.
+var mergeLocationLevels = map[string]int{
+	"STATE":    1,
+	"DISTRICT": 2,
+	"TEHSIL":   3,
+	"VILLAGE":  4,
+}
+
+// mergeAreaTolerance is how far a caller-supplied merged area may differ
+// from the sum of the sources.
+const mergeAreaTolerance = 0.01
+
+// normalizeMergeLocationLevel defaults an empty level to VILLAGE.
+func normalizeMergeLocationLevel(level string) string {
+	if level == "" {
+		return "VILLAGE"
+	}
+	return level
+}
+
+// locationCodes returns a location's codes down to the given depth
+// (1 = state only, 4 = down to village).
+func locationCodes(loc Location, depth int) []string {
+	return []string{loc.StateCode, loc.DistrictCode, loc.TehsilCode, loc.VillageCode}[:depth]
+}
+
+// sameLocationCodes reports whether two locations share every code down
+// to the given depth.
+func sameLocationCodes(a, b Location, depth int) bool {
+	ac, bc := locationCodes(a, depth), locationCodes(b, depth)
+	for i := range ac {
+		if ac[i] != bc[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateMergeLocation requires every source to share the first one's
+// location down to level, and the merged record's location to be that
+// of one of the sources; names and PIN code are then taken from that
+// source. An empty merged location takes the first source's.
+func validateMergeLocation(sources []*LandRecord, merged *LandRecord, level string) error {
+	level = normalizeMergeLocationLevel(level)
+	depth, ok := mergeLocationLevels[level]
+	if !ok {
+		return newError(ErrCodeValidationError, "mergeLocationLevel '%s' must be STATE, DISTRICT, TEHSIL or VILLAGE", level)
+	}
+
+	first := sources[0].Location
+	for i, prop := range sources[1:] {
+		if !sameLocationCodes(first, prop.Location, depth) {
+			return newError(ErrCodeMergeNotColocated, "property[%d]: %s is not in the same %s as %s",
+				i+1, prop.PropertyID, strings.ToLower(level), sources[0].PropertyID)
+		}
+	}
+
+	if merged.Location == (Location{}) {
+		merged.Location = first
+		return nil
+	}
+	for _, prop := range sources {
+		if sameLocationCodes(merged.Location, prop.Location, len(mergeLocationLevels)) {
+			merged.Location = prop.Location
+			return nil
+		}
+	}
+	return newError(ErrCodeMergeLocationMismatch, "merged location %s/%s/%s/%s does not match any source property",
+		merged.Location.StateCode, merged.Location.DistrictCode, merged.Location.TehsilCode, merged.Location.VillageCode)
+}
+
+// mergedArea sums the sources' areas into the merged record's units. The
+// standard value is expressed in the merged record's unit (or the first
+// source's, if none is given) and rejected if the caller's value differs
+// from the sum by more than 1%. Local values are summed only when every
+// source records one.
+func mergedArea(sources []*LandRecord, requested Area, bighaSqMeters float64) (Area, error) {
+	unit := requested.Unit
+	if unit == "" {
+		unit = sources[0].Area.Unit
+	}
+	localUnit := requested.LocalUnit
+	if localUnit == "" {
+		localUnit = sources[0].Area.LocalUnit
+	}
+
+	var total, localTotal float64
+	haveLocal := localUnit != ""
+	for i, prop := range sources {
+		value, err := ConvertArea(prop.Area.Value, prop.Area.Unit, unit, bighaSqMeters)
+		if err != nil {
+			return Area{}, errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+		total += value
+
+		if prop.Area.LocalUnit == "" || prop.Area.LocalVal == 0 {
+			haveLocal = false
+		}
+		if haveLocal {
+			local, err := ConvertArea(prop.Area.LocalVal, prop.Area.LocalUnit, localUnit, bighaSqMeters)
+			if err != nil {
+				return Area{}, errorAt(fmt.Sprintf("property[%d]", i), err)
+			}
+			localTotal += local
+		}
+	}
+
+	if total <= 0 {
+		return Area{}, newError(ErrCodeValidationError, "source properties have no recorded area")
+	}
+	if requested.Value != 0 && math.Abs(requested.Value-total)/total > mergeAreaTolerance {
+		return Area{}, newError(ErrCodeAreaMismatch, "merged area (%.2f %s) does not match the sources' total (%.2f %s)",
+			requested.Value, unit, total, unit)
+	}
+
+	area := Area{Value: total, Unit: unit}
+	if haveLocal {
+		area.LocalVal = localTotal
+		area.LocalUnit = localUnit
+	}
+	return area, nil
+}
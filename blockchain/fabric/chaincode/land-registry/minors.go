@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// MINOR OWNERS AND GUARDIANS
+// ============================================================
+// A minor's property can only be dealt with by their guardian, and a
+// transfer needs a court order (Rule 4). Every minor owner therefore
+// carries the guardian entitled to act for them.
+
+// guardianAppointmentTypes lists how a guardian can be appointed.
+var guardianAppointmentTypes = map[string]bool{
+	"NATURAL":         true,
+	"COURT_APPOINTED": true,
+}
+
+// validateGuardian checks the guardian of an owner: required for a minor,
+// not allowed for an adult. field is the owner's path for error messages.
+func validateGuardian(owner Owner, field string) error {
+	if !owner.IsMinor {
+		if owner.Guardian != nil {
+			return newError(ErrCodeGuardianInvalid, "%s is not a minor and cannot have a guardian", field)
+		}
+		return nil
+	}
+
+	g := owner.Guardian
+	if g == nil {
+		return newError(ErrCodeGuardianRequired, "%s is a minor and must have a guardian", field)
+	}
+	if g.Name == "" {
+		return newError(ErrCodeGuardianInvalid, "%s.guardian.name is required", field)
+	}
+	if err := validateAadhaarHash(g.AadhaarHash, field+".guardian.aadhaarHash"); err != nil {
+		return err
+	}
+	if g.AadhaarHash == owner.AadhaarHash {
+		return newError(ErrCodeGuardianInvalid, "%s cannot be their own guardian", field)
+	}
+	if g.Relationship == "" {
+		return newError(ErrCodeGuardianInvalid, "%s.guardian.relationship is required", field)
+	}
+	if !guardianAppointmentTypes[g.AppointmentType] {
+		return newError(ErrCodeGuardianInvalid, "%s.guardian.appointmentType must be NATURAL or COURT_APPOINTED", field)
+	}
+	if g.AppointmentType == "COURT_APPOINTED" && g.CourtOrderRef == "" {
+		return newError(ErrCodeGuardianInvalid, "%s.guardian.courtOrderRef is required for a court-appointed guardian", field)
+	}
+	return nil
+}
+
+// AttainMajority clears the minor flag and guardian of an owner who has
+// turned 18. proofDocHash is the hash of the date-of-birth proof; if the
+// owner's dateOfBirth is on record, the 18th birthday must have passed.
+// The change is appended to the Corrections audit trail.
+// Only registrars in the property's state can record majority.
+// Emits OWNER_ATTAINED_MAJORITY.
+func (s *LandRegistryContract) AttainMajority(ctx contractapi.TransactionContextInterface, propertyID, aadhaarHash, proofDocHash string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(aadhaarHash, "aadhaarHash"); err != nil {
+		return err
+	}
+	if err := validateDocumentHash(proofDocHash, "proofDocHash"); err != nil {
+		return err
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return err
+	}
+
+	var owner *Owner
+	for i := range property.CurrentOwner.Owners {
+		if property.CurrentOwner.Owners[i].AadhaarHash == aadhaarHash {
+			owner = &property.CurrentOwner.Owners[i]
+			break
+		}
+	}
+	if owner == nil {
+		return newError(ErrCodeOwnerNotFound, "%s is not a current owner of property %s", aadhaarHash, propertyID)
+	}
+	if !owner.IsMinor {
+		return newError(ErrCodeOwnerNotMinor, "owner %s is not recorded as a minor", aadhaarHash)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0).UTC()
+	now := txTime.Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	if owner.DateOfBirth != "" {
+		dob, err := time.Parse("2006-01-02", owner.DateOfBirth)
+		if err != nil {
+			return newError(ErrCodeValidationError, "recorded dateOfBirth '%s' is not YYYY-MM-DD", owner.DateOfBirth)
+		}
+		if majority := dob.AddDate(18, 0, 0); txTime.Before(majority) {
+			return newError(ErrCodeOwnerStillMinor, "owner %s turns 18 on %s", aadhaarHash, majority.Format("2006-01-02"))
+		}
+	}
+
+	guardianName := ""
+	if owner.Guardian != nil {
+		guardianName = owner.Guardian.Name
+	}
+	changes := appendChange(nil, fmt.Sprintf("currentOwner.owners[%s].isMinor", aadhaarHash), "true", "false")
+	changes = appendChange(changes, fmt.Sprintf("currentOwner.owners[%s].guardian", aadhaarHash), guardianName, "")
+
+	owner.IsMinor = false
+	owner.Guardian = nil
+
+	entry := CorrectionEntry{
+		CorrectionID: "cor_" + txID[:8],
+		Type:         "ATTAIN_MAJORITY",
+		Changes:      changes,
+		Reason:       "owner attained majority",
+		DocumentHash: proofDocHash,
+		CorrectedBy:  getCallerID(ctx),
+		CorrectedAt:  now,
+		FabricTxID:   txID,
+	}
+	property.Corrections = append(property.Corrections, entry)
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+
+	event := OwnerAttainedMajorityEvent{
+		Type:         "OWNER_ATTAINED_MAJORITY",
+		PropertyID:   propertyID,
+		CorrectionID: entry.CorrectionID,
+		OwnerHash:    aadhaarHash,
+		ProofDocHash: proofDocHash,
+		FabricTxID:   txID,
+		Timestamp:    now,
+		StateCode:    property.Location.StateCode,
+		ChannelID:    ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "OWNER_ATTAINED_MAJORITY", event)
+}
@@ -1,5 +1,7 @@
 package main
 
+import "encoding/json"
+
 // ============================================================
 // LandRecord — Primary entity representing a property on-chain
 // ============================================================
@@ -7,30 +9,95 @@ package main
 // LandRecord is the core land ownership document stored in Fabric world state.
 // All financial fields are in paisa (int64) to avoid floating point errors.
 type LandRecord struct {
-	DocType            string           `json:"docType"`
-	PropertyID         string           `json:"propertyId"`
-	SurveyNumber       string           `json:"surveyNumber"`
-	SubSurveyNumber    string           `json:"subSurveyNumber"`
-	Location           Location         `json:"location"`
-	Area               Area             `json:"area"`
-	Boundaries         Boundaries       `json:"boundaries"`
-	CurrentOwner       OwnerInfo        `json:"currentOwner"`
-	LandUse            string           `json:"landUse"`
-	LandClassification string           `json:"landClassification"`
-	Status             string           `json:"status"`
-	DisputeStatus      string           `json:"disputeStatus"`
-	EncumbranceStatus  string           `json:"encumbranceStatus"`
-	CoolingPeriod      CoolingPeriod    `json:"coolingPeriod"`
-	TaxInfo            TaxInfo          `json:"taxInfo"`
-	RegistrationInfo   RegistrationInfo `json:"registrationInfo"`
-	AlgorandInfo       AlgorandInfo     `json:"algorandInfo"`
-	PolygonInfo        PolygonInfo      `json:"polygonInfo"`
-	Provenance         Provenance       `json:"provenance"`
-	FabricTxID         string           `json:"fabricTxId"`
-	CreatedAt          string           `json:"createdAt"`
-	UpdatedAt          string           `json:"updatedAt"`
-	CreatedBy          string           `json:"createdBy"`
-	UpdatedBy          string           `json:"updatedBy"`
+	DocType            string             `json:"docType"`
+	SchemaVersion      int                `json:"schemaVersion"`
+	PropertyID         string             `json:"propertyId"`
+	SurveyNumber       string             `json:"surveyNumber"`
+	SubSurveyNumber    string             `json:"subSurveyNumber"`
+	Location           Location           `json:"location"`
+	Area               Area               `json:"area"`
+	Boundaries         Boundaries         `json:"boundaries"`
+	CurrentOwner       OwnerInfo          `json:"currentOwner"`
+	LandUse            string             `json:"landUse"`
+	LandClassification string             `json:"landClassification"`
+	Status             string             `json:"status"`
+	DisputeStatus      string             `json:"disputeStatus"`
+	EncumbranceStatus  string             `json:"encumbranceStatus"`
+	CoolingPeriod      CoolingPeriod      `json:"coolingPeriod"`
+	TaxInfo            TaxInfo            `json:"taxInfo"`
+	RegistrationInfo   RegistrationInfo   `json:"registrationInfo"`
+	AlgorandInfo       AlgorandInfo       `json:"algorandInfo"`
+	PolygonInfo        PolygonInfo        `json:"polygonInfo"`
+	Provenance         Provenance         `json:"provenance"`
+	FabricTxID         string             `json:"fabricTxId"`
+	CreatedAt          string             `json:"createdAt"`
+	UpdatedAt          string             `json:"updatedAt"`
+	CreatedBy          string             `json:"createdBy"`
+	UpdatedBy          string             `json:"updatedBy"`
+	Corrections        []CorrectionEntry  `json:"corrections,omitempty"`
+	DataQualityFlags   []string           `json:"dataQualityFlags,omitempty"`
+	Documents          []PropertyDocument `json:"documents,omitempty"`
+	Archival           *ArchivalInfo      `json:"archival,omitempty"`
+	TaxPayments        []TaxPayment       `json:"taxPayments,omitempty"`
+	// BoundaryConflict is set when the parcel's polygon overlaps another
+	// parcel's; see QueryBoundaryConflicts.
+	BoundaryConflict bool `json:"boundaryConflict,omitempty"`
+	// LeaseStatus is LEASED while a registered lease is active on the
+	// property; see GetLeases.
+	LeaseStatus string `json:"leaseStatus,omitempty"`
+	// Annotations record wells, borewells and similar fixtures; see
+	// AddAnnotation.
+	Annotations []LandAnnotation `json:"annotations,omitempty"`
+	// InvestigationHold is set while an enforcement agency has attached
+	// the property; see PlaceInvestigationHold.
+	InvestigationHold *InvestigationHold `json:"investigationHold,omitempty"`
+	// DisasterStatus is set while the parcel is flagged as affected by a
+	// notified disaster; see MarkDisasterAffected.
+	DisasterStatus *DisasterStatus `json:"disasterStatus,omitempty"`
+}
+
+// DisasterStatus flags a parcel as affected by a notified disaster.
+// Severity is MINOR, MODERATE, SEVERE or DESTROYED; Stage is AFFECTED
+// or, once rebuilding starts, REHABILITATION.
+type DisasterStatus struct {
+	EventRef    string `json:"eventRef"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity"`
+	Stage       string `json:"stage"`
+	MarkedBy    string `json:"markedBy"`
+	MarkedAt    string `json:"markedAt"`
+	FabricTxID  string `json:"fabricTxId"`
+}
+
+// InvestigationHold is an enforcement agency's attachment of a property
+// pending a benami or similar investigation. ReviewBy (YYYY-MM-DD) is
+// the date by which the agency must renew or release it.
+type InvestigationHold struct {
+	HoldID             string `json:"holdId"`
+	Agency             string `json:"agency"`
+	AttachmentOrderRef string `json:"attachmentOrderRef"`
+	OrderDocumentHash  string `json:"orderDocumentHash,omitempty"`
+	ReviewBy           string `json:"reviewBy"`
+	Notes              string `json:"notes,omitempty"`
+	PlacedBy           string `json:"placedBy"`
+	PlacedAt           string `json:"placedAt"`
+	FabricTxID         string `json:"fabricTxId"`
+}
+
+// LandAnnotation is an informational entry on a land record, such as a
+// borewell with its permit. CopiedFrom names the parcel it was copied
+// from on a split or merge.
+type LandAnnotation struct {
+	AnnotationID string `json:"annotationId"`
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	PermitRef    string `json:"permitRef,omitempty"`
+	PermitStatus string `json:"permitStatus"`
+	DocumentHash string `json:"documentHash"`
+	CopiedFrom   string `json:"copiedFrom,omitempty"`
+	AddedBy      string `json:"addedBy"`
+	AddedAt      string `json:"addedAt"`
+	FabricTxID   string `json:"fabricTxId"`
 }
 
 // Location holds the hierarchical administrative location of a property,
@@ -56,10 +123,12 @@ type Area struct {
 	LocalUnit string  `json:"localUnit"`
 }
 
-// GeoJSON represents the geographic boundary of a property.
+// GeoJSON represents the geographic boundary of a property as a
+// Polygon or MultiPolygon geometry. Coordinates are kept raw because
+// their nesting depends on Type; see validateGeoJSON.
 type GeoJSON struct {
-	Type        string        `json:"type"`
-	Coordinates [][][]float64 `json:"coordinates"`
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
 }
 
 // Boundaries describes the property boundary by adjacent landmarks/owners
@@ -90,12 +159,73 @@ type Owner struct {
 	FatherName      string `json:"fatherName"`
 	SharePercentage int    `json:"sharePercentage"`
 	IsMinor         bool   `json:"isMinor"`
+	DateOfBirth     string `json:"dateOfBirth,omitempty"`
+	// Guardian acts for the owner while IsMinor; required for minors.
+	Guardian *Guardian `json:"guardian,omitempty"`
+	// Entity identifies a non-individual owner (HUF, company, trust,
+	// government); AadhaarHash is then the authorized signatory's.
+	Entity *EntityDetails `json:"entity,omitempty"`
+	// KYCStatus is VERIFIED (Aadhaar eKYC done), MIGRATED_UNVERIFIED
+	// (legacy hash from bulk migration) or PENDING.
+	KYCStatus          string `json:"kycStatus,omitempty"`
+	KYCVerifiedAt      string `json:"kycVerifiedAt,omitempty"`
+	KYCVerifiedBy      string `json:"kycVerifiedBy,omitempty"`
+	KYCVerificationRef string `json:"kycVerificationRef,omitempty"`
+	// ProtectedCategory is SC or ST where the owner is recorded as a
+	// member of a protected category (see AttestProtectedCategory).
+	// It is never shown in third-party views.
+	ProtectedCategory           string `json:"protectedCategory,omitempty"`
+	ProtectedCategoryRef        string `json:"protectedCategoryRef,omitempty"`
+	ProtectedCategoryAttestedBy string `json:"protectedCategoryAttestedBy,omitempty"`
+	ProtectedCategoryAttestedAt string `json:"protectedCategoryAttestedAt,omitempty"`
+}
+
+// Guardian is the person entitled to act for a minor owner.
+type Guardian struct {
+	Name        string `json:"name"`
+	AadhaarHash string `json:"aadhaarHash"`
+	// Relationship to the minor, e.g. FATHER, MOTHER, UNCLE.
+	Relationship string `json:"relationship"`
+	// AppointmentType is NATURAL or COURT_APPOINTED.
+	AppointmentType string `json:"appointmentType"`
+	CourtOrderRef   string `json:"courtOrderRef,omitempty"`
+}
+
+// EntityDetails holds the identity of a non-individual owner. Which
+// fields are required depends on OwnerInfo.OwnerType.
+type EntityDetails struct {
+	PAN string `json:"pan,omitempty"`
+	// CIN is the MCA Corporate Identification Number (COMPANY).
+	CIN string `json:"cin,omitempty"`
+	// KartaName is the manager of the Hindu Undivided Family (HUF).
+	KartaName               string `json:"kartaName,omitempty"`
+	TrustRegistrationNumber string `json:"trustRegistrationNumber,omitempty"`
+	// DepartmentCode identifies the owning department (GOVERNMENT).
+	DepartmentCode       string `json:"departmentCode,omitempty"`
+	SignatoryName        string `json:"signatoryName"`
+	SignatoryDesignation string `json:"signatoryDesignation,omitempty"`
+}
+
+// AddCoOwnerRequest is the input to AddCoOwner: the new co-owner plus
+// the rebalanced shares of existing owners, keyed by aadhaarHash.
+type AddCoOwnerRequest struct {
+	Owner
+	RebalancedShares map[string]int `json:"rebalancedShares"`
+}
+
+// OwnershipViolation is one record reported by AuditOwnershipInvariants.
+type OwnershipViolation struct {
+	PropertyID string `json:"propertyId"`
+	Status     string `json:"status"`
+	Violation  string `json:"violation"`
 }
 
 // CoolingPeriod tracks the 72-hour objection window after a transfer.
 type CoolingPeriod struct {
 	Active    bool   `json:"active"`
 	ExpiresAt string `json:"expiresAt"`
+	// TransferID is the transfer awaiting finality in this window.
+	TransferID string `json:"transferId,omitempty"`
 }
 
 // TaxInfo holds land revenue tax payment details (amounts in paisa).
@@ -105,12 +235,58 @@ type TaxInfo struct {
 	PaidUpToYear      string `json:"paidUpToYear"`
 }
 
+// TaxPayment is one land revenue payment recorded against a property.
+// Year is the revenue (financial) year, e.g. "2024-25".
+type TaxPayment struct {
+	PaymentID     string `json:"paymentId"`
+	Year          string `json:"year"`
+	Amount        int64  `json:"amount"`
+	ReceiptNumber string `json:"receiptNumber"`
+	PaidDate      string `json:"paidDate"`
+	PayerHash     string `json:"payerHash,omitempty"`
+	RecordedBy    string `json:"recordedBy"`
+	RecordedAt    string `json:"recordedAt"`
+	FabricTxID    string `json:"fabricTxId"`
+}
+
+// TaxPaymentDigest counts the land revenue payments recorded in a
+// district on one day (the UTC recording date, not the paid date), so the
+// treasury can check that it has seen every TAX_PAYMENT_RECORDED event.
+// TotalAmount is in paisa.
+type TaxPaymentDigest struct {
+	DocType        string `json:"docType"`
+	StateCode      string `json:"stateCode"`
+	DistrictCode   string `json:"districtCode"`
+	Date           string `json:"date"`
+	PaymentCount   int    `json:"paymentCount"`
+	TotalAmount    int64  `json:"totalAmount"`
+	LastPaymentID  string `json:"lastPaymentId,omitempty"`
+	LastFabricTxID string `json:"lastFabricTxId,omitempty"`
+}
+
+// TaxStatus is the land revenue position of a property as of a revenue
+// year. Status: CURRENT, ARREARS, or UNKNOWN when no payment has ever
+// been recorded. Amounts are in paisa.
+type TaxStatus struct {
+	PropertyID        string `json:"propertyId"`
+	AsOfYear          string `json:"asOfYear"`
+	AnnualLandRevenue int64  `json:"annualLandRevenue"`
+	PaidUpToYear      string `json:"paidUpToYear"`
+	LastPaidDate      string `json:"lastPaidDate"`
+	YearsInArrears    int    `json:"yearsInArrears"`
+	AmountInArrears   int64  `json:"amountInArrears"`
+	Status            string `json:"status"`
+}
+
 // RegistrationInfo holds sub-registrar office registration details.
 type RegistrationInfo struct {
 	RegistrationNumber string `json:"registrationNumber"`
 	BookNumber         string `json:"bookNumber"`
 	SubRegistrarOffice string `json:"subRegistrarOffice"`
 	RegistrationDate   string `json:"registrationDate"`
+	// DuplicateOverrideJustification lets an admin register a genuine
+	// historical duplicate of an existing registration number.
+	DuplicateOverrideJustification string `json:"duplicateOverrideJustification,omitempty"`
 }
 
 // AlgorandInfo tracks the public verification layer references on Algorand.
@@ -134,6 +310,57 @@ type Provenance struct {
 	SplitFrom          string   `json:"splitFrom"`
 	MergedFrom         []string `json:"mergedFrom"`
 	Sequence           int      `json:"sequence"`
+	// MinPlotExemptionRef is the order that allowed this plot to be
+	// split off below the state's minimum plot size.
+	MinPlotExemptionRef string `json:"minPlotExemptionRef,omitempty"`
+}
+
+// CorrectionEntry records a clerical correction to a land record made
+// under a correction order, outside the transfer/mutation flow.
+type CorrectionEntry struct {
+	CorrectionID string        `json:"correctionId"`
+	Type         string        `json:"type"`
+	Changes      []FieldChange `json:"changes"`
+	Reason       string        `json:"reason"`
+	ApprovalRef  string        `json:"approvalRef"`
+	DocumentHash string        `json:"documentHash,omitempty"`
+	CorrectedBy  string        `json:"correctedBy"`
+	CorrectedAt  string        `json:"correctedAt"`
+	FabricTxID   string        `json:"fabricTxId"`
+}
+
+// ArchivalInfo records why and under which order a property was
+// archived after ceasing to exist as a registrable unit.
+type ArchivalInfo struct {
+	Reason     string `json:"reason"`
+	OrderRef   string `json:"orderRef"`
+	ArchivedBy string `json:"archivedBy"`
+	ArchivedAt string `json:"archivedAt"`
+}
+
+// PropertyDocument is a supporting document attached to a property
+// after registration (NA orders, building approvals, survey sketches).
+// Documents are append-only; a newer document replaces an older one by
+// setting the older one's SupersededBy.
+type PropertyDocument struct {
+	DocumentID   string `json:"documentId"`
+	DocType      string `json:"docType"`
+	Hash         string `json:"hash"`
+	IssuedBy     string `json:"issuedBy"`
+	IssuedDate   string `json:"issuedDate"`
+	Description  string `json:"description"`
+	Supersedes   string `json:"supersedes,omitempty"`
+	SupersededBy string `json:"supersededBy,omitempty"`
+	AddedBy      string `json:"addedBy"`
+	AddedAt      string `json:"addedAt"`
+	FabricTxID   string `json:"fabricTxId"`
+}
+
+// FieldChange is one field-level difference in a correction.
+type FieldChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
 }
 
 // ============================================================
@@ -162,17 +389,91 @@ type TransferRecord struct {
 	FabricTxID         string             `json:"fabricTxId"`
 	CreatedAt          string             `json:"createdAt"`
 	UpdatedAt          string             `json:"updatedAt"`
+	RegistrationInfo   RegistrationInfo   `json:"registrationInfo"`
+	// FirstApproval is set while a high-value transfer awaits a second
+	// registrar (status AWAITING_SECOND_APPROVAL).
+	FirstApproval *TransferApproval `json:"firstApproval,omitempty"`
+	// Signatures are the verified party and witness signatures recorded
+	// by SignTransfer.
+	Signatures []TransferSignature `json:"signatures,omitempty"`
+	// PreviousOwners is the owner set the transfer replaced, recorded
+	// when it is registered.
+	PreviousOwners []OwnerShare `json:"previousOwners,omitempty"`
+	// PreviousOwnerInfo and PreviousRegistrationInfo are the property's
+	// owners and deed registration as the transfer found them, kept so
+	// RollbackTransfer can restore them.
+	PreviousOwnerInfo        *OwnerInfo        `json:"previousOwnerInfo,omitempty"`
+	PreviousRegistrationInfo *RegistrationInfo `json:"previousRegistrationInfo,omitempty"`
+	// RollbackReason is why a ROLLED_BACK transfer was reversed.
+	RollbackReason string `json:"rollbackReason,omitempty"`
+	// DisclosedLeaseIDs lists the active leases on the property the
+	// buyer has been told of; every one must be listed.
+	DisclosedLeaseIDs []string `json:"disclosedLeaseIds,omitempty"`
+	// SellerPOA and BuyerPOA name the powers of attorney under which an
+	// attorney acts for the seller or buyer.
+	SellerPOA *POAReference `json:"sellerPoa,omitempty"`
+	BuyerPOA  *POAReference `json:"buyerPoa,omitempty"`
+	// CultivationDisclosed is set when the transfer is registered if
+	// the property had active cultivators, which pass to the buyer
+	// with the land.
+	CultivationDisclosed bool `json:"cultivationDisclosed,omitempty"`
+	// TaxArrearsFlagged is set when registering the transfer emitted
+	// TAX_ARREARS_FLAGGED (see flagTaxArrears).
+	TaxArrearsFlagged bool `json:"taxArrearsFlagged,omitempty"`
+	// AcknowledgedCropLoanIDs lists the active crop loans on the
+	// property the buyer has acknowledged; every one must be listed.
+	AcknowledgedCropLoanIDs []string `json:"acknowledgedCropLoanIds,omitempty"`
+	// CeilingExemptionOrderRef is the order exempting the buyer from
+	// the state's land ceiling (see checkCeiling).
+	CeilingExemptionOrderRef string `json:"ceilingExemptionOrderRef,omitempty"`
+	// ClassificationClearance is required when the property's land
+	// classification is restricted (see requireClassificationClearance).
+	ClassificationClearance *Clearance `json:"classificationClearance,omitempty"`
+	// BuyerCategoryDeclaration is the protected category (SC or ST) the
+	// buyer declares, and AlienationPermissionRef the collector's
+	// permission for a buyer outside the seller's category (see
+	// checkAlienation).
+	BuyerCategoryDeclaration string `json:"buyerCategoryDeclaration,omitempty"`
+	AlienationPermissionRef  string `json:"alienationPermissionRef,omitempty"`
+	// DisasterCertificateRef stands in for documents lost in a disaster
+	// in the checks the state relaxes for flagged parcels (see
+	// disasterRelaxed).
+	DisasterCertificateRef string `json:"disasterCertificateRef,omitempty"`
+	// ExecuteAfter is the earliest time ExecuteTransfer may run, set by
+	// ScheduleTransferExecution.
+	ExecuteAfter string `json:"executeAfter,omitempty"`
+	ScheduledBy  string `json:"scheduledBy,omitempty"`
+	ScheduledAt  string `json:"scheduledAt,omitempty"`
+	// GovernmentApprovals are the officials' approvals a transfer of
+	// government land needs, in the order given (see
+	// RecordGovernmentApproval).
+	GovernmentApprovals []GovernmentApproval `json:"governmentApprovals,omitempty"`
 }
 
 // PartyInfo identifies a buyer or seller in a transfer by their
 // Aadhaar hash (never raw Aadhaar) and name.
 type PartyInfo struct {
-	AadhaarHash string `json:"aadhaarHash"`
-	Name        string `json:"name"`
+	AadhaarHash string    `json:"aadhaarHash"`
+	Name        string    `json:"name"`
+	IsMinor     bool      `json:"isMinor,omitempty"`
+	Guardian    *Guardian `json:"guardian,omitempty"`
+	// OwnerType and Entity describe a non-individual party; see EntityDetails.
+	OwnerType string         `json:"ownerType,omitempty"`
+	Entity    *EntityDetails `json:"entity,omitempty"`
+}
+
+// Clearance is an authority's clearance for dealing with restricted
+// land: central clearance for a transfer, or diversion approval for a
+// change of land use.
+type Clearance struct {
+	Authority    string `json:"authority"`
+	ClearanceRef string `json:"clearanceRef"`
+	DocumentHash string `json:"documentHash"`
 }
 
 // Witness records a witness to a property transfer, including
-// their digital signature status.
+// their digital signature status. Signed is cleared at initiation and
+// set only by SignTransfer, on a verified signature.
 type Witness struct {
 	AadhaarHash string `json:"aadhaarHash"`
 	Name        string `json:"name"`
@@ -195,6 +496,7 @@ type Documents struct {
 	SaleDeedHash                string `json:"saleDeedHash"`
 	StampDutyReceiptHash        string `json:"stampDutyReceiptHash"`
 	EncumbranceCertificateHash  string `json:"encumbranceCertificateHash"`
+	TaxClearanceHash            string `json:"taxClearanceHash,omitempty"`
 }
 
 // StatusEntry records a status transition in the transfer lifecycle.
@@ -202,6 +504,38 @@ type StatusEntry struct {
 	Status string `json:"status"`
 	At     string `json:"at"`
 	By     string `json:"by"`
+	// CertFingerprint identifies the approving certificate on
+	// registrar approvals; see TransferApproval.
+	CertFingerprint string `json:"certFingerprint,omitempty"`
+}
+
+// TransferApproval records one registrar's approval of a transfer
+// execution. CertFingerprint is the SHA-256 of the approver's
+// certificate, so two enrollments sharing a role and state are told
+// apart.
+type TransferApproval struct {
+	ApproverID      string `json:"approverId"`
+	CertFingerprint string `json:"certFingerprint"`
+	ApprovedAt      string `json:"approvedAt"`
+}
+
+// GovernmentApproval records one official's approval of a transfer of
+// government land. OrderRef is the official's sanction order; the
+// approver is bound by CertFingerprint like a TransferApproval.
+type GovernmentApproval struct {
+	Designation     string `json:"designation"`
+	OrderRef        string `json:"orderRef"`
+	DocumentHash    string `json:"documentHash,omitempty"`
+	ApproverID      string `json:"approverId"`
+	CertFingerprint string `json:"certFingerprint"`
+	ApprovedAt      string `json:"approvedAt"`
+}
+
+// GovernmentApprover is a designation whose approval a state requires
+// to dispose of government land, and the role its holder must have.
+type GovernmentApprover struct {
+	Designation string `json:"designation"`
+	Role        string `json:"role"`
 }
 
 // ============================================================
@@ -211,26 +545,200 @@ type StatusEntry struct {
 // EncumbranceRecord represents a financial or legal claim (mortgage,
 // lien, court order) against a property.
 type EncumbranceRecord struct {
-	DocType        string             `json:"docType"`
-	EncumbranceID  string             `json:"encumbranceId"`
-	PropertyID     string             `json:"propertyId"`
-	Type           string             `json:"type"`
-	Status         string             `json:"status"`
-	Institution    Institution        `json:"institution"`
-	Details        EncumbranceDetails `json:"details"`
-	CourtOrderRef  string             `json:"courtOrderRef"`
-	CreatedAt      string             `json:"createdAt"`
-	CreatedBy      string             `json:"createdBy"`
+	DocType       string             `json:"docType"`
+	EncumbranceID string             `json:"encumbranceId"`
+	PropertyID    string             `json:"propertyId"`
+	Type          string             `json:"type"`
+	Status        string             `json:"status"`
+	Institution   Institution        `json:"institution"`
+	Details       EncumbranceDetails `json:"details"`
+	CourtOrderRef string             `json:"courtOrderRef"`
+	CreatedAt     string             `json:"createdAt"`
+	CreatedBy     string             `json:"createdBy"`
+	// CarriedFrom and CarriedFromEncumbranceID identify the charge this
+	// one was cloned from on a split or merge, with the holder's consent
+	// in ConsentRef.
+	CarriedFrom              string `json:"carriedFrom,omitempty"`
+	CarriedFromEncumbranceID string `json:"carriedFromEncumbranceId,omitempty"`
+	ConsentRef               string `json:"consentRef,omitempty"`
+	// RequestID is the caller's idempotency key, if one was given.
+	RequestID string `json:"requestId,omitempty"`
+	// CropLoan holds the season details of a CROP_LOAN; Details.EndDate
+	// is the season end.
+	CropLoan *CropLoanDetails `json:"cropLoan,omitempty"`
+	// Easement holds the dominant parcel and strip of an EASEMENT.
+	Easement *EasementDetails `json:"easement,omitempty"`
+}
+
+// EasementDetails describes an easement over the servient parcel for
+// the benefit of DominantPropertyID. Extent is the width or extent as
+// described in the deed; Strip optionally draws it. NeedsConfirmation
+// is set when a split or merge carries the easement onto a new parcel
+// and cleared by ConfirmEasement.
+type EasementDetails struct {
+	DominantPropertyID  string   `json:"dominantPropertyId"`
+	Purpose             string   `json:"purpose"`
+	Extent              string   `json:"extent"`
+	Strip               *GeoJSON `json:"strip,omitempty"`
+	ServientConsentHash string   `json:"servientConsentHash"`
+	DominantConsentHash string   `json:"dominantConsentHash"`
+	NeedsConfirmation   bool     `json:"needsConfirmation"`
+	ConfirmedBy         string   `json:"confirmedBy,omitempty"`
+	ConfirmedAt         string   `json:"confirmedAt,omitempty"`
+}
+
+// EasementRequest is the input to AddEasement. PropertyID is the
+// servient parcel the easement burdens.
+type EasementRequest struct {
+	PropertyID          string   `json:"propertyId"`
+	DominantPropertyID  string   `json:"dominantPropertyId"`
+	Purpose             string   `json:"purpose"`
+	Extent              string   `json:"extent"`
+	Strip               *GeoJSON `json:"strip,omitempty"`
+	ServientConsentHash string   `json:"servientConsentHash"`
+	DominantConsentHash string   `json:"dominantConsentHash"`
+	// RequestID is the caller's idempotency key.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// CropLoanDetails describes a Kisan Credit Card crop loan for one
+// season. LimitAmount is in paisa. History holds the seasons it was
+// renewed from, oldest first.
+type CropLoanDetails struct {
+	CultivatorHash string            `json:"cultivatorHash"`
+	Season         string            `json:"season"`
+	Year           int               `json:"year"`
+	Crop           string            `json:"crop"`
+	LimitAmount    int64             `json:"limitAmount"`
+	History        []CropLoanRenewal `json:"history,omitempty"`
+}
+
+// CropLoanRenewal is the next season submitted to RenewCropLoan and,
+// in a crop loan's history, a past season with when it was renewed.
+type CropLoanRenewal struct {
+	Season        string `json:"season"`
+	Year          int    `json:"year"`
+	Crop          string `json:"crop"`
+	LimitAmount   int64  `json:"limitAmount"`
+	SeasonEndDate string `json:"seasonEndDate"`
+	RenewedAt     string `json:"renewedAt,omitempty"`
+	RenewedBy     string `json:"renewedBy,omitempty"`
+}
+
+// LeaseRecord is a registered lease of a property. Status: ACTIVE or
+// TERMINATED; an ACTIVE lease past its EndDate has expired. AnnualRent
+// is in paisa.
+type LeaseRecord struct {
+	DocType           string    `json:"docType"`
+	LeaseID           string    `json:"leaseId"`
+	PropertyID        string    `json:"propertyId"`
+	LeaseType         string    `json:"leaseType"`
+	Lessor            PartyInfo `json:"lessor"`
+	Lessee            PartyInfo `json:"lessee"`
+	StartDate         string    `json:"startDate"`
+	EndDate           string    `json:"endDate"`
+	AnnualRent        int64     `json:"annualRent"`
+	LeaseDeedHash     string    `json:"leaseDeedHash"`
+	Status            string    `json:"status"`
+	RegisteredBy      string    `json:"registeredBy"`
+	RegisteredAt      string    `json:"registeredAt"`
+	TerminationReason string    `json:"terminationReason,omitempty"`
+	TerminatedBy      string    `json:"terminatedBy,omitempty"`
+	TerminatedAt      string    `json:"terminatedAt,omitempty"`
+	FabricTxID        string    `json:"fabricTxId"`
+}
+
+// CultivationRecord is a cultivator entry in a property's record of
+// rights. Status: ACTIVE or ENDED; an ACTIVE entry past its EndDate has
+// lapsed. DisclosedInTransfers lists the transfers the entry was carried
+// over in.
+type CultivationRecord struct {
+	DocType              string       `json:"docType"`
+	CultivationID        string       `json:"cultivationId"`
+	PropertyID           string       `json:"propertyId"`
+	CultivatorHash       string       `json:"cultivatorHash"`
+	CultivatorName       string       `json:"cultivatorName"`
+	TenureClass          string       `json:"tenureClass"`
+	Seasons              []CropSeason `json:"seasons,omitempty"`
+	StartDate            string       `json:"startDate"`
+	EndDate              string       `json:"endDate,omitempty"`
+	Status               string       `json:"status"`
+	EndReason            string       `json:"endReason,omitempty"`
+	DisclosedInTransfers []string     `json:"disclosedInTransfers,omitempty"`
+	RecordedBy           string       `json:"recordedBy"`
+	RecordedAt           string       `json:"recordedAt"`
+	EndedBy              string       `json:"endedBy,omitempty"`
+	EndedAt              string       `json:"endedAt,omitempty"`
+	FabricTxID           string       `json:"fabricTxId"`
+}
+
+// CropSeason is one season's crop on a cultivation entry.
+type CropSeason struct {
+	Season string `json:"season"`
+	Year   int    `json:"year"`
+	Crop   string `json:"crop"`
+}
+
+// RecordOfRights is the GetRecordOfRights view of a property: who owns
+// it and who cultivates it.
+type RecordOfRights struct {
+	PropertyID         string               `json:"propertyId"`
+	SurveyNumber       string               `json:"surveyNumber"`
+	SubSurveyNumber    string               `json:"subSurveyNumber"`
+	Location           Location             `json:"location"`
+	Area               Area                 `json:"area"`
+	LandUse            string               `json:"landUse"`
+	LandClassification string               `json:"landClassification"`
+	Owner              OwnerInfo            `json:"owner"`
+	Cultivators        []*CultivationRecord `json:"cultivators"`
+	AsOf               string               `json:"asOf"`
 }
 
 // Institution identifies the bank or financial institution
-// holding the encumbrance.
+// holding the encumbrance. MspID is authoritative: it is the creating
+// bank's own MSP, or a registered institution's; Name is for display.
 type Institution struct {
 	Name       string `json:"name"`
 	BranchCode string `json:"branchCode"`
 	MspID      string `json:"mspId"`
 }
 
+// RegisteredInstitution is a bank or lender that courts and admins can
+// record encumbrances for, keyed by its Fabric MSP ID.
+type RegisteredInstitution struct {
+	DocType      string   `json:"docType"`
+	MspID        string   `json:"mspId"`
+	Name         string   `json:"name"`
+	BranchCodes  []string `json:"branchCodes"`
+	RegisteredBy string   `json:"registeredBy"`
+	RegisteredAt string   `json:"registeredAt"`
+	FabricTxID   string   `json:"fabricTxId"`
+}
+
+// Delegation hands one officer's role to another identity for a fixed
+// period within a jurisdiction, e.g. while a sub-registrar is on leave.
+// The delegate is identified by MSP ID and Fabric CA enrollment ID.
+// Status: ACTIVE, REVOKED. An ACTIVE delegation lapses at ExpiresAt.
+type Delegation struct {
+	DocType              string `json:"docType"`
+	DelegationID         string `json:"delegationId"`
+	DelegatorID          string `json:"delegatorId"`
+	DelegateMspID        string `json:"delegateMspId"`
+	DelegateEnrollmentID string `json:"delegateEnrollmentId"`
+	Role                 string `json:"role"`
+	StateCode            string `json:"stateCode"`
+	DistrictCode         string `json:"districtCode,omitempty"`
+	TehsilCode           string `json:"tehsilCode,omitempty"`
+	ExpiresAt            string `json:"expiresAt"`
+	Status               string `json:"status"`
+	GrantedBy            string `json:"grantedBy"`
+	GrantedAt            string `json:"grantedAt"`
+	RevokedBy            string `json:"revokedBy,omitempty"`
+	RevokedAt            string `json:"revokedAt,omitempty"`
+	RevokeReason         string `json:"revokeReason,omitempty"`
+	FabricTxID           string `json:"fabricTxId"`
+}
+
 // EncumbranceDetails holds the financial details of a mortgage
 // or lien (all amounts in paisa).
 type EncumbranceDetails struct {
@@ -261,6 +769,10 @@ type DisputeRecord struct {
 	CreatedAt    string       `json:"createdAt"`
 	ResolvedAt   string       `json:"resolvedAt"`
 	Resolution   string       `json:"resolution"`
+	RequestID    string       `json:"requestId,omitempty"`
+	// CompensationRecordID is set on a dispute over acquisition
+	// compensation rather than the property (see FlagCompensationDispute).
+	CompensationRecordID string `json:"compensationRecordId,omitempty"`
 }
 
 // CourtDetails holds court case reference information for a dispute.
@@ -291,13 +803,403 @@ type MutationRecord struct {
 	ApprovedAt           string   `json:"approvedAt"`
 	RejectedReason       string   `json:"rejectedReason"`
 	RevenueRecordUpdated bool     `json:"revenueRecordUpdated"`
-	CreatedAt            string   `json:"createdAt"`
+	// SourcePropertyID and DeedHash record the parent parcel and the
+	// registered deed for a PARTITION mutation.
+	SourcePropertyID string `json:"sourcePropertyId,omitempty"`
+	DeedHash         string `json:"deedHash,omitempty"`
+	CreatedAt        string `json:"createdAt"`
+	// Heirs, CourtOrderRef, WillID and CertificateID record an
+	// INHERITANCE mutation: everyone the deceased's share passed to, and
+	// the court order and probated will, or the legal heir certificate.
+	// NewOwner is the first heir.
+	Heirs         []OwnerShare `json:"heirs,omitempty"`
+	CourtOrderRef string       `json:"courtOrderRef,omitempty"`
+	WillID        string       `json:"willId,omitempty"`
+	CertificateID string       `json:"certificateId,omitempty"`
+	// AwardRef is the compensation award of an ACQUISITION mutation.
+	AwardRef string `json:"awardRef,omitempty"`
+	// CeilingExemptionOrderRef is the order exempting the new owner
+	// from the state's land ceiling (see checkCeiling).
+	CeilingExemptionOrderRef string `json:"ceilingExemptionOrderRef,omitempty"`
+}
+
+// LandAcquisition is the input to AcquireLand.
+type LandAcquisition struct {
+	AwardRef            string              `json:"awardRef"`
+	NotificationRef     string              `json:"notificationRef"`
+	AwardDocumentHash   string              `json:"awardDocumentHash"`
+	AcquiringDepartment Owner               `json:"acquiringDepartment"`
+	Awards              []CompensationAward `json:"awards"`
+}
+
+// CompensationAward is one dispossessed owner's award: Entitlement in
+// paisa, InterestRate in basis points a year from InterestFrom.
+type CompensationAward struct {
+	OwnerHash    string `json:"ownerHash"`
+	Entitlement  int64  `json:"entitlement"`
+	InterestRate int64  `json:"interestRate"`
+	InterestFrom string `json:"interestFrom,omitempty"`
+}
+
+// CompensationRecord tracks one dispossessed owner's compensation for an
+// acquired property. Status: UNPAID, PARTIALLY_PAID or PAID, by
+// principal paid against Entitlement; DisputeStatus: CLEAR or DISPUTED.
+// Amounts are in paisa.
+type CompensationRecord struct {
+	DocType         string                `json:"docType"`
+	RecordID        string                `json:"recordId"`
+	PropertyID      string                `json:"propertyId"`
+	StateCode       string                `json:"stateCode"`
+	DistrictCode    string                `json:"districtCode"`
+	AwardRef        string                `json:"awardRef"`
+	NotificationRef string                `json:"notificationRef"`
+	MutationID      string                `json:"mutationId"`
+	OwnerHash       string                `json:"ownerHash"`
+	OwnerName       string                `json:"ownerName"`
+	Entitlement     int64                 `json:"entitlement"`
+	InterestRate    int64                 `json:"interestRate"`
+	InterestFrom    string                `json:"interestFrom,omitempty"`
+	PaidAmount      int64                 `json:"paidAmount"`
+	InterestPaid    int64                 `json:"interestPaid"`
+	Payments        []CompensationPayment `json:"payments,omitempty"`
+	Status          string                `json:"status"`
+	DisputeStatus   string                `json:"disputeStatus"`
+	AwardedBy       string                `json:"awardedBy"`
+	AwardedAt       string                `json:"awardedAt"`
+	FabricTxID      string                `json:"fabricTxId"`
+}
+
+// CompensationPayment is one tranche paid against a compensation
+// record. CourtOrderRef is required while the record is disputed.
+type CompensationPayment struct {
+	PaymentID      string `json:"paymentId"`
+	Amount         int64  `json:"amount"`
+	InterestAmount int64  `json:"interestAmount,omitempty"`
+	PaymentRef     string `json:"paymentRef"`
+	PaidOn         string `json:"paidOn"`
+	CourtOrderRef  string `json:"courtOrderRef,omitempty"`
+	RecordedBy     string `json:"recordedBy"`
+	RecordedAt     string `json:"recordedAt"`
+}
+
+// ValuationRecord is an assessed value of a property for a purpose,
+// such as a registered valuer's report or an auction reserve price. It
+// is never updated; see RecordValuation. Value is in paisa.
+type ValuationRecord struct {
+	DocType                  string `json:"docType"`
+	ValuationID              string `json:"valuationId"`
+	PropertyID               string `json:"propertyId"`
+	ValuerRegistrationNumber string `json:"valuerRegistrationNumber"`
+	Purpose                  string `json:"purpose"`
+	Value                    int64  `json:"value"`
+	Method                   string `json:"method"`
+	ReportHash               string `json:"reportHash"`
+	ValuationDate            string `json:"valuationDate"`
+	RecordedBy               string `json:"recordedBy"`
+	RecordedByMspID          string `json:"recordedByMspId"`
+	RecordedAt               string `json:"recordedAt"`
+	FabricTxID               string `json:"fabricTxId"`
+}
+
+// InsurancePolicy is a property insurance policy recorded for a lender.
+// SumInsured is in paisa. LenderMspID is the bank whose charge the
+// policy backs, if any. IsActive is recomputed for the transaction date
+// on every read; see RecordInsurancePolicy.
+type InsurancePolicy struct {
+	DocType            string `json:"docType"`
+	PolicyID           string `json:"policyId"`
+	PropertyID         string `json:"propertyId"`
+	Insurer            string `json:"insurer"`
+	PolicyNumber       string `json:"policyNumber"`
+	SumInsured         int64  `json:"sumInsured"`
+	StartDate          string `json:"startDate"`
+	EndDate            string `json:"endDate"`
+	PolicyDocumentHash string `json:"policyDocumentHash"`
+	EncumbranceID      string `json:"encumbranceId,omitempty"`
+	LenderMspID        string `json:"lenderMspId,omitempty"`
+	IsActive           bool   `json:"isActive"`
+	RecordedBy         string `json:"recordedBy"`
+	RecordedByMspID    string `json:"recordedByMspId"`
+	RecordedAt         string `json:"recordedAt"`
+	FabricTxID         string `json:"fabricTxId"`
+}
+
+// CertifiedCopy is a certified copy issued by a sub-registrar office:
+// a snapshot of the record with its landRecordHash at issuance, printed
+// on the copy with SerialNumber. Fee is in paisa.
+type CertifiedCopy struct {
+	DocType       string     `json:"docType"`
+	SerialNumber  string     `json:"serialNumber"`
+	PropertyID    string     `json:"propertyId"`
+	Purpose       string     `json:"purpose"`
+	RequestorHash string     `json:"requestorHash"`
+	RecordHash    string     `json:"recordHash"`
+	Snapshot      LandRecord `json:"snapshot"`
+	Office        string     `json:"office"`
+	Fee           int64      `json:"fee"`
+	IssuedBy      string     `json:"issuedBy"`
+	IssuedAt      string     `json:"issuedAt"`
+	FabricTxID    string     `json:"fabricTxId"`
+}
+
+// CertifiedCopyVerification is the VerifyCertifiedCopy result for a
+// presented copy.
+type CertifiedCopyVerification struct {
+	SerialNumber      string `json:"serialNumber"`
+	PropertyID        string `json:"propertyId"`
+	Authentic         bool   `json:"authentic"`
+	RecordChanged     bool   `json:"recordChanged"`
+	CurrentRecordHash string `json:"currentRecordHash"`
+	Office            string `json:"office"`
+	IssuedAt          string `json:"issuedAt"`
+	VerifiedAt        string `json:"verifiedAt"`
+}
+
+// CertifiedCopyCounter counts the certified copies an office issued on
+// a date (UTC) and the fees charged for them, in paisa.
+type CertifiedCopyCounter struct {
+	DocType          string `json:"docType"`
+	Office           string `json:"office"`
+	Date             string `json:"date"`
+	CopyCount        int    `json:"copyCount"`
+	TotalFees        int64  `json:"totalFees"`
+	LastSerialNumber string `json:"lastSerialNumber,omitempty"`
+	LastFabricTxID   string `json:"lastFabricTxId,omitempty"`
+}
+
+// CertificatePayload is a GenerateCertificatePayload result: the
+// canonical JSON of a CertificateContent for the QR code, its SHA-256
+// and its size in bytes.
+type CertificatePayload struct {
+	Payload     string `json:"payload"`
+	PayloadHash string `json:"payloadHash"`
+	Size        int    `json:"size"`
+}
+
+// CertificateContent is what an ownership certificate's QR code
+// carries. RecordHash is the record's landRecordHash and Anchor the
+// latest anchor of its state when the payload was generated.
+// OwnersTruncated is set when Owners lists fewer than OwnerCount owners
+// to fit the QR code.
+type CertificateContent struct {
+	PropertyID          string             `json:"propertyId"`
+	SurveyNumber        string             `json:"surveyNumber"`
+	SubSurveyNumber     string             `json:"subSurveyNumber,omitempty"`
+	Owners              []CertificateOwner `json:"owners"`
+	OwnerCount          int                `json:"ownerCount"`
+	OwnersTruncated     bool               `json:"ownersTruncated,omitempty"`
+	Status              string             `json:"status"`
+	DisputeStatus       string             `json:"disputeStatus"`
+	EncumbranceStatus   string             `json:"encumbranceStatus"`
+	CoolingPeriodActive bool               `json:"coolingPeriodActive"`
+	Area                CertificateArea    `json:"area"`
+	LastMutationID      string             `json:"lastMutationId,omitempty"`
+	RecordHash          string             `json:"recordHash"`
+	Anchor              *CertificateAnchor `json:"anchor,omitempty"`
+}
+
+// CertificateOwner is an owner on a certificate, with the name masked.
+type CertificateOwner struct {
+	Name            string `json:"name"`
+	AadhaarHash     string `json:"aadhaarHash"`
+	SharePercentage int    `json:"sharePercentage"`
+}
+
+// CertificateArea is a property's area on a certificate.
+type CertificateArea struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// CertificateAnchor references the Algorand anchor on a certificate.
+type CertificateAnchor struct {
+	AnchorID     string `json:"anchorId"`
+	AlgorandTxID string `json:"algorandTxId"`
+	AnchoredAt   string `json:"anchoredAt"`
+}
+
+// CertificateVerification is the VerifyCertificatePayload result for a
+// scanned payload. Divergence names the payload fields the live record
+// no longer matches.
+type CertificateVerification struct {
+	PropertyID        string   `json:"propertyId"`
+	HashValid         bool     `json:"hashValid"`
+	Current           bool     `json:"current"`
+	Divergence        []string `json:"divergence"`
+	CurrentRecordHash string   `json:"currentRecordHash"`
+	VerifiedAt        string   `json:"verifiedAt"`
+}
+
+// OwnershipCredential is a W3C Verifiable Credential attesting one
+// owner's share in a property; see ExportOwnershipCredential.
+type OwnershipCredential struct {
+	Context           []string                   `json:"@context"`
+	ID                string                     `json:"id"`
+	Type              []string                   `json:"type"`
+	Issuer            string                     `json:"issuer"`
+	IssuanceDate      string                     `json:"issuanceDate"`
+	CredentialSubject OwnershipCredentialSubject `json:"credentialSubject"`
+	Proof             OwnershipCredentialProof   `json:"proof"`
+}
+
+// OwnershipCredentialSubject is the redacted view of a property and the
+// holder's share in it: the holder's name is masked and no other owner
+// is named.
+type OwnershipCredentialSubject struct {
+	PropertyID          string          `json:"propertyId"`
+	SurveyNumber        string          `json:"surveyNumber"`
+	SubSurveyNumber     string          `json:"subSurveyNumber"`
+	StateCode           string          `json:"stateCode"`
+	DistrictCode        string          `json:"districtCode"`
+	TehsilCode          string          `json:"tehsilCode"`
+	VillageCode         string          `json:"villageCode"`
+	Area                CertificateArea `json:"area"`
+	LandUse             string          `json:"landUse"`
+	OwnerType           string          `json:"ownerType"`
+	HolderName          string          `json:"holderName"`
+	HolderAadhaarHash   string          `json:"holderAadhaarHash"`
+	SharePercentage     int             `json:"sharePercentage"`
+	OwnerCount          int             `json:"ownerCount"`
+	Status              string          `json:"status"`
+	DisputeStatus       string          `json:"disputeStatus"`
+	EncumbranceStatus   string          `json:"encumbranceStatus"`
+	CoolingPeriodActive bool            `json:"coolingPeriodActive"`
+}
+
+// OwnershipCredentialProof stands in for a signature: SubjectHash is
+// the SHA-256 of the canonical credentialSubject, RecordHash the land
+// record's hash as anchored, and Anchor the state's latest anchor when
+// the credential was issued.
+type OwnershipCredentialProof struct {
+	Type               string             `json:"type"`
+	Created            string             `json:"created"`
+	ProofPurpose       string             `json:"proofPurpose"`
+	VerificationMethod string             `json:"verificationMethod"`
+	SubjectHash        string             `json:"subjectHash"`
+	RecordHash         string             `json:"recordHash"`
+	Anchor             *CertificateAnchor `json:"anchor,omitempty"`
+	ChannelID          string             `json:"channelId"`
+}
+
+// CredentialVerification is the VerifyOwnershipCredential result.
+// Divergence names the credentialSubject fields the live record no
+// longer matches.
+type CredentialVerification struct {
+	PropertyID        string   `json:"propertyId"`
+	HolderAadhaarHash string   `json:"holderAadhaarHash"`
+	Valid             bool     `json:"valid"`
+	SubjectHashValid  bool     `json:"subjectHashValid"`
+	IssuerValid       bool     `json:"issuerValid"`
+	AnchorValid       bool     `json:"anchorValid"`
+	Current           bool     `json:"current"`
+	Divergence        []string `json:"divergence"`
+	CurrentRecordHash string   `json:"currentRecordHash"`
+	VerifiedAt        string   `json:"verifiedAt"`
+}
+
+// VillageExtract is a page of a village's khatauni; see
+// GenerateVillageExtract. Areas are in square meters. ExtractHash is
+// the SHA-256 of the page's canonical JSON without ExtractHash and AsOf.
+type VillageExtract struct {
+	StateCode    string            `json:"stateCode"`
+	DistrictCode string            `json:"districtCode"`
+	TehsilCode   string            `json:"tehsilCode"`
+	VillageCode  string            `json:"villageCode"`
+	VillageName  string            `json:"villageName"`
+	Holdings     []*VillageHolding `json:"holdings"`
+	ParcelCount  int               `json:"parcelCount"`
+	TotalAreaSqM float64           `json:"totalAreaSqM"`
+	Bookmark     string            `json:"bookmark"`
+	ExtractHash  string            `json:"extractHash,omitempty"`
+	AsOf         string            `json:"asOf,omitempty"`
+}
+
+// VillageHolding is one primary owner's parcels in a village extract.
+type VillageHolding struct {
+	OwnerHash    string          `json:"ownerHash"`
+	OwnerName    string          `json:"ownerName"`
+	Parcels      []ExtractParcel `json:"parcels"`
+	TotalAreaSqM float64         `json:"totalAreaSqM"`
+}
+
+// ExtractParcel is a parcel in a village extract. OwnerCount includes
+// the primary owner.
+type ExtractParcel struct {
+	PropertyID         string  `json:"propertyId"`
+	SurveyNumber       string  `json:"surveyNumber"`
+	SubSurveyNumber    string  `json:"subSurveyNumber,omitempty"`
+	AreaSqM            float64 `json:"areaSqM"`
+	LandUse            string  `json:"landUse"`
+	LandClassification string  `json:"landClassification"`
+	OwnerCount         int     `json:"ownerCount"`
+	Disputed           bool    `json:"disputed"`
+	Encumbered         bool    `json:"encumbered"`
+}
+
+// HeirCertificate is a recorded legal heir certificate. Status: ACTIVE,
+// EXPIRED or INVALIDATED (see SetHeirCertificateStatus); an ACTIVE
+// certificate past ValidUntil cannot be used either. IssuerMspID is the
+// org of the tehsildar who recorded it.
+type HeirCertificate struct {
+	DocType           string      `json:"docType"`
+	CertificateID     string      `json:"certificateId"`
+	DeceasedHash      string      `json:"deceasedHash"`
+	IssuingAuthority  string      `json:"issuingAuthority"`
+	CertificateNumber string      `json:"certificateNumber"`
+	IssueDate         string      `json:"issueDate"`
+	ValidUntil        string      `json:"validUntil,omitempty"`
+	Heirs             []HeirEntry `json:"heirs"`
+	DocumentHash      string      `json:"documentHash"`
+	Status            string      `json:"status"`
+	StatusReason      string      `json:"statusReason,omitempty"`
+	IssuerMspID       string      `json:"issuerMspId"`
+	RecordedBy        string      `json:"recordedBy"`
+	RecordedAt        string      `json:"recordedAt"`
+	FabricTxID        string      `json:"fabricTxId"`
+}
+
+// HeirEntry is one heir on a legal heir certificate, with their share
+// of the deceased's interest.
+type HeirEntry struct {
+	AadhaarHash     string    `json:"aadhaarHash"`
+	Name            string    `json:"name"`
+	Relationship    string    `json:"relationship"`
+	SharePercentage int       `json:"sharePercentage"`
+	IsMinor         bool      `json:"isMinor,omitempty"`
+	Guardian        *Guardian `json:"guardian,omitempty"`
+}
+
+// WillRecord is a registered will. Only its document's hash is kept on
+// chain. Status: ACTIVE, REVOKED, SUPERSEDED (by SupersededBy) or
+// PROBATED.
+type WillRecord struct {
+	DocType          string           `json:"docType"`
+	WillID           string           `json:"willId"`
+	TestatorHash     string           `json:"testatorHash"`
+	PropertyIDs      []string         `json:"propertyIds"`
+	WillDocumentHash string           `json:"willDocumentHash"`
+	RegistrationInfo RegistrationInfo `json:"registrationInfo"`
+	SupersedesWillID string           `json:"supersedesWillId,omitempty"`
+	Status           string           `json:"status"`
+	SupersededBy     string           `json:"supersededBy,omitempty"`
+	RevokeReason     string           `json:"revokeReason,omitempty"`
+	ProbateOrderRef  string           `json:"probateOrderRef,omitempty"`
+	ProbatedBy       string           `json:"probatedBy,omitempty"`
+	ProbatedAt       string           `json:"probatedAt,omitempty"`
+	RegisteredBy     string           `json:"registeredBy"`
+	RegisteredAt     string           `json:"registeredAt"`
+	FabricTxID       string           `json:"fabricTxId"`
 }
 
 // OwnerRef is a lightweight reference to a property owner.
 type OwnerRef struct {
-	AadhaarHash string `json:"aadhaarHash"`
-	Name        string `json:"name"`
+	AadhaarHash string    `json:"aadhaarHash"`
+	Name        string    `json:"name"`
+	IsMinor     bool      `json:"isMinor,omitempty"`
+	Guardian    *Guardian `json:"guardian,omitempty"`
+	// OwnerType and Entity describe a non-individual party; see EntityDetails.
+	OwnerType string         `json:"ownerType,omitempty"`
+	Entity    *EntityDetails `json:"entity,omitempty"`
 }
 
 // ============================================================
@@ -318,6 +1220,29 @@ type AnchorRecord struct {
 	AlgorandRound    int64      `json:"algorandRound"`
 	AnchoredAt       string     `json:"anchoredAt"`
 	Verified         bool       `json:"verified"`
+	RequestID        string     `json:"requestId,omitempty"`
+	// AttemptID names the BeginAnchorAttempt this anchor completes.
+	AttemptID string `json:"attemptId"`
+}
+
+// AnchorAttempt is an anchoring in progress, opened before the state
+// root is submitted to Algorand. Status: PENDING, COMPLETED (AnchorID
+// set by RecordAnchor) or FAILED (FailureReason set by
+// AbortAnchorAttempt).
+type AnchorAttempt struct {
+	DocType          string     `json:"docType"`
+	AttemptID        string     `json:"attemptId"`
+	StateCode        string     `json:"stateCode"`
+	FabricBlockRange BlockRange `json:"fabricBlockRange"`
+	StateRoot        string     `json:"stateRoot"`
+	Status           string     `json:"status"`
+	AnchorID         string     `json:"anchorId,omitempty"`
+	FailureReason    string     `json:"failureReason,omitempty"`
+	StartedBy        string     `json:"startedBy"`
+	StartedAt        string     `json:"startedAt"`
+	FinishedBy       string     `json:"finishedBy,omitempty"`
+	FinishedAt       string     `json:"finishedAt,omitempty"`
+	FabricTxID       string     `json:"fabricTxId"`
 }
 
 // BlockRange specifies a contiguous range of Fabric blocks.
@@ -338,6 +1263,26 @@ type SplitRequest struct {
 	Area            Area       `json:"area"`
 	Boundaries      Boundaries `json:"boundaries"`
 	OwnerInfo       OwnerInfo  `json:"ownerInfo"`
+	// ExemptionOrderRef permits a plot below the state's minimum size.
+	ExemptionOrderRef string `json:"exemptionOrderRef,omitempty"`
+	// AnnotationIDs lists the parent's annotations copied to this plot.
+	AnnotationIDs []string `json:"annotationIds,omitempty"`
+}
+
+// PartitionChild is one sub-plot of a partition deed. AllottedTo lists
+// the aadhaarHash of each parent owner who receives it; they hold it in
+// proportion to their shares in the parent.
+type PartitionChild struct {
+	NewPropertyID   string     `json:"newPropertyId"`
+	SurveyNumber    string     `json:"surveyNumber"`
+	SubSurveyNumber string     `json:"subSurveyNumber"`
+	Area            Area       `json:"area"`
+	Boundaries      Boundaries `json:"boundaries"`
+	AllottedTo      []string   `json:"allottedTo"`
+	// ExemptionOrderRef permits a plot below the state's minimum size.
+	ExemptionOrderRef string `json:"exemptionOrderRef,omitempty"`
+	// AnnotationIDs lists the parent's annotations copied to this plot.
+	AnnotationIDs []string `json:"annotationIds,omitempty"`
 }
 
 // ============================================================
@@ -352,3 +1297,728 @@ type HistoryEntry struct {
 	IsDelete  bool        `json:"isDelete"`
 	Record    *LandRecord `json:"record"`
 }
+
+// ============================================================
+// RegistrySettings — Per-state registry configuration
+// ============================================================
+
+// RegistrySettings holds per-state configuration read by the registry
+// through getSettings. A state without a settings document uses
+// defaultRegistrySettings.
+type RegistrySettings struct {
+	DocType   string `json:"docType"`
+	StateCode string `json:"stateCode"`
+	// BighaSqMeters is the state's bigha in square meters. Zero means
+	// bigha areas cannot be converted in this state.
+	BighaSqMeters float64 `json:"bighaSqMeters"`
+	// LenientBulkMigration makes bulk registration flag, rather than
+	// reject, legacy records with mismatched area units, unknown land
+	// classifications or bad PIN codes.
+	LenientBulkMigration bool `json:"lenientBulkMigration"`
+	// ExtraLandClassifications extends the built-in land classification
+	// vocabulary for this state.
+	ExtraLandClassifications []string `json:"extraLandClassifications,omitempty"`
+	// RequireTaxClearanceForTransfer makes ExecuteTransfer demand land
+	// revenue paid up to the previous revenue year, or a dues-clearance
+	// document hash on the transfer.
+	RequireTaxClearanceForTransfer bool `json:"requireTaxClearanceForTransfer"`
+	// TaxArrearsAlertYears is the number of revenue years in arrears at
+	// which a registered transfer emits TAX_ARREARS_FLAGGED. Zero
+	// disables the alert.
+	TaxArrearsAlertYears int `json:"taxArrearsAlertYears"`
+	// BoundaryOverlapThresholdSqM is the polygon overlap, in square
+	// meters, above which two parcels are recorded as a boundary
+	// conflict. Zero means the default of 1 square meter.
+	BoundaryOverlapThresholdSqM float64 `json:"boundaryOverlapThresholdSqM"`
+	// MergeLocationLevel is the location level merged parcels must
+	// share: STATE, DISTRICT, TEHSIL or VILLAGE. Empty means VILLAGE.
+	MergeLocationLevel string `json:"mergeLocationLevel,omitempty"`
+	// RequireSubdivisionSanction makes splits and merges two-phase: a
+	// registrar proposes and a tehsildar sanctions.
+	RequireSubdivisionSanction bool `json:"requireSubdivisionSanction"`
+	// MinPlotSizeSqM is the smallest plot, in square meters, a split may
+	// create for each land use (e.g. AGRICULTURAL, RESIDENTIAL). Land
+	// uses without an entry have no minimum.
+	MinPlotSizeSqM map[string]float64 `json:"minPlotSizeSqM,omitempty"`
+	// MaxSplitChildren caps the sub-plots one split may create. Zero
+	// means no limit.
+	MaxSplitChildren int `json:"maxSplitChildren"`
+	// HighValueTransferThreshold is the applicable value, in paisa,
+	// above which a transfer needs a second registrar's confirmation
+	// (see ConfirmHighValueTransfer). Zero disables dual approval.
+	HighValueTransferThreshold int64 `json:"highValueTransferThreshold"`
+	// EndorsingMspID is the org whose peers must endorse writes to the
+	// state's land records (see applyLandEndorsement). Changed only by
+	// ReassignStateOrg, which re-keys existing records. Empty leaves the
+	// channel policy alone.
+	EndorsingMspID string `json:"endorsingMspId,omitempty"`
+	// DistrictMspIDs overrides EndorsingMspID for districts
+	// administered by another org. Changed only by ReassignDistrictOrg.
+	DistrictMspIDs map[string]string `json:"districtMspIds,omitempty"`
+	// RoleHierarchy maps a role to the roles it includes, e.g.
+	// {"admin": ["registrar"], "sdm": ["tehsildar"]}: role checks for
+	// an included role also accept the including one. Inclusion is
+	// transitive. Empty means no inheritance.
+	RoleHierarchy map[string][]string `json:"roleHierarchy,omitempty"`
+	// NationalWriteRoles lists the roles whose national-scope identities
+	// (stateCode "IN") may modify the state's records. Unset (null) means
+	// defaultNationalWriteRoles; an empty list admits none, so the field
+	// is stored even when empty.
+	NationalWriteRoles []string `json:"nationalWriteRoles"`
+	// LandCeilingSqM caps, in square meters, the agricultural land of
+	// each land classification one person may hold in the state; the
+	// DEFAULT entry covers classifications without one. See
+	// CheckCeilingCompliance.
+	LandCeilingSqM map[string]float64 `json:"landCeilingSqM,omitempty"`
+	// EnforceLandCeiling makes ExecuteTransfer and ApproveMutation
+	// refuse to take the acquiring party over a land ceiling.
+	EnforceLandCeiling bool `json:"enforceLandCeiling"`
+	// CertifiedCopyFee is the fee, in paisa, charged for a certified
+	// copy; see IssueCertifiedCopy.
+	CertifiedCopyFee int64 `json:"certifiedCopyFee"`
+	// RequireConversionCharge makes ChangeLandUse require a stamp-duty
+	// challan paying the conversion charge for the change.
+	RequireConversionCharge bool `json:"requireConversionCharge"`
+	// RestrictedClassifications maps each restricted land
+	// classification or land use (e.g. FOREST) to the authority whose
+	// clearance a transfer of such land, or a change of land use away
+	// from it, requires.
+	RestrictedClassifications map[string]string `json:"restrictedClassifications,omitempty"`
+	// EnforceAlienationProtection makes ExecuteTransfer require the
+	// collector's permission for a transfer from an SC/ST owner to a
+	// buyer outside the category.
+	EnforceAlienationProtection bool `json:"enforceAlienationProtection"`
+	// DisasterRelaxations lists the checks that accept a disaster
+	// certificate for parcels flagged as disaster-affected (see
+	// disasterRelaxableChecks).
+	DisasterRelaxations []string `json:"disasterRelaxations,omitempty"`
+	// GovernmentDisposalApprovers lists the officials who must each
+	// approve a transfer of government land; see
+	// RecordGovernmentApproval. Without any, such transfers cannot be
+	// executed.
+	GovernmentDisposalApprovers []GovernmentApprover `json:"governmentDisposalApprovers,omitempty"`
+	// RegistryDID is the state registry's DID, the issuer of its
+	// ownership credentials; see ExportOwnershipCredential.
+	RegistryDID string `json:"registryDid,omitempty"`
+	// CoolingPeriodHours is the objection window, in hours, between a
+	// transfer's execution and its finalization. Zero means the default
+	// of 72 hours; see coolingPeriod.
+	CoolingPeriodHours int `json:"coolingPeriodHours"`
+	// RequireSettingsApproval makes SetRegistrySettings only propose a
+	// change, which a second admin applies with ApproveRegistrySettings.
+	RequireSettingsApproval bool `json:"requireSettingsApproval"`
+	// Extras holds state-specific settings the chaincode does not model,
+	// for off-chain services that configure themselves from the ledger.
+	Extras map[string]string `json:"extras,omitempty"`
+	UpdatedBy     string              `json:"updatedBy"`
+	UpdatedAt     string              `json:"updatedAt"`
+	FabricTxID    string              `json:"fabricTxId"`
+}
+
+// SettingChange is one setting changed by a settings update. Values are
+// JSON text, empty for a setting one side leaves out; extras are keyed
+// "extras.{key}".
+type SettingChange struct {
+	Key      string `json:"key"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+// SettingsProposal is a settings change awaiting a second admin's
+// approval. A state has at most one; BaseFabricTxID is the settings
+// version it was proposed against.
+type SettingsProposal struct {
+	DocType         string           `json:"docType"`
+	ProposalID      string           `json:"proposalId"`
+	StateCode       string           `json:"stateCode"`
+	Settings        RegistrySettings `json:"settings"`
+	Changes         []SettingChange  `json:"changes"`
+	BaseFabricTxID  string           `json:"baseFabricTxId"`
+	ProposedBy      string           `json:"proposedBy"`
+	CertFingerprint string           `json:"certFingerprint"`
+	ProposedAt      string           `json:"proposedAt"`
+	FabricTxID      string           `json:"fabricTxId"`
+}
+
+// SettingsHistoryEntry records one applied change to a state's settings.
+// ApprovedBy and ProposalID are set when the change went through
+// ApproveRegistrySettings.
+type SettingsHistoryEntry struct {
+	DocType    string          `json:"docType"`
+	StateCode  string          `json:"stateCode"`
+	Changes    []SettingChange `json:"changes"`
+	ChangedBy  string          `json:"changedBy"`
+	ApprovedBy string          `json:"approvedBy,omitempty"`
+	ProposalID string          `json:"proposalId,omitempty"`
+	ChangedAt  string          `json:"changedAt"`
+	FabricTxID string          `json:"fabricTxId"`
+}
+
+// CeilingReport is the CheckCeilingCompliance view of an owner's
+// agricultural holdings in a state.
+type CeilingReport struct {
+	AadhaarHash string                `json:"aadhaarHash"`
+	StateCode   string                `json:"stateCode"`
+	Compliant   bool                  `json:"compliant"`
+	Classes     []CeilingClassHolding `json:"classes"`
+	AsOf        string                `json:"asOf"`
+}
+
+// CeilingClassHolding is an owner's holding of one land class in
+// square meters. CeilingSqM and HeadroomSqM are zero, and Exceeded
+// false, for a class with no ceiling.
+type CeilingClassHolding struct {
+	Classification string  `json:"classification"`
+	HeldSqM        float64 `json:"heldSqM"`
+	CeilingSqM     float64 `json:"ceilingSqM"`
+	HeadroomSqM    float64 `json:"headroomSqM"`
+	Exceeded       bool    `json:"exceeded"`
+}
+
+// ============================================================
+// BoundaryConflictRecord — Overlapping parcel polygons
+// ============================================================
+
+// BoundaryConflictRecord names a pair of parcels in the same village
+// whose GeoJSON polygons overlap by more than the state's threshold.
+type BoundaryConflictRecord struct {
+	DocType         string   `json:"docType"`
+	ConflictID      string   `json:"conflictId"`
+	StateCode       string   `json:"stateCode"`
+	DistrictCode    string   `json:"districtCode"`
+	TehsilCode      string   `json:"tehsilCode"`
+	VillageCode     string   `json:"villageCode"`
+	PropertyIDs     []string `json:"propertyIds"`
+	OverlapSqMeters float64  `json:"overlapSqMeters"`
+	Status          string   `json:"status"`
+	DetectedBy      string   `json:"detectedBy"`
+	DetectedAt      string   `json:"detectedAt"`
+	CreatedAt       string   `json:"createdAt"`
+	FabricTxID      string   `json:"fabricTxId"`
+}
+
+// ============================================================
+// SubdivisionProposal — Split or merge awaiting tehsildar sanction
+// ============================================================
+
+// SubdivisionProposal is a split or merge stored by a registrar for a
+// tehsildar to sanction. RequestJSON is the splits JSON (SPLIT) or the
+// merged property JSON (MERGE), executed as stored on sanction.
+type SubdivisionProposal struct {
+	DocType             string   `json:"docType"`
+	ProposalID          string   `json:"proposalId"`
+	Type                string   `json:"type"`
+	PropertyIDs         []string `json:"propertyIds"`
+	Location            Location `json:"location"`
+	RequestJSON         string   `json:"requestJson"`
+	EncumbranceHandling string   `json:"encumbranceHandling"`
+	ConsentRefsJSON     string   `json:"consentRefsJson"`
+	Status              string   `json:"status"`
+	ProposedBy          string   `json:"proposedBy"`
+	ProposedAt          string   `json:"proposedAt"`
+	DecidedBy           string   `json:"decidedBy,omitempty"`
+	DecidedAt           string   `json:"decidedAt,omitempty"`
+	RejectedReason      string   `json:"rejectedReason,omitempty"`
+	FabricTxID          string   `json:"fabricTxId"`
+}
+
+// ============================================================
+// SplitTreeNode — Descendants of a split or merged property
+// ============================================================
+
+// SplitTreeNode is one property in a GetSplitTree result. Relation is
+// how it was created from its parent (SPLIT or MERGE; empty for the
+// root if it was registered directly). Truncated marks a node whose
+// children were not expanded because a depth or size limit was hit;
+// Cycle marks a child that is already an ancestor on this path.
+type SplitTreeNode struct {
+	PropertyID string           `json:"propertyId"`
+	Relation   string           `json:"relation,omitempty"`
+	Status     string           `json:"status,omitempty"`
+	Area       Area             `json:"area"`
+	OwnerCount int              `json:"ownerCount"`
+	Children   []*SplitTreeNode `json:"children"`
+	Truncated  bool             `json:"truncated,omitempty"`
+	Cycle      bool             `json:"cycle,omitempty"`
+}
+
+// ============================================================
+// MigrationResult — Progress of a schema migration batch
+// ============================================================
+
+// MigrationResult reports one MigrateRecords batch. Bookmark is the
+// last land key scanned; Done is set once the scan reached the end.
+type MigrationResult struct {
+	StateCode     string `json:"stateCode"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Scanned       int    `json:"scanned"`
+	Migrated      int    `json:"migrated"`
+	Bookmark      string `json:"bookmark"`
+	Done          bool   `json:"done"`
+}
+
+// ============================================================
+// EndorsementUpdateResult — Progress of a district reassignment
+// ============================================================
+
+// EndorsementUpdateResult reports one ReassignDistrictOrg or
+// ReassignStateOrg batch. Bookmark is the last LOCATION index key
+// scanned; Done is set once the scan reached the end. DistrictCode is
+// empty for ReassignStateOrg.
+type EndorsementUpdateResult struct {
+	StateCode    string `json:"stateCode"`
+	DistrictCode string `json:"districtCode"`
+	MspID        string `json:"mspId"`
+	Scanned      int    `json:"scanned"`
+	Updated      int    `json:"updated"`
+	Bookmark     string `json:"bookmark"`
+	Done         bool   `json:"done"`
+}
+
+// ============================================================
+// BulkResult — Per-record report of a bulk registration
+// ============================================================
+
+// BulkResult reports the outcome of RegisterBulkWithReport for every
+// input record. MaxBatchSize is the per-transaction record limit.
+type BulkResult struct {
+	Total           int                `json:"total"`
+	Registered      int                `json:"registered"`
+	Duplicates      int                `json:"duplicates"`
+	Rejected        int                `json:"rejected"`
+	MaxBatchSize    int                `json:"maxBatchSize"`
+	ContinueOnError bool               `json:"continueOnError"`
+	Records         []BulkRecordResult `json:"records"`
+}
+
+// BulkRecordResult is the outcome for one input record, by index.
+// Status: REGISTERED, DUPLICATE, REJECTED.
+type BulkRecordResult struct {
+	Index      int      `json:"index"`
+	PropertyID string   `json:"propertyId"`
+	Status     string   `json:"status"`
+	Error      string   `json:"error,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// ============================================================
+// RegistrationNumberEntry — Deed registration number uniqueness
+// ============================================================
+
+// RegistrationNumberEntry is the REGNO index value for one
+// SRO + book + registration number combination. It names the property
+// (and transfer, for deeds registered by ExecuteTransfer) that first
+// used the number, plus any admin-approved historical duplicates.
+type RegistrationNumberEntry struct {
+	DocType            string                 `json:"docType"`
+	SubRegistrarOffice string                 `json:"subRegistrarOffice"`
+	BookNumber         string                 `json:"bookNumber"`
+	RegistrationNumber string                 `json:"registrationNumber"`
+	PropertyID         string                 `json:"propertyId"`
+	TransferID         string                 `json:"transferId,omitempty"`
+	RecordedAt         string                 `json:"recordedAt"`
+	FabricTxID         string                 `json:"fabricTxId"`
+	Overrides          []RegistrationOverride `json:"overrides,omitempty"`
+}
+
+// RegistrationOverride records an admin-approved reuse of a
+// registration number.
+type RegistrationOverride struct {
+	PropertyID    string `json:"propertyId"`
+	TransferID    string `json:"transferId,omitempty"`
+	Justification string `json:"justification"`
+	OverriddenBy  string `json:"overriddenBy"`
+	OverriddenAt  string `json:"overriddenAt"`
+	FabricTxID    string `json:"fabricTxId"`
+}
+
+// ============================================================
+// AuditEntry — Access audit log
+// ============================================================
+
+// AuditEntry records who did, or tried to do, what. Sensitive operations
+// write a SUCCESS entry as part of their own transaction; denied attempts
+// can't commit, so the API middleware reports them through
+// RecordAccessAttempt as DENIED entries. Keyed by date and transaction.
+type AuditEntry struct {
+	DocType    string `json:"docType"`
+	Date       string `json:"date"`
+	FabricTxID string `json:"fabricTxId"`
+	CallerID   string `json:"callerId"`
+	Function   string `json:"function"`
+	Target     string `json:"target"`
+	Outcome    string `json:"outcome"`
+	Reason     string `json:"reason,omitempty"`
+	RecordedBy string `json:"recordedBy"`
+	Timestamp  string `json:"timestamp"`
+}
+
+// AccessAttempt is the input to RecordAccessAttempt, as observed by the
+// API middleware when a call was refused.
+type AccessAttempt struct {
+	CallerID string `json:"callerId"`
+	Function string `json:"function"`
+	Target   string `json:"target"`
+	Reason   string `json:"reason"`
+}
+
+// AuditLogPage is one page of QueryAuditLog results. Pass Bookmark back
+// to fetch the next page; it is empty after the last one.
+type AuditLogPage struct {
+	Entries  []*AuditEntry `json:"entries"`
+	Count    int32         `json:"count"`
+	Bookmark string        `json:"bookmark"`
+}
+
+// DisasterAffectedPage is one page of QueryDisasterAffected results.
+// Pass Bookmark back to fetch the next page; it is empty after the last
+// one.
+type DisasterAffectedPage struct {
+	Properties []*LandRecord `json:"properties"`
+	Count      int32         `json:"count"`
+	Bookmark   string        `json:"bookmark"`
+}
+
+// ============================================================
+// RequestIndexEntry — Idempotent request index
+// ============================================================
+
+// RequestIndexEntry maps a caller-supplied requestId to the record the
+// first call with it created. Keyed by the caller's MSP ID, enrollment
+// ID and requestId. PayloadHash is the hex SHA-256 of the call's JSON
+// argument.
+type RequestIndexEntry struct {
+	DocType     string `json:"docType"`
+	RequestID   string `json:"requestId"`
+	MspID       string `json:"mspId"`
+	Function    string `json:"function"`
+	PayloadHash string `json:"payloadHash"`
+	RecordKey   string `json:"recordKey"`
+	RecordID    string `json:"recordId"`
+	CreatedBy   string `json:"createdBy"`
+	CreatedAt   string `json:"createdAt"`
+	FabricTxID  string `json:"fabricTxId"`
+}
+
+// Receipt is returned by write functions and says what the transaction
+// did, so the client need not query it back: the primary record and its
+// resulting status, the other records it created or changed, and any
+// cooling period it started. For a requestId seen before, Duplicate is
+// set and RecordID, FabricTxID and Timestamp are those of the original
+// call, which wrote nothing new.
+type Receipt struct {
+	RecordID         string          `json:"recordId"`
+	Status           string          `json:"status,omitempty"`
+	Related          []ReceiptRecord `json:"related,omitempty"`
+	CoolingExpiresAt string          `json:"coolingExpiresAt,omitempty"`
+	RequestID        string          `json:"requestId,omitempty"`
+	Duplicate        bool            `json:"duplicate"`
+	FabricTxID       string          `json:"fabricTxId"`
+	Timestamp        string          `json:"timestamp"`
+}
+
+// ReceiptRecord is another record a transaction created or changed.
+// Kind: property, transfer, mutation, encumbrance, boundaryConflict.
+type ReceiptRecord struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Status string `json:"status,omitempty"`
+}
+
+// CallerIdentity is a caller ID from an audit field split into its
+// parts by parseCallerID. Fields absent from the ID are empty.
+type CallerIdentity struct {
+	MspID        string `json:"mspId"`
+	Role         string `json:"role"`
+	StateCode    string `json:"stateCode"`
+	EnrollmentID string `json:"enrollmentId"`
+	Fingerprint  string `json:"fingerprint"`
+	OnBehalfOf   string `json:"onBehalfOf,omitempty"`
+}
+
+// ============================================================
+// DenyListEntry — Blocked identities
+// ============================================================
+
+// DenyListEntry blocks an identity, named by certificate fingerprint or
+// enrollment ID, from every role-checked function. Status: BLOCKED,
+// UNBLOCKED. ConfirmedBy is the second admin whose confirmation makes the
+// entry binding on admin identities. Keyed by identifier.
+type DenyListEntry struct {
+	DocType              string `json:"docType"`
+	Identifier           string `json:"identifier"`
+	Status               string `json:"status"`
+	Reason               string `json:"reason"`
+	BlockedBy            string `json:"blockedBy"`
+	BlockedByFingerprint string `json:"blockedByFingerprint"`
+	BlockedAt            string `json:"blockedAt"`
+	ConfirmedBy          string `json:"confirmedBy,omitempty"`
+	ConfirmedAt          string `json:"confirmedAt,omitempty"`
+	ConfirmReason        string `json:"confirmReason,omitempty"`
+	UnblockedBy          string `json:"unblockedBy,omitempty"`
+	UnblockedAt          string `json:"unblockedAt,omitempty"`
+	UnblockReason        string `json:"unblockReason,omitempty"`
+	FabricTxID           string `json:"fabricTxId"`
+}
+
+// ============================================================
+// SigningKey — Registered citizen signing keys
+// ============================================================
+
+// SigningKey is a citizen's eSign or DSC public key, against which
+// SignTransfer verifies their signatures. KeyID is the hex SHA-256 of the
+// DER SubjectPublicKeyInfo; PreviousKeyIDs lists rotated-out keys.
+// KeyType: RSA, ECDSA. Status: ACTIVE, REVOKED. Keyed by aadhaarHash.
+type SigningKey struct {
+	DocType        string   `json:"docType"`
+	AadhaarHash    string   `json:"aadhaarHash"`
+	KeyID          string   `json:"keyId"`
+	KeyType        string   `json:"keyType"`
+	PublicKeyPEM   string   `json:"publicKeyPem"`
+	Status         string   `json:"status"`
+	PreviousKeyIDs []string `json:"previousKeyIds,omitempty"`
+	RegisteredBy   string   `json:"registeredBy"`
+	RegisteredAt   string   `json:"registeredAt"`
+	RevokedBy      string   `json:"revokedBy,omitempty"`
+	RevokedAt      string   `json:"revokedAt,omitempty"`
+	RevokeReason   string   `json:"revokeReason,omitempty"`
+	FabricTxID     string   `json:"fabricTxId"`
+}
+
+// TransferSignature is a verified signature over a transfer's digest.
+// Role: SELLER, BUYER, WITNESS. Signature is base64.
+type TransferSignature struct {
+	AadhaarHash string `json:"aadhaarHash"`
+	Role        string `json:"role"`
+	KeyID       string `json:"keyId"`
+	Signature   string `json:"signature"`
+	SignedAt    string `json:"signedAt"`
+	RecordedBy  string `json:"recordedBy"`
+	// AttorneyHash and POAID are set when an attorney signed for the
+	// party under a power of attorney; KeyID is then the attorney's.
+	AttorneyHash string `json:"attorneyHash,omitempty"`
+	POAID        string `json:"poaId,omitempty"`
+	// EvidenceHash is the document hash of the signing evidence given
+	// to AddWitnessSignature or RecordPartySignature.
+	EvidenceHash string `json:"evidenceHash,omitempty"`
+}
+
+// POARecord is a registered power of attorney. Scope: SPECIFIC (only
+// PropertyIDs) or GENERAL. Status: ACTIVE or REVOKED. ValidFrom and
+// ValidUntil are YYYY-MM-DD, inclusive.
+type POARecord struct {
+	DocType       string   `json:"docType"`
+	POAID         string   `json:"poaId"`
+	PrincipalHash string   `json:"principalHash"`
+	AttorneyHash  string   `json:"attorneyHash"`
+	Scope         string   `json:"scope"`
+	PropertyIDs   []string `json:"propertyIds,omitempty"`
+	POADeedHash   string   `json:"poaDeedHash"`
+	ValidFrom     string   `json:"validFrom"`
+	ValidUntil    string   `json:"validUntil"`
+	Status        string   `json:"status"`
+	RegisteredBy  string   `json:"registeredBy"`
+	RegisteredAt  string   `json:"registeredAt"`
+	RevokeReason  string   `json:"revokeReason,omitempty"`
+	RevokedBy     string   `json:"revokedBy,omitempty"`
+	RevokedAt     string   `json:"revokedAt,omitempty"`
+	FabricTxID    string   `json:"fabricTxId"`
+}
+
+// POAReference names the power of attorney a transfer party acts
+// under and the attorney acting.
+type POAReference struct {
+	POAID        string `json:"poaId"`
+	AttorneyHash string `json:"attorneyHash"`
+}
+
+// EventSequenceCounter holds a channel's last event sequence number and
+// the transaction that took it. Keyed by channel ID.
+type EventSequenceCounter struct {
+	DocType    string `json:"docType"`
+	Sequence   int64  `json:"sequence"`
+	FabricTxID string `json:"fabricTxId"`
+}
+
+// IndexAuditReport is one AuditIndexes slice of a district. Phase is
+// the phase the slice covered; pass Bookmark back until Done. Scanned
+// counts keys read, Checked those of the district that were checked.
+type IndexAuditReport struct {
+	StateCode     string             `json:"stateCode"`
+	DistrictCode  string             `json:"districtCode"`
+	Phase         string             `json:"phase"`
+	Scanned       int                `json:"scanned"`
+	Checked       int                `json:"checked"`
+	Discrepancies []IndexDiscrepancy `json:"discrepancies"`
+	Bookmark      string             `json:"bookmark"`
+	Done          bool               `json:"done"`
+}
+
+// IndexDiscrepancy is one index entry found out of step with the land
+// records. Index is OWNER, SURVEY or LOCATION and Attributes the entry's
+// composite key attributes. Kind is MISSING (PropertyID should have the
+// entry), ORPHANED (the entry names PropertyID, which is gone, retired or
+// no longer matches it) or STALE_POINTER (the survey entry names
+// PointsTo instead of PropertyID).
+type IndexDiscrepancy struct {
+	Index      string   `json:"index"`
+	Kind       string   `json:"kind"`
+	Attributes []string `json:"attributes"`
+	PropertyID string   `json:"propertyId"`
+	PointsTo   string   `json:"pointsTo,omitempty"`
+	Detail     string   `json:"detail"`
+}
+
+// IndexRepairRequest is the input to RepairIndexes: discrepancies of
+// one state as AuditIndexes reported them.
+type IndexRepairRequest struct {
+	StateCode     string             `json:"stateCode"`
+	Discrepancies []IndexDiscrepancy `json:"discrepancies"`
+}
+
+// IndexRepairLog records one RepairIndexes call and what it did with
+// each discrepancy. Keyed by state code and repair ID.
+type IndexRepairLog struct {
+	DocType         string             `json:"docType"`
+	RepairID        string             `json:"repairId"`
+	StateCode       string             `json:"stateCode"`
+	Repairs         []IndexRepairEntry `json:"repairs"`
+	Applied         int                `json:"applied"`
+	Skipped         int                `json:"skipped"`
+	RepairedBy      string             `json:"repairedBy"`
+	RepairedByMspID string             `json:"repairedByMspId"`
+	CertFingerprint string             `json:"certFingerprint"`
+	RepairedAt      string             `json:"repairedAt"`
+	FabricTxID      string             `json:"fabricTxId"`
+}
+
+// IndexRepairEntry is the outcome of one discrepancy. Action: CREATED,
+// DELETED, RETARGETED or SKIPPED (Note says why).
+type IndexRepairEntry struct {
+	Discrepancy IndexDiscrepancy `json:"discrepancy"`
+	Action      string           `json:"action"`
+	Note        string           `json:"note,omitempty"`
+}
+
+// LedgerCounters holds a state's running record counts, kept by the
+// write paths for GetSystemStats. Keyed by state code.
+type LedgerCounters struct {
+	DocType              string           `json:"docType"`
+	StateCode            string           `json:"stateCode"`
+	RecordsByDocType     map[string]int64 `json:"recordsByDocType"`
+	LandRecordsByStatus  map[string]int64 `json:"landRecordsByStatus"`
+	MutationsByStatus    map[string]int64 `json:"mutationsByStatus"`
+	ActiveCoolingPeriods int64            `json:"activeCoolingPeriods"`
+	CountingSince        string           `json:"countingSince"`
+	SeededAt             string           `json:"seededAt,omitempty"`
+	SeededBy             string           `json:"seededBy,omitempty"`
+	UpdatedAt            string           `json:"updatedAt"`
+	FabricTxID           string           `json:"fabricTxId"`
+}
+
+// SystemStats is the operational snapshot returned by GetSystemStats.
+// Chaincode cannot see the channel height, so the unanchored span is the
+// blocks after LastAnchoredBlock; SecondsSinceAnchor is measured to
+// ComputedAt, the transaction time. Counts cover records written since
+// CountingSince or, if SeededAt is set, since the counters were seeded.
+type SystemStats struct {
+	StatsVersion         int              `json:"statsVersion"`
+	StateCode            string           `json:"stateCode"`
+	ChannelID            string           `json:"channelId"`
+	RecordsByDocType     map[string]int64 `json:"recordsByDocType"`
+	LandRecordsByStatus  map[string]int64 `json:"landRecordsByStatus"`
+	MutationsByStatus    map[string]int64 `json:"mutationsByStatus"`
+	ActiveCoolingPeriods int64            `json:"activeCoolingPeriods"`
+	PendingMutations     int64            `json:"pendingMutations"`
+	LastAnchorID         string           `json:"lastAnchorId,omitempty"`
+	LastAnchoredBlock    int64            `json:"lastAnchoredBlock"`
+	LastAnchoredAt       string           `json:"lastAnchoredAt,omitempty"`
+	SecondsSinceAnchor   int64            `json:"secondsSinceAnchor,omitempty"`
+	EventSequence        int64            `json:"eventSequence"`
+	CountingSince        string           `json:"countingSince"`
+	SeededAt             string           `json:"seededAt,omitempty"`
+	ComputedAt           string           `json:"computedAt"`
+}
+
+// CoolingPeriodInfo is an active cooling period returned by
+// QueryCoolingPeriodsExpiringBefore.
+type CoolingPeriodInfo struct {
+	PropertyID string `json:"propertyId"`
+	TransferID string `json:"transferId,omitempty"`
+	ExpiresAt  string `json:"expiresAt"`
+	StateCode  string `json:"stateCode"`
+}
+
+// CoolingSweepResult is returned by FinalizeExpiredCoolingPeriods. Done
+// is false when expired cooling periods remain beyond maxCount.
+type CoolingSweepResult struct {
+	Outcomes []CoolingPeriodOutcome `json:"outcomes"`
+	Done     bool                   `json:"done"`
+}
+
+// CoolingPeriodOutcome is how one expired cooling period was settled:
+// FINALIZED, BLOCKED or EXPIRED_UNFINALIZED.
+type CoolingPeriodOutcome struct {
+	PropertyID string `json:"propertyId"`
+	TransferID string `json:"transferId,omitempty"`
+	Outcome    string `json:"outcome"`
+}
+
+// EventLogEntry is the journal entry of one event, keyed by channel and
+// envelope sequence. Keys holds the record IDs the payload named;
+// RelatedLists names the related-event lists it carried.
+type EventLogEntry struct {
+	DocType      string            `json:"docType"`
+	Sequence     int64             `json:"sequence"`
+	EventName    string            `json:"eventName"`
+	Keys         map[string]string `json:"keys"`
+	RelatedLists []string          `json:"relatedLists,omitempty"`
+	FabricTxID   string            `json:"fabricTxId"`
+	Timestamp    string            `json:"timestamp"`
+}
+
+// EventLogPage is a page of GetEventsSince. HasMore is set when further
+// entries follow LastSequence.
+type EventLogPage struct {
+	Entries      []*EventLogEntry `json:"entries"`
+	LastSequence int64            `json:"lastSequence"`
+	HasMore      bool             `json:"hasMore"`
+}
+
+// EventLogDigest stands in for a channel's pruned journal entries, 1
+// through ThroughSequence. Digest chains SHA-256 over them in order; see
+// PruneEventLog.
+type EventLogDigest struct {
+	DocType         string `json:"docType"`
+	ChannelID       string `json:"channelId"`
+	ThroughSequence int64  `json:"throughSequence"`
+	EntryCount      int64  `json:"entryCount"`
+	Digest          string `json:"digest"`
+	PrunedAt        string `json:"prunedAt"`
+	PrunedBy        string `json:"prunedBy"`
+	FabricTxID      string `json:"fabricTxId"`
+}
+
+// EventDigest accumulates the events a channel delivered on one UTC day,
+// for checking a consumer's stream without replaying blocks. Digest
+// chains SHA-256 over each delivered event's name and payload; see
+// GetEventDigest. PreviousDigest is the digest before the last
+// transaction's event, which that transaction replaces if it sets its
+// event again.
+type EventDigest struct {
+	DocType        string `json:"docType"`
+	ChannelID      string `json:"channelId"`
+	Date           string `json:"date"`
+	EventCount     int64  `json:"eventCount"`
+	Digest         string `json:"digest"`
+	PreviousDigest string `json:"previousDigest,omitempty"`
+	FirstSequence  int64  `json:"firstSequence,omitempty"`
+	LastSequence   int64  `json:"lastSequence,omitempty"`
+	LastFabricTxID string `json:"lastFabricTxId,omitempty"`
+}
+
+// ErrorCodeInfo describes an error code; see GetErrorCodes.
+type ErrorCodeInfo struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
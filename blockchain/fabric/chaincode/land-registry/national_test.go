@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// karnatakaProperty returns a valid land record in KA-BLR-ANK-HBL.
+func karnatakaProperty(surveyNo string, owner int) *LandRecord {
+	property := testProperty(surveyNo, owner)
+	property.PropertyID = "KA-BLR-ANK-HBL-" + surveyNo + "-0"
+	property.Location = Location{StateCode: "KA", StateName: "Karnataka", DistrictCode: "BLR", DistrictName: "Bengaluru Urban",
+		TehsilCode: "ANK", TehsilName: "Anekal", VillageCode: "HBL", VillageName: "Hebbagodi"}
+	return property
+}
+
+func TestNationalCourtFreezesPropertyInAnyState(t *testing.T) {
+	ledger := newTestLedger(t)
+	property := testProperty("142", 1)
+	ledger.registerTestProperty(newTestIdentity(t, "TelanganaMSP", "registrar", "TS"), property)
+	court := newTestIdentity(t, "SupremeCourtMSP", "court", NationalStateCode)
+
+	err := ledger.submit(court, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.FreezeProperty(ctx, property.PropertyID, "SC/CA/1234/2027")
+	})
+	if err != nil {
+		t.Fatalf("national court FreezeProperty: %v", err)
+	}
+	frozen := ledger.readProperty(property.PropertyID)
+	if frozen.Status != "FROZEN" {
+		t.Fatalf("status = %s, want FROZEN", frozen.Status)
+	}
+	if caller := parseCallerID(frozen.UpdatedBy); caller.MspID != "SupremeCourtMSP" || caller.StateCode != NationalStateCode {
+		t.Fatalf("updatedBy = %q, want the national court", frozen.UpdatedBy)
+	}
+}
+
+func TestNationalBankEncumbersAcrossStates(t *testing.T) {
+	ledger := newTestLedger(t)
+	telangana, karnataka := testProperty("142", 1), karnatakaProperty("12", 2)
+	ledger.registerTestProperty(newTestIdentity(t, "TelanganaMSP", "registrar", "TS"), telangana)
+	ledger.registerTestProperty(newTestIdentity(t, "KarnatakaMSP", "registrar", "KA"), karnataka)
+	headOffice := newTestIdentity(t, "SBIMSP", "bank", NationalStateCode)
+
+	for _, property := range []*LandRecord{telangana, karnataka} {
+		encJSON, _ := json.Marshal(EncumbranceRecord{PropertyID: property.PropertyID, Type: "MORTGAGE", Institution: Institution{Name: "State Bank of India"}})
+		err := ledger.submit(headOffice, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := ledger.contract.AddEncumbrance(ctx, string(encJSON))
+			return err
+		})
+		if err != nil {
+			t.Fatalf("%s: national bank AddEncumbrance: %v", property.PropertyID, err)
+		}
+		encumbrances := ledger.readEncumbrances(property.PropertyID)
+		if len(encumbrances) != 1 || encumbrances[0].Institution.MspID != "SBIMSP" {
+			t.Fatalf("%s encumbrances = %+v", property.PropertyID, encumbrances)
+		}
+		if !strings.Contains(encumbrances[0].CreatedBy, ":bank:"+NationalStateCode+":") {
+			t.Fatalf("createdBy = %q does not record national scope", encumbrances[0].CreatedBy)
+		}
+		if got := ledger.readProperty(property.PropertyID).EncumbranceStatus; got != "ENCUMBERED" {
+			t.Fatalf("%s encumbranceStatus = %s", property.PropertyID, got)
+		}
+	}
+}
+
+func TestNationalWriteRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		writeRoles []string
+		role       string
+		want       string
+	}{
+		{"default admits court", nil, "court", ""},
+		{"default admits admin", nil, "admin", ""},
+		{"default refuses bank", nil, "bank", ErrCodeStateMismatch},
+		{"default refuses registrar", nil, "registrar", ErrCodeStateMismatch},
+		{"configured bank", []string{"court", "bank"}, "bank", ""},
+		{"configured list drops admin", []string{"court", "bank"}, "admin", ErrCodeStateMismatch},
+		{"empty list refuses court", []string{}, "court", ErrCodeStateMismatch},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			if tc.writeRoles != nil {
+				ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", NationalWriteRoles: tc.writeRoles})
+			}
+			err := ledger.submit(newTestIdentity(t, "CentralMSP", tc.role, NationalStateCode), func(ctx contractapi.TransactionContextInterface) error {
+				return requireStateAccess(ctx, "TS")
+			})
+			expectCode(t, err, tc.want)
+		})
+	}
+}
+
+func TestNationalScopeIsNotAStateWildcard(t *testing.T) {
+	ledger := newTestLedger(t)
+	// A state identity does not reach other states, nor national records
+	for _, target := range []string{"KA", NationalStateCode} {
+		err := ledger.submit(newTestIdentity(t, "TelanganaMSP", "admin", "TS"), func(ctx contractapi.TransactionContextInterface) error {
+			return requireStateAccess(ctx, target)
+		})
+		expectCode(t, err, ErrCodeStateMismatch)
+	}
+	err := ledger.submit(newTestIdentity(t, "CentralMSP", "registrar", NationalStateCode), func(ctx contractapi.TransactionContextInterface) error {
+		return requireStateAccess(ctx, NationalStateCode)
+	})
+	if err != nil {
+		t.Fatalf("national identity on national records: %v", err)
+	}
+}
+
+func TestRegisterBulkChecksStateOfEveryRecord(t *testing.T) {
+	ledger := newTestLedger(t)
+	registerBulk := func(id *testIdentity, property *LandRecord) error {
+		propertiesJSON, _ := json.Marshal([]*LandRecord{property})
+		return ledger.submit(id, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.RegisterBulk(ctx, string(propertiesJSON))
+		})
+	}
+
+	// A national="true" attribute does not widen a state admin's scope
+	stateAdmin := newTestIdentity(t, "TelanganaMSP", "admin", "TS")
+	stateAdmin.attrs["national"] = "true"
+	expectCode(t, registerBulk(stateAdmin, karnatakaProperty("12", 2)), ErrCodeStateMismatch)
+
+	// National scope comes from stateCode IN and the state's nationalWriteRoles
+	centralAdmin := newTestIdentity(t, "CentralMSP", "admin", NationalStateCode)
+	if err := registerBulk(centralAdmin, karnatakaProperty("12", 2)); err != nil {
+		t.Fatalf("national admin RegisterBulk: %v", err)
+	}
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", NationalWriteRoles: []string{"court"}})
+	expectCode(t, registerBulk(centralAdmin, testProperty("142", 1)), ErrCodeStateMismatch)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// NON-INDIVIDUAL OWNERS
+// ============================================================
+// Companies, HUFs, trusts and government departments hold land through
+// an authorized signatory. For these owner types the Owner's aadhaarHash
+// is the signatory's (Rule 10 applies to the person who signs), and the
+// entity itself is identified by the fields in EntityDetails.
+
+// ownerTypes lists the values accepted for OwnerInfo.OwnerType.
+var ownerTypes = map[string]bool{
+	"INDIVIDUAL": true,
+	"HUF":        true,
+	"COMPANY":    true,
+	"TRUST":      true,
+	"GOVERNMENT": true,
+}
+
+// panPattern matches an Indian Permanent Account Number.
+var panPattern = regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+
+// cinPattern matches a Corporate Identification Number issued by the MCA.
+var cinPattern = regexp.MustCompile(`^[LU][0-9]{5}[A-Z]{2}[0-9]{4}[A-Z]{3}[0-9]{6}$`)
+
+// normalizeOwnerType defaults an empty owner type to INDIVIDUAL.
+func normalizeOwnerType(ownerType string) string {
+	if ownerType == "" {
+		return "INDIVIDUAL"
+	}
+	return ownerType
+}
+
+// validateOwnerInfo checks the owner type and validates every owner's
+// identity for it. An empty OwnerType is set to INDIVIDUAL in place.
+func validateOwnerInfo(info *OwnerInfo) error {
+	info.OwnerType = normalizeOwnerType(info.OwnerType)
+	if !ownerTypes[info.OwnerType] {
+		return newError(ErrCodeValidationError, "ownerType '%s' must be INDIVIDUAL, HUF, COMPANY, TRUST or GOVERNMENT", info.OwnerType)
+	}
+	for i, owner := range info.Owners {
+		if err := validateEntityDetails(info.OwnerType, owner.Entity, owner.IsMinor, fmt.Sprintf("owners[%d]", i)); err != nil {
+			return err
+		}
+		if err := validateProtectedCategory(owner.ProtectedCategory, fmt.Sprintf("owners[%d].protectedCategory", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateEntityDetails checks the identity fields required for an
+// owner of the given type: PAN and CIN for a company, karta for an HUF,
+// registration number for a trust and department code for government.
+// Non-individual owners need a named signatory and cannot be minors.
+func validateEntityDetails(ownerType string, entity *EntityDetails, isMinor bool, field string) error {
+	if ownerType == "INDIVIDUAL" {
+		if entity != nil {
+			return newError(ErrCodeOwnerIdentityInvalid, "%s is an individual and cannot have entity details", field)
+		}
+		return nil
+	}
+
+	if entity == nil {
+		return newError(ErrCodeOwnerIdentityInvalid, "%s.entity is required for owner type %s", field, ownerType)
+	}
+	if isMinor {
+		return newError(ErrCodeOwnerIdentityInvalid, "%s is a %s and cannot be a minor", field, ownerType)
+	}
+	if entity.PAN != "" && !panPattern.MatchString(entity.PAN) {
+		return newError(ErrCodeOwnerIdentityInvalid, "%s.entity.pan '%s' is not a valid PAN", field, entity.PAN)
+	}
+
+	switch ownerType {
+	case "COMPANY":
+		if !cinPattern.MatchString(entity.CIN) {
+			return newError(ErrCodeOwnerIdentityInvalid, "%s.entity.cin '%s' is not a valid CIN", field, entity.CIN)
+		}
+		if entity.PAN == "" {
+			return newError(ErrCodeOwnerIdentityInvalid, "%s.entity.pan is required for a company", field)
+		}
+	case "HUF":
+		// The karta manages the HUF and signs for it
+		if entity.KartaName == "" {
+			return newError(ErrCodeOwnerIdentityInvalid, "%s.entity.kartaName is required for an HUF", field)
+		}
+		if entity.SignatoryName == "" {
+			entity.SignatoryName = entity.KartaName
+		}
+	case "TRUST":
+		if entity.TrustRegistrationNumber == "" {
+			return newError(ErrCodeOwnerIdentityInvalid, "%s.entity.trustRegistrationNumber is required for a trust", field)
+		}
+	case "GOVERNMENT":
+		if entity.DepartmentCode == "" {
+			return newError(ErrCodeOwnerIdentityInvalid, "%s.entity.departmentCode is required for a government owner", field)
+		}
+	}
+
+	if entity.SignatoryName == "" {
+		return newError(ErrCodeOwnerIdentityInvalid, "%s.entity.signatoryName is required for owner type %s", field, ownerType)
+	}
+	return nil
+}
+
+// entityIdentifier returns the identifier a non-individual owner is
+// indexed under: CIN, trust registration number, department code or,
+// failing those, PAN. It is empty for individuals.
+func entityIdentifier(entity *EntityDetails) string {
+	if entity == nil {
+		return ""
+	}
+	for _, id := range []string{entity.CIN, entity.TrustRegistrationNumber, entity.DepartmentCode, entity.PAN} {
+		if id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// createEntityIndexKey creates a composite key for the entity lookup index.
+func createEntityIndexKey(ctx contractapi.TransactionContextInterface, identifier, propertyID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(KeyPrefixEntityIndex, []string{identifier, propertyID})
+}
+
+// putEntityIndex indexes a property under a non-individual owner's
+// entity identifier. It does nothing for individuals.
+func putEntityIndex(ctx contractapi.TransactionContextInterface, owner Owner, propertyID string) error {
+	identifier := entityIdentifier(owner.Entity)
+	if identifier == "" {
+		return nil
+	}
+	key, err := createEntityIndexKey(ctx, identifier, propertyID)
+	if err != nil {
+		return internalError("failed to create entity index key: %v", err)
+	}
+	return ctx.GetStub().PutState(key, []byte(propertyID))
+}
+
+// deleteEntityIndex removes a property from a non-individual owner's
+// entity index. It does nothing for individuals.
+func deleteEntityIndex(ctx contractapi.TransactionContextInterface, owner Owner, propertyID string) error {
+	identifier := entityIdentifier(owner.Entity)
+	if identifier == "" {
+		return nil
+	}
+	key, err := createEntityIndexKey(ctx, identifier, propertyID)
+	if err != nil {
+		return internalError("failed to create entity index key for deletion: %v", err)
+	}
+	return ctx.GetStub().DelState(key)
+}
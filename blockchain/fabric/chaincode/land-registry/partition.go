@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// PARTITION
+// ============================================================
+// A partition deed divides a co-owned parcel among its owners: each owner
+// (or a declared group of them) takes a defined sub-plot and stops
+// co-owning the rest. Unlike SplitProperty, the children's owners are
+// derived from the parent's, never supplied by the caller.
+
+// partitionShareTolerance is how far, in percentage points, a child's
+// share of the parent's area may differ from its owners' combined share.
+const partitionShareTolerance = 5.0
+
+// PartitionProperty partitions a co-owned property among its current
+// owners under a registered partition deed. partitionJSON is a list of
+// PartitionChild; every current owner must be allotted exactly one child
+// and no one else may be. Each child's area must track its owners'
+// combined share of the parent within 5 percentage points. The parent
+// is split as in SplitProperty (it must be free of encumbrances), and a
+// PARTITION mutation is recorded for every child.
+// Only registrars in the property's state can record a partition.
+// Emits PROPERTY_PARTITIONED.
+func (s *LandRegistryContract) PartitionProperty(ctx contractapi.TransactionContextInterface, propertyID, partitionJSON, partitionDeedHash string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+	if err := validateDocumentHash(partitionDeedHash, "partitionDeedHash"); err != nil {
+		return err
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+	if len(property.CurrentOwner.Owners) < 2 {
+		return newError(ErrCodeValidationError, "property %s has a single owner; use SplitProperty", propertyID)
+	}
+
+	var children []PartitionChild
+	if err := json.Unmarshal([]byte(partitionJSON), &children); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse partition JSON: %v", err)
+	}
+
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	splits, err := partitionSplits(property, children, partitionDeedHash, settings.BighaSqMeters, now)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.splitParcel(ctx, property, splits, "REQUIRE_CLEAR", nil)
+	if err != nil {
+		return err
+	}
+
+	previousOwner := property.CurrentOwner.Owners[0]
+	var allotments []PartitionAllotment
+	for i, split := range splits {
+		mutationID := fmt.Sprintf("mut_%s_%d", txID[:8], i+1)
+		newOwner := split.OwnerInfo.Owners[0]
+		mutation := MutationRecord{
+			DocType:    "mutationRecord",
+			MutationID: mutationID,
+			PropertyID: split.NewPropertyID,
+			Type:       "PARTITION",
+			PreviousOwner: OwnerRef{
+				AadhaarHash: previousOwner.AadhaarHash,
+				Name:        previousOwner.Name,
+				OwnerType:   property.CurrentOwner.OwnerType,
+				Entity:      previousOwner.Entity,
+			},
+			NewOwner: OwnerRef{
+				AadhaarHash: newOwner.AadhaarHash,
+				Name:        newOwner.Name,
+				IsMinor:     newOwner.IsMinor,
+				Guardian:    newOwner.Guardian,
+				OwnerType:   split.OwnerInfo.OwnerType,
+				Entity:      newOwner.Entity,
+			},
+			Status:               "AUTO_APPROVED",
+			ApprovedBy:           "system",
+			ApprovedAt:           now,
+			RevenueRecordUpdated: true,
+			SourcePropertyID:     propertyID,
+			DeedHash:             partitionDeedHash,
+			CreatedAt:            now,
+		}
+		mutationKey, err := createMutationKey(ctx, mutationID)
+		if err != nil {
+			return internalError("failed to create mutation key: %v", err)
+		}
+		mutationBytes, err := canonicalMarshal(mutation)
+		if err != nil {
+			return internalError("failed to marshal mutation: %v", err)
+		}
+		if err := ctx.GetStub().PutState(mutationKey, mutationBytes); err != nil {
+			return internalError("failed to create mutation record: %v", err)
+		}
+		if err := emitMutationCreated(ctx, &mutation, property.Location.StateCode); err != nil {
+			return err
+		}
+
+		for _, owner := range split.OwnerInfo.Owners {
+			allotments = append(allotments, PartitionAllotment{
+				OwnerHash:     owner.AadhaarHash,
+				NewPropertyID: split.NewPropertyID,
+				MutationID:    mutationID,
+			})
+		}
+	}
+
+	event := PropertyPartitionedEvent{
+		Type:               "PROPERTY_PARTITIONED",
+		OriginalPropertyID: propertyID,
+		PartitionDeedHash:  partitionDeedHash,
+		NewPropertyIDs:     result.NewPropertyIDs,
+		Allotments:         allotments,
+		FabricTxID:         txID,
+		Timestamp:          now,
+		StateCode:          property.Location.StateCode,
+		ChannelID:          ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "PROPERTY_PARTITIONED", event)
+}
+
+// partitionSplits checks a partition against the parent's owners and
+// builds the split requests for it. Each child's owners are copied from
+// the parent, with shares rescaled to 100 among the child's owners in
+// proportion to their shares in the parent.
+func partitionSplits(property *LandRecord, children []PartitionChild, deedHash string, bighaSqMeters float64, now string) ([]SplitRequest, error) {
+	parentOwners := make(map[string]Owner, len(property.CurrentOwner.Owners))
+	for _, owner := range property.CurrentOwner.Owners {
+		parentOwners[owner.AadhaarHash] = owner
+	}
+
+	allotted := make(map[string]string, len(parentOwners))
+	splits := make([]SplitRequest, 0, len(children))
+	for i, child := range children {
+		if len(child.AllottedTo) == 0 {
+			return nil, newError(ErrCodeValidationError, "partition[%d]: allottedTo must name at least one owner", i)
+		}
+
+		shareSum := 0
+		for _, hash := range child.AllottedTo {
+			owner, ok := parentOwners[hash]
+			if !ok {
+				return nil, newError(ErrCodePartitionOwnerMismatch, "partition[%d]: %s is not a current owner of %s", i, hash, property.PropertyID)
+			}
+			if other, dup := allotted[hash]; dup {
+				return nil, newError(ErrCodePartitionOwnerMismatch, "partition[%d]: owner %s is already allotted %s", i, hash, other)
+			}
+			allotted[hash] = child.NewPropertyID
+			shareSum += owner.SharePercentage
+		}
+
+		// The plot should be worth roughly what its owners held
+		childArea, err := ConvertArea(child.Area.Value, child.Area.Unit, property.Area.Unit, bighaSqMeters)
+		if err != nil {
+			return nil, errorAt(fmt.Sprintf("partition[%d]", i), err)
+		}
+		areaShare := childArea / property.Area.Value * 100
+		if math.Abs(areaShare-float64(shareSum)) > partitionShareTolerance {
+			return nil, newError(ErrCodePartitionShareMismatch, "partition[%d]: %s is %.1f%% of the parent's area but its owners hold %d%%",
+				i, child.NewPropertyID, areaShare, shareSum)
+		}
+
+		owners := make([]Owner, 0, len(child.AllottedTo))
+		assigned := 0
+		for _, hash := range child.AllottedTo {
+			owner := parentOwners[hash]
+			owner.SharePercentage = owner.SharePercentage * 100 / shareSum
+			assigned += owner.SharePercentage
+			owners = append(owners, owner)
+		}
+		// Integer rounding leftovers go to the first allottee
+		owners[0].SharePercentage += 100 - assigned
+
+		ownershipType := "JOINT"
+		if len(owners) == 1 {
+			ownershipType = "SOLE"
+		}
+
+		splits = append(splits, SplitRequest{
+			NewPropertyID:     child.NewPropertyID,
+			SurveyNumber:      child.SurveyNumber,
+			SubSurveyNumber:   child.SubSurveyNumber,
+			Area:              child.Area,
+			Boundaries:        child.Boundaries,
+			ExemptionOrderRef: child.ExemptionOrderRef,
+			AnnotationIDs:     child.AnnotationIDs,
+			OwnerInfo: OwnerInfo{
+				OwnerType:               property.CurrentOwner.OwnerType,
+				Owners:                  owners,
+				OwnershipType:           ownershipType,
+				AcquisitionType:         "PARTITION",
+				AcquisitionDate:         now[:10],
+				AcquisitionDocumentHash: deedHash,
+			},
+		})
+	}
+
+	for _, owner := range property.CurrentOwner.Owners {
+		if _, ok := allotted[owner.AadhaarHash]; !ok {
+			return nil, newError(ErrCodePartitionOwnerMismatch, "owner %s is not allotted any sub-plot", owner.AadhaarHash)
+		}
+	}
+	return splits, nil
+}
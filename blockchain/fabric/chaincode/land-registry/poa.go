@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// POWER OF ATTORNEY
+// ============================================================
+// An owner may act through an attorney under a registered power of
+// attorney. A transfer names the POA a seller or buyer acts under, and
+// SignTransfer accepts an attorney's signature for their principal;
+// either way the POA must be ACTIVE, within its validity period and
+// cover the property, or the call fails with POA_INVALID.
+
+// poaScopes lists the POA scopes: SPECIFIC covers only PropertyIDs.
+var poaScopes = map[string]bool{
+	"SPECIFIC": true,
+	"GENERAL":  true,
+}
+
+// RegisterPOA registers a power of attorney. poaJSON is a POARecord
+// with principalHash, attorneyHash, scope (SPECIFIC with propertyIds,
+// or GENERAL), poaDeedHash, and validFrom and validUntil (YYYY-MM-DD).
+// Only registrars can register POAs. Writes an audit entry and emits
+// POA_REGISTERED.
+func (s *LandRegistryContract) RegisterPOA(ctx contractapi.TransactionContextInterface, poaJSON string) (string, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return "", err
+	}
+
+	var poa POARecord
+	if err := json.Unmarshal([]byte(poaJSON), &poa); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse POA JSON: %v", err)
+	}
+	if err := validatePOA(&poa); err != nil {
+		return "", err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	if poa.ValidUntil < now[:10] {
+		return "", newError(ErrCodeValidationError, "POA expired on %s", poa.ValidUntil)
+	}
+
+	poa.DocType = "poaRecord"
+	poa.POAID = "poa_" + txID[:8]
+	poa.Status = "ACTIVE"
+	poa.RegisteredBy = getCallerID(ctx)
+	poa.RegisteredAt = now
+	poa.FabricTxID = txID
+	if err := putPOA(ctx, &poa); err != nil {
+		return "", err
+	}
+	if err := recordAudit(ctx, "RegisterPOA", poa.POAID); err != nil {
+		return "", err
+	}
+	if err := emitPOAEvent(ctx, "POA_REGISTERED", &poa); err != nil {
+		return "", err
+	}
+	return poa.POAID, nil
+}
+
+// RevokePOA revokes a power of attorney, e.g. on the principal's
+// cancellation deed or death. Transfers that name it can no longer be
+// executed. Only registrars can revoke POAs. Writes an audit entry and
+// emits POA_REVOKED.
+func (s *LandRegistryContract) RevokePOA(ctx contractapi.TransactionContextInterface, poaID, reason string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+
+	poa, err := getPOA(ctx, poaID)
+	if err != nil {
+		return err
+	}
+	if poa.Status != "ACTIVE" {
+		return newError(ErrCodePoaInvalid, "POA %s is already %s", poaID, poa.Status)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	poa.Status = "REVOKED"
+	poa.RevokeReason = reason
+	poa.RevokedBy = getCallerID(ctx)
+	poa.RevokedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	poa.FabricTxID = ctx.GetStub().GetTxID()
+	if err := putPOA(ctx, poa); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, "RevokePOA", poaID); err != nil {
+		return err
+	}
+	return emitPOAEvent(ctx, "POA_REVOKED", poa)
+}
+
+// GetPOA returns a power of attorney by ID, for a registrar verifying
+// a claimed POA.
+func (s *LandRegistryContract) GetPOA(ctx contractapi.TransactionContextInterface, poaID string) (*POARecord, error) {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return nil, err
+	}
+	return getPOA(ctx, poaID)
+}
+
+// validatePOA checks a POA submitted to RegisterPOA.
+func validatePOA(poa *POARecord) error {
+	if err := validateAadhaarHash(poa.PrincipalHash, "principalHash"); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(poa.AttorneyHash, "attorneyHash"); err != nil {
+		return err
+	}
+	if poa.AttorneyHash == poa.PrincipalHash {
+		return newError(ErrCodeValidationError, "attorney cannot be the principal")
+	}
+	poa.Scope = strings.ToUpper(poa.Scope)
+	if !poaScopes[poa.Scope] {
+		return newError(ErrCodeValidationError, "scope '%s' must be SPECIFIC or GENERAL", poa.Scope)
+	}
+	if poa.Scope == "SPECIFIC" && len(poa.PropertyIDs) == 0 {
+		return newError(ErrCodeValidationError, "a SPECIFIC POA must list propertyIds")
+	}
+	if poa.Scope == "GENERAL" {
+		poa.PropertyIDs = nil
+	}
+	for _, propertyID := range poa.PropertyIDs {
+		if err := validatePropertyID(propertyID); err != nil {
+			return err
+		}
+	}
+	if err := validateDocumentHash(poa.POADeedHash, "poaDeedHash"); err != nil {
+		return err
+	}
+
+	from, err := time.Parse("2006-01-02", poa.ValidFrom)
+	if err != nil {
+		return newError(ErrCodeValidationError, "validFrom must be YYYY-MM-DD")
+	}
+	until, err := time.Parse("2006-01-02", poa.ValidUntil)
+	if err != nil {
+		return newError(ErrCodeValidationError, "validUntil must be YYYY-MM-DD")
+	}
+	if until.Before(from) {
+		return newError(ErrCodeValidationError, "validUntil must not be before validFrom")
+	}
+	return nil
+}
+
+// requireValidPOA loads poaID and checks that attorneyHash may act
+// under it for principalHash on propertyID at the transaction time.
+// Every failure other than a missing POA is POA_INVALID.
+func requireValidPOA(ctx contractapi.TransactionContextInterface, poaID, principalHash, attorneyHash, propertyID string) (*POARecord, error) {
+	poa, err := getPOA(ctx, poaID)
+	if err != nil {
+		return nil, err
+	}
+	if poa.Status != "ACTIVE" {
+		return nil, newError(ErrCodePoaInvalid, "POA %s is %s", poaID, poa.Status).with("poaId", poaID)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	today := time.Unix(timestamp.Seconds, 0).Format("2006-01-02")
+	if today < poa.ValidFrom || today > poa.ValidUntil {
+		return nil, newError(ErrCodePoaInvalid, "POA %s is valid from %s to %s", poaID, poa.ValidFrom, poa.ValidUntil).with("poaId", poaID)
+	}
+	if poa.PrincipalHash != principalHash {
+		return nil, newError(ErrCodePoaInvalid, "POA %s was not granted by the party", poaID).with("poaId", poaID)
+	}
+	if poa.AttorneyHash != attorneyHash {
+		return nil, newError(ErrCodePoaInvalid, "POA %s was not granted to the acting party", poaID).with("poaId", poaID)
+	}
+	if poa.Scope == "SPECIFIC" {
+		covered := false
+		for _, id := range poa.PropertyIDs {
+			if id == propertyID {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return nil, newError(ErrCodePoaInvalid, "POA %s does not cover %s", poaID, propertyID).with("poaId", poaID)
+		}
+	}
+	return poa, nil
+}
+
+// checkTransferPOAs validates the POAs a transfer's parties act under,
+// both those named on the transfer and those its signatures were given
+// under, so a POA revoked or expired since is caught before execution.
+func checkTransferPOAs(ctx contractapi.TransactionContextInterface, transfer *TransferRecord) error {
+	parties := []struct {
+		ref         *POAReference
+		aadhaarHash string
+		field       string
+	}{
+		{transfer.SellerPOA, transfer.Seller.AadhaarHash, "sellerPoa"},
+		{transfer.BuyerPOA, transfer.Buyer.AadhaarHash, "buyerPoa"},
+	}
+	for _, party := range parties {
+		if party.ref == nil {
+			continue
+		}
+		if party.ref.POAID == "" {
+			return newError(ErrCodeValidationError, "%s.poaId is required", party.field)
+		}
+		if err := validateAadhaarHash(party.ref.AttorneyHash, party.field+".attorneyHash"); err != nil {
+			return err
+		}
+		if _, err := requireValidPOA(ctx, party.ref.POAID, party.aadhaarHash, party.ref.AttorneyHash, transfer.PropertyID); err != nil {
+			return err
+		}
+	}
+	for _, sig := range transfer.Signatures {
+		if sig.POAID == "" {
+			continue
+		}
+		if _, err := requireValidPOA(ctx, sig.POAID, sig.AadhaarHash, sig.AttorneyHash, transfer.PropertyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPOA reads a POA by ID.
+func getPOA(ctx contractapi.TransactionContextInterface, poaID string) (*POARecord, error) {
+	if poaID == "" {
+		return nil, newError(ErrCodeValidationError, "poaId is required")
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixPOA, []string{poaID})
+	if err != nil {
+		return nil, internalError("failed to create POA key: %v", err)
+	}
+	poaBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read POA: %v", err)
+	}
+	if poaBytes == nil {
+		return nil, newError(ErrCodePoaNotFound, "%s does not exist", poaID).with("poaId", poaID)
+	}
+	var poa POARecord
+	if err := json.Unmarshal(poaBytes, &poa); err != nil {
+		return nil, internalError("failed to unmarshal POA: %v", err)
+	}
+	return &poa, nil
+}
+
+// putPOA writes a POA under its key.
+func putPOA(ctx contractapi.TransactionContextInterface, poa *POARecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixPOA, []string{poa.POAID})
+	if err != nil {
+		return internalError("failed to create POA key: %v", err)
+	}
+	poaBytes, err := canonicalMarshal(poa)
+	if err != nil {
+		return internalError("failed to marshal POA: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, poaBytes); err != nil {
+		return internalError("failed to write POA %s: %v", poa.POAID, err)
+	}
+	return nil
+}
+
+func emitPOAEvent(ctx contractapi.TransactionContextInterface, eventType string, poa *POARecord) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := POAEvent{
+		Type:          eventType,
+		POAID:         poa.POAID,
+		PrincipalHash: poa.PrincipalHash,
+		AttorneyHash:  poa.AttorneyHash,
+		Scope:         poa.Scope,
+		PropertyIDs:   poa.PropertyIDs,
+		ValidUntil:    poa.ValidUntil,
+		Reason:        poa.RevokeReason,
+		FabricTxID:    ctx.GetStub().GetTxID(),
+		Timestamp:     time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		ChannelID:     ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, eventType, event)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// PROPERTY ID GENERATION
+// ============================================================
+// A property ID embeds the record's location codes and survey numbers:
+// {StateCode}-{DistrictCode}-{TehsilCode}-{VillageCode}-{SurveyNo}-{SubSurveyNo}.
+// Authorization decisions such as extractStateCode read the state from
+// the ID, so the ID must agree with the record it names.
+
+// buildPropertyID assembles the canonical property ID for a location and
+// survey number. An empty sub-survey number is written as "0".
+func buildPropertyID(loc Location, surveyNo, subSurveyNo string) (string, error) {
+	if subSurveyNo == "" {
+		subSurveyNo = "0"
+	}
+	propertyID := strings.Join([]string{
+		loc.StateCode,
+		loc.DistrictCode,
+		loc.TehsilCode,
+		loc.VillageCode,
+		surveyNo,
+		subSurveyNo,
+	}, "-")
+	if err := validatePropertyID(propertyID); err != nil {
+		return "", err
+	}
+	return propertyID, nil
+}
+
+// GeneratePropertyID returns the canonical property ID for a location
+// (a Location JSON object) and survey number, so clients do not have to
+// assemble the segments themselves.
+func (s *LandRegistryContract) GeneratePropertyID(ctx contractapi.TransactionContextInterface, locationJSON, surveyNo, subSurveyNo string) (string, error) {
+	var loc Location
+	if err := json.Unmarshal([]byte(locationJSON), &loc); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse location JSON: %v", err)
+	}
+	if surveyNo == "" {
+		return "", newError(ErrCodeValidationError, "surveyNo is required")
+	}
+	return buildPropertyID(loc, surveyNo, subSurveyNo)
+}
+
+// validatePropertyIDMatches checks that each segment of a well-formed
+// propertyID matches the location and survey numbers it is registered
+// with, failing with PROPERTY_ID_MISMATCH on the first segment that
+// disagrees.
+func validatePropertyIDMatches(propertyID string, loc Location, surveyNo, subSurveyNo string) error {
+	if subSurveyNo == "" {
+		subSurveyNo = "0"
+	}
+	parts := strings.Split(propertyID, "-")
+	expected := []struct {
+		segment string
+		field   string
+		value   string
+	}{
+		{"state code", "location.stateCode", loc.StateCode},
+		{"district code", "location.districtCode", loc.DistrictCode},
+		{"tehsil code", "location.tehsilCode", loc.TehsilCode},
+		{"village code", "location.villageCode", loc.VillageCode},
+		{"survey number", "surveyNumber", surveyNo},
+		{"sub-survey number", "subSurveyNumber", subSurveyNo},
+	}
+	for i, e := range expected {
+		if parts[i] != e.value {
+			return newError(ErrCodePropertyIdMismatch, "propertyId %s has %s '%s' but %s is '%s'",
+				propertyID, e.segment, parts[i], e.field, e.value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// RECEIPTS
+// ============================================================
+
+// newReceipt starts the receipt of the current transaction for
+// recordID, now at status.
+func newReceipt(ctx contractapi.TransactionContextInterface, recordID, status string) *Receipt {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	return &Receipt{
+		RecordID:   recordID,
+		Status:     status,
+		FabricTxID: ctx.GetStub().GetTxID(),
+		Timestamp:  time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+	}
+}
+
+// withRequest records the requestId the receipt answers.
+func (r *Receipt) withRequest(requestID string) *Receipt {
+	r.RequestID = requestID
+	return r
+}
+
+// relate adds a record the transaction created or changed.
+func (r *Receipt) relate(kind, id, status string) *Receipt {
+	r.Related = append(r.Related, ReceiptRecord{Kind: kind, ID: id, Status: status})
+	return r
+}
+
+// relateCarried adds the encumbrances a split or merge carried: each
+// clone, and each original it superseded.
+func (r *Receipt) relateCarried(carried []EncumbranceCarriedEvent) *Receipt {
+	superseded := map[string]bool{}
+	for _, c := range carried {
+		r.relate("encumbrance", c.EncumbranceID, "ACTIVE")
+		if !superseded[c.OriginalEncumbranceID] {
+			superseded[c.OriginalEncumbranceID] = true
+			r.relate("encumbrance", c.OriginalEncumbranceID, "SUPERSEDED")
+		}
+	}
+	return r
+}
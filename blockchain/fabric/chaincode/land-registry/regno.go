@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// REGISTRATION NUMBER UNIQUENESS
+// ============================================================
+// A deed's registration number is unique within its sub-registrar
+// office and book. Duplicate numbers are a red flag for forged deeds,
+// so every number is claimed in the REGNO index when a property is
+// registered or a transfer deed is executed.
+
+// claimRegistrationNumber records info's SRO + book + registration
+// number as used by propertyID (and transferID, if any). Reuse fails
+// with REGISTRATION_NUMBER_DUPLICATE naming the earlier holder, unless
+// an admin supplies DuplicateOverrideJustification, in which case the
+// override is appended to the index entry. Records without a
+// registration number are not indexed. batch tracks numbers claimed
+// earlier in the same transaction, which GetState cannot see; pass nil
+// outside bulk operations.
+func claimRegistrationNumber(ctx contractapi.TransactionContextInterface, info RegistrationInfo, propertyID, transferID string, batch map[string]string) error {
+	if info.RegistrationNumber == "" {
+		return nil
+	}
+	if info.SubRegistrarOffice == "" {
+		return newError(ErrCodeValidationError, "registrationInfo.subRegistrarOffice is required with a registration number")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixRegNo, []string{info.SubRegistrarOffice, info.BookNumber, info.RegistrationNumber})
+	if err != nil {
+		return internalError("failed to create registration number key: %v", err)
+	}
+	if earlier, ok := batch[key]; ok {
+		return newError(ErrCodeRegistrationNumberDuplicate, "%s/%s/%s is already used by property %s earlier in this batch",
+			info.SubRegistrarOffice, info.BookNumber, info.RegistrationNumber, earlier)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	existingBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return internalError("failed to read registration number index: %v", err)
+	}
+
+	var entry RegistrationNumberEntry
+	if existingBytes == nil {
+		entry = RegistrationNumberEntry{
+			DocType:            "registrationNumberEntry",
+			SubRegistrarOffice: info.SubRegistrarOffice,
+			BookNumber:         info.BookNumber,
+			RegistrationNumber: info.RegistrationNumber,
+			PropertyID:         propertyID,
+			TransferID:         transferID,
+			RecordedAt:         now,
+			FabricTxID:         txID,
+		}
+	} else {
+		if err := json.Unmarshal(existingBytes, &entry); err != nil {
+			return internalError("failed to unmarshal registration number entry: %v", err)
+		}
+		if info.DuplicateOverrideJustification == "" {
+			earlier := "property " + entry.PropertyID
+			if entry.TransferID != "" {
+				earlier += " (transfer " + entry.TransferID + ")"
+			}
+			return newError(ErrCodeRegistrationNumberDuplicate, "%s/%s/%s is already registered to %s",
+				info.SubRegistrarOffice, info.BookNumber, info.RegistrationNumber, earlier)
+		}
+		// Genuine historical duplicates can only be accepted by an admin
+		if err := requireRole(ctx, "admin"); err != nil {
+			return newError(ErrCodeRegistrationNumberDuplicate, "only an admin can override a duplicate registration number: %v", err)
+		}
+		entry.Overrides = append(entry.Overrides, RegistrationOverride{
+			PropertyID:    propertyID,
+			TransferID:    transferID,
+			Justification: info.DuplicateOverrideJustification,
+			OverriddenBy:  getCallerID(ctx),
+			OverriddenAt:  now,
+			FabricTxID:    txID,
+		})
+	}
+
+	entryBytes, err := canonicalMarshal(entry)
+	if err != nil {
+		return internalError("failed to marshal registration number entry: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, entryBytes); err != nil {
+		return internalError("failed to write registration number index: %v", err)
+	}
+	if batch != nil {
+		batch[key] = propertyID
+	}
+	return nil
+}
+
+// releaseRegistrationNumber undoes claimRegistrationNumber for a
+// transfer that is rolled back. An override the transfer added is
+// dropped; if the transfer holds the entry itself, the earliest
+// override takes its place, or the entry is removed when there is none,
+// so the number is free again.
+func releaseRegistrationNumber(ctx contractapi.TransactionContextInterface, info RegistrationInfo, transferID string) error {
+	if info.RegistrationNumber == "" {
+		return nil
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixRegNo, []string{info.SubRegistrarOffice, info.BookNumber, info.RegistrationNumber})
+	if err != nil {
+		return internalError("failed to create registration number key: %v", err)
+	}
+	entryBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return internalError("failed to read registration number index: %v", err)
+	}
+	if entryBytes == nil {
+		return nil
+	}
+	var entry RegistrationNumberEntry
+	if err := json.Unmarshal(entryBytes, &entry); err != nil {
+		return internalError("failed to unmarshal registration number entry: %v", err)
+	}
+
+	if entry.TransferID == transferID {
+		if len(entry.Overrides) == 0 {
+			if err := ctx.GetStub().DelState(key); err != nil {
+				return internalError("failed to release registration number: %v", err)
+			}
+			return nil
+		}
+		next := entry.Overrides[0]
+		entry.PropertyID = next.PropertyID
+		entry.TransferID = next.TransferID
+		entry.RecordedAt = next.OverriddenAt
+		entry.FabricTxID = next.FabricTxID
+		entry.Overrides = entry.Overrides[1:]
+	} else {
+		kept := []RegistrationOverride{}
+		for _, override := range entry.Overrides {
+			if override.TransferID != transferID {
+				kept = append(kept, override)
+			}
+		}
+		if len(kept) == len(entry.Overrides) {
+			return nil
+		}
+		entry.Overrides = kept
+	}
+	if len(entry.Overrides) == 0 {
+		entry.Overrides = nil
+	}
+
+	entryBytes, err = canonicalMarshal(entry)
+	if err != nil {
+		return internalError("failed to marshal registration number entry: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, entryBytes); err != nil {
+		return internalError("failed to write registration number index: %v", err)
+	}
+	return nil
+}
+
+// GetRegistrationNumber returns the REGNO index entry for a deed
+// registration number, showing which property or transfer holds it.
+func (s *LandRegistryContract) GetRegistrationNumber(ctx contractapi.TransactionContextInterface, subRegistrarOffice, bookNumber, registrationNumber string) (*RegistrationNumberEntry, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixRegNo, []string{subRegistrarOffice, bookNumber, registrationNumber})
+	if err != nil {
+		return nil, internalError("failed to create registration number key: %v", err)
+	}
+	entryBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read registration number index: %v", err)
+	}
+	if entryBytes == nil {
+		return nil, newError(ErrCodeRegistrationNumberNotFound, "%s/%s/%s", subRegistrarOffice, bookNumber, registrationNumber)
+	}
+
+	var entry RegistrationNumberEntry
+	if err := json.Unmarshal(entryBytes, &entry); err != nil {
+		return nil, internalError("failed to unmarshal registration number entry: %v", err)
+	}
+	return &entry, nil
+}
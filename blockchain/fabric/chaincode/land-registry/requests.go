@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// IDEMPOTENT REQUESTS
+// ============================================================
+// The API retries a submission when it times out waiting for the
+// commit, which can record the same encumbrance or dispute twice. A
+// caller that sets requestId in the payload gets at most one record per
+// requestId: the REQUEST index remembers what the first call created and
+// a repeat returns that record instead of creating another.
+//
+// A requestId belongs to the caller that first used it. Entries are
+// keyed by the caller's MSP ID and enrollment ID, so one caller's
+// requestId can neither reveal nor block another's record, and the
+// lookup runs only after the function's own authorization checks. The
+// entry keeps a hash of the payload; a repeat with a different payload
+// is a REQUEST_ID_CONFLICT rather than a silent duplicate.
+
+// maxRequestIDLength bounds requestId, which becomes part of a state key.
+const maxRequestIDLength = 128
+
+// createRequestKey returns the caller-scoped REQUEST key for requestID.
+// Callers without an enrollment ID are keyed by certificate fingerprint.
+func createRequestKey(ctx contractapi.TransactionContextInterface, requestID string) (string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", newError(ErrCodeAccessDenied, "failed to read caller MSP ID: %v", err)
+	}
+	callerKey := callerEnrollmentID(ctx)
+	if callerKey == "" {
+		if callerKey, err = callerFingerprint(ctx); err != nil {
+			return "", err
+		}
+	}
+	return ctx.GetStub().CreateCompositeKey(KeyPrefixRequest, []string{mspID, callerKey, requestID})
+}
+
+// requestPayloadHash returns the hex SHA-256 of a function's JSON argument.
+func requestPayloadHash(payload string) string {
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+// findRequest returns the receipt of the caller's earlier call to
+// function with requestID, or nil if there was none. A requestID the
+// caller used for a different function, or with a different payload, is
+// a REQUEST_ID_CONFLICT. Call it after the function's authorization
+// checks.
+func findRequest(ctx contractapi.TransactionContextInterface, requestID, function, payload string) (*Receipt, error) {
+	if len(requestID) > maxRequestIDLength {
+		return nil, newError(ErrCodeValidationError, "requestId cannot exceed %d characters", maxRequestIDLength)
+	}
+	key, err := createRequestKey(ctx, requestID)
+	if err != nil {
+		return nil, internalError("failed to create request key: %v", err)
+	}
+	entryBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read request index: %v", err)
+	}
+	if entryBytes == nil {
+		return nil, nil
+	}
+
+	var entry RequestIndexEntry
+	if err := json.Unmarshal(entryBytes, &entry); err != nil {
+		return nil, internalError("failed to unmarshal request index entry: %v", err)
+	}
+	if entry.Function != function {
+		return nil, newError(ErrCodeRequestIdConflict, "requestId %s was already used by %s", requestID, entry.Function)
+	}
+	if entry.PayloadHash != requestPayloadHash(payload) {
+		return nil, newError(ErrCodeRequestIdConflict, "requestId %s was already used with a different payload", requestID)
+	}
+	return &Receipt{
+		RecordID:   entry.RecordID,
+		RequestID:  requestID,
+		Duplicate:  true,
+		FabricTxID: entry.FabricTxID,
+		Timestamp:  entry.CreatedAt,
+	}, nil
+}
+
+// putRequest records that the caller's call to function with requestID
+// and payload created the record stored under recordKey.
+func putRequest(ctx contractapi.TransactionContextInterface, requestID, function, payload, recordKey, recordID string) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
+
+	entry := RequestIndexEntry{
+		DocType:     "requestIndexEntry",
+		RequestID:   requestID,
+		MspID:       mspID,
+		Function:    function,
+		PayloadHash: requestPayloadHash(payload),
+		RecordKey:   recordKey,
+		RecordID:    recordID,
+		CreatedBy:   getCallerID(ctx),
+		CreatedAt:   time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		FabricTxID:  ctx.GetStub().GetTxID(),
+	}
+	key, err := createRequestKey(ctx, requestID)
+	if err != nil {
+		return internalError("failed to create request key: %v", err)
+	}
+	entryBytes, err := canonicalMarshal(entry)
+	if err != nil {
+		return internalError("failed to marshal request index entry: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, entryBytes); err != nil {
+		return internalError("failed to write request index entry: %v", err)
+	}
+	return nil
+}
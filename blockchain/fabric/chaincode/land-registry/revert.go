@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// SPLIT REVERSAL
+// ============================================================
+// Rule 9 forbids deleting records, so a mistaken split is undone by
+// cancelling its children and reactivating the parent. That is only
+// safe while no child has been dealt with since the split.
+
+// RevertSplit undoes a split whose children are all untouched since it
+// was made: every child must still be ACTIVE, written by the split
+// transaction only, and have no transfers, disputes, mutations, splits
+// or encumbrances of its own. Encumbrances carried from the parent are
+// restored to it. The children are marked CANCELLED and drop out of the
+// owner, survey, location and children indexes; the parent becomes
+// ACTIVE again and regains them. Parent and children each advance their
+// provenance sequence. Only admins in the property's state can revert.
+// Emits SPLIT_REVERTED.
+func (s *LandRegistryContract) RevertSplit(ctx contractapi.TransactionContextInterface, originalPropertyID, reason string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(originalPropertyID); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required to revert a split")
+	}
+
+	parent, err := s.GetProperty(ctx, originalPropertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireStateAccess(ctx, parent.Location.StateCode); err != nil {
+		return err
+	}
+	if parent.Status != "SPLIT" {
+		return newError(ErrCodePropertyNotSplit, "property %s has status %s", originalPropertyID, parent.Status)
+	}
+
+	children, err := s.splitChildren(ctx, parent)
+	if err != nil {
+		return err
+	}
+	if len(children) == 0 {
+		return newError(ErrCodeSplitNotRevertible, "no children of %s are recorded in the children index", originalPropertyID)
+	}
+
+	// Every child must be exactly as the split left it
+	var carried []*EncumbranceRecord
+	for _, child := range children {
+		childCarried, err := s.checkChildUntouched(ctx, parent, child)
+		if err != nil {
+			return err
+		}
+		carried = append(carried, childCarried...)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	childIDs := make([]string, 0, len(children))
+	for _, child := range children {
+		if err := removeLookupIndexes(ctx, child); err != nil {
+			return err
+		}
+		if err := deleteChildIndex(ctx, originalPropertyID, child.PropertyID); err != nil {
+			return err
+		}
+		if err := setPropertyStatus(ctx, child, "CANCELLED", "split reverted: "+reason); err != nil {
+			return err
+		}
+		child.Provenance.Sequence++
+		child.UpdatedAt = now
+		child.UpdatedBy = getCallerID(ctx)
+		child.FabricTxID = txID
+		if err := putLandRecord(ctx, child); err != nil {
+			return err
+		}
+		childIDs = append(childIDs, child.PropertyID)
+	}
+
+	// Carried charges go back onto the parent
+	restored := map[string]bool{}
+	for _, enc := range carried {
+		enc.Status = "CANCELLED"
+		if err := putEncumbrance(ctx, enc); err != nil {
+			return err
+		}
+		if restored[enc.CarriedFromEncumbranceID] {
+			continue
+		}
+		original, err := getEncumbrance(ctx, originalPropertyID, enc.CarriedFromEncumbranceID)
+		if err != nil {
+			return err
+		}
+		original.Status = "ACTIVE"
+		if err := putEncumbrance(ctx, original); err != nil {
+			return err
+		}
+		restored[enc.CarriedFromEncumbranceID] = true
+	}
+
+	if err := setPropertyStatus(ctx, parent, "ACTIVE", "split reverted: "+reason); err != nil {
+		return err
+	}
+	if len(restored) > 0 {
+		parent.EncumbranceStatus = "ENCUMBERED"
+	}
+	parent.Provenance.Sequence++
+	parent.UpdatedAt = now
+	parent.UpdatedBy = getCallerID(ctx)
+	parent.FabricTxID = txID
+	if err := putLandRecord(ctx, parent); err != nil {
+		return err
+	}
+	if err := restoreLookupIndexes(ctx, parent); err != nil {
+		return err
+	}
+
+	event := SplitRevertedEvent{
+		Type:               "SPLIT_REVERTED",
+		OriginalPropertyID: originalPropertyID,
+		CancelledIDs:       childIDs,
+		Reason:             reason,
+		FabricTxID:         txID,
+		Timestamp:          now,
+		StateCode:          parent.Location.StateCode,
+		ChannelID:          ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "SPLIT_REVERTED", event)
+}
+
+// splitChildren loads the properties recorded in the children index
+// under parent.
+func (s *LandRegistryContract) splitChildren(ctx contractapi.TransactionContextInterface, parent *LandRecord) ([]*LandRecord, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixChildIndex, []string{parent.PropertyID})
+	if err != nil {
+		return nil, internalError("failed to query children index: %v", err)
+	}
+	defer iterator.Close()
+
+	var children []*LandRecord
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate children index: %v", err)
+		}
+		child, err := s.GetProperty(ctx, string(kv.Value))
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// checkChildUntouched fails, naming the child, if anything has happened
+// to it since the split. It returns the encumbrances carried onto the
+// child from the parent, which the revert hands back.
+func (s *LandRegistryContract) checkChildUntouched(ctx contractapi.TransactionContextInterface, parent, child *LandRecord) ([]*EncumbranceRecord, error) {
+	id := child.PropertyID
+	if child.Status != "ACTIVE" {
+		return nil, newError(ErrCodeSplitNotRevertible, "child %s has status %s", id, child.Status)
+	}
+	if child.DisputeStatus != "CLEAR" {
+		return nil, newError(ErrCodeSplitNotRevertible, "child %s is disputed", id)
+	}
+	// The split wrote parent and children in one transaction; any later
+	// write to the child (transfer, correction, payment...) moves its
+	// FabricTxID on
+	if child.FabricTxID != parent.FabricTxID || child.Provenance.Sequence != 1 {
+		return nil, newError(ErrCodeSplitNotRevertible, "child %s has been updated since the split", id)
+	}
+
+	grandchildren, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixChildIndex, []string{id})
+	if err != nil {
+		return nil, internalError("failed to query children index: %v", err)
+	}
+	hasGrandchildren := grandchildren.HasNext()
+	grandchildren.Close()
+	if hasGrandchildren {
+		return nil, newError(ErrCodeSplitNotRevertible, "child %s has been split or merged further", id)
+	}
+
+	disputes, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixDispute, []string{id})
+	if err != nil {
+		return nil, internalError("failed to query disputes: %v", err)
+	}
+	hasDisputes := disputes.HasNext()
+	disputes.Close()
+	if hasDisputes {
+		return nil, newError(ErrCodeSplitNotRevertible, "child %s has dispute records", id)
+	}
+
+	// Encumbrances carried from the parent are fine; anything else is new
+	encIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixEncumbrance, []string{id})
+	if err != nil {
+		return nil, internalError("failed to query encumbrances: %v", err)
+	}
+	defer encIterator.Close()
+	var carried []*EncumbranceRecord
+	for encIterator.HasNext() {
+		kv, err := encIterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate encumbrances: %v", err)
+		}
+		var enc EncumbranceRecord
+		if err := json.Unmarshal(kv.Value, &enc); err != nil {
+			return nil, internalError("failed to unmarshal encumbrance: %v", err)
+		}
+		if enc.CarriedFrom != parent.PropertyID || enc.Status != "ACTIVE" {
+			return nil, newError(ErrCodeSplitNotRevertible, "child %s has encumbrance %s (%s)", id, enc.EncumbranceID, enc.Status)
+		}
+		carried = append(carried, &enc)
+	}
+
+	// Transfers and mutations are keyed by their own IDs, so look them up
+	// by property. A partition's own mutations are part of the split.
+	if found, err := queryExists(ctx, fmt.Sprintf(`{"selector":{"docType":"transferRecord","propertyId":"%s"}}`, id)); err != nil {
+		return nil, err
+	} else if found {
+		return nil, newError(ErrCodeSplitNotRevertible, "child %s has transfer records", id)
+	}
+	// CouchDB's $ne skips documents without the field, so filter here
+	// rather than in the selector
+	mutations, err := ctx.GetStub().GetQueryResult(fmt.Sprintf(`{"selector":{"docType":"mutationRecord","propertyId":"%s"}}`, id))
+	if err != nil {
+		return nil, internalError("failed to run query: %v", err)
+	}
+	defer mutations.Close()
+	for mutations.HasNext() {
+		kv, err := mutations.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate mutations: %v", err)
+		}
+		var mutation MutationRecord
+		if err := json.Unmarshal(kv.Value, &mutation); err != nil {
+			return nil, internalError("failed to unmarshal mutation: %v", err)
+		}
+		if mutation.SourcePropertyID != parent.PropertyID {
+			return nil, newError(ErrCodeSplitNotRevertible, "child %s has mutation records", id)
+		}
+	}
+	return carried, nil
+}
+
+// queryExists reports whether a CouchDB rich query matches anything.
+func queryExists(ctx contractapi.TransactionContextInterface, queryString string) (bool, error) {
+	iterator, err := ctx.GetStub().GetQueryResult(queryString)
+	if err != nil {
+		return false, internalError("failed to run query: %v", err)
+	}
+	defer iterator.Close()
+	return iterator.HasNext(), nil
+}
+
+// getEncumbrance reads one encumbrance record.
+func getEncumbrance(ctx contractapi.TransactionContextInterface, propertyID, encumbranceID string) (*EncumbranceRecord, error) {
+	key, err := createEncumbranceKey(ctx, propertyID, encumbranceID)
+	if err != nil {
+		return nil, internalError("failed to create encumbrance key: %v", err)
+	}
+	encBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read encumbrance: %v", err)
+	}
+	if encBytes == nil {
+		return nil, newError(ErrCodeEncumbranceNotFound, "%s on %s", encumbranceID, propertyID)
+	}
+	var enc EncumbranceRecord
+	if err := json.Unmarshal(encBytes, &enc); err != nil {
+		return nil, internalError("failed to unmarshal encumbrance: %v", err)
+	}
+	return &enc, nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// matrixRoles are the roles tried against every function in the matrix.
+var matrixRoles = []string{"registrar", "tehsildar", "bank", "court", "admin", "treasury", "enforcement", "citizen", "sdm", "commissioner"}
+
+// roleMatrix lists contract functions with the roles each allows when
+// the state has no role hierarchy. Each call passes arguments that fail
+// validation, so an allowed role gets any error but ACCESS_DENIED.
+var roleMatrix = []struct {
+	name    string
+	allowed string
+	call    func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error
+}{
+	{"RegisterProperty", "registrar", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.RegisterProperty(ctx, "{")
+		return err
+	}},
+	{"InitiateTransfer", "registrar", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.InitiateTransfer(ctx, "{")
+		return err
+	}},
+	{"SplitProperty", "registrar", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.SplitProperty(ctx, "bad-id", "[]", "", "")
+		return err
+	}},
+	{"FinalizeAfterCooling", "admin registrar", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.FinalizeAfterCooling(ctx, "missing")
+		return err
+	}},
+	{"ApproveMutation", "tehsildar", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		return s.ApproveMutation(ctx, "missing")
+	}},
+	{"RecordTaxPayment", "admin tehsildar", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		return s.RecordTaxPayment(ctx, "bad-id", "{}")
+	}},
+	{"AddEncumbrance", "admin bank court", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.AddEncumbrance(ctx, "{")
+		return err
+	}},
+	{"FlagDispute", "admin court", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.FlagDispute(ctx, "{")
+		return err
+	}},
+	{"RecordCompensationPayment", "admin treasury", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		return s.RecordCompensationPayment(ctx, "missing", "{")
+	}},
+	{"RecordValuation", "admin bank registrar", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.RecordValuation(ctx, "bad-id", "{")
+		return err
+	}},
+	{"ReleaseLapsedCropLoan", "admin bank registrar tehsildar", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		return s.ReleaseLapsedCropLoan(ctx, "")
+	}},
+	{"PlaceInvestigationHold", "admin enforcement", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.PlaceInvestigationHold(ctx, "bad-id", "{")
+		return err
+	}},
+	{"ApplyProbate", "court", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.ApplyProbate(ctx, "", "", "")
+		return err
+	}},
+	{"SetRegistrySettings", "admin", func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error {
+		return s.SetRegistrySettings(ctx, "TS", "{")
+	}},
+}
+
+// effectiveRoles returns, space separated and sorted, the matrix roles
+// that get past fn's role check on ledger.
+func effectiveRoles(ledger *testLedger, fn func(s *LandRegistryContract, ctx contractapi.TransactionContextInterface) error) string {
+	ledger.t.Helper()
+	var allowed []string
+	for _, role := range matrixRoles {
+		err := ledger.submit(newTestIdentity(ledger.t, "TelanganaMSP", role, "TS"), func(ctx contractapi.TransactionContextInterface) error {
+			return fn(ledger.contract, ctx)
+		})
+		if errorCode(err) != ErrCodeAccessDenied {
+			allowed = append(allowed, role)
+		}
+	}
+	sort.Strings(allowed)
+	return strings.Join(allowed, " ")
+}
+
+func TestRoleMatrixWithoutHierarchy(t *testing.T) {
+	ledger := newTestLedger(t)
+	for _, fn := range roleMatrix {
+		if got := effectiveRoles(ledger, fn.call); got != fn.allowed {
+			t.Errorf("%s allows [%s], want [%s]", fn.name, got, fn.allowed)
+		}
+	}
+}
+
+func TestRoleMatrixWithHierarchy(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", RoleHierarchy: map[string][]string{
+		"admin":        {"registrar"},
+		"commissioner": {"sdm"},
+		"sdm":          {"tehsildar"},
+	}})
+	// admin ⊇ registrar, and commissioner ⊇ sdm ⊇ tehsildar transitively
+	expand := func(allowed string) string {
+		roles := strings.Fields(allowed)
+		has := map[string]bool{}
+		for _, role := range roles {
+			has[role] = true
+		}
+		if has["registrar"] && !has["admin"] {
+			roles = append(roles, "admin")
+		}
+		if has["tehsildar"] {
+			roles = append(roles, "sdm", "commissioner")
+		}
+		sort.Strings(roles)
+		return strings.Join(roles, " ")
+	}
+	for _, fn := range roleMatrix {
+		if got, want := effectiveRoles(ledger, fn.call), expand(fn.allowed); got != want {
+			t.Errorf("%s allows [%s], want [%s]", fn.name, got, want)
+		}
+	}
+}
+
+func TestRoleHierarchyIsPerState(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", RoleHierarchy: map[string][]string{"admin": {"registrar"}}})
+
+	check := func(stateCode string) error {
+		return ledger.submit(newTestIdentity(t, "AdminOrgMSP", "admin", stateCode), func(ctx contractapi.TransactionContextInterface) error {
+			return requireRole(ctx, "registrar")
+		})
+	}
+	if err := check("TS"); err != nil {
+		t.Fatalf("TS admin should act as registrar: %v", err)
+	}
+	expectCode(t, check("KA"), ErrCodeAccessDenied)
+	expectCode(t, check(""), ErrCodeAccessDenied)
+}
+
+func TestRequireAnyRoleReportsActingRole(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", RoleHierarchy: map[string][]string{"admin": {"bank"}}})
+
+	acting := func(role string, allowed ...string) string {
+		var got string
+		ledger.mustSubmit(newTestIdentity(t, "TelanganaMSP", role, "TS"), func(ctx contractapi.TransactionContextInterface) error {
+			var err error
+			got, err = requireAnyRole(ctx, allowed...)
+			return err
+		})
+		return got
+	}
+	if got := acting("admin", "bank", "admin"); got != "admin" {
+		t.Errorf("admin listed directly acts as %s, want admin", got)
+	}
+	if got := acting("admin", "bank", "registrar"); got != "bank" {
+		t.Errorf("admin through the hierarchy acts as %s, want bank", got)
+	}
+}
+
+func TestSetRegistrySettingsRoleHierarchy(t *testing.T) {
+	ledger := newTestLedger(t)
+	admin := newTestIdentity(t, "AdminOrgMSP", "admin", "TS")
+	set := func(settingsJSON string) error {
+		return ledger.submit(admin, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.SetRegistrySettings(ctx, "TS", settingsJSON)
+		})
+	}
+
+	expectCode(t, set(`{"roleHierarchy":{"admin":["registrar"],"registrar":["admin"]}}`), ErrCodeValidationError)
+	expectCode(t, set(`{"roleHierarchy":{"admin":["admin"]}}`), ErrCodeValidationError)
+	expectCode(t, set(`{"roleHierarchy":{"admin":[""]}}`), ErrCodeValidationError)
+
+	if err := set(`{"roleHierarchy":{"admin":["registrar"]}}`); err != nil {
+		t.Fatalf("SetRegistrySettings: %v", err)
+	}
+	if !ledger.hasEvent("ROLE_HIERARCHY_CHANGED") {
+		t.Fatal("changing the hierarchy should emit ROLE_HIERARCHY_CHANGED")
+	}
+	if err := set(`{"roleHierarchy":{"admin":["registrar"]}}`); err != nil {
+		t.Fatalf("SetRegistrySettings: %v", err)
+	}
+	if ledger.hasEvent("ROLE_HIERARCHY_CHANGED") {
+		t.Fatal("an unchanged hierarchy should not emit ROLE_HIERARCHY_CHANGED")
+	}
+
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	err := ledger.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetRegistrySettings(ctx, "TS", `{}`)
+	})
+	expectCode(t, err, ErrCodeAccessDenied)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// TRANSFER ROLLBACK (Rule 8)
+// ============================================================
+// The cooling period exists so that objections can be heard before a
+// transfer becomes final. When one is upheld, the registration is
+// reversed rather than erased (Rule 9): the owners the transfer replaced
+// are restored from the snapshot taken when it was registered, a
+// ROLLBACK mutation records the change of owner for the revenue
+// records, and the transfer stays on the ledger as ROLLED_BACK. What
+// else registering it did is undone too: its registration number is
+// released, its cultivation carry-over withdrawn and any tax-arrears
+// flag it raised withdrawn.
+
+// RollbackTransfer reverses a REGISTERED_PENDING_FINALITY transfer
+// within its cooling period, or after a sweep left its window BLOCKED.
+// The owners and deed registration the transfer replaced are restored
+// and regain their owner index entries, the transfer's registration
+// number is released and its cultivation disclosure withdrawn, the
+// cooling period is closed and a ROLLBACK mutation is recorded. The
+// buyer must still be the sole owner. Only registrars with access to the
+// property's state can roll transfers back. Emits TRANSFER_ROLLED_BACK,
+// listing the mutation, the COOLING_PERIOD_ENDED outcome ROLLED_BACK and,
+// if the transfer flagged tax arrears, TAX_ARREARS_FLAG_WITHDRAWN.
+func (s *LandRegistryContract) RollbackTransfer(ctx contractapi.TransactionContextInterface, transferID, reason string) (*Receipt, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return nil, newError(ErrCodeValidationError, "reason is required to roll back a transfer")
+	}
+
+	transferKey, err := createTransferKey(ctx, transferID)
+	if err != nil {
+		return nil, internalError("failed to create transfer key: %v", err)
+	}
+	transferBytes, err := ctx.GetStub().GetState(transferKey)
+	if err != nil || transferBytes == nil {
+		return nil, newError(ErrCodeTransferNotFound, "%s", transferID)
+	}
+	var transfer TransferRecord
+	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
+		return nil, internalError("failed to unmarshal transfer: %v", err)
+	}
+	if transfer.Status == "REGISTERED_FINAL" {
+		return nil, newError(ErrCodeTransferAlreadyFinal, "transfer %s is final and cannot be rolled back", transferID)
+	}
+	if transfer.Status != "REGISTERED_PENDING_FINALITY" {
+		return nil, newError(ErrCodeTransferInvalidState, "expected REGISTERED_PENDING_FINALITY, got %s", transfer.Status)
+	}
+	if transfer.PreviousOwnerInfo == nil {
+		return nil, newError(ErrCodeTransferNotRevertible, "transfer %s was registered without an owner snapshot", transferID)
+	}
+
+	property, err := s.GetProperty(ctx, transfer.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	nowTime := time.Unix(timestamp.Seconds, 0)
+	now := nowTime.Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	// The window must be this transfer's, and either still open or
+	// closed as BLOCKED by a sweep
+	if property.CoolingPeriod.TransferID != transferID {
+		return nil, newError(ErrCodeTransferNotRevertible, "%s is not in the cooling period of transfer %s", transfer.PropertyID, transferID)
+	}
+	if property.CoolingPeriod.Active {
+		expiresAt, err := time.Parse(time.RFC3339, property.CoolingPeriod.ExpiresAt)
+		if err == nil && !nowTime.Before(expiresAt) {
+			return nil, newError(ErrCodeTransferNotRevertible, "the cooling period of transfer %s ended at %s", transferID, property.CoolingPeriod.ExpiresAt)
+		}
+	}
+	if property.Status == "TRANSFER_IN_PROGRESS" {
+		return nil, newError(ErrCodeTransferInProgress, "the buyer has since initiated a transfer of %s", transfer.PropertyID)
+	}
+	buyerShares := transferBuyerShares(&transfer)
+	currentShares := ownerShares(property.CurrentOwner.Owners)
+	if len(currentShares) != len(buyerShares) || currentShares[0] != buyerShares[0] {
+		return nil, newError(ErrCodeTransferNotRevertible, "the owners of %s have changed since transfer %s was registered", transfer.PropertyID, transferID)
+	}
+
+	// Restore the replaced owners and their index entries
+	buyerOwner := property.CurrentOwner
+	for _, owner := range buyerOwner.Owners {
+		if err := deleteOwnerIndex(ctx, owner.AadhaarHash, property.PropertyID); err != nil {
+			return nil, internalError("failed to remove owner index: %v", err)
+		}
+		if err := deleteEntityIndex(ctx, owner, property.PropertyID); err != nil {
+			return nil, internalError("failed to remove entity index: %v", err)
+		}
+	}
+	property.CurrentOwner = *transfer.PreviousOwnerInfo
+	for _, owner := range property.CurrentOwner.Owners {
+		if err := putOwnerIndex(ctx, owner.AadhaarHash, property.PropertyID); err != nil {
+			return nil, internalError("failed to restore owner index: %v", err)
+		}
+		if err := putEntityIndex(ctx, owner, property.PropertyID); err != nil {
+			return nil, internalError("failed to restore entity index: %v", err)
+		}
+	}
+	if transfer.PreviousRegistrationInfo != nil {
+		property.RegistrationInfo = *transfer.PreviousRegistrationInfo
+	}
+
+	// Undo the transfer's other side effects (see applyTransfer)
+	if err := releaseRegistrationNumber(ctx, transfer.RegistrationInfo, transferID); err != nil {
+		return nil, err
+	}
+	if transfer.CultivationDisclosed {
+		if err := withdrawCultivationDisclosure(ctx, &transfer); err != nil {
+			return nil, err
+		}
+		transfer.CultivationDisclosed = false
+	}
+	if transfer.TaxArrearsFlagged {
+		if err := withdrawTaxArrearsFlag(ctx, property, transferID); err != nil {
+			return nil, err
+		}
+		transfer.TaxArrearsFlagged = false
+	}
+
+	if property.CoolingPeriod.Active {
+		if err := countCoolingPeriods(ctx, property.Location.StateCode, -1); err != nil {
+			return nil, err
+		}
+	}
+	property.CoolingPeriod = CoolingPeriod{Active: false, ExpiresAt: ""}
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.Provenance.Sequence++
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Status = "ROLLED_BACK"
+	transfer.StatusHistory = append(transfer.StatusHistory, StatusEntry{
+		Status:          "ROLLED_BACK",
+		At:              now,
+		By:              getCallerID(ctx),
+		CertFingerprint: fingerprint,
+	})
+	transfer.RollbackReason = reason
+	transfer.FabricTxID = txID
+	transfer.UpdatedAt = now
+	transferUpdatedBytes, _ := canonicalMarshal(transfer)
+	if err := ctx.GetStub().PutState(transferKey, transferUpdatedBytes); err != nil {
+		return nil, internalError("failed to update transfer: %v", err)
+	}
+
+	// Rule 3: the revenue records follow the owner back
+	restoredOwner := property.CurrentOwner.Owners[0]
+	mutationID := "mut_" + txID[:8]
+	mutation := MutationRecord{
+		DocType:    "mutationRecord",
+		MutationID: mutationID,
+		PropertyID: transfer.PropertyID,
+		Type:       "ROLLBACK",
+		TransferID: transferID,
+		PreviousOwner: OwnerRef{
+			AadhaarHash: buyerOwner.Owners[0].AadhaarHash,
+			Name:        buyerOwner.Owners[0].Name,
+		},
+		NewOwner: OwnerRef{
+			AadhaarHash: restoredOwner.AadhaarHash,
+			Name:        restoredOwner.Name,
+			IsMinor:     restoredOwner.IsMinor,
+			Guardian:    restoredOwner.Guardian,
+			OwnerType:   property.CurrentOwner.OwnerType,
+			Entity:      restoredOwner.Entity,
+		},
+		Status:               "AUTO_APPROVED",
+		ApprovedBy:           "system",
+		ApprovedAt:           now,
+		RevenueRecordUpdated: true,
+		CreatedAt:            now,
+	}
+	mutationKey, _ := createMutationKey(ctx, mutationID)
+	mutationBytes, _ := canonicalMarshal(mutation)
+	if err := ctx.GetStub().PutState(mutationKey, mutationBytes); err != nil {
+		return nil, internalError("failed to create mutation record: %v", err)
+	}
+	if err := emitMutationCreated(ctx, &mutation, property.Location.StateCode); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, "RollbackTransfer", transferID); err != nil {
+		return nil, err
+	}
+
+	recordHash, err := landRecordHash(ctx, property)
+	if err != nil {
+		return nil, err
+	}
+	event := TransferEvent{
+		Type:              "TRANSFER_ROLLED_BACK",
+		TransferID:        transferID,
+		PropertyID:        transfer.PropertyID,
+		PreviousOwnerHash: buyerOwner.Owners[0].AadhaarHash,
+		NewOwnerHash:      restoredOwner.AadhaarHash,
+		FabricTxID:        txID,
+		Timestamp:         now,
+		MutationID:        mutationID,
+		StateCode:         property.Location.StateCode,
+		ChannelID:         ctx.GetStub().GetChannelID(),
+		PreviousOwners:    currentShares,
+		NewOwners:         ownerShares(property.CurrentOwner.Owners),
+		RecordHash:        recordHash,
+		Reason:            reason,
+	}
+	if err := emitEvent(ctx, "TRANSFER_ROLLED_BACK", event); err != nil {
+		return nil, err
+	}
+	if err := emitCoolingPeriodEnded(ctx, property, transferID, "ROLLED_BACK"); err != nil {
+		return nil, err
+	}
+	return newReceipt(ctx, transferID, transfer.Status).
+		relate("mutation", mutationID, mutation.Status).
+		relate("property", property.PropertyID, property.Status), nil
+}
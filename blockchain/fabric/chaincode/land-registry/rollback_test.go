@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// rollbackTestTransfer registers a sale of a TS property that leaves
+// every side effect a rollback has to undo: it claims registration
+// number SRN/I/1234/2027, carries over a tenant's cultivation entry and
+// flags two years of land revenue arrears. It returns the ledger, the
+// registrar and the transfer, now REGISTERED_PENDING_FINALITY.
+func rollbackTestTransfer(t *testing.T) (*testLedger, *testIdentity, *TransferRecord) {
+	t.Helper()
+	ledger := newTestLedger(t)
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", TaxArrearsAlertYears: 1})
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+
+	seller, buyer := newTestSigner(t, 1), newTestSigner(t, 2)
+	witness1, witness2 := newTestSigner(t, 3), newTestSigner(t, 4)
+	property := testProperty("142", 1)
+	property.TaxInfo.AnnualLandRevenue = 120000
+	property.TaxInfo.PaidUpToYear = "2023-24"
+	ledger.registerTestProperty(registrar, property)
+	ledger.registerTestSigners(registrar, seller, buyer, witness1, witness2)
+
+	cultivationJSON, _ := json.Marshal(CultivationRecord{
+		PropertyID: property.PropertyID, CultivatorHash: testAadhaarHash(9), CultivatorName: "Tenant 9",
+		TenureClass: "TENANT", StartDate: "2024-06-01",
+	})
+	ledger.mustSubmit(newTestOfficer(t, "tehsildar", "TS", "HYD", "SRN"), func(ctx contractapi.TransactionContextInterface) error {
+		_, err := ledger.contract.RecordCultivator(ctx, string(cultivationJSON))
+		return err
+	})
+
+	transfer := testTransfer(property.PropertyID, seller, buyer, witness1, witness2)
+	transfer.RegistrationInfo = RegistrationInfo{RegistrationNumber: "1234/2027", BookNumber: "I", SubRegistrarOffice: "SRN", RegistrationDate: "2027-03-15"}
+	transferID := ledger.initiateTestTransfer(registrar, transfer)
+	ledger.signTestTransfer(registrar, transferID, seller, buyer, witness1, witness2)
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer: %v", err)
+	}
+	return ledger, registrar, ledger.readTransfer(transferID)
+}
+
+// rollback calls RollbackTransfer as id.
+func (l *testLedger) rollback(id *testIdentity, transferID string) error {
+	return l.submit(id, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := l.contract.RollbackTransfer(ctx, transferID, "objection upheld by the sub-registrar")
+		return err
+	})
+}
+
+// readCultivation returns the cultivation entries recorded on a property.
+func (l *testLedger) readCultivation(propertyID string) []CultivationRecord {
+	l.t.Helper()
+	var entries []CultivationRecord
+	l.mustSubmit(newTestIdentity(l.t, "AdminOrgMSP", "admin", "IN"), func(ctx contractapi.TransactionContextInterface) error {
+		iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixCultivation, []string{propertyID})
+		if err != nil {
+			return err
+		}
+		defer iterator.Close()
+		for iterator.HasNext() {
+			kv, err := iterator.Next()
+			if err != nil {
+				return err
+			}
+			var entry CultivationRecord
+			if err := json.Unmarshal(kv.Value, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	return entries
+}
+
+// getRegistrationNumber looks up SRN/I/1234/2027.
+func (l *testLedger) getRegistrationNumber() (*RegistrationNumberEntry, error) {
+	var entry *RegistrationNumberEntry
+	err := l.submit(newTestIdentity(l.t, "TelanganaMSP", "registrar", "TS"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		entry, err = l.contract.GetRegistrationNumber(ctx, "SRN", "I", "1234/2027")
+		return err
+	})
+	return entry, err
+}
+
+func TestRollbackTransferUndoesRegistration(t *testing.T) {
+	ledger, registrar, transfer := rollbackTestTransfer(t)
+
+	// The transfer left its side effects behind
+	if !transfer.CultivationDisclosed || !transfer.TaxArrearsFlagged {
+		t.Fatalf("transfer cultivationDisclosed = %v, taxArrearsFlagged = %v", transfer.CultivationDisclosed, transfer.TaxArrearsFlagged)
+	}
+	if entry, err := ledger.getRegistrationNumber(); err != nil || entry.TransferID != transfer.TransferID {
+		t.Fatalf("GetRegistrationNumber = %+v, %v", entry, err)
+	}
+
+	if err := ledger.rollback(registrar, transfer.TransferID); err != nil {
+		t.Fatalf("RollbackTransfer: %v", err)
+	}
+	fields := ledger.eventFields(ledger.events[len(ledger.events)-1].Name)
+	for _, list := range []string{"mutationsCreated", "coolingPeriodsEnded", "taxArrearsFlagsWithdrawn"} {
+		if _, ok := fields[list]; !ok {
+			t.Errorf("last event does not list %s: %v", list, fields)
+		}
+	}
+
+	property := ledger.readProperty(transfer.PropertyID)
+	if owners := property.CurrentOwner.Owners; len(owners) != 1 || owners[0].AadhaarHash != testAadhaarHash(1) {
+		t.Fatalf("owners = %+v, want the seller", owners)
+	}
+	if property.RegistrationInfo.RegistrationNumber != "" || property.CoolingPeriod.Active {
+		t.Fatalf("property = %+v", property)
+	}
+	rolledBack := ledger.readTransfer(transfer.TransferID)
+	if rolledBack.Status != "ROLLED_BACK" || rolledBack.CultivationDisclosed || rolledBack.TaxArrearsFlagged {
+		t.Fatalf("transfer = %+v", rolledBack)
+	}
+
+	// The registration number is free again and the cultivation entry
+	// no longer lists the transfer
+	_, err := ledger.getRegistrationNumber()
+	expectCode(t, err, ErrCodeRegistrationNumberNotFound)
+	cultivation := ledger.readCultivation(transfer.PropertyID)
+	if len(cultivation) != 1 || len(cultivation[0].DisclosedInTransfers) != 0 || cultivation[0].Status != "ACTIVE" {
+		t.Fatalf("cultivation = %+v", cultivation)
+	}
+}
+
+func TestRollbackTransferRefusals(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare changes the ledger after the transfer is registered
+		// and returns the identity that asks for the rollback
+		prepare func(t *testing.T, ledger *testLedger, registrar *testIdentity, transfer *TransferRecord) *testIdentity
+		want    string
+	}{
+		{"final transfer", func(t *testing.T, ledger *testLedger, registrar *testIdentity, transfer *TransferRecord) *testIdentity {
+			ledger.now = testTime.Add(73 * time.Hour)
+			ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+				_, err := ledger.contract.FinalizeAfterCooling(ctx, transfer.TransferID)
+				return err
+			})
+			return registrar
+		}, ErrCodeTransferAlreadyFinal},
+		{"no owner snapshot", func(t *testing.T, ledger *testLedger, registrar *testIdentity, transfer *TransferRecord) *testIdentity {
+			ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+				transfer.PreviousOwnerInfo = nil
+				key, _ := createTransferKey(ctx, transfer.TransferID)
+				transferBytes, _ := canonicalMarshal(transfer)
+				return ctx.GetStub().PutState(key, transferBytes)
+			})
+			return registrar
+		}, ErrCodeTransferNotRevertible},
+		{"buyer no longer sole owner", func(t *testing.T, ledger *testLedger, registrar *testIdentity, transfer *TransferRecord) *testIdentity {
+			ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+				property, err := readLandRecord(ctx, transfer.PropertyID)
+				if err != nil {
+					return err
+				}
+				property.CurrentOwner.Owners[0].SharePercentage = 50
+				property.CurrentOwner.Owners = append(property.CurrentOwner.Owners,
+					Owner{AadhaarHash: testAadhaarHash(5), Name: "Owner 5", SharePercentage: 50})
+				return putLandRecord(ctx, property)
+			})
+			return registrar
+		}, ErrCodeTransferNotRevertible},
+		{"tehsildar", func(t *testing.T, ledger *testLedger, registrar *testIdentity, transfer *TransferRecord) *testIdentity {
+			return newTestOfficer(t, "tehsildar", "TS", "HYD", "SRN")
+		}, ErrCodeAccessDenied},
+		{"registrar of another state", func(t *testing.T, ledger *testLedger, registrar *testIdentity, transfer *TransferRecord) *testIdentity {
+			return newTestIdentity(t, "KarnatakaMSP", "registrar", "KA")
+		}, ErrCodeStateMismatch},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ledger, registrar, transfer := rollbackTestTransfer(t)
+			caller := tc.prepare(t, ledger, registrar, transfer)
+			expectCode(t, ledger.rollback(caller, transfer.TransferID), tc.want)
+
+			// Nothing the transfer did is undone by a refused rollback
+			if entry, err := ledger.getRegistrationNumber(); err != nil || entry.TransferID != transfer.TransferID {
+				t.Fatalf("GetRegistrationNumber = %+v, %v", entry, err)
+			}
+			if got := ledger.readProperty(transfer.PropertyID).CurrentOwner.Owners[0].AadhaarHash; got != testAadhaarHash(2) {
+				t.Fatalf("owner = %s, want the buyer", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// TEHSILDAR SANCTION FOR SPLITS AND MERGES
+// ============================================================
+// Splits and merges redraw the revenue map, and many states require the
+// tehsildar to sanction them. With RequireSubdivisionSanction set, the
+// registrar proposes and the stored request is executed only when a
+// tehsildar sanctions it; nothing is re-supplied at sanction time.
+
+// requireSingleStepSubdivision fails if the state has two-phase splits
+// and merges switched on.
+func requireSingleStepSubdivision(ctx contractapi.TransactionContextInterface, stateCode string) error {
+	settings, err := getSettings(ctx, stateCode)
+	if err != nil {
+		return err
+	}
+	if settings.RequireSubdivisionSanction {
+		return newError(ErrCodeSanctionRequired, "splits and merges in state %s need tehsildar sanction; use ProposeSplit or ProposeMerge", stateCode)
+	}
+	return nil
+}
+
+// ProposeSplit stores a split of propertyID for tehsildar sanction. The
+// arguments are those of SplitProperty and are validated in full when
+// the split is sanctioned. Only registrars in the property's state can
+// propose. Returns the proposal ID. Emits SPLIT_PROPOSED.
+func (s *LandRegistryContract) ProposeSplit(ctx contractapi.TransactionContextInterface, propertyID, splitsJSON, encumbranceHandling, consentRefsJSON string) (string, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return "", err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return "", err
+	}
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return "", err
+	}
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return "", err
+	}
+	if property.Status != "ACTIVE" {
+		return "", newError(ErrCodePropertyNotActive, "cannot split property with status %s", property.Status)
+	}
+
+	var splits []SplitRequest
+	if err := json.Unmarshal([]byte(splitsJSON), &splits); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse splits JSON: %v", err)
+	}
+	if _, _, err := parseEncumbranceHandling(encumbranceHandling, consentRefsJSON); err != nil {
+		return "", err
+	}
+
+	proposal := SubdivisionProposal{
+		Type:                "SPLIT",
+		PropertyIDs:         []string{propertyID},
+		Location:            property.Location,
+		RequestJSON:         splitsJSON,
+		EncumbranceHandling: encumbranceHandling,
+		ConsentRefsJSON:     consentRefsJSON,
+	}
+	return s.storeProposal(ctx, &proposal)
+}
+
+// ProposeMerge stores a merge for tehsildar sanction. The arguments are
+// those of MergeProperties and are validated in full when the merge is
+// sanctioned. Only registrars in the properties' state can propose.
+// Returns the proposal ID. Emits MERGE_PROPOSED.
+func (s *LandRegistryContract) ProposeMerge(ctx contractapi.TransactionContextInterface, propertyIDsJSON, mergedPropertyJSON, encumbranceHandling, consentRefsJSON string) (string, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return "", err
+	}
+
+	var propertyIDs []string
+	if err := json.Unmarshal([]byte(propertyIDsJSON), &propertyIDs); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse property IDs: %v", err)
+	}
+	if len(propertyIDs) < 2 {
+		return "", newError(ErrCodeValidationError, "merge requires at least 2 properties")
+	}
+	for i, propID := range propertyIDs {
+		if err := validatePropertyID(propID); err != nil {
+			return "", errorAt(fmt.Sprintf("property[%d]", i), err)
+		}
+	}
+	first, err := s.GetProperty(ctx, propertyIDs[0])
+	if err != nil {
+		return "", err
+	}
+	if err := requireStateAccess(ctx, first.Location.StateCode); err != nil {
+		return "", err
+	}
+
+	var mergedProperty LandRecord
+	if err := json.Unmarshal([]byte(mergedPropertyJSON), &mergedProperty); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse merged property JSON: %v", err)
+	}
+	if _, _, err := parseEncumbranceHandling(encumbranceHandling, consentRefsJSON); err != nil {
+		return "", err
+	}
+
+	proposal := SubdivisionProposal{
+		Type:                "MERGE",
+		PropertyIDs:         propertyIDs,
+		Location:            first.Location,
+		RequestJSON:         mergedPropertyJSON,
+		EncumbranceHandling: encumbranceHandling,
+		ConsentRefsJSON:     consentRefsJSON,
+	}
+	return s.storeProposal(ctx, &proposal)
+}
+
+// storeProposal fills in the audit fields of a new proposal, stores it
+// as PENDING_SANCTION and emits SPLIT_PROPOSED or MERGE_PROPOSED.
+func (s *LandRegistryContract) storeProposal(ctx contractapi.TransactionContextInterface, proposal *SubdivisionProposal) (string, error) {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	proposal.DocType = "subdivisionProposal"
+	proposal.ProposalID = "prp_" + txID[:8]
+	proposal.Status = "PENDING_SANCTION"
+	proposal.ProposedBy = getCallerID(ctx)
+	proposal.ProposedAt = now
+	proposal.FabricTxID = txID
+	if err := putProposal(ctx, proposal); err != nil {
+		return "", err
+	}
+
+	if err := emitProposalEvent(ctx, proposal, proposal.Type+"_PROPOSED", nil, nil); err != nil {
+		return "", err
+	}
+	return proposal.ProposalID, nil
+}
+
+// SanctionSplit executes a proposed split exactly as stored. Only
+// tehsildars with jurisdiction over the property (see
+// requireJurisdiction) can sanction. Emits SPLIT_SANCTIONED.
+func (s *LandRegistryContract) SanctionSplit(ctx contractapi.TransactionContextInterface, proposalID string) error {
+	proposal, err := s.pendingProposal(ctx, proposalID, "SPLIT")
+	if err != nil {
+		return err
+	}
+
+	handling, consentRefs, err := parseEncumbranceHandling(proposal.EncumbranceHandling, proposal.ConsentRefsJSON)
+	if err != nil {
+		return err
+	}
+	var splits []SplitRequest
+	if err := json.Unmarshal([]byte(proposal.RequestJSON), &splits); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse stored splits JSON: %v", err)
+	}
+	property, err := s.GetProperty(ctx, proposal.PropertyIDs[0])
+	if err != nil {
+		return err
+	}
+
+	result, err := s.splitParcel(ctx, property, splits, handling, consentRefs)
+	if err != nil {
+		return err
+	}
+
+	if err := decideProposal(ctx, proposal, "SANCTIONED", ""); err != nil {
+		return err
+	}
+	return emitProposalEvent(ctx, proposal, "SPLIT_SANCTIONED", result.NewPropertyIDs, result.EncumbrancesCarried)
+}
+
+// SanctionMerge executes a proposed merge exactly as stored. Only
+// tehsildars with jurisdiction over every source property can sanction.
+// Emits MERGE_SANCTIONED.
+func (s *LandRegistryContract) SanctionMerge(ctx contractapi.TransactionContextInterface, proposalID string) error {
+	proposal, err := s.pendingProposal(ctx, proposalID, "MERGE")
+	if err != nil {
+		return err
+	}
+
+	handling, consentRefs, err := parseEncumbranceHandling(proposal.EncumbranceHandling, proposal.ConsentRefsJSON)
+	if err != nil {
+		return err
+	}
+	var mergedProperty LandRecord
+	if err := json.Unmarshal([]byte(proposal.RequestJSON), &mergedProperty); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse stored merged property JSON: %v", err)
+	}
+	if err := validatePropertyID(mergedProperty.PropertyID); err != nil {
+		return err
+	}
+
+	carried, err := s.mergeParcels(ctx, proposal.PropertyIDs, &mergedProperty, handling, consentRefs)
+	if err != nil {
+		return err
+	}
+
+	if err := decideProposal(ctx, proposal, "SANCTIONED", ""); err != nil {
+		return err
+	}
+	return emitProposalEvent(ctx, proposal, "MERGE_SANCTIONED", []string{mergedProperty.PropertyID}, carried)
+}
+
+// RejectSplit rejects a proposed split with a reason. Only tehsildars
+// with jurisdiction over the property can reject. Emits SPLIT_REJECTED.
+func (s *LandRegistryContract) RejectSplit(ctx contractapi.TransactionContextInterface, proposalID, reason string) error {
+	return s.rejectProposal(ctx, proposalID, "SPLIT", reason)
+}
+
+// RejectMerge rejects a proposed merge with a reason. Only tehsildars
+// with jurisdiction over every source property can reject. Emits
+// MERGE_REJECTED.
+func (s *LandRegistryContract) RejectMerge(ctx contractapi.TransactionContextInterface, proposalID, reason string) error {
+	return s.rejectProposal(ctx, proposalID, "MERGE", reason)
+}
+
+// GetSubdivisionProposal returns a split or merge proposal by ID.
+func (s *LandRegistryContract) GetSubdivisionProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*SubdivisionProposal, error) {
+	return getProposal(ctx, proposalID)
+}
+
+// rejectProposal records a tehsildar's rejection of a pending proposal.
+func (s *LandRegistryContract) rejectProposal(ctx contractapi.TransactionContextInterface, proposalID, proposalType, reason string) error {
+	if reason == "" {
+		return newError(ErrCodeValidationError, "rejection reason is required")
+	}
+	proposal, err := s.pendingProposal(ctx, proposalID, proposalType)
+	if err != nil {
+		return err
+	}
+	if err := decideProposal(ctx, proposal, "REJECTED", reason); err != nil {
+		return err
+	}
+	return emitProposalEvent(ctx, proposal, proposalType+"_REJECTED", nil, nil)
+}
+
+// pendingProposal checks the caller is a tehsildar with jurisdiction over
+// the proposal's properties and returns the proposal if it is still
+// pending.
+func (s *LandRegistryContract) pendingProposal(ctx contractapi.TransactionContextInterface, proposalID, proposalType string) (*SubdivisionProposal, error) {
+	if err := requireRole(ctx, "tehsildar"); err != nil {
+		return nil, err
+	}
+
+	proposal, err := getProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Type != proposalType {
+		return nil, newError(ErrCodeProposalInvalidState, "%s is a %s proposal, not %s", proposalID, proposal.Type, proposalType)
+	}
+	// A merge may span districts; the tehsildar needs every source
+	for _, propertyID := range proposal.PropertyIDs {
+		property, err := s.GetProperty(ctx, propertyID)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireJurisdiction(ctx, property.Location); err != nil {
+			return nil, err
+		}
+	}
+	if proposal.Status != "PENDING_SANCTION" {
+		return nil, newError(ErrCodeProposalInvalidState, "expected PENDING_SANCTION, got %s", proposal.Status)
+	}
+	return proposal, nil
+}
+
+// decideProposal records the tehsildar's decision on a proposal.
+func decideProposal(ctx contractapi.TransactionContextInterface, proposal *SubdivisionProposal, status, reason string) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	proposal.Status = status
+	proposal.RejectedReason = reason
+	proposal.DecidedBy = getCallerID(ctx)
+	proposal.DecidedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	proposal.FabricTxID = ctx.GetStub().GetTxID()
+	return putProposal(ctx, proposal)
+}
+
+// emitProposalEvent emits a proposal lifecycle event.
+func emitProposalEvent(ctx contractapi.TransactionContextInterface, proposal *SubdivisionProposal, eventType string, resultIDs []string, carried []EncumbranceCarriedEvent) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := SubdivisionProposalEvent{
+		Type:                eventType,
+		ProposalID:          proposal.ProposalID,
+		ProposalType:        proposal.Type,
+		PropertyIDs:         proposal.PropertyIDs,
+		ResultPropertyIDs:   resultIDs,
+		RejectedReason:      proposal.RejectedReason,
+		EncumbrancesCarried: carried,
+		FabricTxID:          ctx.GetStub().GetTxID(),
+		Timestamp:           time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:           proposal.Location.StateCode,
+		ChannelID:           ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, eventType, event)
+}
+
+// createProposalKey creates the key for a subdivision proposal.
+func createProposalKey(ctx contractapi.TransactionContextInterface, proposalID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(KeyPrefixSubdivisionProposal, []string{proposalID})
+}
+
+// getProposal reads a subdivision proposal from world state.
+func getProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*SubdivisionProposal, error) {
+	key, err := createProposalKey(ctx, proposalID)
+	if err != nil {
+		return nil, internalError("failed to create proposal key: %v", err)
+	}
+	proposalBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read proposal: %v", err)
+	}
+	if proposalBytes == nil {
+		return nil, newError(ErrCodeProposalNotFound, "%s", proposalID)
+	}
+
+	var proposal SubdivisionProposal
+	if err := json.Unmarshal(proposalBytes, &proposal); err != nil {
+		return nil, internalError("failed to unmarshal proposal: %v", err)
+	}
+	return &proposal, nil
+}
+
+// putProposal writes a subdivision proposal to world state.
+func putProposal(ctx contractapi.TransactionContextInterface, proposal *SubdivisionProposal) error {
+	key, err := createProposalKey(ctx, proposal.ProposalID)
+	if err != nil {
+		return internalError("failed to create proposal key: %v", err)
+	}
+	proposalBytes, err := canonicalMarshal(proposal)
+	if err != nil {
+		return internalError("failed to marshal proposal: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, proposalBytes); err != nil {
+		return internalError("failed to write proposal: %v", err)
+	}
+	return nil
+}
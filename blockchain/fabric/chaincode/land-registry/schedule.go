@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// SCHEDULED TRANSFER EXECUTION
+// ============================================================
+// Parties may agree that a sale completes on a later date, such as on
+// possession handover. A registrar locks the signed transfer in with an
+// earliest execution time; ExecuteTransfer refuses it until then, and
+// the execution job finds due transfers with QueryDueScheduledTransfers.
+// Every comparison is against the transaction timestamp, never the
+// peer's clock, so endorsers agree.
+
+// ScheduleTransferExecution sets the earliest time (RFC3339, after the
+// transaction time) at which a SIGNATURES_COMPLETE transfer may be
+// executed. Calling it again reschedules the transfer. A scheduled
+// transfer is cancelled with CancelTransfer as usual. Only registrars
+// with access to the property's state can schedule transfers. Emits
+// TRANSFER_SCHEDULED.
+func (s *LandRegistryContract) ScheduleTransferExecution(ctx contractapi.TransactionContextInterface, transferID, executeAfter string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+	due, err := time.Parse(time.RFC3339, executeAfter)
+	if err != nil {
+		return newError(ErrCodeValidationError, "executeAfter must be an RFC3339 timestamp")
+	}
+
+	transferKey, err := createTransferKey(ctx, transferID)
+	if err != nil {
+		return internalError("failed to create transfer key: %v", err)
+	}
+	transferBytes, err := ctx.GetStub().GetState(transferKey)
+	if err != nil || transferBytes == nil {
+		return newError(ErrCodeTransferNotFound, "%s", transferID)
+	}
+	var transfer TransferRecord
+	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
+		return internalError("failed to unmarshal transfer: %v", err)
+	}
+	if transfer.Status != "SIGNATURES_COMPLETE" {
+		return newError(ErrCodeTransferInvalidState, "expected SIGNATURES_COMPLETE, got %s", transfer.Status)
+	}
+
+	property, err := s.GetProperty(ctx, transfer.PropertyID)
+	if err != nil {
+		return err
+	}
+	if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0)
+	if !due.After(txTime) {
+		return newError(ErrCodeValidationError, "executeAfter must be after the transaction time")
+	}
+	now := txTime.Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	if transfer.ExecuteAfter != "" {
+		if err := deleteScheduleIndex(ctx, property.Location.StateCode, &transfer); err != nil {
+			return err
+		}
+	}
+	// Stored in UTC so the schedule index sorts by time
+	transfer.ExecuteAfter = due.UTC().Format(time.RFC3339)
+	transfer.ScheduledBy = getCallerID(ctx)
+	transfer.ScheduledAt = now
+	transfer.FabricTxID = txID
+	transfer.UpdatedAt = now
+
+	transferUpdatedBytes, _ := canonicalMarshal(transfer)
+	if err := ctx.GetStub().PutState(transferKey, transferUpdatedBytes); err != nil {
+		return internalError("failed to update transfer: %v", err)
+	}
+	scheduleKey, err := createScheduleIndexKey(ctx, property.Location.StateCode, &transfer)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(scheduleKey, []byte(transferID)); err != nil {
+		return internalError("failed to write schedule index: %v", err)
+	}
+
+	event := TransferScheduledEvent{
+		Type:         "TRANSFER_SCHEDULED",
+		TransferID:   transferID,
+		PropertyID:   transfer.PropertyID,
+		ExecuteAfter: transfer.ExecuteAfter,
+		ScheduledBy:  transfer.ScheduledBy,
+		FabricTxID:   txID,
+		Timestamp:    now,
+		StateCode:    property.Location.StateCode,
+		ChannelID:    ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "TRANSFER_SCHEDULED", event)
+}
+
+// QueryDueScheduledTransfers lists a state's scheduled transfers that
+// are still SIGNATURES_COMPLETE and whose execution time has been
+// reached at the transaction time, earliest first. Only registrars and
+// admins can query them.
+func (s *LandRegistryContract) QueryDueScheduledTransfers(ctx contractapi.TransactionContextInterface, stateCode string) ([]*TransferRecord, error) {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0).UTC().Format(time.RFC3339)
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixScheduledTransfer, []string{stateCode})
+	if err != nil {
+		return nil, internalError("failed to query schedule index: %v", err)
+	}
+	defer iterator.Close()
+
+	transfers := []*TransferRecord{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate schedule index: %v", err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) != 3 {
+			continue
+		}
+		// Keys sort by execution time, so the rest are not yet due
+		if parts[1] > txTime {
+			break
+		}
+		transferKey, err := createTransferKey(ctx, parts[2])
+		if err != nil {
+			return nil, internalError("failed to create transfer key: %v", err)
+		}
+		transferBytes, err := ctx.GetStub().GetState(transferKey)
+		if err != nil {
+			return nil, internalError("failed to read transfer %s: %v", parts[2], err)
+		}
+		if transferBytes == nil {
+			continue
+		}
+		var transfer TransferRecord
+		if err := json.Unmarshal(transferBytes, &transfer); err != nil {
+			return nil, internalError("failed to unmarshal transfer: %v", err)
+		}
+		if transfer.Status == "SIGNATURES_COMPLETE" {
+			transfers = append(transfers, &transfer)
+		}
+	}
+	return transfers, nil
+}
+
+// requireTransferDue fails with TRANSFER_NOT_YET_DUE while a scheduled
+// transfer's execution time is after the transaction time.
+func requireTransferDue(ctx contractapi.TransactionContextInterface, transfer *TransferRecord) error {
+	if transfer.ExecuteAfter == "" {
+		return nil
+	}
+	due, err := time.Parse(time.RFC3339, transfer.ExecuteAfter)
+	if err != nil {
+		return internalError("transfer %s has an invalid executeAfter: %v", transfer.TransferID, err)
+	}
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	if time.Unix(timestamp.Seconds, 0).Before(due) {
+		return newError(ErrCodeTransferNotYetDue, "transfer %s is scheduled for execution after %s", transfer.TransferID, transfer.ExecuteAfter)
+	}
+	return nil
+}
+
+// createScheduleIndexKey returns a scheduled transfer's key in the
+// schedule index.
+func createScheduleIndexKey(ctx contractapi.TransactionContextInterface, stateCode string, transfer *TransferRecord) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixScheduledTransfer, []string{stateCode, transfer.ExecuteAfter, transfer.TransferID})
+	if err != nil {
+		return "", internalError("failed to create schedule index key: %v", err)
+	}
+	return key, nil
+}
+
+// deleteScheduleIndex removes a scheduled transfer from the schedule
+// index.
+func deleteScheduleIndex(ctx contractapi.TransactionContextInterface, stateCode string, transfer *TransferRecord) error {
+	key, err := createScheduleIndexKey(ctx, stateCode, transfer)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return internalError("failed to remove schedule index: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LAND RECORD SCHEMA VERSIONING
+// ============================================================
+// Records written before a field existed are stored in an older shape.
+// normalizeRecord upgrades them in memory on every read, so callers
+// always see the current shape; MigrateRecords persists the upgrade.
+//
+// Versions:
+//   0 — records written before versioning (no schemaVersion field)
+//   1 — owner KYC status, guardians, corrections, documents, tax payments
+
+// landRecordSchemaVersion is the schema version written by every write path.
+const landRecordSchemaVersion = 1
+
+// normalizeRecord upgrades a land record read from world state to the
+// current schema version in memory, filling defaults for fields that
+// did not exist when it was written. It reports whether anything changed.
+func normalizeRecord(property *LandRecord) bool {
+	if property.SchemaVersion >= landRecordSchemaVersion {
+		return false
+	}
+
+	if property.SchemaVersion < 1 {
+		if property.DocType == "" {
+			property.DocType = "landRecord"
+		}
+		if property.DisputeStatus == "" {
+			property.DisputeStatus = "CLEAR"
+		}
+		if property.EncumbranceStatus == "" {
+			property.EncumbranceStatus = "CLEAR"
+		}
+		if property.Provenance.Sequence == 0 {
+			property.Provenance.Sequence = 1
+		}
+		// Owners recorded before KYC tracking were never verified on-chain
+		for i := range property.CurrentOwner.Owners {
+			if property.CurrentOwner.Owners[i].KYCStatus == "" {
+				property.CurrentOwner.Owners[i].KYCStatus = "MIGRATED_UNVERIFIED"
+			}
+		}
+		if property.Corrections == nil {
+			property.Corrections = []CorrectionEntry{}
+		}
+		if property.Documents == nil {
+			property.Documents = []PropertyDocument{}
+		}
+		if property.TaxPayments == nil {
+			property.TaxPayments = []TaxPayment{}
+		}
+	}
+
+	property.SchemaVersion = landRecordSchemaVersion
+	return true
+}
+
+// MigrateRecords persists the current schema version for up to maxCount
+// land records in stateCode that are still stored at an older version.
+// Records are scanned in key order starting after bookmark (empty for
+// the first batch); pass the returned bookmark to continue until Done.
+// Only admins in the state can migrate its records.
+func (s *LandRegistryContract) MigrateRecords(ctx contractapi.TransactionContextInterface, stateCode string, maxCount int, bookmark string) (*MigrationResult, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+
+	if stateCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return nil, err
+	}
+	if maxCount <= 0 || maxCount > maxBulkRecords {
+		return nil, newError(ErrCodeValidationError, "maxCount must be between 1 and %d", maxBulkRecords)
+	}
+
+	// Paginated queries are not allowed in update transactions, so the
+	// bookmark is the last land key processed
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixLand, []string{})
+	if err != nil {
+		return nil, internalError("failed to iterate land records: %v", err)
+	}
+	defer iterator.Close()
+
+	result := &MigrationResult{
+		StateCode:     stateCode,
+		SchemaVersion: landRecordSchemaVersion,
+		Done:          true,
+	}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate: %v", err)
+		}
+		if bookmark != "" && kv.Key <= bookmark {
+			continue
+		}
+		if result.Migrated >= maxCount {
+			result.Done = false
+			break
+		}
+		result.Bookmark = kv.Key
+		result.Scanned++
+
+		var property LandRecord
+		if err := json.Unmarshal(kv.Value, &property); err != nil {
+			return nil, internalError("failed to unmarshal %s: %v", kv.Key, err)
+		}
+		if extractStateCode(property.PropertyID) != stateCode {
+			continue
+		}
+		if !normalizeRecord(&property) {
+			continue
+		}
+		if err := putLandRecord(ctx, &property); err != nil {
+			return nil, err
+		}
+		result.Migrated++
+	}
+	return result, nil
+}
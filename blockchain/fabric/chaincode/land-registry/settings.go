@@ -0,0 +1,565 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// REGISTRY SETTINGS
+// ============================================================
+
+// defaultRegistrySettings returns the settings used for a state that
+// has no settings document.
+func defaultRegistrySettings(stateCode string) *RegistrySettings {
+	return &RegistrySettings{
+		DocType:   "registrySettings",
+		StateCode: stateCode,
+	}
+}
+
+// SetRegistrySettings replaces a state's registry settings document.
+// settingsJSON is a RegistrySettings; fields it leaves out take their
+// defaults, and audit fields are set here. Only admins in the state can
+// change its settings. While the state's settings have
+// requireSettingsApproval set, the change is only proposed and takes
+// effect when a second admin approves it with ApproveRegistrySettings;
+// emits SETTINGS_CHANGE_PROPOSED. Otherwise see applySettings.
+func (s *LandRegistryContract) SetRegistrySettings(ctx contractapi.TransactionContextInterface, stateCode, settingsJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return newError(ErrCodeValidationError, "stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+
+	var settings RegistrySettings
+	if err := json.Unmarshal([]byte(settingsJSON), &settings); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse settings JSON: %v", err)
+	}
+	if err := validateRegistrySettings(&settings); err != nil {
+		return err
+	}
+
+	previous, err := getSettings(ctx, stateCode)
+	if err != nil {
+		return err
+	}
+	// Moving a district or state also re-keys its records' endorsement policies
+	if !sameDistrictMspIDs(previous.DistrictMspIDs, settings.DistrictMspIDs) {
+		return newError(ErrCodeValidationError, "districtMspIds can only be changed with ReassignDistrictOrg")
+	}
+	if previous.EndorsingMspID != settings.EndorsingMspID {
+		return newError(ErrCodeValidationError, "endorsingMspId can only be changed with ReassignStateOrg")
+	}
+
+	if previous.RequireSettingsApproval {
+		return proposeSettings(ctx, previous, &settings)
+	}
+	return applySettings(ctx, previous, &settings, nil)
+}
+
+// ApproveRegistrySettings applies a state's pending settings proposal.
+// The approving admin must not be the proposer, and the settings must
+// not have changed since the proposal was made. Only admins in the
+// state can approve. See applySettings for what follows.
+func (s *LandRegistryContract) ApproveRegistrySettings(ctx contractapi.TransactionContextInterface, stateCode, proposalID string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+	if stateCode == "" || proposalID == "" {
+		return newError(ErrCodeValidationError, "stateCode and proposalId are required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+
+	proposalKey, err := ctx.GetStub().CreateCompositeKey(KeyPrefixSettingsProposal, []string{stateCode})
+	if err != nil {
+		return internalError("failed to create settings proposal key: %v", err)
+	}
+	proposalBytes, err := ctx.GetStub().GetState(proposalKey)
+	if err != nil {
+		return internalError("failed to read settings proposal: %v", err)
+	}
+	var proposal SettingsProposal
+	if proposalBytes != nil {
+		if err := json.Unmarshal(proposalBytes, &proposal); err != nil {
+			return internalError("failed to unmarshal settings proposal: %v", err)
+		}
+	}
+	if proposal.ProposalID != proposalID {
+		return newError(ErrCodeSettingsProposalNotFound, "%s has no pending settings proposal %s", stateCode, proposalID)
+	}
+
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+	if fingerprint == proposal.CertFingerprint {
+		return newError(ErrCodeSelfConfirmationDenied, "the admin who proposed %s cannot also approve it", proposalID)
+	}
+	previous, err := getSettings(ctx, stateCode)
+	if err != nil {
+		return err
+	}
+	if previous.FabricTxID != proposal.BaseFabricTxID {
+		return newError(ErrCodeSettingsProposalStale, "%s settings changed after %s was proposed", stateCode, proposalID)
+	}
+
+	if err := ctx.GetStub().DelState(proposalKey); err != nil {
+		return internalError("failed to remove settings proposal: %v", err)
+	}
+	return applySettings(ctx, previous, &proposal.Settings, &proposal)
+}
+
+// GetSettingsHistory returns every applied change to a state's
+// settings, oldest first.
+func (s *LandRegistryContract) GetSettingsHistory(ctx contractapi.TransactionContextInterface, stateCode string) ([]*SettingsHistoryEntry, error) {
+	if stateCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
+	}
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixSettingsHistory, []string{stateCode})
+	if err != nil {
+		return nil, internalError("failed to query settings history: %v", err)
+	}
+	defer iterator.Close()
+
+	entries := []*SettingsHistoryEntry{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate settings history: %v", err)
+		}
+		var entry SettingsHistoryEntry
+		if err := json.Unmarshal(kv.Value, &entry); err != nil {
+			return nil, internalError("failed to unmarshal settings history: %v", err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+// validateRegistrySettings checks a settings document submitted to
+// SetRegistrySettings.
+func validateRegistrySettings(settings *RegistrySettings) error {
+	if settings.BighaSqMeters < 0 {
+		return newError(ErrCodeValidationError, "bighaSqMeters cannot be negative")
+	}
+	if settings.BoundaryOverlapThresholdSqM < 0 {
+		return newError(ErrCodeValidationError, "boundaryOverlapThresholdSqM cannot be negative")
+	}
+	for landUse, minSqM := range settings.MinPlotSizeSqM {
+		if minSqM < 0 {
+			return newError(ErrCodeValidationError, "minPlotSizeSqM for %s cannot be negative", landUse)
+		}
+	}
+	if settings.HighValueTransferThreshold < 0 {
+		return newError(ErrCodeValidationError, "highValueTransferThreshold cannot be negative")
+	}
+	if settings.CertifiedCopyFee < 0 {
+		return newError(ErrCodeValidationError, "certifiedCopyFee cannot be negative")
+	}
+	if settings.TaxArrearsAlertYears < 0 {
+		return newError(ErrCodeValidationError, "taxArrearsAlertYears cannot be negative")
+	}
+	for class, ceiling := range settings.LandCeilingSqM {
+		if ceiling < 0 {
+			return newError(ErrCodeValidationError, "landCeilingSqM for %s cannot be negative", class)
+		}
+	}
+	for class, authority := range settings.RestrictedClassifications {
+		if strings.TrimSpace(class) == "" || strings.TrimSpace(authority) == "" {
+			return newError(ErrCodeValidationError, "restrictedClassifications entries need a classification and an authority")
+		}
+	}
+	if settings.MaxSplitChildren < 0 {
+		return newError(ErrCodeValidationError, "maxSplitChildren cannot be negative")
+	}
+	if _, ok := mergeLocationLevels[normalizeMergeLocationLevel(settings.MergeLocationLevel)]; !ok {
+		return newError(ErrCodeValidationError, "mergeLocationLevel '%s' must be STATE, DISTRICT, TEHSIL or VILLAGE", settings.MergeLocationLevel)
+	}
+	if err := validateRoleHierarchy(settings.RoleHierarchy); err != nil {
+		return err
+	}
+	for _, check := range settings.DisasterRelaxations {
+		if _, ok := disasterRelaxableChecks[check]; !ok {
+			return newError(ErrCodeValidationError, "disasterRelaxations: '%s' is not a check that can be relaxed", check)
+		}
+	}
+	designations := map[string]bool{}
+	for i := range settings.GovernmentDisposalApprovers {
+		approver := &settings.GovernmentDisposalApprovers[i]
+		approver.Designation = strings.ToUpper(strings.TrimSpace(approver.Designation))
+		if approver.Designation == "" || strings.TrimSpace(approver.Role) == "" {
+			return newError(ErrCodeValidationError, "governmentDisposalApprovers[%d] needs a designation and a role", i)
+		}
+		if designations[approver.Designation] {
+			return newError(ErrCodeValidationError, "governmentDisposalApprovers lists %s twice", approver.Designation)
+		}
+		designations[approver.Designation] = true
+	}
+	for _, role := range settings.NationalWriteRoles {
+		if strings.TrimSpace(role) == "" {
+			return newError(ErrCodeValidationError, "nationalWriteRoles has an empty role")
+		}
+	}
+	if settings.RegistryDID != "" && !strings.HasPrefix(settings.RegistryDID, "did:") {
+		return newError(ErrCodeValidationError, "registryDid must be a DID (did:<method>:<id>)")
+	}
+	if settings.CoolingPeriodHours < 0 {
+		return newError(ErrCodeValidationError, "coolingPeriodHours cannot be negative")
+	}
+	for key := range settings.Extras {
+		if strings.TrimSpace(key) == "" {
+			return newError(ErrCodeValidationError, "extras has an empty key")
+		}
+	}
+	return nil
+}
+
+// proposeSettings stores a settings change for a second admin to
+// approve, replacing any proposal already pending for the state.
+func proposeSettings(ctx contractapi.TransactionContextInterface, previous, settings *RegistrySettings) error {
+	changes, err := settingsChanges(previous, settings)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return newError(ErrCodeValidationError, "the proposed settings are the same as the current ones")
+	}
+	fingerprint, err := callerFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	proposal := SettingsProposal{
+		DocType:         "settingsProposal",
+		ProposalID:      "set_" + txID[:8],
+		StateCode:       previous.StateCode,
+		Settings:        *settings,
+		Changes:         changes,
+		BaseFabricTxID:  previous.FabricTxID,
+		ProposedBy:      getCallerID(ctx),
+		CertFingerprint: fingerprint,
+		ProposedAt:      now,
+		FabricTxID:      txID,
+	}
+	proposalKey, err := ctx.GetStub().CreateCompositeKey(KeyPrefixSettingsProposal, []string{previous.StateCode})
+	if err != nil {
+		return internalError("failed to create settings proposal key: %v", err)
+	}
+	proposalBytes, err := canonicalMarshal(proposal)
+	if err != nil {
+		return internalError("failed to marshal settings proposal: %v", err)
+	}
+	if err := ctx.GetStub().PutState(proposalKey, proposalBytes); err != nil {
+		return internalError("failed to write settings proposal: %v", err)
+	}
+	if err := recordAudit(ctx, "SetRegistrySettings", previous.StateCode); err != nil {
+		return err
+	}
+
+	event := SettingsChangedEvent{
+		Type:       "SETTINGS_CHANGE_PROPOSED",
+		ProposalID: proposal.ProposalID,
+		Changes:    changes,
+		ChangedBy:  proposal.ProposedBy,
+		FabricTxID: txID,
+		Timestamp:  now,
+		StateCode:  previous.StateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "SETTINGS_CHANGE_PROPOSED", event)
+}
+
+// applySettings writes a state's new settings and an audit entry. If
+// any key changed, the change is appended to the settings history and
+// SETTINGS_CHANGED lists the changed keys with their old and new values;
+// when the role hierarchy changed, ROLE_HIERARCHY_CHANGED is emitted
+// instead, listing the same change under settingsChanges. proposal is
+// the approved proposal, or nil for a direct change.
+func applySettings(ctx contractapi.TransactionContextInterface, previous, settings *RegistrySettings, proposal *SettingsProposal) error {
+	changes, err := settingsChanges(previous, settings)
+	if err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+	stateCode := previous.StateCode
+
+	settings.DocType = "registrySettings"
+	settings.StateCode = stateCode
+	settings.UpdatedBy = getCallerID(ctx)
+	settings.UpdatedAt = now
+	settings.FabricTxID = txID
+
+	if err := putSettings(ctx, settings); err != nil {
+		return err
+	}
+	function := "SetRegistrySettings"
+	if proposal != nil {
+		function = "ApproveRegistrySettings"
+	}
+	if err := recordAudit(ctx, function, stateCode); err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	entry := SettingsHistoryEntry{
+		DocType:    "settingsHistoryEntry",
+		StateCode:  stateCode,
+		Changes:    changes,
+		ChangedBy:  settings.UpdatedBy,
+		ChangedAt:  now,
+		FabricTxID: txID,
+	}
+	if proposal != nil {
+		entry.ProposalID = proposal.ProposalID
+		entry.ChangedBy = proposal.ProposedBy
+		entry.ApprovedBy = settings.UpdatedBy
+	}
+	historyKey, err := ctx.GetStub().CreateCompositeKey(KeyPrefixSettingsHistory, []string{stateCode, now, txID})
+	if err != nil {
+		return internalError("failed to create settings history key: %v", err)
+	}
+	entryBytes, err := canonicalMarshal(entry)
+	if err != nil {
+		return internalError("failed to marshal settings history: %v", err)
+	}
+	if err := ctx.GetStub().PutState(historyKey, entryBytes); err != nil {
+		return internalError("failed to write settings history: %v", err)
+	}
+
+	event := SettingsChangedEvent{
+		Type:       "SETTINGS_CHANGED",
+		ProposalID: entry.ProposalID,
+		Changes:    changes,
+		ChangedBy:  entry.ChangedBy,
+		ApprovedBy: entry.ApprovedBy,
+		FabricTxID: txID,
+		Timestamp:  now,
+		StateCode:  stateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	if err := emitRelatedEvent(ctx, "SETTINGS_CHANGED", "settingsChanges", event, event); err != nil {
+		return err
+	}
+
+	if sameRoleHierarchy(previous.RoleHierarchy, settings.RoleHierarchy) {
+		return nil
+	}
+	hierarchyEvent := RoleHierarchyChangedEvent{
+		Type:              "ROLE_HIERARCHY_CHANGED",
+		RoleHierarchy:     settings.RoleHierarchy,
+		PreviousHierarchy: previous.RoleHierarchy,
+		ChangedBy:         settings.UpdatedBy,
+		FabricTxID:        txID,
+		Timestamp:         now,
+		StateCode:         stateCode,
+		ChannelID:         ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "ROLE_HIERARCHY_CHANGED", hierarchyEvent)
+}
+
+// settingsChanges lists the keys whose values differ between two
+// settings documents, in key order, with each value as JSON ("" for a
+// key one side leaves out). Extras are listed as "extras.{key}"; audit
+// fields are not settings and are skipped.
+func settingsChanges(previous, next *RegistrySettings) ([]SettingChange, error) {
+	flatten := func(settings *RegistrySettings) (map[string]string, error) {
+		settingsBytes, err := json.Marshal(settings)
+		if err != nil {
+			return nil, internalError("failed to marshal settings: %v", err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(settingsBytes, &fields); err != nil {
+			return nil, internalError("failed to unmarshal settings: %v", err)
+		}
+		for _, audit := range []string{"docType", "stateCode", "updatedBy", "updatedAt", "fabricTxId", "extras"} {
+			delete(fields, audit)
+		}
+		flat := make(map[string]string, len(fields)+len(settings.Extras))
+		for key, value := range fields {
+			flat[key] = string(value)
+		}
+		for key, value := range settings.Extras {
+			valueJSON, _ := json.Marshal(value)
+			flat["extras."+key] = string(valueJSON)
+		}
+		return flat, nil
+	}
+	before, err := flatten(previous)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flatten(next)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []SettingChange{}
+	for _, key := range keys {
+		if before[key] != after[key] {
+			changes = append(changes, SettingChange{Key: key, OldValue: before[key], NewValue: after[key]})
+		}
+	}
+	return changes, nil
+}
+
+// validateRoleHierarchy checks that every role and included role is
+// named, that no role includes itself, and that inclusion has no cycles.
+func validateRoleHierarchy(hierarchy map[string][]string) error {
+	for role, included := range hierarchy {
+		if strings.TrimSpace(role) == "" {
+			return newError(ErrCodeValidationError, "roleHierarchy has an empty role")
+		}
+		for _, inc := range included {
+			if strings.TrimSpace(inc) == "" {
+				return newError(ErrCodeValidationError, "roleHierarchy role %s includes an empty role", role)
+			}
+		}
+	}
+
+	// Depth-first search; a role met again on the current path is a cycle
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var visit func(role string) error
+	visit = func(role string) error {
+		switch state[role] {
+		case visiting:
+			return newError(ErrCodeValidationError, "roleHierarchy has a cycle through %s", role)
+		case done:
+			return nil
+		}
+		state[role] = visiting
+		for _, inc := range hierarchy[role] {
+			if err := visit(inc); err != nil {
+				return err
+			}
+		}
+		state[role] = done
+		return nil
+	}
+	for role := range hierarchy {
+		if err := visit(role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameRoleHierarchy reports whether two role hierarchies are identical,
+// treating nil and empty as the same.
+func sameRoleHierarchy(a, b map[string][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for role, included := range a {
+		other, ok := b[role]
+		if !ok || len(other) != len(included) {
+			return false
+		}
+		for i := range included {
+			if included[i] != other[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GetRegistrySettings returns a state's registry settings, or the
+// defaults if none have been set.
+func (s *LandRegistryContract) GetRegistrySettings(ctx contractapi.TransactionContextInterface, stateCode string) (*RegistrySettings, error) {
+	if stateCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
+	}
+	return getSettings(ctx, stateCode)
+}
+
+// putSettings writes a state's registry settings document.
+func putSettings(ctx contractapi.TransactionContextInterface, settings *RegistrySettings) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixSettings, []string{settings.StateCode})
+	if err != nil {
+		return internalError("failed to create settings key: %v", err)
+	}
+	settingsBytes, err := canonicalMarshal(settings)
+	if err != nil {
+		return internalError("failed to marshal settings: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, settingsBytes); err != nil {
+		return internalError("failed to write settings: %v", err)
+	}
+	return nil
+}
+
+// sameDistrictMspIDs reports whether two district-to-MSP tables are
+// identical, treating nil and empty as the same.
+func sameDistrictMspIDs(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for district, mspID := range a {
+		if b[district] != mspID {
+			return false
+		}
+	}
+	return true
+}
+
+// getSettings loads a state's registry settings, falling back to
+// defaultRegistrySettings when no document exists. Every setting's zero
+// value is its default, so a key a document leaves out behaves as if no
+// document existed. Behaviors configured per state read their settings
+// only through here.
+func getSettings(ctx contractapi.TransactionContextInterface, stateCode string) (*RegistrySettings, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixSettings, []string{stateCode})
+	if err != nil {
+		return nil, internalError("failed to create settings key: %v", err)
+	}
+	settingsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read settings: %v", err)
+	}
+	if settingsBytes == nil {
+		return defaultRegistrySettings(stateCode), nil
+	}
+
+	var settings RegistrySettings
+	if err := json.Unmarshal(settingsBytes, &settings); err != nil {
+		return nil, internalError("failed to unmarshal settings: %v", err)
+	}
+	return &settings, nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// setSettings calls SetRegistrySettings for Telangana as admin.
+func (l *testLedger) setSettings(admin *testIdentity, settingsJSON string) error {
+	return l.submit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		return l.contract.SetRegistrySettings(ctx, "TS", settingsJSON)
+	})
+}
+
+// readSettings returns GetRegistrySettings for stateCode.
+func (l *testLedger) readSettings(stateCode string) *RegistrySettings {
+	l.t.Helper()
+	var settings *RegistrySettings
+	l.mustSubmit(newTestIdentity(l.t, "AdminOrgMSP", "admin", stateCode), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		settings, err = l.contract.GetRegistrySettings(ctx, stateCode)
+		return err
+	})
+	return settings
+}
+
+// readSettingsHistory returns GetSettingsHistory for stateCode.
+func (l *testLedger) readSettingsHistory(stateCode string) []*SettingsHistoryEntry {
+	l.t.Helper()
+	var history []*SettingsHistoryEntry
+	l.mustSubmit(newTestIdentity(l.t, "AdminOrgMSP", "admin", stateCode), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		history, err = l.contract.GetSettingsHistory(ctx, stateCode)
+		return err
+	})
+	return history
+}
+
+// coolingExpiryAfterSale executes a sale on a ledger prepared by setup
+// and returns when the property's cooling period expires.
+func coolingExpiryAfterSale(t *testing.T, setup func(ledger *testLedger)) string {
+	t.Helper()
+	ledger, registrar, transferID, signers := signingTestTransfer(t)
+	setup(ledger)
+	ledger.signTestTransfer(registrar, transferID, signers...)
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer: %v", err)
+	}
+	return ledger.readProperty(ledger.readTransfer(transferID).PropertyID).CoolingPeriod.ExpiresAt
+}
+
+func TestSettingsDefaultWithoutDocument(t *testing.T) {
+	ledger := newTestLedger(t)
+	if got, want := ledger.readSettings("TS"), defaultRegistrySettings("TS"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("settings = %+v, want the defaults %+v", got, want)
+	}
+	if got := coolingExpiryAfterSale(t, func(*testLedger) {}); got != "2027-03-18T10:30:00Z" {
+		t.Fatalf("default cooling period expires %s, want 72 hours on", got)
+	}
+	// National courts and admins may write to the state's records
+	err := ledger.submit(newTestIdentity(t, "SupremeCourtMSP", "court", NationalStateCode), func(ctx contractapi.TransactionContextInterface) error {
+		return requireStateAccess(ctx, "TS")
+	})
+	if err != nil {
+		t.Fatalf("national court under default settings: %v", err)
+	}
+}
+
+func TestSettingsExplicitDocument(t *testing.T) {
+	ledger := newTestLedger(t)
+	admin := newTestIdentity(t, "AdminOrgMSP", "admin", "TS")
+	settingsJSON := `{"coolingPeriodHours":24,"requireTaxClearanceForTransfer":true,"highValueTransferThreshold":1000000000,` +
+		`"nationalWriteRoles":["court"],"mergeLocationLevel":"TEHSIL","extras":{"portalTheme":"dark"}}`
+	if err := ledger.setSettings(admin, settingsJSON); err != nil {
+		t.Fatalf("SetRegistrySettings: %v", err)
+	}
+
+	settings := ledger.readSettings("TS")
+	if settings.CoolingPeriodHours != 24 || !settings.RequireTaxClearanceForTransfer || settings.HighValueTransferThreshold != 1000000000 ||
+		!reflect.DeepEqual(settings.NationalWriteRoles, []string{"court"}) || settings.MergeLocationLevel != "TEHSIL" ||
+		settings.Extras["portalTheme"] != "dark" {
+		t.Fatalf("settings = %+v", settings)
+	}
+	if settings.DocType != "registrySettings" || settings.StateCode != "TS" || settings.UpdatedBy != ledger.callerIDOf(admin) || settings.FabricTxID == "" {
+		t.Fatalf("audit fields = %s %s %s %s", settings.DocType, settings.StateCode, settings.UpdatedBy, settings.FabricTxID)
+	}
+	// Settings are per state
+	if got := ledger.readSettings("KA"); !reflect.DeepEqual(got, defaultRegistrySettings("KA")) {
+		t.Fatalf("KA settings = %+v, want the defaults", got)
+	}
+
+	got := coolingExpiryAfterSale(t, func(ledger *testLedger) {
+		ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", CoolingPeriodHours: 24})
+	})
+	if got != "2027-03-16T10:30:00Z" {
+		t.Fatalf("cooling period expires %s, want 24 hours on", got)
+	}
+}
+
+func TestSettingsPartialDocument(t *testing.T) {
+	ledger := newTestLedger(t)
+	admin := newTestIdentity(t, "AdminOrgMSP", "admin", "TS")
+	if err := ledger.setSettings(admin, `{"highValueTransferThreshold":1000000000}`); err != nil {
+		t.Fatalf("SetRegistrySettings: %v", err)
+	}
+
+	// Only the key given changed; the rest keep their defaults
+	want := []SettingChange{{Key: "highValueTransferThreshold", OldValue: "0", NewValue: "1000000000"}}
+	if history := ledger.readSettingsHistory("TS"); len(history) != 1 || !reflect.DeepEqual(history[0].Changes, want) {
+		t.Fatalf("history = %+v, want one entry with %+v", history, want)
+	}
+	settings := ledger.readSettings("TS")
+	if settings.NationalWriteRoles != nil || settings.CoolingPeriodHours != 0 {
+		t.Fatalf("settings = %+v", settings)
+	}
+	err := ledger.submit(newTestIdentity(t, "SupremeCourtMSP", "court", NationalStateCode), func(ctx contractapi.TransactionContextInterface) error {
+		return requireStateAccess(ctx, "TS")
+	})
+	if err != nil {
+		t.Fatalf("national court under partial settings: %v", err)
+	}
+
+	// A stored document missing keys, as an older chaincode wrote it
+	got := coolingExpiryAfterSale(t, func(ledger *testLedger) {
+		ledger.mustSubmit(admin, func(ctx contractapi.TransactionContextInterface) error {
+			key, _ := ctx.GetStub().CreateCompositeKey(KeyPrefixSettings, []string{"TS"})
+			return ctx.GetStub().PutState(key, []byte(`{"docType":"registrySettings","stateCode":"TS","bighaSqMeters":2529.29}`))
+		})
+	})
+	if got != "2027-03-18T10:30:00Z" {
+		t.Fatalf("cooling period expires %s, want the 72-hour default", got)
+	}
+}
+
+func TestSettingsChangesAreRecorded(t *testing.T) {
+	ledger := newTestLedger(t)
+	admin := newTestIdentity(t, "AdminOrgMSP", "admin", "TS")
+	if err := ledger.setSettings(admin, `{"coolingPeriodHours":24,"extras":{"portalTheme":"dark"}}`); err != nil {
+		t.Fatalf("SetRegistrySettings: %v", err)
+	}
+	if err := ledger.setSettings(admin, `{"coolingPeriodHours":48,"extras":{"portalTheme":"light"}}`); err != nil {
+		t.Fatalf("SetRegistrySettings: %v", err)
+	}
+	var changes []SettingChange
+	if err := json.Unmarshal(ledger.eventFields("SETTINGS_CHANGED")["changes"], &changes); err != nil {
+		t.Fatalf("SETTINGS_CHANGED changes: %v", err)
+	}
+	want := []SettingChange{
+		{Key: "coolingPeriodHours", OldValue: "24", NewValue: "48"},
+		{Key: "extras.portalTheme", OldValue: `"dark"`, NewValue: `"light"`},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("SETTINGS_CHANGED changes = %+v, want %+v", changes, want)
+	}
+
+	// Resubmitting the same settings records nothing
+	if err := ledger.setSettings(admin, `{"coolingPeriodHours":48,"extras":{"portalTheme":"light"}}`); err != nil {
+		t.Fatalf("SetRegistrySettings: %v", err)
+	}
+	if ledger.hasEvent("SETTINGS_CHANGED") {
+		t.Fatal("unchanged settings emitted SETTINGS_CHANGED")
+	}
+	history := ledger.readSettingsHistory("TS")
+	if len(history) != 2 || !reflect.DeepEqual(history[1].Changes, want) || history[1].ChangedBy != ledger.callerIDOf(admin) {
+		t.Fatalf("history = %+v", history)
+	}
+
+	expectCode(t, ledger.setSettings(admin, `{"coolingPeriodHours":-1}`), ErrCodeValidationError)
+	expectCode(t, ledger.setSettings(admin, `{"extras":{" ":"x"}}`), ErrCodeValidationError)
+}
+
+func TestSettingsMakerChecker(t *testing.T) {
+	ledger := newTestLedger(t)
+	maker := newTestIdentity(t, "AdminOrgMSP", "admin", "TS")
+	checker := newTestIdentity(t, "AdminOrgMSP", "admin", "TS")
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", RequireSettingsApproval: true})
+
+	propose := func(settingsJSON string) string {
+		if err := ledger.setSettings(maker, settingsJSON); err != nil {
+			t.Fatalf("SetRegistrySettings: %v", err)
+		}
+		proposalID := stringField(t, ledger.eventFields("SETTINGS_CHANGE_PROPOSED"), "proposalId")
+		if proposalID == "" {
+			t.Fatal("SetRegistrySettings did not emit SETTINGS_CHANGE_PROPOSED")
+		}
+		return proposalID
+	}
+	approve := func(id *testIdentity, proposalID string) error {
+		return ledger.submit(id, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.ApproveRegistrySettings(ctx, "TS", proposalID)
+		})
+	}
+
+	proposalID := propose(`{"requireSettingsApproval":true,"coolingPeriodHours":24}`)
+	if got := ledger.readSettings("TS").CoolingPeriodHours; got != 0 {
+		t.Fatalf("a proposal took effect: coolingPeriodHours = %d", got)
+	}
+	expectCode(t, approve(maker, proposalID), ErrCodeSelfConfirmationDenied)
+	expectCode(t, approve(checker, "set_missing"), ErrCodeSettingsProposalNotFound)
+	if err := approve(checker, proposalID); err != nil {
+		t.Fatalf("ApproveRegistrySettings: %v", err)
+	}
+	if got := ledger.readSettings("TS").CoolingPeriodHours; got != 24 {
+		t.Fatalf("coolingPeriodHours = %d after approval, want 24", got)
+	}
+	history := ledger.readSettingsHistory("TS")
+	if len(history) != 1 || history[0].ProposalID != proposalID ||
+		history[0].ChangedBy != ledger.callerIDOf(maker) || history[0].ApprovedBy != ledger.callerIDOf(checker) {
+		t.Fatalf("history = %+v", history)
+	}
+	expectCode(t, approve(checker, proposalID), ErrCodeSettingsProposalNotFound)
+
+	// A proposal made against settings that have since changed is stale
+	stale := propose(`{"requireSettingsApproval":true,"coolingPeriodHours":48}`)
+	ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", RequireSettingsApproval: true, FabricTxID: "changed"})
+	expectCode(t, approve(checker, stale), ErrCodeSettingsProposalStale)
+}
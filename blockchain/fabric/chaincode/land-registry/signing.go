@@ -0,0 +1,518 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// PARTY AND WITNESS SIGNATURES
+// ============================================================
+// A witness's "signed" flag in the transfer JSON is only the caller's
+// word, so InitiateTransfer clears it on every witness. Citizens with an
+// eSign or DSC public key registered here sign the transfer digest
+// themselves; SignTransfer verifies the signature against the registered
+// key before recording it, and the transfer reaches SIGNATURES_COMPLETE
+// only on verified signatures. AddWitnessSignature and
+// RecordPartySignature do the same for witnesses and for the parties
+// alone, and keep a hash of the signing evidence with the signature.
+//
+// The digest is SHA-256 over the UTF-8 string
+//
+//	BHULEKH-TRANSFER-V1|{transferId}|{propertyId}|{seller.aadhaarHash}|{buyer.aadhaarHash}|{declaredValue}
+//
+// with declaredValue in paisa as a base-10 integer. Clients sign that
+// string with SHA256withRSA (PKCS #1 v1.5) or SHA256withECDSA (ASN.1 DER
+// signature), and submit the signature base64-encoded. Test vectors are
+// in docs/SMART_CONTRACTS.md and signing_test.go.
+
+// transferDigestPrefix versions the transfer digest format.
+const transferDigestPrefix = "BHULEKH-TRANSFER-V1"
+
+// minRSAKeyBits is the smallest RSA signing key accepted.
+const minRSAKeyBits = 2048
+
+// RegisterSigningKey records a citizen's signing public key, at a
+// registrar-assisted enrollment. publicKeyPEM is a PUBLIC KEY block or
+// the citizen's DSC as a CERTIFICATE block; keyType is RSA or ECDSA and
+// must match the key. A citizen with an active key must use
+// RotateSigningKey instead. Only registrars and admins can register keys.
+// Writes an audit entry.
+func (s *LandRegistryContract) RegisterSigningKey(ctx contractapi.TransactionContextInterface, aadhaarHash, publicKeyPEM, keyType string) error {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return err
+	}
+	if err := validateAadhaarHash(aadhaarHash, "aadhaarHash"); err != nil {
+		return err
+	}
+	keyID, err := signingKeyID(publicKeyPEM, keyType)
+	if err != nil {
+		return err
+	}
+
+	existing, err := getSigningKey(ctx, aadhaarHash)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Status == "ACTIVE" {
+		return newError(ErrCodeSigningKeyExists, "%s already has an active key; use RotateSigningKey", aadhaarHash)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	key := &SigningKey{
+		DocType:      "signingKey",
+		AadhaarHash:  aadhaarHash,
+		KeyID:        keyID,
+		KeyType:      keyType,
+		PublicKeyPEM: publicKeyPEM,
+		Status:       "ACTIVE",
+		RegisteredBy: getCallerID(ctx),
+		RegisteredAt: now,
+		FabricTxID:   ctx.GetStub().GetTxID(),
+	}
+	if existing != nil {
+		key.PreviousKeyIDs = append(existing.PreviousKeyIDs, existing.KeyID)
+	}
+	if err := putSigningKey(ctx, key); err != nil {
+		return err
+	}
+	return recordAudit(ctx, "RegisterSigningKey", aadhaarHash)
+}
+
+// RotateSigningKey replaces a citizen's active signing key, e.g. when
+// their DSC is renewed. Signatures already recorded stay valid; they
+// name the key that verified them. Only registrars and admins can rotate
+// keys. Writes an audit entry.
+func (s *LandRegistryContract) RotateSigningKey(ctx contractapi.TransactionContextInterface, aadhaarHash, publicKeyPEM, keyType string) error {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return err
+	}
+	keyID, err := signingKeyID(publicKeyPEM, keyType)
+	if err != nil {
+		return err
+	}
+
+	key, err := getSigningKey(ctx, aadhaarHash)
+	if err != nil {
+		return err
+	}
+	if key == nil || key.Status != "ACTIVE" {
+		return newError(ErrCodeSigningKeyNotFound, "%s has no active key", aadhaarHash)
+	}
+	if key.KeyID == keyID {
+		return newError(ErrCodeValidationError, "the new key is the current key")
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	key.PreviousKeyIDs = append(key.PreviousKeyIDs, key.KeyID)
+	key.KeyID = keyID
+	key.KeyType = keyType
+	key.PublicKeyPEM = publicKeyPEM
+	key.RegisteredBy = getCallerID(ctx)
+	key.RegisteredAt = now
+	key.FabricTxID = ctx.GetStub().GetTxID()
+
+	if err := putSigningKey(ctx, key); err != nil {
+		return err
+	}
+	return recordAudit(ctx, "RotateSigningKey", aadhaarHash)
+}
+
+// RevokeSigningKey withdraws a citizen's signing key, e.g. when it is
+// reported compromised. No further signatures are accepted until a new
+// key is registered. Only registrars and admins can revoke keys. Writes
+// an audit entry.
+func (s *LandRegistryContract) RevokeSigningKey(ctx contractapi.TransactionContextInterface, aadhaarHash, reason string) error {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required to revoke a signing key")
+	}
+
+	key, err := getSigningKey(ctx, aadhaarHash)
+	if err != nil {
+		return err
+	}
+	if key == nil || key.Status != "ACTIVE" {
+		return newError(ErrCodeSigningKeyNotFound, "%s has no active key", aadhaarHash)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	key.Status = "REVOKED"
+	key.RevokedBy = getCallerID(ctx)
+	key.RevokedAt = now
+	key.RevokeReason = reason
+	key.FabricTxID = ctx.GetStub().GetTxID()
+
+	if err := putSigningKey(ctx, key); err != nil {
+		return err
+	}
+	return recordAudit(ctx, "RevokeSigningKey", aadhaarHash)
+}
+
+// SignTransfer records the seller's, buyer's or a witness's signature
+// on a transfer. signatureB64 is the base64 signature over the transfer
+// digest, verified against the signer's active registered key. Once the
+// seller, buyer and two witnesses have signed (parties and witnesses are
+// excused when a party is GOVERNMENT, as in Rule 7) the transfer moves to
+// SIGNATURES_COMPLETE and TRANSFER_SIGNATURES_COMPLETE is emitted. The
+// signer may submit their own signature; otherwise registrars submit it.
+// With poaID, signerAadhaarHash is an attorney signing with their own
+// key for the seller or buyer who granted that POA (see requireValidPOA);
+// empty poaID means the signer signs for themselves.
+func (s *LandRegistryContract) SignTransfer(ctx contractapi.TransactionContextInterface, transferID, signerAadhaarHash, signatureB64, poaID string) error {
+	return signTransfer(ctx, transferID, signerAadhaarHash, signatureB64, poaID, "", "")
+}
+
+// AddWitnessSignature records a witness's signature on a transfer, as
+// SignTransfer does, together with evidenceHash: the document hash of
+// the signing evidence, such as the eSign response or the scanned
+// signed page. The signature must verify against the witness's
+// registered key; a seller or buyer cannot sign here.
+func (s *LandRegistryContract) AddWitnessSignature(ctx contractapi.TransactionContextInterface, transferID, witnessAadhaarHash, signatureB64, evidenceHash string) error {
+	if err := validateDocumentHash(evidenceHash, "evidenceHash"); err != nil {
+		return err
+	}
+	return signTransfer(ctx, transferID, witnessAadhaarHash, signatureB64, "", evidenceHash, "WITNESS")
+}
+
+// RecordPartySignature records the seller's or buyer's signature on a
+// transfer, as SignTransfer does, together with evidenceHash as for
+// AddWitnessSignature. With poaID an attorney signs for the party. The
+// signature must verify against the signer's registered key; a witness
+// cannot sign here.
+func (s *LandRegistryContract) RecordPartySignature(ctx contractapi.TransactionContextInterface, transferID, partyAadhaarHash, signatureB64, evidenceHash, poaID string) error {
+	if err := validateDocumentHash(evidenceHash, "evidenceHash"); err != nil {
+		return err
+	}
+	return signTransfer(ctx, transferID, partyAadhaarHash, signatureB64, poaID, evidenceHash, "PARTY")
+}
+
+// signTransfer verifies and records a signature on a transfer for
+// SignTransfer, AddWitnessSignature and RecordPartySignature. capacity
+// limits the signer to a WITNESS or a PARTY (seller or buyer); empty
+// allows either. evidenceHash, if any, is kept with the signature.
+func signTransfer(ctx contractapi.TransactionContextInterface, transferID, signerAadhaarHash, signatureB64, poaID, evidenceHash, capacity string) error {
+	if err := requireSelfOrRole(ctx, []string{signerAadhaarHash}, "registrar"); err != nil {
+		return err
+	}
+
+	transferKey, err := createTransferKey(ctx, transferID)
+	if err != nil {
+		return internalError("failed to create transfer key: %v", err)
+	}
+	transferBytes, err := ctx.GetStub().GetState(transferKey)
+	if err != nil || transferBytes == nil {
+		return newError(ErrCodeTransferNotFound, "%s", transferID)
+	}
+	var transfer TransferRecord
+	if err := json.Unmarshal(transferBytes, &transfer); err != nil {
+		return internalError("failed to unmarshal transfer: %v", err)
+	}
+	if transfer.Status != "INITIATED" && transfer.Status != "SIGNATURES_PENDING" {
+		return newError(ErrCodeTransferInvalidState, "cannot sign transfer with status %s", transfer.Status)
+	}
+
+	// An attorney signs as their principal
+	partyHash := signerAadhaarHash
+	var poa *POARecord
+	if poaID != "" {
+		poa, err = getPOA(ctx, poaID)
+		if err != nil {
+			return err
+		}
+		if poa.PrincipalHash != transfer.Seller.AadhaarHash && poa.PrincipalHash != transfer.Buyer.AadhaarHash {
+			return newError(ErrCodePoaInvalid, "POA %s was not granted by a party to transfer %s", poaID, transferID).with("poaId", poaID)
+		}
+		if _, err := requireValidPOA(ctx, poaID, poa.PrincipalHash, signerAadhaarHash, transfer.PropertyID); err != nil {
+			return err
+		}
+		partyHash = poa.PrincipalHash
+	}
+
+	signerRole := ""
+	witnessIndex := -1
+	switch partyHash {
+	case "":
+	case transfer.Seller.AadhaarHash:
+		signerRole = "SELLER"
+	case transfer.Buyer.AadhaarHash:
+		signerRole = "BUYER"
+	default:
+		for i, w := range transfer.Witnesses {
+			if w.AadhaarHash == signerAadhaarHash {
+				signerRole = "WITNESS"
+				witnessIndex = i
+				break
+			}
+		}
+	}
+	if signerRole == "" {
+		return newError(ErrCodeSignerNotParty, "%s is not a party or witness to transfer %s", signerAadhaarHash, transferID)
+	}
+	switch {
+	case capacity == "WITNESS" && signerRole != "WITNESS":
+		return newError(ErrCodeSignerNotParty, "%s is not a witness to transfer %s", signerAadhaarHash, transferID)
+	case capacity == "PARTY" && signerRole == "WITNESS":
+		return newError(ErrCodeSignerNotParty, "%s is not the seller or buyer in transfer %s", signerAadhaarHash, transferID)
+	}
+	for _, sig := range transfer.Signatures {
+		if sig.AadhaarHash == partyHash {
+			return newError(ErrCodeAlreadySigned, "%s has already signed transfer %s", partyHash, transferID)
+		}
+	}
+
+	key, err := getSigningKey(ctx, signerAadhaarHash)
+	if err != nil {
+		return err
+	}
+	if key == nil || key.Status != "ACTIVE" {
+		return newError(ErrCodeSigningKeyNotFound, "%s has no active signing key", signerAadhaarHash)
+	}
+	signature, err := base64.StdEncoding.DecodeString(signatureB64)
+	if err != nil {
+		return newError(ErrCodeValidationError, "signature must be base64: %v", err)
+	}
+	if err := verifyTransferSignature(key, transferDigest(&transfer), signature); err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	signed := TransferSignature{
+		AadhaarHash:  partyHash,
+		Role:         signerRole,
+		KeyID:        key.KeyID,
+		Signature:    signatureB64,
+		SignedAt:     now,
+		RecordedBy:   getCallerID(ctx),
+		EvidenceHash: evidenceHash,
+	}
+	if poa != nil {
+		signed.AttorneyHash = signerAadhaarHash
+		signed.POAID = poa.POAID
+	}
+	transfer.Signatures = append(transfer.Signatures, signed)
+	if witnessIndex >= 0 {
+		transfer.Witnesses[witnessIndex].Signed = true
+	}
+
+	status := "SIGNATURES_PENDING"
+	if signaturesComplete(&transfer) {
+		status = "SIGNATURES_COMPLETE"
+	}
+	if status != transfer.Status {
+		transfer.Status = status
+		transfer.StatusHistory = append(transfer.StatusHistory, StatusEntry{
+			Status: status,
+			At:     now,
+			By:     getCallerID(ctx),
+		})
+	}
+	transfer.UpdatedAt = now
+	transfer.FabricTxID = txID
+
+	updatedBytes, err := canonicalMarshal(transfer)
+	if err != nil {
+		return internalError("failed to marshal transfer: %v", err)
+	}
+	if err := ctx.GetStub().PutState(transferKey, updatedBytes); err != nil {
+		return internalError("failed to put transfer state: %v", err)
+	}
+
+	if status != "SIGNATURES_COMPLETE" {
+		return nil
+	}
+
+	// The signer need not be able to read the property, so its owners
+	// are read from state directly.
+	property, err := readLandRecord(ctx, transfer.PropertyID)
+	if err != nil {
+		return err
+	}
+
+	event := TransferEvent{
+		Type:              "TRANSFER_SIGNATURES_COMPLETE",
+		TransferID:        transfer.TransferID,
+		PropertyID:        transfer.PropertyID,
+		PreviousOwnerHash: transfer.Seller.AadhaarHash,
+		NewOwnerHash:      transfer.Buyer.AadhaarHash,
+		FabricTxID:        txID,
+		Timestamp:         now,
+		StateCode:         extractStateCode(transfer.PropertyID),
+		ChannelID:         ctx.GetStub().GetChannelID(),
+		PreviousOwners:    ownerShares(property.CurrentOwner.Owners),
+		NewOwners:         transferBuyerShares(&transfer),
+	}
+	return emitEvent(ctx, "TRANSFER_SIGNATURES_COMPLETE", event)
+}
+
+// transferDigest returns the SHA-256 digest parties sign for a transfer.
+// See the file comment for the format.
+func transferDigest(transfer *TransferRecord) []byte {
+	message := transferDigestPrefix + "|" + transfer.TransferID + "|" + transfer.PropertyID + "|" +
+		transfer.Seller.AadhaarHash + "|" + transfer.Buyer.AadhaarHash + "|" +
+		strconv.FormatInt(transfer.TransactionDetails.DeclaredValue, 10)
+	sum := sha256.Sum256([]byte(message))
+	return sum[:]
+}
+
+// verifyTransferSignature checks signature over digest with key.
+func verifyTransferSignature(key *SigningKey, digest, signature []byte) error {
+	publicKey, err := parseSigningKey(key.PublicKeyPEM, key.KeyType)
+	if err != nil {
+		return err
+	}
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature); err != nil {
+			return newError(ErrCodeSignatureInvalid, "signature does not verify against key %s", key.KeyID)
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return newError(ErrCodeSignatureInvalid, "signature does not verify against key %s", key.KeyID)
+		}
+	}
+	return nil
+}
+
+// signaturesComplete reports whether a transfer has every signature
+// Rule 7 needs: seller, buyer and two witnesses, none of them required
+// when a party is GOVERNMENT.
+func signaturesComplete(transfer *TransferRecord) bool {
+	if transfer.Seller.OwnerType == "GOVERNMENT" || transfer.Buyer.OwnerType == "GOVERNMENT" {
+		return true
+	}
+	seller, buyer, witnesses := false, false, 0
+	for _, sig := range transfer.Signatures {
+		switch sig.Role {
+		case "SELLER":
+			seller = true
+		case "BUYER":
+			buyer = true
+		case "WITNESS":
+			witnesses++
+		}
+	}
+	return seller && buyer && witnesses >= 2
+}
+
+// parseSigningKey decodes a PEM public key or certificate and checks
+// that it is a usable key of keyType: RSA of at least minRSAKeyBits, or
+// ECDSA on P-256 or P-384.
+func parseSigningKey(publicKeyPEM, keyType string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, newError(ErrCodeValidationError, "publicKeyPem is not PEM encoded")
+	}
+	var publicKey crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, newError(ErrCodeValidationError, "failed to parse public key: %v", err)
+		}
+		publicKey = key
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, newError(ErrCodeValidationError, "failed to parse certificate: %v", err)
+		}
+		publicKey = cert.PublicKey
+	default:
+		return nil, newError(ErrCodeValidationError, "PEM block must be PUBLIC KEY or CERTIFICATE, got %s", block.Type)
+	}
+
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		if keyType != "RSA" {
+			return nil, newError(ErrCodeValidationError, "keyType %s does not match an RSA key", keyType)
+		}
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, newError(ErrCodeValidationError, "RSA key must be at least %d bits", minRSAKeyBits)
+		}
+	case *ecdsa.PublicKey:
+		if keyType != "ECDSA" {
+			return nil, newError(ErrCodeValidationError, "keyType %s does not match an ECDSA key", keyType)
+		}
+		if pub.Curve != elliptic.P256() && pub.Curve != elliptic.P384() {
+			return nil, newError(ErrCodeValidationError, "ECDSA key must be on P-256 or P-384")
+		}
+	default:
+		return nil, newError(ErrCodeValidationError, "keyType must be RSA or ECDSA")
+	}
+	return publicKey, nil
+}
+
+// signingKeyID validates a signing key and returns its ID, the hex
+// SHA-256 of its DER SubjectPublicKeyInfo.
+func signingKeyID(publicKeyPEM, keyType string) (string, error) {
+	publicKey, err := parseSigningKey(publicKeyPEM, keyType)
+	if err != nil {
+		return "", err
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", internalError("failed to marshal public key: %v", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// getSigningKey loads a citizen's signing key record, or nil if none
+// was ever registered.
+func getSigningKey(ctx contractapi.TransactionContextInterface, aadhaarHash string) (*SigningKey, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixSigningKey, []string{aadhaarHash})
+	if err != nil {
+		return nil, internalError("failed to create signing key key: %v", err)
+	}
+	keyBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read signing key: %v", err)
+	}
+	if keyBytes == nil {
+		return nil, nil
+	}
+	var signingKey SigningKey
+	if err := json.Unmarshal(keyBytes, &signingKey); err != nil {
+		return nil, internalError("failed to unmarshal signing key: %v", err)
+	}
+	return &signingKey, nil
+}
+
+// putSigningKey writes a citizen's signing key record.
+func putSigningKey(ctx contractapi.TransactionContextInterface, signingKey *SigningKey) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixSigningKey, []string{signingKey.AadhaarHash})
+	if err != nil {
+		return internalError("failed to create signing key key: %v", err)
+	}
+	keyBytes, err := canonicalMarshal(signingKey)
+	if err != nil {
+		return internalError("failed to marshal signing key: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, keyBytes); err != nil {
+		return internalError("failed to write signing key: %v", err)
+	}
+	return nil
+}
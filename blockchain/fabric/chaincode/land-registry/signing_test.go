@@ -0,0 +1,368 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// The digest and signature vectors below are published in
+// docs/SMART_CONTRACTS.md for client implementations; keep them in step.
+
+// digestVectors are transfers with the message and SHA-256 digest they sign.
+var digestVectors = []struct {
+	transfer TransferRecord
+	message  string
+	digest   string
+}{
+	{
+		TransferRecord{TransferID: "xfr_1a2b3c4d", PropertyID: "AP-GNT-TNL-SKM-142-3",
+			Seller:             PartyInfo{AadhaarHash: strings.Repeat("a", 64)},
+			Buyer:              PartyInfo{AadhaarHash: strings.Repeat("b", 64)},
+			TransactionDetails: TransactionDetails{DeclaredValue: 250000000}},
+		"BHULEKH-TRANSFER-V1|xfr_1a2b3c4d|AP-GNT-TNL-SKM-142-3|aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa|bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb|250000000",
+		"bf05df555d0e82856ac6663eb1bc2320ce905a039152cc186a30aa0d8b4c1489",
+	},
+	{
+		TransferRecord{TransferID: "xfr_00000000", PropertyID: "MH-PUN-HVL-WGL-7-0",
+			Seller: PartyInfo{AadhaarHash: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+			Buyer:  PartyInfo{AadhaarHash: "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"}},
+		"BHULEKH-TRANSFER-V1|xfr_00000000|MH-PUN-HVL-WGL-7-0|0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef|fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210|0",
+		"38ff29ac792e8d189a7edaa15028c1287d5c6f946ad2e0e6bb6b2bd36108830e",
+	},
+}
+
+// vectorPublicKeyPEM is the P-256 key whose private scalar is
+// SHA-256("bhulekh test signer"), and vectorSignature its signature over
+// the first digest vector.
+const (
+	vectorPublicKeyPEM = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEtnelk24EpOrqucr7oHLUMrxqGtyH
+CV1GxwZ4nFb8tP2qgyumnwd8P4G5yHIjshB8oz+erEeJcWgo+aLHygDAXw==
+-----END PUBLIC KEY-----
+`
+	vectorSignature = "MEUCIC/mKj+clr3DrcKLuaiZpggxho/2Hm8eamhiOqAGgX4nAiEAjF/qux20pycv5Gv9/rTt9fF62t/v681GYnyKJYXwJsM="
+)
+
+func TestTransferDigestVectors(t *testing.T) {
+	for _, v := range digestVectors {
+		if sum := sha256.Sum256([]byte(v.message)); hex.EncodeToString(sum[:]) != v.digest {
+			t.Errorf("SHA-256 of %q = %x, want %s", v.message, sum, v.digest)
+		}
+		if got := hex.EncodeToString(transferDigest(&v.transfer)); got != v.digest {
+			t.Errorf("transferDigest(%s) = %s, want %s", v.transfer.TransferID, got, v.digest)
+		}
+	}
+}
+
+func TestTransferSignatureVector(t *testing.T) {
+	key := &SigningKey{KeyID: "vector", KeyType: "ECDSA", PublicKeyPEM: vectorPublicKeyPEM}
+	signature, err := base64.StdEncoding.DecodeString(vectorSignature)
+	if err != nil {
+		t.Fatalf("decode vector signature: %v", err)
+	}
+	if err := verifyTransferSignature(key, transferDigest(&digestVectors[0].transfer), signature); err != nil {
+		t.Fatalf("vector signature does not verify: %v", err)
+	}
+	// The same signature does not cover the other transfer
+	err = verifyTransferSignature(key, transferDigest(&digestVectors[1].transfer), signature)
+	expectCode(t, err, ErrCodeSignatureInvalid)
+}
+
+func TestVerifyTransferSignatureRSA(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	key := &SigningKey{KeyID: "rsa", KeyType: "RSA", PublicKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))}
+
+	digest := transferDigest(&digestVectors[0].transfer)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := verifyTransferSignature(key, digest, signature); err != nil {
+		t.Fatalf("PKCS #1 v1.5 signature: %v", err)
+	}
+	pss, _ := rsa.SignPSS(rand.Reader, private, crypto.SHA256, digest, nil)
+	expectCode(t, verifyTransferSignature(key, digest, pss), ErrCodeSignatureInvalid)
+}
+
+func TestParseSigningKey(t *testing.T) {
+	publicKeyPEM := func(public any) string {
+		der, err := x509.MarshalPKIXPublicKey(public)
+		if err != nil {
+			t.Fatalf("marshal public key: %v", err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p224, _ := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	rsa2048, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsa1024, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	tests := []struct {
+		name    string
+		pem     string
+		keyType string
+		valid   bool
+	}{
+		{"ECDSA P-256", publicKeyPEM(&p256.PublicKey), "ECDSA", true},
+		{"ECDSA P-384", publicKeyPEM(&p384.PublicKey), "ECDSA", true},
+		{"ECDSA P-224", publicKeyPEM(&p224.PublicKey), "ECDSA", false},
+		{"RSA 2048", publicKeyPEM(&rsa2048.PublicKey), "RSA", true},
+		{"RSA 1024", publicKeyPEM(&rsa1024.PublicKey), "RSA", false},
+		{"ECDSA key declared RSA", publicKeyPEM(&p256.PublicKey), "RSA", false},
+		{"RSA key declared ECDSA", publicKeyPEM(&rsa2048.PublicKey), "ECDSA", false},
+		{"not PEM", "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE", "ECDSA", false},
+		{"private key block", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte{1}})), "ECDSA", false},
+	}
+	for _, tc := range tests {
+		_, err := parseSigningKey(tc.pem, tc.keyType)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s: err = %v, want valid = %v", tc.name, err, tc.valid)
+		}
+		if err != nil {
+			expectCode(t, err, ErrCodeValidationError)
+		}
+	}
+}
+
+// signingTestTransfer initiates a sale between registered signers and
+// returns the ledger, registrar, transfer ID and signers (seller, buyer,
+// then two witnesses).
+func signingTestTransfer(t *testing.T) (*testLedger, *testIdentity, string, []*testSigner) {
+	t.Helper()
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	signers := []*testSigner{newTestSigner(t, 1), newTestSigner(t, 2), newTestSigner(t, 3), newTestSigner(t, 4)}
+	property := testProperty("142", 1)
+	ledger.registerTestProperty(registrar, property)
+	ledger.registerTestSigners(registrar, signers...)
+	transferID := ledger.initiateTestTransfer(registrar, testTransfer(property.PropertyID, signers[0], signers[1], signers[2], signers[3]))
+	return ledger, registrar, transferID, signers
+}
+
+// signAs submits signer's signature over digest to transferID as id.
+func (l *testLedger) signAs(id *testIdentity, transferID string, signer *testSigner, digest []byte) error {
+	signature := signer.sign(l.t, digest)
+	return l.submit(id, func(ctx contractapi.TransactionContextInterface) error {
+		return l.contract.SignTransfer(ctx, transferID, signer.hash, signature, "")
+	})
+}
+
+func TestSignTransferCompletesOnVerifiedSignatures(t *testing.T) {
+	ledger, registrar, transferID, signers := signingTestTransfer(t)
+	digest := transferDigest(ledger.readTransfer(transferID))
+
+	for i, signer := range signers {
+		if err := ledger.signAs(registrar, transferID, signer, digest); err != nil {
+			t.Fatalf("signer %d: %v", i, err)
+		}
+		complete := i == len(signers)-1
+		if got := ledger.hasEvent("TRANSFER_SIGNATURES_COMPLETE"); got != complete {
+			t.Fatalf("after signer %d TRANSFER_SIGNATURES_COMPLETE = %v", i, got)
+		}
+	}
+	transfer := ledger.readTransfer(transferID)
+	if transfer.Status != "SIGNATURES_COMPLETE" || len(transfer.Signatures) != 4 {
+		t.Fatalf("transfer is %s with %d signatures", transfer.Status, len(transfer.Signatures))
+	}
+	roles := []string{"SELLER", "BUYER", "WITNESS", "WITNESS"}
+	for i, sig := range transfer.Signatures {
+		if sig.AadhaarHash != signers[i].hash || sig.Role != roles[i] {
+			t.Errorf("signature %d is %s by %s, want %s by %s", i, sig.Role, sig.AadhaarHash, roles[i], signers[i].hash)
+		}
+	}
+	for _, witness := range transfer.Witnesses {
+		if !witness.Signed {
+			t.Errorf("witness %s not marked signed", witness.AadhaarHash)
+		}
+	}
+}
+
+func TestSignTransferRejectsBadSignatures(t *testing.T) {
+	ledger, registrar, transferID, signers := signingTestTransfer(t)
+	transfer := ledger.readTransfer(transferID)
+	digest := transferDigest(transfer)
+	seller := signers[0]
+
+	// Signed over a different declared value
+	altered := *transfer
+	altered.TransactionDetails.DeclaredValue++
+	expectCode(t, ledger.signAs(registrar, transferID, seller, transferDigest(&altered)), ErrCodeSignatureInvalid)
+	// The buyer's signature offered as the seller's
+	buyerSignature := signers[1].sign(t, digest)
+	err := ledger.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SignTransfer(ctx, transferID, seller.hash, buyerSignature, "")
+	})
+	expectCode(t, err, ErrCodeSignatureInvalid)
+	err = ledger.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SignTransfer(ctx, transferID, seller.hash, "not base64!", "")
+	})
+	expectCode(t, err, ErrCodeValidationError)
+
+	stranger := newTestSigner(t, 9)
+	ledger.registerTestSigners(registrar, stranger)
+	expectCode(t, ledger.signAs(registrar, transferID, stranger, digest), ErrCodeSignerNotParty)
+
+	if err := ledger.signAs(registrar, transferID, seller, digest); err != nil {
+		t.Fatalf("seller: %v", err)
+	}
+	expectCode(t, ledger.signAs(registrar, transferID, seller, digest), ErrCodeAlreadySigned)
+	if got := ledger.readTransfer(transferID); got.Status != "SIGNATURES_PENDING" || len(got.Signatures) != 1 {
+		t.Fatalf("transfer is %s with %d signatures, want SIGNATURES_PENDING with 1", got.Status, len(got.Signatures))
+	}
+}
+
+func TestSignTransferFollowsKeyLifecycle(t *testing.T) {
+	ledger, registrar, transferID, signers := signingTestTransfer(t)
+	digest := transferDigest(ledger.readTransfer(transferID))
+	seller := signers[0]
+
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.RevokeSigningKey(ctx, seller.hash, "reported lost")
+	})
+	expectCode(t, ledger.signAs(registrar, transferID, seller, digest), ErrCodeSigningKeyNotFound)
+
+	// A fresh key is registered; the old one no longer verifies
+	replacement := newTestSigner(t, 1)
+	ledger.registerTestSigners(registrar, replacement)
+	expectCode(t, ledger.signAs(registrar, transferID, seller, digest), ErrCodeSignatureInvalid)
+
+	rotated := newTestSigner(t, 1)
+	rotatedPEM := rotated.publicKeyPEM(t)
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.RotateSigningKey(ctx, seller.hash, rotatedPEM, "ECDSA")
+	})
+	expectCode(t, ledger.signAs(registrar, transferID, replacement, digest), ErrCodeSignatureInvalid)
+	if err := ledger.signAs(registrar, transferID, rotated, digest); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+
+	var key *SigningKey
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		key, err = getSigningKey(ctx, seller.hash)
+		return err
+	})
+	// The revoked and the replaced key are both kept in the history
+	if len(key.PreviousKeyIDs) != 2 || ledger.readTransfer(transferID).Signatures[0].KeyID != key.KeyID {
+		t.Fatalf("key history %v, signature key %s", key.PreviousKeyIDs, ledger.readTransfer(transferID).Signatures[0].KeyID)
+	}
+
+	err := ledger.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.RegisterSigningKey(ctx, seller.hash, rotatedPEM, "ECDSA")
+	})
+	expectCode(t, err, ErrCodeSigningKeyExists)
+}
+
+func TestSignTransferSubmitters(t *testing.T) {
+	ledger, _, transferID, signers := signingTestTransfer(t)
+	digest := transferDigest(ledger.readTransfer(transferID))
+	buyer := signers[1]
+
+	// A citizen submits only their own signature
+	expectCode(t, ledger.signAs(newTestCitizen(t, signers[0].hash), transferID, buyer, digest), ErrCodeAccessDenied)
+	expectCode(t, ledger.signAs(newTestIdentity(t, "TelanganaMSP", "tehsildar", "TS"), transferID, buyer, digest), ErrCodeAccessDenied)
+	if err := ledger.signAs(newTestCitizen(t, buyer.hash), transferID, buyer, digest); err != nil {
+		t.Fatalf("buyer signing for themselves: %v", err)
+	}
+}
+
+func TestInitiateTransferClearsWitnessSignedFlags(t *testing.T) {
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	seller, buyer := newTestSigner(t, 1), newTestSigner(t, 2)
+	// Neither witness has a registered key
+	witness1, witness2 := newTestSigner(t, 3), newTestSigner(t, 4)
+	property := testProperty("142", 1)
+	ledger.registerTestProperty(registrar, property)
+	ledger.registerTestSigners(registrar, seller, buyer)
+
+	transfer := testTransfer(property.PropertyID, seller, buyer, witness1, witness2)
+	for i := range transfer.Witnesses {
+		transfer.Witnesses[i].Signed = true
+	}
+	transferID := ledger.initiateTestTransfer(registrar, transfer)
+	for _, witness := range ledger.readTransfer(transferID).Witnesses {
+		if witness.Signed {
+			t.Fatalf("witness %s kept the caller's signed flag", witness.AadhaarHash)
+		}
+	}
+
+	ledger.signTestTransfer(registrar, transferID, seller, buyer)
+	if got := ledger.readTransfer(transferID).Status; got != "SIGNATURES_PENDING" {
+		t.Fatalf("status = %s without witness signatures, want SIGNATURES_PENDING", got)
+	}
+}
+
+func TestWitnessAndPartySignatures(t *testing.T) {
+	ledger, registrar, transferID, signers := signingTestTransfer(t)
+	digest := transferDigest(ledger.readTransfer(transferID))
+	seller, buyer, witness := signers[0], signers[1], signers[2]
+	evidenceHash := fmt.Sprintf("%064x", 0xe5)
+	addWitness := func(signer *testSigner, signature, evidenceHash string) error {
+		return ledger.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.AddWitnessSignature(ctx, transferID, signer.hash, signature, evidenceHash)
+		})
+	}
+	recordParty := func(signer *testSigner, signature, evidenceHash string) error {
+		return ledger.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.RecordPartySignature(ctx, transferID, signer.hash, signature, evidenceHash, "")
+		})
+	}
+
+	// Each records only its own kind of signer
+	expectCode(t, addWitness(seller, seller.sign(t, digest), evidenceHash), ErrCodeSignerNotParty)
+	expectCode(t, recordParty(witness, witness.sign(t, digest), evidenceHash), ErrCodeSignerNotParty)
+	// The signature is verified and the evidence is required
+	expectCode(t, addWitness(witness, signers[3].sign(t, digest), evidenceHash), ErrCodeSignatureInvalid)
+	expectCode(t, recordParty(buyer, buyer.sign(t, digest), ""), ErrCodeValidationError)
+	expectCode(t, recordParty(buyer, buyer.sign(t, digest), "not-a-hash"), ErrCodeValidationError)
+	if got := ledger.readTransfer(transferID).Signatures; len(got) != 0 {
+		t.Fatalf("refused signatures were recorded: %+v", got)
+	}
+
+	for _, signer := range []*testSigner{seller, buyer} {
+		if err := recordParty(signer, signer.sign(t, digest), evidenceHash); err != nil {
+			t.Fatalf("RecordPartySignature: %v", err)
+		}
+	}
+	expectCode(t, recordParty(seller, seller.sign(t, digest), evidenceHash), ErrCodeAlreadySigned)
+	for _, signer := range signers[2:] {
+		if err := addWitness(signer, signer.sign(t, digest), evidenceHash); err != nil {
+			t.Fatalf("AddWitnessSignature: %v", err)
+		}
+	}
+	if !ledger.hasEvent("TRANSFER_SIGNATURES_COMPLETE") {
+		t.Fatal("the last signature did not emit TRANSFER_SIGNATURES_COMPLETE")
+	}
+
+	transfer := ledger.readTransfer(transferID)
+	if transfer.Status != "SIGNATURES_COMPLETE" || len(transfer.Signatures) != 4 {
+		t.Fatalf("transfer is %s with %d signatures", transfer.Status, len(transfer.Signatures))
+	}
+	for i, sig := range transfer.Signatures {
+		if sig.EvidenceHash != evidenceHash {
+			t.Errorf("signature %d evidenceHash = %q", i, sig.EvidenceHash)
+		}
+	}
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer: %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// splitChild returns a TS-HYD-SRN-GCB sub-plot of area square metres
+// owned by testAadhaarHash(owner).
+func splitChild(surveyNo, subSurveyNo string, owner int, area float64) SplitRequest {
+	return SplitRequest{
+		NewPropertyID:   "TS-HYD-SRN-GCB-" + surveyNo + "-" + subSurveyNo,
+		SurveyNumber:    surveyNo,
+		SubSurveyNumber: subSurveyNo,
+		Area:            Area{Value: area, Unit: "SQ_METERS"},
+		OwnerInfo:       testProperty(surveyNo, owner).CurrentOwner,
+	}
+}
+
+// splitTestProperty submits SplitProperty as registrar.
+func (l *testLedger) splitTestProperty(registrar *testIdentity, propertyID string, splits []SplitRequest, handling string, consentRefs map[string]string) error {
+	l.t.Helper()
+	splitsJSON, err := json.Marshal(splits)
+	if err != nil {
+		l.t.Fatalf("marshal splits: %v", err)
+	}
+	consentRefsJSON := ""
+	if consentRefs != nil {
+		refs, err := json.Marshal(consentRefs)
+		if err != nil {
+			l.t.Fatalf("marshal consent references: %v", err)
+		}
+		consentRefsJSON = string(refs)
+	}
+	return l.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := l.contract.SplitProperty(ctx, propertyID, string(splitsJSON), handling, consentRefsJSON)
+		return err
+	})
+}
+
+// mortgageTestProperty records a mortgage on propertyID by a bank and
+// returns its encumbrance ID.
+func (l *testLedger) mortgageTestProperty(propertyID string) string {
+	l.t.Helper()
+	encJSON, err := json.Marshal(EncumbranceRecord{PropertyID: propertyID, Type: "MORTGAGE", Institution: Institution{Name: "State Bank of India"}})
+	if err != nil {
+		l.t.Fatalf("marshal encumbrance: %v", err)
+	}
+	var receipt *Receipt
+	l.mustSubmit(newTestIdentity(l.t, "SBIMSP", "bank", "TS"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		receipt, err = l.contract.AddEncumbrance(ctx, string(encJSON))
+		return err
+	})
+	var encumbranceID string
+	l.mustSubmit(newTestIdentity(l.t, "AdminOrgMSP", "admin", "IN"), func(ctx contractapi.TransactionContextInterface) error {
+		encumbrances, err := getActiveEncumbrances(ctx, propertyID)
+		if len(encumbrances) == 1 {
+			encumbranceID = encumbrances[0].EncumbranceID
+		}
+		return err
+	})
+	if receipt == nil || encumbranceID == "" {
+		l.t.Fatalf("no mortgage recorded on %s", propertyID)
+	}
+	return encumbranceID
+}
+
+// readEncumbrances reads every encumbrance on propertyID, whatever its
+// status.
+func (l *testLedger) readEncumbrances(propertyID string) []*EncumbranceRecord {
+	l.t.Helper()
+	var encumbrances []*EncumbranceRecord
+	l.mustSubmit(newTestIdentity(l.t, "AdminOrgMSP", "admin", "IN"), func(ctx contractapi.TransactionContextInterface) error {
+		iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixEncumbrance, []string{propertyID})
+		if err != nil {
+			return err
+		}
+		defer iterator.Close()
+		for iterator.HasNext() {
+			kv, err := iterator.Next()
+			if err != nil {
+				return err
+			}
+			var enc EncumbranceRecord
+			if err := json.Unmarshal(kv.Value, &enc); err != nil {
+				return err
+			}
+			encumbrances = append(encumbrances, &enc)
+		}
+		return nil
+	})
+	return encumbrances
+}
+
+// splitTestParent registers a 1000 sq m parcel at survey 142 for splitting.
+func splitTestParent(t *testing.T) (*testLedger, *testIdentity, string) {
+	t.Helper()
+	ledger := newTestLedger(t)
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+	parent := testProperty("142", 1)
+	parent.Area.Value = 1000
+	ledger.registerTestProperty(registrar, parent)
+	return ledger, registrar, parent.PropertyID
+}
+
+// halves splits survey 142 into 142/1 and 142/2 of 500 sq m each.
+var halves = []SplitRequest{splitChild("142", "1", 1, 500), splitChild("142", "2", 2, 500)}
+
+func TestSplitMortgagedParentRequiresClearance(t *testing.T) {
+	for _, handling := range []string{"", "REQUIRE_CLEAR"} {
+		ledger, registrar, parentID := splitTestParent(t)
+		ledger.mortgageTestProperty(parentID)
+
+		expectCode(t, ledger.splitTestProperty(registrar, parentID, halves, handling, nil), ErrCodeLandEncumbered)
+		if got := ledger.readProperty(parentID); got.Status != "ACTIVE" || got.EncumbranceStatus != "ENCUMBERED" {
+			t.Fatalf("handling %q: parent is %s/%s, want ACTIVE/ENCUMBERED", handling, got.Status, got.EncumbranceStatus)
+		}
+	}
+}
+
+func TestSplitMortgagedParentNeedsBankConsent(t *testing.T) {
+	ledger, registrar, parentID := splitTestParent(t)
+	ledger.mortgageTestProperty(parentID)
+
+	expectCode(t, ledger.splitTestProperty(registrar, parentID, halves, "CARRY_WITH_CONSENT", nil), ErrCodeEncumbranceConsentRequired)
+	expectCode(t, ledger.splitTestProperty(registrar, parentID, halves, "CARRY_WITH_CONSENT", map[string]string{"enc_other": "SBI/NOC/1"}), ErrCodeEncumbranceConsentRequired)
+}
+
+func TestSplitCarriesMortgageWithConsent(t *testing.T) {
+	ledger, registrar, parentID := splitTestParent(t)
+	encumbranceID := ledger.mortgageTestProperty(parentID)
+
+	consent := map[string]string{encumbranceID: "SBI/NOC/2027/142"}
+	if err := ledger.splitTestProperty(registrar, parentID, halves, "CARRY_WITH_CONSENT", consent); err != nil {
+		t.Fatalf("SplitProperty: %v", err)
+	}
+
+	original := ledger.readEncumbrances(parentID)
+	if len(original) != 1 || original[0].Status != "SUPERSEDED" {
+		t.Fatalf("parent encumbrances = %+v, want the mortgage SUPERSEDED", original)
+	}
+	for _, child := range halves {
+		if got := ledger.readProperty(child.NewPropertyID).EncumbranceStatus; got != "ENCUMBERED" {
+			t.Errorf("%s encumbranceStatus = %s, want ENCUMBERED", child.NewPropertyID, got)
+		}
+		carried := ledger.readEncumbrances(child.NewPropertyID)
+		if len(carried) != 1 {
+			t.Fatalf("%s has %d encumbrances, want 1", child.NewPropertyID, len(carried))
+		}
+		enc := carried[0]
+		if enc.Status != "ACTIVE" || enc.Type != "MORTGAGE" || enc.Institution.MspID != "SBIMSP" {
+			t.Errorf("%s carries %s %s held by %s", child.NewPropertyID, enc.Status, enc.Type, enc.Institution.MspID)
+		}
+		if enc.CarriedFrom != parentID || enc.CarriedFromEncumbranceID != encumbranceID || enc.ConsentRef != consent[encumbranceID] {
+			t.Errorf("%s mortgage carried from %s/%s with consent %q", child.NewPropertyID, enc.CarriedFrom, enc.CarriedFromEncumbranceID, enc.ConsentRef)
+		}
+	}
+}
+
+func TestSplitReleasesParentIndexes(t *testing.T) {
+	ledger, registrar, parentID := splitTestParent(t)
+	if err := ledger.splitTestProperty(registrar, parentID, halves, "", nil); err != nil {
+		t.Fatalf("SplitProperty: %v", err)
+	}
+	if got := ledger.readProperty(parentID).Status; got != "SPLIT" {
+		t.Fatalf("parent status = %s, want SPLIT", got)
+	}
+
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := ledger.contract.QueryBySurvey(ctx, "TS", "HYD", "142")
+		expectCode(t, err, ErrCodePropertyNotFound)
+
+		child, err := ledger.contract.QueryBySurvey(ctx, "TS", "HYD", "142/1")
+		if err != nil {
+			return err
+		}
+		if child.PropertyID != halves[0].NewPropertyID {
+			t.Errorf("survey 142/1 resolves to %s, want %s", child.PropertyID, halves[0].NewPropertyID)
+		}
+
+		located, err := ledger.contract.QueryByLocation(ctx, "TS", "HYD", "SRN", "GCB")
+		if err != nil {
+			return err
+		}
+		var ids []string
+		for _, property := range located {
+			ids = append(ids, property.PropertyID)
+		}
+		if len(ids) != 2 || ids[0] != halves[0].NewPropertyID || ids[1] != halves[1].NewPropertyID {
+			t.Errorf("Gachibowli lists %v, want only the children", ids)
+		}
+		return nil
+	})
+}
+
+func TestSplitChildSurveySlotMustBeFree(t *testing.T) {
+	ledger, registrar, parentID := splitTestParent(t)
+	// The survey index is per district, so Madhapur's 142/1 holds the slot
+	other := testProperty("142", 3)
+	other.PropertyID, other.SubSurveyNumber = "TS-HYD-SRN-MDP-142-1", "1"
+	other.Location.VillageCode, other.Location.VillageName = "MDP", "Madhapur"
+	ledger.registerTestProperty(registrar, other)
+
+	expectCode(t, ledger.splitTestProperty(registrar, parentID, halves, "", nil), ErrCodeSurveyNumberOccupied)
+	if got := ledger.readProperty(parentID).Status; got != "ACTIVE" {
+		t.Fatalf("parent status = %s, want ACTIVE", got)
+	}
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		holder, err := ledger.contract.QueryBySurvey(ctx, "TS", "HYD", "142/1")
+		if err == nil && holder.PropertyID != other.PropertyID {
+			t.Errorf("survey 142/1 resolves to %s, want %s", holder.PropertyID, other.PropertyID)
+		}
+		return err
+	})
+}
+
+// revertTestSplit submits RevertSplit for parentID as a TS admin.
+func (l *testLedger) revertTestSplit(parentID string) error {
+	return l.submit(newTestIdentity(l.t, "TelanganaMSP", "admin", "TS"), func(ctx contractapi.TransactionContextInterface) error {
+		return l.contract.RevertSplit(ctx, parentID, "survey sketch recorded against the wrong parcel")
+	})
+}
+
+func TestRevertSplitRestoresParent(t *testing.T) {
+	ledger, registrar, parentID := splitTestParent(t)
+	encumbranceID := ledger.mortgageTestProperty(parentID)
+	consent := map[string]string{encumbranceID: "SBI/NOC/2027/142"}
+	if err := ledger.splitTestProperty(registrar, parentID, halves, "CARRY_WITH_CONSENT", consent); err != nil {
+		t.Fatalf("SplitProperty: %v", err)
+	}
+
+	if err := ledger.revertTestSplit(parentID); err != nil {
+		t.Fatalf("RevertSplit: %v", err)
+	}
+	if !ledger.hasEvent("SPLIT_REVERTED") {
+		t.Fatalf("events = %+v, want SPLIT_REVERTED", ledger.events)
+	}
+
+	// The children are retired along with the charges carried onto them
+	for _, child := range halves {
+		if got := ledger.readProperty(child.NewPropertyID).Status; got != "CANCELLED" {
+			t.Errorf("%s status = %s, want CANCELLED", child.NewPropertyID, got)
+		}
+		if carried := ledger.readEncumbrances(child.NewPropertyID); len(carried) != 1 || carried[0].Status != "CANCELLED" {
+			t.Errorf("%s encumbrances = %+v, want the carried mortgage CANCELLED", child.NewPropertyID, carried)
+		}
+	}
+
+	// The parent is ACTIVE and mortgaged again, and lookups find it
+	parent := ledger.readProperty(parentID)
+	if parent.Status != "ACTIVE" || parent.EncumbranceStatus != "ENCUMBERED" {
+		t.Fatalf("parent is %s/%s, want ACTIVE/ENCUMBERED", parent.Status, parent.EncumbranceStatus)
+	}
+	if original := ledger.readEncumbrances(parentID); len(original) != 1 || original[0].Status != "ACTIVE" {
+		t.Fatalf("parent encumbrances = %+v, want the mortgage ACTIVE", original)
+	}
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		property, err := ledger.contract.QueryBySurvey(ctx, "TS", "HYD", "142")
+		if err == nil && property.PropertyID != parentID {
+			t.Errorf("survey 142 resolves to %s, want %s", property.PropertyID, parentID)
+		}
+		_, childErr := ledger.contract.QueryBySurvey(ctx, "TS", "HYD", "142/1")
+		expectCode(t, childErr, ErrCodePropertyNotFound)
+		return err
+	})
+}
+
+func TestRevertSplitRefusedAfterChildTransfer(t *testing.T) {
+	ledger, registrar, parentID := splitTestParent(t)
+	if err := ledger.splitTestProperty(registrar, parentID, halves, "", nil); err != nil {
+		t.Fatalf("SplitProperty: %v", err)
+	}
+
+	// The owner of 142/1 sells it on
+	seller, buyer := newTestSigner(t, 1), newTestSigner(t, 5)
+	witness1, witness2 := newTestSigner(t, 3), newTestSigner(t, 4)
+	ledger.registerTestSigners(registrar, seller, buyer, witness1, witness2)
+	transferID := ledger.initiateTestTransfer(registrar, testTransfer(halves[0].NewPropertyID, seller, buyer, witness1, witness2))
+	ledger.signTestTransfer(registrar, transferID, seller, buyer, witness1, witness2)
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer: %v", err)
+	}
+
+	expectCode(t, ledger.revertTestSplit(parentID), ErrCodeSplitNotRevertible)
+	if got := ledger.readProperty(parentID).Status; got != "SPLIT" {
+		t.Fatalf("parent status = %s, want SPLIT", got)
+	}
+	if got := ledger.readProperty(halves[1].NewPropertyID).Status; got != "ACTIVE" {
+		t.Fatalf("%s status = %s, want ACTIVE", halves[1].NewPropertyID, got)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// STAMP-DUTY CHAINCODE CALLS
+// ============================================================
+// Fees are configured and paid in the stamp-duty chaincode on the same
+// channel. Functions here invoke it in the same transaction, so its
+// reads and writes (such as consuming a challan) commit with ours.
+
+// stampDutyChaincode is the name the stamp-duty chaincode is deployed
+// under.
+const stampDutyChaincode = "stamp-duty"
+
+// chaincodeStatusOK is the response status of a successful
+// chaincode-to-chaincode call.
+const chaincodeStatusOK = 200
+
+// invokeStampDuty calls function on the stamp-duty chaincode and
+// unmarshals its JSON result into result, if non-nil.
+func invokeStampDuty(ctx contractapi.TransactionContextInterface, result interface{}, function string, args ...string) error {
+	invokeArgs := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+	response := ctx.GetStub().InvokeChaincode(stampDutyChaincode, invokeArgs, "")
+	if response.Status != chaincodeStatusOK {
+		return newError(ErrCodeStampDutyCallFailed, "%s: %s", function, response.Message)
+	}
+	if result == nil || len(response.Payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(response.Payload, result); err != nil {
+		return internalError("failed to unmarshal %s result: %v", function, err)
+	}
+	return nil
+}
+
+// conversionCharge is the part of the stamp-duty ConversionCharge that
+// ChangeLandUse checks.
+type conversionCharge struct {
+	ChargeAmount int64 `json:"chargeAmount"`
+}
+
+// paymentVerification is the part of the stamp-duty PaymentVerification
+// that ChangeLandUse checks.
+type paymentVerification struct {
+	Valid      bool   `json:"valid"`
+	Reason     string `json:"reason"`
+	AmountPaid int64  `json:"amountPaid"`
+}
+
+// settleConversionCharge checks that challan pays exactly the
+// stamp-duty conversion charge for changing property to newLandUse, and
+// consumes it against the property. It returns the charge in paisa.
+func settleConversionCharge(ctx contractapi.TransactionContextInterface, property *LandRecord, newLandUse, challan string, bighaSqMeters float64) (int64, error) {
+	if challan == "" {
+		return 0, newError(ErrCodeConversionChargeUnpaid, "a conversion charge challan is required to change %s from %s to %s",
+			property.PropertyID, property.LandUse, newLandUse)
+	}
+	areaSqM, err := ConvertArea(property.Area.Value, property.Area.Unit, AreaUnitSqMeters, bighaSqMeters)
+	if err != nil {
+		return 0, err
+	}
+
+	var charge conversionCharge
+	if err := invokeStampDuty(ctx, &charge, "CalculateConversionCharge",
+		property.Location.StateCode, property.Location.DistrictCode, property.Location.TehsilCode,
+		property.LandUse, newLandUse, fmt.Sprintf("%g", areaSqM)); err != nil {
+		return 0, err
+	}
+
+	var verification paymentVerification
+	if err := invokeStampDuty(ctx, &verification, "VerifyPayment", property.Location.StateCode, challan, fmt.Sprintf("%d", charge.ChargeAmount)); err != nil {
+		return 0, err
+	}
+	if !verification.Valid {
+		return 0, newError(ErrCodeConversionChargeUnpaid, "challan %s cannot pay the conversion charge of %d paisa: %s",
+			challan, charge.ChargeAmount, verification.Reason)
+	}
+	if verification.AmountPaid != charge.ChargeAmount {
+		return 0, newError(ErrCodeConversionChargeUnpaid, "challan %s paid %d paisa, the conversion charge is %d paisa",
+			challan, verification.AmountPaid, charge.ChargeAmount)
+	}
+	if err := invokeStampDuty(ctx, nil, "MarkPaymentConsumed", property.Location.StateCode, challan, property.PropertyID); err != nil {
+		return 0, err
+	}
+	return charge.ChargeAmount, nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// SYSTEM STATS
+// ============================================================
+// The operations dashboard and the daily health report need ledger-wide
+// numbers without scanning millions of records. Each state keeps one
+// LedgerCounters document that the write paths adjust as records are
+// created and as land records, mutations and cooling periods change
+// state; GetSystemStats reads it alongside the state's latest anchor and
+// the channel's event sequence counter. Counting starts when the
+// counters are first written, so an existing channel seeds them once
+// from its off-chain mirror with SeedLedgerCounters.
+//
+// Like the event sequence counter, the document is written by every
+// counted transaction of a state, so concurrent ones in a block fail
+// MVCC validation and must be resubmitted.
+
+// SystemStatsVersion is the version of the SystemStats shape, raised
+// whenever a field changes meaning or is removed.
+const SystemStatsVersion = 1
+
+// GetSystemStats returns the operational numbers of a state: records by
+// docType, land records and mutations by status, active cooling periods,
+// pending mutations, the last anchored block and the channel's event
+// sequence. It reads the state's counters, its anchors and the event
+// sequence counter only, never the records themselves. Only admins can
+// read it.
+func (s *LandRegistryContract) GetSystemStats(ctx contractapi.TransactionContextInterface, stateCode string) (*SystemStats, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode is required")
+	}
+
+	counters, err := readLedgerCounters(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0)
+
+	stats := &SystemStats{
+		StatsVersion:         SystemStatsVersion,
+		StateCode:            stateCode,
+		ChannelID:            ctx.GetStub().GetChannelID(),
+		RecordsByDocType:     counters.RecordsByDocType,
+		LandRecordsByStatus:  counters.LandRecordsByStatus,
+		MutationsByStatus:    counters.MutationsByStatus,
+		ActiveCoolingPeriods: counters.ActiveCoolingPeriods,
+		PendingMutations:     counters.MutationsByStatus["PENDING_APPROVAL"],
+		CountingSince:        counters.CountingSince,
+		SeededAt:             counters.SeededAt,
+		ComputedAt:           txTime.Format(time.RFC3339),
+	}
+
+	anchor, err := latestAnchor(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+	if anchor != nil {
+		stats.LastAnchorID = anchor.AnchorID
+		stats.LastAnchoredBlock = anchor.FabricBlockRange.End
+		stats.LastAnchoredAt = anchor.AnchoredAt
+		if anchoredAt, err := time.Parse(time.RFC3339, anchor.AnchoredAt); err == nil {
+			stats.SecondsSinceAnchor = int64(txTime.Sub(anchoredAt).Seconds())
+		}
+	}
+
+	sequenceKey, err := ctx.GetStub().CreateCompositeKey(KeyPrefixEventSequence, []string{stats.ChannelID})
+	if err != nil {
+		return nil, internalError("failed to create event sequence key: %v", err)
+	}
+	sequenceBytes, err := ctx.GetStub().GetState(sequenceKey)
+	if err != nil {
+		return nil, internalError("failed to read event sequence: %v", err)
+	}
+	if sequenceBytes != nil {
+		var sequence EventSequenceCounter
+		if err := json.Unmarshal(sequenceBytes, &sequence); err != nil {
+			return nil, internalError("failed to unmarshal event sequence: %v", err)
+		}
+		stats.EventSequence = sequence.Sequence
+	}
+	return stats, nil
+}
+
+// SeedLedgerCounters replaces a state's counters with countersJSON, a
+// LedgerCounters giving recordsByDocType, landRecordsByStatus,
+// mutationsByStatus and activeCoolingPeriods as counted off-chain. It is
+// for channels that held records before counting began, and for
+// correcting counters found to have drifted. Only admins can seed them.
+func (s *LandRegistryContract) SeedLedgerCounters(ctx contractapi.TransactionContextInterface, stateCode, countersJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+	if stateCode == "" {
+		return newError(ErrCodeValidationError, "stateCode is required")
+	}
+	var seed LedgerCounters
+	if err := json.Unmarshal([]byte(countersJSON), &seed); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse counters JSON: %v", err)
+	}
+	for name, counts := range map[string]map[string]int64{
+		"recordsByDocType":    seed.RecordsByDocType,
+		"landRecordsByStatus": seed.LandRecordsByStatus,
+		"mutationsByStatus":   seed.MutationsByStatus,
+	} {
+		for key, count := range counts {
+			if strings.TrimSpace(key) == "" || count < 0 {
+				return newError(ErrCodeValidationError, "%s must map non-empty names to non-negative counts", name)
+			}
+		}
+	}
+	if seed.ActiveCoolingPeriods < 0 {
+		return newError(ErrCodeValidationError, "activeCoolingPeriods must not be negative")
+	}
+
+	counters, err := ledgerCountersFor(ctx, stateCode)
+	if err != nil {
+		return err
+	}
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	counters.RecordsByDocType = seed.RecordsByDocType
+	counters.LandRecordsByStatus = seed.LandRecordsByStatus
+	counters.MutationsByStatus = seed.MutationsByStatus
+	counters.ActiveCoolingPeriods = seed.ActiveCoolingPeriods
+	counters.SeededAt = now
+	counters.SeededBy = getCallerID(ctx)
+	if err := putLedgerCounters(ctx, counters); err != nil {
+		return err
+	}
+	return recordAudit(ctx, "SeedLedgerCounters", stateCode)
+}
+
+// countNewRecord counts a record of docType created in a state. status
+// is the initial status of a land record or mutation and is otherwise
+// ignored.
+func countNewRecord(ctx contractapi.TransactionContextInterface, stateCode, docType, status string) error {
+	return adjustLedgerCounters(ctx, stateCode, func(counters *LedgerCounters) {
+		counters.RecordsByDocType[docType]++
+		switch docType {
+		case "landRecord":
+			counters.LandRecordsByStatus[status]++
+		case "mutationRecord":
+			counters.MutationsByStatus[status]++
+		}
+	})
+}
+
+// countStatusChange moves a land record or mutation from one status
+// count to another.
+func countStatusChange(ctx contractapi.TransactionContextInterface, stateCode, docType, oldStatus, newStatus string) error {
+	return adjustLedgerCounters(ctx, stateCode, func(counters *LedgerCounters) {
+		byStatus := counters.LandRecordsByStatus
+		if docType == "mutationRecord" {
+			byStatus = counters.MutationsByStatus
+		}
+		decrementCount(byStatus, oldStatus)
+		byStatus[newStatus]++
+	})
+}
+
+// countCoolingPeriods adjusts a state's active cooling periods by delta.
+func countCoolingPeriods(ctx contractapi.TransactionContextInterface, stateCode string, delta int64) error {
+	return adjustLedgerCounters(ctx, stateCode, func(counters *LedgerCounters) {
+		counters.ActiveCoolingPeriods += delta
+		if counters.ActiveCoolingPeriods < 0 {
+			counters.ActiveCoolingPeriods = 0
+		}
+	})
+}
+
+// decrementCount lowers counts[key], dropping it at zero. A count is
+// never taken below zero: a record that predates counting was never
+// added to it.
+func decrementCount(counts map[string]int64, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+		return
+	}
+	counts[key]--
+}
+
+// adjustLedgerCounters applies adjust to a state's counters and writes
+// them back.
+func adjustLedgerCounters(ctx contractapi.TransactionContextInterface, stateCode string, adjust func(*LedgerCounters)) error {
+	counters, err := ledgerCountersFor(ctx, stateCode)
+	if err != nil {
+		return err
+	}
+	adjust(counters)
+	return putLedgerCounters(ctx, counters)
+}
+
+// ledgerCountersFor returns a state's counters for updating. GetState
+// does not see a transaction's own writes, so the counters are read once
+// per transaction and kept on the LandRegistryContext for the
+// transaction's later adjustments.
+func ledgerCountersFor(ctx contractapi.TransactionContextInterface, stateCode string) (*LedgerCounters, error) {
+	var cache map[string]*LedgerCounters
+	if c, ok := ctx.(interface {
+		ledgerCounters() map[string]*LedgerCounters
+	}); ok {
+		cache = c.ledgerCounters()
+	}
+	if counters := cache[stateCode]; counters != nil {
+		return counters, nil
+	}
+	counters, err := readLedgerCounters(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache[stateCode] = counters
+	}
+	return counters, nil
+}
+
+func (c *LandRegistryContext) ledgerCounters() map[string]*LedgerCounters {
+	if c.counters == nil {
+		c.counters = map[string]*LedgerCounters{}
+	}
+	return c.counters
+}
+
+// readLedgerCounters reads a state's counters, or empty counters that
+// start counting at the transaction time if it has none.
+func readLedgerCounters(ctx contractapi.TransactionContextInterface, stateCode string) (*LedgerCounters, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixLedgerCounters, []string{stateCode})
+	if err != nil {
+		return nil, internalError("failed to create ledger counters key: %v", err)
+	}
+	countersBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read ledger counters: %v", err)
+	}
+	var counters LedgerCounters
+	if countersBytes != nil {
+		if err := json.Unmarshal(countersBytes, &counters); err != nil {
+			return nil, internalError("failed to unmarshal ledger counters: %v", err)
+		}
+	} else {
+		timestamp, _ := ctx.GetStub().GetTxTimestamp()
+		counters.DocType = "ledgerCounters"
+		counters.StateCode = stateCode
+		counters.CountingSince = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	}
+	if counters.RecordsByDocType == nil {
+		counters.RecordsByDocType = map[string]int64{}
+	}
+	if counters.LandRecordsByStatus == nil {
+		counters.LandRecordsByStatus = map[string]int64{}
+	}
+	if counters.MutationsByStatus == nil {
+		counters.MutationsByStatus = map[string]int64{}
+	}
+	return &counters, nil
+}
+
+// putLedgerCounters writes a state's counters.
+func putLedgerCounters(ctx contractapi.TransactionContextInterface, counters *LedgerCounters) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixLedgerCounters, []string{counters.StateCode})
+	if err != nil {
+		return internalError("failed to create ledger counters key: %v", err)
+	}
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	counters.UpdatedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	counters.FabricTxID = ctx.GetStub().GetTxID()
+	countersBytes, err := canonicalMarshal(counters)
+	if err != nil {
+		return internalError("failed to marshal ledger counters: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, countersBytes); err != nil {
+		return internalError("failed to write ledger counters: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,517 @@
+package main
+
+import (
+	"container/list"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// ============================================================
+// MOCKED-STUB TEST HARNESS
+// ============================================================
+// Tests run contract functions against a shimtest.MockStub world state.
+// Each call is one transaction submitted by a testIdentity, which stands
+// in for a Fabric CA enrolled certificate, through the LandRegistryContext
+// main registers. A failed transaction's writes are rolled back, as the
+// peer would discard its read-write set. Every event a transaction sets
+// is kept, though Fabric delivers only the last.
+
+// testTime is the default transaction timestamp.
+var testTime = time.Date(2027, 3, 15, 10, 30, 0, 0, time.UTC)
+
+// testIdentity is a client identity with fixed certificate attributes
+// and a freshly generated self-signed certificate.
+type testIdentity struct {
+	mspID string
+	attrs map[string]string
+	cert  *x509.Certificate
+}
+
+// newTestIdentity returns an identity of mspID with the given role and
+// stateCode attributes. Further attributes can be set on attrs.
+func newTestIdentity(t *testing.T, mspID, role, stateCode string) *testIdentity {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: role + "@" + strings.ToLower(mspID)},
+		NotBefore:    testTime.AddDate(-1, 0, 0),
+		NotAfter:     testTime.AddDate(1, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	attrs := map[string]string{}
+	if role != "" {
+		attrs["role"] = role
+	}
+	if stateCode != "" {
+		attrs["stateCode"] = stateCode
+	}
+	return &testIdentity{mspID: mspID, attrs: attrs, cert: cert}
+}
+
+func (id *testIdentity) GetID() (string, error) {
+	return "x509::CN=" + id.cert.Subject.CommonName, nil
+}
+
+func (id *testIdentity) GetMSPID() (string, error) {
+	return id.mspID, nil
+}
+
+func (id *testIdentity) GetAttributeValue(name string) (string, bool, error) {
+	value, found := id.attrs[name]
+	return value, found, nil
+}
+
+func (id *testIdentity) AssertAttributeValue(name, value string) error {
+	if id.attrs[name] != value {
+		return fmt.Errorf("attribute %s is '%s', not '%s'", name, id.attrs[name], value)
+	}
+	return nil
+}
+
+func (id *testIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return id.cert, nil
+}
+
+// testEvent is a chaincode event set by a test transaction.
+type testEvent struct {
+	Name    string
+	Payload []byte
+}
+
+// testLedger is the world state shared by a test's transactions.
+type testLedger struct {
+	t        *testing.T
+	stub     *shimtest.MockStub
+	contract *LandRegistryContract
+	now      time.Time
+	txCount  int
+	// events holds the events set by the last transaction
+	events []testEvent
+}
+
+// newTestLedger returns an empty ledger on the landregistry channel.
+func newTestLedger(t *testing.T) *testLedger {
+	stub := shimtest.NewMockStub("land-registry", nil)
+	stub.ChannelID = "landregistry"
+	return &testLedger{t: t, stub: stub, contract: new(LandRegistryContract), now: testTime}
+}
+
+// testTxID returns the ID of the ledger's nth transaction.
+func testTxID(n int) string {
+	return fmt.Sprintf("%08x%056x", n, n)
+}
+
+// submit runs fn as one transaction submitted by id, rolling its writes
+// back if it fails.
+func (l *testLedger) submit(id *testIdentity, fn func(ctx contractapi.TransactionContextInterface) error) error {
+	l.t.Helper()
+	l.txCount++
+	txID := testTxID(l.txCount)
+
+	state := make(map[string][]byte, len(l.stub.State))
+	for key, value := range l.stub.State {
+		state[key] = value
+	}
+	policies := make(map[string][]byte, len(l.stub.EndorsementPolicies[""]))
+	for key, value := range l.stub.EndorsementPolicies[""] {
+		policies[key] = value
+	}
+
+	l.stub.MockTransactionStart(txID)
+	l.stub.TxTimestamp.Seconds = l.now.Unix()
+	l.stub.TxTimestamp.Nanos = 0
+	ctx := new(LandRegistryContext)
+	ctx.SetStub(&queryStub{l.stub})
+	ctx.SetClientIdentity(id)
+	err := fn(ctx)
+	l.stub.MockTransactionEnd(txID)
+
+	l.events = nil
+	for len(l.stub.ChaincodeEventsChannel) > 0 {
+		event := <-l.stub.ChaincodeEventsChannel
+		l.events = append(l.events, testEvent{Name: event.EventName, Payload: event.Payload})
+	}
+	if err != nil {
+		l.stub.State = state
+		l.stub.Keys = sortedKeys(state)
+		l.stub.EndorsementPolicies[""] = policies
+		l.events = nil
+	}
+	return err
+}
+
+// mustSubmit is submit for transactions the test expects to succeed.
+func (l *testLedger) mustSubmit(id *testIdentity, fn func(ctx contractapi.TransactionContextInterface) error) {
+	l.t.Helper()
+	if err := l.submit(id, fn); err != nil {
+		l.t.Fatalf("transaction failed: %v", err)
+	}
+}
+
+// hasEvent reports whether the last transaction set an event named name.
+func (l *testLedger) hasEvent(name string) bool {
+	for _, event := range l.events {
+		if event.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// eventFields returns the fields of the last event named name set by
+// the last transaction, or nil.
+func (l *testLedger) eventFields(name string) map[string]json.RawMessage {
+	l.t.Helper()
+	var fields map[string]json.RawMessage
+	for _, event := range l.events {
+		if event.Name != name {
+			continue
+		}
+		fields = nil
+		if err := json.Unmarshal(event.Payload, &fields); err != nil {
+			l.t.Fatalf("unmarshal %s payload: %v", name, err)
+		}
+	}
+	return fields
+}
+
+// sortedKeys returns the keys of state as an ordered MockStub key
+// list, which range queries rely on.
+func sortedKeys(state map[string][]byte) *list.List {
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sorted := list.New()
+	for _, key := range keys {
+		sorted.PushBack(key)
+	}
+	return sorted
+}
+
+// queryStub is a MockStub that answers rich queries whose selector
+// only matches fields, dotted paths included, against literal values:
+// enough for lookups such as an encumbrance by its ID. Any other query
+// fails rather than returning a wrong result.
+type queryStub struct {
+	*shimtest.MockStub
+}
+
+func (s *queryStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(query), &parsed); err != nil {
+		return nil, fmt.Errorf("parse query: %v", err)
+	}
+	var selector map[string]interface{}
+	for field, value := range parsed {
+		switch field {
+		case "selector":
+			if err := json.Unmarshal(value, &selector); err != nil {
+				return nil, fmt.Errorf("parse selector: %v", err)
+			}
+		case "use_index":
+		default:
+			return nil, fmt.Errorf("query %s is not supported by the test stub", field)
+		}
+	}
+	for field, want := range selector {
+		switch want.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("selector on %s is not supported by the test stub", field)
+		}
+	}
+
+	results := &sliceIterator{}
+	for e := s.Keys.Front(); e != nil; e = e.Next() {
+		key := e.Value.(string)
+		var doc map[string]interface{}
+		if json.Unmarshal(s.State[key], &doc) != nil {
+			continue
+		}
+		if selectorMatches(selector, doc) {
+			results.kvs = append(results.kvs, &queryresult.KV{Key: key, Value: s.State[key]})
+		}
+	}
+	return results, nil
+}
+
+// selectorMatches reports whether doc has every selector field with the
+// selector's value.
+func selectorMatches(selector, doc map[string]interface{}) bool {
+	for field, want := range selector {
+		var got interface{} = doc
+		for _, part := range strings.Split(field, ".") {
+			object, ok := got.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			got = object[part]
+		}
+		if got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// sliceIterator iterates over query results held in memory.
+type sliceIterator struct {
+	kvs []*queryresult.KV
+}
+
+func (it *sliceIterator) HasNext() bool {
+	return len(it.kvs) > 0
+}
+
+func (it *sliceIterator) Next() (*queryresult.KV, error) {
+	if len(it.kvs) == 0 {
+		return nil, errors.New("no more query results")
+	}
+	kv := it.kvs[0]
+	it.kvs = it.kvs[1:]
+	return kv, nil
+}
+
+func (it *sliceIterator) Close() error {
+	return nil
+}
+
+// putTestSettings stores settings for their state directly.
+func (l *testLedger) putTestSettings(settings *RegistrySettings) {
+	l.t.Helper()
+	l.mustSubmit(newTestIdentity(l.t, "AdminOrgMSP", "admin", settings.StateCode), func(ctx contractapi.TransactionContextInterface) error {
+		return putSettings(ctx, settings)
+	})
+}
+
+// errorCode returns the code of a *ChaincodeError, or "" for nil.
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var coded *ChaincodeError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return "UNCODED: " + err.Error()
+}
+
+// expectCode fails the test unless err carries code.
+func expectCode(t *testing.T, err error, code string) {
+	t.Helper()
+	if got := errorCode(err); got != code {
+		t.Fatalf("expected %s, got %s (%v)", code, got, err)
+	}
+}
+
+// testAadhaarHash returns a well-formed aadhaarHash unique to n.
+func testAadhaarHash(n int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+// testProperty returns a valid single-owner land record in
+// TS-HYD-SRN-GCB with the given survey number.
+func testProperty(surveyNo string, owner int) *LandRecord {
+	loc := Location{StateCode: "TS", StateName: "Telangana", DistrictCode: "HYD", DistrictName: "Hyderabad",
+		TehsilCode: "SRN", TehsilName: "Serilingampally", VillageCode: "GCB", VillageName: "Gachibowli"}
+	return &LandRecord{
+		PropertyID:   "TS-HYD-SRN-GCB-" + surveyNo + "-0",
+		SurveyNumber: surveyNo,
+		Location:     loc,
+		Area:         Area{Value: 500, Unit: "SQ_METERS"},
+		LandUse:      "RESIDENTIAL",
+		CurrentOwner: OwnerInfo{
+			OwnerType: "INDIVIDUAL",
+			Owners: []Owner{{
+				AadhaarHash:     testAadhaarHash(owner),
+				Name:            fmt.Sprintf("Owner %d", owner),
+				SharePercentage: 100,
+			}},
+		},
+	}
+}
+
+// registerTestProperty registers property through RegisterProperty as
+// registrar.
+func (l *testLedger) registerTestProperty(registrar *testIdentity, property *LandRecord) {
+	l.t.Helper()
+	propertyJSON, err := json.Marshal(property)
+	if err != nil {
+		l.t.Fatalf("marshal property: %v", err)
+	}
+	l.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := l.contract.RegisterProperty(ctx, string(propertyJSON))
+		return err
+	})
+}
+
+// readProperty reads a land record back, outside any transaction.
+func (l *testLedger) readProperty(propertyID string) *LandRecord {
+	l.t.Helper()
+	var property *LandRecord
+	l.mustSubmit(newTestIdentity(l.t, "AdminOrgMSP", "admin", "IN"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		property, err = readLandRecord(ctx, propertyID)
+		return err
+	})
+	return property
+}
+
+// ============================================================
+// TRANSFER FLOW
+// ============================================================
+
+// testSigner is a citizen with an ECDSA signing key.
+type testSigner struct {
+	hash string
+	name string
+	key  *ecdsa.PrivateKey
+}
+
+// newTestSigner returns a signer whose aadhaarHash is testAadhaarHash(n).
+func newTestSigner(t *testing.T, n int) *testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return &testSigner{hash: testAadhaarHash(n), name: fmt.Sprintf("Owner %d", n), key: key}
+}
+
+// publicKeyPEM returns the signer's public key as a PUBLIC KEY block.
+func (s *testSigner) publicKeyPEM(t *testing.T) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// sign returns the signer's base64 signature over digest.
+func (s *testSigner) sign(t *testing.T, digest []byte) string {
+	t.Helper()
+	signature, err := ecdsa.SignASN1(rand.Reader, s.key, digest)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+// registerTestSigners registers each signer's key as registrar.
+func (l *testLedger) registerTestSigners(registrar *testIdentity, signers ...*testSigner) {
+	l.t.Helper()
+	for _, signer := range signers {
+		publicKeyPEM := signer.publicKeyPEM(l.t)
+		l.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+			return l.contract.RegisterSigningKey(ctx, signer.hash, publicKeyPEM, "ECDSA")
+		})
+	}
+}
+
+// testTransfer returns a sale of propertyID from seller to buyer for
+// ₹50 lakh, witnessed by witnesses.
+func testTransfer(propertyID string, seller, buyer *testSigner, witnesses ...*testSigner) *TransferRecord {
+	transfer := &TransferRecord{
+		PropertyID: propertyID,
+		Seller:     PartyInfo{AadhaarHash: seller.hash, Name: seller.name},
+		Buyer:      PartyInfo{AadhaarHash: buyer.hash, Name: buyer.name, OwnerType: "INDIVIDUAL"},
+		TransactionDetails: TransactionDetails{
+			SaleAmount:      500000000,
+			DeclaredValue:   500000000,
+			CircleRateValue: 400000000,
+			StampDutyAmount: 25000000,
+			RegistrationFee: 5000000,
+		},
+		Documents: Documents{SaleDeedHash: fmt.Sprintf("%064x", 0xdeed)},
+	}
+	for _, witness := range witnesses {
+		transfer.Witnesses = append(transfer.Witnesses, Witness{AadhaarHash: witness.hash, Name: witness.name})
+	}
+	return transfer
+}
+
+// initiateTestTransfer initiates transfer as registrar and returns its ID.
+func (l *testLedger) initiateTestTransfer(registrar *testIdentity, transfer *TransferRecord) string {
+	l.t.Helper()
+	transferJSON, err := json.Marshal(transfer)
+	if err != nil {
+		l.t.Fatalf("marshal transfer: %v", err)
+	}
+	var transferID string
+	l.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		transferID, err = l.contract.InitiateTransfer(ctx, string(transferJSON))
+		return err
+	})
+	return transferID
+}
+
+// readTransfer reads a transfer record back.
+func (l *testLedger) readTransfer(transferID string) *TransferRecord {
+	l.t.Helper()
+	var transfer TransferRecord
+	l.mustSubmit(newTestIdentity(l.t, "AdminOrgMSP", "admin", "IN"), func(ctx contractapi.TransactionContextInterface) error {
+		key, err := createTransferKey(ctx, transferID)
+		if err != nil {
+			return err
+		}
+		transferBytes, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(transferBytes, &transfer)
+	})
+	return &transfer
+}
+
+// signTestTransfer submits each signer's signature over the transfer
+// digest as registrar.
+func (l *testLedger) signTestTransfer(registrar *testIdentity, transferID string, signers ...*testSigner) {
+	l.t.Helper()
+	digest := transferDigest(l.readTransfer(transferID))
+	for _, signer := range signers {
+		signature := signer.sign(l.t, digest)
+		l.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+			return l.contract.SignTransfer(ctx, transferID, signer.hash, signature, "")
+		})
+	}
+}
+
+// executeTestTransfer calls ExecuteTransfer as registrar.
+func (l *testLedger) executeTestTransfer(registrar *testIdentity, transferID string) error {
+	return l.submit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		_, err := l.contract.ExecuteTransfer(ctx, transferID)
+		return err
+	})
+}
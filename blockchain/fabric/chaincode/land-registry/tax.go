@@ -0,0 +1,396 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LAND REVENUE TAX
+// ============================================================
+
+// revenueYearPattern matches a revenue year as "2024-25" or "2024-2025".
+var revenueYearPattern = regexp.MustCompile(`^(\d{4})-(\d{2}|\d{4})$`)
+
+// parseRevenueYear validates a revenue year and returns its starting
+// calendar year and canonical "YYYY-YY" form.
+func parseRevenueYear(year string) (int, string, error) {
+	m := revenueYearPattern.FindStringSubmatch(year)
+	if m == nil {
+		return 0, "", newError(ErrCodeValidationError, "revenue year '%s' must be in the form YYYY-YY", year)
+	}
+	start, _ := strconv.Atoi(m[1])
+	end, _ := strconv.Atoi(m[2])
+	if len(m[2]) == 2 {
+		end += (start / 100) * 100
+		if end < start {
+			end += 100
+		}
+	}
+	if end != start+1 {
+		return 0, "", newError(ErrCodeValidationError, "revenue year '%s' must span consecutive years", year)
+	}
+	return start, formatRevenueYear(start), nil
+}
+
+// formatRevenueYear formats the revenue year starting in start as "YYYY-YY".
+func formatRevenueYear(start int) string {
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+// revenueYearOf returns the start of the revenue year (April–March)
+// containing t.
+func revenueYearOf(t time.Time) int {
+	if t.Month() < time.April {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// RecordTaxPayment records a land revenue payment against a property.
+// paymentJSON is a TaxPayment with year, amount (paisa), receiptNumber
+// and paidDate (YYYY-MM-DD), and optionally the payer's Aadhaar hash as
+// payerHash. Once the payments for a year cover the annual land revenue,
+// PaidUpToYear advances to that year. The payment is also counted in
+// the district's digest for the day (see GetTaxPaymentDigest).
+// Only tehsildars and admins with jurisdiction over the property (see
+// requireJurisdiction) can record payments. Emits TAX_PAYMENT_RECORDED.
+func (s *LandRegistryContract) RecordTaxPayment(ctx contractapi.TransactionContextInterface, propertyID, paymentJSON string) error {
+	if _, err := requireAnyRole(ctx, "tehsildar", "admin"); err != nil {
+		return err
+	}
+
+	if err := validatePropertyID(propertyID); err != nil {
+		return err
+	}
+
+	var payment TaxPayment
+	if err := json.Unmarshal([]byte(paymentJSON), &payment); err != nil {
+		return newError(ErrCodeInvalidInput, "failed to parse tax payment JSON: %v", err)
+	}
+	yearStart, year, err := parseRevenueYear(payment.Year)
+	if err != nil {
+		return err
+	}
+	payment.Year = year
+	if payment.Amount <= 0 {
+		return newError(ErrCodeValidationError, "amount must be positive, got %d", payment.Amount)
+	}
+	if payment.ReceiptNumber == "" {
+		return newError(ErrCodeValidationError, "receiptNumber is required")
+	}
+	if _, err := time.Parse("2006-01-02", payment.PaidDate); err != nil {
+		return newError(ErrCodeValidationError, "paidDate must be YYYY-MM-DD")
+	}
+	if payment.PayerHash != "" {
+		if err := validateAadhaarHash(payment.PayerHash, "payerHash"); err != nil {
+			return err
+		}
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireJurisdiction(ctx, property.Location); err != nil {
+		return err
+	}
+	if err := requireNotArchived(property); err != nil {
+		return err
+	}
+
+	var paidForYear int64
+	for _, existing := range property.TaxPayments {
+		if existing.ReceiptNumber == payment.ReceiptNumber {
+			return newError(ErrCodeTaxReceiptDuplicate, "receipt %s already recorded as %s", payment.ReceiptNumber, existing.PaymentID)
+		}
+		if existing.Year == payment.Year {
+			paidForYear += existing.Amount
+		}
+	}
+	paidForYear += payment.Amount
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	payment.PaymentID = "tax_" + txID[:8]
+	payment.RecordedBy = getCallerID(ctx)
+	payment.RecordedAt = now
+	payment.FabricTxID = txID
+
+	property.TaxPayments = append(property.TaxPayments, payment)
+	if payment.PaidDate > property.TaxInfo.LastPaidDate {
+		property.TaxInfo.LastPaidDate = payment.PaidDate
+	}
+	if paidForYear >= property.TaxInfo.AnnualLandRevenue {
+		paidUpTo, _, err := parseRevenueYear(property.TaxInfo.PaidUpToYear)
+		if err != nil || yearStart > paidUpTo {
+			property.TaxInfo.PaidUpToYear = payment.Year
+		}
+	}
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return err
+	}
+	if err := addToTaxPaymentDigest(ctx, property.Location, &payment); err != nil {
+		return err
+	}
+
+	event := TaxPaymentRecordedEvent{
+		Type:          "TAX_PAYMENT_RECORDED",
+		PropertyID:    propertyID,
+		PaymentID:     payment.PaymentID,
+		Year:          payment.Year,
+		Amount:        payment.Amount,
+		ReceiptNumber: payment.ReceiptNumber,
+		PayerHash:     payment.PayerHash,
+		PaidUpToYear:  property.TaxInfo.PaidUpToYear,
+		FabricTxID:    txID,
+		Timestamp:     now,
+		StateCode:     property.Location.StateCode,
+		ChannelID:     ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "TAX_PAYMENT_RECORDED", event)
+}
+
+// GetTaxStatus returns a property's land revenue position as of the
+// given revenue year ("YYYY-YY"; empty means the year containing the
+// transaction timestamp). Years in arrears are the revenue years after
+// PaidUpToYear up to and including asOfYear.
+func (s *LandRegistryContract) GetTaxStatus(ctx contractapi.TransactionContextInterface, propertyID, asOfYear string) (*TaxStatus, error) {
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	return computeTaxStatus(ctx, property, asOfYear)
+}
+
+// computeTaxStatus implements GetTaxStatus for a loaded property.
+func computeTaxStatus(ctx contractapi.TransactionContextInterface, property *LandRecord, asOfYear string) (*TaxStatus, error) {
+	var asOf int
+	if asOfYear == "" {
+		timestamp, _ := ctx.GetStub().GetTxTimestamp()
+		asOf = revenueYearOf(time.Unix(timestamp.Seconds, 0).UTC())
+	} else {
+		start, _, err := parseRevenueYear(asOfYear)
+		if err != nil {
+			return nil, err
+		}
+		asOf = start
+	}
+
+	status := &TaxStatus{
+		PropertyID:        property.PropertyID,
+		AsOfYear:          formatRevenueYear(asOf),
+		AnnualLandRevenue: property.TaxInfo.AnnualLandRevenue,
+		PaidUpToYear:      property.TaxInfo.PaidUpToYear,
+		LastPaidDate:      property.TaxInfo.LastPaidDate,
+		Status:            "CURRENT",
+	}
+
+	if property.TaxInfo.PaidUpToYear == "" {
+		status.Status = "UNKNOWN"
+		return status, nil
+	}
+	paidUpTo, _, err := parseRevenueYear(property.TaxInfo.PaidUpToYear)
+	if err != nil {
+		status.Status = "UNKNOWN"
+		return status, nil
+	}
+
+	if asOf > paidUpTo {
+		status.YearsInArrears = asOf - paidUpTo
+		status.AmountInArrears = int64(status.YearsInArrears) * property.TaxInfo.AnnualLandRevenue
+		status.Status = "ARREARS"
+	}
+	return status, nil
+}
+
+// requireTaxClearance enforces the state's RequireTaxClearanceForTransfer
+// setting. When enabled, land revenue must be paid up to the previous
+// revenue year — the current year is not yet due — unless a
+// dues-clearance document hash is attached to the transfer. For a
+// disaster-affected parcel in a state relaxing TAX_CLEARANCE, a disaster
+// certificate reference on the transfer is accepted instead. States with
+// the setting off are not checked.
+func requireTaxClearance(ctx contractapi.TransactionContextInterface, property *LandRecord, transfer *TransferRecord) error {
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return err
+	}
+	if !settings.RequireTaxClearanceForTransfer {
+		return nil
+	}
+	if transfer.Documents.TaxClearanceHash != "" {
+		return validateDocumentHash(transfer.Documents.TaxClearanceHash, "documents.taxClearanceHash")
+	}
+	if transfer.DisasterCertificateRef != "" && disasterRelaxed(settings, property, "TAX_CLEARANCE") {
+		return nil
+	}
+
+	dueThrough := dueThroughYear(ctx)
+	status, err := computeTaxStatus(ctx, property, formatRevenueYear(dueThrough))
+	if err != nil {
+		return err
+	}
+
+	switch status.Status {
+	case "UNKNOWN":
+		return newError(ErrCodeTransferTaxDuesPending, "no land revenue payment recorded for %s; dues through %s must be paid or a clearance attached",
+			property.PropertyID, status.AsOfYear)
+	case "ARREARS":
+		var years []string
+		for y := dueThrough - status.YearsInArrears + 1; y <= dueThrough; y++ {
+			years = append(years, formatRevenueYear(y))
+		}
+		return newError(ErrCodeTransferTaxDuesPending, "land revenue outstanding for %d year(s): %s (%d paisa)",
+			status.YearsInArrears, strings.Join(years, ", "), status.AmountInArrears)
+	}
+	return nil
+}
+
+// dueThroughYear returns the start of the last revenue year whose land
+// revenue is due at the transaction time: the current year is not yet.
+func dueThroughYear(ctx contractapi.TransactionContextInterface) int {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	return revenueYearOf(time.Unix(timestamp.Seconds, 0).UTC()) - 1
+}
+
+// flagTaxArrears emits TAX_ARREARS_FLAGGED, listed under
+// taxArrearsFlagged, when a property being transferred has land revenue
+// outstanding for at least the state's TaxArrearsAlertYears, and
+// reports whether it did. Unlike RequireTaxClearanceForTransfer it never
+// blocks the transfer; states without a threshold are not checked.
+func flagTaxArrears(ctx contractapi.TransactionContextInterface, property *LandRecord, transferID string) (bool, error) {
+	settings, err := getSettings(ctx, property.Location.StateCode)
+	if err != nil {
+		return false, err
+	}
+	if settings.TaxArrearsAlertYears <= 0 {
+		return false, nil
+	}
+
+	status, err := computeTaxStatus(ctx, property, formatRevenueYear(dueThroughYear(ctx)))
+	if err != nil {
+		return false, err
+	}
+	if status.Status != "ARREARS" || status.YearsInArrears < settings.TaxArrearsAlertYears {
+		return false, nil
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := TaxArrearsFlaggedEvent{
+		Type:            "TAX_ARREARS_FLAGGED",
+		PropertyID:      property.PropertyID,
+		TransferID:      transferID,
+		PaidUpToYear:    status.PaidUpToYear,
+		DueThroughYear:  status.AsOfYear,
+		YearsInArrears:  status.YearsInArrears,
+		AmountInArrears: status.AmountInArrears,
+		ThresholdYears:  settings.TaxArrearsAlertYears,
+		FabricTxID:      ctx.GetStub().GetTxID(),
+		Timestamp:       time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:       property.Location.StateCode,
+		ChannelID:       ctx.GetStub().GetChannelID(),
+	}
+	return true, emitRelatedEvent(ctx, "TAX_ARREARS_FLAGGED", "taxArrearsFlagged", event, event)
+}
+
+// withdrawTaxArrearsFlag emits TAX_ARREARS_FLAG_WITHDRAWN, listed under
+// taxArrearsFlagsWithdrawn, when a transfer that flagged arrears is
+// rolled back, so the treasury feed drops the alert it raised.
+func withdrawTaxArrearsFlag(ctx contractapi.TransactionContextInterface, property *LandRecord, transferID string) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	event := TaxArrearsFlagWithdrawnEvent{
+		Type:       "TAX_ARREARS_FLAG_WITHDRAWN",
+		PropertyID: property.PropertyID,
+		TransferID: transferID,
+		FabricTxID: ctx.GetStub().GetTxID(),
+		Timestamp:  time.Unix(timestamp.Seconds, 0).Format(time.RFC3339),
+		StateCode:  property.Location.StateCode,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	return emitRelatedEvent(ctx, "TAX_ARREARS_FLAG_WITHDRAWN", "taxArrearsFlagsWithdrawn", event, event)
+}
+
+// GetTaxPaymentDigest returns the count and total of the land revenue
+// payments recorded in a district on date (YYYY-MM-DD, the recording
+// date). A day with no payments has a zero digest. Only tehsildars and
+// admins with access to the state can query.
+func (s *LandRegistryContract) GetTaxPaymentDigest(ctx contractapi.TransactionContextInterface, stateCode, districtCode, date string) (*TaxPaymentDigest, error) {
+	if _, err := requireAnyRole(ctx, "tehsildar", "admin"); err != nil {
+		return nil, err
+	}
+	if stateCode == "" || districtCode == "" {
+		return nil, newError(ErrCodeValidationError, "stateCode and districtCode are required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, newError(ErrCodeValidationError, "date must be YYYY-MM-DD")
+	}
+
+	digest, _, err := getTaxPaymentDigest(ctx, stateCode, districtCode, date)
+	return digest, err
+}
+
+// addToTaxPaymentDigest counts a recorded payment in its district's
+// digest for the transaction date.
+func addToTaxPaymentDigest(ctx contractapi.TransactionContextInterface, location Location, payment *TaxPayment) error {
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	date := time.Unix(timestamp.Seconds, 0).UTC().Format("2006-01-02")
+
+	digest, key, err := getTaxPaymentDigest(ctx, location.StateCode, location.DistrictCode, date)
+	if err != nil {
+		return err
+	}
+	digest.PaymentCount++
+	digest.TotalAmount += payment.Amount
+	digest.LastPaymentID = payment.PaymentID
+	digest.LastFabricTxID = payment.FabricTxID
+
+	digestBytes, err := canonicalMarshal(digest)
+	if err != nil {
+		return internalError("failed to marshal tax payment digest: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, digestBytes); err != nil {
+		return internalError("failed to write tax payment digest: %v", err)
+	}
+	return nil
+}
+
+// getTaxPaymentDigest loads a district's digest for date, or a zero one,
+// along with its key.
+func getTaxPaymentDigest(ctx contractapi.TransactionContextInterface, stateCode, districtCode, date string) (*TaxPaymentDigest, string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixTaxDigest, []string{stateCode, districtCode, date})
+	if err != nil {
+		return nil, "", internalError("failed to create tax payment digest key: %v", err)
+	}
+	digestBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, "", internalError("failed to read tax payment digest: %v", err)
+	}
+	digest := &TaxPaymentDigest{
+		DocType:      "taxPaymentDigest",
+		StateCode:    stateCode,
+		DistrictCode: districtCode,
+		Date:         date,
+	}
+	if digestBytes != nil {
+		if err := json.Unmarshal(digestBytes, digest); err != nil {
+			return nil, "", internalError("failed to unmarshal tax payment digest: %v", err)
+		}
+	}
+	return digest, key, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// taxTestTransfer registers a TS property with land revenue paid up to
+// paidUpToYear and takes a sale of it to SIGNATURES_COMPLETE. With
+// requireClearance the state turns RequireTaxClearanceForTransfer on.
+func taxTestTransfer(t *testing.T, paidUpToYear string, requireClearance bool, clearanceHash string) (*testLedger, *testIdentity, string) {
+	t.Helper()
+	ledger := newTestLedger(t)
+	if requireClearance {
+		ledger.putTestSettings(&RegistrySettings{DocType: "registrySettings", StateCode: "TS", RequireTaxClearanceForTransfer: true})
+	}
+	registrar := newTestIdentity(t, "TelanganaMSP", "registrar", "TS")
+
+	seller, buyer := newTestSigner(t, 1), newTestSigner(t, 2)
+	witness1, witness2 := newTestSigner(t, 3), newTestSigner(t, 4)
+	property := testProperty("142", 1)
+	property.TaxInfo.AnnualLandRevenue = 120000
+	property.TaxInfo.PaidUpToYear = paidUpToYear
+	ledger.registerTestProperty(registrar, property)
+	ledger.registerTestSigners(registrar, seller, buyer, witness1, witness2)
+
+	transfer := testTransfer(property.PropertyID, seller, buyer, witness1, witness2)
+	transfer.Documents.TaxClearanceHash = clearanceHash
+	transferID := ledger.initiateTestTransfer(registrar, transfer)
+	ledger.signTestTransfer(registrar, transferID, seller, buyer, witness1, witness2)
+	return ledger, registrar, transferID
+}
+
+func TestTaxClearanceBoundaryYear(t *testing.T) {
+	tests := []struct {
+		name         string
+		now          time.Time
+		paidUpToYear string
+		want         string
+		outstanding  string
+	}{
+		// On 15 March 2027 the 2026-27 revenue is not yet due
+		{"paid through previous year", testTime, "2025-26", "", ""},
+		{"one year outstanding", testTime, "2024-25", ErrCodeTransferTaxDuesPending, "2025-26"},
+		{"two years outstanding", testTime, "2023-24", ErrCodeTransferTaxDuesPending, "2024-25, 2025-26"},
+		// The last day of the revenue year, still 2026-27
+		{"last day of the year", time.Date(2027, 3, 31, 23, 59, 59, 0, time.UTC), "2025-26", "", ""},
+		// From 1 April 2027 the 2026-27 revenue falls due
+		{"first day of the next year", time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC), "2025-26", ErrCodeTransferTaxDuesPending, "2026-27"},
+		{"next year paid", time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC), "2026-27", "", ""},
+		{"no payment recorded", testTime, "", ErrCodeTransferTaxDuesPending, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ledger, registrar, transferID := taxTestTransfer(t, tc.paidUpToYear, true, "")
+			ledger.now = tc.now
+			err := ledger.executeTestTransfer(registrar, transferID)
+			expectCode(t, err, tc.want)
+			if tc.outstanding != "" && !strings.Contains(err.Error(), tc.outstanding) {
+				t.Fatalf("error %q does not list %s", err, tc.outstanding)
+			}
+		})
+	}
+}
+
+func TestTaxClearanceDocumentStandsInForPayment(t *testing.T) {
+	ledger, registrar, transferID := taxTestTransfer(t, "2020-21", true, strings.Repeat("ab", 32))
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer with a clearance hash: %v", err)
+	}
+}
+
+func TestTaxClearanceRejectsMalformedDocumentHash(t *testing.T) {
+	ledger, registrar, transferID := taxTestTransfer(t, "2025-26", true, "not-a-hash")
+	expectCode(t, ledger.executeTestTransfer(registrar, transferID), ErrCodeValidationError)
+}
+
+func TestTaxClearanceOffLeavesArrearsUnchecked(t *testing.T) {
+	ledger, registrar, transferID := taxTestTransfer(t, "2015-16", false, "")
+	if err := ledger.executeTestTransfer(registrar, transferID); err != nil {
+		t.Fatalf("ExecuteTransfer with the setting off: %v", err)
+	}
+	if got := ledger.readTransfer(transferID).Status; got != "REGISTERED_PENDING_FINALITY" {
+		t.Fatalf("transfer status = %s, want REGISTERED_PENDING_FINALITY", got)
+	}
+}
+
+func TestRevenueYearOf(t *testing.T) {
+	tests := map[time.Time]int{
+		time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC):     2026,
+		time.Date(2027, 3, 31, 23, 59, 0, 0, time.UTC):  2026,
+		time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC):     2027,
+		time.Date(2027, 12, 31, 23, 59, 0, 0, time.UTC): 2027,
+	}
+	for at, want := range tests {
+		if got := revenueYearOf(at); got != want {
+			t.Errorf("revenueYearOf(%s) = %d, want %d", at.Format(time.RFC3339), got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"regexp"
+)
+
+// ============================================================
+// LAND CLASSIFICATION AND PIN CODE VALIDATION
+// ============================================================
+
+// landClassifications is the built-in land classification vocabulary.
+// States add local classes through RegistrySettings.ExtraLandClassifications.
+var landClassifications = map[string]bool{
+	"IRRIGATED":     true,
+	"IRRIGATED_WET": true,
+	"IRRIGATED_DRY": true,
+	"UNIRRIGATED":   true,
+	"RAIN_FED":      true,
+	"ORCHARD":       true,
+	"GARDEN":        true,
+	"PLANTATION":    true,
+	"HOMESTEAD":     true,
+	"PASTURE":       true,
+	"FOREST":        true,
+	"WASTELAND":     true,
+	"BARREN":        true,
+	"URBAN":         true,
+}
+
+// validateLandClassification checks a land classification against the
+// built-in vocabulary and the state's extra classes. An empty
+// classification is allowed (unclassified land).
+func validateLandClassification(classification string, settings *RegistrySettings) error {
+	if classification == "" || landClassifications[classification] {
+		return nil
+	}
+	for _, extra := range settings.ExtraLandClassifications {
+		if classification == extra {
+			return nil
+		}
+	}
+	return newError(ErrCodeValidationError, "landClassification '%s' is not a recognised classification", classification)
+}
+
+// pinCodePattern matches a 6-digit Indian postal index number.
+var pinCodePattern = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+
+// statePostalZones maps a state code to the first digits of the PIN
+// codes (postal zones) that serve it.
+var statePostalZones = map[string]string{
+	"DL": "1", "HR": "1", "PB": "1", "HP": "1", "JK": "1", "CH": "1", "LA": "1",
+	"UP": "2", "UK": "2", "UT": "2",
+	"RJ": "3", "GJ": "3", "DD": "3", "DN": "3",
+	"MH": "4", "GA": "4", "MP": "4", "CG": "4", "CT": "4",
+	"AP": "5", "TG": "5", "TS": "5", "KA": "5",
+	"TN": "6", "KL": "6", "PY": "6", "LD": "6",
+	"WB": "7", "OD": "7", "OR": "7", "AS": "7", "AR": "7", "MN": "7",
+	"ML": "7", "MZ": "7", "NL": "7", "TR": "7", "SK": "7", "AN": "7",
+	"BR": "8", "JH": "8",
+}
+
+// validatePinCode checks that a PIN code is 6 digits and that its
+// first digit is the postal zone of the property's state. An empty PIN
+// is allowed; states missing from statePostalZones get the format
+// check only.
+func validatePinCode(pinCode, stateCode string) error {
+	if pinCode == "" {
+		return nil
+	}
+	if !pinCodePattern.MatchString(pinCode) {
+		return newError(ErrCodeValidationError, "location.pinCode '%s' must be exactly 6 digits", pinCode)
+	}
+	if zone, ok := statePostalZones[stateCode]; ok && pinCode[:1] != zone {
+		return newError(ErrCodeValidationError, "location.pinCode '%s' is not in postal zone %s for state %s", pinCode, zone, stateCode)
+	}
+	return nil
+}
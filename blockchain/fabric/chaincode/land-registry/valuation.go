@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// VALUATIONS
+// ============================================================
+// A valuation is an assessed value of one parcel for a stated purpose:
+// a registered valuer's report for a loan, an auction reserve price, an
+// insurance valuation. It is neither the circle rate nor the last sale
+// price. Valuations are append-only; a revised value is a new entry.
+
+// valuationPurposes lists the purposes a valuation can be made for.
+var valuationPurposes = map[string]bool{
+	"LOAN":            true,
+	"AUCTION_RESERVE": true,
+	"INSURANCE":       true,
+	"COMPENSATION":    true,
+	"TAXATION":        true,
+	"COURT":           true,
+	"OTHER":           true,
+}
+
+// valuationMethods lists the valuation methods a valuer can cite.
+var valuationMethods = map[string]bool{
+	"SALES_COMPARISON": true,
+	"INCOME":           true,
+	"COST":             true,
+	"RESIDUAL":         true,
+	"OTHER":            true,
+}
+
+// RecordValuation appends a valuation to a property's history.
+// valuationJSON is a ValuationRecord with propertyId,
+// valuerRegistrationNumber, purpose (LOAN, AUCTION_RESERVE, INSURANCE,
+// COMPENSATION, TAXATION, COURT or OTHER), value in paisa, method
+// (SALES_COMPARISON, INCOME, COST, RESIDUAL or OTHER), reportHash and
+// valuationDate (YYYY-MM-DD, not after the transaction date). Banks,
+// registrars with jurisdiction over the property and admins can record
+// valuations. Emits VALUATION_RECORDED.
+func (s *LandRegistryContract) RecordValuation(ctx contractapi.TransactionContextInterface, propertyID, valuationJSON string) (string, error) {
+	role, err := requireAnyRole(ctx, "bank", "registrar", "admin")
+	if err != nil {
+		return "", err
+	}
+
+	var valuation ValuationRecord
+	if err := json.Unmarshal([]byte(valuationJSON), &valuation); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse valuation JSON: %v", err)
+	}
+	if valuation.PropertyID == "" {
+		valuation.PropertyID = propertyID
+	}
+	if valuation.PropertyID != propertyID {
+		return "", newError(ErrCodeValidationError, "valuation propertyId %s does not match %s", valuation.PropertyID, propertyID)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	txTime := time.Unix(timestamp.Seconds, 0)
+	if err := validateValuation(&valuation, txTime.Format("2006-01-02")); err != nil {
+		return "", err
+	}
+
+	property, err := s.GetProperty(ctx, propertyID)
+	if err != nil {
+		return "", err
+	}
+	if role == "registrar" {
+		if err := requireJurisdiction(ctx, property.Location); err != nil {
+			return "", err
+		}
+	}
+	if err := requireNotArchived(property); err != nil {
+		return "", err
+	}
+
+	txID := ctx.GetStub().GetTxID()
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
+	valuation.DocType = "valuationRecord"
+	valuation.ValuationID = "val_" + txID[:8]
+	valuation.RecordedBy = getCallerID(ctx)
+	valuation.RecordedByMspID = mspID
+	valuation.RecordedAt = txTime.Format(time.RFC3339)
+	valuation.FabricTxID = txID
+
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixValuation, []string{propertyID, valuation.ValuationID})
+	if err != nil {
+		return "", internalError("failed to create valuation key: %v", err)
+	}
+	valuationBytes, err := canonicalMarshal(valuation)
+	if err != nil {
+		return "", internalError("failed to marshal valuation: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, valuationBytes); err != nil {
+		return "", internalError("failed to write valuation %s: %v", valuation.ValuationID, err)
+	}
+
+	event := ValuationEvent{
+		Type:                     "VALUATION_RECORDED",
+		ValuationID:              valuation.ValuationID,
+		PropertyID:               propertyID,
+		Purpose:                  valuation.Purpose,
+		Value:                    valuation.Value,
+		Method:                   valuation.Method,
+		ValuerRegistrationNumber: valuation.ValuerRegistrationNumber,
+		ReportHash:               valuation.ReportHash,
+		ValuationDate:            valuation.ValuationDate,
+		RecordedByMspID:          mspID,
+		FabricTxID:               txID,
+		Timestamp:                valuation.RecordedAt,
+		StateCode:                property.Location.StateCode,
+		ChannelID:                ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "VALUATION_RECORDED", event); err != nil {
+		return "", err
+	}
+	return valuation.ValuationID, nil
+}
+
+// GetValuationHistory returns every valuation recorded on a property,
+// oldest first by valuation date. It is readable by whoever can read
+// the property.
+func (s *LandRegistryContract) GetValuationHistory(ctx contractapi.TransactionContextInterface, propertyID string) ([]*ValuationRecord, error) {
+	if _, err := s.GetProperty(ctx, propertyID); err != nil {
+		return nil, err
+	}
+	return getValuations(ctx, propertyID)
+}
+
+// validateValuation checks a valuation submitted to RecordValuation.
+// today is the transaction date (YYYY-MM-DD).
+func validateValuation(valuation *ValuationRecord, today string) error {
+	if err := validatePropertyID(valuation.PropertyID); err != nil {
+		return err
+	}
+	valuation.ValuerRegistrationNumber = strings.TrimSpace(valuation.ValuerRegistrationNumber)
+	if valuation.ValuerRegistrationNumber == "" {
+		return newError(ErrCodeValidationError, "valuerRegistrationNumber is required")
+	}
+	valuation.Purpose = strings.ToUpper(valuation.Purpose)
+	if !valuationPurposes[valuation.Purpose] {
+		return newError(ErrCodeValidationError, "purpose '%s' must be LOAN, AUCTION_RESERVE, INSURANCE, COMPENSATION, TAXATION, COURT or OTHER", valuation.Purpose)
+	}
+	valuation.Method = strings.ToUpper(valuation.Method)
+	if !valuationMethods[valuation.Method] {
+		return newError(ErrCodeValidationError, "method '%s' must be SALES_COMPARISON, INCOME, COST, RESIDUAL or OTHER", valuation.Method)
+	}
+	if valuation.Value <= 0 {
+		return newError(ErrCodeValidationError, "value must be positive")
+	}
+	if err := validateDocumentHash(valuation.ReportHash, "reportHash"); err != nil {
+		return err
+	}
+	if _, err := time.Parse("2006-01-02", valuation.ValuationDate); err != nil {
+		return newError(ErrCodeValidationError, "valuationDate must be YYYY-MM-DD")
+	}
+	if valuation.ValuationDate > today {
+		return newError(ErrCodeValidationError, "valuationDate must not be in the future")
+	}
+	return nil
+}
+
+// getValuations reads a property's valuations, oldest first by
+// valuation date and then by when they were recorded.
+func getValuations(ctx contractapi.TransactionContextInterface, propertyID string) ([]*ValuationRecord, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(KeyPrefixValuation, []string{propertyID})
+	if err != nil {
+		return nil, internalError("failed to query valuations: %v", err)
+	}
+	defer iterator.Close()
+
+	valuations := []*ValuationRecord{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, internalError("failed to iterate valuations: %v", err)
+		}
+		var valuation ValuationRecord
+		if err := json.Unmarshal(kv.Value, &valuation); err != nil {
+			return nil, internalError("failed to unmarshal valuation: %v", err)
+		}
+		valuations = append(valuations, &valuation)
+	}
+	sort.SliceStable(valuations, func(i, j int) bool {
+		if valuations[i].ValuationDate != valuations[j].ValuationDate {
+			return valuations[i].ValuationDate < valuations[j].ValuationDate
+		}
+		return valuations[i].RecordedAt < valuations[j].RecordedAt
+	})
+	return valuations, nil
+}
@@ -0,0 +1,485 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// WILLS AND PROBATE
+// ============================================================
+// A registered will records only that a testator has bequeathed their
+// interest in certain properties, with the will document's hash; its
+// contents stay off chain. After the testator's death a court probates
+// the will with ApplyProbate, naming the beneficiaries and their shares,
+// and the testator's interest in each property passes to them by an
+// INHERITANCE mutation (see applySuccession).
+
+// RegisterWill registers a will. willJSON is a WillRecord with
+// testatorHash, propertyIds (each currently owned in part or whole by
+// the testator), willDocumentHash and registrationInfo, and optionally
+// supersedesWillId, an earlier will of the same testator that it
+// replaces. Only registrars with access to every property's state can
+// register wills. Emits WILL_REGISTERED.
+func (s *LandRegistryContract) RegisterWill(ctx contractapi.TransactionContextInterface, willJSON string) (string, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return "", err
+	}
+
+	var will WillRecord
+	if err := json.Unmarshal([]byte(willJSON), &will); err != nil {
+		return "", newError(ErrCodeInvalidInput, "failed to parse will JSON: %v", err)
+	}
+	if err := validateAadhaarHash(will.TestatorHash, "testatorHash"); err != nil {
+		return "", err
+	}
+	if err := validateDocumentHash(will.WillDocumentHash, "willDocumentHash"); err != nil {
+		return "", err
+	}
+	if len(will.PropertyIDs) == 0 {
+		return "", newError(ErrCodeValidationError, "propertyIds must name at least one property")
+	}
+	seen := map[string]bool{}
+	for i, propertyID := range will.PropertyIDs {
+		if seen[propertyID] {
+			return "", newError(ErrCodeValidationError, "propertyIds[%d] duplicates %s", i, propertyID)
+		}
+		seen[propertyID] = true
+		property, err := s.GetProperty(ctx, propertyID)
+		if err != nil {
+			return "", err
+		}
+		if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+			return "", err
+		}
+		if ownerIndex(property, will.TestatorHash) < 0 {
+			return "", newError(ErrCodeOwnerNotFound, "testator is not a current owner of %s", propertyID)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	will.DocType = "willRecord"
+	will.WillID = "wil_" + txID[:8]
+	will.Status = "ACTIVE"
+	will.RegisteredBy = getCallerID(ctx)
+	will.RegisteredAt = now
+	will.FabricTxID = txID
+
+	if will.SupersedesWillID != "" {
+		previous, err := getWill(ctx, will.SupersedesWillID)
+		if err != nil {
+			return "", err
+		}
+		if previous.TestatorHash != will.TestatorHash {
+			return "", newError(ErrCodeValidationError, "will %s is another testator's", previous.WillID)
+		}
+		if previous.Status != "ACTIVE" {
+			return "", newError(ErrCodeWillNotActive, "will %s is %s", previous.WillID, previous.Status)
+		}
+		previous.Status = "SUPERSEDED"
+		previous.SupersededBy = will.WillID
+		previous.FabricTxID = txID
+		if err := putWill(ctx, previous); err != nil {
+			return "", err
+		}
+	}
+	if err := putWill(ctx, &will); err != nil {
+		return "", err
+	}
+
+	event := WillEvent{
+		Type:             "WILL_REGISTERED",
+		WillID:           will.WillID,
+		TestatorHash:     will.TestatorHash,
+		PropertyIDs:      will.PropertyIDs,
+		WillDocumentHash: will.WillDocumentHash,
+		SupersedesWillID: will.SupersedesWillID,
+		FabricTxID:       txID,
+		Timestamp:        now,
+		StateCode:        extractStateCode(will.PropertyIDs[0]),
+		ChannelID:        ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "WILL_REGISTERED", event); err != nil {
+		return "", err
+	}
+	return will.WillID, nil
+}
+
+// RevokeWill revokes an active will, e.g. on the testator's registered
+// revocation. A will replaced by a later one is superseded through
+// RegisterWill's supersedesWillId instead. Only registrars can revoke
+// wills. Writes an audit entry and emits WILL_REVOKED.
+func (s *LandRegistryContract) RevokeWill(ctx contractapi.TransactionContextInterface, willID, reason string) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return err
+	}
+	if reason == "" {
+		return newError(ErrCodeValidationError, "reason is required")
+	}
+
+	will, err := getWill(ctx, willID)
+	if err != nil {
+		return err
+	}
+	if err := requireStateAccess(ctx, extractStateCode(will.PropertyIDs[0])); err != nil {
+		return err
+	}
+	if will.Status != "ACTIVE" {
+		return newError(ErrCodeWillNotActive, "will %s is %s", willID, will.Status)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	will.Status = "REVOKED"
+	will.RevokeReason = reason
+	will.FabricTxID = txID
+	if err := putWill(ctx, will); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, "RevokeWill", willID); err != nil {
+		return err
+	}
+
+	event := WillEvent{
+		Type:             "WILL_REVOKED",
+		WillID:           will.WillID,
+		TestatorHash:     will.TestatorHash,
+		PropertyIDs:      will.PropertyIDs,
+		WillDocumentHash: will.WillDocumentHash,
+		Reason:           reason,
+		FabricTxID:       txID,
+		Timestamp:        now,
+		StateCode:        extractStateCode(will.PropertyIDs[0]),
+		ChannelID:        ctx.GetStub().GetChannelID(),
+	}
+	return emitEvent(ctx, "WILL_REVOKED", event)
+}
+
+// ApplyProbate applies a court's probate of an active will.
+// beneficiariesJSON is a list of Owner: the beneficiaries, with shares
+// of the testator's interest summing to 100, and guardians for minors.
+// For each property the will covers, the testator's share passes to the
+// beneficiaries through an INHERITANCE mutation; a property now under
+// dispute, frozen, archived or in transfer fails the whole probate.
+// Only courts with access to the properties' state can apply probate.
+// Emits PROBATE_APPLIED, listing the mutations.
+func (s *LandRegistryContract) ApplyProbate(ctx contractapi.TransactionContextInterface, willID, probateOrderRef, beneficiariesJSON string) (*Receipt, error) {
+	if err := requireRole(ctx, "court"); err != nil {
+		return nil, err
+	}
+	if probateOrderRef == "" {
+		return nil, newError(ErrCodeValidationError, "probateOrderRef is required")
+	}
+
+	will, err := getWill(ctx, willID)
+	if err != nil {
+		return nil, err
+	}
+	if will.Status != "ACTIVE" {
+		return nil, newError(ErrCodeWillNotActive, "will %s is %s", willID, will.Status)
+	}
+
+	var beneficiaries []Owner
+	if err := json.Unmarshal([]byte(beneficiariesJSON), &beneficiaries); err != nil {
+		return nil, newError(ErrCodeInvalidInput, "failed to parse beneficiaries JSON: %v", err)
+	}
+	if err := validateHeirs(beneficiaries, will.TestatorHash, "beneficiaries"); err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	receipt := newReceipt(ctx, willID, "PROBATED")
+	var mutationIDs []string
+	for i, propertyID := range will.PropertyIDs {
+		property, err := s.GetProperty(ctx, propertyID)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireStateAccess(ctx, property.Location.StateCode); err != nil {
+			return nil, err
+		}
+		mutation, err := applySuccession(ctx, property, will.TestatorHash, beneficiaries, successionDetails{
+			MutationID:    fmt.Sprintf("mut_%s_%d", txID[:8], i+1),
+			DeedHash:      will.WillDocumentHash,
+			CourtOrderRef: probateOrderRef,
+			WillID:        willID,
+		})
+		if err != nil {
+			return nil, errorAt(propertyID, err)
+		}
+		mutationIDs = append(mutationIDs, mutation.MutationID)
+		receipt.relate("mutation", mutation.MutationID, mutation.Status).
+			relate("property", propertyID, property.Status)
+	}
+
+	will.Status = "PROBATED"
+	will.ProbateOrderRef = probateOrderRef
+	will.ProbatedBy = getCallerID(ctx)
+	will.ProbatedAt = now
+	will.FabricTxID = txID
+	if err := putWill(ctx, will); err != nil {
+		return nil, err
+	}
+
+	event := ProbateAppliedEvent{
+		Type:            "PROBATE_APPLIED",
+		WillID:          willID,
+		TestatorHash:    will.TestatorHash,
+		ProbateOrderRef: probateOrderRef,
+		PropertyIDs:     will.PropertyIDs,
+		MutationIDs:     mutationIDs,
+		Beneficiaries:   ownerShares(beneficiaries),
+		FabricTxID:      txID,
+		Timestamp:       now,
+		StateCode:       extractStateCode(will.PropertyIDs[0]),
+		ChannelID:       ctx.GetStub().GetChannelID(),
+	}
+	if err := emitEvent(ctx, "PROBATE_APPLIED", event); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// GetWill returns a registered will by ID.
+func (s *LandRegistryContract) GetWill(ctx contractapi.TransactionContextInterface, willID string) (*WillRecord, error) {
+	if _, err := requireAnyRole(ctx, "registrar", "court", "admin"); err != nil {
+		return nil, err
+	}
+	return getWill(ctx, willID)
+}
+
+// successionDetails describes how an inheritance is recorded: the mutation's
+// ID and the instruments it rests on.
+type successionDetails struct {
+	MutationID    string
+	DeedHash      string
+	CourtOrderRef string
+	WillID        string
+	CertificateID string
+}
+
+// validateHeirs checks the people a deceased owner's interest passes
+// to: at least one, none of them the deceased, and shares of that
+// interest summing to 100, with Aadhaar hashes and minors' guardians as
+// for any owner.
+func validateHeirs(heirs []Owner, deceasedHash, field string) error {
+	if len(heirs) == 0 {
+		return newError(ErrCodeValidationError, "%s must name at least one heir", field)
+	}
+	for i, heir := range heirs {
+		if heir.AadhaarHash == deceasedHash {
+			return newError(ErrCodeValidationError, "%s[%d] is the deceased owner", field, i)
+		}
+	}
+	if err := validateOwnership(heirs); err != nil {
+		return errorAt(field, err)
+	}
+	return nil
+}
+
+// applySuccession passes a deceased owner's share of a property to
+// heirs, in proportion to their shares of it; an heir who already
+// co-owns the property has the inherited share added to theirs. The
+// property must be free of disputes and investigation holds, not
+// frozen, archived or in transfer. Writes the property, the owner indexes and an
+// AUTO_APPROVED INHERITANCE mutation, listed under mutationsCreated.
+func applySuccession(ctx contractapi.TransactionContextInterface, property *LandRecord, deceasedHash string, heirs []Owner, succession successionDetails) (*MutationRecord, error) {
+	if property.DisputeStatus != "CLEAR" {
+		return nil, newError(ErrCodeLandDisputed, "property %s has an active dispute", property.PropertyID)
+	}
+	if property.Status == "FROZEN" {
+		return nil, newError(ErrCodeLandFrozen, "property %s is frozen by court order", property.PropertyID)
+	}
+	if err := requireNoInvestigationHold(property); err != nil {
+		return nil, err
+	}
+	if property.Status == "TRANSFER_IN_PROGRESS" {
+		return nil, newError(ErrCodeTransferInProgress, "property %s has an active transfer", property.PropertyID)
+	}
+	if err := requireNotArchived(property); err != nil {
+		return nil, err
+	}
+	index := ownerIndex(property, deceasedHash)
+	if index < 0 {
+		return nil, newError(ErrCodeOwnerNotFound, "deceased is not a current owner of %s", property.PropertyID)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	deceased := property.CurrentOwner.Owners[index]
+	owners := make([]Owner, 0, len(property.CurrentOwner.Owners)+len(heirs))
+	owners = append(owners, property.CurrentOwner.Owners[:index]...)
+	owners = append(owners, property.CurrentOwner.Owners[index+1:]...)
+
+	// Integer rounding leftovers go to the first heir
+	assigned := 0
+	inherited := make([]int, len(heirs))
+	for i, heir := range heirs {
+		inherited[i] = deceased.SharePercentage * heir.SharePercentage / 100
+		assigned += inherited[i]
+	}
+	inherited[0] += deceased.SharePercentage - assigned
+
+	var added []Owner
+	for i, heir := range heirs {
+		if inherited[i] == 0 {
+			continue
+		}
+		merged := false
+		for j := range owners {
+			if owners[j].AadhaarHash == heir.AadhaarHash {
+				owners[j].SharePercentage += inherited[i]
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			heir.SharePercentage = inherited[i]
+			owners = append(owners, heir)
+			added = append(added, heir)
+		}
+	}
+	if err := validateOwnership(owners); err != nil {
+		return nil, err
+	}
+
+	property.CurrentOwner.Owners = owners
+	if len(owners) == 1 {
+		property.CurrentOwner.OwnershipType = "SOLE"
+	} else {
+		property.CurrentOwner.OwnershipType = "JOINT"
+	}
+	if err := validateOwnerInfo(&property.CurrentOwner); err != nil {
+		return nil, err
+	}
+	if len(property.CurrentOwner.Owners) == len(added) {
+		property.CurrentOwner.AcquisitionType = "INHERITANCE"
+		property.CurrentOwner.AcquisitionDate = now[:10]
+		property.CurrentOwner.AcquisitionDocumentHash = succession.DeedHash
+	}
+	property.UpdatedAt = now
+	property.UpdatedBy = getCallerID(ctx)
+	property.Provenance.Sequence++
+	property.FabricTxID = txID
+	if err := putLandRecord(ctx, property); err != nil {
+		return nil, err
+	}
+
+	if err := deleteOwnerIndex(ctx, deceased.AadhaarHash, property.PropertyID); err != nil {
+		return nil, internalError("failed to delete owner index: %v", err)
+	}
+	_ = deleteEntityIndex(ctx, deceased, property.PropertyID)
+	for _, heir := range added {
+		if err := putOwnerIndex(ctx, heir.AadhaarHash, property.PropertyID); err != nil {
+			return nil, internalError("failed to create owner index: %v", err)
+		}
+		_ = putEntityIndex(ctx, heir, property.PropertyID)
+	}
+
+	first := heirs[0]
+	mutation := &MutationRecord{
+		DocType:    "mutationRecord",
+		MutationID: succession.MutationID,
+		PropertyID: property.PropertyID,
+		Type:       "INHERITANCE",
+		PreviousOwner: OwnerRef{
+			AadhaarHash: deceased.AadhaarHash,
+			Name:        deceased.Name,
+		},
+		NewOwner: OwnerRef{
+			AadhaarHash: first.AadhaarHash,
+			Name:        first.Name,
+			IsMinor:     first.IsMinor,
+			Guardian:    first.Guardian,
+			OwnerType:   property.CurrentOwner.OwnerType,
+		},
+		Heirs:                ownerShares(heirs),
+		Status:               "AUTO_APPROVED",
+		ApprovedBy:           getCallerID(ctx),
+		ApprovedAt:           now,
+		RevenueRecordUpdated: true,
+		DeedHash:             succession.DeedHash,
+		CourtOrderRef:        succession.CourtOrderRef,
+		WillID:               succession.WillID,
+		CertificateID:        succession.CertificateID,
+		CreatedAt:            now,
+	}
+	mutationKey, err := createMutationKey(ctx, mutation.MutationID)
+	if err != nil {
+		return nil, internalError("failed to create mutation key: %v", err)
+	}
+	mutationBytes, err := canonicalMarshal(mutation)
+	if err != nil {
+		return nil, internalError("failed to marshal mutation: %v", err)
+	}
+	if err := ctx.GetStub().PutState(mutationKey, mutationBytes); err != nil {
+		return nil, internalError("failed to create mutation record: %v", err)
+	}
+	if err := emitMutationCreated(ctx, mutation, property.Location.StateCode); err != nil {
+		return nil, err
+	}
+	return mutation, nil
+}
+
+// ownerIndex returns the position of aadhaarHash among a property's
+// current owners, or -1.
+func ownerIndex(property *LandRecord, aadhaarHash string) int {
+	for i, owner := range property.CurrentOwner.Owners {
+		if owner.AadhaarHash == aadhaarHash {
+			return i
+		}
+	}
+	return -1
+}
+
+// getWill reads a will by ID.
+func getWill(ctx contractapi.TransactionContextInterface, willID string) (*WillRecord, error) {
+	if willID == "" {
+		return nil, newError(ErrCodeValidationError, "willId is required")
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixWill, []string{willID})
+	if err != nil {
+		return nil, internalError("failed to create will key: %v", err)
+	}
+	willBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, internalError("failed to read will: %v", err)
+	}
+	if willBytes == nil {
+		return nil, newError(ErrCodeWillNotFound, "%s does not exist", willID).with("willId", willID)
+	}
+	var will WillRecord
+	if err := json.Unmarshal(willBytes, &will); err != nil {
+		return nil, internalError("failed to unmarshal will: %v", err)
+	}
+	return &will, nil
+}
+
+// putWill writes a will under its key.
+func putWill(ctx contractapi.TransactionContextInterface, will *WillRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(KeyPrefixWill, []string{will.WillID})
+	if err != nil {
+		return internalError("failed to create will key: %v", err)
+	}
+	willBytes, err := canonicalMarshal(will)
+	if err != nil {
+		return internalError("failed to marshal will: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, willBytes); err != nil {
+		return internalError("failed to write will %s: %v", will.WillID, err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// AMNESTY SCHEMES
+// ============================================================
+// States periodically waive part of the interest on deficit stamp duty
+// for a limited window. An AmnestyScheme names the window, the waiver
+// percentage and the instrument types it covers; a demand settled while
+// a scheme covering its instrument is in force has the waiver applied.
+// Schemes stop applying by themselves once the window closes.
+
+// SetAmnestyScheme creates or replaces a state's amnesty scheme.
+// schemeJSON is an AmnestyScheme with schemeId, startDate and endDate
+// (YYYY-MM-DD, both inclusive), waiverPercentage (1-100) and
+// instrumentTypes. A scheme whose window overlaps another scheme of the
+// state covering any of the same instrument types is rejected. Only
+// admins can set schemes.
+func (s *StampDutyContract) SetAmnestyScheme(ctx contractapi.TransactionContextInterface, stateCode, schemeJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+
+	var scheme AmnestyScheme
+	if err := json.Unmarshal([]byte(schemeJSON), &scheme); err != nil {
+		return fmt.Errorf("INVALID_INPUT: failed to parse amnesty scheme JSON: %v", err)
+	}
+	scheme.SchemeID = strings.TrimSpace(scheme.SchemeID)
+	if scheme.SchemeID == "" {
+		return fmt.Errorf("VALIDATION_ERROR: schemeId is required")
+	}
+	start, err := time.Parse("2006-01-02", scheme.StartDate)
+	if err != nil {
+		return fmt.Errorf("VALIDATION_ERROR: startDate must be YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", scheme.EndDate)
+	if err != nil {
+		return fmt.Errorf("VALIDATION_ERROR: endDate must be YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("VALIDATION_ERROR: endDate %s is before startDate %s", scheme.EndDate, scheme.StartDate)
+	}
+	if scheme.WaiverPercentage < 1 || scheme.WaiverPercentage > 100 {
+		return fmt.Errorf("VALIDATION_ERROR: waiverPercentage must be between 1 and 100")
+	}
+	if len(scheme.InstrumentTypes) == 0 {
+		return fmt.Errorf("VALIDATION_ERROR: at least one instrument type is required")
+	}
+	seen := make(map[string]bool, len(scheme.InstrumentTypes))
+	for i, instrument := range scheme.InstrumentTypes {
+		instrument = strings.ToUpper(strings.TrimSpace(instrument))
+		if instrument == "" {
+			return fmt.Errorf("VALIDATION_ERROR: instrument type %d is empty", i)
+		}
+		if seen[instrument] {
+			return fmt.Errorf("VALIDATION_ERROR: duplicate instrument type %s", instrument)
+		}
+		seen[instrument] = true
+		scheme.InstrumentTypes[i] = instrument
+	}
+
+	existing, err := s.GetAmnestySchemes(ctx, stateCode)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.SchemeID == scheme.SchemeID {
+			continue
+		}
+		if other.StartDate > scheme.EndDate || scheme.StartDate > other.EndDate {
+			continue
+		}
+		for _, instrument := range other.InstrumentTypes {
+			if seen[instrument] {
+				return fmt.Errorf("AMNESTY_OVERLAP: scheme %s already covers %s from %s to %s", other.SchemeID, instrument, other.StartDate, other.EndDate)
+			}
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	scheme.DocType = "amnestyScheme"
+	scheme.StateCode = stateCode
+	scheme.SetBy = getCallerID(ctx)
+	scheme.UpdatedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	scheme.FabricTxID = ctx.GetStub().GetTxID()
+
+	key, err := ctx.GetStub().CreateCompositeKey("AMNESTY", []string{stateCode, scheme.SchemeID})
+	if err != nil {
+		return fmt.Errorf("failed to create amnesty scheme key: %v", err)
+	}
+	schemeBytes, err := json.Marshal(scheme)
+	if err != nil {
+		return fmt.Errorf("failed to marshal amnesty scheme: %v", err)
+	}
+	return ctx.GetStub().PutState(key, schemeBytes)
+}
+
+// GetAmnestySchemes returns a state's amnesty schemes, past and
+// future, ordered by start date.
+func (s *StampDutyContract) GetAmnestySchemes(ctx contractapi.TransactionContextInterface, stateCode string) ([]*AmnestyScheme, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("AMNESTY", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query amnesty schemes: %v", err)
+	}
+	defer iterator.Close()
+
+	schemes := []*AmnestyScheme{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate amnesty schemes: %v", err)
+		}
+		var scheme AmnestyScheme
+		if err := json.Unmarshal(kv.Value, &scheme); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal amnesty scheme: %v", err)
+		}
+		schemes = append(schemes, &scheme)
+	}
+	sort.SliceStable(schemes, func(i, j int) bool { return schemes[i].StartDate < schemes[j].StartDate })
+	return schemes, nil
+}
+
+// activeAmnestyScheme returns the state's scheme covering
+// instrumentType on the date of at, or nil if none is in force.
+func (s *StampDutyContract) activeAmnestyScheme(ctx contractapi.TransactionContextInterface, stateCode, instrumentType string, at time.Time) (*AmnestyScheme, error) {
+	schemes, err := s.GetAmnestySchemes(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+	date := at.UTC().Format("2006-01-02")
+	for _, scheme := range schemes {
+		if date < scheme.StartDate || date > scheme.EndDate {
+			continue
+		}
+		for _, instrument := range scheme.InstrumentTypes {
+			if instrument == instrumentType {
+				return scheme, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// applyAmnesty recomputes a demand's waiver for the scheme in force at
+// at, clearing it if no scheme covers the demand's instrument then.
+func (s *StampDutyContract) applyAmnesty(ctx contractapi.TransactionContextInterface, demand *DemandRecord, at time.Time) error {
+	scheme, err := s.activeAmnestyScheme(ctx, demand.StateCode, demand.InstrumentType, at)
+	if err != nil {
+		return err
+	}
+	demand.AmnestySchemeID = ""
+	demand.PenaltyWaived = 0
+	if scheme != nil {
+		demand.AmnestySchemeID = scheme.SchemeID
+		demand.PenaltyWaived = (demand.InterestAmount * int64(scheme.WaiverPercentage)) / 100
+	}
+	demand.TotalDemand = demand.DeficitAmount + demand.InterestAmount - demand.PenaltyWaived
+	return nil
+}
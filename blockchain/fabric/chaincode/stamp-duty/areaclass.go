@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// AREA CLASSIFICATION (URBAN / RURAL / MUNICIPALITY)
+// ============================================================
+
+const (
+	areaClassUrban = "URBAN"
+	areaClassRural = "RURAL"
+)
+
+// areaClassPattern matches URBAN, RURAL, or a notified municipality
+// code (e.g. "MCGM", "KMC", "BBMP").
+var areaClassPattern = regexp.MustCompile(`^[A-Z0-9_-]{2,32}$`)
+
+// SetAreaClassification records whether a tehsil lies inside notified
+// municipal limits, so metro surcharges are resolved from the ledger
+// rather than trusted from the caller. areaClass is URBAN, RURAL, or a
+// municipality code with its own surcharge entry in StampDutyConfig.
+// Only users with the "admin" role can set classifications.
+func (s *StampDutyContract) SetAreaClassification(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode, areaClass string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" || districtCode == "" || tehsilCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and tehsilCode are all required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+	class, err := normalizeAreaClass(areaClass)
+	if err != nil {
+		return err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	classification := AreaClassification{
+		DocType:      "areaClassification",
+		StateCode:    stateCode,
+		DistrictCode: districtCode,
+		TehsilCode:   tehsilCode,
+		AreaClass:    class,
+		UpdatedBy:    getCallerID(ctx),
+		UpdatedAt:    now,
+		FabricTxID:   txID,
+	}
+
+	// Composite key: AREA_CLASS~{stateCode}~{districtCode}~{tehsilCode}
+	key, err := ctx.GetStub().CreateCompositeKey("AREA_CLASS", []string{stateCode, districtCode, tehsilCode})
+	if err != nil {
+		return fmt.Errorf("failed to create area classification key: %v", err)
+	}
+
+	classBytes, err := json.Marshal(classification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal area classification: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, classBytes); err != nil {
+		return fmt.Errorf("failed to put area classification state: %v", err)
+	}
+
+	event := AreaClassificationChangedEvent{
+		Type:         "AREA_CLASSIFICATION_CHANGED",
+		StateCode:    stateCode,
+		DistrictCode: districtCode,
+		TehsilCode:   tehsilCode,
+		AreaClass:    class,
+		FabricTxID:   txID,
+		Timestamp:    now,
+		ChannelID:    ctx.GetStub().GetChannelID(),
+	}
+	eventJSON, _ := json.Marshal(event)
+	return ctx.GetStub().SetEvent("AREA_CLASSIFICATION_CHANGED", eventJSON)
+}
+
+// GetAreaClassification retrieves the recorded classification for a
+// tehsil. Returns an error if the tehsil has not been classified.
+func (s *StampDutyContract) GetAreaClassification(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string) (*AreaClassification, error) {
+	classification, err := s.getAreaClassification(ctx, stateCode, districtCode, tehsilCode)
+	if err != nil {
+		return nil, err
+	}
+	if classification == nil {
+		return nil, fmt.Errorf("AREA_CLASS_NOT_FOUND: no classification for %s/%s/%s", stateCode, districtCode, tehsilCode)
+	}
+	return classification, nil
+}
+
+// getAreaClassification returns the classification for a tehsil, or
+// nil if none has been recorded.
+func (s *StampDutyContract) getAreaClassification(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string) (*AreaClassification, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("AREA_CLASS", []string{stateCode, districtCode, tehsilCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create area classification key: %v", err)
+	}
+	classBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read area classification: %v", err)
+	}
+	if classBytes == nil {
+		return nil, nil
+	}
+	var classification AreaClassification
+	if err := json.Unmarshal(classBytes, &classification); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal area classification: %v", err)
+	}
+	return &classification, nil
+}
+
+// normalizeAreaClass upper-cases and validates an area class.
+func normalizeAreaClass(areaClass string) (string, error) {
+	class := strings.ToUpper(strings.TrimSpace(areaClass))
+	if !areaClassPattern.MatchString(class) {
+		return "", fmt.Errorf("VALIDATION_ERROR: areaClass must be URBAN, RURAL, or a municipality code, got '%s'", areaClass)
+	}
+	return class, nil
+}
+
+// resolveSurchargeBp returns the surcharge rate for an area class.
+// An explicit SurchargeByClass entry wins. Otherwise RURAL pays no
+// surcharge, and URBAN or an unlisted municipality pays the URBAN entry,
+// falling back to the state-level SurchargeBasisPts.
+func resolveSurchargeBp(config *StampDutyConfig, areaClass string) int32 {
+	if bp, ok := config.SurchargeByClass[areaClass]; ok {
+		return bp
+	}
+	if areaClass == areaClassRural {
+		return 0
+	}
+	if bp, ok := config.SurchargeByClass[areaClassUrban]; ok {
+		return bp
+	}
+	return config.SurchargeBasisPts
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -57,28 +58,94 @@ const defaultRegistrationBp int32 = 100 // 1%
 // defaultSurchargeBp is the default surcharge rate.
 const defaultSurchargeBp int32 = 0 // 0%
 
+// defaultCircleRateMaxChangePct is the largest percentage change allowed
+// for an existing circle rate without an override justification, used
+// when no per-state limit has been set via SetCircleRateChangeLimit.
+const defaultCircleRateMaxChangePct int32 = 300 // ±300%
+
 // ============================================================
 // CIRCLE RATE MANAGEMENT
 // ============================================================
 
 // SetCircleRate sets the circle rate (minimum government valuation)
-// per square meter for a specific tehsil/area. Circle rates are the
-// backbone of anti-benami enforcement -- transactions below circle
-// rate are automatically flagged.
+// for a specific tehsil/area. Circle rates are the backbone of
+// anti-benami enforcement -- transactions below circle rate are
+// automatically flagged.
+//
+// The rate is stored as notified, in paisa per unit (SQ_METER, SQ_FOOT,
+// ACRE, HECTARE, or BIGHA using the state's bigha factor; empty means
+// SQ_METER), together with its normalized paisa-per-square-meter value.
+//
+// When a rate already exists for the area, a change larger than the
+// state's guardrail (default ±300%) is treated as a probable data-entry
+// error (rupees entered as paisa, an extra zero) and rejected unless an
+// overrideJustification is supplied. The justification is stored on the
+// CircleRate record and surfaced in the CIRCLE_RATE_CHANGED event.
 //
 // Only users with the "admin" role can set circle rates.
-// All rates are in paisa per square meter (int64).
-func (s *StampDutyContract) SetCircleRate(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string, ratePerSqMeter int64) error {
+// All rates are in paisa (int64).
+func (s *StampDutyContract) SetCircleRate(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string, rate int64, unit, overrideJustification string) error {
 	// ABAC: Only admin can set circle rates
-	if err := s.requireRole(ctx, "admin"); err != nil {
+	if err := requireRole(ctx, "admin"); err != nil {
 		return err
 	}
 
 	if stateCode == "" || districtCode == "" || tehsilCode == "" {
 		return fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and tehsilCode are all required")
 	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+	if rate <= 0 {
+		return fmt.Errorf("VALIDATION_ERROR: rate must be positive, got %d", rate)
+	}
+	unit, err := normalizeRateUnit(unit)
+	if err != nil {
+		return err
+	}
+	areaE8, err := s.rateUnitAreaE8(ctx, stateCode, unit)
+	if err != nil {
+		return err
+	}
+	ratePerSqMeter := ratePerSqMeterFromUnit(rate, areaE8)
 	if ratePerSqMeter <= 0 {
-		return fmt.Errorf("VALIDATION_ERROR: ratePerSqMeter must be positive, got %d", ratePerSqMeter)
+		return fmt.Errorf("VALIDATION_ERROR: rate %d paisa per %s is below 1 paisa per square meter", rate, unit)
+	}
+
+	// Composite key: CIRCLE_RATE~{stateCode}~{districtCode}~{tehsilCode}
+	key, err := ctx.GetStub().CreateCompositeKey("CIRCLE_RATE", []string{stateCode, districtCode, tehsilCode})
+	if err != nil {
+		return fmt.Errorf("failed to create circle rate key: %v", err)
+	}
+
+	// Guardrail: compare against the prior rate for the same area
+	var previousRate int64
+	var percentChange float64
+	overridden := false
+	existingBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read existing circle rate: %v", err)
+	}
+	if existingBytes != nil {
+		var existing CircleRate
+		if err := json.Unmarshal(existingBytes, &existing); err != nil {
+			return fmt.Errorf("failed to unmarshal existing circle rate: %v", err)
+		}
+		previousRate = existing.RatePerSqMeter
+	}
+	if previousRate > 0 {
+		percentChange = float64(ratePerSqMeter-previousRate) * 100 / float64(previousRate)
+
+		maxChangePct, err := s.getCircleRateChangeLimit(ctx, stateCode)
+		if err != nil {
+			return err
+		}
+		if exceedsCircleRateChangeLimit(previousRate, ratePerSqMeter, maxChangePct) {
+			if overrideJustification == "" {
+				return fmt.Errorf("CIRCLE_RATE_CHANGE_EXCEEDS_LIMIT: new rate %d paisa/sqm differs from current rate %d paisa/sqm by %.2f%% (limit ±%d%%); supply an overrideJustification to proceed", ratePerSqMeter, previousRate, percentChange, maxChangePct)
+			}
+			overridden = true
+		}
 	}
 
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
@@ -86,20 +153,18 @@ func (s *StampDutyContract) SetCircleRate(ctx contractapi.TransactionContextInte
 	txID := ctx.GetStub().GetTxID()
 
 	circleRate := CircleRate{
-		DocType:        "circleRate",
-		StateCode:      stateCode,
-		DistrictCode:   districtCode,
-		TehsilCode:     tehsilCode,
-		RatePerSqMeter: ratePerSqMeter,
-		EffectiveFrom:  now,
-		SetBy:          s.getCallerID(ctx),
-		FabricTxID:     txID,
-	}
-
-	// Composite key: CIRCLE_RATE~{stateCode}~{districtCode}~{tehsilCode}
-	key, err := ctx.GetStub().CreateCompositeKey("CIRCLE_RATE", []string{stateCode, districtCode, tehsilCode})
-	if err != nil {
-		return fmt.Errorf("failed to create circle rate key: %v", err)
+		DocType:               "circleRate",
+		StateCode:             stateCode,
+		DistrictCode:          districtCode,
+		TehsilCode:            tehsilCode,
+		RatePerSqMeter:        ratePerSqMeter,
+		NotifiedRate:          rate,
+		Unit:                  unit,
+		PreviousRate:          previousRate,
+		OverrideJustification: overrideJustification,
+		EffectiveFrom:         now,
+		SetBy:                 getCallerID(ctx),
+		FabricTxID:            txID,
 	}
 
 	rateBytes, err := json.Marshal(circleRate)
@@ -113,14 +178,20 @@ func (s *StampDutyContract) SetCircleRate(ctx contractapi.TransactionContextInte
 
 	// Emit event for rate change notifications
 	event := CircleRateChangedEvent{
-		Type:           "CIRCLE_RATE_CHANGED",
-		StateCode:      stateCode,
-		DistrictCode:   districtCode,
-		TehsilCode:     tehsilCode,
-		RatePerSqMeter: ratePerSqMeter,
-		FabricTxID:     txID,
-		Timestamp:      now,
-		ChannelID:      ctx.GetStub().GetChannelID(),
+		Type:                  "CIRCLE_RATE_CHANGED",
+		StateCode:             stateCode,
+		DistrictCode:          districtCode,
+		TehsilCode:            tehsilCode,
+		RatePerSqMeter:        ratePerSqMeter,
+		NotifiedRate:          rate,
+		Unit:                  unit,
+		PreviousRate:          previousRate,
+		PercentChange:         percentChange,
+		Overridden:            overridden,
+		OverrideJustification: overrideJustification,
+		FabricTxID:            txID,
+		Timestamp:             now,
+		ChannelID:             ctx.GetStub().GetChannelID(),
 	}
 	eventJSON, err := json.Marshal(event)
 	if err != nil {
@@ -129,45 +200,116 @@ func (s *StampDutyContract) SetCircleRate(ctx contractapi.TransactionContextInte
 	return ctx.GetStub().SetEvent("CIRCLE_RATE_CHANGED", eventJSON)
 }
 
+// SetCircleRateChangeLimit configures the maximum percentage by which
+// a state's circle rates may change in a single SetCircleRate call
+// before an override justification is required. Only admins can set it.
+func (s *StampDutyContract) SetCircleRateChangeLimit(ctx contractapi.TransactionContextInterface, stateCode string, maxChangePct int32) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+	if maxChangePct <= 0 {
+		return fmt.Errorf("VALIDATION_ERROR: maxChangePct must be positive, got %d", maxChangePct)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	limit := CircleRateChangeLimit{
+		DocType:      "circleRateChangeLimit",
+		StateCode:    stateCode,
+		MaxChangePct: maxChangePct,
+		SetBy:        getCallerID(ctx),
+		UpdatedAt:    now,
+		FabricTxID:   txID,
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("CIRCLE_RATE_LIMIT", []string{stateCode})
+	if err != nil {
+		return fmt.Errorf("failed to create circle rate limit key: %v", err)
+	}
+	limitBytes, err := json.Marshal(limit)
+	if err != nil {
+		return fmt.Errorf("failed to marshal circle rate limit: %v", err)
+	}
+	return ctx.GetStub().PutState(key, limitBytes)
+}
+
 // GetCircleRate retrieves the circle rate per square meter (in paisa)
-// for the specified tehsil. Returns an error if no rate has been set.
+// for the specified tehsil, normalized from the notified unit. Returns
+// an error if no rate has been set.
 func (s *StampDutyContract) GetCircleRate(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string) (int64, error) {
+	circleRate, err := s.GetCircleRateRecord(ctx, stateCode, districtCode, tehsilCode)
+	if err != nil {
+		return 0, err
+	}
+	return circleRate.RatePerSqMeter, nil
+}
+
+// GetCircleRateRecord retrieves the full circle rate record for the
+// specified tehsil, including the notified rate and unit.
+func (s *StampDutyContract) GetCircleRateRecord(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string) (*CircleRate, error) {
 	if stateCode == "" || districtCode == "" || tehsilCode == "" {
-		return 0, fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and tehsilCode are all required")
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and tehsilCode are all required")
 	}
 
 	key, err := ctx.GetStub().CreateCompositeKey("CIRCLE_RATE", []string{stateCode, districtCode, tehsilCode})
 	if err != nil {
-		return 0, fmt.Errorf("failed to create circle rate key: %v", err)
+		return nil, fmt.Errorf("failed to create circle rate key: %v", err)
 	}
 
 	rateBytes, err := ctx.GetStub().GetState(key)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read circle rate: %v", err)
+		return nil, fmt.Errorf("failed to read circle rate: %v", err)
 	}
 	if rateBytes == nil {
-		return 0, fmt.Errorf("CIRCLE_RATE_NOT_FOUND: no circle rate set for %s/%s/%s", stateCode, districtCode, tehsilCode)
+		return nil, fmt.Errorf("CIRCLE_RATE_NOT_FOUND: no circle rate set for %s/%s/%s", stateCode, districtCode, tehsilCode)
 	}
 
 	var circleRate CircleRate
 	if err := json.Unmarshal(rateBytes, &circleRate); err != nil {
-		return 0, fmt.Errorf("failed to unmarshal circle rate: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal circle rate: %v", err)
 	}
 
-	return circleRate.RatePerSqMeter, nil
+	// Rates set before units were supported are per square meter
+	if circleRate.Unit == "" {
+		circleRate.Unit = UnitSqMeter
+		circleRate.NotifiedRate = circleRate.RatePerSqMeter
+	}
+
+	return &circleRate, nil
 }
 
 // SetStampDutyConfig sets the stamp duty, registration fee, and
 // surcharge rates for a specific state. Rates are in basis points.
 // Only admins can update these configurations.
-func (s *StampDutyContract) SetStampDutyConfig(ctx contractapi.TransactionContextInterface, stateCode string, stampDutyBp, registrationBp, surchargeBp int32) error {
-	if err := s.requireRole(ctx, "admin"); err != nil {
+//
+// surchargeByClassJSON optionally maps area classes (URBAN, RURAL, or a
+// municipality code) to surcharge basis points, e.g. {"MCGM":100}; pass
+// "" to apply surchargeBp to urban areas only.
+//
+// effectiveFrom (RFC3339 or YYYY-MM-DD) lets a rate revision be loaded
+// ahead of the date it takes effect; an empty value means "now". Configs
+// are stored per state and effective date, so earlier revisions remain
+// available for valuations dated before the cutover.
+func (s *StampDutyContract) SetStampDutyConfig(ctx contractapi.TransactionContextInterface, stateCode string, stampDutyBp, registrationBp, surchargeBp int32, surchargeByClassJSON, effectiveFrom string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
 		return err
 	}
 
 	if stateCode == "" {
 		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
 	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
 	if stampDutyBp < 0 || stampDutyBp > 2000 {
 		return fmt.Errorf("VALIDATION_ERROR: stampDutyBasisPoints must be between 0 and 2000 (0-20%%)")
 	}
@@ -178,22 +320,59 @@ func (s *StampDutyContract) SetStampDutyConfig(ctx contractapi.TransactionContex
 		return fmt.Errorf("VALIDATION_ERROR: surchargeBasisPoints must be between 0 and 1000 (0-10%%)")
 	}
 
+	var surchargeByClass map[string]int32
+	if surchargeByClassJSON != "" {
+		var raw map[string]int32
+		if err := json.Unmarshal([]byte(surchargeByClassJSON), &raw); err != nil {
+			return fmt.Errorf("INVALID_INPUT: failed to parse surchargeByClass JSON: %v", err)
+		}
+		surchargeByClass = make(map[string]int32, len(raw))
+		for class, bp := range raw {
+			normalized, err := normalizeAreaClass(class)
+			if err != nil {
+				return err
+			}
+			if bp < 0 || bp > 1000 {
+				return fmt.Errorf("VALIDATION_ERROR: surcharge for area class %s must be between 0 and 1000 (0-10%%)", normalized)
+			}
+			surchargeByClass[normalized] = bp
+		}
+	}
+
 	timestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	nowTime := time.Unix(timestamp.Seconds, 0).UTC()
+	now := nowTime.Format(time.RFC3339)
 	txID := ctx.GetStub().GetTxID()
 
+	// Resolve the effective date; revisions may be scheduled ahead but
+	// never backdated, so past valuations keep the rates that applied.
+	effectiveTime := nowTime
+	if effectiveFrom != "" {
+		parsed, err := parseConfigDate(effectiveFrom, false)
+		if err != nil {
+			return err
+		}
+		if parsed.Before(nowTime) {
+			return fmt.Errorf("VALIDATION_ERROR: effectiveFrom %s is in the past; configs cannot be backdated", effectiveFrom)
+		}
+		effectiveTime = parsed
+	}
+	effectiveKey := effectiveTime.Format(time.RFC3339)
+
 	config := StampDutyConfig{
 		DocType:              "stampDutyConfig",
 		StateCode:            stateCode,
 		StampDutyBasisPts:    stampDutyBp,
 		RegistrationBasisPts: registrationBp,
 		SurchargeBasisPts:    surchargeBp,
-		EffectiveFrom:        now,
-		SetBy:                s.getCallerID(ctx),
+		EffectiveFrom:        effectiveKey,
+		SetBy:                getCallerID(ctx),
 		FabricTxID:           txID,
+		SurchargeByClass:     surchargeByClass,
 	}
 
-	key, err := ctx.GetStub().CreateCompositeKey("STAMP_DUTY_CONFIG", []string{stateCode})
+	// Composite key: STAMP_DUTY_CONFIG~{stateCode}~{effectiveFrom (UTC RFC3339)}
+	key, err := ctx.GetStub().CreateCompositeKey("STAMP_DUTY_CONFIG", []string{stateCode, effectiveKey})
 	if err != nil {
 		return fmt.Errorf("failed to create config key: %v", err)
 	}
@@ -211,6 +390,7 @@ func (s *StampDutyContract) SetStampDutyConfig(ctx contractapi.TransactionContex
 		Type:              "STAMP_DUTY_CONFIG_CHANGED",
 		StateCode:         stateCode,
 		StampDutyBasisPts: stampDutyBp,
+		EffectiveFrom:     effectiveKey,
 		FabricTxID:        txID,
 		Timestamp:         now,
 		ChannelID:         ctx.GetStub().GetChannelID(),
@@ -219,31 +399,138 @@ func (s *StampDutyContract) SetStampDutyConfig(ctx contractapi.TransactionContex
 	return ctx.GetStub().SetEvent("STAMP_DUTY_CONFIG_CHANGED", eventJSON)
 }
 
-// GetStampDutyConfig retrieves the stamp duty configuration for a state.
-// Falls back to hardcoded defaults if no config has been explicitly set.
-func (s *StampDutyContract) GetStampDutyConfig(ctx contractapi.TransactionContextInterface, stateCode string) (*StampDutyConfig, error) {
+// GetStampDutyConfig retrieves the stamp duty configuration for a state
+// that applies on asOfDate (RFC3339 or YYYY-MM-DD; empty means the
+// transaction timestamp): the config with the latest effectiveFrom that
+// is not after that date. Falls back to hardcoded defaults if no config
+// has been explicitly set or none is yet effective.
+func (s *StampDutyContract) GetStampDutyConfig(ctx contractapi.TransactionContextInterface, stateCode, asOfDate string) (*StampDutyConfig, error) {
 	if stateCode == "" {
 		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
 	}
 
-	key, err := ctx.GetStub().CreateCompositeKey("STAMP_DUTY_CONFIG", []string{stateCode})
+	asOf, err := s.resolveAsOfDate(ctx, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.GetStampDutyConfigHistory(ctx, stateCode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create config key: %v", err)
+		return nil, err
+	}
+
+	var selected *StampDutyConfig
+	var selectedAt time.Time
+	for _, config := range history {
+		effectiveAt, err := time.Parse(time.RFC3339, config.EffectiveFrom)
+		if err != nil || effectiveAt.After(asOf) {
+			continue
+		}
+		if selected == nil || !effectiveAt.Before(selectedAt) {
+			selected = config
+			selectedAt = effectiveAt
+		}
+	}
+	if selected != nil {
+		return selected, nil
 	}
 
-	configBytes, err := ctx.GetStub().GetState(key)
+	return defaultStampDutyConfig(stateCode), nil
+}
+
+// ListStampDutyConfigs returns the currently effective config for every
+// state in the defaults table plus any state configured on-chain, sorted
+// by state code. Each entry's Source is EXPLICIT or DEFAULT, and any
+// configured concession rules are attached.
+func (s *StampDutyContract) ListStampDutyConfigs(ctx contractapi.TransactionContextInterface) ([]*StampDutyConfig, error) {
+	stateSet := make(map[string]bool, len(stateDefaults))
+	for stateCode := range stateDefaults {
+		stateSet[stateCode] = true
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("STAMP_DUTY_CONFIG", []string{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config: %v", err)
+		return nil, fmt.Errorf("failed to query configs: %v", err)
 	}
+	defer iterator.Close()
 
-	if configBytes != nil {
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate configs: %v", err)
+		}
+		_, attrs, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(attrs) == 0 {
+			continue
+		}
+		stateSet[attrs[0]] = true
+	}
+
+	stateCodes := make([]string, 0, len(stateSet))
+	for stateCode := range stateSet {
+		stateCodes = append(stateCodes, stateCode)
+	}
+	sort.Strings(stateCodes)
+
+	configs := make([]*StampDutyConfig, 0, len(stateCodes))
+	for _, stateCode := range stateCodes {
+		config, err := s.GetStampDutyConfig(ctx, stateCode, "")
+		if err != nil {
+			return nil, err
+		}
+		concessions, err := s.GetConcessionRules(ctx, stateCode)
+		if err != nil {
+			return nil, err
+		}
+		if len(concessions) > 0 {
+			config.Concessions = concessions
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// GetStampDutyConfigHistory returns every explicitly set stamp duty
+// config for a state, including future-dated revisions, ordered by
+// effectiveFrom.
+func (s *StampDutyContract) GetStampDutyConfigHistory(ctx contractapi.TransactionContextInterface, stateCode string) ([]*StampDutyConfig, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("STAMP_DUTY_CONFIG", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query configs: %v", err)
+	}
+	defer iterator.Close()
+
+	var history []*StampDutyConfig
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate configs: %v", err)
+		}
 		var config StampDutyConfig
-		if err := json.Unmarshal(configBytes, &config); err != nil {
+		if err := json.Unmarshal(kv.Value, &config); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal config: %v", err)
 		}
-		return &config, nil
-	}
+		config.Source = "EXPLICIT"
+		history = append(history, &config)
+	}
+
+	// Configs written before effective dating used a stateCode-only key
+	// and local-time timestamps, so order by parsed time, not key.
+	sort.SliceStable(history, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339, history[i].EffectiveFrom)
+		tj, _ := time.Parse(time.RFC3339, history[j].EffectiveFrom)
+		return ti.Before(tj)
+	})
+	return history, nil
+}
 
+// defaultStampDutyConfig returns the hardcoded config for a state from
+// stateDefaults, or the national default rates if the state is unknown.
+func defaultStampDutyConfig(stateCode string) *StampDutyConfig {
 	// Fall back to hardcoded defaults
 	if rates, exists := stateDefaults[stateCode]; exists {
 		return &StampDutyConfig{
@@ -254,7 +541,8 @@ func (s *StampDutyContract) GetStampDutyConfig(ctx contractapi.TransactionContex
 			SurchargeBasisPts:    rates[2],
 			EffectiveFrom:        "default",
 			SetBy:                "system",
-		}, nil
+			Source:               "DEFAULT",
+		}
 	}
 
 	// Ultimate fallback: default rates
@@ -266,7 +554,8 @@ func (s *StampDutyContract) GetStampDutyConfig(ctx contractapi.TransactionContex
 		SurchargeBasisPts:    defaultSurchargeBp,
 		EffectiveFrom:        "default",
 		SetBy:                "system",
-	}, nil
+		Source:               "DEFAULT",
+	}
 }
 
 // ============================================================
@@ -274,20 +563,25 @@ func (s *StampDutyContract) GetStampDutyConfig(ctx contractapi.TransactionContex
 // ============================================================
 
 // CalculateStampDuty calculates the complete stamp duty breakdown
-// for a property transaction. It uses the circle rate for the area
-// to determine the minimum applicable value, then applies state-
-// specific duty rates.
+// for a property transaction from a circle rate value supplied by the
+// caller (e.g. computed via GetCircleRate), then applies state-specific
+// duty rates. Prefer CalculateStampDutyWithCircleRate when the tehsil is
+// known, so the circle rate is read from the ledger.
 //
 // Parameters:
 //   - stateCode: Indian state code (e.g., "MH", "KA")
 //   - areaSqMeters: Area of the property in square meters (float64)
 //   - declaredValue: Sale consideration declared by buyer/seller (in paisa)
+//   - circleRateValue: Circle rate value of the whole property (in paisa)
+//   - asOfDate: Valuation date selecting the config in force (empty = tx timestamp)
+//   - areaClass: URBAN, RURAL, or a municipality code (empty = RURAL, flagged)
+//   - claimedConcessions: Concession codes claimed (see SetConcessionRules)
 //
 // Returns a StampDutyBreakdown with the full fee calculation.
 //
 // Anti-benami rule: The applicable value is always the HIGHER of
 // declared value and circle rate value, preventing undervaluation.
-func (s *StampDutyContract) CalculateStampDuty(ctx contractapi.TransactionContextInterface, stateCode string, areaSqMeters float64, declaredValue int64) (*StampDutyBreakdown, error) {
+func (s *StampDutyContract) CalculateStampDuty(ctx contractapi.TransactionContextInterface, stateCode string, areaSqMeters float64, declaredValue, circleRateValue int64, asOfDate, areaClass string, claimedConcessions []string) (*StampDutyBreakdown, error) {
 	if stateCode == "" {
 		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
 	}
@@ -297,54 +591,36 @@ func (s *StampDutyContract) CalculateStampDuty(ctx contractapi.TransactionContex
 	if declaredValue < 0 {
 		return nil, fmt.Errorf("VALIDATION_ERROR: declaredValue cannot be negative")
 	}
+	if circleRateValue < 0 {
+		return nil, fmt.Errorf("VALIDATION_ERROR: circleRateValue cannot be negative")
+	}
+	if areaClass != "" {
+		normalized, err := normalizeAreaClass(areaClass)
+		if err != nil {
+			return nil, err
+		}
+		areaClass = normalized
+	}
 
-	// Get state-specific stamp duty config (or defaults)
-	config, err := s.GetStampDutyConfig(ctx, stateCode)
+	// Get state-specific stamp duty config (or defaults) in force on the valuation date
+	config, err := s.GetStampDutyConfig(ctx, stateCode, asOfDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stamp duty config: %v", err)
 	}
 
-	// Calculate circle rate value
-	// Note: We attempt to get the circle rate from the ledger.
-	// If not found, we use the declared value as both values (no circle rate enforcement).
-	circleRateValue := declaredValue // Default to declared value
-
-	// Try to find circle rate -- iterate through all matching tehsil rates
-	// In a real scenario, the caller should pass stateCode + districtCode + tehsilCode
-	// For this calculation function, we use the declared value as the baseline
-	// and the caller is expected to have already verified against circle rate.
-	// The circle rate value can be passed separately via the declaredValue parameter
-	// since the land-registry chaincode enforces declared >= circle rate.
-
-	// Determine applicable value: higher of declared and circle rate (anti-benami)
-	applicableValue := declaredValue
-	if circleRateValue > applicableValue {
-		applicableValue = circleRateValue
+	// A sale deed, with any caps and rounding the state sets for it
+	rate, roundingUnit, err := s.instrumentRate(ctx, stateCode, defaultInstrumentType)
+	if err != nil {
+		return nil, err
 	}
 
-	// Calculate stamp duty (in paisa)
-	// Formula: applicableValue * rate / 10000 (since rate is in basis points)
-	stampDutyAmount := (applicableValue * int64(config.StampDutyBasisPts)) / 10000
-
-	// Calculate registration fee (in paisa)
-	registrationFee := (applicableValue * int64(config.RegistrationBasisPts)) / 10000
-
-	// Calculate surcharge (in paisa)
-	surcharge := (applicableValue * int64(config.SurchargeBasisPts)) / 10000
+	// Apply rates to max(declared, circle rate) (anti-benami)
+	breakdown := computeBreakdown(config, rate, stateCode, circleRateValue, declaredValue, areaClass)
 
-	// Total government fees
-	totalFees := stampDutyAmount + registrationFee + surcharge
-
-	breakdown := &StampDutyBreakdown{
-		CircleRateValue: circleRateValue,
-		ApplicableValue: applicableValue,
-		StampDutyRate:   config.StampDutyBasisPts,
-		StampDutyAmount: stampDutyAmount,
-		RegistrationFee: registrationFee,
-		Surcharge:       surcharge,
-		TotalFees:       totalFees,
-		State:           stateCode,
+	if err := s.applyClaimedConcessions(ctx, breakdown, stateCode, claimedConcessions); err != nil {
+		return nil, err
 	}
+	roundBreakdown(breakdown, roundingUnit)
 
 	return breakdown, nil
 }
@@ -357,90 +633,241 @@ func (s *StampDutyContract) CalculateStampDuty(ctx contractapi.TransactionContex
 //   - stateCode, districtCode, tehsilCode: Location codes for circle rate lookup
 //   - areaSqMeters: Property area in square meters
 //   - declaredValue: Transaction value declared by parties (in paisa)
+//   - asOfDate: Valuation date selecting the config in force (empty = tx timestamp)
+//   - claimedConcessions: Concession codes claimed (see SetConcessionRules)
 //
 // The applicable value is max(declaredValue, circleRate * areaSqMeters).
-func (s *StampDutyContract) CalculateStampDutyWithCircleRate(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string, areaSqMeters float64, declaredValue int64) (*StampDutyBreakdown, error) {
+// The surcharge uses the tehsil's on-chain area classification (see
+// SetAreaClassification); unclassified tehsils are treated as RURAL.
+func (s *StampDutyContract) CalculateStampDutyWithCircleRate(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string, areaSqMeters float64, declaredValue int64, asOfDate string, claimedConcessions []string) (*StampDutyBreakdown, error) {
+	breakdown, _, _, err := s.calculateWithCircleRate(ctx, stateCode, districtCode, tehsilCode, "", "", areaSqMeters, declaredValue, asOfDate, claimedConcessions)
+	if err != nil {
+		return nil, err
+	}
+	return breakdown, nil
+}
+
+// calculateWithCircleRate performs the circle-rate-based calculation and
+// also returns the stamp duty config and circle rate (paisa per square
+// meter) that were applied, for callers that need to record them. A
+// non-empty landUse scales the circle rate value by the state's factor
+// for it (see SetLandUseFactors). instrumentType (empty = SALE_DEED)
+// selects the state's rates, caps and rounding (see SetInstrumentRates).
+func (s *StampDutyContract) calculateWithCircleRate(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode, landUse, instrumentType string, areaSqMeters float64, declaredValue int64, asOfDate string, claimedConcessions []string) (*StampDutyBreakdown, *StampDutyConfig, int64, error) {
 	if stateCode == "" || districtCode == "" || tehsilCode == "" {
-		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and tehsilCode are all required")
+		return nil, nil, 0, fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and tehsilCode are all required")
 	}
 	if areaSqMeters <= 0 {
-		return nil, fmt.Errorf("VALIDATION_ERROR: areaSqMeters must be positive")
+		return nil, nil, 0, fmt.Errorf("VALIDATION_ERROR: areaSqMeters must be positive")
 	}
 	if declaredValue < 0 {
-		return nil, fmt.Errorf("VALIDATION_ERROR: declaredValue cannot be negative")
+		return nil, nil, 0, fmt.Errorf("VALIDATION_ERROR: declaredValue cannot be negative")
 	}
 
-	// Look up circle rate for the tehsil
-	ratePerSqMeter, err := s.GetCircleRate(ctx, stateCode, districtCode, tehsilCode)
+	// Look up circle rate for the tehsil (normalized to per square meter)
+	circleRate, err := s.GetCircleRateRecord(ctx, stateCode, districtCode, tehsilCode)
 	if err != nil {
-		return nil, fmt.Errorf("CIRCLE_RATE_LOOKUP_FAILED: %v", err)
+		return nil, nil, 0, fmt.Errorf("CIRCLE_RATE_LOOKUP_FAILED: %v", err)
 	}
+	ratePerSqMeter := circleRate.RatePerSqMeter
 
 	// Calculate circle rate value = rate per sq meter * area
 	// Both are already in paisa, but areaSqMeters is float64
 	circleRateValue := int64(float64(ratePerSqMeter) * areaSqMeters)
+	var landUseBp int32
+	if landUse != "" {
+		if landUseBp, err = s.landUseFactorBp(ctx, stateCode, landUse); err != nil {
+			return nil, nil, 0, err
+		}
+		circleRateValue = (circleRateValue * int64(landUseBp)) / 10000
+	}
 
-	// Get state-specific stamp duty config
-	config, err := s.GetStampDutyConfig(ctx, stateCode)
+	// Get state-specific stamp duty config in force on the valuation date
+	config, err := s.GetStampDutyConfig(ctx, stateCode, asOfDate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stamp duty config: %v", err)
+		return nil, nil, 0, fmt.Errorf("failed to get stamp duty config: %v", err)
+	}
+
+	// Resolve the surcharge class from the ledger, not the caller
+	classification, err := s.getAreaClassification(ctx, stateCode, districtCode, tehsilCode)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	areaClass := ""
+	if classification != nil {
+		areaClass = classification.AreaClass
+	}
+
+	rate, roundingUnit, err := s.instrumentRate(ctx, stateCode, instrumentType)
+	if err != nil {
+		return nil, nil, 0, err
 	}
 
+	breakdown := computeBreakdown(config, rate, stateCode, circleRateValue, declaredValue, areaClass)
+	breakdown.NotifiedRate = circleRate.NotifiedRate
+	breakdown.NotifiedRateUnit = circleRate.Unit
+	breakdown.RatePerSqMeter = ratePerSqMeter
+	breakdown.LandUse = landUse
+	breakdown.LandUseFactorBasisPts = landUseBp
+
+	if err := s.applyClaimedConcessions(ctx, breakdown, stateCode, claimedConcessions); err != nil {
+		return nil, nil, 0, err
+	}
+	roundBreakdown(breakdown, roundingUnit)
+	return breakdown, config, ratePerSqMeter, nil
+}
+
+// computeBreakdown applies a state's duty, registration and surcharge
+// rates to max(declaredValue, circleRateValue) (anti-benami).
+// rate prices the instrument: its basis points, where set, replace the
+// config's, and its caps limit the stamp duty and registration fee.
+// The surcharge is resolved for areaClass; an empty class defaults to
+// RURAL and sets AreaClassDefaulted.
+// All amounts are in paisa; rates are in basis points.
+func computeBreakdown(config *StampDutyConfig, rate *InstrumentRate, stateCode string, circleRateValue, declaredValue int64, areaClass string) *StampDutyBreakdown {
 	// Anti-benami: applicable value = max(declared, circleRate)
 	applicableValue := declaredValue
 	if circleRateValue > applicableValue {
 		applicableValue = circleRateValue
 	}
 
+	// Unknown location: assume no metro surcharge, but flag it
+	defaulted := false
+	if areaClass == "" {
+		areaClass = areaClassRural
+		defaulted = true
+	}
+	surchargeBp := resolveSurchargeBp(config, areaClass)
+
+	stampDutyBp, registrationBp := config.StampDutyBasisPts, config.RegistrationBasisPts
+	if rate.StampDutyBasisPts != nil {
+		stampDutyBp = *rate.StampDutyBasisPts
+	}
+	if rate.RegistrationBasisPts != nil {
+		registrationBp = *rate.RegistrationBasisPts
+	}
+
 	// Calculate all fees (in paisa, using basis points)
-	stampDutyAmount := (applicableValue * int64(config.StampDutyBasisPts)) / 10000
-	registrationFee := (applicableValue * int64(config.RegistrationBasisPts)) / 10000
-	surcharge := (applicableValue * int64(config.SurchargeBasisPts)) / 10000
+	stampDutyAmount := (applicableValue * int64(stampDutyBp)) / 10000
+	registrationFee := (applicableValue * int64(registrationBp)) / 10000
+	surcharge := (applicableValue * int64(surchargeBp)) / 10000
+
+	// Caps on the instrument's duty and registration fee
+	stampDutyCapped := rate.StampDutyCap > 0 && stampDutyAmount > rate.StampDutyCap
+	if stampDutyCapped {
+		stampDutyAmount = rate.StampDutyCap
+	}
+	registrationFeeCapped := rate.RegistrationFeeCap > 0 && registrationFee > rate.RegistrationFeeCap
+	if registrationFeeCapped {
+		registrationFee = rate.RegistrationFeeCap
+	}
 	totalFees := stampDutyAmount + registrationFee + surcharge
 
 	breakdown := &StampDutyBreakdown{
 		CircleRateValue: circleRateValue,
 		ApplicableValue: applicableValue,
-		StampDutyRate:   config.StampDutyBasisPts,
+		StampDutyRate:   stampDutyBp,
 		StampDutyAmount: stampDutyAmount,
 		RegistrationFee: registrationFee,
 		Surcharge:       surcharge,
 		TotalFees:       totalFees,
 		State:           stateCode,
+
+		AreaClass:          areaClass,
+		SurchargeRate:      surchargeBp,
+		AreaClassDefaulted: defaulted,
+
+		InstrumentType:        rate.InstrumentType,
+		StampDutyCapped:       stampDutyCapped,
+		RegistrationFeeCapped: registrationFeeCapped,
 	}
+	breakdown.DisplayAmounts = newDisplayAmounts(breakdown)
+	return breakdown
+}
 
-	return breakdown, nil
+// roundBreakdown rounds the stamp duty, registration fee and surcharge
+// up to a multiple of unit paisa and recomputes the total. It runs last,
+// after caps and concessions, so the amounts collected are the rounded
+// ones. A unit of 0 or 1 leaves the breakdown as computed.
+func roundBreakdown(breakdown *StampDutyBreakdown, unit int64) {
+	if unit <= 1 {
+		return
+	}
+	roundUp := func(amount int64) int64 {
+		return (amount + unit - 1) / unit * unit
+	}
+	breakdown.StampDutyAmount = roundUp(breakdown.StampDutyAmount)
+	breakdown.RegistrationFee = roundUp(breakdown.RegistrationFee)
+	breakdown.Surcharge = roundUp(breakdown.Surcharge)
+	breakdown.TotalFees = breakdown.StampDutyAmount + breakdown.RegistrationFee + breakdown.Surcharge
+	breakdown.RoundingUnit = unit
+	breakdown.DisplayAmounts = newDisplayAmounts(breakdown)
 }
 
 // ============================================================
 // Helper Functions
 // ============================================================
 
-// requireRole checks that the caller has the specified role attribute
-// in their X.509 certificate (ABAC).
-func (s *StampDutyContract) requireRole(ctx contractapi.TransactionContextInterface, requiredRole string) error {
-	clientIdentity := ctx.GetClientIdentity()
-	role, found, err := clientIdentity.GetAttributeValue("role")
+// getCircleRateChangeLimit returns the configured circle rate change
+// guardrail for a state, or defaultCircleRateMaxChangePct if none is set.
+func (s *StampDutyContract) getCircleRateChangeLimit(ctx contractapi.TransactionContextInterface, stateCode string) (int32, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("CIRCLE_RATE_LIMIT", []string{stateCode})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create circle rate limit key: %v", err)
+	}
+	limitBytes, err := ctx.GetStub().GetState(key)
 	if err != nil {
-		return fmt.Errorf("ACCESS_DENIED: failed to read role attribute: %v", err)
+		return 0, fmt.Errorf("failed to read circle rate limit: %v", err)
+	}
+	if limitBytes == nil {
+		return defaultCircleRateMaxChangePct, nil
+	}
+	var limit CircleRateChangeLimit
+	if err := json.Unmarshal(limitBytes, &limit); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal circle rate limit: %v", err)
 	}
-	if !found {
-		return fmt.Errorf("ACCESS_DENIED: caller identity has no 'role' attribute")
+	return limit.MaxChangePct, nil
+}
+
+// exceedsCircleRateChangeLimit reports whether moving from previousRate
+// to newRate breaches a ±maxChangePct guardrail. Increases are measured
+// against the previous rate and decreases against the new rate, so the
+// guard is symmetric: with the default 300%, both a 4x rise and a fall
+// to a quarter of the previous rate are flagged.
+func exceedsCircleRateChangeLimit(previousRate, newRate int64, maxChangePct int32) bool {
+	if previousRate <= 0 || newRate <= 0 {
+		return false
 	}
-	if role != requiredRole {
-		return fmt.Errorf("ACCESS_DENIED: required role '%s', caller has role '%s'", requiredRole, role)
+	if newRate >= previousRate {
+		return (newRate-previousRate)*100 > previousRate*int64(maxChangePct)
 	}
-	return nil
+	return (previousRate-newRate)*100 > newRate*int64(maxChangePct)
 }
 
-// getCallerID extracts a readable identifier from the caller's
-// X.509 certificate for audit purposes.
-func (s *StampDutyContract) getCallerID(ctx contractapi.TransactionContextInterface) string {
-	role, _, _ := ctx.GetClientIdentity().GetAttributeValue("role")
-	stateCode, _, _ := ctx.GetClientIdentity().GetAttributeValue("stateCode")
-	mspID, _ := ctx.GetClientIdentity().GetMSPID()
-	if role != "" && stateCode != "" {
-		return fmt.Sprintf("%s:%s:%s", mspID, role, stateCode)
+// resolveAsOfDate returns the valuation instant for config selection:
+// the parsed asOfDate, or the transaction timestamp when empty. A bare
+// date (YYYY-MM-DD) covers the whole day, so a config effective at any
+// time on that date applies.
+func (s *StampDutyContract) resolveAsOfDate(ctx contractapi.TransactionContextInterface, asOfDate string) (time.Time, error) {
+	if asOfDate == "" {
+		timestamp, _ := ctx.GetStub().GetTxTimestamp()
+		return time.Unix(timestamp.Seconds, 0).UTC(), nil
+	}
+	return parseConfigDate(asOfDate, true)
+}
+
+// parseConfigDate parses an RFC3339 timestamp or a YYYY-MM-DD date (UTC).
+// For bare dates, endOfDay selects 23:59:59 instead of midnight.
+func parseConfigDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("VALIDATION_ERROR: date '%s' must be RFC3339 or YYYY-MM-DD", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
 	}
-	return mspID
+	return t, nil
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// calculate runs CalculateStampDuty for KA (5.6% duty, 1% registration,
+// no surcharge) on a 100 sq m property.
+func (l *testLedger) calculate(declaredValue, circleRateValue int64) (*StampDutyBreakdown, error) {
+	var breakdown *StampDutyBreakdown
+	err := l.submit(newTestIdentity("RegistrarOrgMSP", "registrar", "KA"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		breakdown, err = l.contract.CalculateStampDuty(ctx, "KA", 100, declaredValue, circleRateValue, "", "RURAL", nil)
+		return err
+	})
+	return breakdown, err
+}
+
+func TestCalculateStampDutyUnderDeclaredUsesCircleRateValue(t *testing.T) {
+	ledger := newTestLedger(t)
+
+	// Declared ₹5 lakh against a ₹8 lakh circle rate value
+	breakdown, err := ledger.calculate(50000000, 80000000)
+	if err != nil {
+		t.Fatalf("CalculateStampDuty: %v", err)
+	}
+	if breakdown.CircleRateValue != 80000000 {
+		t.Fatalf("CircleRateValue = %d, want the supplied 80000000", breakdown.CircleRateValue)
+	}
+	if breakdown.ApplicableValue != 80000000 {
+		t.Fatalf("ApplicableValue = %d, want 80000000", breakdown.ApplicableValue)
+	}
+	if breakdown.StampDutyAmount != 4480000 || breakdown.RegistrationFee != 800000 || breakdown.TotalFees != 5280000 {
+		t.Fatalf("duty %d, registration %d, total %d; want 4480000, 800000, 5280000",
+			breakdown.StampDutyAmount, breakdown.RegistrationFee, breakdown.TotalFees)
+	}
+}
+
+func TestCalculateStampDutyDeclaredAboveCircleRate(t *testing.T) {
+	ledger := newTestLedger(t)
+
+	breakdown, err := ledger.calculate(90000000, 80000000)
+	if err != nil {
+		t.Fatalf("CalculateStampDuty: %v", err)
+	}
+	if breakdown.CircleRateValue != 80000000 || breakdown.ApplicableValue != 90000000 {
+		t.Fatalf("circle %d, applicable %d; want 80000000, 90000000", breakdown.CircleRateValue, breakdown.ApplicableValue)
+	}
+	if breakdown.StampDutyAmount != 5040000 {
+		t.Fatalf("StampDutyAmount = %d, want 5040000", breakdown.StampDutyAmount)
+	}
+}
+
+func TestCalculateStampDutyRejectsNegativeCircleRateValue(t *testing.T) {
+	ledger := newTestLedger(t)
+
+	_, err := ledger.calculate(50000000, -1)
+	expectCode(t, err, "VALIDATION_ERROR")
+}
+
+func TestCalculateStampDutyWithCircleRateUnderDeclared(t *testing.T) {
+	ledger := newTestLedger(t)
+	admin := newTestIdentity("RevenueOrgMSP", "admin", "KA")
+	ledger.mustSubmit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		// ₹8,000 per sq m
+		return ledger.contract.SetCircleRate(ctx, "KA", "BLR", "ANK", 800000, "", "")
+	})
+
+	var breakdown *StampDutyBreakdown
+	ledger.mustSubmit(newTestIdentity("RegistrarOrgMSP", "registrar", "KA"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		breakdown, err = ledger.contract.CalculateStampDutyWithCircleRate(ctx, "KA", "BLR", "ANK", 100, 50000000, "", nil)
+		return err
+	})
+	if breakdown.CircleRateValue != 80000000 || breakdown.ApplicableValue != 80000000 {
+		t.Fatalf("circle %d, applicable %d; want 80000000 for both", breakdown.CircleRateValue, breakdown.ApplicableValue)
+	}
+	if breakdown.StampDutyAmount != 4480000 {
+		t.Fatalf("StampDutyAmount = %d, want 4480000", breakdown.StampDutyAmount)
+	}
+}
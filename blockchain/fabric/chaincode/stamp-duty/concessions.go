@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// STAMP DUTY CONCESSIONS
+// ============================================================
+// States stack rebates (women buyers, first-time buyers, defence
+// personnel, affordable-housing value bands). Each state configures an
+// ordered list of ConcessionRules; callers claim concessions by code and
+// the calculators apply the claimed rules in list order.
+
+// SetConcessionRules replaces a state's ordered concession rules.
+// rulesJSON is a JSON array of ConcessionRule. Rule order decides
+// stacking conflicts. Only admins can set the rules.
+func (s *StampDutyContract) SetConcessionRules(ctx contractapi.TransactionContextInterface, stateCode, rulesJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+
+	var rules []ConcessionRule
+	if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
+		return fmt.Errorf("INVALID_INPUT: failed to parse concession rules JSON: %v", err)
+	}
+
+	seen := make(map[string]bool, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		rule.Code = strings.ToUpper(strings.TrimSpace(rule.Code))
+		if rule.Code == "" {
+			return fmt.Errorf("VALIDATION_ERROR: concession rule %d has no code", i)
+		}
+		if seen[rule.Code] {
+			return fmt.Errorf("VALIDATION_ERROR: duplicate concession code %s", rule.Code)
+		}
+		seen[rule.Code] = true
+		if rule.ReductionBasisPts < 0 || rule.ReductionBasisPts > 2000 {
+			return fmt.Errorf("VALIDATION_ERROR: concession %s reductionBasisPoints must be between 0 and 2000", rule.Code)
+		}
+		if rule.FlatAmount < 0 {
+			return fmt.Errorf("VALIDATION_ERROR: concession %s flatAmount cannot be negative", rule.Code)
+		}
+		if rule.ReductionBasisPts == 0 && rule.FlatAmount == 0 {
+			return fmt.Errorf("VALIDATION_ERROR: concession %s must set reductionBasisPoints or flatAmount", rule.Code)
+		}
+		if rule.MinValue < 0 || rule.MaxValue < 0 || (rule.MaxValue > 0 && rule.MaxValue < rule.MinValue) {
+			return fmt.Errorf("VALIDATION_ERROR: concession %s has an invalid value band", rule.Code)
+		}
+		rule.BuyerGender = strings.ToUpper(strings.TrimSpace(rule.BuyerGender))
+		switch rule.BuyerGender {
+		case "MALE", "FEMALE", "OTHER", "":
+		default:
+			return fmt.Errorf("VALIDATION_ERROR: concession %s buyerGender must be MALE, FEMALE, or OTHER, got '%s'", rule.Code, rule.BuyerGender)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	ruleSet := ConcessionRuleSet{
+		DocType:    "concessionRuleSet",
+		StateCode:  stateCode,
+		Rules:      rules,
+		SetBy:      getCallerID(ctx),
+		UpdatedAt:  now,
+		FabricTxID: ctx.GetStub().GetTxID(),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("CONCESSION_RULES", []string{stateCode})
+	if err != nil {
+		return fmt.Errorf("failed to create concession rules key: %v", err)
+	}
+	ruleBytes, err := json.Marshal(ruleSet)
+	if err != nil {
+		return fmt.Errorf("failed to marshal concession rules: %v", err)
+	}
+	return ctx.GetStub().PutState(key, ruleBytes)
+}
+
+// GetConcessionRules returns a state's ordered concession rules, or an
+// empty list if none are configured.
+func (s *StampDutyContract) GetConcessionRules(ctx contractapi.TransactionContextInterface, stateCode string) ([]ConcessionRule, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("CONCESSION_RULES", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create concession rules key: %v", err)
+	}
+	ruleBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read concession rules: %v", err)
+	}
+	if ruleBytes == nil {
+		return []ConcessionRule{}, nil
+	}
+
+	var ruleSet ConcessionRuleSet
+	if err := json.Unmarshal(ruleBytes, &ruleSet); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal concession rules: %v", err)
+	}
+	return ruleSet.Rules, nil
+}
+
+// applyClaimedConcessions loads the state's rules and applies the
+// claimed concessions to the breakdown.
+func (s *StampDutyContract) applyClaimedConcessions(ctx contractapi.TransactionContextInterface, breakdown *StampDutyBreakdown, stateCode string, claimed []string) error {
+	if len(claimed) == 0 {
+		return nil
+	}
+	rules, err := s.GetConcessionRules(ctx, stateCode)
+	if err != nil {
+		return err
+	}
+	return applyConcessions(breakdown, rules, claimed)
+}
+
+// applyConcessions reduces the stamp duty in a breakdown by the claimed
+// concessions. Every claimed code must be configured for the state.
+// Claimed rules are evaluated in rule order and apply only inside their
+// value band (on the applicable value). A non-stackable rule applies
+// only if nothing has been applied before it, and once applied stops
+// further concessions. Each applied concession is itemized and the duty
+// never goes below zero.
+func applyConcessions(breakdown *StampDutyBreakdown, rules []ConcessionRule, claimed []string) error {
+	claimedSet := make(map[string]bool, len(claimed))
+	for _, code := range claimed {
+		claimedSet[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+	configured := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		configured[rule.Code] = true
+	}
+	for code := range claimedSet {
+		if !configured[code] {
+			return fmt.Errorf("CONCESSION_NOT_CONFIGURED: concession %s is not offered in %s", code, breakdown.State)
+		}
+	}
+
+	remaining := breakdown.StampDutyAmount
+	var applied []AppliedConcession
+	for _, rule := range rules {
+		if !claimedSet[rule.Code] {
+			continue
+		}
+		if breakdown.ApplicableValue < rule.MinValue || (rule.MaxValue > 0 && breakdown.ApplicableValue > rule.MaxValue) {
+			continue
+		}
+		if !rule.Stackable && len(applied) > 0 {
+			continue
+		}
+
+		amount := (breakdown.ApplicableValue*int64(rule.ReductionBasisPts))/10000 + rule.FlatAmount
+		if amount > remaining {
+			amount = remaining
+		}
+		remaining -= amount
+		applied = append(applied, AppliedConcession{
+			Code:              rule.Code,
+			ReductionBasisPts: rule.ReductionBasisPts,
+			FlatAmount:        rule.FlatAmount,
+			Amount:            amount,
+		})
+
+		if !rule.Stackable {
+			break
+		}
+	}
+
+	if len(applied) == 0 {
+		return nil
+	}
+	breakdown.AppliedConcessions = applied
+	breakdown.ConcessionTotal = breakdown.StampDutyAmount - remaining
+	breakdown.StampDutyAmount = remaining
+	breakdown.TotalFees = breakdown.StampDutyAmount + breakdown.RegistrationFee + breakdown.Surcharge
+	breakdown.DisplayAmounts = newDisplayAmounts(breakdown)
+	return nil
+}
+
+// checkBuyerEligibility rejects a claimed concession whose rule is
+// restricted to a class of buyer that not every buyer belongs to.
+func checkBuyerEligibility(rules []ConcessionRule, claimed []string, buyers []TransferBuyer) error {
+	claimedSet := make(map[string]bool, len(claimed))
+	for _, code := range claimed {
+		claimedSet[code] = true
+	}
+	for _, rule := range rules {
+		if !claimedSet[rule.Code] {
+			continue
+		}
+		for i, buyer := range buyers {
+			if rule.BuyerGender != "" && buyer.Gender != rule.BuyerGender {
+				return fmt.Errorf("CONCESSION_NOT_ELIGIBLE: concession %s requires every buyer to be %s; buyer %d is not", rule.Code, rule.BuyerGender, i)
+			}
+			if rule.FirstTimeBuyerOnly && !buyer.FirstTimeBuyer {
+				return fmt.Errorf("CONCESSION_NOT_ELIGIBLE: concession %s requires every buyer to be a first-time buyer; buyer %d is not", rule.Code, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LAND-USE CONVERSION CHARGES
+// ============================================================
+// Converting land to another use (e.g. agricultural to residential, NA
+// conversion) attracts a charge set as a share of the circle-rate value
+// of the parcel, which depends on the from→to use pair. The
+// land-registry chaincode calls CalculateConversionCharge from
+// ChangeLandUse to check the challan presented for the conversion.
+
+// SetConversionChargeRules replaces a state's conversion charge rules.
+// rulesJSON is a JSON array of ConversionChargeRule; each from→to use
+// pair may appear once. Only admins can set the rules.
+func (s *StampDutyContract) SetConversionChargeRules(ctx contractapi.TransactionContextInterface, stateCode, rulesJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+
+	var rules []ConversionChargeRule
+	if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
+		return fmt.Errorf("INVALID_INPUT: failed to parse conversion charge rules JSON: %v", err)
+	}
+
+	seen := make(map[string]bool, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		rule.FromUse = strings.ToUpper(strings.TrimSpace(rule.FromUse))
+		rule.ToUse = strings.ToUpper(strings.TrimSpace(rule.ToUse))
+		if rule.FromUse == "" || rule.ToUse == "" {
+			return fmt.Errorf("VALIDATION_ERROR: conversion rule %d needs fromUse and toUse", i)
+		}
+		if rule.FromUse == rule.ToUse {
+			return fmt.Errorf("VALIDATION_ERROR: conversion rule %d converts %s to itself", i, rule.FromUse)
+		}
+		pair := rule.FromUse + "->" + rule.ToUse
+		if seen[pair] {
+			return fmt.Errorf("VALIDATION_ERROR: duplicate conversion rule %s", pair)
+		}
+		seen[pair] = true
+		if rule.ChargeBasisPts <= 0 || rule.ChargeBasisPts > 10000 {
+			return fmt.Errorf("VALIDATION_ERROR: conversion %s chargeBasisPoints must be between 1 and 10000", pair)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	ruleSet := ConversionChargeRuleSet{
+		DocType:    "conversionChargeRuleSet",
+		StateCode:  stateCode,
+		Rules:      rules,
+		SetBy:      getCallerID(ctx),
+		UpdatedAt:  now,
+		FabricTxID: ctx.GetStub().GetTxID(),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("CONVERSION_RULES", []string{stateCode})
+	if err != nil {
+		return fmt.Errorf("failed to create conversion rules key: %v", err)
+	}
+	ruleBytes, err := json.Marshal(ruleSet)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversion rules: %v", err)
+	}
+	return ctx.GetStub().PutState(key, ruleBytes)
+}
+
+// GetConversionChargeRules returns a state's conversion charge rules,
+// or an empty list if none are configured.
+func (s *StampDutyContract) GetConversionChargeRules(ctx contractapi.TransactionContextInterface, stateCode string) ([]ConversionChargeRule, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("CONVERSION_RULES", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversion rules key: %v", err)
+	}
+	ruleBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversion rules: %v", err)
+	}
+	if ruleBytes == nil {
+		return []ConversionChargeRule{}, nil
+	}
+
+	var ruleSet ConversionChargeRuleSet
+	if err := json.Unmarshal(ruleBytes, &ruleSet); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conversion rules: %v", err)
+	}
+	return ruleSet.Rules, nil
+}
+
+// CalculateConversionCharge computes the charge for converting a parcel
+// of areaSqMeters in a tehsil from fromUse to toUse: the rule's basis
+// points of the circle-rate value (rate per square meter times area).
+// Fails with CONVERSION_RULE_NOT_FOUND if the state has no rule for the
+// pair.
+func (s *StampDutyContract) CalculateConversionCharge(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode, fromUse, toUse string, areaSqMeters float64) (*ConversionCharge, error) {
+	if stateCode == "" || districtCode == "" || tehsilCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and tehsilCode are all required")
+	}
+	if areaSqMeters <= 0 {
+		return nil, fmt.Errorf("VALIDATION_ERROR: areaSqMeters must be positive")
+	}
+	fromUse = strings.ToUpper(strings.TrimSpace(fromUse))
+	toUse = strings.ToUpper(strings.TrimSpace(toUse))
+
+	rules, err := s.GetConversionChargeRules(ctx, stateCode)
+	if err != nil {
+		return nil, err
+	}
+	var rule *ConversionChargeRule
+	for i := range rules {
+		if rules[i].FromUse == fromUse && rules[i].ToUse == toUse {
+			rule = &rules[i]
+			break
+		}
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("CONVERSION_RULE_NOT_FOUND: %s has no conversion charge rule for %s to %s", stateCode, fromUse, toUse)
+	}
+
+	circleRate, err := s.GetCircleRateRecord(ctx, stateCode, districtCode, tehsilCode)
+	if err != nil {
+		return nil, fmt.Errorf("CIRCLE_RATE_LOOKUP_FAILED: %v", err)
+	}
+	circleRateValue := int64(float64(circleRate.RatePerSqMeter) * areaSqMeters)
+
+	return &ConversionCharge{
+		StateCode:       stateCode,
+		DistrictCode:    districtCode,
+		TehsilCode:      tehsilCode,
+		FromUse:         fromUse,
+		ToUse:           toUse,
+		AreaSqMeters:    areaSqMeters,
+		RatePerSqMeter:  circleRate.RatePerSqMeter,
+		CircleRateValue: circleRateValue,
+		ChargeBasisPts:  rule.ChargeBasisPts,
+		ChargeAmount:    (circleRateValue * int64(rule.ChargeBasisPts)) / 10000,
+	}, nil
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// DEFICIT STAMP DUTY AND INTEREST
+// ============================================================
+
+// defaultPenaltyMonthlyInterestBp is the interest charged on deficit
+// stamp duty per month or part thereof when no PenaltyConfig exists.
+const defaultPenaltyMonthlyInterestBp int32 = 200 // 2% per month
+
+// SetPenaltyConfig sets the monthly interest rate (basis points) charged
+// on deficit stamp duty in a state and an optional cap on total interest
+// as basis points of the deficit (0 = uncapped). Only admins can set it.
+func (s *StampDutyContract) SetPenaltyConfig(ctx contractapi.TransactionContextInterface, stateCode string, monthlyInterestBp, maxInterestBp int32) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+	if monthlyInterestBp < 0 || monthlyInterestBp > 1000 {
+		return fmt.Errorf("VALIDATION_ERROR: monthlyInterestBasisPoints must be between 0 and 1000 (0-10%%)")
+	}
+	if maxInterestBp < 0 {
+		return fmt.Errorf("VALIDATION_ERROR: maxInterestBasisPoints cannot be negative")
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	config := PenaltyConfig{
+		DocType:                 "penaltyConfig",
+		StateCode:               stateCode,
+		MonthlyInterestBasisPts: monthlyInterestBp,
+		MaxInterestBasisPts:     maxInterestBp,
+		SetBy:                   getCallerID(ctx),
+		UpdatedAt:               now,
+		FabricTxID:              ctx.GetStub().GetTxID(),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("PENALTY_CONFIG", []string{stateCode})
+	if err != nil {
+		return fmt.Errorf("failed to create penalty config key: %v", err)
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal penalty config: %v", err)
+	}
+	return ctx.GetStub().PutState(key, configBytes)
+}
+
+// GetPenaltyConfig returns the deficit interest rules for a state,
+// falling back to the default monthly rate with no cap.
+func (s *StampDutyContract) GetPenaltyConfig(ctx contractapi.TransactionContextInterface, stateCode string) (*PenaltyConfig, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("PENALTY_CONFIG", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create penalty config key: %v", err)
+	}
+	configBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read penalty config: %v", err)
+	}
+	if configBytes == nil {
+		return &PenaltyConfig{
+			DocType:                 "penaltyConfig",
+			StateCode:               stateCode,
+			MonthlyInterestBasisPts: defaultPenaltyMonthlyInterestBp,
+			SetBy:                   "system",
+		}, nil
+	}
+
+	var config PenaltyConfig
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal penalty config: %v", err)
+	}
+	return &config, nil
+}
+
+// AssessDeficit records an audit reassessment of an under-stamped deed.
+// The duty due on the reassessed value is computed with the state config
+// in force on the registration date; the deficit is that duty less the
+// stamp duty paid under the original challan. The challan also covers
+// the registration fee and surcharge, so the stamp duty paid is taken
+// from the quote when quoteId is given and from paidStampDuty otherwise.
+// A challan, or the transfer it was paid for, can have only one OPEN
+// demand at a time. Interest accrues at the
+// penalty config's monthly rate for each month or part thereof from
+// registration to assessment, less any amnesty waiver in force for the
+// instrument type (see applyAmnesty). An OPEN DemandRecord is stored
+// against the payer hash and DEFICIT_ASSESSED is emitted. Only admins
+// can assess.
+func (s *StampDutyContract) AssessDeficit(ctx contractapi.TransactionContextInterface, assessmentJSON string) (*DemandRecord, error) {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return nil, err
+	}
+
+	var input DeficitAssessmentInput
+	if err := json.Unmarshal([]byte(assessmentJSON), &input); err != nil {
+		return nil, fmt.Errorf("INVALID_INPUT: failed to parse assessment JSON: %v", err)
+	}
+
+	if input.ChallanNumber == "" || input.StateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode and challanNumber of the original payment are required")
+	}
+	if input.ReassessedValue <= 0 {
+		return nil, fmt.Errorf("VALIDATION_ERROR: reassessedValue must be positive, got %d", input.ReassessedValue)
+	}
+	input.InstrumentType = strings.ToUpper(strings.TrimSpace(input.InstrumentType))
+	if input.InstrumentType == "" {
+		input.InstrumentType = "SALE_DEED"
+	}
+
+	if err := requireStateAccess(ctx, input.StateCode); err != nil {
+		return nil, err
+	}
+	payment, err := s.GetPayment(ctx, input.StateCode, input.ChallanNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireNoOpenDemand(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	var originalApplicableValue int64
+	paidDuty := input.PaidStampDuty
+	if input.QuoteID != "" {
+		quote, err := s.GetQuote(ctx, input.QuoteID)
+		if err != nil {
+			return nil, err
+		}
+		if quote.StateCode != payment.StateCode {
+			return nil, fmt.Errorf("VALIDATION_ERROR: quote %s is for %s, payment %s is for %s", input.QuoteID, quote.StateCode, input.ChallanNumber, payment.StateCode)
+		}
+		originalApplicableValue = quote.Breakdown.ApplicableValue
+		paidDuty = quote.Breakdown.StampDutyAmount
+	} else if paidDuty <= 0 {
+		return nil, fmt.Errorf("VALIDATION_ERROR: paidStampDuty is required when no quoteId is given")
+	}
+	if paidDuty > payment.Amount {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stamp duty of %d paisa exceeds the %d paisa paid under challan %s", paidDuty, payment.Amount, input.ChallanNumber)
+	}
+
+	// Interest runs from registration; default to when the challan was
+	// consumed by the transfer.
+	registrationDate := input.RegistrationDate
+	if registrationDate == "" {
+		registrationDate = payment.ConsumedAt
+	}
+	if registrationDate == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: registrationDate is required for an unconsumed challan")
+	}
+	registeredAt, err := parseConfigDate(registrationDate, false)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	nowTime := time.Unix(timestamp.Seconds, 0)
+	now := nowTime.Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	if registeredAt.After(nowTime) {
+		return nil, fmt.Errorf("VALIDATION_ERROR: registrationDate %s is in the future", registrationDate)
+	}
+
+	config, err := s.GetStampDutyConfig(ctx, payment.StateCode, registeredAt.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	requiredDuty := (input.ReassessedValue * int64(config.StampDutyBasisPts)) / 10000
+	deficit := requiredDuty - paidDuty
+	if deficit <= 0 {
+		return nil, fmt.Errorf("NO_DEFICIT: duty of %d paisa on reassessed value is covered by %d paisa of stamp duty paid", requiredDuty, paidDuty)
+	}
+
+	penalty, err := s.GetPenaltyConfig(ctx, payment.StateCode)
+	if err != nil {
+		return nil, err
+	}
+	months := monthsOrPartThereof(registeredAt, nowTime)
+	interest := deficitInterest(deficit, months, penalty)
+
+	demand := DemandRecord{
+		DocType:                 "demandRecord",
+		DemandID:                "dmd_" + txID[:8],
+		StateCode:               payment.StateCode,
+		ChallanNumber:           input.ChallanNumber,
+		QuoteID:                 input.QuoteID,
+		PayerHash:               payment.PayerHash,
+		RegistrationDate:        registeredAt.Format(time.RFC3339),
+		OriginalApplicableValue: originalApplicableValue,
+		ReassessedValue:         input.ReassessedValue,
+		StampDutyBasisPts:       config.StampDutyBasisPts,
+		RequiredDuty:            requiredDuty,
+		PaidDuty:                paidDuty,
+		DeficitAmount:           deficit,
+		InterestMonths:          months,
+		MonthlyInterestBasisPts: penalty.MonthlyInterestBasisPts,
+		InterestAmount:          interest,
+		InstrumentType:          input.InstrumentType,
+		Remarks:                 input.Remarks,
+		Status:                  "OPEN",
+		AssessedBy:              getCallerID(ctx),
+		AssessedAt:              now,
+		FabricTxID:              txID,
+	}
+	if err := s.applyAmnesty(ctx, &demand, nowTime); err != nil {
+		return nil, err
+	}
+
+	if err := s.putDemand(ctx, &demand); err != nil {
+		return nil, err
+	}
+	if err := putOpenDemandMarkers(ctx, payment, demand.DemandID); err != nil {
+		return nil, err
+	}
+	if err := s.emitDemandEvent(ctx, "DEFICIT_ASSESSED", &demand, now); err != nil {
+		return nil, err
+	}
+	return &demand, nil
+}
+
+// RecordDeficitPayment closes an open demand against a recorded challan
+// covering the full demand. The amnesty waiver is recomputed for the
+// payment date first, so a scheme that has since opened applies and one
+// that has since closed no longer does. The challan is consumed with
+// the demand ID as its purpose so it cannot be reused, and the original
+// challan can be assessed again. Callable by treasury or admin. Emits
+// DEFICIT_PAID.
+func (s *StampDutyContract) RecordDeficitPayment(ctx contractapi.TransactionContextInterface, stateCode, demandID, challanNumber string) error {
+	if _, err := requireAnyRole(ctx, "treasury", "admin"); err != nil {
+		return err
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+
+	demand, err := s.GetDemand(ctx, stateCode, demandID)
+	if err != nil {
+		return err
+	}
+	if demand.Status != "OPEN" {
+		return fmt.Errorf("DEMAND_INVALID_STATE: demand %s is %s", demandID, demand.Status)
+	}
+	if demand.InstrumentType == "" {
+		demand.InstrumentType = "SALE_DEED"
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	nowTime := time.Unix(timestamp.Seconds, 0)
+	now := nowTime.Format(time.RFC3339)
+	if err := s.applyAmnesty(ctx, demand, nowTime); err != nil {
+		return err
+	}
+
+	verification, err := s.VerifyPayment(ctx, stateCode, challanNumber, demand.TotalDemand)
+	if err != nil {
+		return err
+	}
+	if !verification.Valid {
+		return fmt.Errorf("%s: challan %s cannot settle demand %s of %d paisa", verification.Reason, challanNumber, demandID, demand.TotalDemand)
+	}
+	if err := s.MarkPaymentConsumed(ctx, stateCode, challanNumber, demandID); err != nil {
+		return err
+	}
+
+	demand.Status = "PAID"
+	demand.PaidChallan = challanNumber
+	demand.PaidAt = now
+	demand.FabricTxID = ctx.GetStub().GetTxID()
+
+	if err := s.putDemand(ctx, demand); err != nil {
+		return err
+	}
+	original, err := s.GetPayment(ctx, demand.StateCode, demand.ChallanNumber)
+	if err != nil {
+		return err
+	}
+	if err := deleteOpenDemandMarkers(ctx, original); err != nil {
+		return err
+	}
+	return s.emitDemandEvent(ctx, "DEFICIT_PAID", demand, now)
+}
+
+// GetDemand retrieves a deficit demand by state and ID.
+func (s *StampDutyContract) GetDemand(ctx contractapi.TransactionContextInterface, stateCode, demandID string) (*DemandRecord, error) {
+	if stateCode == "" || demandID == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode and demandID are required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("DEMAND", []string{stateCode, demandID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create demand key: %v", err)
+	}
+	demandBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read demand: %v", err)
+	}
+	if demandBytes == nil {
+		return nil, fmt.Errorf("DEMAND_NOT_FOUND: %s/%s", stateCode, demandID)
+	}
+
+	var demand DemandRecord
+	if err := json.Unmarshal(demandBytes, &demand); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal demand: %v", err)
+	}
+	return &demand, nil
+}
+
+// QueryOpenDemands returns a state's unpaid deficit demands.
+func (s *StampDutyContract) QueryOpenDemands(ctx contractapi.TransactionContextInterface, stateCode string) ([]*DemandRecord, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("DEMAND", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query demands: %v", err)
+	}
+	defer iterator.Close()
+
+	var demands []*DemandRecord
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate demands: %v", err)
+		}
+		var demand DemandRecord
+		if err := json.Unmarshal(kv.Value, &demand); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal demand: %v", err)
+		}
+		if demand.Status == "OPEN" {
+			demands = append(demands, &demand)
+		}
+	}
+	return demands, nil
+}
+
+// putDemand stores a demand under DEMAND~{stateCode}~{demandId}.
+func (s *StampDutyContract) putDemand(ctx contractapi.TransactionContextInterface, demand *DemandRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey("DEMAND", []string{demand.StateCode, demand.DemandID})
+	if err != nil {
+		return fmt.Errorf("failed to create demand key: %v", err)
+	}
+	demandBytes, err := json.Marshal(demand)
+	if err != nil {
+		return fmt.Errorf("failed to marshal demand: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, demandBytes); err != nil {
+		return fmt.Errorf("failed to put demand state: %v", err)
+	}
+	return nil
+}
+
+// openDemandKeys returns the OPEN_DEMAND marker keys for a challan and,
+// when it was paid for a transfer, for that transfer:
+// OPEN_DEMAND~CHALLAN~{stateCode}~{challanNumber} and
+// OPEN_DEMAND~TRANSFER~{transferId}.
+func openDemandKeys(ctx contractapi.TransactionContextInterface, payment *StampDutyPayment) ([]string, error) {
+	refs := [][]string{{"CHALLAN", payment.StateCode, payment.ChallanNumber}}
+	if payment.Purpose != "" {
+		refs = append(refs, []string{"TRANSFER", payment.Purpose})
+	}
+	var keys []string
+	for _, ref := range refs {
+		key, err := ctx.GetStub().CreateCompositeKey("OPEN_DEMAND", ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create open demand key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// requireNoOpenDemand rejects an assessment against a challan, or the
+// transfer it was paid for, that already has an OPEN demand.
+func requireNoOpenDemand(ctx contractapi.TransactionContextInterface, payment *StampDutyPayment) error {
+	keys, err := openDemandKeys(ctx, payment)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		demandID, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return fmt.Errorf("failed to read open demand marker: %v", err)
+		}
+		if demandID != nil {
+			return fmt.Errorf("DEMAND_EXISTS: challan %s already has open demand %s", payment.ChallanNumber, string(demandID))
+		}
+	}
+	return nil
+}
+
+// putOpenDemandMarkers records demandID as the OPEN demand for a payment.
+func putOpenDemandMarkers(ctx contractapi.TransactionContextInterface, payment *StampDutyPayment, demandID string) error {
+	keys, err := openDemandKeys(ctx, payment)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := ctx.GetStub().PutState(key, []byte(demandID)); err != nil {
+			return fmt.Errorf("failed to put open demand marker: %v", err)
+		}
+	}
+	return nil
+}
+
+// deleteOpenDemandMarkers clears a payment's OPEN demand markers.
+func deleteOpenDemandMarkers(ctx contractapi.TransactionContextInterface, payment *StampDutyPayment) error {
+	keys, err := openDemandKeys(ctx, payment)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := ctx.GetStub().DelState(key); err != nil {
+			return fmt.Errorf("failed to clear open demand marker: %v", err)
+		}
+	}
+	return nil
+}
+
+// emitDemandEvent emits a DemandEvent with the given name.
+func (s *StampDutyContract) emitDemandEvent(ctx contractapi.TransactionContextInterface, eventName string, demand *DemandRecord, now string) error {
+	event := DemandEvent{
+		Type:            eventName,
+		DemandID:        demand.DemandID,
+		StateCode:       demand.StateCode,
+		ChallanNumber:   demand.ChallanNumber,
+		PayerHash:       demand.PayerHash,
+		DeficitAmount:   demand.DeficitAmount,
+		InterestAmount:  demand.InterestAmount,
+		AmnestySchemeID: demand.AmnestySchemeID,
+		PenaltyWaived:   demand.PenaltyWaived,
+		TotalDemand:     demand.TotalDemand,
+		Status:          demand.Status,
+		FabricTxID:      ctx.GetStub().GetTxID(),
+		Timestamp:       now,
+		ChannelID:       ctx.GetStub().GetChannelID(),
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// monthsOrPartThereof counts calendar months from start to end, with a
+// started month counted in full.
+func monthsOrPartThereof(start, end time.Time) int32 {
+	if !end.After(start) {
+		return 0
+	}
+	months := int32((end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month()))
+	if start.AddDate(0, int(months), 0).After(end) {
+		months--
+	}
+	if start.AddDate(0, int(months), 0).Before(end) {
+		months++
+	}
+	return months
+}
+
+// deficitInterest computes simple interest on a deficit for the given
+// months, capped at the config's maximum (basis points of the deficit).
+func deficitInterest(deficit int64, months int32, config *PenaltyConfig) int64 {
+	interest := (deficit * int64(config.MonthlyInterestBasisPts) * int64(months)) / 10000
+	if config.MaxInterestBasisPts > 0 {
+		maxInterest := (deficit * int64(config.MaxInterestBasisPts)) / 10000
+		if interest > maxInterest {
+			interest = maxInterest
+		}
+	}
+	return interest
+}
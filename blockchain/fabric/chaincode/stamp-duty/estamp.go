@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// E-STAMP CERTIFICATE (UIN) REGISTRY
+// ============================================================
+
+// RegisterEStamp records an issued e-stamp certificate so registrars can
+// detect forged or reused stamp papers. Registering a UIN that already
+// exists fails. Only users with the "treasury" or "admin" role can
+// register e-stamps. The denomination is in paisa (int64).
+func (s *StampDutyContract) RegisterEStamp(ctx contractapi.TransactionContextInterface, estampJSON string) error {
+	if _, err := requireAnyRole(ctx, "treasury", "admin"); err != nil {
+		return err
+	}
+
+	var estamp EStampCertificate
+	if err := json.Unmarshal([]byte(estampJSON), &estamp); err != nil {
+		return fmt.Errorf("INVALID_INPUT: failed to parse e-stamp JSON: %v", err)
+	}
+
+	if estamp.UIN == "" || estamp.StateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: uin and stateCode are required")
+	}
+	if err := requireStateAccess(ctx, estamp.StateCode); err != nil {
+		return err
+	}
+	if estamp.Denomination <= 0 {
+		return fmt.Errorf("VALIDATION_ERROR: denomination must be positive, got %d", estamp.Denomination)
+	}
+	if err := validateAadhaarHash(estamp.PurchaserHash, "purchaserHash"); err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("ESTAMP", []string{estamp.UIN})
+	if err != nil {
+		return fmt.Errorf("failed to create e-stamp key: %v", err)
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read e-stamp: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("ESTAMP_EXISTS: UIN %s already registered", estamp.UIN)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	estamp.DocType = "eStampCertificate"
+	if estamp.IssuedAt == "" {
+		estamp.IssuedAt = now
+	}
+	estamp.Used = false
+	estamp.UsedFor = ""
+	estamp.UsedAt = ""
+	estamp.RegisteredBy = getCallerID(ctx)
+	estamp.RegisteredAt = now
+	estamp.FabricTxID = txID
+
+	estampBytes, err := json.Marshal(estamp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal e-stamp: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, estampBytes); err != nil {
+		return fmt.Errorf("failed to put e-stamp state: %v", err)
+	}
+
+	event := EStampEvent{
+		Type:         "ESTAMP_REGISTERED",
+		UIN:          estamp.UIN,
+		StateCode:    estamp.StateCode,
+		Denomination: estamp.Denomination,
+		FabricTxID:   txID,
+		Timestamp:    now,
+		ChannelID:    ctx.GetStub().GetChannelID(),
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	return ctx.GetStub().SetEvent("ESTAMP_REGISTERED", eventJSON)
+}
+
+// VerifyEStamp returns the registered details of an e-stamp UIN,
+// including whether it has already been used. An unknown UIN returns
+// ESTAMP_NOT_FOUND, which registrars should treat as a possible forgery.
+func (s *StampDutyContract) VerifyEStamp(ctx contractapi.TransactionContextInterface, uin string) (*EStampCertificate, error) {
+	if uin == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: uin is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("ESTAMP", []string{uin})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create e-stamp key: %v", err)
+	}
+	estampBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read e-stamp: %v", err)
+	}
+	if estampBytes == nil {
+		return nil, fmt.Errorf("ESTAMP_NOT_FOUND: UIN %s is not registered", uin)
+	}
+
+	var estamp EStampCertificate
+	if err := json.Unmarshal(estampBytes, &estamp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal e-stamp: %v", err)
+	}
+	return &estamp, nil
+}
+
+// ConsumeEStamp ties an e-stamp UIN to a transfer. A UIN can be consumed
+// exactly once; later attempts fail with ESTAMP_ALREADY_USED naming the
+// transfer that used it. Callable by registrars (typically via the
+// land-registry chaincode during ExecuteTransfer), treasury, or admin.
+func (s *StampDutyContract) ConsumeEStamp(ctx contractapi.TransactionContextInterface, uin, transferID string) error {
+	if _, err := requireAnyRole(ctx, "registrar", "treasury", "admin"); err != nil {
+		return err
+	}
+
+	if transferID == "" {
+		return fmt.Errorf("VALIDATION_ERROR: transferID is required")
+	}
+
+	estamp, err := s.VerifyEStamp(ctx, uin)
+	if err != nil {
+		return err
+	}
+	if err := requireStateAccess(ctx, estamp.StateCode); err != nil {
+		return err
+	}
+	if estamp.Used {
+		return fmt.Errorf("ESTAMP_ALREADY_USED: UIN %s was used for %s at %s", uin, estamp.UsedFor, estamp.UsedAt)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	estamp.Used = true
+	estamp.UsedFor = transferID
+	estamp.UsedAt = now
+	estamp.FabricTxID = txID
+
+	key, err := ctx.GetStub().CreateCompositeKey("ESTAMP", []string{uin})
+	if err != nil {
+		return fmt.Errorf("failed to create e-stamp key: %v", err)
+	}
+	estampBytes, err := json.Marshal(estamp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal e-stamp: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, estampBytes); err != nil {
+		return fmt.Errorf("failed to update e-stamp: %v", err)
+	}
+
+	event := EStampEvent{
+		Type:         "ESTAMP_CONSUMED",
+		UIN:          uin,
+		StateCode:    estamp.StateCode,
+		Denomination: estamp.Denomination,
+		TransferID:   transferID,
+		FabricTxID:   txID,
+		Timestamp:    now,
+		ChannelID:    ctx.GetStub().GetChannelID(),
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	return ctx.GetStub().SetEvent("ESTAMP_CONSUMED", eventJSON)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ============================================================
+// DISPLAY FORMATTING (INDIAN NUMBERING SYSTEM)
+// ============================================================
+// Amounts are stored in paisa; these helpers derive the rupee strings
+// shown on receipts and the citizen portal. The paisa fields remain
+// authoritative.
+
+var onesWords = []string{
+	"Zero", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine",
+	"Ten", "Eleven", "Twelve", "Thirteen", "Fourteen", "Fifteen", "Sixteen",
+	"Seventeen", "Eighteen", "Nineteen",
+}
+
+var tensWords = []string{
+	"", "", "Twenty", "Thirty", "Forty", "Fifty", "Sixty", "Seventy", "Eighty", "Ninety",
+}
+
+// newDisplayAmounts renders the rupee amounts of a breakdown.
+func newDisplayAmounts(b *StampDutyBreakdown) *DisplayAmounts {
+	return &DisplayAmounts{
+		CircleRateValue: formatRupees(b.CircleRateValue),
+		ApplicableValue: formatRupees(b.ApplicableValue),
+		StampDutyAmount: formatRupees(b.StampDutyAmount),
+		RegistrationFee: formatRupees(b.RegistrationFee),
+		Surcharge:       formatRupees(b.Surcharge),
+		TotalFees:       formatRupees(b.TotalFees),
+		TotalInWords:    amountInWords(b.TotalFees),
+	}
+}
+
+// formatRupees formats paisa as rupees with Indian digit grouping,
+// e.g. 43600000 -> "₹4,36,000" and 43600050 -> "₹4,36,000.50".
+func formatRupees(paisa int64) string {
+	sign := ""
+	if paisa < 0 {
+		sign = "-"
+		paisa = -paisa
+	}
+	rupees := groupIndian(strconv.FormatInt(paisa/100, 10))
+	if rem := paisa % 100; rem != 0 {
+		return sign + "₹" + rupees + "." + leftPad2(rem)
+	}
+	return sign + "₹" + rupees
+}
+
+// groupIndian inserts commas into a digit string using the Indian
+// system: the last three digits, then groups of two (12,34,56,789).
+func groupIndian(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	var groups []string
+	for len(head) > 2 {
+		groups = append([]string{head[len(head)-2:]}, groups...)
+		head = head[:len(head)-2]
+	}
+	groups = append([]string{head}, groups...)
+	return strings.Join(groups, ",") + "," + tail
+}
+
+// amountInWords renders paisa as words in the Indian numbering system,
+// e.g. 43600000 -> "Four Lakh Thirty-Six Thousand Rupees Only" and
+// 150 -> "One Rupee and Fifty Paise Only".
+func amountInWords(paisa int64) string {
+	prefix := ""
+	if paisa < 0 {
+		prefix = "Minus "
+		paisa = -paisa
+	}
+	rupees, rem := paisa/100, paisa%100
+	if rupees == 0 && rem != 0 {
+		return prefix + numberInWords(rem) + " Paise Only"
+	}
+
+	unit := "Rupees"
+	if rupees == 1 {
+		unit = "Rupee"
+	}
+	words := prefix + numberInWords(rupees) + " " + unit
+	if rem != 0 {
+		words += " and " + numberInWords(rem) + " Paise"
+	}
+	return words + " Only"
+}
+
+// numberInWords spells a non-negative integer using crore, lakh,
+// thousand and hundred. Counts above 99 crore recurse on the crore
+// part ("One Lakh Crore").
+func numberInWords(n int64) string {
+	if n == 0 {
+		return onesWords[0]
+	}
+	var parts []string
+	if n >= 10000000 {
+		parts = append(parts, numberInWords(n/10000000)+" Crore")
+		n %= 10000000
+	}
+	if n >= 100000 {
+		parts = append(parts, belowHundredInWords(n/100000)+" Lakh")
+		n %= 100000
+	}
+	if n >= 1000 {
+		parts = append(parts, belowHundredInWords(n/1000)+" Thousand")
+		n %= 1000
+	}
+	if n >= 100 {
+		parts = append(parts, onesWords[n/100]+" Hundred")
+		n %= 100
+	}
+	if n > 0 {
+		parts = append(parts, belowHundredInWords(n))
+	}
+	return strings.Join(parts, " ")
+}
+
+// belowHundredInWords spells 1-99, hyphenating compound tens.
+func belowHundredInWords(n int64) string {
+	if n < 20 {
+		return onesWords[n]
+	}
+	if n%10 == 0 {
+		return tensWords[n/10]
+	}
+	return tensWords[n/10] + "-" + onesWords[n%10]
+}
+
+func leftPad2(n int64) string {
+	if n < 10 {
+		return "0" + strconv.FormatInt(n, 10)
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+func TestGroupIndian(t *testing.T) {
+	tests := map[string]string{
+		"0":             "0",
+		"999":           "999",
+		"1000":          "1,000",
+		"99999":         "99,999",
+		"100000":        "1,00,000",
+		"9999999":       "99,99,999",
+		"10000000":      "1,00,00,000",
+		"123456789":     "12,34,56,789",
+		"1000000000000": "10,00,00,00,00,000",
+	}
+	for digits, want := range tests {
+		if got := groupIndian(digits); got != want {
+			t.Errorf("groupIndian(%s) = %s, want %s", digits, got, want)
+		}
+	}
+}
+
+func TestFormatRupees(t *testing.T) {
+	tests := []struct {
+		paisa int64
+		want  string
+	}{
+		{0, "₹0"},
+		{5, "₹0.05"},
+		{50, "₹0.50"},
+		{100, "₹1"},
+		{150, "₹1.50"},
+		{99999, "₹999.99"},
+		{100000, "₹1,000"},
+		{9999900, "₹99,999"},
+		{10000000, "₹1,00,000"},
+		{43600000, "₹4,36,000"},
+		{43600050, "₹4,36,000.50"},
+		{999999999, "₹99,99,999.99"},
+		{1000000000, "₹1,00,00,000"},
+		{123456789012, "₹1,23,45,67,890.12"},
+		{-150, "-₹1.50"},
+	}
+	for _, tc := range tests {
+		if got := formatRupees(tc.paisa); got != tc.want {
+			t.Errorf("formatRupees(%d) = %s, want %s", tc.paisa, got, tc.want)
+		}
+	}
+}
+
+func TestAmountInWords(t *testing.T) {
+	tests := []struct {
+		paisa int64
+		want  string
+	}{
+		{0, "Zero Rupees Only"},
+		{1, "One Paise Only"},
+		{99, "Ninety-Nine Paise Only"},
+		{100, "One Rupee Only"},
+		{150, "One Rupee and Fifty Paise Only"},
+		{2000, "Twenty Rupees Only"},
+		{10100, "One Hundred One Rupees Only"},
+		{99999900, "Nine Lakh Ninety-Nine Thousand Nine Hundred Ninety-Nine Rupees Only"},
+		{100000, "One Thousand Rupees Only"},
+		{9999900, "Ninety-Nine Thousand Nine Hundred Ninety-Nine Rupees Only"},
+		{10000000, "One Lakh Rupees Only"},
+		{43600000, "Four Lakh Thirty-Six Thousand Rupees Only"},
+		{100101100, "Ten Lakh One Thousand Eleven Rupees Only"},
+		{999999900, "Ninety-Nine Lakh Ninety-Nine Thousand Nine Hundred Ninety-Nine Rupees Only"},
+		{999999999, "Ninety-Nine Lakh Ninety-Nine Thousand Nine Hundred Ninety-Nine Rupees and Ninety-Nine Paise Only"},
+		{1000000000, "One Crore Rupees Only"},
+		{1000000001, "One Crore Rupees and One Paise Only"},
+		{12345678900, "Twelve Crore Thirty-Four Lakh Fifty-Six Thousand Seven Hundred Eighty-Nine Rupees Only"},
+		{100000000000000, "One Lakh Crore Rupees Only"},
+		{-100, "Minus One Rupee Only"},
+	}
+	for _, tc := range tests {
+		if got := amountInWords(tc.paisa); got != tc.want {
+			t.Errorf("amountInWords(%d) = %q, want %q", tc.paisa, got, tc.want)
+		}
+	}
+}
+
+func TestNewDisplayAmountsIsDerivedFromPaisa(t *testing.T) {
+	breakdown := &StampDutyBreakdown{
+		CircleRateValue: 800000000,
+		ApplicableValue: 800000000,
+		StampDutyAmount: 40000000,
+		RegistrationFee: 3600000,
+		Surcharge:       0,
+		TotalFees:       43600000,
+	}
+	display := newDisplayAmounts(breakdown)
+	if display.ApplicableValue != "₹80,00,000" || display.StampDutyAmount != "₹4,00,000" ||
+		display.RegistrationFee != "₹36,000" || display.Surcharge != "₹0" || display.TotalFees != "₹4,36,000" {
+		t.Fatalf("display amounts = %+v", display)
+	}
+	if display.TotalInWords != "Four Lakh Thirty-Six Thousand Rupees Only" {
+		t.Fatalf("TotalInWords = %q", display.TotalInWords)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// ABAC Helpers
+// ============================================================
+// Shared with the land-registry chaincode: callers are authorised by
+// the "role" and "stateCode" attributes in their X.509 certificate.
+
+// requireRole verifies that the calling identity has the specified role
+// attribute in their X.509 certificate. Roles include: admin,
+// registrar, treasury.
+func requireRole(ctx contractapi.TransactionContextInterface, requiredRole string) error {
+	clientIdentity := ctx.GetClientIdentity()
+	role, found, err := clientIdentity.GetAttributeValue("role")
+	if err != nil {
+		return fmt.Errorf("ACCESS_DENIED: failed to read role attribute: %v", err)
+	}
+	if !found {
+		return fmt.Errorf("ACCESS_DENIED: caller identity has no 'role' attribute")
+	}
+	if role != requiredRole {
+		return fmt.Errorf("ACCESS_DENIED: required role '%s', caller has role '%s'", requiredRole, role)
+	}
+	return nil
+}
+
+// requireAnyRole verifies that the calling identity has at least one
+// of the specified roles in their X.509 certificate.
+func requireAnyRole(ctx contractapi.TransactionContextInterface, allowedRoles ...string) (string, error) {
+	clientIdentity := ctx.GetClientIdentity()
+	role, found, err := clientIdentity.GetAttributeValue("role")
+	if err != nil {
+		return "", fmt.Errorf("ACCESS_DENIED: failed to read role attribute: %v", err)
+	}
+	if !found {
+		return "", fmt.Errorf("ACCESS_DENIED: caller identity has no 'role' attribute")
+	}
+	for _, allowed := range allowedRoles {
+		if role == allowed {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("ACCESS_DENIED: role '%s' is not in allowed roles %v", role, allowedRoles)
+}
+
+// requireStateAccess verifies that the calling identity's stateCode
+// attribute matches the state whose rates or records are being changed.
+// This enforces jurisdictional boundaries — a Karnataka admin cannot
+// set Maharashtra circle rates.
+func requireStateAccess(ctx contractapi.TransactionContextInterface, stateCode string) error {
+	clientIdentity := ctx.GetClientIdentity()
+	callerState, found, err := clientIdentity.GetAttributeValue("stateCode")
+	if err != nil {
+		return fmt.Errorf("ACCESS_DENIED: failed to read stateCode attribute: %v", err)
+	}
+	if !found {
+		return fmt.Errorf("ACCESS_DENIED: caller identity has no 'stateCode' attribute")
+	}
+	if callerState != stateCode {
+		return fmt.Errorf("STATE_MISMATCH: caller from %s cannot modify %s records", callerState, stateCode)
+	}
+	return nil
+}
+
+// getCallerID extracts a readable identifier from the caller's
+// X.509 certificate for audit purposes.
+func getCallerID(ctx contractapi.TransactionContextInterface) string {
+	role, _, _ := ctx.GetClientIdentity().GetAttributeValue("role")
+	stateCode, _, _ := ctx.GetClientIdentity().GetAttributeValue("stateCode")
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
+	if role != "" && stateCode != "" {
+		return fmt.Sprintf("%s:%s:%s", mspID, role, stateCode)
+	}
+	return mspID
+}
+
+// ============================================================
+// Aadhaar Hash Validation
+// ============================================================
+
+// aadhaarHashPattern matches a lowercase hex SHA-256 digest.
+var aadhaarHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// rawAadhaarPattern matches a raw 12-digit Aadhaar number, optionally
+// grouped with spaces or hyphens.
+var rawAadhaarPattern = regexp.MustCompile(`^\d{4}[ -]?\d{4}[ -]?\d{4}$`)
+
+// validateAadhaarHash checks that an Aadhaar reference is a SHA-256
+// hash (64 lowercase hex characters) and never a raw Aadhaar number.
+// Errors name only the field path and never echo the value, so a raw
+// number submitted by mistake does not end up in logs.
+func validateAadhaarHash(hash, field string) error {
+	if hash == "" {
+		return fmt.Errorf("AADHAAR_REQUIRED: %s is required", field)
+	}
+	if rawAadhaarPattern.MatchString(strings.TrimSpace(hash)) {
+		return fmt.Errorf("AADHAAR_FORMAT_INVALID: %s appears to be a raw Aadhaar number; submit its SHA-256 hash", field)
+	}
+	if !aadhaarHashPattern.MatchString(hash) {
+		return fmt.Errorf("AADHAAR_FORMAT_INVALID: %s must be a 64-character lowercase hex SHA-256 hash", field)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestRoleMatrix(t *testing.T) {
+	tests := []struct {
+		role             string
+		adminOnly        string
+		registrarOrAdmin string
+		treasuryOrAdmin  string
+	}{
+		// expected codes for requireRole("admin"),
+		// requireAnyRole("registrar", "admin") and
+		// requireAnyRole("treasury", "admin")
+		{"admin", "", "", ""},
+		{"registrar", "ACCESS_DENIED", "", "ACCESS_DENIED"},
+		{"treasury", "ACCESS_DENIED", "ACCESS_DENIED", ""},
+		{"citizen", "ACCESS_DENIED", "ACCESS_DENIED", "ACCESS_DENIED"},
+		{"bank", "ACCESS_DENIED", "ACCESS_DENIED", "ACCESS_DENIED"},
+		{"", "ACCESS_DENIED", "ACCESS_DENIED", "ACCESS_DENIED"},
+	}
+	ledger := newTestLedger(t)
+	for _, tc := range tests {
+		id := newTestIdentity("RevenueOrgMSP", tc.role, "KA")
+		var adminErr, registrarErr, treasuryErr error
+		ledger.mustSubmit(id, func(ctx contractapi.TransactionContextInterface) error {
+			adminErr = requireRole(ctx, "admin")
+			_, registrarErr = requireAnyRole(ctx, "registrar", "admin")
+			_, treasuryErr = requireAnyRole(ctx, "treasury", "admin")
+			return nil
+		})
+		if got := errorCode(adminErr); got != tc.adminOnly {
+			t.Errorf("role %q: requireRole(admin) = %q, want %q", tc.role, got, tc.adminOnly)
+		}
+		if got := errorCode(registrarErr); got != tc.registrarOrAdmin {
+			t.Errorf("role %q: requireAnyRole(registrar, admin) = %q, want %q", tc.role, got, tc.registrarOrAdmin)
+		}
+		if got := errorCode(treasuryErr); got != tc.treasuryOrAdmin {
+			t.Errorf("role %q: requireAnyRole(treasury, admin) = %q, want %q", tc.role, got, tc.treasuryOrAdmin)
+		}
+	}
+}
+
+func TestRequireStateAccess(t *testing.T) {
+	tests := []struct {
+		callerState string
+		want        string
+	}{
+		{"KA", ""},
+		{"MH", "STATE_MISMATCH"},
+		{"", "ACCESS_DENIED"},
+	}
+	ledger := newTestLedger(t)
+	for _, tc := range tests {
+		var err error
+		ledger.mustSubmit(newTestIdentity("RevenueOrgMSP", "admin", tc.callerState), func(ctx contractapi.TransactionContextInterface) error {
+			err = requireStateAccess(ctx, "KA")
+			return nil
+		})
+		if got := errorCode(err); got != tc.want {
+			t.Errorf("caller state %q: requireStateAccess(KA) = %q, want %q", tc.callerState, got, tc.want)
+		}
+	}
+}
+
+func TestSetCircleRateRequiresAdminOfTheState(t *testing.T) {
+	ledger := newTestLedger(t)
+	setRate := func(id *testIdentity) error {
+		return ledger.submit(id, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.SetCircleRate(ctx, "MH", "MUM", "AND", 1500000, "", "")
+		})
+	}
+
+	expectCode(t, setRate(newTestIdentity("RevenueOrgMSP", "admin", "KA")), "STATE_MISMATCH")
+	expectCode(t, setRate(newTestIdentity("RevenueOrgMSP", "registrar", "MH")), "ACCESS_DENIED")
+	expectCode(t, setRate(newTestIdentity("RevenueOrgMSP", "admin", "MH")), "")
+}
+
+func TestCalculateAndRecordAllowsRegistrarAndAdmin(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.mustSubmit(newTestIdentity("RevenueOrgMSP", "admin", "KA"), func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetCircleRate(ctx, "KA", "BLR", "ANK", 800000, "", "")
+	})
+	record := func(id *testIdentity) error {
+		return ledger.submit(id, func(ctx contractapi.TransactionContextInterface) error {
+			_, err := ledger.contract.CalculateAndRecord(ctx, "KA", "BLR", "ANK", 100, 50000000)
+			return err
+		})
+	}
+
+	expectCode(t, record(newTestIdentity("RegistrarOrgMSP", "registrar", "KA")), "")
+	expectCode(t, record(newTestIdentity("RevenueOrgMSP", "admin", "KA")), "")
+	expectCode(t, record(newTestIdentity("TreasuryOrgMSP", "treasury", "KA")), "ACCESS_DENIED")
+	expectCode(t, record(newTestIdentity("CitizenOrgMSP", "citizen", "KA")), "ACCESS_DENIED")
+	expectCode(t, record(newTestIdentity("RegistrarOrgMSP", "registrar", "MH")), "STATE_MISMATCH")
+}
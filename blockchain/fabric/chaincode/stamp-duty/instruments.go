@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// INSTRUMENT RATES, CAPS AND ROUNDING
+// ============================================================
+// The stamp duty config prices a sale deed. Other instruments (gift,
+// settlement, partition, mortgage deeds) carry their own rates in most
+// states, often with a ceiling on the duty or the registration fee, and
+// states collect fees in whole rupees or hundreds of rupees. A state's
+// instrument rates cover all of these; an instrument without an entry
+// other than SALE_DEED cannot be priced.
+
+// defaultInstrumentType is the instrument priced when none is given.
+const defaultInstrumentType = "SALE_DEED"
+
+// maxRoundingUnit caps the rounding multiple at ₹1,000.
+const maxRoundingUnit = 100000
+
+// SetInstrumentRates replaces a state's instrument rates. ratesJSON is a
+// JSON array of InstrumentRate; each instrument type may appear once.
+// roundingUnit is the multiple of paisa every fee is rounded up to (100
+// for whole rupees; 0 for none). Only admins can set the rates.
+func (s *StampDutyContract) SetInstrumentRates(ctx contractapi.TransactionContextInterface, stateCode, ratesJSON string, roundingUnit int64) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+	if roundingUnit < 0 || roundingUnit > maxRoundingUnit {
+		return fmt.Errorf("VALIDATION_ERROR: roundingUnit must be between 0 and %d paisa", maxRoundingUnit)
+	}
+
+	var rates []InstrumentRate
+	if err := json.Unmarshal([]byte(ratesJSON), &rates); err != nil {
+		return fmt.Errorf("INVALID_INPUT: failed to parse instrument rates JSON: %v", err)
+	}
+
+	seen := make(map[string]bool, len(rates))
+	for i := range rates {
+		rate := &rates[i]
+		rate.InstrumentType = strings.ToUpper(strings.TrimSpace(rate.InstrumentType))
+		if rate.InstrumentType == "" {
+			return fmt.Errorf("VALIDATION_ERROR: instrument rate %d has no instrumentType", i)
+		}
+		if seen[rate.InstrumentType] {
+			return fmt.Errorf("VALIDATION_ERROR: duplicate instrument rate %s", rate.InstrumentType)
+		}
+		seen[rate.InstrumentType] = true
+		if bp := rate.StampDutyBasisPts; bp != nil && (*bp < 0 || *bp > 2000) {
+			return fmt.Errorf("VALIDATION_ERROR: %s stampDutyBasisPoints must be between 0 and 2000 (0-20%%)", rate.InstrumentType)
+		}
+		if bp := rate.RegistrationBasisPts; bp != nil && (*bp < 0 || *bp > 1000) {
+			return fmt.Errorf("VALIDATION_ERROR: %s registrationBasisPoints must be between 0 and 1000 (0-10%%)", rate.InstrumentType)
+		}
+		if rate.StampDutyCap < 0 || rate.RegistrationFeeCap < 0 {
+			return fmt.Errorf("VALIDATION_ERROR: %s caps cannot be negative", rate.InstrumentType)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	rateSet := InstrumentRateSet{
+		DocType:      "instrumentRateSet",
+		StateCode:    stateCode,
+		Rates:        rates,
+		RoundingUnit: roundingUnit,
+		SetBy:        getCallerID(ctx),
+		UpdatedAt:    now,
+		FabricTxID:   ctx.GetStub().GetTxID(),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("INSTRUMENT_RATES", []string{stateCode})
+	if err != nil {
+		return fmt.Errorf("failed to create instrument rates key: %v", err)
+	}
+	rateBytes, err := json.Marshal(rateSet)
+	if err != nil {
+		return fmt.Errorf("failed to marshal instrument rates: %v", err)
+	}
+	return ctx.GetStub().PutState(key, rateBytes)
+}
+
+// GetInstrumentRates returns a state's instrument rates, or an empty set
+// if none are configured.
+func (s *StampDutyContract) GetInstrumentRates(ctx contractapi.TransactionContextInterface, stateCode string) (*InstrumentRateSet, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("INSTRUMENT_RATES", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create instrument rates key: %v", err)
+	}
+	rateBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instrument rates: %v", err)
+	}
+	if rateBytes == nil {
+		return &InstrumentRateSet{DocType: "instrumentRateSet", StateCode: stateCode, Rates: []InstrumentRate{}}, nil
+	}
+
+	var rateSet InstrumentRateSet
+	if err := json.Unmarshal(rateBytes, &rateSet); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal instrument rates: %v", err)
+	}
+	return &rateSet, nil
+}
+
+// instrumentRate returns the rate a state sets for instrumentType (empty
+// means SALE_DEED) and the state's rounding unit. A sale deed without an
+// entry is priced at the config's rates, uncapped; any other instrument
+// must be configured.
+func (s *StampDutyContract) instrumentRate(ctx contractapi.TransactionContextInterface, stateCode, instrumentType string) (*InstrumentRate, int64, error) {
+	instrumentType = strings.ToUpper(strings.TrimSpace(instrumentType))
+	if instrumentType == "" {
+		instrumentType = defaultInstrumentType
+	}
+	rateSet, err := s.GetInstrumentRates(ctx, stateCode)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range rateSet.Rates {
+		if rateSet.Rates[i].InstrumentType == instrumentType {
+			return &rateSet.Rates[i], rateSet.RoundingUnit, nil
+		}
+	}
+	if instrumentType != defaultInstrumentType {
+		return nil, 0, fmt.Errorf("INSTRUMENT_NOT_CONFIGURED: instrument type %s has no rates in %s", instrumentType, stateCode)
+	}
+	return &InstrumentRate{InstrumentType: defaultInstrumentType}, rateSet.RoundingUnit, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// calculateForTransfer runs CalculateForTransfer for a 100.5 sq m
+// parcel in KA-BLR-ANK declared at ₹5 lakh.
+func (l *testLedger) calculateForTransfer(instrumentType string) (*StampDutyBreakdown, error) {
+	reqJSON, _ := json.Marshal(TransferFeeRequest{
+		StateCode: "KA", DistrictCode: "BLR", TehsilCode: "ANK",
+		AreaSqMeters: 100.5, DeclaredValue: 50000000, InstrumentType: instrumentType,
+		Buyers: []TransferBuyer{{Gender: "FEMALE"}}, ValuationDate: "2027-03-15",
+	})
+	var calculation *TransferFeeCalculation
+	err := l.submit(newTestIdentity("RegistrarOrgMSP", "registrar", "KA"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		calculation, err = l.contract.CalculateForTransfer(ctx, string(reqJSON))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return calculation.Breakdown, nil
+}
+
+// newInstrumentTestLedger returns a ledger with a ₹8,000 per sq m
+// circle rate in KA-BLR-ANK.
+func newInstrumentTestLedger(t *testing.T) *testLedger {
+	ledger := newTestLedger(t)
+	ledger.mustSubmit(newTestIdentity("RevenueOrgMSP", "admin", "KA"), func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetCircleRate(ctx, "KA", "BLR", "ANK", 800000, "", "")
+	})
+	return ledger
+}
+
+func TestCalculateForTransferWithoutInstrumentRates(t *testing.T) {
+	ledger := newInstrumentTestLedger(t)
+
+	// A sale deed is priced at the config's rates, uncapped and unrounded
+	breakdown, err := ledger.calculateForTransfer("")
+	if err != nil {
+		t.Fatalf("CalculateForTransfer: %v", err)
+	}
+	if breakdown.InstrumentType != "SALE_DEED" || breakdown.StampDutyAmount != 4502400 || breakdown.RegistrationFee != 804000 ||
+		breakdown.StampDutyCapped || breakdown.RoundingUnit != 0 {
+		t.Fatalf("breakdown = %+v", breakdown)
+	}
+	_, err = ledger.calculateForTransfer("GIFT_DEED")
+	expectCode(t, err, "INSTRUMENT_NOT_CONFIGURED")
+}
+
+func TestCalculateForTransferAppliesInstrumentRatesCapsAndRounding(t *testing.T) {
+	ledger := newInstrumentTestLedger(t)
+	ratesJSON := `[{"instrumentType":"gift_deed","stampDutyBasisPoints":100,"registrationFeeCap":500000},` +
+		`{"instrumentType":"SALE_DEED","stampDutyCap":3000000}]`
+	ledger.mustSubmit(newTestIdentity("RevenueOrgMSP", "admin", "KA"), func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetInstrumentRates(ctx, "KA", ratesJSON, 10000)
+	})
+
+	tests := []struct {
+		instrumentType                 string
+		stampDutyRate                  int32
+		stampDuty, registration, total int64
+		stampDutyCapped, feeCapped     bool
+	}{
+		// 5.6% of ₹8,04,000 capped at ₹30,000; 1% rounded up to ₹8,100
+		{"SALE_DEED", 560, 3000000, 810000, 3810000, true, false},
+		// 1% rounded up to ₹8,100; the registration fee capped at ₹5,000
+		{"GIFT_DEED", 100, 810000, 500000, 1310000, false, true},
+	}
+	for _, tc := range tests {
+		breakdown, err := ledger.calculateForTransfer(tc.instrumentType)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.instrumentType, err)
+		}
+		if breakdown.InstrumentType != tc.instrumentType || breakdown.StampDutyRate != tc.stampDutyRate ||
+			breakdown.StampDutyAmount != tc.stampDuty || breakdown.RegistrationFee != tc.registration || breakdown.TotalFees != tc.total ||
+			breakdown.StampDutyCapped != tc.stampDutyCapped || breakdown.RegistrationFeeCapped != tc.feeCapped || breakdown.RoundingUnit != 10000 {
+			t.Errorf("%s breakdown = %+v", tc.instrumentType, breakdown)
+		}
+	}
+	_, err := ledger.calculateForTransfer("MORTGAGE_DEED")
+	expectCode(t, err, "INSTRUMENT_NOT_CONFIGURED")
+}
+
+func TestSetInstrumentRatesValidation(t *testing.T) {
+	ledger := newTestLedger(t)
+	set := func(id *testIdentity, ratesJSON string, roundingUnit int64) error {
+		return ledger.submit(id, func(ctx contractapi.TransactionContextInterface) error {
+			return ledger.contract.SetInstrumentRates(ctx, "KA", ratesJSON, roundingUnit)
+		})
+	}
+	admin := newTestIdentity("RevenueOrgMSP", "admin", "KA")
+
+	expectCode(t, set(admin, `[{"instrumentType":"GIFT_DEED"},{"instrumentType":" gift_deed "}]`, 0), "VALIDATION_ERROR")
+	expectCode(t, set(admin, `[{"instrumentType":""}]`, 0), "VALIDATION_ERROR")
+	expectCode(t, set(admin, `[{"instrumentType":"GIFT_DEED","stampDutyBasisPoints":2001}]`, 0), "VALIDATION_ERROR")
+	expectCode(t, set(admin, `[{"instrumentType":"GIFT_DEED","registrationFeeCap":-1}]`, 0), "VALIDATION_ERROR")
+	expectCode(t, set(admin, `[]`, -100), "VALIDATION_ERROR")
+	expectCode(t, set(newTestIdentity("RegistrarOrgMSP", "registrar", "KA"), `[]`, 0), "ACCESS_DENIED")
+	expectCode(t, set(newTestIdentity("RevenueOrgMSP", "admin", "MH"), `[]`, 0), "STATE_MISMATCH")
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// LAND-USE CIRCLE RATE FACTORS
+// ============================================================
+// Circle rates are notified per tehsil for the base (residential) use;
+// states value commercial or industrial parcels at a multiple of that
+// rate and agricultural land at a fraction of it. A state's factors
+// scale the tehsil circle rate by land use in CalculateForTransfer. A
+// land use with no factor is valued at the notified rate.
+
+// landUses are the land-use categories recorded by the land-registry
+// chaincode.
+var landUses = map[string]bool{
+	"AGRICULTURAL": true, "RESIDENTIAL": true, "COMMERCIAL": true,
+	"INDUSTRIAL": true, "MIXED_USE": true, "FOREST": true,
+	"GOVERNMENT": true, "BARREN": true, "WATER_BODY": true,
+}
+
+// SetLandUseFactors replaces a state's land-use circle rate factors.
+// factorsJSON is a JSON array of LandUseFactor; each land use may appear
+// once. Only admins can set the factors.
+func (s *StampDutyContract) SetLandUseFactors(ctx contractapi.TransactionContextInterface, stateCode, factorsJSON string) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+
+	var factors []LandUseFactor
+	if err := json.Unmarshal([]byte(factorsJSON), &factors); err != nil {
+		return fmt.Errorf("INVALID_INPUT: failed to parse land use factors JSON: %v", err)
+	}
+
+	seen := make(map[string]bool, len(factors))
+	for i := range factors {
+		factor := &factors[i]
+		factor.LandUse = strings.ToUpper(strings.TrimSpace(factor.LandUse))
+		if !landUses[factor.LandUse] {
+			return fmt.Errorf("VALIDATION_ERROR: land use factor %d has unknown land use '%s'", i, factor.LandUse)
+		}
+		if seen[factor.LandUse] {
+			return fmt.Errorf("VALIDATION_ERROR: duplicate land use factor %s", factor.LandUse)
+		}
+		seen[factor.LandUse] = true
+		if factor.CircleRateBasisPts <= 0 || factor.CircleRateBasisPts > 50000 {
+			return fmt.Errorf("VALIDATION_ERROR: land use %s circleRateBasisPoints must be between 1 and 50000", factor.LandUse)
+		}
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	factorSet := LandUseFactorSet{
+		DocType:    "landUseFactorSet",
+		StateCode:  stateCode,
+		Factors:    factors,
+		SetBy:      getCallerID(ctx),
+		UpdatedAt:  now,
+		FabricTxID: ctx.GetStub().GetTxID(),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("LAND_USE_FACTORS", []string{stateCode})
+	if err != nil {
+		return fmt.Errorf("failed to create land use factors key: %v", err)
+	}
+	factorBytes, err := json.Marshal(factorSet)
+	if err != nil {
+		return fmt.Errorf("failed to marshal land use factors: %v", err)
+	}
+	return ctx.GetStub().PutState(key, factorBytes)
+}
+
+// GetLandUseFactors returns a state's land-use circle rate factors, or
+// an empty list if none are configured.
+func (s *StampDutyContract) GetLandUseFactors(ctx contractapi.TransactionContextInterface, stateCode string) ([]LandUseFactor, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("LAND_USE_FACTORS", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create land use factors key: %v", err)
+	}
+	factorBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read land use factors: %v", err)
+	}
+	if factorBytes == nil {
+		return []LandUseFactor{}, nil
+	}
+
+	var factorSet LandUseFactorSet
+	if err := json.Unmarshal(factorBytes, &factorSet); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal land use factors: %v", err)
+	}
+	return factorSet.Factors, nil
+}
+
+// landUseFactorBp returns the circle rate factor (basis points) for a
+// land use in a state: 10000 when the state sets none for it.
+func (s *StampDutyContract) landUseFactorBp(ctx contractapi.TransactionContextInterface, stateCode, landUse string) (int32, error) {
+	factors, err := s.GetLandUseFactors(ctx, stateCode)
+	if err != nil {
+		return 0, err
+	}
+	for _, factor := range factors {
+		if factor.LandUse == landUse {
+			return factor.CircleRateBasisPts, nil
+		}
+	}
+	return 10000, nil
+}
@@ -14,6 +14,30 @@ type CircleRate struct {
 	EffectiveFrom   string `json:"effectiveFrom"`
 	SetBy           string `json:"setBy"`
 	FabricTxID      string `json:"fabricTxId"`
+
+	// PreviousRate is the rate this record replaced (0 for the first rate
+	// set for the area). OverrideJustification is recorded when a change
+	// beyond the state's guardrail limit was explicitly accepted.
+	PreviousRate          int64  `json:"previousRate"`
+	OverrideJustification string `json:"overrideJustification,omitempty"`
+
+	// NotifiedRate is the rate as published, in paisa per Unit
+	// (SQ_METER, SQ_FOOT, ACRE, HECTARE, BIGHA). RatePerSqMeter holds
+	// the normalized value used in calculations.
+	NotifiedRate int64  `json:"notifiedRate"`
+	Unit         string `json:"unit"`
+}
+
+// CircleRateChangeLimit configures the maximum percentage change
+// permitted for an existing circle rate in a state before an override
+// justification is required.
+type CircleRateChangeLimit struct {
+	DocType      string `json:"docType"`
+	StateCode    string `json:"stateCode"`
+	MaxChangePct int32  `json:"maxChangePct"`
+	SetBy        string `json:"setBy"`
+	UpdatedAt    string `json:"updatedAt"`
+	FabricTxID   string `json:"fabricTxId"`
 }
 
 // StampDutyBreakdown is the result of a stamp duty calculation.
@@ -29,6 +53,54 @@ type StampDutyBreakdown struct {
 	Surcharge       int64  `json:"surcharge"`
 	TotalFees       int64  `json:"totalFees"`
 	State           string `json:"state"`
+
+	// AreaClass is the class the surcharge was resolved for. When the
+	// caller or ledger gave none, RURAL is assumed and AreaClassDefaulted
+	// is set so the registrar can verify the location.
+	AreaClass          string `json:"areaClass"`
+	SurchargeRate      int32  `json:"surchargeRate"`
+	AreaClassDefaulted bool   `json:"areaClassDefaulted"`
+
+	// Circle rate as notified and as normalized to paisa per square
+	// meter; set only when the rate was looked up on-chain.
+	NotifiedRate     int64  `json:"notifiedRate,omitempty"`
+	NotifiedRateUnit string `json:"notifiedRateUnit,omitempty"`
+	RatePerSqMeter   int64  `json:"ratePerSqMeter,omitempty"`
+
+	// LandUse and the factor (basis points) the circle rate value was
+	// scaled by; set only by CalculateForTransfer.
+	LandUse               string `json:"landUse,omitempty"`
+	LandUseFactorBasisPts int32  `json:"landUseFactorBasisPoints,omitempty"`
+
+	// InstrumentType is the instrument priced. StampDutyCapped and
+	// RegistrationFeeCapped are set when the state's cap for it applied,
+	// and RoundingUnit is the multiple of paisa the fees were rounded up
+	// to (see SetInstrumentRates).
+	InstrumentType        string `json:"instrumentType,omitempty"`
+	StampDutyCapped       bool   `json:"stampDutyCapped,omitempty"`
+	RegistrationFeeCapped bool   `json:"registrationFeeCapped,omitempty"`
+	RoundingUnit          int64  `json:"roundingUnit,omitempty"`
+
+	// AppliedConcessions itemizes concessions deducted from the stamp
+	// duty; ConcessionTotal is their sum (in paisa).
+	AppliedConcessions []AppliedConcession `json:"appliedConcessions,omitempty"`
+	ConcessionTotal    int64               `json:"concessionTotal,omitempty"`
+
+	// DisplayAmounts is derived from the paisa fields for receipts and
+	// the citizen portal; the paisa fields remain authoritative.
+	DisplayAmounts *DisplayAmounts `json:"displayAmounts,omitempty"`
+}
+
+// DisplayAmounts holds rupee strings formatted with Indian digit
+// grouping (e.g. "₹4,36,000") and the total in words.
+type DisplayAmounts struct {
+	CircleRateValue string `json:"circleRateValue"`
+	ApplicableValue string `json:"applicableValue"`
+	StampDutyAmount string `json:"stampDutyAmount"`
+	RegistrationFee string `json:"registrationFee"`
+	Surcharge       string `json:"surcharge"`
+	TotalFees       string `json:"totalFees"`
+	TotalInWords    string `json:"totalInWords"`
 }
 
 // StampDutyConfig holds the stamp duty and registration fee rates
@@ -43,6 +115,18 @@ type StampDutyConfig struct {
 	EffectiveFrom       string `json:"effectiveFrom"`
 	SetBy               string `json:"setBy"`
 	FabricTxID          string `json:"fabricTxId"`
+
+	// SurchargeByClass overrides SurchargeBasisPts per area class
+	// (URBAN, RURAL, or a municipality code such as "MCGM").
+	SurchargeByClass map[string]int32 `json:"surchargeByClass,omitempty"`
+
+	// Source is set on read: EXPLICIT for a config stored via
+	// SetStampDutyConfig, DEFAULT for the hardcoded fallback.
+	Source string `json:"source,omitempty"`
+
+	// Concessions lists the state's concession rules; populated only by
+	// ListStampDutyConfigs.
+	Concessions []ConcessionRule `json:"concessions,omitempty"`
 }
 
 // CircleRateChangedEvent is emitted when a circle rate is set or updated.
@@ -55,6 +139,14 @@ type CircleRateChangedEvent struct {
 	FabricTxID     string `json:"fabricTxId"`
 	Timestamp      string `json:"timestamp"`
 	ChannelID      string `json:"channelId"`
+
+	PreviousRate          int64   `json:"previousRate"`
+	PercentChange         float64 `json:"percentChange"`
+	Overridden            bool    `json:"overridden"`
+	OverrideJustification string  `json:"overrideJustification,omitempty"`
+
+	NotifiedRate int64  `json:"notifiedRate"`
+	Unit         string `json:"unit"`
 }
 
 // StampDutyConfigChangedEvent is emitted when state-level stamp duty
@@ -63,7 +155,453 @@ type StampDutyConfigChangedEvent struct {
 	Type              string `json:"type"`
 	StateCode         string `json:"stateCode"`
 	StampDutyBasisPts int32  `json:"stampDutyBasisPoints"`
+	EffectiveFrom     string `json:"effectiveFrom"`
 	FabricTxID        string `json:"fabricTxId"`
 	Timestamp         string `json:"timestamp"`
 	ChannelID         string `json:"channelId"`
 }
+
+// StampDutyPayment records a stamp duty payment made through the state
+// treasury (e-GRAS / e-challan). Payments are keyed by challan number
+// and can be consumed by exactly one transfer to prevent reuse.
+// All financial values are in paisa (int64).
+type StampDutyPayment struct {
+	DocType       string `json:"docType"`
+	ChallanNumber string `json:"challanNumber"`
+	StateCode     string `json:"stateCode"`
+	Amount        int64  `json:"amount"`
+	PayerHash     string `json:"payerHash"`
+	Purpose       string `json:"purpose"` // transferID the duty was paid for
+	PaidAt        string `json:"paidAt"`
+	Consumed      bool   `json:"consumed"`
+	ConsumedBy    string `json:"consumedBy"`
+	ConsumedAt    string `json:"consumedAt"`
+	Refunded      bool   `json:"refunded"`
+	RefundClaimID string `json:"refundClaimId,omitempty"`
+	RefundedAt    string `json:"refundedAt,omitempty"`
+	RecordedBy    string `json:"recordedBy"`
+	RecordedAt    string `json:"recordedAt"`
+	FabricTxID    string `json:"fabricTxId"`
+}
+
+// PaymentVerification is the result of checking a challan against an
+// expected stamp duty amount.
+type PaymentVerification struct {
+	ChallanNumber  string `json:"challanNumber"`
+	Valid          bool   `json:"valid"`
+	Reason         string `json:"reason"`
+	AmountPaid     int64  `json:"amountPaid"`
+	ExpectedAmount int64  `json:"expectedAmount"`
+	Consumed       bool   `json:"consumed"`
+	Purpose        string `json:"purpose"`
+}
+
+// PaymentEvent is emitted when a stamp duty payment is recorded or
+// consumed by a transfer.
+type PaymentEvent struct {
+	Type          string `json:"type"`
+	ChallanNumber string `json:"challanNumber"`
+	StateCode     string `json:"stateCode"`
+	Amount        int64  `json:"amount"`
+	TransferID    string `json:"transferId"`
+	FabricTxID    string `json:"fabricTxId"`
+	Timestamp     string `json:"timestamp"`
+	ChannelID     string `json:"channelId"`
+}
+
+// EStampCertificate records an e-stamp paper issued by the Stock Holding
+// Corporation of India (SHCIL) or an authorised collection centre,
+// identified by its Unique Identification Number (UIN). A UIN can be
+// consumed by exactly one transfer.
+// All financial values are in paisa (int64).
+type EStampCertificate struct {
+	DocType       string `json:"docType"`
+	UIN           string `json:"uin"`
+	StateCode     string `json:"stateCode"`
+	Denomination  int64  `json:"denomination"`
+	PurchaserHash string `json:"purchaserHash"`
+	Purpose       string `json:"purpose"`
+	IssuedBy      string `json:"issuedBy"`
+	IssuedAt      string `json:"issuedAt"`
+	Used          bool   `json:"used"`
+	UsedFor       string `json:"usedFor"`
+	UsedAt        string `json:"usedAt"`
+	RegisteredBy  string `json:"registeredBy"`
+	RegisteredAt  string `json:"registeredAt"`
+	FabricTxID    string `json:"fabricTxId"`
+}
+
+// EStampEvent is emitted when an e-stamp UIN is registered or consumed.
+type EStampEvent struct {
+	Type         string `json:"type"`
+	UIN          string `json:"uin"`
+	StateCode    string `json:"stateCode"`
+	Denomination int64  `json:"denomination"`
+	TransferID   string `json:"transferId"`
+	FabricTxID   string `json:"fabricTxId"`
+	Timestamp    string `json:"timestamp"`
+	ChannelID    string `json:"channelId"`
+}
+
+// RefundConfig holds a state's stamp duty refund rules: the deduction
+// retained by the state (in basis points) and the limitation window,
+// measured in days from the payment date to the filing of the claim.
+type RefundConfig struct {
+	DocType           string `json:"docType"`
+	StateCode         string `json:"stateCode"`
+	DeductionBasisPts int32  `json:"deductionBasisPoints"`
+	LimitationDays    int32  `json:"limitationDays"`
+	SetBy             string `json:"setBy"`
+	UpdatedAt         string `json:"updatedAt"`
+	FabricTxID        string `json:"fabricTxId"`
+}
+
+// RefundClaim is a claim for refund of stamp duty paid on a transaction
+// that was cancelled or failed. All financial values are in paisa (int64).
+type RefundClaim struct {
+	DocType              string `json:"docType"`
+	ClaimID              string `json:"claimId"`
+	StateCode            string `json:"stateCode"`
+	ChallanNumber        string `json:"challanNumber"`
+	ClaimantHash         string `json:"claimantHash"`
+	Reason               string `json:"reason"`
+	DeedCancellationHash string `json:"deedCancellationHash"`
+	PaidAmount           int64  `json:"paidAmount"`
+	PaidAt               string `json:"paidAt"`
+	DeductionBasisPts    int32  `json:"deductionBasisPoints"`
+	RefundableAmount     int64  `json:"refundableAmount"`
+	Status               string `json:"status"` // FILED, APPROVED, REJECTED
+	FiledAt              string `json:"filedAt"`
+	FiledBy              string `json:"filedBy"`
+	DecidedAt            string `json:"decidedAt"`
+	DecidedBy            string `json:"decidedBy"`
+	DecisionReason       string `json:"decisionReason"`
+	FabricTxID           string `json:"fabricTxId"`
+}
+
+// RefundClaimEvent is emitted when a refund claim is filed or decided.
+type RefundClaimEvent struct {
+	Type             string `json:"type"`
+	ClaimID          string `json:"claimId"`
+	StateCode        string `json:"stateCode"`
+	ChallanNumber    string `json:"challanNumber"`
+	Status           string `json:"status"`
+	RefundableAmount int64  `json:"refundableAmount"`
+	FabricTxID       string `json:"fabricTxId"`
+	Timestamp        string `json:"timestamp"`
+	ChannelID        string `json:"channelId"`
+}
+
+// CalculationRecord is an on-chain record of a stamp duty quote shown to
+// the parties, capturing the inputs, the rates applied and the resulting
+// breakdown. Quotes expire after a validity window and can be referenced
+// by a transfer through their QuoteID.
+// All financial values are in paisa (int64).
+type CalculationRecord struct {
+	DocType              string             `json:"docType"`
+	QuoteID              string             `json:"quoteId"`
+	StateCode            string             `json:"stateCode"`
+	DistrictCode         string             `json:"districtCode"`
+	TehsilCode           string             `json:"tehsilCode"`
+	AreaSqMeters         float64            `json:"areaSqMeters"`
+	DeclaredValue        int64              `json:"declaredValue"`
+	CircleRatePerSqMeter int64              `json:"circleRatePerSqMeter"`
+	StampDutyBasisPts    int32              `json:"stampDutyBasisPoints"`
+	RegistrationBasisPts int32              `json:"registrationBasisPoints"`
+	SurchargeBasisPts    int32              `json:"surchargeBasisPoints"`
+	Breakdown            StampDutyBreakdown `json:"breakdown"`
+	QuotedAt             string             `json:"quotedAt"`
+	ExpiresAt            string             `json:"expiresAt"`
+	Expired              bool               `json:"expired"`
+	QuotedBy             string             `json:"quotedBy"`
+	FabricTxID           string             `json:"fabricTxId"`
+}
+
+// StampDutyQuotedEvent is emitted when a stamp duty quote is recorded.
+type StampDutyQuotedEvent struct {
+	Type       string `json:"type"`
+	QuoteID    string `json:"quoteId"`
+	StateCode  string `json:"stateCode"`
+	TotalFees  int64  `json:"totalFees"`
+	ExpiresAt  string `json:"expiresAt"`
+	FabricTxID string `json:"fabricTxId"`
+	Timestamp  string `json:"timestamp"`
+	ChannelID  string `json:"channelId"`
+}
+
+// AreaClassification records whether a tehsil is urban, rural, or
+// inside a specific notified municipality, for surcharge resolution.
+type AreaClassification struct {
+	DocType      string `json:"docType"`
+	StateCode    string `json:"stateCode"`
+	DistrictCode string `json:"districtCode"`
+	TehsilCode   string `json:"tehsilCode"`
+	AreaClass    string `json:"areaClass"`
+	UpdatedBy    string `json:"updatedBy"`
+	UpdatedAt    string `json:"updatedAt"`
+	FabricTxID   string `json:"fabricTxId"`
+}
+
+// AreaClassificationChangedEvent is emitted when a tehsil's area class
+// is set or updated.
+type AreaClassificationChangedEvent struct {
+	Type         string `json:"type"`
+	StateCode    string `json:"stateCode"`
+	DistrictCode string `json:"districtCode"`
+	TehsilCode   string `json:"tehsilCode"`
+	AreaClass    string `json:"areaClass"`
+	FabricTxID   string `json:"fabricTxId"`
+	Timestamp    string `json:"timestamp"`
+	ChannelID    string `json:"channelId"`
+}
+
+// BighaFactor records the size of one bigha in a state, in square feet.
+// The bigha varies widely across states, so per-bigha circle rates can
+// only be normalized once a factor is configured.
+type BighaFactor struct {
+	DocType        string `json:"docType"`
+	StateCode      string `json:"stateCode"`
+	SqFeetPerBigha int64  `json:"sqFeetPerBigha"`
+	SetBy          string `json:"setBy"`
+	UpdatedAt      string `json:"updatedAt"`
+	FabricTxID     string `json:"fabricTxId"`
+}
+
+// TransferFeeRequest is the input to CalculateForTransfer: everything
+// about a transfer that bears on the fees, in one structure.
+type TransferFeeRequest struct {
+	StateCode      string          `json:"stateCode"`
+	DistrictCode   string          `json:"districtCode"`
+	TehsilCode     string          `json:"tehsilCode"`
+	VillageCode    string          `json:"villageCode,omitempty"`
+	AreaSqMeters   float64         `json:"areaSqMeters"`
+	LandUse        string          `json:"landUse,omitempty"`
+	DeclaredValue  int64           `json:"declaredValue"` // In paisa
+	InstrumentType string          `json:"instrumentType"`
+	Buyers         []TransferBuyer `json:"buyers"`
+	ValuationDate  string          `json:"valuationDate"` // RFC3339 or YYYY-MM-DD
+
+	ClaimedConcessions []string `json:"claimedConcessions,omitempty"`
+}
+
+// TransferBuyer describes one buyer for concession eligibility.
+type TransferBuyer struct {
+	Gender         string `json:"gender,omitempty"` // MALE, FEMALE, OTHER
+	FirstTimeBuyer bool   `json:"firstTimeBuyer,omitempty"`
+}
+
+// TransferFeeCalculation is the result of CalculateForTransfer. The
+// RequestHash lets the land-registry chaincode bind a payment or quote
+// to the exact request that was priced.
+type TransferFeeCalculation struct {
+	RequestHash string              `json:"requestHash"`
+	Request     TransferFeeRequest  `json:"request"`
+	Breakdown   *StampDutyBreakdown `json:"breakdown"`
+}
+
+// PenaltyConfig holds a state's interest rules for deficit stamp duty.
+// MaxInterestBasisPts caps total interest as a share of the deficit
+// (0 = uncapped).
+type PenaltyConfig struct {
+	DocType                 string `json:"docType"`
+	StateCode               string `json:"stateCode"`
+	MonthlyInterestBasisPts int32  `json:"monthlyInterestBasisPoints"`
+	MaxInterestBasisPts     int32  `json:"maxInterestBasisPoints"`
+	SetBy                   string `json:"setBy"`
+	UpdatedAt               string `json:"updatedAt"`
+	FabricTxID              string `json:"fabricTxId"`
+}
+
+// ConversionChargeRule sets the charge for converting land from FromUse
+// to ToUse as ChargeBasisPts of the parcel's circle-rate value.
+type ConversionChargeRule struct {
+	FromUse        string `json:"fromUse"`
+	ToUse          string `json:"toUse"`
+	ChargeBasisPts int32  `json:"chargeBasisPoints"`
+}
+
+// LandUseFactor scales the tehsil circle rate for parcels of LandUse to
+// CircleRateBasisPts of the notified rate (10000 = the notified rate).
+type LandUseFactor struct {
+	LandUse            string `json:"landUse"`
+	CircleRateBasisPts int32  `json:"circleRateBasisPoints"`
+}
+
+// LandUseFactorSet is a state's land-use circle rate factors.
+type LandUseFactorSet struct {
+	DocType    string          `json:"docType"`
+	StateCode  string          `json:"stateCode"`
+	Factors    []LandUseFactor `json:"factors"`
+	SetBy      string          `json:"setBy"`
+	UpdatedAt  string          `json:"updatedAt"`
+	FabricTxID string          `json:"fabricTxId"`
+}
+
+// InstrumentRate prices InstrumentType in a state. Unset basis points
+// take the rates of the stamp duty config in force. Caps are in paisa;
+// 0 leaves the amount uncapped.
+type InstrumentRate struct {
+	InstrumentType       string `json:"instrumentType"`
+	StampDutyBasisPts    *int32 `json:"stampDutyBasisPoints,omitempty"`
+	RegistrationBasisPts *int32 `json:"registrationBasisPoints,omitempty"`
+	StampDutyCap         int64  `json:"stampDutyCap,omitempty"`
+	RegistrationFeeCap   int64  `json:"registrationFeeCap,omitempty"`
+}
+
+// InstrumentRateSet is a state's instrument rates and the multiple of
+// paisa its fees are rounded up to (0 = no rounding).
+type InstrumentRateSet struct {
+	DocType      string           `json:"docType"`
+	StateCode    string           `json:"stateCode"`
+	Rates        []InstrumentRate `json:"rates"`
+	RoundingUnit int64            `json:"roundingUnit"`
+	SetBy        string           `json:"setBy"`
+	UpdatedAt    string           `json:"updatedAt"`
+	FabricTxID   string           `json:"fabricTxId"`
+}
+
+// ConversionChargeRuleSet is a state's conversion charge rules.
+type ConversionChargeRuleSet struct {
+	DocType    string                 `json:"docType"`
+	StateCode  string                 `json:"stateCode"`
+	Rules      []ConversionChargeRule `json:"rules"`
+	SetBy      string                 `json:"setBy"`
+	UpdatedAt  string                 `json:"updatedAt"`
+	FabricTxID string                 `json:"fabricTxId"`
+}
+
+// ConversionCharge is the result of CalculateConversionCharge. Amounts
+// are in paisa.
+type ConversionCharge struct {
+	StateCode       string  `json:"stateCode"`
+	DistrictCode    string  `json:"districtCode"`
+	TehsilCode      string  `json:"tehsilCode"`
+	FromUse         string  `json:"fromUse"`
+	ToUse           string  `json:"toUse"`
+	AreaSqMeters    float64 `json:"areaSqMeters"`
+	RatePerSqMeter  int64   `json:"ratePerSqMeter"`
+	CircleRateValue int64   `json:"circleRateValue"`
+	ChargeBasisPts  int32   `json:"chargeBasisPoints"`
+	ChargeAmount    int64   `json:"chargeAmount"`
+}
+
+// AmnestyScheme waives WaiverPercentage of the interest on deficit
+// stamp duty for demands on InstrumentTypes settled between StartDate
+// and EndDate (YYYY-MM-DD, inclusive).
+type AmnestyScheme struct {
+	DocType          string   `json:"docType"`
+	SchemeID         string   `json:"schemeId"`
+	StateCode        string   `json:"stateCode"`
+	Name             string   `json:"name,omitempty"`
+	StartDate        string   `json:"startDate"`
+	EndDate          string   `json:"endDate"`
+	WaiverPercentage int32    `json:"waiverPercentage"`
+	InstrumentTypes  []string `json:"instrumentTypes"`
+	SetBy            string   `json:"setBy"`
+	UpdatedAt        string   `json:"updatedAt"`
+	FabricTxID       string   `json:"fabricTxId"`
+}
+
+// DeficitAssessmentInput is the input to AssessDeficit.
+type DeficitAssessmentInput struct {
+	StateCode        string `json:"stateCode"`
+	ChallanNumber    string `json:"challanNumber"`
+	QuoteID          string `json:"quoteId,omitempty"`
+	RegistrationDate string `json:"registrationDate,omitempty"` // RFC3339 or YYYY-MM-DD
+	ReassessedValue  int64  `json:"reassessedValue"`            // In paisa
+	PaidStampDuty    int64  `json:"paidStampDuty,omitempty"`    // In paisa; required without quoteId
+	InstrumentType   string `json:"instrumentType,omitempty"`   // Defaults to SALE_DEED
+	Remarks          string `json:"remarks,omitempty"`
+}
+
+// DemandRecord is a recovery demand for deficit stamp duty and interest
+// raised by an audit reassessment. Status: OPEN, PAID. PenaltyWaived is
+// the interest waived under AmnestySchemeID, as of assessment while the
+// demand is OPEN and as of payment once PAID; TotalDemand is net of it.
+// PaidDuty is the stamp duty part of the original challan, excluding the
+// registration fee and surcharge it also covered.
+type DemandRecord struct {
+	DocType                 string `json:"docType"`
+	DemandID                string `json:"demandId"`
+	StateCode               string `json:"stateCode"`
+	ChallanNumber           string `json:"challanNumber"`
+	QuoteID                 string `json:"quoteId,omitempty"`
+	PayerHash               string `json:"payerHash"`
+	RegistrationDate        string `json:"registrationDate"`
+	OriginalApplicableValue int64  `json:"originalApplicableValue,omitempty"`
+	ReassessedValue         int64  `json:"reassessedValue"`
+	StampDutyBasisPts       int32  `json:"stampDutyBasisPoints"`
+	RequiredDuty            int64  `json:"requiredDuty"`
+	PaidDuty                int64  `json:"paidDuty"`
+	DeficitAmount           int64  `json:"deficitAmount"`
+	InterestMonths          int32  `json:"interestMonths"`
+	MonthlyInterestBasisPts int32  `json:"monthlyInterestBasisPoints"`
+	InterestAmount          int64  `json:"interestAmount"`
+	InstrumentType          string `json:"instrumentType"`
+	AmnestySchemeID         string `json:"amnestySchemeId,omitempty"`
+	PenaltyWaived           int64  `json:"penaltyWaived,omitempty"`
+	TotalDemand             int64  `json:"totalDemand"`
+	Remarks                 string `json:"remarks,omitempty"`
+	Status                  string `json:"status"`
+	PaidChallan             string `json:"paidChallan,omitempty"`
+	PaidAt                  string `json:"paidAt,omitempty"`
+	AssessedBy              string `json:"assessedBy"`
+	AssessedAt              string `json:"assessedAt"`
+	FabricTxID              string `json:"fabricTxId"`
+}
+
+// DemandEvent is emitted when a deficit is assessed (DEFICIT_ASSESSED)
+// or paid (DEFICIT_PAID).
+type DemandEvent struct {
+	Type            string `json:"type"`
+	DemandID        string `json:"demandId"`
+	StateCode       string `json:"stateCode"`
+	ChallanNumber   string `json:"challanNumber"`
+	PayerHash       string `json:"payerHash"`
+	DeficitAmount   int64  `json:"deficitAmount"`
+	InterestAmount  int64  `json:"interestAmount"`
+	AmnestySchemeID string `json:"amnestySchemeId,omitempty"`
+	PenaltyWaived   int64  `json:"penaltyWaived,omitempty"`
+	TotalDemand     int64  `json:"totalDemand"`
+	Status          string `json:"status"`
+	FabricTxID      string `json:"fabricTxId"`
+	Timestamp       string `json:"timestamp"`
+	ChannelID       string `json:"channelId"`
+}
+
+// ConcessionRule is one stamp duty concession offered by a state.
+// Code is the criteria key claimed by callers (e.g. FEMALE_BUYER,
+// FIRST_TIME_BUYER, DEFENCE). The reduction is ReductionBasisPts of the
+// applicable value plus FlatAmount (paisa). MinValue/MaxValue bound the
+// applicable value the rule covers (MaxValue 0 = no upper bound).
+// BuyerGender and FirstTimeBuyerOnly restrict the rule to transfers in
+// which every buyer is of that class (see CalculateForTransfer).
+type ConcessionRule struct {
+	Code               string `json:"code"`
+	Description        string `json:"description,omitempty"`
+	ReductionBasisPts  int32  `json:"reductionBasisPoints"`
+	FlatAmount         int64  `json:"flatAmount"`
+	Stackable          bool   `json:"stackable"`
+	MinValue           int64  `json:"minValue"`
+	MaxValue           int64  `json:"maxValue"`
+	BuyerGender        string `json:"buyerGender,omitempty"`
+	FirstTimeBuyerOnly bool   `json:"firstTimeBuyerOnly,omitempty"`
+}
+
+// ConcessionRuleSet is the ordered list of concession rules for a state.
+type ConcessionRuleSet struct {
+	DocType    string           `json:"docType"`
+	StateCode  string           `json:"stateCode"`
+	Rules      []ConcessionRule `json:"rules"`
+	SetBy      string           `json:"setBy"`
+	UpdatedAt  string           `json:"updatedAt"`
+	FabricTxID string           `json:"fabricTxId"`
+}
+
+// AppliedConcession is a concession deducted in a breakdown.
+type AppliedConcession struct {
+	Code              string `json:"code"`
+	ReductionBasisPts int32  `json:"reductionBasisPoints"`
+	FlatAmount        int64  `json:"flatAmount"`
+	Amount            int64  `json:"amount"` // In paisa
+}
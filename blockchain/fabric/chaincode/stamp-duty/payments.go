@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// STAMP DUTY PAYMENTS (e-GRAS / e-challan)
+// ============================================================
+
+// RecordPayment records a stamp duty payment received by the state
+// treasury so that registrars can verify the challan on-chain before
+// executing a transfer. Each challan number can be recorded only once
+// per state; payments are keyed PAYMENT~{stateCode}~{challanNumber}.
+//
+// Only users with the "treasury" or "admin" role can record payments.
+// The amount is in paisa (int64).
+func (s *StampDutyContract) RecordPayment(ctx contractapi.TransactionContextInterface, paymentJSON string) error {
+	if _, err := requireAnyRole(ctx, "treasury", "admin"); err != nil {
+		return err
+	}
+
+	var payment StampDutyPayment
+	if err := json.Unmarshal([]byte(paymentJSON), &payment); err != nil {
+		return fmt.Errorf("INVALID_INPUT: failed to parse payment JSON: %v", err)
+	}
+
+	if payment.ChallanNumber == "" || payment.StateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: challanNumber and stateCode are required")
+	}
+	if err := requireStateAccess(ctx, payment.StateCode); err != nil {
+		return err
+	}
+	if payment.Amount <= 0 {
+		return fmt.Errorf("VALIDATION_ERROR: amount must be positive, got %d", payment.Amount)
+	}
+	if err := validateAadhaarHash(payment.PayerHash, "payerHash"); err != nil {
+		return err
+	}
+	if payment.PaidAt == "" {
+		return fmt.Errorf("VALIDATION_ERROR: paidAt is required")
+	}
+	if _, err := time.Parse(time.RFC3339, payment.PaidAt); err != nil {
+		return fmt.Errorf("VALIDATION_ERROR: paidAt must be RFC3339, got '%s'", payment.PaidAt)
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("PAYMENT", []string{payment.StateCode, payment.ChallanNumber})
+	if err != nil {
+		return fmt.Errorf("failed to create payment key: %v", err)
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read payment: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("PAYMENT_EXISTS: challan %s already recorded in %s", payment.ChallanNumber, payment.StateCode)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	payment.DocType = "stampDutyPayment"
+	payment.Consumed = false
+	payment.ConsumedBy = ""
+	payment.ConsumedAt = ""
+	payment.Refunded = false
+	payment.RefundClaimID = ""
+	payment.RefundedAt = ""
+	payment.RecordedBy = getCallerID(ctx)
+	payment.RecordedAt = now
+	payment.FabricTxID = txID
+
+	paymentBytes, err := json.Marshal(payment)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, paymentBytes); err != nil {
+		return fmt.Errorf("failed to put payment state: %v", err)
+	}
+
+	event := PaymentEvent{
+		Type:          "STAMP_DUTY_PAID",
+		ChallanNumber: payment.ChallanNumber,
+		StateCode:     payment.StateCode,
+		Amount:        payment.Amount,
+		TransferID:    payment.Purpose,
+		FabricTxID:    txID,
+		Timestamp:     now,
+		ChannelID:     ctx.GetStub().GetChannelID(),
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	return ctx.GetStub().SetEvent("STAMP_DUTY_PAID", eventJSON)
+}
+
+// GetPayment retrieves a recorded stamp duty payment by state and
+// challan number.
+func (s *StampDutyContract) GetPayment(ctx contractapi.TransactionContextInterface, stateCode, challanNumber string) (*StampDutyPayment, error) {
+	if stateCode == "" || challanNumber == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode and challanNumber are required")
+	}
+
+	payment, err := readPayment(ctx, stateCode, challanNumber)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("PAYMENT_NOT_FOUND: no payment recorded for challan %s in %s", challanNumber, stateCode)
+	}
+	return payment, nil
+}
+
+// readPayment returns the payment recorded for a state's challan, or nil
+// if there is none. A failed ledger read is an error, never a nil
+// payment.
+func readPayment(ctx contractapi.TransactionContextInterface, stateCode, challanNumber string) (*StampDutyPayment, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("PAYMENT", []string{stateCode, challanNumber})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment key: %v", err)
+	}
+	paymentBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payment: %v", err)
+	}
+	if paymentBytes == nil {
+		return nil, nil
+	}
+
+	var payment StampDutyPayment
+	if err := json.Unmarshal(paymentBytes, &payment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payment: %v", err)
+	}
+	return &payment, nil
+}
+
+// VerifyPayment checks whether an unconsumed payment for at least the
+// expected amount (in paisa) exists for the given state's challan. It
+// never returns an error for a missing or unusable challan; the Valid
+// flag and Reason explain the outcome so callers (including the
+// land-registry chaincode via cross-chaincode invoke) can decide how to
+// proceed. A failed ledger read is returned as an error, not as
+// PAYMENT_NOT_FOUND.
+func (s *StampDutyContract) VerifyPayment(ctx contractapi.TransactionContextInterface, stateCode, challanNumber string, expectedAmount int64) (*PaymentVerification, error) {
+	if stateCode == "" || challanNumber == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode and challanNumber are required")
+	}
+	if expectedAmount < 0 {
+		return nil, fmt.Errorf("VALIDATION_ERROR: expectedAmount cannot be negative")
+	}
+
+	result := &PaymentVerification{
+		ChallanNumber:  challanNumber,
+		ExpectedAmount: expectedAmount,
+	}
+
+	payment, err := readPayment(ctx, stateCode, challanNumber)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		result.Reason = "PAYMENT_NOT_FOUND"
+		return result, nil
+	}
+
+	result.AmountPaid = payment.Amount
+	result.Consumed = payment.Consumed
+	result.Purpose = payment.Purpose
+
+	switch {
+	case payment.Consumed:
+		result.Reason = "PAYMENT_ALREADY_CONSUMED"
+	case payment.Refunded:
+		result.Reason = "PAYMENT_REFUNDED"
+	case payment.Amount < expectedAmount:
+		result.Reason = "PAYMENT_AMOUNT_INSUFFICIENT"
+	default:
+		result.Valid = true
+		result.Reason = "OK"
+	}
+	return result, nil
+}
+
+// MarkPaymentConsumed ties a challan to a transfer so that the same
+// payment cannot be presented for a second transfer. If the payment was
+// recorded with a purpose, it must match the consuming transferID. A
+// refunded payment cannot be consumed. Callable by registrars (typically
+// via the land-registry chaincode during ExecuteTransfer), treasury, or
+// admin.
+func (s *StampDutyContract) MarkPaymentConsumed(ctx contractapi.TransactionContextInterface, stateCode, challanNumber, transferID string) error {
+	if _, err := requireAnyRole(ctx, "registrar", "treasury", "admin"); err != nil {
+		return err
+	}
+
+	if transferID == "" {
+		return fmt.Errorf("VALIDATION_ERROR: transferID is required")
+	}
+
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+	payment, err := s.GetPayment(ctx, stateCode, challanNumber)
+	if err != nil {
+		return err
+	}
+	if payment.Consumed {
+		return fmt.Errorf("PAYMENT_ALREADY_CONSUMED: challan %s was consumed by %s at %s", challanNumber, payment.ConsumedBy, payment.ConsumedAt)
+	}
+	if payment.Refunded {
+		return fmt.Errorf("PAYMENT_REFUNDED: challan %s was refunded under claim %s", challanNumber, payment.RefundClaimID)
+	}
+	if payment.Purpose != "" && payment.Purpose != transferID {
+		return fmt.Errorf("PAYMENT_PURPOSE_MISMATCH: challan %s was paid for %s, not %s", challanNumber, payment.Purpose, transferID)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	payment.Consumed = true
+	payment.ConsumedBy = transferID
+	payment.ConsumedAt = now
+	payment.FabricTxID = txID
+	if err := putPayment(ctx, payment); err != nil {
+		return err
+	}
+
+	event := PaymentEvent{
+		Type:          "STAMP_DUTY_PAYMENT_CONSUMED",
+		ChallanNumber: challanNumber,
+		StateCode:     payment.StateCode,
+		Amount:        payment.Amount,
+		TransferID:    transferID,
+		FabricTxID:    txID,
+		Timestamp:     now,
+		ChannelID:     ctx.GetStub().GetChannelID(),
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	return ctx.GetStub().SetEvent("STAMP_DUTY_PAYMENT_CONSUMED", eventJSON)
+}
+
+// putPayment writes back an updated payment under
+// PAYMENT~{stateCode}~{challanNumber}.
+func putPayment(ctx contractapi.TransactionContextInterface, payment *StampDutyPayment) error {
+	key, err := ctx.GetStub().CreateCompositeKey("PAYMENT", []string{payment.StateCode, payment.ChallanNumber})
+	if err != nil {
+		return fmt.Errorf("failed to create payment key: %v", err)
+	}
+	paymentBytes, err := json.Marshal(payment)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, paymentBytes); err != nil {
+		return fmt.Errorf("failed to update payment: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// recordPayment records challan in stateCode for amount paisa as that
+// state's treasury.
+func (l *testLedger) recordPayment(stateCode, challan string, amount int64) error {
+	paymentJSON, _ := json.Marshal(StampDutyPayment{
+		ChallanNumber: challan, StateCode: stateCode, Amount: amount,
+		PayerHash: fmt.Sprintf("%064x", 1), PaidAt: "2027-03-14T11:00:00Z",
+	})
+	return l.submit(newTestIdentity("TreasuryOrgMSP", "treasury", stateCode), func(ctx contractapi.TransactionContextInterface) error {
+		return l.contract.RecordPayment(ctx, string(paymentJSON))
+	})
+}
+
+func TestPaymentsAreKeyedByStateAndChallan(t *testing.T) {
+	ledger := newTestLedger(t)
+
+	// Treasuries number challans independently, so the same number can
+	// be recorded once in each state
+	for stateCode, amount := range map[string]int64{"KA": 5000000, "MH": 7000000} {
+		if err := ledger.recordPayment(stateCode, "CH-2027-0001", amount); err != nil {
+			t.Fatalf("RecordPayment %s: %v", stateCode, err)
+		}
+	}
+	expectCode(t, ledger.recordPayment("KA", "CH-2027-0001", 5000000), "PAYMENT_EXISTS")
+
+	registrar := newTestIdentity("RegistrarOrgMSP", "registrar", "KA")
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		if err := ledger.contract.MarkPaymentConsumed(ctx, "KA", "CH-2027-0001", "txn_ka"); err != nil {
+			return err
+		}
+		for stateCode, want := range map[string]bool{"KA": true, "MH": false} {
+			payment, err := ledger.contract.GetPayment(ctx, stateCode, "CH-2027-0001")
+			if err != nil {
+				return err
+			}
+			if payment.Consumed != want {
+				t.Errorf("%s payment consumed = %v, want %v", stateCode, payment.Consumed, want)
+			}
+		}
+		return nil
+	})
+
+	// A KA registrar cannot consume the MH challan, and an unknown KA
+	// challan is reported rather than failing
+	ledger.mustSubmit(registrar, func(ctx contractapi.TransactionContextInterface) error {
+		expectCode(t, ledger.contract.MarkPaymentConsumed(ctx, "MH", "CH-2027-0001", "txn_ka"), "STATE_MISMATCH")
+		verification, err := ledger.contract.VerifyPayment(ctx, "KA", "CH-2027-0002", 0)
+		if err != nil {
+			return err
+		}
+		if verification.Valid || verification.Reason != "PAYMENT_NOT_FOUND" {
+			t.Errorf("verification = %+v, want PAYMENT_NOT_FOUND", verification)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// RECORDED STAMP DUTY QUOTES
+// ============================================================
+
+// quoteValidityDays is how long a recorded quote remains valid.
+const quoteValidityDays = 30
+
+// CalculateAndRecord performs the same calculation as
+// CalculateStampDutyWithCircleRate but, as a submit transaction, stores
+// the inputs, applied rates and result as a CalculationRecord keyed by a
+// generated quote ID and emits STAMP_DUTY_QUOTED. This gives an on-chain
+// trace of the quote a registrar showed the parties.
+// Callable by registrar or admin. Returns the recorded quote.
+func (s *StampDutyContract) CalculateAndRecord(ctx contractapi.TransactionContextInterface, stateCode, districtCode, tehsilCode string, areaSqMeters float64, declaredValue int64) (*CalculationRecord, error) {
+	if _, err := requireAnyRole(ctx, "registrar", "admin"); err != nil {
+		return nil, err
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return nil, err
+	}
+
+	breakdown, config, ratePerSqMeter, err := s.calculateWithCircleRate(ctx, stateCode, districtCode, tehsilCode, "", "", areaSqMeters, declaredValue, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	nowTime := time.Unix(timestamp.Seconds, 0)
+	now := nowTime.Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	record := CalculationRecord{
+		DocType:              "calculationRecord",
+		QuoteID:              "qt_" + txID[:8],
+		StateCode:            stateCode,
+		DistrictCode:         districtCode,
+		TehsilCode:           tehsilCode,
+		AreaSqMeters:         areaSqMeters,
+		DeclaredValue:        declaredValue,
+		CircleRatePerSqMeter: ratePerSqMeter,
+		StampDutyBasisPts:    config.StampDutyBasisPts,
+		RegistrationBasisPts: config.RegistrationBasisPts,
+		SurchargeBasisPts:    config.SurchargeBasisPts,
+		Breakdown:            *breakdown,
+		QuotedAt:             now,
+		ExpiresAt:            nowTime.AddDate(0, 0, quoteValidityDays).Format(time.RFC3339),
+		QuotedBy:             getCallerID(ctx),
+		FabricTxID:           txID,
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("QUOTE", []string{record.QuoteID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quote key: %v", err)
+	}
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quote: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, recordBytes); err != nil {
+		return nil, fmt.Errorf("failed to put quote state: %v", err)
+	}
+
+	event := StampDutyQuotedEvent{
+		Type:       "STAMP_DUTY_QUOTED",
+		QuoteID:    record.QuoteID,
+		StateCode:  stateCode,
+		TotalFees:  breakdown.TotalFees,
+		ExpiresAt:  record.ExpiresAt,
+		FabricTxID: txID,
+		Timestamp:  now,
+		ChannelID:  ctx.GetStub().GetChannelID(),
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %v", err)
+	}
+	if err := ctx.GetStub().SetEvent("STAMP_DUTY_QUOTED", eventJSON); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// GetQuote retrieves a recorded stamp duty quote. The Expired flag is
+// computed against the transaction timestamp, so a quote past its
+// validity window is still returned (for dispute resolution) but marked
+// as expired.
+func (s *StampDutyContract) GetQuote(ctx contractapi.TransactionContextInterface, quoteID string) (*CalculationRecord, error) {
+	if quoteID == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: quoteID is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("QUOTE", []string{quoteID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quote key: %v", err)
+	}
+	recordBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quote: %v", err)
+	}
+	if recordBytes == nil {
+		return nil, fmt.Errorf("QUOTE_NOT_FOUND: %s", quoteID)
+	}
+
+	var record CalculationRecord
+	if err := json.Unmarshal(recordBytes, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal quote: %v", err)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	nowTime := time.Unix(timestamp.Seconds, 0)
+	if expiresAt, err := time.Parse(time.RFC3339, record.ExpiresAt); err == nil {
+		record.Expired = !nowTime.Before(expiresAt)
+	}
+	return &record, nil
+}
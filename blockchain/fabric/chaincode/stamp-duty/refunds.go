@@ -0,0 +1,408 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// STAMP DUTY REFUND CLAIMS
+// ============================================================
+
+// defaultRefundDeductionBp is the share of the duty retained by the state
+// on refund when no state-specific RefundConfig exists.
+const defaultRefundDeductionBp int32 = 1000 // 10%
+
+// defaultRefundLimitationDays is the default window, from the payment
+// date, within which a refund claim must be filed.
+const defaultRefundLimitationDays int32 = 180
+
+// SetRefundConfig sets the refund deduction (basis points) and the
+// limitation window (days) for a state. Only admins can set it.
+func (s *StampDutyContract) SetRefundConfig(ctx contractapi.TransactionContextInterface, stateCode string, deductionBp, limitationDays int32) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+	if deductionBp < 0 || deductionBp > 10000 {
+		return fmt.Errorf("VALIDATION_ERROR: deductionBasisPoints must be between 0 and 10000")
+	}
+	if limitationDays <= 0 {
+		return fmt.Errorf("VALIDATION_ERROR: limitationDays must be positive, got %d", limitationDays)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	config := RefundConfig{
+		DocType:           "refundConfig",
+		StateCode:         stateCode,
+		DeductionBasisPts: deductionBp,
+		LimitationDays:    limitationDays,
+		SetBy:             getCallerID(ctx),
+		UpdatedAt:         now,
+		FabricTxID:        ctx.GetStub().GetTxID(),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("REFUND_CONFIG", []string{stateCode})
+	if err != nil {
+		return fmt.Errorf("failed to create refund config key: %v", err)
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal refund config: %v", err)
+	}
+	return ctx.GetStub().PutState(key, configBytes)
+}
+
+// GetRefundConfig returns the refund rules for a state, falling back to
+// the default deduction and limitation window if none has been set.
+func (s *StampDutyContract) GetRefundConfig(ctx contractapi.TransactionContextInterface, stateCode string) (*RefundConfig, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("REFUND_CONFIG", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refund config key: %v", err)
+	}
+	configBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refund config: %v", err)
+	}
+	if configBytes == nil {
+		return &RefundConfig{
+			DocType:           "refundConfig",
+			StateCode:         stateCode,
+			DeductionBasisPts: defaultRefundDeductionBp,
+			LimitationDays:    defaultRefundLimitationDays,
+			SetBy:             "system",
+		}, nil
+	}
+
+	var config RefundConfig
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal refund config: %v", err)
+	}
+	return &config, nil
+}
+
+// FileRefundClaim files a claim for refund of the stamp duty paid under
+// a recorded challan. Only one open or approved claim may exist per
+// challan, and a challan already consumed by a transfer or demand, or
+// already refunded, cannot be claimed. Callable by registrar, treasury,
+// or admin on behalf of the claimant. Emits REFUND_CLAIM_FILED. Returns
+// the claim ID.
+func (s *StampDutyContract) FileRefundClaim(ctx contractapi.TransactionContextInterface, claimJSON string) (string, error) {
+	if _, err := requireAnyRole(ctx, "registrar", "treasury", "admin"); err != nil {
+		return "", err
+	}
+
+	var claim RefundClaim
+	if err := json.Unmarshal([]byte(claimJSON), &claim); err != nil {
+		return "", fmt.Errorf("INVALID_INPUT: failed to parse refund claim JSON: %v", err)
+	}
+
+	if claim.ChallanNumber == "" || claim.StateCode == "" {
+		return "", fmt.Errorf("VALIDATION_ERROR: challanNumber and stateCode are required")
+	}
+	if err := validateAadhaarHash(claim.ClaimantHash, "claimantHash"); err != nil {
+		return "", err
+	}
+	if claim.Reason == "" {
+		return "", fmt.Errorf("VALIDATION_ERROR: reason is required")
+	}
+	if claim.DeedCancellationHash == "" {
+		return "", fmt.Errorf("VALIDATION_ERROR: deedCancellationHash is required")
+	}
+
+	if err := requireStateAccess(ctx, claim.StateCode); err != nil {
+		return "", err
+	}
+	payment, err := s.GetPayment(ctx, claim.StateCode, claim.ChallanNumber)
+	if err != nil {
+		return "", err
+	}
+	if err := requireRefundable(payment); err != nil {
+		return "", err
+	}
+
+	// One live claim per challan
+	markerKey, err := ctx.GetStub().CreateCompositeKey("REFUND_CHALLAN", []string{claim.StateCode, claim.ChallanNumber})
+	if err != nil {
+		return "", fmt.Errorf("failed to create refund marker key: %v", err)
+	}
+	existingClaimID, err := ctx.GetStub().GetState(markerKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read refund marker: %v", err)
+	}
+	if existingClaimID != nil {
+		return "", fmt.Errorf("REFUND_CLAIM_EXISTS: challan %s already has refund claim %s", claim.ChallanNumber, string(existingClaimID))
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+	txID := ctx.GetStub().GetTxID()
+
+	claim.DocType = "refundClaim"
+	claim.ClaimID = "rfd_" + txID[:8]
+	claim.PaidAmount = payment.Amount
+	claim.PaidAt = payment.PaidAt
+	claim.DeductionBasisPts = 0
+	claim.RefundableAmount = 0
+	claim.Status = "FILED"
+	claim.FiledAt = now
+	claim.FiledBy = getCallerID(ctx)
+	claim.DecidedAt = ""
+	claim.DecidedBy = ""
+	claim.DecisionReason = ""
+	claim.FabricTxID = txID
+
+	if err := s.putRefundClaim(ctx, &claim); err != nil {
+		return "", err
+	}
+	if err := ctx.GetStub().PutState(markerKey, []byte(claim.ClaimID)); err != nil {
+		return "", fmt.Errorf("failed to put refund marker: %v", err)
+	}
+
+	if err := s.emitRefundClaimEvent(ctx, "REFUND_CLAIM_FILED", &claim, now); err != nil {
+		return "", err
+	}
+	return claim.ClaimID, nil
+}
+
+// ApproveRefundClaim approves a filed refund claim, computing the
+// refundable amount as the paid amount less the state's deduction. Claims
+// filed after the state's limitation window fail with REFUND_TIME_BARRED
+// and should be rejected instead. Approval marks the challan refunded,
+// so it can no longer be consumed; a challan consumed since filing
+// cannot be approved. Only treasury or admin can approve. Emits
+// REFUND_CLAIM_DECIDED.
+func (s *StampDutyContract) ApproveRefundClaim(ctx contractapi.TransactionContextInterface, stateCode, claimID string) (*RefundClaim, error) {
+	if _, err := requireAnyRole(ctx, "treasury", "admin"); err != nil {
+		return nil, err
+	}
+
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return nil, err
+	}
+
+	claim, err := s.GetRefundClaim(ctx, stateCode, claimID)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Status != "FILED" {
+		return nil, fmt.Errorf("REFUND_CLAIM_INVALID_STATE: expected FILED, got %s", claim.Status)
+	}
+
+	config, err := s.GetRefundConfig(ctx, claim.StateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	paidAt, err := time.Parse(time.RFC3339, claim.PaidAt)
+	if err != nil {
+		return nil, fmt.Errorf("VALIDATION_ERROR: payment date '%s' is not RFC3339", claim.PaidAt)
+	}
+	filedAt, err := time.Parse(time.RFC3339, claim.FiledAt)
+	if err != nil {
+		return nil, fmt.Errorf("VALIDATION_ERROR: filing date '%s' is not RFC3339", claim.FiledAt)
+	}
+	deadline := paidAt.AddDate(0, 0, int(config.LimitationDays))
+	if filedAt.After(deadline) {
+		return nil, fmt.Errorf("REFUND_TIME_BARRED: claim filed %s, limitation of %d days from payment expired %s", claim.FiledAt, config.LimitationDays, deadline.Format(time.RFC3339))
+	}
+
+	payment, err := s.GetPayment(ctx, claim.StateCode, claim.ChallanNumber)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireRefundable(payment); err != nil {
+		return nil, err
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	payment.Refunded = true
+	payment.RefundClaimID = claim.ClaimID
+	payment.RefundedAt = now
+	payment.FabricTxID = ctx.GetStub().GetTxID()
+	if err := putPayment(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	claim.DeductionBasisPts = config.DeductionBasisPts
+	claim.RefundableAmount = claim.PaidAmount - (claim.PaidAmount*int64(config.DeductionBasisPts))/10000
+	claim.Status = "APPROVED"
+	claim.DecidedAt = now
+	claim.DecidedBy = getCallerID(ctx)
+	claim.FabricTxID = ctx.GetStub().GetTxID()
+
+	if err := s.putRefundClaim(ctx, claim); err != nil {
+		return nil, err
+	}
+	if err := s.emitRefundClaimEvent(ctx, "REFUND_CLAIM_DECIDED", claim, now); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// RejectRefundClaim rejects a filed refund claim with a reason. The
+// challan becomes eligible for a fresh claim. Only treasury or admin can
+// reject. Emits REFUND_CLAIM_DECIDED.
+func (s *StampDutyContract) RejectRefundClaim(ctx contractapi.TransactionContextInterface, stateCode, claimID, reason string) error {
+	if _, err := requireAnyRole(ctx, "treasury", "admin"); err != nil {
+		return err
+	}
+
+	if reason == "" {
+		return fmt.Errorf("VALIDATION_ERROR: reason is required to reject a refund claim")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+
+	claim, err := s.GetRefundClaim(ctx, stateCode, claimID)
+	if err != nil {
+		return err
+	}
+	if claim.Status != "FILED" {
+		return fmt.Errorf("REFUND_CLAIM_INVALID_STATE: expected FILED, got %s", claim.Status)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	claim.Status = "REJECTED"
+	claim.DecidedAt = now
+	claim.DecidedBy = getCallerID(ctx)
+	claim.DecisionReason = reason
+	claim.FabricTxID = ctx.GetStub().GetTxID()
+
+	if err := s.putRefundClaim(ctx, claim); err != nil {
+		return err
+	}
+
+	markerKey, err := ctx.GetStub().CreateCompositeKey("REFUND_CHALLAN", []string{claim.StateCode, claim.ChallanNumber})
+	if err != nil {
+		return fmt.Errorf("failed to create refund marker key: %v", err)
+	}
+	if err := ctx.GetStub().DelState(markerKey); err != nil {
+		return fmt.Errorf("failed to clear refund marker: %v", err)
+	}
+
+	return s.emitRefundClaimEvent(ctx, "REFUND_CLAIM_DECIDED", claim, now)
+}
+
+// requireRefundable rejects a payment that has been consumed or refunded.
+func requireRefundable(payment *StampDutyPayment) error {
+	if payment.Consumed {
+		return fmt.Errorf("PAYMENT_ALREADY_CONSUMED: challan %s was consumed by %s and cannot be refunded", payment.ChallanNumber, payment.ConsumedBy)
+	}
+	if payment.Refunded {
+		return fmt.Errorf("PAYMENT_REFUNDED: challan %s was refunded under claim %s", payment.ChallanNumber, payment.RefundClaimID)
+	}
+	return nil
+}
+
+// GetRefundClaim retrieves a refund claim by state and claim ID.
+func (s *StampDutyContract) GetRefundClaim(ctx contractapi.TransactionContextInterface, stateCode, claimID string) (*RefundClaim, error) {
+	if stateCode == "" || claimID == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode and claimID are required")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("REFUND_CLAIM", []string{stateCode, claimID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refund claim key: %v", err)
+	}
+	claimBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refund claim: %v", err)
+	}
+	if claimBytes == nil {
+		return nil, fmt.Errorf("REFUND_CLAIM_NOT_FOUND: %s/%s", stateCode, claimID)
+	}
+
+	var claim RefundClaim
+	if err := json.Unmarshal(claimBytes, &claim); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal refund claim: %v", err)
+	}
+	return &claim, nil
+}
+
+// QueryRefundClaims returns a state's refund claims, optionally filtered
+// by status (FILED, APPROVED, REJECTED). An empty status returns all.
+func (s *StampDutyContract) QueryRefundClaims(ctx contractapi.TransactionContextInterface, stateCode, status string) ([]*RefundClaim, error) {
+	if stateCode == "" {
+		return nil, fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("REFUND_CLAIM", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refund claims: %v", err)
+	}
+	defer iterator.Close()
+
+	var claims []*RefundClaim
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate refund claims: %v", err)
+		}
+		var claim RefundClaim
+		if err := json.Unmarshal(kv.Value, &claim); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal refund claim: %v", err)
+		}
+		if status != "" && claim.Status != status {
+			continue
+		}
+		claims = append(claims, &claim)
+	}
+	return claims, nil
+}
+
+// putRefundClaim stores a refund claim under REFUND_CLAIM~{stateCode}~{claimId}.
+func (s *StampDutyContract) putRefundClaim(ctx contractapi.TransactionContextInterface, claim *RefundClaim) error {
+	key, err := ctx.GetStub().CreateCompositeKey("REFUND_CLAIM", []string{claim.StateCode, claim.ClaimID})
+	if err != nil {
+		return fmt.Errorf("failed to create refund claim key: %v", err)
+	}
+	claimBytes, err := json.Marshal(claim)
+	if err != nil {
+		return fmt.Errorf("failed to marshal refund claim: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, claimBytes); err != nil {
+		return fmt.Errorf("failed to put refund claim state: %v", err)
+	}
+	return nil
+}
+
+// emitRefundClaimEvent emits a RefundClaimEvent with the given name.
+func (s *StampDutyContract) emitRefundClaimEvent(ctx contractapi.TransactionContextInterface, eventName string, claim *RefundClaim, now string) error {
+	event := RefundClaimEvent{
+		Type:             eventName,
+		ClaimID:          claim.ClaimID,
+		StateCode:        claim.StateCode,
+		ChallanNumber:    claim.ChallanNumber,
+		Status:           claim.Status,
+		RefundableAmount: claim.RefundableAmount,
+		FabricTxID:       ctx.GetStub().GetTxID(),
+		Timestamp:        now,
+		ChannelID:        ctx.GetStub().GetChannelID(),
+	}
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
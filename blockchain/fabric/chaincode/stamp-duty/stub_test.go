@@ -0,0 +1,165 @@
+package main
+
+import (
+	"container/list"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// MOCKED-STUB TEST HARNESS
+// ============================================================
+// Tests run contract functions against a shimtest.MockStub world state.
+// Each call is one transaction submitted by a testIdentity, which stands
+// in for a Fabric CA enrolled certificate. A failed transaction's writes
+// are rolled back, as the peer would discard its read-write set.
+
+// testTime is the default transaction timestamp.
+var testTime = time.Date(2027, 3, 15, 10, 30, 0, 0, time.UTC)
+
+// testIdentity is a client identity with fixed certificate attributes.
+// The stamp-duty chaincode only reads attributes and the MSP ID.
+type testIdentity struct {
+	mspID string
+	attrs map[string]string
+}
+
+// newTestIdentity returns an identity of mspID with the given role and
+// stateCode attributes; an empty value leaves the attribute unset.
+func newTestIdentity(mspID, role, stateCode string) *testIdentity {
+	attrs := map[string]string{}
+	if role != "" {
+		attrs["role"] = role
+	}
+	if stateCode != "" {
+		attrs["stateCode"] = stateCode
+	}
+	return &testIdentity{mspID: mspID, attrs: attrs}
+}
+
+func (id *testIdentity) GetID() (string, error) {
+	return "x509::CN=" + id.attrs["role"] + "@" + strings.ToLower(id.mspID), nil
+}
+
+func (id *testIdentity) GetMSPID() (string, error) {
+	return id.mspID, nil
+}
+
+func (id *testIdentity) GetAttributeValue(name string) (string, bool, error) {
+	value, found := id.attrs[name]
+	return value, found, nil
+}
+
+func (id *testIdentity) AssertAttributeValue(name, value string) error {
+	if id.attrs[name] != value {
+		return fmt.Errorf("attribute %s is '%s', not '%s'", name, id.attrs[name], value)
+	}
+	return nil
+}
+
+func (id *testIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return nil, fmt.Errorf("test identities carry no certificate")
+}
+
+// testLedger is the world state shared by a test's transactions.
+type testLedger struct {
+	t        *testing.T
+	stub     *shimtest.MockStub
+	contract *StampDutyContract
+	now      time.Time
+	txCount  int
+	// events holds the names of the events set by the last transaction
+	events []string
+}
+
+// newTestLedger returns an empty ledger on the stampduty channel.
+func newTestLedger(t *testing.T) *testLedger {
+	stub := shimtest.NewMockStub("stamp-duty", nil)
+	stub.ChannelID = "stampduty"
+	return &testLedger{t: t, stub: stub, contract: new(StampDutyContract), now: testTime}
+}
+
+// submit runs fn as one transaction submitted by id, rolling its writes
+// back if it fails.
+func (l *testLedger) submit(id *testIdentity, fn func(ctx contractapi.TransactionContextInterface) error) error {
+	l.t.Helper()
+	l.txCount++
+	txID := fmt.Sprintf("%08x%056x", l.txCount, l.txCount)
+
+	state := make(map[string][]byte, len(l.stub.State))
+	for key, value := range l.stub.State {
+		state[key] = value
+	}
+
+	l.stub.MockTransactionStart(txID)
+	l.stub.TxTimestamp.Seconds = l.now.Unix()
+	l.stub.TxTimestamp.Nanos = 0
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(l.stub)
+	ctx.SetClientIdentity(id)
+	err := fn(ctx)
+	l.stub.MockTransactionEnd(txID)
+
+	l.events = nil
+	for len(l.stub.ChaincodeEventsChannel) > 0 {
+		event := <-l.stub.ChaincodeEventsChannel
+		l.events = append(l.events, event.EventName)
+	}
+	if err != nil {
+		l.stub.State = state
+		l.stub.Keys = sortedKeys(state)
+		l.events = nil
+	}
+	return err
+}
+
+// mustSubmit is submit for transactions the test expects to succeed.
+func (l *testLedger) mustSubmit(id *testIdentity, fn func(ctx contractapi.TransactionContextInterface) error) {
+	l.t.Helper()
+	if err := l.submit(id, fn); err != nil {
+		l.t.Fatalf("transaction failed: %v", err)
+	}
+}
+
+// sortedKeys returns the keys of state as an ordered MockStub key
+// list, which range queries rely on.
+func sortedKeys(state map[string][]byte) *list.List {
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sorted := list.New()
+	for _, key := range keys {
+		sorted.PushBack(key)
+	}
+	return sorted
+}
+
+// errorCode returns the "CODE" prefix of a "CODE: message" error, or ""
+// for nil.
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	code, _, found := strings.Cut(err.Error(), ":")
+	if !found || code != strings.ToUpper(code) || strings.Contains(code, " ") {
+		return "UNCODED: " + err.Error()
+	}
+	return code
+}
+
+// expectCode fails the test unless err carries code.
+func expectCode(t *testing.T, err error, code string) {
+	t.Helper()
+	if got := errorCode(err); got != code {
+		t.Fatalf("expected %s, got %s (%v)", code, got, err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// CROSS-CHAINCODE TRANSFER CALCULATION
+// ============================================================
+
+// CalculateForTransfer is the single entry point used by the
+// land-registry chaincode to verify the fees for a transfer. It takes
+// the property's location, area, land use, declared value, instrument
+// type, buyer composition, claimed concessions and valuation date in one
+// TransferFeeRequest, runs the full circle-rate and concession lookup
+// chain, and returns the breakdown with a SHA-256 hash of the
+// normalized request.
+//
+// The circle rate value is scaled by the state's factor for the land use
+// (see SetLandUseFactors). The instrument type (default SALE_DEED)
+// selects the state's rates and caps, and the fees are rounded as the
+// state configures (see SetInstrumentRates); an instrument the state
+// has not configured fails with INSTRUMENT_NOT_CONFIGURED. A claimed
+// concession restricted to a class of buyer fails with
+// CONCESSION_NOT_ELIGIBLE unless every buyer is of that class.
+//
+// The result depends only on the request and ledger state — the
+// valuation date is mandatory rather than defaulting to the transaction
+// time — so every endorser computes the same response.
+func (s *StampDutyContract) CalculateForTransfer(ctx contractapi.TransactionContextInterface, requestJSON string) (*TransferFeeCalculation, error) {
+	var req TransferFeeRequest
+	if err := json.Unmarshal([]byte(requestJSON), &req); err != nil {
+		return nil, fmt.Errorf("INVALID_INPUT: failed to parse transfer fee request JSON: %v", err)
+	}
+
+	if err := normalizeTransferFeeRequest(&req); err != nil {
+		return nil, err
+	}
+
+	if len(req.ClaimedConcessions) > 0 {
+		rules, err := s.GetConcessionRules(ctx, req.StateCode)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkBuyerEligibility(rules, req.ClaimedConcessions, req.Buyers); err != nil {
+			return nil, err
+		}
+	}
+
+	breakdown, _, _, err := s.calculateWithCircleRate(ctx, req.StateCode, req.DistrictCode, req.TehsilCode, req.LandUse, req.InstrumentType, req.AreaSqMeters, req.DeclaredValue, req.ValuationDate, req.ClaimedConcessions)
+	if err != nil {
+		return nil, err
+	}
+
+	requestHash, err := hashTransferFeeRequest(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransferFeeCalculation{
+		RequestHash: requestHash,
+		Request:     req,
+		Breakdown:   breakdown,
+	}, nil
+}
+
+// normalizeTransferFeeRequest validates a transfer fee request and
+// canonicalizes its enum fields so equivalent requests hash equally.
+func normalizeTransferFeeRequest(req *TransferFeeRequest) error {
+	if req.StateCode == "" || req.DistrictCode == "" || req.TehsilCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode, districtCode, and tehsilCode are all required")
+	}
+	if req.AreaSqMeters <= 0 {
+		return fmt.Errorf("VALIDATION_ERROR: areaSqMeters must be positive")
+	}
+	if req.DeclaredValue < 0 {
+		return fmt.Errorf("VALIDATION_ERROR: declaredValue cannot be negative")
+	}
+	if req.ValuationDate == "" {
+		return fmt.Errorf("VALIDATION_ERROR: valuationDate is required for a deterministic calculation")
+	}
+	if _, err := parseConfigDate(req.ValuationDate, true); err != nil {
+		return err
+	}
+	if len(req.Buyers) == 0 {
+		return fmt.Errorf("VALIDATION_ERROR: at least one buyer is required")
+	}
+
+	req.LandUse = strings.ToUpper(strings.TrimSpace(req.LandUse))
+	if req.LandUse != "" && !landUses[req.LandUse] {
+		return fmt.Errorf("VALIDATION_ERROR: unknown land use '%s'", req.LandUse)
+	}
+	req.InstrumentType = strings.ToUpper(strings.TrimSpace(req.InstrumentType))
+	if req.InstrumentType == "" {
+		req.InstrumentType = defaultInstrumentType
+	}
+	for i := range req.Buyers {
+		gender := strings.ToUpper(strings.TrimSpace(req.Buyers[i].Gender))
+		switch gender {
+		case "MALE", "FEMALE", "OTHER", "":
+		default:
+			return fmt.Errorf("VALIDATION_ERROR: buyer %d gender must be MALE, FEMALE, or OTHER, got '%s'", i, req.Buyers[i].Gender)
+		}
+		req.Buyers[i].Gender = gender
+	}
+	for i, code := range req.ClaimedConcessions {
+		req.ClaimedConcessions[i] = strings.ToUpper(strings.TrimSpace(code))
+	}
+	return nil
+}
+
+// hashTransferFeeRequest returns the hex SHA-256 of the request's JSON
+// encoding. Struct fields marshal in declaration order, so the encoding
+// is stable for a given normalized request.
+func hashTransferFeeRequest(req *TransferFeeRequest) (string, error) {
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal transfer fee request: %v", err)
+	}
+	sum := sha256.Sum256(reqBytes)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ============================================================
+// CIRCLE RATE UNITS
+// ============================================================
+// Circle rates are notified per square foot (urban) or per acre/bigha
+// (rural). The notified rate is stored as published and normalized to
+// paisa per square meter for calculation.
+
+const (
+	UnitSqMeter = "SQ_METER"
+	UnitSqFoot  = "SQ_FOOT"
+	UnitAcre    = "ACRE"
+	UnitHectare = "HECTARE"
+	UnitBigha   = "BIGHA"
+)
+
+// unitAreaE8 gives the exact area of each fixed unit in units of
+// 1e-8 square meters, so conversions stay in integer arithmetic.
+// 1 ft = 0.3048 m exactly, so 1 sq ft = 0.09290304 m² and
+// 1 acre = 43,560 sq ft = 4046.8564224 m².
+var unitAreaE8 = map[string]int64{
+	UnitSqMeter: 100000000,
+	UnitSqFoot:  9290304,
+	UnitAcre:    404685642240,
+	UnitHectare: 1000000000000,
+}
+
+// SetBighaFactor records the size of a bigha in a state in square feet
+// (e.g. 27,225 in Rajasthan, 14,400 in West Bengal and Assam). A state
+// must have a factor before circle rates can be notified per bigha.
+// Only users with the "admin" role can set it.
+func (s *StampDutyContract) SetBighaFactor(ctx contractapi.TransactionContextInterface, stateCode string, sqFeetPerBigha int64) error {
+	if err := requireRole(ctx, "admin"); err != nil {
+		return err
+	}
+
+	if stateCode == "" {
+		return fmt.Errorf("VALIDATION_ERROR: stateCode is required")
+	}
+	if err := requireStateAccess(ctx, stateCode); err != nil {
+		return err
+	}
+	if sqFeetPerBigha <= 0 {
+		return fmt.Errorf("VALIDATION_ERROR: sqFeetPerBigha must be positive, got %d", sqFeetPerBigha)
+	}
+
+	timestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	factor := BighaFactor{
+		DocType:        "bighaFactor",
+		StateCode:      stateCode,
+		SqFeetPerBigha: sqFeetPerBigha,
+		SetBy:          getCallerID(ctx),
+		UpdatedAt:      now,
+		FabricTxID:     ctx.GetStub().GetTxID(),
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey("BIGHA_FACTOR", []string{stateCode})
+	if err != nil {
+		return fmt.Errorf("failed to create bigha factor key: %v", err)
+	}
+	factorBytes, err := json.Marshal(factor)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bigha factor: %v", err)
+	}
+	return ctx.GetStub().PutState(key, factorBytes)
+}
+
+// GetBighaFactor retrieves the configured bigha size for a state.
+func (s *StampDutyContract) GetBighaFactor(ctx contractapi.TransactionContextInterface, stateCode string) (*BighaFactor, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("BIGHA_FACTOR", []string{stateCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bigha factor key: %v", err)
+	}
+	factorBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bigha factor: %v", err)
+	}
+	if factorBytes == nil {
+		return nil, fmt.Errorf("BIGHA_FACTOR_NOT_SET: no bigha size configured for state %s", stateCode)
+	}
+	var factor BighaFactor
+	if err := json.Unmarshal(factorBytes, &factor); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bigha factor: %v", err)
+	}
+	return &factor, nil
+}
+
+// normalizeRateUnit upper-cases a unit and defaults empty to SQ_METER.
+func normalizeRateUnit(unit string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(unit))
+	if normalized == "" {
+		return UnitSqMeter, nil
+	}
+	if _, ok := unitAreaE8[normalized]; ok || normalized == UnitBigha {
+		return normalized, nil
+	}
+	return "", fmt.Errorf("VALIDATION_ERROR: unit must be one of SQ_METER, SQ_FOOT, ACRE, HECTARE, BIGHA, got '%s'", unit)
+}
+
+// rateUnitAreaE8 returns the area of one unit in 1e-8 square meters,
+// reading the state's bigha factor when needed.
+func (s *StampDutyContract) rateUnitAreaE8(ctx contractapi.TransactionContextInterface, stateCode, unit string) (int64, error) {
+	if unit == UnitBigha {
+		factor, err := s.GetBighaFactor(ctx, stateCode)
+		if err != nil {
+			return 0, err
+		}
+		return factor.SqFeetPerBigha * unitAreaE8[UnitSqFoot], nil
+	}
+	areaE8, ok := unitAreaE8[unit]
+	if !ok {
+		return 0, fmt.Errorf("VALIDATION_ERROR: unknown circle rate unit '%s'", unit)
+	}
+	return areaE8, nil
+}
+
+// ratePerSqMeterFromUnit converts a rate in paisa per unit to paisa per
+// square meter, rounding half up. Arithmetic is done in big.Int because
+// rate × 1e8 can exceed int64 for per-acre rates.
+func ratePerSqMeterFromUnit(rate, unitAreaE8 int64) int64 {
+	num := new(big.Int).Mul(big.NewInt(rate), big.NewInt(100000000))
+	den := big.NewInt(unitAreaE8)
+	num.Add(num, new(big.Int).Quo(den, big.NewInt(2)))
+	return num.Quo(num, den).Int64()
+}
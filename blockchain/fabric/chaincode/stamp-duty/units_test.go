@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestUnitAreaEquivalences(t *testing.T) {
+	// 1 acre = 43,560 sq ft, exactly
+	if got := 43560 * unitAreaE8[UnitSqFoot]; got != unitAreaE8[UnitAcre] {
+		t.Errorf("43,560 sq ft = %d e-8 m², want 1 acre = %d", got, unitAreaE8[UnitAcre])
+	}
+	// 1 hectare = 10,000 sq m
+	if got := 10000 * unitAreaE8[UnitSqMeter]; got != unitAreaE8[UnitHectare] {
+		t.Errorf("10,000 sq m = %d e-8 m², want 1 hectare = %d", got, unitAreaE8[UnitHectare])
+	}
+	// 1 sq ft = 0.3048 m × 0.3048 m
+	if got := unitAreaE8[UnitSqFoot]; got != 3048*3048 {
+		t.Errorf("1 sq ft = %d e-8 m², want %d", got, 3048*3048)
+	}
+}
+
+func TestRatePerSqMeterFromUnit(t *testing.T) {
+	tests := []struct {
+		name string
+		rate int64
+		unit string
+		want int64
+	}{
+		{"per sq m is unchanged", 800000, UnitSqMeter, 800000},
+		// ₹1,000/sq ft = ₹10,763.91/sq m
+		{"per sq ft", 100000, UnitSqFoot, 1076391},
+		// ₹1 crore/hectare = ₹1,000/sq m
+		{"per hectare", 1000000000, UnitHectare, 100000},
+		// ₹40,46,856.42/acre ≈ ₹1,000/sq m, rounded half up
+		{"per acre", 404685642, UnitAcre, 100000},
+		// ₹43,560/acre = ₹1/sq ft = 107.64 paisa/sq m, rounded half up
+		{"per acre rounds", 4356000, UnitAcre, 1076},
+		// ₹5 crore/acre does not overflow int64 arithmetic
+		{"large per acre", 5000000000, UnitAcre, 1235527},
+	}
+	for _, tc := range tests {
+		if got := ratePerSqMeterFromUnit(tc.rate, unitAreaE8[tc.unit]); got != tc.want {
+			t.Errorf("%s: ratePerSqMeterFromUnit(%d, %s) = %d, want %d", tc.name, tc.rate, tc.unit, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeRateUnit(t *testing.T) {
+	tests := map[string]string{
+		"":          UnitSqMeter,
+		"sq_foot":   UnitSqFoot,
+		" ACRE ":    UnitAcre,
+		"hectare":   UnitHectare,
+		"Bigha":     UnitBigha,
+		"SQ_METER":  UnitSqMeter,
+		"SQ_YARD":   "",
+		"sq meters": "",
+	}
+	for input, want := range tests {
+		got, err := normalizeRateUnit(input)
+		if want == "" {
+			expectCode(t, err, "VALIDATION_ERROR")
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("normalizeRateUnit(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestCircleRatePerBighaUsesStateFactor(t *testing.T) {
+	ledger := newTestLedger(t)
+	admin := newTestIdentity("RevenueOrgMSP", "admin", "RJ")
+
+	err := ledger.submit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetCircleRate(ctx, "RJ", "JPR", "SNG", 2722500000, UnitBigha, "")
+	})
+	expectCode(t, err, "BIGHA_FACTOR_NOT_SET")
+
+	// A Rajasthan bigha is 27,225 sq ft, so ₹2,72,25,000/bigha = ₹1,000/sq ft
+	ledger.mustSubmit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetBighaFactor(ctx, "RJ", 27225)
+	})
+	ledger.mustSubmit(admin, func(ctx contractapi.TransactionContextInterface) error {
+		return ledger.contract.SetCircleRate(ctx, "RJ", "JPR", "SNG", 2722500000, "bigha", "")
+	})
+
+	var record *CircleRate
+	var breakdown *StampDutyBreakdown
+	ledger.mustSubmit(newTestIdentity("RegistrarOrgMSP", "registrar", "RJ"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		if record, err = ledger.contract.GetCircleRateRecord(ctx, "RJ", "JPR", "SNG"); err != nil {
+			return err
+		}
+		breakdown, err = ledger.contract.CalculateStampDutyWithCircleRate(ctx, "RJ", "JPR", "SNG", 10, 0, "", nil)
+		return err
+	})
+	if record.NotifiedRate != 2722500000 || record.Unit != UnitBigha || record.RatePerSqMeter != 1076391 {
+		t.Fatalf("record = %d per %s, %d per sq m; want 2722500000 per BIGHA, 1076391 per sq m",
+			record.NotifiedRate, record.Unit, record.RatePerSqMeter)
+	}
+	if breakdown.NotifiedRate != 2722500000 || breakdown.NotifiedRateUnit != UnitBigha || breakdown.RatePerSqMeter != 1076391 {
+		t.Fatalf("breakdown = %d per %s, %d per sq m; want 2722500000 per BIGHA, 1076391 per sq m",
+			breakdown.NotifiedRate, breakdown.NotifiedRateUnit, breakdown.RatePerSqMeter)
+	}
+	if breakdown.CircleRateValue != 10763910 {
+		t.Fatalf("CircleRateValue = %d, want 10763910", breakdown.CircleRateValue)
+	}
+}
+
+func TestGetCircleRateRecordDefaultsLegacyUnit(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.mustSubmit(newTestIdentity("RevenueOrgMSP", "admin", "KA"), func(ctx contractapi.TransactionContextInterface) error {
+		key, _ := ctx.GetStub().CreateCompositeKey("CIRCLE_RATE", []string{"KA", "BLR", "ANK"})
+		return ctx.GetStub().PutState(key, []byte(`{"docType":"circleRate","stateCode":"KA","ratePerSqMeter":800000}`))
+	})
+
+	var record *CircleRate
+	ledger.mustSubmit(newTestIdentity("RegistrarOrgMSP", "registrar", "KA"), func(ctx contractapi.TransactionContextInterface) error {
+		var err error
+		record, err = ledger.contract.GetCircleRateRecord(ctx, "KA", "BLR", "ANK")
+		return err
+	})
+	if record.Unit != UnitSqMeter || record.NotifiedRate != 800000 {
+		t.Fatalf("legacy record = %d per %s, want 800000 per SQ_METER", record.NotifiedRate, record.Unit)
+	}
+}